@@ -0,0 +1,135 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/lazycatapps/gosmee/backend/internal/handler"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// apiClient calls the HTTP API of a running server, authenticating with a service account token.
+type apiClient struct {
+	baseURL string       // Server URL (e.g. http://localhost:8080)
+	apiKey  string       // Service account token
+	http    *http.Client // Underlying HTTP client
+}
+
+// addAPIFlags registers the flags shared by all commands talking to the API.
+func addAPIFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().String("server", "http://localhost:8080", "URL of the running gosmee-web server")
+	cmd.PersistentFlags().String("api-key", "", "Service account token used to authenticate against the API")
+	cmd.PersistentFlags().Bool("json", false, "Print raw JSON responses instead of tables")
+}
+
+// silenceAPIErrors stops cobra from printing usage (and a duplicate error) once the
+// arguments are valid, so API failures are reported on their own.
+func silenceAPIErrors(cmd *cobra.Command, args []string) {
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+}
+
+// newAPIClient creates an API client from the command's flags,
+// falling back to the GOSMEE_SERVER and GOSMEE_API_KEY environment variables.
+func newAPIClient(cmd *cobra.Command) (*apiClient, error) {
+	if err := viper.BindPFlags(cmd.Flags()); err != nil {
+		return nil, err
+	}
+
+	apiKey := viper.GetString("api-key")
+	if apiKey == "" {
+		return nil, fmt.Errorf("an API key is required (--api-key or GOSMEE_API_KEY)")
+	}
+
+	return &apiClient{
+		baseURL: strings.TrimRight(viper.GetString("server"), "/"),
+		apiKey:  apiKey,
+		http:    &http.Client{Timeout: 5 * time.Minute}, // Batch operations and replays can be slow
+	}, nil
+}
+
+// do sends a request to path (relative to /api/v1) and decodes the JSON response into out.
+// Error envelopes are turned into errors carrying the code, message and request ID.
+func (a *apiClient) do(method, path string, query url.Values, body, out interface{}) error {
+	target := a.baseURL + "/api/v1" + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, target, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+a.apiKey)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := a.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var errResp handler.ErrorResponse
+		if json.Unmarshal(data, &errResp) != nil || errResp.Code == "" {
+			return fmt.Errorf("%s %s: HTTP %d", method, path, resp.StatusCode)
+		}
+		return fmt.Errorf("%s: %s (request ID %s)", errResp.Code, errResp.Message, errResp.RequestID)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// wantJSON reports whether the command should print raw JSON.
+func wantJSON(cmd *cobra.Command) bool {
+	asJSON, _ := cmd.Flags().GetBool("json")
+	return asJSON
+}
+
+// printJSON prints a value as indented JSON.
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// newTable returns a tab writer for aligned table output. Callers must Flush it.
+func newTable() *tabwriter.Writer {
+	return tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+}
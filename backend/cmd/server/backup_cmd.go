@@ -0,0 +1,118 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export user data from the data directory to a tar.gz archive",
+	Long: `Export the clients, events and logs of one user (--user) or of all users
+(including service accounts) from the data directory to a tar.gz archive.
+Events moved to the cold data directory are included with --cold-data-dir. Events in S3
+object storage (--event-storage s3) are not included; back up the bucket separately.
+Exporting while the server is running is possible, but clients receiving events may be
+captured in an inconsistent state.`,
+	Args: cobra.NoArgs,
+	RunE: runExport,
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import a tar.gz archive created by export into the data directory",
+	Long: `Import an archive created by export into the data directory.
+The server must be stopped while importing. Existing clients with the same ID are only
+replaced with --overwrite; otherwise the import is aborted before any change is made.
+Archived cold events are restored to --cold-data-dir, or to the data directory without it.`,
+	Args: cobra.NoArgs,
+	RunE: runImport,
+}
+
+// init registers the export and import subcommands and their flags.
+func init() {
+	exportCmd.Flags().String("data-dir", "/data", "Base data directory for all user data")
+	exportCmd.Flags().String("cold-data-dir", "", "Cold data directory whose events are included (empty = disabled)")
+	exportCmd.Flags().String("user", "", "Only export this user (default: all users and service accounts)")
+	exportCmd.Flags().String("out", "", "Archive file to write (required)")
+	exportCmd.MarkFlagRequired("out")
+
+	importCmd.Flags().String("data-dir", "/data", "Base data directory for all user data")
+	importCmd.Flags().String("cold-data-dir", "", "Cold data directory archived cold events are restored to (empty = data directory)")
+	importCmd.Flags().String("user", "", "Only import this user from the archive (default: everything)")
+	importCmd.Flags().String("in", "", "Archive file to read (required)")
+	importCmd.Flags().Bool("overwrite", false, "Replace existing clients and files")
+	importCmd.MarkFlagRequired("in")
+
+	rootCmd.AddCommand(exportCmd, importCmd)
+}
+
+// runExport writes the export archive, replacing the output file only on success.
+func runExport(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	if err := viper.BindPFlags(cmd.Flags()); err != nil {
+		return err
+	}
+
+	out := viper.GetString("out")
+	tmpPath := out + ".tmp"
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer os.Remove(tmpPath)
+
+	backupService := service.NewBackupService(viper.GetString("data-dir"), logger.New())
+	backupService.SetColdDir(viper.GetString("cold-data-dir"))
+	manifest, err := backupService.Export(file, viper.GetString("user"))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("export failed: %w", err)
+	}
+	if err := os.Rename(tmpPath, out); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	fmt.Printf("Exported %d users with %d clients to %s\n", len(manifest.Users), manifest.Clients, out)
+	return nil
+}
+
+// runImport restores an export archive into the data directory.
+func runImport(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	if err := viper.BindPFlags(cmd.Flags()); err != nil {
+		return err
+	}
+
+	file, err := os.Open(viper.GetString("in"))
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+
+	backupService := service.NewBackupService(viper.GetString("data-dir"), logger.New())
+	backupService.SetColdDir(viper.GetString("cold-data-dir"))
+	result, err := backupService.Import(file, service.ImportOptions{
+		UserID:    viper.GetString("user"),
+		Overwrite: viper.GetBool("overwrite"),
+	})
+	if err != nil {
+		return fmt.Errorf("import failed: %w", err)
+	}
+
+	fmt.Printf("Imported %d clients of %d users (%d files)\n", result.Clients, len(result.Users), result.Files)
+	return nil
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+
+	"github.com/spf13/cobra"
+)
+
+// clientCmd groups the client management subcommands.
+var clientCmd = &cobra.Command{
+	Use:              "client",
+	Short:            "Manage gosmee client instances of a running server",
+	PersistentPreRun: silenceAPIErrors,
+}
+
+var clientListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List client instances",
+	Args:  cobra.NoArgs,
+	RunE:  runClientList,
+}
+
+var clientCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a client instance",
+	Args:  cobra.NoArgs,
+	RunE:  runClientCreate,
+}
+
+var clientStartCmd = &cobra.Command{
+	Use:   "start <client-id>...",
+	Short: "Start client instances",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runClientAction(cmd, args, "start")
+	},
+}
+
+var clientStopCmd = &cobra.Command{
+	Use:   "stop <client-id>...",
+	Short: "Stop client instances",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runClientAction(cmd, args, "stop")
+	},
+}
+
+// init registers the client subcommands and their flags.
+func init() {
+	addAPIFlags(clientCmd)
+
+	clientListCmd.Flags().String("status", "", "Filter by status (running, stopped, error)")
+	clientListCmd.Flags().String("search", "", "Search by name")
+	clientListCmd.Flags().Int("page", 1, "Page number")
+	clientListCmd.Flags().Int("page-size", 20, "Items per page (max 100)")
+
+	clientCreateCmd.Flags().String("name", "", "Instance name (required)")
+	clientCreateCmd.Flags().String("description", "", "Instance description")
+	clientCreateCmd.Flags().String("smee-url", "", "Smee server URL (required)")
+	clientCreateCmd.Flags().String("target-url", "", "Target URL (required)")
+	clientCreateCmd.Flags().Int("target-timeout", 60, "Target timeout in seconds")
+	clientCreateCmd.Flags().String("provider", "", "Webhook provider (github, gitlab, bitbucket, stripe, custom)")
+	clientCreateCmd.Flags().StringSlice("ignore-events", nil, "Event types to ignore")
+	clientCreateCmd.Flags().Bool("no-replay", false, "Save events without forwarding them")
+	clientCreateCmd.Flags().Bool("httpie", false, "Use HTTPie format")
+	clientCreateCmd.Flags().String("log-level", "", "gosmee log verbosity (info, debug)")
+	clientCreateCmd.MarkFlagRequired("name")
+	clientCreateCmd.MarkFlagRequired("smee-url")
+	clientCreateCmd.MarkFlagRequired("target-url")
+
+	clientCmd.AddCommand(clientListCmd, clientCreateCmd, clientStartCmd, clientStopCmd)
+	rootCmd.AddCommand(clientCmd)
+}
+
+// runClientList lists client instances.
+func runClientList(cmd *cobra.Command, args []string) error {
+	api, err := newAPIClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	status, _ := cmd.Flags().GetString("status")
+	search, _ := cmd.Flags().GetString("search")
+	page, _ := cmd.Flags().GetInt("page")
+	pageSize, _ := cmd.Flags().GetInt("page-size")
+
+	query := url.Values{}
+	query.Set("page", strconv.Itoa(page))
+	query.Set("pageSize", strconv.Itoa(pageSize))
+	if status != "" {
+		query.Set("status", status)
+	}
+	if search != "" {
+		query.Set("search", search)
+	}
+
+	var resp models.ClientListResponse
+	if err := api.do(http.MethodGet, "/clients", query, nil, &resp); err != nil {
+		return err
+	}
+	if wantJSON(cmd) {
+		return printJSON(resp)
+	}
+
+	table := newTable()
+	fmt.Fprintln(table, "ID\tNAME\tSTATUS\tTODAY\tTOTAL\tTARGET")
+	for _, client := range resp.Clients {
+		status := client.Status
+		if client.Paused {
+			status += " (paused)"
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%d\t%d\t%s\n",
+			client.ID, client.Name, status, client.TodayEvents, client.TotalEvents, client.TargetURL)
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("\nPage %d, %d of %d clients\n", resp.Page, len(resp.Clients), resp.Total)
+	return nil
+}
+
+// runClientCreate creates a client instance.
+func runClientCreate(cmd *cobra.Command, args []string) error {
+	api, err := newAPIClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	req := &models.ClientRequest{}
+	req.Name, _ = cmd.Flags().GetString("name")
+	req.Description, _ = cmd.Flags().GetString("description")
+	req.SmeeURL, _ = cmd.Flags().GetString("smee-url")
+	req.TargetURL, _ = cmd.Flags().GetString("target-url")
+	req.TargetTimeout, _ = cmd.Flags().GetInt("target-timeout")
+	req.Provider, _ = cmd.Flags().GetString("provider")
+	req.IgnoreEvents, _ = cmd.Flags().GetStringSlice("ignore-events")
+	req.NoReplay, _ = cmd.Flags().GetBool("no-replay")
+	req.HTTPie, _ = cmd.Flags().GetBool("httpie")
+	req.LogLevel, _ = cmd.Flags().GetString("log-level")
+
+	var client models.Client
+	if err := api.do(http.MethodPost, "/clients", nil, req, &client); err != nil {
+		return err
+	}
+	if wantJSON(cmd) {
+		return printJSON(client)
+	}
+
+	fmt.Printf("Created client %s (%s)\n", client.ID, client.Name)
+	return nil
+}
+
+// runClientAction starts or stops each client, continuing past failures.
+func runClientAction(cmd *cobra.Command, clientIDs []string, action string) error {
+	api, err := newAPIClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, clientID := range clientIDs {
+		var resp struct {
+			Message string `json:"message"`
+		}
+		if err := api.do(http.MethodPost, "/clients/"+url.PathEscape(clientID)+"/"+action, nil, nil, &resp); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%s: %v\n", clientID, err)
+			failed++
+			continue
+		}
+		fmt.Printf("%s: %s\n", clientID, resp.Message)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to %s %d of %d clients", action, failed, len(clientIDs))
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+	"github.com/lazycatapps/gosmee/backend/internal/types"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose the installation and print actionable findings",
+	Long: `Validate the data directory layout and permissions, verify the gosmee binary and
+its version, and probe the OIDC issuer. Exits with a non-zero status if any check fails.`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+// init registers the doctor subcommand and its flags.
+func init() {
+	doctorCmd.Flags().String("data-dir", "/data", "Base data directory for all user data")
+	doctorCmd.Flags().String("client-runtime", "exec", "How clients run: exec (gosmee client processes) or embedded (in the server process)")
+	doctorCmd.Flags().String("oidc-client-id", "", "OIDC client ID")
+	doctorCmd.Flags().String("oidc-client-secret", "", "OIDC client secret")
+	doctorCmd.Flags().String("oidc-issuer", "", "OIDC issuer URL")
+	doctorCmd.Flags().String("oidc-redirect-url", "", "OIDC redirect URL")
+	doctorCmd.Flags().Bool("oidc-pkce", false, "Use PKCE in the OIDC authorization code flow")
+
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// runDoctor runs all checks and prints the findings.
+func runDoctor(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	if err := viper.BindPFlags(cmd.Flags()); err != nil {
+		return err
+	}
+
+	oidc := &types.OIDCConfig{
+		ClientID:     viper.GetString("oidc-client-id"),
+		ClientSecret: viper.GetString("oidc-client-secret"),
+		Issuer:       viper.GetString("oidc-issuer"),
+		RedirectURL:  viper.GetString("oidc-redirect-url"),
+		PKCE:         viper.GetBool("oidc-pkce"),
+	}
+	oidc.Enabled = oidc.ClientID != "" && (oidc.ClientSecret != "" || oidc.PKCE) && oidc.Issuer != ""
+
+	doctor := service.NewDoctorService(viper.GetString("data-dir"), oidc)
+	doctor.SetClientRuntime(viper.GetString("client-runtime"))
+	findings := doctor.Run()
+
+	counts := make(map[service.DoctorLevel]int)
+	for _, finding := range findings {
+		counts[finding.Level]++
+		fmt.Printf("[%-4s] %-16s %s\n", strings.ToUpper(string(finding.Level)), finding.Check, finding.Message)
+		if finding.Hint != "" {
+			fmt.Printf("%24s %s\n", "->", finding.Hint)
+		}
+	}
+	fmt.Printf("\n%d ok, %d warnings, %d failures\n", counts[service.DoctorOK], counts[service.DoctorWarn], counts[service.DoctorFail])
+
+	if counts[service.DoctorFail] > 0 {
+		return fmt.Errorf("%d checks failed", counts[service.DoctorFail])
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+
+	"github.com/spf13/cobra"
+)
+
+// eventCmd groups the event history subcommands.
+var eventCmd = &cobra.Command{
+	Use:              "event",
+	Short:            "Inspect and replay events of a running server",
+	PersistentPreRun: silenceAPIErrors,
+}
+
+var eventListCmd = &cobra.Command{
+	Use:   "list <client-id>",
+	Short: "List events received by a client",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runEventList,
+}
+
+var eventReplayCmd = &cobra.Command{
+	Use:   "replay <client-id> [event-id]...",
+	Short: "Replay events to the client's target",
+	Long:  "Replay the given events to the client's target, or all failed events with --failed.",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runEventReplay,
+}
+
+// init registers the event subcommands and their flags.
+func init() {
+	addAPIFlags(eventCmd)
+
+	eventListCmd.Flags().String("status", "", "Filter by status (success, failed, retrying, not_replayed)")
+	eventListCmd.Flags().String("event-type", "", "Filter by event type")
+	eventListCmd.Flags().String("search", "", "Search in source")
+	eventListCmd.Flags().Int("page", 1, "Page number")
+	eventListCmd.Flags().Int("page-size", 20, "Items per page (max 100)")
+
+	eventReplayCmd.Flags().Bool("failed", false, "Replay all failed events of the client")
+
+	eventCmd.AddCommand(eventListCmd, eventReplayCmd)
+	rootCmd.AddCommand(eventCmd)
+}
+
+// runEventList lists the events of a client.
+func runEventList(cmd *cobra.Command, args []string) error {
+	api, err := newAPIClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	status, _ := cmd.Flags().GetString("status")
+	eventType, _ := cmd.Flags().GetString("event-type")
+	search, _ := cmd.Flags().GetString("search")
+	page, _ := cmd.Flags().GetInt("page")
+	pageSize, _ := cmd.Flags().GetInt("page-size")
+
+	query := url.Values{}
+	query.Set("page", strconv.Itoa(page))
+	query.Set("pageSize", strconv.Itoa(pageSize))
+	if status != "" {
+		query.Set("status", status)
+	}
+	if eventType != "" {
+		query.Set("eventType", eventType)
+	}
+	if search != "" {
+		query.Set("search", search)
+	}
+
+	var resp models.EventListResponse
+	if err := api.do(http.MethodGet, "/clients/"+url.PathEscape(args[0])+"/events", query, nil, &resp); err != nil {
+		return err
+	}
+	if wantJSON(cmd) {
+		return printJSON(resp)
+	}
+
+	table := newTable()
+	fmt.Fprintln(table, "ID\tTIME\tTYPE\tSTATUS\tCODE\tLATENCY")
+	for _, event := range resp.Events {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%d\t%dms\n",
+			event.ID, event.Timestamp.Local().Format(time.DateTime), event.EventType, event.Status, event.StatusCode, event.LatencyMs)
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("\nPage %d, %d of %d events\n", resp.Page, len(resp.Events), resp.Total)
+	return nil
+}
+
+// runEventReplay replays the given events, or all failed events with --failed.
+func runEventReplay(cmd *cobra.Command, args []string) error {
+	api, err := newAPIClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	clientID, eventIDs := args[0], args[1:]
+	failedOnly, _ := cmd.Flags().GetBool("failed")
+	if failedOnly == (len(eventIDs) > 0) {
+		return fmt.Errorf("specify either event IDs or --failed")
+	}
+
+	path := "/clients/" + url.PathEscape(clientID) + "/events/replay"
+	var body interface{} = &models.EventReplayRequest{EventIDs: eventIDs}
+	if failedOnly {
+		path += "-failed"
+		body = nil
+	}
+
+	var resp models.EventReplayResponse
+	if err := api.do(http.MethodPost, path, nil, body, &resp); err != nil {
+		return err
+	}
+	if wantJSON(cmd) {
+		return printJSON(resp)
+	}
+
+	table := newTable()
+	fmt.Fprintln(table, "EVENT\tRESULT\tCODE\tLATENCY\tERROR")
+	for _, result := range resp.Results {
+		outcome := "ok"
+		if !result.Success {
+			outcome = "failed"
+		}
+		fmt.Fprintf(table, "%s\t%s\t%d\t%dms\t%s\n",
+			result.EventID, outcome, result.StatusCode, result.LatencyMs, result.ErrorMessage)
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("\nReplayed %d events: %d successful, %d failed\n", resp.Total, resp.Successful, resp.Failed)
+
+	if resp.Failed > 0 {
+		return fmt.Errorf("%d of %d events failed to replay", resp.Failed, resp.Total)
+	}
+	return nil
+}
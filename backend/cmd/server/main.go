@@ -5,19 +5,28 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/lazycatapps/gosmee/backend/internal/handler"
+	"github.com/lazycatapps/gosmee/backend/internal/middleware"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/encryption"
 	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/s3"
 	"github.com/lazycatapps/gosmee/backend/internal/repository"
 	"github.com/lazycatapps/gosmee/backend/internal/router"
 	"github.com/lazycatapps/gosmee/backend/internal/service"
 	"github.com/lazycatapps/gosmee/backend/internal/types"
+	"github.com/lazycatapps/gosmee/backend/web"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -35,22 +44,74 @@ var rootCmd = &cobra.Command{
 func init() {
 	rootCmd.Flags().String("host", "0.0.0.0", "Server host")
 	rootCmd.Flags().IntP("port", "p", 8080, "Server port")
+	rootCmd.Flags().Bool("compression", true, "Compress API responses with brotli/gzip when the client supports it")
+	rootCmd.Flags().Int("compression-min-size", 1024, "Minimum response size in bytes to compress")
+	rootCmd.Flags().Int64("max-body-size", 1<<20, "Maximum request body size in bytes (0 = unlimited)")
+	rootCmd.Flags().Int64("max-event-body-size", 25<<20, "Maximum request body size in bytes for manual event injection (0 = unlimited)")
+	rootCmd.Flags().Int64("max-bundle-size", 100<<20, "Maximum request body size in bytes for client bundle uploads (0 = unlimited)")
 	rootCmd.Flags().StringSlice("cors-allowed-origins", []string{"*"}, "CORS allowed origins")
 	rootCmd.Flags().String("data-dir", "/data", "Base data directory for all user data")
+	rootCmd.Flags().String("cold-data-dir", "", "Secondary data directory (e.g. a slower, bigger disk) old events are moved to (empty = disabled)")
+	rootCmd.Flags().Int("cold-after-days", 7, "Days after which events are moved to the cold data directory")
+	rootCmd.Flags().Float64("disk-warning-free-percent", 10, "Free space of a data volume in percent below which administrators are warned (0 = disabled)")
+	rootCmd.Flags().Float64("disk-critical-free-percent", 5, "Free space of a data volume in percent below which it is rated critical (0 = disabled)")
+	rootCmd.Flags().String("encryption-key", "", "AES-256 key (base64 or hex) encrypting event files and logs at rest (empty = disabled)")
+	rootCmd.Flags().String("encryption-key-file", "", "File containing the encryption key (e.g. a secret mounted by a KMS)")
+	rootCmd.Flags().String("event-storage", "file", "Event storage backend: file (data directory) or s3 (S3-compatible object storage)")
+	rootCmd.Flags().String("s3-endpoint", "", "S3 endpoint URL (e.g. https://s3.amazonaws.com or http://minio:9000)")
+	rootCmd.Flags().String("s3-region", "us-east-1", "S3 region")
+	rootCmd.Flags().String("s3-bucket", "", "S3 bucket storing events")
+	rootCmd.Flags().String("s3-prefix", "", "Key prefix of all event objects in the bucket")
+	rootCmd.Flags().String("s3-access-key", "", "S3 access key ID")
+	rootCmd.Flags().String("s3-secret-key", "", "S3 secret access key")
+	rootCmd.Flags().Bool("serve-frontend", false, "Serve the embedded frontend (single-binary deployment)")
 
 	// Gosmee configuration
-	rootCmd.Flags().Int("max-clients-per-user", 1000, "Maximum number of clients per user")
+	rootCmd.Flags().Int("max-clients-per-user", 1000, "Maximum number of clients per user (hard limit, creating more is blocked)")
+	rootCmd.Flags().Int("soft-clients-per-user", 0, "Number of clients per user from which users are warned but can still create clients (0 = no soft limit)")
 	rootCmd.Flags().Int64("max-storage-per-user", 10737418240, "Maximum storage per user in bytes (default: 10GB)")
 	rootCmd.Flags().Int("event-retention-days", 30, "Days to retain events (0 = forever)")
+	rootCmd.Flags().Int("failed-event-retention-days", 0, "Days to retain failed events, e.g. longer than the others (0 = same as --event-retention-days)")
 	rootCmd.Flags().Int("log-retention-days", 30, "Days to retain logs (0 = forever)")
 	rootCmd.Flags().Bool("auto-restart", false, "Auto restart crashed clients")
-	rootCmd.Flags().Int("max-restart-attempts", 3, "Maximum restart attempts")
+	rootCmd.Flags().Int("max-restart-attempts", 3, "Maximum automatic restarts within the restart window")
+	rootCmd.Flags().Int("restart-window-seconds", 600, "Sliding window for the automatic restart budget in seconds")
+	rootCmd.Flags().Int("log-buffer-lines", 5000, "Log lines kept in memory per running client (full history is written to log files)")
+	rootCmd.Flags().Int("last-run-log-lines", 200, "Log lines of a stopped client kept in memory for the last-run logs endpoint")
+	rootCmd.Flags().Int("last-run-retention-seconds", 900, "Seconds the log lines of a stopped client are kept in memory (0 = not kept)")
+	rootCmd.Flags().Int("startup-grace-seconds", 3, "Seconds a started client must stay alive before start is reported successful")
+	rootCmd.Flags().String("startup-ready-pattern", "", "Regexp matching the client log line that signals an established SSE connection (empty = don't wait)")
+	rootCmd.Flags().Int("startup-ready-timeout", 15, "Seconds to wait for the startup ready pattern")
+	rootCmd.Flags().Int("max-concurrent-starts", 10, "Maximum client processes starting up at the same time, further starts are queued (0 = unlimited)")
+	rootCmd.Flags().Int("max-running-clients", 0, "Maximum client processes running at the same time across all users (0 = unlimited)")
+	rootCmd.Flags().Int("max-running-clients-per-user", 0, "Maximum client processes of a single user running at the same time (0 = unlimited)")
+	rootCmd.Flags().Int("stop-timeout-seconds", 5, "Seconds a stopped client process gets to exit after SIGTERM before it is killed (0 = kill right away)")
+	rootCmd.Flags().String("stale-process-policy", "adopt", "What happens on startup to client processes left running by a previous server: adopt (manage them) or terminate (stop them)")
+	rootCmd.Flags().String("client-runtime", "exec", "How clients run: exec (gosmee client processes) or embedded (in the server process, gosmee is not needed)")
+	rootCmd.Flags().Int("circuit-breaker-threshold", 10, "Consecutive delivery failures that pause a client's deliveries (0 = disabled)")
+	rootCmd.Flags().Int("circuit-breaker-cooldown", 60, "Seconds before paused deliveries are probed again")
+	rootCmd.Flags().Int("script-replay-timeout", 0, "Seconds a stored replay script may run when replaying in script mode (0 = script replay disabled)")
+	rootCmd.Flags().Int("response-capture-size", 64*1024, "Maximum target response body in bytes stored on events delivered by the backend (0 = not stored)")
+	rootCmd.Flags().Int("max-payload-size", 0, "Maximum stored event payload size in bytes (0 = unlimited)")
+	rootCmd.Flags().String("payload-limit-policy", "truncate", "What happens to events with larger payloads: truncate (store the beginning) or reject (don't store them)")
+	rootCmd.Flags().Int("max-events-per-minute-per-client", 0, "Maximum new events stored per client and minute (0 = unlimited)")
+	rootCmd.Flags().Int("max-events-per-minute", 0, "Maximum new events stored across all clients per minute (0 = unlimited)")
+	rootCmd.Flags().String("ingestion-overflow", "drop", "What happens to events over the event rate limits: drop (delete and count them) or queue (process them in a later minute)")
+	rootCmd.Flags().Int("ingestion-queue-size", 1000, "Events per client held back with --ingestion-overflow=queue before further events are dropped")
+	rootCmd.Flags().Int("metrics-max-event-types", 20, "Event types per client exported with their own metrics label, further types are counted as \"other\"")
+	rootCmd.Flags().String("quota-alert-thresholds", "80,95,100", "Comma-separated storage usage percentages that notify the user (empty = disabled)")
+	rootCmd.Flags().Float64("storage-warning-threshold", 80, "Storage usage percentage that triggers a quota warning (users can override it)")
+	rootCmd.Flags().Float64("storage-full-threshold", 100, "Storage usage percentage at which storage counts as full (users can only lower it)")
 
 	// OIDC configuration
 	rootCmd.Flags().String("oidc-client-id", "", "OIDC client ID")
 	rootCmd.Flags().String("oidc-client-secret", "", "OIDC client secret")
 	rootCmd.Flags().String("oidc-issuer", "", "OIDC issuer URL")
 	rootCmd.Flags().String("oidc-redirect-url", "", "OIDC redirect URL")
+	rootCmd.Flags().StringSlice("oidc-scopes", []string{"openid", "profile", "email", "groups"}, "OIDC scopes to request (openid is always included)")
+	rootCmd.Flags().Bool("oidc-pkce", false, "Use PKCE in the OIDC authorization code flow (the client secret becomes optional)")
+	rootCmd.Flags().String("oidc-providers-file", "", "JSON file listing additional OIDC identity providers")
+	rootCmd.Flags().String("authz-policy-file", "", "JSON file mapping roles to the route groups they may access (default: built-in policy)")
 
 	viper.BindPFlags(rootCmd.Flags())
 
@@ -68,32 +129,84 @@ func runServer(cmd *cobra.Command, args []string) {
 	oidcClientSecret := viper.GetString("oidc-client-secret")
 	oidcIssuer := viper.GetString("oidc-issuer")
 	oidcRedirectURL := viper.GetString("oidc-redirect-url")
+	oidcPKCE := viper.GetBool("oidc-pkce")
 
 	cfg := &types.Config{
 		Server: types.ServerConfig{
-			Host: viper.GetString("host"),
-			Port: viper.GetInt("port"),
+			Host:               viper.GetString("host"),
+			Port:               viper.GetInt("port"),
+			Compression:        viper.GetBool("compression"),
+			CompressionMinSize: viper.GetInt("compression-min-size"),
+			MaxBodySize:        viper.GetInt64("max-body-size"),
+			MaxEventBodySize:   viper.GetInt64("max-event-body-size"),
+			MaxBundleSize:      viper.GetInt64("max-bundle-size"),
+			ServeFrontend:      viper.GetBool("serve-frontend"),
 		},
 		Gosmee: types.GosmeeConfig{
-			MaxClientsPerUser:  viper.GetInt("max-clients-per-user"),
-			MaxStoragePerUser:  viper.GetInt64("max-storage-per-user"),
-			EventRetentionDays: viper.GetInt("event-retention-days"),
-			LogRetentionDays:   viper.GetInt("log-retention-days"),
-			AutoRestart:        viper.GetBool("auto-restart"),
-			MaxRestartAttempts: viper.GetInt("max-restart-attempts"),
+			MaxClientsPerUser:           viper.GetInt("max-clients-per-user"),
+			SoftClientsPerUser:          viper.GetInt("soft-clients-per-user"),
+			MaxStoragePerUser:           viper.GetInt64("max-storage-per-user"),
+			EventRetentionDays:          viper.GetInt("event-retention-days"),
+			FailedRetentionDays:         viper.GetInt("failed-event-retention-days"),
+			LogRetentionDays:            viper.GetInt("log-retention-days"),
+			AutoRestart:                 viper.GetBool("auto-restart"),
+			MaxRestartAttempts:          viper.GetInt("max-restart-attempts"),
+			RestartWindow:               viper.GetInt("restart-window-seconds"),
+			LogBufferLines:              viper.GetInt("log-buffer-lines"),
+			LastRunLogLines:             viper.GetInt("last-run-log-lines"),
+			LastRunRetentionSec:         viper.GetInt("last-run-retention-seconds"),
+			StartupGraceSeconds:         viper.GetInt("startup-grace-seconds"),
+			StartupReadyPattern:         viper.GetString("startup-ready-pattern"),
+			StartupReadyTimeout:         viper.GetInt("startup-ready-timeout"),
+			MaxConcurrentStarts:         viper.GetInt("max-concurrent-starts"),
+			MaxRunningClients:           viper.GetInt("max-running-clients"),
+			MaxRunningPerUser:           viper.GetInt("max-running-clients-per-user"),
+			StopTimeoutSeconds:          viper.GetInt("stop-timeout-seconds"),
+			StaleProcessPolicy:          viper.GetString("stale-process-policy"),
+			ClientRuntime:               viper.GetString("client-runtime"),
+			CircuitBreakerThreshold:     viper.GetInt("circuit-breaker-threshold"),
+			CircuitBreakerCooldown:      viper.GetInt("circuit-breaker-cooldown"),
+			ScriptReplayTimeout:         viper.GetInt("script-replay-timeout"),
+			ResponseCaptureSize:         viper.GetInt("response-capture-size"),
+			MaxPayloadSize:              viper.GetInt("max-payload-size"),
+			PayloadLimitPolicy:          viper.GetString("payload-limit-policy"),
+			MaxEventsPerMinutePerClient: viper.GetInt("max-events-per-minute-per-client"),
+			MaxEventsPerMinute:          viper.GetInt("max-events-per-minute"),
+			IngestionOverflow:           viper.GetString("ingestion-overflow"),
+			IngestionQueueSize:          viper.GetInt("ingestion-queue-size"),
+			MetricsMaxEventTypes:        viper.GetInt("metrics-max-event-types"),
+			StorageWarningThreshold:     viper.GetFloat64("storage-warning-threshold"),
+			StorageFullThreshold:        viper.GetFloat64("storage-full-threshold"),
 		},
 		CORS: types.CORSConfig{
 			AllowedOrigins: viper.GetStringSlice("cors-allowed-origins"),
 		},
 		Storage: types.StorageConfig{
-			DataDir: viper.GetString("data-dir"),
+			DataDir:                 viper.GetString("data-dir"),
+			ColdDataDir:             viper.GetString("cold-data-dir"),
+			ColdAfterDays:           viper.GetInt("cold-after-days"),
+			DiskWarningFreePercent:  viper.GetFloat64("disk-warning-free-percent"),
+			DiskCriticalFreePercent: viper.GetFloat64("disk-critical-free-percent"),
+			EncryptionKey:           viper.GetString("encryption-key"),
+			EncryptionKeyFile:       viper.GetString("encryption-key-file"),
+			EventStorage:            viper.GetString("event-storage"),
+			S3: types.S3Config{
+				Endpoint:  viper.GetString("s3-endpoint"),
+				Region:    viper.GetString("s3-region"),
+				Bucket:    viper.GetString("s3-bucket"),
+				Prefix:    viper.GetString("s3-prefix"),
+				AccessKey: viper.GetString("s3-access-key"),
+				SecretKey: viper.GetString("s3-secret-key"),
+			},
 		},
 		OIDC: types.OIDCConfig{
 			ClientID:     oidcClientID,
 			ClientSecret: oidcClientSecret,
 			Issuer:       oidcIssuer,
 			RedirectURL:  oidcRedirectURL,
-			Enabled:      oidcClientID != "" && oidcClientSecret != "" && oidcIssuer != "",
+			Enabled:      oidcClientID != "" && (oidcClientSecret != "" || oidcPKCE) && oidcIssuer != "",
+			Scopes:       viper.GetStringSlice("oidc-scopes"),
+			PKCE:         oidcPKCE,
 		},
 	}
 
@@ -103,13 +216,111 @@ func runServer(cmd *cobra.Command, args []string) {
 	log.Info("Starting Gosmee Web UI server")
 	log.Info("=================================")
 
+	if path := viper.GetString("oidc-providers-file"); path != "" {
+		providers, err := loadOIDCProviders(path)
+		if err != nil {
+			log.Error("Invalid OIDC providers file: %v", err)
+			return
+		}
+		cfg.OIDC.Providers = providers
+		cfg.OIDC.Enabled = cfg.OIDC.Enabled || len(providers) > 0
+	}
+
+	policy := middleware.DefaultPolicy()
+	if path := viper.GetString("authz-policy-file"); path != "" {
+		loaded, err := middleware.LoadPolicy(path)
+		if err != nil {
+			log.Error("Invalid authorization policy file: %v", err)
+			return
+		}
+		policy = loaded
+		log.Info("Authorization policy: %s", path)
+	}
+
+	quotaAlertThresholds, err := parseThresholds(viper.GetString("quota-alert-thresholds"))
+	if err != nil {
+		log.Error("Invalid quota alert thresholds: %v", err)
+		return
+	}
+	cfg.Gosmee.QuotaAlertThresholds = quotaAlertThresholds
+	if cfg.Gosmee.StorageFullThreshold <= 0 || cfg.Gosmee.StorageFullThreshold > 100 ||
+		cfg.Gosmee.StorageWarningThreshold <= 0 || cfg.Gosmee.StorageWarningThreshold > cfg.Gosmee.StorageFullThreshold {
+		log.Error("Invalid storage thresholds: warning %g%% and full %g%% must satisfy 0 < warning <= full <= 100",
+			cfg.Gosmee.StorageWarningThreshold, cfg.Gosmee.StorageFullThreshold)
+		return
+	}
+	if cfg.Storage.DiskCriticalFreePercent < 0 || cfg.Storage.DiskWarningFreePercent < 0 || cfg.Storage.DiskWarningFreePercent >= 100 ||
+		(cfg.Storage.DiskWarningFreePercent != 0 && cfg.Storage.DiskCriticalFreePercent > cfg.Storage.DiskWarningFreePercent) {
+		log.Error("Invalid disk space thresholds: warning %g%% and critical %g%% must satisfy 0 <= critical <= warning < 100 (0 = disabled)",
+			cfg.Storage.DiskWarningFreePercent, cfg.Storage.DiskCriticalFreePercent)
+		return
+	}
+	if cfg.Gosmee.MaxConcurrentStarts < 0 || cfg.Gosmee.MaxRunningClients < 0 || cfg.Gosmee.MaxRunningPerUser < 0 {
+		log.Error("Invalid process limits: max concurrent starts %d, max running clients %d and max running clients per user %d must not be negative",
+			cfg.Gosmee.MaxConcurrentStarts, cfg.Gosmee.MaxRunningClients, cfg.Gosmee.MaxRunningPerUser)
+		return
+	}
+	if cfg.Gosmee.EventRetentionDays < 0 || cfg.Gosmee.FailedRetentionDays < 0 {
+		log.Error("Invalid event retention: %d days and %d days for failed events must not be negative",
+			cfg.Gosmee.EventRetentionDays, cfg.Gosmee.FailedRetentionDays)
+		return
+	}
+	if cfg.Gosmee.StopTimeoutSeconds < 0 {
+		log.Error("Invalid stop timeout %d: must not be negative", cfg.Gosmee.StopTimeoutSeconds)
+		return
+	}
+	if cfg.Gosmee.StaleProcessPolicy != service.StaleProcessAdopt && cfg.Gosmee.StaleProcessPolicy != service.StaleProcessTerminate {
+		log.Error("Invalid stale process policy %q: must be adopt or terminate", cfg.Gosmee.StaleProcessPolicy)
+		return
+	}
+	if cfg.Gosmee.ClientRuntime != service.ClientRuntimeExec && cfg.Gosmee.ClientRuntime != service.ClientRuntimeEmbedded {
+		log.Error("Invalid client runtime %q: must be exec or embedded", cfg.Gosmee.ClientRuntime)
+		return
+	}
+	if cfg.Gosmee.LastRunLogLines < 0 || cfg.Gosmee.LastRunRetentionSec < 0 {
+		log.Error("Invalid last-run log retention: %d lines and %d seconds must not be negative",
+			cfg.Gosmee.LastRunLogLines, cfg.Gosmee.LastRunRetentionSec)
+		return
+	}
+	if cfg.Gosmee.MaxPayloadSize < 0 ||
+		(cfg.Gosmee.PayloadLimitPolicy != service.PayloadLimitTruncate && cfg.Gosmee.PayloadLimitPolicy != service.PayloadLimitReject) {
+		log.Error("Invalid payload limit: size %d must not be negative and policy %q must be truncate or reject",
+			cfg.Gosmee.MaxPayloadSize, cfg.Gosmee.PayloadLimitPolicy)
+		return
+	}
+	if cfg.Gosmee.MaxEventsPerMinutePerClient < 0 || cfg.Gosmee.MaxEventsPerMinute < 0 || cfg.Gosmee.IngestionQueueSize < 0 ||
+		(cfg.Gosmee.IngestionOverflow != service.IngestionOverflowDrop && cfg.Gosmee.IngestionOverflow != service.IngestionOverflowQueue) {
+		log.Error("Invalid ingestion limits: limits and queue size must not be negative and overflow %q must be drop or queue",
+			cfg.Gosmee.IngestionOverflow)
+		return
+	}
+	if cfg.Gosmee.MetricsMaxEventTypes < 0 {
+		log.Error("Invalid metrics event types %d: must not be negative", cfg.Gosmee.MetricsMaxEventTypes)
+		return
+	}
+
 	// Log configuration
 	log.Info("Gosmee Configuration:")
-	log.Info("  Max Clients Per User: %d", cfg.Gosmee.MaxClientsPerUser)
+	log.Info("  Max Clients Per User: %d (soft limit: %d, 0 = none)", cfg.Gosmee.MaxClientsPerUser, cfg.Gosmee.SoftClientsPerUser)
 	log.Info("  Max Storage Per User: %d bytes (%.2f GB)", cfg.Gosmee.MaxStoragePerUser, float64(cfg.Gosmee.MaxStoragePerUser)/1024/1024/1024)
-	log.Info("  Event Retention: %d days", cfg.Gosmee.EventRetentionDays)
+	log.Info("  Event Retention: %d days (failed events: %d days, 0 = same)", cfg.Gosmee.EventRetentionDays, cfg.Gosmee.FailedRetentionDays)
 	log.Info("  Log Retention: %d days", cfg.Gosmee.LogRetentionDays)
-	log.Info("  Auto Restart: %v", cfg.Gosmee.AutoRestart)
+	log.Info("  Auto Restart: %v (max %d restarts within %ds)", cfg.Gosmee.AutoRestart, cfg.Gosmee.MaxRestartAttempts, cfg.Gosmee.RestartWindow)
+	log.Info("  Max Concurrent Starts: %d (0 = unlimited)", cfg.Gosmee.MaxConcurrentStarts)
+	log.Info("  Max Running Clients: %d (per user: %d, 0 = unlimited)", cfg.Gosmee.MaxRunningClients, cfg.Gosmee.MaxRunningPerUser)
+	log.Info("  Stop Timeout: %ds (0 = kill right away)", cfg.Gosmee.StopTimeoutSeconds)
+	log.Info("  Stale Process Policy: %s", cfg.Gosmee.StaleProcessPolicy)
+	log.Info("  Client Runtime: %s", cfg.Gosmee.ClientRuntime)
+	log.Info("  Circuit Breaker: %d failures, %ds cooldown", cfg.Gosmee.CircuitBreakerThreshold, cfg.Gosmee.CircuitBreakerCooldown)
+	log.Info("  Script Replay Timeout: %ds (0 = disabled)", cfg.Gosmee.ScriptReplayTimeout)
+	log.Info("  Response Capture Size: %d bytes (0 = not stored)", cfg.Gosmee.ResponseCaptureSize)
+	log.Info("  Max Payload Size: %d bytes (0 = unlimited, policy: %s)", cfg.Gosmee.MaxPayloadSize, cfg.Gosmee.PayloadLimitPolicy)
+	log.Info("  Max Events Per Minute: %d per client, %d in total (0 = unlimited, overflow: %s, queue size: %d)",
+		cfg.Gosmee.MaxEventsPerMinutePerClient, cfg.Gosmee.MaxEventsPerMinute, cfg.Gosmee.IngestionOverflow, cfg.Gosmee.IngestionQueueSize)
+	log.Info("  Metrics Event Types Per Client: %d", cfg.Gosmee.MetricsMaxEventTypes)
+	log.Info("  Quota Alert Thresholds: %v%%", cfg.Gosmee.QuotaAlertThresholds)
+	log.Info("  Storage Thresholds: warning %g%%, full %g%%", cfg.Gosmee.StorageWarningThreshold, cfg.Gosmee.StorageFullThreshold)
+	log.Info("  Disk Free Space Thresholds: warning %g%%, critical %g%% (0 = disabled)", cfg.Storage.DiskWarningFreePercent, cfg.Storage.DiskCriticalFreePercent)
 
 	// Log OIDC configuration status
 	if cfg.OIDC.Enabled {
@@ -117,6 +328,10 @@ func runServer(cmd *cobra.Command, args []string) {
 		log.Info("  Issuer: %s", cfg.OIDC.Issuer)
 		log.Info("  Client ID: %s", cfg.OIDC.ClientID)
 		log.Info("  Redirect URL: %s", cfg.OIDC.RedirectURL)
+		log.Info("  Scopes: %s (PKCE: %v)", strings.Join(cfg.OIDC.Scopes, " "), cfg.OIDC.PKCE)
+		for _, provider := range cfg.OIDC.Providers {
+			log.Info("  Additional Provider: %s (%s, issuer %s)", provider.ID, provider.Name, provider.Issuer)
+		}
 	} else {
 		log.Info("OIDC authentication: DISABLED")
 	}
@@ -131,38 +346,300 @@ func runServer(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	eventRepo := repository.NewFileEventRepository(cfg.Storage.DataDir)
+	cipher, err := encryption.LoadCipher(cfg.Storage.EncryptionKey, cfg.Storage.EncryptionKeyFile)
+	if err != nil {
+		log.Error("Failed to load encryption key: %v", err)
+		return
+	}
+	if cipher != nil {
+		log.Info("  Encryption at rest: ENABLED")
+	}
+
+	fileEventRepo := repository.NewFileEventRepository(cfg.Storage.DataDir)
+	fileEventRepo.SetCipher(cipher)
+	var eventRepo repository.EventRepository = fileEventRepo
+	var s3EventRepo *repository.S3EventRepository
+	switch cfg.Storage.EventStorage {
+	case "file":
+		// Events stay in the data directory
+	case "s3":
+		if cfg.Storage.ColdDataDir != "" {
+			log.Error("The cold data directory cannot be used with S3 event storage")
+			return
+		}
+		s3Client, err := s3.New(s3.Config{
+			Endpoint:  cfg.Storage.S3.Endpoint,
+			Region:    cfg.Storage.S3.Region,
+			Bucket:    cfg.Storage.S3.Bucket,
+			AccessKey: cfg.Storage.S3.AccessKey,
+			SecretKey: cfg.Storage.S3.SecretKey,
+		})
+		if err != nil {
+			log.Error("Invalid S3 configuration: %v", err)
+			return
+		}
+		s3EventRepo = repository.NewS3EventRepository(s3Client, cfg.Storage.S3.Prefix, fileEventRepo)
+		s3EventRepo.SetCipher(cipher)
+		eventRepo = s3EventRepo
+		log.Info("  Event storage: S3 bucket %s at %s", cfg.Storage.S3.Bucket, cfg.Storage.S3.Endpoint)
+	default:
+		log.Error("Invalid event storage %q (expected file or s3)", cfg.Storage.EventStorage)
+		return
+	}
+	if cfg.Storage.ColdDataDir != "" {
+		if filepath.Clean(cfg.Storage.ColdDataDir) == filepath.Clean(cfg.Storage.DataDir) {
+			log.Error("The cold data directory must differ from the data directory")
+			return
+		}
+		fileEventRepo.SetColdDir(cfg.Storage.ColdDataDir)
+		log.Info("  Cold data directory: %s (events older than %d days)", cfg.Storage.ColdDataDir, cfg.Storage.ColdAfterDays)
+	}
+	if recovered, err := fileEventRepo.RecoverJournals(); err != nil {
+		log.Error("Failed to recover interrupted event updates: %v", err)
+		return
+	} else if recovered > 0 {
+		log.Info("  Recovered %d interrupted event updates", recovered)
+	}
+	serviceAccountRepo, err := repository.NewFileServiceAccountRepository(cfg.Storage.DataDir)
+	if err != nil {
+		log.Error("Failed to initialize service account repository: %v", err)
+		return
+	}
+	settingsRepo := repository.NewFileSettingsRepository(cfg.Storage.DataDir)
+	secretRepo := repository.NewFileSecretRepository(cfg.Storage.DataDir)
+	secretRepo.SetCipher(cipher)
+	logAnnotationRepo := repository.NewFileLogAnnotationRepository(cfg.Storage.DataDir)
+	logAnnotationRepo.SetCipher(cipher)
+	statsRepo := repository.NewFileStatsRepository(cfg.Storage.DataDir)
+	quotaHistoryRepo := repository.NewFileQuotaHistoryRepository(cfg.Storage.DataDir)
 	quotaRepo := repository.NewFileQuotaRepository(
 		cfg.Storage.DataDir,
 		cfg.Gosmee.MaxStoragePerUser,
 		cfg.Gosmee.MaxClientsPerUser,
 	)
+	quotaRepo.SetSoftClientLimit(cfg.Gosmee.SoftClientsPerUser)
+	quotaRepo.SetColdDir(cfg.Storage.ColdDataDir)
 
 	log.Info("Repositories initialized successfully")
 
 	// Initialize services
-	processService := service.NewProcessService(cfg.Gosmee.AutoRestart, cfg.Gosmee.MaxRestartAttempts, log)
-	clientService := service.NewClientService(clientRepo, quotaRepo, eventRepo, processService, cfg.Storage.DataDir, log)
-	logService := service.NewLogService(cfg.Storage.DataDir, log)
-	eventService := service.NewEventService(eventRepo, clientRepo, log)
-	quotaService := service.NewQuotaService(quotaRepo, log)
+	settingsService := service.NewSettingsService(settingsRepo, log)
+	processService := service.NewProcessService(
+		cfg.Gosmee.AutoRestart,
+		cfg.Gosmee.MaxRestartAttempts,
+		time.Duration(cfg.Gosmee.RestartWindow)*time.Second,
+		log,
+	)
+
+	var readyPattern *regexp.Regexp
+	if cfg.Gosmee.StartupReadyPattern != "" {
+		readyPattern, err = regexp.Compile(cfg.Gosmee.StartupReadyPattern)
+		if err != nil {
+			log.Error("Invalid startup ready pattern: %v", err)
+			return
+		}
+	}
+	processService.SetLogBufferLines(cfg.Gosmee.LogBufferLines)
+	processService.SetLastRunRetention(cfg.Gosmee.LastRunLogLines, time.Duration(cfg.Gosmee.LastRunRetentionSec)*time.Second)
+	processService.SetMasker(settingsService.MaskerFor)
+	processService.SetCipher(cipher)
+	processService.SetStartupCheck(
+		time.Duration(cfg.Gosmee.StartupGraceSeconds)*time.Second,
+		readyPattern,
+		time.Duration(cfg.Gosmee.StartupReadyTimeout)*time.Second,
+	)
+	processService.SetMaxConcurrentStarts(cfg.Gosmee.MaxConcurrentStarts)
+	processService.SetMaxRunning(cfg.Gosmee.MaxRunningClients)
+	processService.SetMaxRunningPerUser(cfg.Gosmee.MaxRunningPerUser)
+	processService.SetStopTimeout(time.Duration(cfg.Gosmee.StopTimeoutSeconds) * time.Second)
+	processService.SetStaleProcessPolicy(cfg.Gosmee.StaleProcessPolicy)
+	jobService := service.NewJobService(24*time.Hour, log) // Keep finished jobs for 1 day
+	clientService := service.NewClientService(clientRepo, quotaRepo, eventRepo, processService, jobService, cfg.Storage.DataDir, log)
+	clientService.SetSecretRepository(secretRepo)
+	logService := service.NewLogService(cfg.Storage.DataDir, logAnnotationRepo, log)
+	logService.SetMasker(settingsService.MaskerFor)
+	logService.SetCipher(cipher)
+	notificationService := service.NewNotificationService(log)
+	diskDirs := []string{cfg.Storage.DataDir}
+	if cfg.Storage.ColdDataDir != "" {
+		diskDirs = append(diskDirs, cfg.Storage.ColdDataDir)
+	}
+	diskWatcher := service.NewDiskWatcherService(diskDirs, cfg.Storage.DiskWarningFreePercent, cfg.Storage.DiskCriticalFreePercent, notificationService, log)
+	diskWatcher.Check()
+	circuitBreakerService := service.NewCircuitBreakerService(
+		cfg.Gosmee.CircuitBreakerThreshold,
+		time.Duration(cfg.Gosmee.CircuitBreakerCooldown)*time.Second,
+		notificationService,
+		log,
+	)
+	eventService := service.NewEventService(eventRepo, clientRepo, jobService, circuitBreakerService, log)
+	eventService.SetMasker(settingsService.MaskerFor)
+	eventService.SetScriptReplay(time.Duration(cfg.Gosmee.ScriptReplayTimeout) * time.Second)
+	eventService.SetResponseCapture(cfg.Gosmee.ResponseCaptureSize)
+	eventService.SetSecretRepository(secretRepo)
+	payloadLimiter := service.NewPayloadLimiter(cfg.Gosmee.MaxPayloadSize, cfg.Gosmee.PayloadLimitPolicy, eventRepo, log)
+	payloadLimiter.SetNotificationService(notificationService)
+	eventService.SetPayloadLimiter(payloadLimiter)
+	ingestionLimiter := service.NewIngestionLimiter(cfg.Gosmee.MaxEventsPerMinutePerClient, cfg.Gosmee.MaxEventsPerMinute,
+		cfg.Gosmee.IngestionOverflow, cfg.Gosmee.IngestionQueueSize, eventRepo, log)
+	ingestionLimiter.SetNotificationService(notificationService)
+	eventService.SetIngestionLimiter(ingestionLimiter)
+	eventSampler := service.NewEventSampler(eventRepo, log)
+	deliveryIndex := service.NewDeliveryIndex(eventRepo, log)
+	eventService.SetDeliveryIndex(deliveryIndex)
+	deliveryCounters := service.NewDeliveryCounters(cfg.Gosmee.MetricsMaxEventTypes)
+	eventService.SetDeliveryCounters(deliveryCounters)
+	eventService.SetRetention(cfg.Gosmee.EventRetentionDays, cfg.Gosmee.FailedRetentionDays)
+	quotaService := service.NewQuotaService(quotaRepo, quotaHistoryRepo, log)
+	quotaService.SetThresholds(settingsRepo, cfg.Gosmee.StorageWarningThreshold, cfg.Gosmee.StorageFullThreshold)
+	quotaService.SetProcessService(processService)
 	sessionService := service.NewSessionService(7 * 24 * time.Hour) // 7 days session TTL
+	serviceAccountService := service.NewServiceAccountService(serviceAccountRepo, log)
+	orphanService := service.NewOrphanService(cfg.Storage.DataDir, clientRepo, quotaRepo, log)
+	orphanService.SetColdDir(cfg.Storage.ColdDataDir)
+	redactionService := service.NewRedactionService(clientRepo, eventRepo, log)
+	statsService := service.NewStatsService(clientRepo, eventRepo, statsRepo, quotaRepo, log)
+	quotaService.SetAlerts(notificationService, clientRepo, cfg.Gosmee.QuotaAlertThresholds)
+	clientService.SetStatsService(statsService)
+	clientService.SetIngestionLimiter(ingestionLimiter)
+	clientService.SetEventSampler(eventSampler)
+	if err := clientService.AssignMissingSlugs(); err != nil {
+		log.Error("Failed to assign client slugs: %v", err)
+	}
+	statsService.SetIngestionLimiter(ingestionLimiter)
+	statsService.SetDeliveryCounters(deliveryCounters)
+	reindexService := service.NewReindexService(clientRepo, quotaRepo, statsService, jobService, log)
+	reindexService.SetDeliveryIndex(deliveryIndex)
+	if s3EventRepo != nil {
+		reindexService.SetS3EventRepository(s3EventRepo)
+	}
+	bundleService := service.NewClientBundleService(clientService, clientRepo, quotaRepo, eventRepo, eventService, logService, log)
+	bundleService.SetStatsService(statsService)
+	impersonationService := service.NewImpersonationService(repository.NewFileImpersonationRepository(cfg.Storage.DataDir), log)
+	offboardingService := service.NewUserOffboardingService(cfg.Storage.DataDir, clientService, clientRepo, quotaRepo,
+		sessionService, serviceAccountService, repository.NewFileOffboardingRepository(cfg.Storage.DataDir), log)
+	offboardingService.SetColdDir(cfg.Storage.ColdDataDir)
+
+	watcherService, err := service.NewWatcherService(eventRepo, quotaService, log)
+	if err != nil {
+		log.Error("Failed to initialize event watcher: %v", err)
+		return
+	}
+	defer watcherService.Close()
+	watcherService.SetStatsService(statsService)
+	watcherService.SetQuotaEnforcement(notificationService, clientService.Stop)
+	watcherService.SetForwarder(eventService.ForwardNewEvents)
+	watcherService.SetPayloadLimiter(payloadLimiter)
+	watcherService.SetIngestionLimiter(ingestionLimiter)
+	watcherService.SetSampler(eventSampler)
+	watcherService.SetDeliveryIndex(deliveryIndex)
+	watcherService.SetDeliveryCounters(deliveryCounters)
+	watcherService.SetMasker(settingsService.MaskerFor)
+	processService.SetEventWatcher(watcherService)
+	if cfg.Gosmee.ClientRuntime == service.ClientRuntimeEmbedded {
+		processService.SetEmbeddedClients(watcherService)
+	}
+	eventService.SetWatcher(watcherService)
+	redactionService.SetWatcher(watcherService)
+
+	// Adopt the processes of a previous backend instead of starting clients twice
+	if report, err := clientService.ReconcileProcesses(); err != nil {
+		log.Error("Failed to reconcile running gosmee processes: %v", err)
+	} else if len(report.Adopted) > 0 || len(report.Terminated) > 0 || len(report.Unmanaged) > 0 {
+		log.Info("Adopted %d running gosmee processes, terminated %d, %d left unmanaged",
+			len(report.Adopted), len(report.Terminated), len(report.Unmanaged))
+	}
+
+	// Register background tasks
+	scheduler := service.NewSchedulerService(log)
+	scheduler.Register("event-retry", 30*time.Second, eventService.RetryFailedDeliveries)
+	scheduler.Register("client-schedules", time.Minute, clientService.ApplySchedules)
+	scheduler.Register("event-redaction", 5*time.Second, redactionService.RedactNewEvents)
+	scheduler.Register("stats-rollup", 10*time.Minute, statsService.RollupEvents)
+	scheduler.Register("quota-snapshots", time.Hour, quotaService.RecordSnapshots)
+	scheduler.Register("event-retention", time.Hour, eventService.ApplyRetention)
+	if len(cfg.Gosmee.QuotaAlertThresholds) > 0 {
+		scheduler.Register("quota-alerts", 5*time.Minute, quotaService.CheckThresholds)
+	}
+	if cfg.Storage.ColdDataDir != "" {
+		tieringService := service.NewTieringService(clientRepo, eventRepo, cfg.Storage.ColdAfterDays, log)
+		scheduler.Register("event-tiering", time.Hour, tieringService.MoveColdEvents)
+	}
+	if s3EventRepo != nil {
+		uploadService := service.NewUploadService(clientRepo, s3EventRepo, log)
+		scheduler.Register("event-upload", 5*time.Second, uploadService.UploadNewEvents)
+	}
+	if cipher != nil {
+		encryptionService := service.NewEncryptionService(clientRepo, eventRepo, log)
+		scheduler.Register("event-encryption", 5*time.Second, encryptionService.EncryptNewEvents)
+	}
+	scheduler.Register("disk-watcher", time.Minute, diskWatcher.Check)
+	scheduler.Start()
 
 	// Initialize HTTP handlers
 	clientHandler := handler.NewClientHandler(clientService, quotaService, log)
-	logHandler := handler.NewLogHandler(logService, processService, log)
+	logHandler := handler.NewLogHandler(logService, processService, clientService, log)
 	eventHandler := handler.NewEventHandler(eventService, log)
 	quotaHandler := handler.NewQuotaHandler(quotaService, log)
+	jobHandler := handler.NewJobHandler(jobService, log)
+	notificationHandler := handler.NewNotificationHandler(notificationService, log)
+	serviceAccountHandler := handler.NewServiceAccountHandler(serviceAccountService, log)
+	orphanHandler := handler.NewOrphanHandler(orphanService, log)
+	settingsHandler := handler.NewSettingsHandler(settingsService, log)
+	statsHandler := handler.NewStatsHandler(statsService, log)
+	reindexHandler := handler.NewReindexHandler(reindexService, log)
+	bundleHandler := handler.NewClientBundleHandler(bundleService, log)
+	impersonationHandler := handler.NewImpersonationHandler(impersonationService, log)
+	offboardingHandler := handler.NewUserOffboardingHandler(offboardingService, log)
+	healthHandler := handler.NewHealthHandler(diskWatcher)
 
 	// Initialize auth handler
-	authHandler, err := handler.NewAuthHandler(&cfg.OIDC, sessionService, log)
+	authorizer := middleware.NewAuthorizer(policy, cfg.OIDC.Enabled)
+	authHandler, err := handler.NewAuthHandler(&cfg.OIDC, sessionService, authorizer, log)
 	if err != nil {
 		log.Error("Failed to initialize auth handler: %v", err)
 		return
 	}
 
+	// Initialize frontend handler (single-binary deployment)
+	var frontendHandler *handler.FrontendHandler
+	if cfg.Server.ServeFrontend {
+		if !web.Available() {
+			log.Error("--serve-frontend is set, but this binary was built without the frontend (use make build-single-bin)")
+			return
+		}
+		frontendHandler, err = handler.NewFrontendHandler(web.FS())
+		if err != nil {
+			log.Error("Failed to initialize frontend handler: %v", err)
+			return
+		}
+		log.Info("Serving embedded frontend")
+	}
+
 	// Set up router and middleware
-	r := router.New(clientHandler, logHandler, eventHandler, quotaHandler, authHandler, sessionService)
+	r := router.New(
+		clientHandler,
+		logHandler,
+		eventHandler,
+		quotaHandler,
+		jobHandler,
+		notificationHandler,
+		serviceAccountHandler,
+		orphanHandler,
+		settingsHandler,
+		statsHandler,
+		reindexHandler,
+		bundleHandler,
+		impersonationHandler,
+		offboardingHandler,
+		healthHandler,
+		authHandler,
+		sessionService,
+		serviceAccountService,
+		authorizer,
+		impersonationService,
+		frontendHandler,
+	)
 	engine := r.Setup(cfg)
 
 	// Set up graceful shutdown
@@ -186,12 +663,63 @@ func runServer(cmd *cobra.Command, args []string) {
 	<-quit
 	log.Info("Shutting down server...")
 
-	// Stop all running processes
+	// Stop background tasks and all running processes
+	scheduler.Stop()
 	processService.StopAll()
 
 	log.Info("Goodbye!")
 }
 
+// parseThresholds parses a comma-separated list of percentages (1-100) into ascending order.
+func parseThresholds(value string) ([]int, error) {
+	thresholds := []int{}
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		threshold, err := strconv.Atoi(strings.TrimSuffix(field, "%"))
+		if err != nil || threshold < 1 || threshold > 100 {
+			return nil, fmt.Errorf("threshold %q must be a percentage between 1 and 100", field)
+		}
+		thresholds = append(thresholds, threshold)
+	}
+	sort.Ints(thresholds)
+	return thresholds, nil
+}
+
+// oidcProviderIDPattern matches valid identity provider IDs, which prefix user IDs.
+var oidcProviderIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// loadOIDCProviders reads and validates the additional identity providers from a JSON file
+// holding an array of provider objects.
+func loadOIDCProviders(path string) ([]types.OIDCProviderConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var providers []types.OIDCProviderConfig
+	if err := json.Unmarshal(data, &providers); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	seen := map[string]bool{"default": true}
+	for i := range providers {
+		provider := &providers[i]
+		if !oidcProviderIDPattern.MatchString(provider.ID) || seen[provider.ID] {
+			return nil, fmt.Errorf("provider %d: id %q must be unique, not \"default\", and consist of lowercase letters, digits and dashes", i+1, provider.ID)
+		}
+		seen[provider.ID] = true
+		if provider.Issuer == "" || provider.ClientID == "" || (provider.ClientSecret == "" && !provider.PKCE) {
+			return nil, fmt.Errorf("provider %s: issuer, clientId and clientSecret (or pkce) are required", provider.ID)
+		}
+		if provider.Name == "" {
+			provider.Name = provider.ID
+		}
+	}
+	return providers, nil
+}
+
 // main is the application entry point.
 func main() {
 	if err := rootCmd.Execute(); err != nil {
@@ -0,0 +1,92 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/encryption"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var reindexCmd = &cobra.Command{
+	Use:   "reindex",
+	Short: "Rebuild the stats rollups of all clients from the raw event files",
+	Long: `Apply interrupted event updates and rewrite the daily stats rollups of every day
+that still has events, e.g. after rollups were corrupted or event files were edited by hand.
+Run it while the server is stopped. A running server rebuilds its in-memory indices too
+through POST /api/v1/admin/reindex, which is also the way to reindex S3 event storage.`,
+	Args: cobra.NoArgs,
+	RunE: runReindex,
+}
+
+// init registers the reindex subcommand and its flags.
+func init() {
+	reindexCmd.Flags().String("data-dir", "/data", "Base data directory for all user data")
+	reindexCmd.Flags().String("cold-data-dir", "", "Secondary data directory old events were moved to (empty = disabled)")
+	reindexCmd.Flags().String("encryption-key", "", "AES-256 key (base64 or hex) the event files are encrypted with")
+	reindexCmd.Flags().String("encryption-key-file", "", "File containing the encryption key")
+
+	rootCmd.AddCommand(reindexCmd)
+}
+
+// runReindex rebuilds the rollups and prints the progress per client.
+func runReindex(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	if err := viper.BindPFlags(cmd.Flags()); err != nil {
+		return err
+	}
+
+	dataDir := viper.GetString("data-dir")
+	cipher, err := encryption.LoadCipher(viper.GetString("encryption-key"), viper.GetString("encryption-key-file"))
+	if err != nil {
+		return fmt.Errorf("failed to load encryption key: %w", err)
+	}
+	clientRepo, err := repository.NewFileClientRepository(dataDir)
+	if err != nil {
+		return fmt.Errorf("failed to open data directory: %w", err)
+	}
+	eventRepo := repository.NewFileEventRepository(dataDir)
+	eventRepo.SetCipher(cipher)
+	quotaRepo := repository.NewFileQuotaRepository(dataDir, 0, 0)
+	if coldDir := viper.GetString("cold-data-dir"); coldDir != "" {
+		eventRepo.SetColdDir(coldDir)
+		quotaRepo.SetColdDir(coldDir)
+	}
+
+	recovered, err := eventRepo.RecoverJournals()
+	if err != nil {
+		return fmt.Errorf("failed to recover interrupted event updates: %w", err)
+	}
+	if recovered > 0 {
+		fmt.Printf("Recovered %d interrupted event updates\n", recovered)
+	}
+
+	log := logger.New()
+	statsService := service.NewStatsService(clientRepo, eventRepo, repository.NewFileStatsRepository(dataDir), quotaRepo, log)
+	// Rebuilds run synchronously, without jobs
+	reindexService := service.NewReindexService(clientRepo, quotaRepo, statsService, nil, log)
+	report, err := reindexService.Run(func(processed, total int) {
+		fmt.Printf("Reindexed %d/%d clients\n", processed, total)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, failure := range report.Failed {
+		fmt.Printf("Failed to reindex client %s: %s\n", failure.ClientID, failure.Error)
+	}
+	fmt.Printf("\n%d clients reindexed, %d daily rollups written, %d failed\n", report.Clients, report.StatsDays, len(report.Failed))
+
+	if len(report.Failed) > 0 {
+		return fmt.Errorf("%d clients could not be reindexed", len(report.Failed))
+	}
+	return nil
+}
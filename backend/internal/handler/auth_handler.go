@@ -8,8 +8,13 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"fmt"
 	"net/http"
+	"net/url"
+	"slices"
 
+	"github.com/lazycatapps/gosmee/backend/internal/middleware"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
 	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
 	"github.com/lazycatapps/gosmee/backend/internal/service"
 	"github.com/lazycatapps/gosmee/backend/internal/types"
@@ -19,123 +24,224 @@ import (
 	"golang.org/x/oauth2"
 )
 
+// defaultProviderID identifies the identity provider configured with the --oidc-* flags.
+// Its users keep their plain subject as user ID.
+const defaultProviderID = "default"
+
 // AuthHandler handles OIDC authentication requests.
 type AuthHandler struct {
 	config         *types.OIDCConfig
 	sessionService *service.SessionService
-	provider       *oidc.Provider
-	oauth2Config   *oauth2.Config
+	authorizer     *middleware.Authorizer // Determines the roles reported by UserInfo
+	providers      []*identityProvider    // Default provider first
 	log            logger.Logger
 }
 
+// identityProvider is an OIDC provider users can log in with.
+type identityProvider struct {
+	config       types.OIDCProviderConfig
+	provider     *oidc.Provider
+	oauth2Config *oauth2.Config
+}
+
+// userID returns the user ID of a subject of the provider. Subjects of additional providers
+// are namespaced with the provider ID, since subjects are only unique per issuer.
+func (p *identityProvider) userID(subject string) string {
+	if p.config.ID == defaultProviderID {
+		return subject
+	}
+	return p.config.ID + ":" + subject
+}
+
 // NewAuthHandler creates a new auth handler.
-func NewAuthHandler(cfg *types.OIDCConfig, sessionService *service.SessionService, log logger.Logger) (*AuthHandler, error) {
+func NewAuthHandler(cfg *types.OIDCConfig, sessionService *service.SessionService, authorizer *middleware.Authorizer, log logger.Logger) (*AuthHandler, error) {
+	h := &AuthHandler{
+		config:         cfg,
+		sessionService: sessionService,
+		authorizer:     authorizer,
+		log:            log,
+	}
+
 	// If OIDC is not enabled, return handler without initialization
 	if !cfg.Enabled {
-		return &AuthHandler{
-			config:         cfg,
-			sessionService: sessionService,
-			log:            log,
-		}, nil
+		return h, nil
 	}
 
-	// Initialize OIDC provider
+	var configs []types.OIDCProviderConfig
+	if cfg.ClientID != "" && cfg.Issuer != "" {
+		configs = append(configs, types.OIDCProviderConfig{
+			ID:           defaultProviderID,
+			Name:         "Default",
+			Issuer:       cfg.Issuer,
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			Scopes:       cfg.Scopes,
+			PKCE:         cfg.PKCE,
+		})
+	}
+	configs = append(configs, cfg.Providers...)
+
+	// Initialize OIDC providers
 	ctx := context.Background()
-	provider, err := oidc.NewProvider(ctx, cfg.Issuer)
-	if err != nil {
-		return nil, err
+	for _, providerConfig := range configs {
+		provider, err := oidc.NewProvider(ctx, providerConfig.Issuer)
+		if err != nil {
+			return nil, fmt.Errorf("identity provider %s: %w", providerConfig.ID, err)
+		}
+
+		// Configure OAuth2
+		h.providers = append(h.providers, &identityProvider{
+			config:   providerConfig,
+			provider: provider,
+			oauth2Config: &oauth2.Config{
+				ClientID:     providerConfig.ClientID,
+				ClientSecret: providerConfig.ClientSecret,
+				RedirectURL:  cfg.RedirectURL,
+				Endpoint:     provider.Endpoint(),
+				Scopes:       oidcScopes(providerConfig.Scopes),
+			},
+		})
 	}
 
-	// Configure OAuth2
-	oauth2Config := &oauth2.Config{
-		ClientID:     cfg.ClientID,
-		ClientSecret: cfg.ClientSecret,
-		RedirectURL:  cfg.RedirectURL,
-		Endpoint:     provider.Endpoint(),
-		Scopes:       []string{oidc.ScopeOpenID, "profile", "email", "groups"},
+	return h, nil
+}
+
+// findProvider returns the identity provider with the given ID, or the default one for an
+// empty ID.
+func (h *AuthHandler) findProvider(id string) (*identityProvider, bool) {
+	for _, provider := range h.providers {
+		if id == "" || provider.config.ID == id {
+			return provider, true
+		}
 	}
+	return nil, false
+}
 
-	return &AuthHandler{
-		config:         cfg,
-		sessionService: sessionService,
-		provider:       provider,
-		oauth2Config:   oauth2Config,
-		log:            log,
-	}, nil
+// Providers lists the identity providers users can log in with.
+// GET /api/v1/auth/providers
+func (h *AuthHandler) Providers(c *gin.Context) {
+	providers := []gin.H{}
+	for _, provider := range h.providers {
+		providers = append(providers, gin.H{
+			"id":       provider.config.ID,
+			"name":     provider.config.Name,
+			"loginUrl": "/api/v1/auth/login?provider=" + url.QueryEscape(provider.config.ID),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"providers": providers})
 }
 
 // Login redirects to OIDC provider for authentication.
+// The provider is selected with ?provider=<id> (default: the first configured one).
 func (h *AuthHandler) Login(c *gin.Context) {
 	if !h.config.Enabled {
-		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "OIDC authentication is not enabled"})
+		respondError(c, apperrors.ErrOIDCDisabled)
+		return
+	}
+
+	provider, ok := h.findProvider(c.Query("provider"))
+	if !ok {
+		respondError(c, apperrors.NewInvalidInput("unknown identity provider: "+c.Query("provider")))
 		return
 	}
 
 	// Generate random state
 	state, err := generateState()
 	if err != nil {
-		h.log.Error("Failed to generate state: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate state"})
+		requestLog(c, h.log).Error("Failed to generate state: %v", err)
+		respondError(c, apperrors.NewAuthFailed("Failed to generate state", http.StatusInternalServerError))
 		return
 	}
 
-	// Store state in cookie for verification
+	// Store state and provider in cookies for verification
 	c.SetCookie("oauth_state", state, 600, "/", "", true, true)
+	c.SetCookie("oauth_provider", provider.config.ID, 600, "/", "", true, true)
+
+	// With PKCE, keep the code verifier for the token exchange and send its challenge
+	var opts []oauth2.AuthCodeOption
+	if provider.config.PKCE {
+		verifier := oauth2.GenerateVerifier()
+		c.SetCookie("oauth_verifier", verifier, 600, "/", "", true, true)
+		opts = append(opts, oauth2.S256ChallengeOption(verifier))
+	}
 
 	// Redirect to OIDC provider
-	authURL := h.oauth2Config.AuthCodeURL(state)
+	authURL := provider.oauth2Config.AuthCodeURL(state, opts...)
 	c.Redirect(http.StatusFound, authURL)
 }
 
 // Callback handles the OIDC callback.
 func (h *AuthHandler) Callback(c *gin.Context) {
 	if !h.config.Enabled {
-		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "OIDC authentication is not enabled"})
+		respondError(c, apperrors.ErrOIDCDisabled)
 		return
 	}
 
 	// Verify state
 	stateCookie, err := c.Cookie("oauth_state")
 	if err != nil {
-		h.log.Error("Missing state cookie: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing state"})
+		requestLog(c, h.log).Error("Missing state cookie: %v", err)
+		respondError(c, apperrors.NewAuthFailed("Missing state", http.StatusBadRequest))
 		return
 	}
 
 	state := c.Query("state")
 	if state != stateCookie {
-		h.log.Error("State mismatch: expected %s, got %s", stateCookie, state)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "State mismatch"})
+		requestLog(c, h.log).Error("State mismatch: expected %s, got %s", stateCookie, state)
+		respondError(c, apperrors.NewAuthFailed("State mismatch", http.StatusBadRequest))
 		return
 	}
 
 	// Clear state cookie
 	c.SetCookie("oauth_state", "", -1, "/", "", true, true)
 
+	// Logins started before multiple providers were configured have no provider cookie
+	providerID, _ := c.Cookie("oauth_provider")
+	c.SetCookie("oauth_provider", "", -1, "/", "", true, true)
+	provider, ok := h.findProvider(providerID)
+	if !ok {
+		requestLog(c, h.log).Error("Unknown identity provider: %s", providerID)
+		respondError(c, apperrors.NewAuthFailed("Unknown identity provider", http.StatusBadRequest))
+		return
+	}
+
+	var opts []oauth2.AuthCodeOption
+	if provider.config.PKCE {
+		verifier, err := c.Cookie("oauth_verifier")
+		if err != nil || verifier == "" {
+			requestLog(c, h.log).Error("Missing PKCE verifier cookie: %v", err)
+			respondError(c, apperrors.NewAuthFailed("Missing PKCE verifier", http.StatusBadRequest))
+			return
+		}
+		c.SetCookie("oauth_verifier", "", -1, "/", "", true, true)
+		opts = append(opts, oauth2.VerifierOption(verifier))
+	}
+
 	// Exchange code for token
 	code := c.Query("code")
 	ctx := context.Background()
-	oauth2Token, err := h.oauth2Config.Exchange(ctx, code)
+	oauth2Token, err := provider.oauth2Config.Exchange(ctx, code, opts...)
 	if err != nil {
-		h.log.Error("Failed to exchange token: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to exchange token"})
+		requestLog(c, h.log).Error("Failed to exchange token: %v", err)
+		respondError(c, apperrors.NewAuthFailed("Failed to exchange token", http.StatusInternalServerError))
 		return
 	}
 
 	// Extract ID token
 	rawIDToken, ok := oauth2Token.Extra("id_token").(string)
 	if !ok {
-		h.log.Error("No id_token in token response")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "No id_token"})
+		requestLog(c, h.log).Error("No id_token in token response")
+		respondError(c, apperrors.NewAuthFailed("No id_token", http.StatusInternalServerError))
 		return
 	}
 
 	// Verify ID token
-	verifier := h.provider.Verifier(&oidc.Config{ClientID: h.config.ClientID})
+	verifier := provider.provider.Verifier(&oidc.Config{ClientID: provider.config.ClientID})
 	idToken, err := verifier.Verify(ctx, rawIDToken)
 	if err != nil {
-		h.log.Error("Failed to verify ID token: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify token"})
+		requestLog(c, h.log).Error("Failed to verify ID token: %v", err)
+		respondError(c, apperrors.NewAuthFailed("Failed to verify token", http.StatusInternalServerError))
 		return
 	}
 
@@ -146,23 +252,24 @@ func (h *AuthHandler) Callback(c *gin.Context) {
 		Groups []string `json:"groups"`
 	}
 	if err := idToken.Claims(&claims); err != nil {
-		h.log.Error("Failed to extract claims: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to extract claims"})
+		requestLog(c, h.log).Error("Failed to extract claims: %v", err)
+		respondError(c, apperrors.NewAuthFailed("Failed to extract claims", http.StatusInternalServerError))
 		return
 	}
 
 	// Create session
-	sessionID, err := h.sessionService.CreateSession(claims.Sub, claims.Email, claims.Groups)
+	userID := provider.userID(claims.Sub)
+	sessionID, err := h.sessionService.CreateSession(userID, claims.Email, claims.Groups, c.ClientIP(), c.Request.UserAgent())
 	if err != nil {
-		h.log.Error("Failed to create session: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create session"})
+		requestLog(c, h.log).Error("Failed to create session: %v", err)
+		respondError(c, apperrors.NewAuthFailed("Failed to create session", http.StatusInternalServerError))
 		return
 	}
 
 	// Set session cookie
 	c.SetCookie("session", sessionID, 86400*7, "/", "", true, true)
 
-	h.log.Info("User authenticated: %s (%s)", claims.Email, claims.Sub)
+	requestLog(c, h.log).Info("User authenticated via %s: %s (%s)", provider.config.ID, claims.Email, userID)
 
 	// Redirect to home page
 	c.Redirect(http.StatusFound, "/")
@@ -182,6 +289,75 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
 }
 
+// ListSessions returns the active sessions of the current user.
+// GET /api/v1/auth/sessions
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	session, ok := h.currentSession(c)
+	if !ok {
+		return
+	}
+
+	sessionCookie, _ := c.Cookie("session")
+	c.JSON(http.StatusOK, h.sessionService.ListSessions(session.UserID, sessionCookie))
+}
+
+// RevokeSession logs the current user out of one of their sessions.
+// DELETE /api/v1/auth/sessions/:sessionId
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	session, ok := h.currentSession(c)
+	if !ok {
+		return
+	}
+
+	if err := h.sessionService.RevokeSession(session.UserID, c.Param("sessionId")); err != nil {
+		respondError(c, err)
+		return
+	}
+	if c.Param("sessionId") == session.ID {
+		c.SetCookie("session", "", -1, "/", "", true, true)
+	}
+
+	requestLog(c, h.log).Info("User %s revoked session %s", session.UserID, c.Param("sessionId"))
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked successfully"})
+}
+
+// RevokeAllSessions logs the current user out everywhere, or only of their other sessions
+// with ?keepCurrent=true.
+// DELETE /api/v1/auth/sessions
+func (h *AuthHandler) RevokeAllSessions(c *gin.Context) {
+	session, ok := h.currentSession(c)
+	if !ok {
+		return
+	}
+
+	keep := ""
+	if c.Query("keepCurrent") == "true" {
+		keep, _ = c.Cookie("session")
+	} else {
+		c.SetCookie("session", "", -1, "/", "", true, true)
+	}
+	revoked := h.sessionService.RevokeUserSessions(session.UserID, keep)
+
+	requestLog(c, h.log).Info("User %s revoked %d sessions", session.UserID, revoked)
+	c.JSON(http.StatusOK, gin.H{"message": "Sessions revoked successfully", "revoked": revoked})
+}
+
+// currentSession returns the session the request is authenticated with, responding with
+// an error if there is none (OIDC disabled or service account token).
+func (h *AuthHandler) currentSession(c *gin.Context) (*service.SessionInfo, bool) {
+	if !h.config.Enabled {
+		respondError(c, apperrors.ErrOIDCDisabled)
+		return nil, false
+	}
+	if value, ok := c.Get("session"); ok {
+		if session, ok := value.(*service.SessionInfo); ok {
+			return session, true
+		}
+	}
+	respondError(c, apperrors.New(apperrors.CodeUnauthorized, "Session authentication required", http.StatusUnauthorized))
+	return nil, false
+}
+
 // UserInfo returns current user information.
 func (h *AuthHandler) UserInfo(c *gin.Context) {
 	if !h.config.Enabled {
@@ -212,25 +388,32 @@ func (h *AuthHandler) UserInfo(c *gin.Context) {
 		return
 	}
 
-	// Check if user is admin
-	isAdmin := false
-	for _, group := range session.Groups {
-		if group == "ADMIN" {
-			isAdmin = true
-			break
-		}
-	}
-
+	roles := h.authorizer.SessionRoles(session.Groups)
 	c.JSON(http.StatusOK, gin.H{
 		"authenticated": true,
 		"oidc_enabled":  true,
 		"user_id":       session.UserID,
 		"email":         session.Email,
 		"groups":        session.Groups,
-		"is_admin":      isAdmin,
+		"roles":         roles,
+		"is_admin":      slices.Contains(roles, middleware.RoleAdmin),
 	})
 }
 
+// oidcScopes returns the scopes to request, making sure openid is among them since the
+// ID token is only issued for it. No configured scopes request the default set.
+func oidcScopes(scopes []string) []string {
+	if len(scopes) == 0 {
+		return []string{oidc.ScopeOpenID, "profile", "email", "groups"}
+	}
+	for _, scope := range scopes {
+		if scope == oidc.ScopeOpenID {
+			return scopes
+		}
+	}
+	return append([]string{oidc.ScopeOpenID}, scopes...)
+}
+
 // generateState generates a random state string for CSRF protection.
 func generateState() (string, error) {
 	b := make([]byte, 32)
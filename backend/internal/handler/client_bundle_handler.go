@@ -0,0 +1,72 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package handler
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+// ClientBundleHandler handles HTTP requests exporting and importing single-client bundles.
+type ClientBundleHandler struct {
+	bundleService *service.ClientBundleService
+	log           logger.Logger
+}
+
+// NewClientBundleHandler creates a new client bundle handler.
+func NewClientBundleHandler(bundleService *service.ClientBundleService, log logger.Logger) *ClientBundleHandler {
+	return &ClientBundleHandler{
+		bundleService: bundleService,
+		log:           log,
+	}
+}
+
+// Export downloads a zip bundle of a client's configuration, events and logs.
+// GET /api/v1/clients/:id/bundle
+func (h *ClientBundleHandler) Export(c *gin.Context) {
+	clientID := c.Param("id")
+
+	data, _, err := h.bundleService.Export(clientID)
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to export client bundle: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("gosmee-client-%s-%s.zip", clientID, time.Now().Format("20060102"))
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	c.Data(http.StatusOK, "application/zip", data)
+}
+
+// Import recreates the client of an uploaded bundle (the request body) under the current
+// user with a fresh ID.
+// POST /api/v1/clients/import-bundle
+func (h *ClientBundleHandler) Import(c *gin.Context) {
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+	if len(data) == 0 {
+		respondError(c, apperrors.NewInvalidInput("the request body must be a client bundle (zip)"))
+		return
+	}
+
+	result, err := h.bundleService.Import(getUserID(c), data)
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to import client bundle: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
@@ -4,10 +4,16 @@
 package handler
 
 import (
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
 	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
 	"github.com/lazycatapps/gosmee/backend/internal/service"
 )
@@ -32,12 +38,39 @@ func NewClientHandler(
 	}
 }
 
+// RequireOwner ensures the client in the :id path parameter exists and belongs to the current user.
+// The parameter may be the client's ID or slug; a slug is replaced by the client ID, so the
+// handlers behind it always see the ID. Used as middleware for all client-scoped routes.
+func (h *ClientHandler) RequireOwner(c *gin.Context) {
+	client, err := h.clientService.Resolve(getUserID(c), c.Param("id"))
+	if err != nil {
+		respondError(c, apperrors.ErrClientNotFound)
+		c.Abort()
+		return
+	}
+
+	if client.UserID != getUserID(c) {
+		requestLog(c, h.log).Info("User %s denied access to client %s", getUserID(c), client.ID)
+		respondError(c, apperrors.ErrNotOwner)
+		c.Abort()
+		return
+	}
+
+	for i := range c.Params {
+		if c.Params[i].Key == "id" {
+			c.Params[i].Value = client.ID
+		}
+	}
+
+	c.Next()
+}
+
 // Create creates a new client instance.
 // POST /api/v1/clients
 func (h *ClientHandler) Create(c *gin.Context) {
 	var req models.ClientRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondInvalidInput(c, err)
 		return
 	}
 
@@ -47,11 +80,16 @@ func (h *ClientHandler) Create(c *gin.Context) {
 	// Create client
 	client, err := h.clientService.Create(userID, &req)
 	if err != nil {
-		h.log.Error("Failed to create client: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		requestLog(c, h.log).Error("Failed to create client: %v", err)
+		respondError(c, err)
 		return
 	}
 
+	// Creating clients beyond the soft limit is allowed, but the caller is told
+	if warning, _ := h.quotaService.GetClientsWarning(userID); warning != "" {
+		c.Header("X-Quota-Warning", warning)
+	}
+
 	c.JSON(http.StatusCreated, client)
 }
 
@@ -60,33 +98,86 @@ func (h *ClientHandler) Create(c *gin.Context) {
 func (h *ClientHandler) List(c *gin.Context) {
 	var req models.ClientListRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondInvalidInput(c, err)
 		return
 	}
 
-	// Set defaults
-	if req.Page == 0 {
-		req.Page = 1
+	if err := validateListQuery(clientListLimits, req.Page, req.PageSize, req.SortBy, req.SortOrder); err != nil {
+		respondError(c, err)
+		return
 	}
-	if req.PageSize == 0 {
-		req.PageSize = 20
+
+	userID := getUserID(c)
+
+	response, err := h.clientService.List(userID, &req)
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to list clients: %v", err)
+		respondError(c, err)
+		return
 	}
-	if req.PageSize > 100 {
-		req.PageSize = 100
+
+	response.Next, response.Prev = paginationLinks(c, response.Total, response.Page, response.PageSize)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Export exports the user's clients for review in spreadsheets.
+// GET /api/v1/clients/export?format=csv
+func (h *ClientHandler) Export(c *gin.Context) {
+	var req models.ClientExportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondInvalidInput(c, err)
+		return
 	}
 
 	userID := getUserID(c)
 
-	response, err := h.clientService.List(userID, &req)
+	data, err := h.clientService.ExportCSV(userID)
 	if err != nil {
-		h.log.Error("Failed to list clients: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		requestLog(c, h.log).Error("Failed to export clients: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("gosmee-clients-%s.csv", time.Now().Format("20060102"))
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", data)
+}
+
+// Validate runs the checks of creating a client without creating it, for feedback while
+// the client form is filled in. Failed checks are reported with 200 OK and valid set to false.
+// POST /api/v1/clients/validate
+func (h *ClientHandler) Validate(c *gin.Context) {
+	var req models.ClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+
+	response, err := h.clientService.Validate(getUserID(c), &req)
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to validate client: %v", err)
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+// IgnoreEventPresets returns presets of commonly ignored events and the known event types
+// of each provider.
+// GET /api/v1/clients/ignore-event-presets?provider=github
+func (h *ClientHandler) IgnoreEventPresets(c *gin.Context) {
+	var req models.IgnoreEventPresetsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, h.clientService.IgnoreEventPresets(req.Provider))
+}
+
 // Get retrieves a single client by ID.
 // GET /api/v1/clients/:id
 func (h *ClientHandler) Get(c *gin.Context) {
@@ -94,13 +185,12 @@ func (h *ClientHandler) Get(c *gin.Context) {
 
 	client, err := h.clientService.Get(clientID)
 	if err != nil {
-		h.log.Error("Failed to get client: %v", err)
-		c.JSON(http.StatusNotFound, gin.H{"error": "Client not found"})
+		requestLog(c, h.log).Error("Failed to get client: %v", err)
+		respondError(c, apperrors.ErrClientNotFound)
 		return
 	}
 
-	// TODO: Check if user owns this client
-
+	setClientETag(c, client)
 	c.JSON(http.StatusOK, client)
 }
 
@@ -111,28 +201,92 @@ func (h *ClientHandler) Update(c *gin.Context) {
 
 	var req models.ClientRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondInvalidInput(c, err)
+		return
+	}
+	if req.Version == 0 {
+		version, err := ifMatchVersion(c)
+		if err != nil {
+			respondInvalidInput(c, err)
+			return
+		}
+		req.Version = version
+	}
+
+	applyNow := c.Query("applyNow") == "true"
+
+	client, err := h.clientService.Update(clientID, &req, applyNow)
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to update client: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	setClientETag(c, client)
+	c.JSON(http.StatusOK, client)
+}
+
+// Patch partially updates a client instance, changing only the fields present in the request.
+// PATCH /api/v1/clients/:id
+func (h *ClientHandler) Patch(c *gin.Context) {
+	clientID := c.Param("id")
+
+	var req models.ClientPatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidInput(c, err)
 		return
 	}
+	if req.Version == 0 {
+		version, err := ifMatchVersion(c)
+		if err != nil {
+			respondInvalidInput(c, err)
+			return
+		}
+		req.Version = version
+	}
+
+	applyNow := c.Query("applyNow") == "true"
 
-	client, err := h.clientService.Update(clientID, &req)
+	client, err := h.clientService.Patch(clientID, &req, applyNow)
 	if err != nil {
-		h.log.Error("Failed to update client: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		requestLog(c, h.log).Error("Failed to patch client: %v", err)
+		respondError(c, err)
 		return
 	}
 
+	setClientETag(c, client)
 	c.JSON(http.StatusOK, client)
 }
 
+// setClientETag returns the configuration version of a client as entity tag, for If-Match
+// preconditions on updates.
+func setClientETag(c *gin.Context, client *models.Client) {
+	c.Header("ETag", fmt.Sprintf("%q", strconv.Itoa(client.Version)))
+}
+
+// ifMatchVersion returns the client version required by the If-Match header (0 = none).
+func ifMatchVersion(c *gin.Context) (int, error) {
+	ifMatch := strings.TrimSpace(c.GetHeader("If-Match"))
+	if ifMatch == "" || ifMatch == "*" {
+		return 0, nil
+	}
+
+	tag := strings.Trim(strings.TrimPrefix(ifMatch, "W/"), `"`)
+	version, err := strconv.Atoi(tag)
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("invalid If-Match header: %s", ifMatch)
+	}
+	return version, nil
+}
+
 // Delete deletes a client instance.
 // DELETE /api/v1/clients/:id
 func (h *ClientHandler) Delete(c *gin.Context) {
 	clientID := c.Param("id")
 
 	if err := h.clientService.Delete(clientID); err != nil {
-		h.log.Error("Failed to delete client: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		requestLog(c, h.log).Error("Failed to delete client: %v", err)
+		respondError(c, err)
 		return
 	}
 
@@ -145,8 +299,8 @@ func (h *ClientHandler) Start(c *gin.Context) {
 	clientID := c.Param("id")
 
 	if err := h.clientService.Start(clientID); err != nil {
-		h.log.Error("Failed to start client: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		requestLog(c, h.log).Error("Failed to start client: %v", err)
+		respondError(c, err)
 		return
 	}
 
@@ -154,13 +308,19 @@ func (h *ClientHandler) Start(c *gin.Context) {
 }
 
 // Stop stops a client instance.
-// POST /api/v1/clients/:id/stop
+// POST /api/v1/clients/:id/stop?force=true&timeout=30
 func (h *ClientHandler) Stop(c *gin.Context) {
 	clientID := c.Param("id")
 
-	if err := h.clientService.Stop(clientID); err != nil {
-		h.log.Error("Failed to stop client: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	var req models.ClientStopRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+
+	if err := h.clientService.StopWith(clientID, &req); err != nil {
+		requestLog(c, h.log).Error("Failed to stop client: %v", err)
+		respondError(c, err)
 		return
 	}
 
@@ -173,25 +333,76 @@ func (h *ClientHandler) Restart(c *gin.Context) {
 	clientID := c.Param("id")
 
 	if err := h.clientService.Restart(clientID); err != nil {
-		h.log.Error("Failed to restart client: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		requestLog(c, h.log).Error("Failed to restart client: %v", err)
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Client restarted successfully"})
 }
 
+// Pause pauses event forwarding of a client (events are still received and saved).
+// POST /api/v1/clients/:id/pause
+func (h *ClientHandler) Pause(c *gin.Context) {
+	clientID := c.Param("id")
+
+	client, err := h.clientService.Pause(clientID)
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to pause client: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, client)
+}
+
+// Resume resumes event forwarding of a paused client.
+// POST /api/v1/clients/:id/resume
+func (h *ClientHandler) Resume(c *gin.Context) {
+	clientID := c.Param("id")
+
+	client, err := h.clientService.Resume(clientID)
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to resume client: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, client)
+}
+
+// RollingRestart restarts running clients batch by batch as an asynchronous job.
+// POST /api/v1/clients/batch/rolling-restart
+func (h *ClientHandler) RollingRestart(c *gin.Context) {
+	var req models.ClientRollingRestartRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		respondInvalidInput(c, err)
+		return
+	}
+
+	userID := getUserID(c)
+
+	job, err := h.clientService.RollingRestart(userID, &req)
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to start rolling restart: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
 // BatchStart starts multiple clients.
 // POST /api/v1/clients/batch/start
 func (h *ClientHandler) BatchStart(c *gin.Context) {
 	var req models.ClientBatchRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondInvalidInput(c, err)
 		return
 	}
 
 	if !req.All && len(req.ClientIDs) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "clientIds cannot be empty"})
+		respondError(c, apperrors.NewInvalidInput("clientIds cannot be empty"))
 		return
 	}
 
@@ -199,8 +410,8 @@ func (h *ClientHandler) BatchStart(c *gin.Context) {
 
 	response, err := h.clientService.BatchStart(userID, &req)
 	if err != nil {
-		h.log.Error("Failed to batch start clients: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		requestLog(c, h.log).Error("Failed to batch start clients: %v", err)
+		respondError(c, err)
 		return
 	}
 
@@ -212,12 +423,12 @@ func (h *ClientHandler) BatchStart(c *gin.Context) {
 func (h *ClientHandler) BatchStop(c *gin.Context) {
 	var req models.ClientBatchRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondInvalidInput(c, err)
 		return
 	}
 
 	if !req.All && len(req.ClientIDs) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "clientIds cannot be empty"})
+		respondError(c, apperrors.NewInvalidInput("clientIds cannot be empty"))
 		return
 	}
 
@@ -225,23 +436,25 @@ func (h *ClientHandler) BatchStop(c *gin.Context) {
 
 	response, err := h.clientService.BatchStop(userID, &req)
 	if err != nil {
-		h.log.Error("Failed to batch stop clients: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		requestLog(c, h.log).Error("Failed to batch stop clients: %v", err)
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
-// GetStats retrieves statistics for a client.
+// GetStats retrieves a statistics snapshot of a client.
 // GET /api/v1/clients/:id/stats
+//
+// Deprecated: use GET /api/v1/clients/:id/stats/query, which reports statistics over time.
 func (h *ClientHandler) GetStats(c *gin.Context) {
 	clientID := c.Param("id")
 
 	stats, err := h.clientService.GetStats(clientID)
 	if err != nil {
-		h.log.Error("Failed to get client stats: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		requestLog(c, h.log).Error("Failed to get client stats: %v", err)
+		respondError(c, err)
 		return
 	}
 
@@ -256,3 +469,17 @@ func getUserID(c *gin.Context) string {
 	}
 	return userID.(string)
 }
+
+// ListUnmanagedProcesses lists the gosmee processes saving events to the data directory that
+// the backend doesn't manage.
+// GET /api/v1/admin/processes/unmanaged
+func (h *ClientHandler) ListUnmanagedProcesses(c *gin.Context) {
+	processes, err := h.clientService.UnmanagedProcesses()
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to scan for unmanaged processes: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"processes": processes})
+}
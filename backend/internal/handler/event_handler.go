@@ -4,10 +4,15 @@
 package handler
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
 	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
 	"github.com/lazycatapps/gosmee/backend/internal/service"
 )
@@ -26,6 +31,48 @@ func NewEventHandler(eventService *service.EventService, log logger.Logger) *Eve
 	}
 }
 
+// StreamEvents streams the summaries of a client's new events via SSE.
+// GET /api/v1/clients/:id/events/stream
+func (h *EventHandler) StreamEvents(c *gin.Context) {
+	clientID := c.Param("id")
+
+	events, cancel, err := h.eventService.Subscribe(clientID)
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to start event stream: %v", err)
+		respondError(c, err)
+		return
+	}
+	defer cancel()
+
+	// Set SSE headers
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("Transfer-Encoding", "chunked")
+	c.Header("X-Accel-Buffering", "no")
+
+	heartbeat := time.NewTicker(logStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event := <-events:
+			data, err := json.Marshal(event)
+			if err != nil {
+				return false
+			}
+			fmt.Fprintf(w, "id: %s\nevent: event\ndata: %s\n\n", event.ID, data)
+			return true
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			return true
+		case <-c.Request.Context().Done():
+			// Client disconnected
+			return false
+		}
+	})
+}
+
 // List retrieves events for a client.
 // GET /api/v1/clients/:id/events
 func (h *EventHandler) List(c *gin.Context) {
@@ -33,62 +80,220 @@ func (h *EventHandler) List(c *gin.Context) {
 
 	var req models.EventListRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondInvalidInput(c, err)
 		return
 	}
 
-	// Set defaults
-	if req.Page == 0 {
-		req.Page = 1
-	}
-	if req.PageSize == 0 {
-		req.PageSize = 20
-	}
-	if req.PageSize > 100 {
-		req.PageSize = 100
+	if err := validateListQuery(eventListLimits, req.Page, req.PageSize, req.SortBy, req.SortOrder); err != nil {
+		respondError(c, err)
+		return
 	}
 
 	response, err := h.eventService.List(clientID, &req)
 	if err != nil {
-		h.log.Error("Failed to list events: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		requestLog(c, h.log).Error("Failed to list events: %v", err)
+		respondError(c, err)
 		return
 	}
 
+	response.Next, response.Prev = paginationLinks(c, response.Total, response.Page, response.PageSize)
+
 	c.JSON(http.StatusOK, response)
 }
 
-// Get retrieves a single event.
+// Get retrieves a single event, or only the fields selected with ?fields= or ?omit=.
 // GET /api/v1/clients/:id/events/:eventId
 func (h *EventHandler) Get(c *gin.Context) {
 	clientID := c.Param("id")
 	eventID := c.Param("eventId")
 
+	var req models.EventDetailRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+
 	event, err := h.eventService.Get(clientID, eventID)
 	if err != nil {
-		h.log.Error("Failed to get event: %v", err)
-		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		requestLog(c, h.log).Error("Failed to get event: %v", err)
+		respondError(c, apperrors.ErrEventNotFound)
 		return
 	}
 
+	if req.Sparse() {
+		c.JSON(http.StatusOK, event.SelectFields(&req))
+		return
+	}
 	c.JSON(http.StatusOK, event)
 }
 
+// Download returns a zip bundle of an event, its replay script and a README.
+// GET /api/v1/clients/:id/events/:eventId/download
+func (h *EventHandler) Download(c *gin.Context) {
+	clientID := c.Param("id")
+	eventID := c.Param("eventId")
+
+	data, err := h.eventService.Bundle(clientID, eventID)
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to bundle event: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("gosmee-event-%s.zip", eventID)
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	c.Data(http.StatusOK, "application/zip", data)
+}
+
+// RegenerateScript regenerates the replay script of an event for the current target URL.
+// POST /api/v1/clients/:id/events/:eventId/script
+func (h *EventHandler) RegenerateScript(c *gin.Context) {
+	clientID := c.Param("id")
+	eventID := c.Param("eventId")
+
+	var req models.EventScriptRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondInvalidInput(c, err)
+			return
+		}
+	}
+
+	response, err := h.eventService.RegenerateScript(clientID, eventID, &req)
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to regenerate replay script: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
 // Delete deletes an event.
 // DELETE /api/v1/clients/:id/events/:eventId
 func (h *EventHandler) Delete(c *gin.Context) {
 	clientID := c.Param("id")
 	eventID := c.Param("eventId")
 
-	if err := h.eventService.Delete(clientID, eventID); err != nil {
-		h.log.Error("Failed to delete event: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	var req models.EventDeleteRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+
+	if err := h.eventService.Delete(clientID, eventID, &req); err != nil {
+		requestLog(c, h.log).Error("Failed to delete event: %v", err)
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Event deleted successfully"})
 }
 
+// ReplayFailed replays all failed events in a date range as an asynchronous job.
+// POST /api/v1/clients/:id/events/replay-failed
+func (h *EventHandler) ReplayFailed(c *gin.Context) {
+	clientID := c.Param("id")
+
+	var req models.EventReplayFailedRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+
+	userID := getUserID(c)
+
+	job, err := h.eventService.ReplayFailed(userID, clientID, &req)
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to replay failed events: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// Deduplicate deletes redelivered events, keeping the earliest event of each fingerprint.
+// POST /api/v1/clients/:id/events/deduplicate
+func (h *EventHandler) Deduplicate(c *gin.Context) {
+	clientID := c.Param("id")
+
+	var req models.EventDeduplicateRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+
+	response, err := h.eventService.Deduplicate(clientID, &req)
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to deduplicate events: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// FindByDelivery locates the events of the user's clients with a provider delivery ID.
+// GET /api/v1/events/by-delivery/:deliveryId
+func (h *EventHandler) FindByDelivery(c *gin.Context) {
+	response, err := h.eventService.FindByDelivery(getUserID(c), c.Param("deliveryId"))
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to find events by delivery ID: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// AckReport reports the delivered events the target never acknowledged.
+// GET /api/v1/clients/:id/events/unacknowledged
+func (h *EventHandler) AckReport(c *gin.Context) {
+	clientID := c.Param("id")
+
+	var req models.EventAckReportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+
+	report, err := h.eventService.AckReport(clientID, &req)
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to reconcile delivery receipts: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// Inject stores a synthetic event for a client.
+// POST /api/v1/clients/:id/events
+func (h *EventHandler) Inject(c *gin.Context) {
+	clientID := c.Param("id")
+
+	var req models.EventInjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+
+	response, err := h.eventService.Inject(clientID, &req)
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to inject event: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
 // Replay replays events to the target URL.
 // POST /api/v1/clients/:id/events/replay
 func (h *EventHandler) Replay(c *gin.Context) {
@@ -96,16 +301,44 @@ func (h *EventHandler) Replay(c *gin.Context) {
 
 	var req models.EventReplayRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondInvalidInput(c, err)
 		return
 	}
 
 	response, err := h.eventService.Replay(clientID, &req)
 	if err != nil {
-		h.log.Error("Failed to replay events: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		requestLog(c, h.log).Error("Failed to replay events: %v", err)
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, response)
 }
+
+// GetCircuit retrieves the delivery circuit breaker status of a client.
+// GET /api/v1/clients/:id/circuit
+func (h *EventHandler) GetCircuit(c *gin.Context) {
+	clientID := c.Param("id")
+
+	status, err := h.eventService.CircuitStatus(clientID)
+	if err != nil {
+		respondError(c, apperrors.ErrClientNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// ResetCircuit closes the delivery circuit of a client.
+// POST /api/v1/clients/:id/circuit/reset
+func (h *EventHandler) ResetCircuit(c *gin.Context) {
+	clientID := c.Param("id")
+
+	status, err := h.eventService.ResetCircuit(clientID)
+	if err != nil {
+		respondError(c, apperrors.ErrClientNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
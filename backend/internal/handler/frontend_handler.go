@@ -0,0 +1,79 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package handler
+
+import (
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+)
+
+// frontendEnvConfig replaces the build's env-config.js: when served by the backend,
+// the API is on the same origin.
+const frontendEnvConfig = `window._env_ = {
+  BACKEND_API_URL: ""
+};
+`
+
+// FrontendHandler serves the single-page frontend with fallback routing:
+// paths that are not files are answered with index.html, so client-side routes work on reload.
+type FrontendHandler struct {
+	files      fs.FS
+	fileServer http.Handler
+	index      []byte
+}
+
+// NewFrontendHandler creates a new frontend handler for a frontend build containing index.html.
+func NewFrontendHandler(files fs.FS) (*FrontendHandler, error) {
+	index, err := fs.ReadFile(files, "index.html")
+	if err != nil {
+		return nil, err
+	}
+
+	return &FrontendHandler{
+		files:      files,
+		fileServer: http.FileServer(http.FS(files)),
+		index:      index,
+	}, nil
+}
+
+// Serve handles GET/HEAD requests not matched by any API route.
+// GET /*
+func (h *FrontendHandler) Serve(c *gin.Context) {
+	urlPath := c.Request.URL.Path
+	if strings.HasPrefix(urlPath, "/api/") {
+		respondError(c, apperrors.ErrNotFound)
+		return
+	}
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		c.Status(http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(path.Clean(urlPath), "/")
+	switch {
+	case name == "env-config.js":
+		c.Header("Cache-Control", "no-cache")
+		c.Data(http.StatusOK, "application/javascript; charset=utf-8", []byte(frontendEnvConfig))
+	case name != "" && name != "index.html" && h.isFile(name):
+		if strings.HasPrefix(name, "static/") {
+			// Build assets have content hashes in their names
+			c.Header("Cache-Control", "public, max-age=31536000, immutable")
+		}
+		h.fileServer.ServeHTTP(c.Writer, c.Request)
+	default:
+		c.Header("Cache-Control", "no-cache")
+		c.Data(http.StatusOK, "text/html; charset=utf-8", h.index)
+	}
+}
+
+// isFile reports whether name is a regular file of the frontend build.
+func (h *FrontendHandler) isFile(name string) bool {
+	info, err := fs.Stat(h.files, name)
+	return err == nil && !info.IsDir()
+}
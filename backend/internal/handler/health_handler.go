@@ -0,0 +1,47 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+// HealthHandler handles the health check and the storage status of the server.
+type HealthHandler struct {
+	diskWatcher *service.DiskWatcherService
+}
+
+// NewHealthHandler creates a new health handler.
+func NewHealthHandler(diskWatcher *service.DiskWatcherService) *HealthHandler {
+	return &HealthHandler{
+		diskWatcher: diskWatcher,
+	}
+}
+
+// Check returns the health status. The server reports itself degraded while a data volume
+// is low on space; it keeps answering 200 so orchestrators don't restart it for that.
+// GET /api/v1/health
+func (h *HealthHandler) Check(c *gin.Context) {
+	storage := h.diskWatcher.Status().Level
+	status := "healthy"
+	if storage != models.StorageLevelOK {
+		status = "degraded"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  status,
+		"service": "gosmee-webui",
+		"storage": storage,
+	})
+}
+
+// Storage returns the free space of the data volumes as of the latest check.
+// GET /api/v1/admin/storage
+func (h *HealthHandler) Storage(c *gin.Context) {
+	c.JSON(http.StatusOK, h.diskWatcher.Status())
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+// ImpersonationHandler handles the admin API of the impersonation audit log.
+type ImpersonationHandler struct {
+	impersonationService *service.ImpersonationService
+	log                  logger.Logger
+}
+
+// NewImpersonationHandler creates a new impersonation audit log handler.
+func NewImpersonationHandler(impersonationService *service.ImpersonationService, log logger.Logger) *ImpersonationHandler {
+	return &ImpersonationHandler{
+		impersonationService: impersonationService,
+		log:                  log,
+	}
+}
+
+// List returns the requests administrators made on behalf of other users, newest first.
+// GET /api/v1/admin/impersonations
+func (h *ImpersonationHandler) List(c *gin.Context) {
+	var req models.ImpersonationListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+
+	records, err := h.impersonationService.List(&req)
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to list impersonation audit log: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"records": records})
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+// JobHandler handles HTTP requests for asynchronous jobs.
+type JobHandler struct {
+	jobService *service.JobService
+	log        logger.Logger
+}
+
+// NewJobHandler creates a new job handler.
+func NewJobHandler(jobService *service.JobService, log logger.Logger) *JobHandler {
+	return &JobHandler{
+		jobService: jobService,
+		log:        log,
+	}
+}
+
+// List retrieves all jobs of the current user.
+// GET /api/v1/jobs
+func (h *JobHandler) List(c *gin.Context) {
+	userID := getUserID(c)
+
+	c.JSON(http.StatusOK, gin.H{
+		"jobs": h.jobService.List(userID),
+	})
+}
+
+// Get retrieves the status and result of a job.
+// GET /api/v1/jobs/:jobId
+func (h *JobHandler) Get(c *gin.Context) {
+	jobID := c.Param("jobId")
+
+	job, err := h.jobService.Get(getUserID(c), jobID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
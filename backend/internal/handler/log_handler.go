@@ -4,20 +4,31 @@
 package handler
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
 	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
 	"github.com/lazycatapps/gosmee/backend/internal/service"
 )
 
+// logStreamHeartbeat is the interval of keep-alive comments on idle log streams,
+// so proxies don't cut the connection.
+const logStreamHeartbeat = 15 * time.Second
+
 // LogHandler handles HTTP requests for log management.
 type LogHandler struct {
 	logService     *service.LogService
 	processService *service.ProcessService
+	clientService  *service.ClientService
 	log            logger.Logger
 }
 
@@ -25,11 +36,13 @@ type LogHandler struct {
 func NewLogHandler(
 	logService *service.LogService,
 	processService *service.ProcessService,
+	clientService *service.ClientService,
 	log logger.Logger,
 ) *LogHandler {
 	return &LogHandler{
 		logService:     logService,
 		processService: processService,
+		clientService:  clientService,
 		log:            log,
 	}
 }
@@ -38,76 +51,208 @@ func NewLogHandler(
 // GET /api/v1/clients/:id/logs
 func (h *LogHandler) GetLogs(c *gin.Context) {
 	clientID := c.Param("id")
-	date := c.DefaultQuery("date", "")
-	pageStr := c.DefaultQuery("page", "1")
-	pageSizeStr := c.DefaultQuery("pageSize", "100")
-	search := c.Query("search")
-
-	page, _ := strconv.Atoi(pageStr)
-	pageSize, _ := strconv.Atoi(pageSizeStr)
 
-	if page < 1 {
-		page = 1
+	var req models.LogListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+	if err := validateListQuery(logListLimits, req.Page, req.PageSize, "", ""); err != nil {
+		respondError(c, err)
+		return
 	}
-	if pageSize < 1 || pageSize > 1000 {
-		pageSize = 100
+	filter, ok := logFilterQuery(c)
+	if !ok {
+		return
 	}
 
 	userID := getUserID(c)
 
-	var logs []string
+	var logs []models.LogRecord
 	var total int
 	var err error
 
+	date := req.Date
 	if date == "" {
 		// Get today's logs
-		logs, total, err = h.logService.GetTodayLogs(userID, clientID, page, pageSize, search)
+		date = time.Now().Format("2006-01-02")
+		logs, total, err = h.logService.GetTodayLogs(userID, clientID, req.Page, req.PageSize, filter)
 	} else {
 		// Get logs for specific date
-		logs, total, err = h.logService.GetLogs(userID, clientID, date, page, pageSize, search)
+		logs, total, err = h.logService.GetLogs(userID, clientID, req.Date, req.Page, req.PageSize, filter)
+	}
+
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to get logs: %v", err)
+		respondError(c, err)
+		return
 	}
 
+	// The annotations of the whole day, so notes stay visible on every page
+	annotations, err := h.logService.ListAnnotations(userID, clientID, date)
 	if err != nil {
-		h.log.Error("Failed to get logs: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		requestLog(c, h.log).Error("Failed to get log annotations: %v", err)
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"total":    total,
-		"page":     page,
-		"pageSize": pageSize,
-		"logs":     logs,
+		"total":       total,
+		"page":        req.Page,
+		"pageSize":    req.PageSize,
+		"logs":        logs,
+		"annotations": annotations,
 	})
 }
 
+// GetMergedLogs retrieves the historical logs of several clients, merged chronologically.
+// GET /api/v1/logs?clients=a,b,c
+func (h *LogHandler) GetMergedLogs(c *gin.Context) {
+	var req models.MergedLogListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+	if err := validateListQuery(logListLimits, req.Page, req.PageSize, "", ""); err != nil {
+		respondError(c, err)
+		return
+	}
+	filter, ok := logFilterQuery(c)
+	if !ok {
+		return
+	}
+	clientIDs, ok := h.requestedClients(c, req.Clients)
+	if !ok {
+		return
+	}
+
+	date := req.Date
+	if date == "" {
+		date = time.Now().Format("2006-01-02")
+	}
+
+	logs, total, err := h.logService.GetMergedLogs(getUserID(c), clientIDs, date, req.Page, req.PageSize, filter)
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to get merged logs: %v", err)
+		respondError(c, err)
+		return
+	}
+	annotations, err := h.logService.ListMergedAnnotations(getUserID(c), clientIDs, date)
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to get log annotations: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"clients":     clientIDs,
+		"total":       total,
+		"page":        req.Page,
+		"pageSize":    req.PageSize,
+		"logs":        logs,
+		"annotations": annotations,
+	})
+}
+
+// requestedClients resolves a comma-separated list of client IDs or slugs to the IDs of
+// the current user's clients, without duplicates. Unknown clients, clients of other users
+// and too many clients are answered with an error and reported as not ok.
+func (h *LogHandler) requestedClients(c *gin.Context, list string) ([]string, bool) {
+	userID := getUserID(c)
+
+	clientIDs := []string{}
+	for _, idOrSlug := range strings.Split(list, ",") {
+		idOrSlug = strings.TrimSpace(idOrSlug)
+		if idOrSlug == "" {
+			continue
+		}
+
+		client, err := h.clientService.Resolve(userID, idOrSlug)
+		if err != nil {
+			respondError(c, apperrors.New(apperrors.CodeClientNotFound, fmt.Sprintf("client not found: %s", idOrSlug), http.StatusNotFound))
+			return nil, false
+		}
+		if client.UserID != userID {
+			requestLog(c, h.log).Info("User %s denied access to client %s", userID, client.ID)
+			respondError(c, apperrors.ErrNotOwner)
+			return nil, false
+		}
+		if !slices.Contains(clientIDs, client.ID) {
+			clientIDs = append(clientIDs, client.ID)
+		}
+	}
+
+	switch {
+	case len(clientIDs) == 0:
+		respondError(c, apperrors.NewInvalidInput("clients cannot be empty"))
+		return nil, false
+	case len(clientIDs) > service.MaxMergedLogClients:
+		respondError(c, apperrors.NewInvalidInput(fmt.Sprintf("at most %d clients can be requested at once", service.MaxMergedLogClients)))
+		return nil, false
+	}
+	return clientIDs, true
+}
+
 // StreamLogs streams real-time logs via SSE.
 // GET /api/v1/clients/:id/logs/stream
 func (h *LogHandler) StreamLogs(c *gin.Context) {
 	clientID := c.Param("id")
+	filter, ok := logFilterQuery(c)
+	if !ok {
+		return
+	}
+
+	// Resume after the last received line (sent by EventSource on reconnect).
+	// Invalid IDs are ignored and the stream starts with live lines only.
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("lastEventId")
+	}
+	resumeFrom, _ := strconv.ParseInt(lastEventID, 10, 64)
 
-	// Get log stream channel
-	logChan, err := h.logService.StreamLogs(clientID, h.processService)
+	// Subscribe to the log stream
+	stream, err := h.logService.StreamLogs(clientID, resumeFrom, filter, h.processService)
 	if err != nil {
-		h.log.Error("Failed to start log stream: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		requestLog(c, h.log).Error("Failed to start log stream: %v", err)
+		respondError(c, err)
 		return
 	}
+	defer stream.Close()
 
 	// Set SSE headers
 	c.Header("Content-Type", "text/event-stream")
 	c.Header("Cache-Control", "no-cache")
 	c.Header("Connection", "keep-alive")
 	c.Header("Transfer-Encoding", "chunked")
+	c.Header("X-Accel-Buffering", "no")
 
-	// Stream logs
+	heartbeat := time.NewTicker(logStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	// Replay missed lines, then stream live logs
+	backlog := stream.Backlog
 	c.Stream(func(w io.Writer) bool {
+		if len(backlog) > 0 {
+			for _, line := range backlog {
+				if stream.Accepts(line) {
+					writeLogEvent(w, line)
+				}
+			}
+			backlog = nil
+			return true
+		}
+
 		select {
-		case log, ok := <-logChan:
+		case line, ok := <-stream.Lines:
 			if !ok {
 				return false
 			}
-			c.SSEvent("log", log)
+			if stream.Accepts(line) {
+				writeLogEvent(w, line)
+			}
+			return true
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
 			return true
 		case <-c.Request.Context().Done():
 			// Client disconnected
@@ -116,6 +261,182 @@ func (h *LogHandler) StreamLogs(c *gin.Context) {
 	})
 }
 
+// StreamMergedLogs streams the real-time logs of several running clients via SSE, each
+// record labeled with its client ID. The stream starts with a "clients" event listing the
+// streamed clients and the requested clients that are not running.
+// GET /api/v1/logs/stream?clients=a,b,c or ?all=true
+func (h *LogHandler) StreamMergedLogs(c *gin.Context) {
+	var req models.MergedLogStreamRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+	filter, ok := logFilterQuery(c)
+	if !ok {
+		return
+	}
+
+	var clientIDs []string
+	if req.All {
+		var err error
+		if clientIDs, err = h.clientService.UserClientIDs(getUserID(c)); err != nil {
+			requestLog(c, h.log).Error("Failed to list clients for log stream: %v", err)
+			respondError(c, err)
+			return
+		}
+	} else if clientIDs, ok = h.requestedClients(c, req.Clients); !ok {
+		return
+	}
+
+	stream, err := h.logService.StreamMergedLogs(clientIDs, filter, h.processService)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	defer stream.Close()
+
+	// Set SSE headers
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("Transfer-Encoding", "chunked")
+	c.Header("X-Accel-Buffering", "no")
+
+	heartbeat := time.NewTicker(logStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	announced := false
+	c.Stream(func(w io.Writer) bool {
+		if !announced {
+			data, _ := json.Marshal(gin.H{"clients": stream.Clients, "notRunning": stream.NotRunning})
+			fmt.Fprintf(w, "event: clients\ndata: %s\n\n", data)
+			announced = true
+			return true
+		}
+
+		select {
+		case line, ok := <-stream.Lines:
+			if !ok {
+				return false
+			}
+			// Sequence numbers are per process, so records carry no event ID to resume from
+			data, err := json.Marshal(line)
+			if err == nil {
+				fmt.Fprintf(w, "event: log\ndata: %s\n\n", data)
+			}
+			return true
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			return true
+		case <-c.Request.Context().Done():
+			// Client disconnected
+			return false
+		}
+	})
+}
+
+// logFilterQuery returns the record filter of a logs request: search, source, minimum level
+// and delivery=true. An invalid filter is answered with 400 and reported as not ok.
+func logFilterQuery(c *gin.Context) (models.LogFilter, bool) {
+	filter := models.LogFilter{
+		Search:   c.Query("search"),
+		Source:   c.Query("source"),
+		Level:    c.Query("level"),
+		Delivery: c.Query("delivery") == "true",
+	}
+	if filter.Source != "" && !models.IsLogSource(filter.Source) {
+		respondError(c, apperrors.NewInvalidInput(fmt.Sprintf("invalid log source %q: must be stdout or stderr", filter.Source)))
+		return filter, false
+	}
+	if filter.Level != "" && !models.IsLogLevel(filter.Level) {
+		respondError(c, apperrors.NewInvalidInput(fmt.Sprintf("invalid log level %q: must be debug, info, warn or error", filter.Level)))
+		return filter, false
+	}
+	return filter, true
+}
+
+// writeLogEvent writes a log record as an SSE "log" event with its sequence number as ID
+// and the JSON record as data.
+func writeLogEvent(w io.Writer, record models.LogRecord) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "id: %d\nevent: log\ndata: %s\n\n", record.Seq, data)
+}
+
+// GetLastRun returns the last log lines of the client's most recently stopped process.
+// GET /api/v1/clients/:id/logs/last-run
+func (h *LogHandler) GetLastRun(c *gin.Context) {
+	filter, ok := logFilterQuery(c)
+	if !ok {
+		return
+	}
+
+	run, err := h.processService.GetLastRun(c.Param("id"), filter)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
+
+// ListAnnotations lists the annotations of a client's log of a day.
+// GET /api/v1/clients/:id/logs/annotations?date=YYYY-MM-DD
+func (h *LogHandler) ListAnnotations(c *gin.Context) {
+	date := c.DefaultQuery("date", time.Now().Format("2006-01-02"))
+
+	annotations, err := h.logService.ListAnnotations(getUserID(c), c.Param("id"), date)
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to list log annotations: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"date":        date,
+		"annotations": annotations,
+	})
+}
+
+// AddAnnotation attaches a note to a log timestamp of a client.
+// POST /api/v1/clients/:id/logs/annotations
+func (h *LogHandler) AddAnnotation(c *gin.Context) {
+	var req models.LogAnnotationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+
+	annotation, err := h.logService.AddAnnotation(getUserID(c), c.Param("id"), &req)
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to add log annotation: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, annotation)
+}
+
+// DeleteAnnotation removes an annotation from a client's log of a day.
+// DELETE /api/v1/clients/:id/logs/annotations/:annotationId?date=YYYY-MM-DD
+func (h *LogHandler) DeleteAnnotation(c *gin.Context) {
+	date := c.Query("date")
+	if date == "" {
+		respondError(c, apperrors.NewInvalidInput("date parameter is required"))
+		return
+	}
+
+	if err := h.logService.DeleteAnnotation(getUserID(c), c.Param("id"), date, c.Param("annotationId")); err != nil {
+		requestLog(c, h.log).Error("Failed to delete log annotation: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Annotation deleted successfully"})
+}
+
 // DownloadLog downloads a log file.
 // GET /api/v1/clients/:id/logs/download
 func (h *LogHandler) DownloadLog(c *gin.Context) {
@@ -123,22 +444,33 @@ func (h *LogHandler) DownloadLog(c *gin.Context) {
 	date := c.Query("date")
 
 	if date == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "date parameter is required"})
+		respondError(c, apperrors.NewInvalidInput("date parameter is required"))
+		return
+	}
+
+	format := c.DefaultQuery("format", service.LogFormatText)
+	if format != service.LogFormatText && format != service.LogFormatNDJSON {
+		respondError(c, apperrors.NewInvalidInput(fmt.Sprintf("invalid log format %q: must be text or ndjson", format)))
 		return
 	}
 
 	userID := getUserID(c)
 
-	data, err := h.logService.DownloadLog(userID, clientID, date)
+	data, err := h.logService.DownloadLog(userID, clientID, date, format)
 	if err != nil {
-		h.log.Error("Failed to download log: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		requestLog(c, h.log).Error("Failed to download log: %v", err)
+		respondError(c, err)
 		return
 	}
 
 	filename := fmt.Sprintf("gosmee-%s-%s.log", clientID, date)
+	contentType := "text/plain"
+	if format == service.LogFormatNDJSON {
+		filename = fmt.Sprintf("gosmee-%s-%s.ndjson", clientID, date)
+		contentType = "application/x-ndjson"
+	}
 
 	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
-	c.Header("Content-Type", "text/plain")
-	c.Data(http.StatusOK, "text/plain", data)
+	c.Header("Content-Type", contentType)
+	c.Data(http.StatusOK, contentType, data)
 }
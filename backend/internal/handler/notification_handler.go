@@ -0,0 +1,79 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+// NotificationHandler handles HTTP requests for user notifications.
+type NotificationHandler struct {
+	notificationService *service.NotificationService
+	log                 logger.Logger
+}
+
+// NewNotificationHandler creates a new notification handler.
+func NewNotificationHandler(notificationService *service.NotificationService, log logger.Logger) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+		log:                 log,
+	}
+}
+
+// List retrieves the notifications of the current user.
+// GET /api/v1/notifications
+func (h *NotificationHandler) List(c *gin.Context) {
+	userID := getUserID(c)
+	unreadOnly := c.Query("unread") == "true"
+
+	c.JSON(http.StatusOK, h.notificationService.List(userID, unreadOnly))
+}
+
+// MarkRead marks a notification as read.
+// POST /api/v1/notifications/:notificationId/read
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	userID := getUserID(c)
+	notificationID := c.Param("notificationId")
+
+	if err := h.notificationService.MarkRead(userID, notificationID); err != nil {
+		respondError(c, apperrors.ErrNotificationNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notification marked as read"})
+}
+
+// MarkAllRead marks all notifications of the current user as read.
+// POST /api/v1/notifications/read-all
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	userID := getUserID(c)
+
+	h.notificationService.MarkAllRead(userID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "All notifications marked as read"})
+}
+
+// ListAdmin retrieves the notifications about the server addressed to administrators.
+// GET /api/v1/admin/notifications
+func (h *NotificationHandler) ListAdmin(c *gin.Context) {
+	unreadOnly := c.Query("unread") == "true"
+
+	c.JSON(http.StatusOK, h.notificationService.ListAdmin(unreadOnly))
+}
+
+// MarkAdminRead marks an administrator notification as read.
+// POST /api/v1/admin/notifications/:notificationId/read
+func (h *NotificationHandler) MarkAdminRead(c *gin.Context) {
+	if err := h.notificationService.MarkAdminRead(c.Param("notificationId")); err != nil {
+		respondError(c, apperrors.ErrNotificationNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notification marked as read"})
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+// OrphanHandler handles the admin API for orphaned client data.
+type OrphanHandler struct {
+	orphanService *service.OrphanService
+	log           logger.Logger
+}
+
+// NewOrphanHandler creates a new orphan handler.
+func NewOrphanHandler(orphanService *service.OrphanService, log logger.Logger) *OrphanHandler {
+	return &OrphanHandler{
+		orphanService: orphanService,
+		log:           log,
+	}
+}
+
+// List scans the data directories for orphaned client data.
+// GET /api/v1/admin/orphans
+func (h *OrphanHandler) List(c *gin.Context) {
+	response, err := h.orphanService.Scan()
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to scan for orphaned client data: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Clean deletes an orphaned client directory.
+// DELETE /api/v1/admin/orphans/:userId/:clientId
+func (h *OrphanHandler) Clean(c *gin.Context) {
+	if err := h.orphanService.Clean(c.Param("userId"), c.Param("clientId")); err != nil {
+		requestLog(c, h.log).Error("Failed to clean orphaned client data: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Orphaned client data deleted successfully"})
+}
+
+// Adopt turns an orphaned client directory back into a client.
+// POST /api/v1/admin/orphans/:userId/:clientId/adopt
+func (h *OrphanHandler) Adopt(c *gin.Context) {
+	var req models.OrphanAdoptRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondInvalidInput(c, err)
+			return
+		}
+	}
+
+	client, err := h.orphanService.Adopt(c.Param("userId"), c.Param("clientId"), &req)
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to adopt orphaned client data: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, client)
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package handler
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+)
+
+// listLimits are the pagination and sorting parameters a list endpoint accepts.
+type listLimits struct {
+	maxPageSize int      // Largest page size
+	sortFields  []string // Fields the list can be sorted by (nil = not sortable)
+}
+
+// Limits of the list endpoints.
+var (
+	clientListLimits = listLimits{maxPageSize: 100, sortFields: []string{"createdAt", "name", "status"}}
+	eventListLimits  = listLimits{maxPageSize: 100, sortFields: []string{"timestamp", "eventType", "status"}}
+	logListLimits    = listLimits{maxPageSize: 1000}
+)
+
+// FieldError is a rejected request parameter, listed in the details of INVALID_INPUT errors.
+type FieldError struct {
+	Field   string `json:"field"`   // Query parameter name
+	Message string `json:"message"` // Why the value was rejected
+}
+
+// validateListQuery checks the pagination and sorting parameters of a list request against
+// the limits of the endpoint. It returns an INVALID_INPUT error listing every rejected
+// parameter in details.fields. Empty sortBy and sortOrder select the endpoint's default.
+func validateListQuery(limits listLimits, page, pageSize int, sortBy, sortOrder string) error {
+	var fields []FieldError
+	if page < 1 {
+		fields = append(fields, FieldError{Field: "page", Message: "must be at least 1"})
+	}
+	if pageSize < 1 || pageSize > limits.maxPageSize {
+		fields = append(fields, FieldError{Field: "pageSize", Message: fmt.Sprintf("must be between 1 and %d", limits.maxPageSize)})
+	}
+	if sortBy != "" && !slices.Contains(limits.sortFields, sortBy) {
+		message := "sorting is not supported"
+		if len(limits.sortFields) > 0 {
+			message = "must be one of " + strings.Join(limits.sortFields, ", ")
+		}
+		fields = append(fields, FieldError{Field: "sortBy", Message: message})
+	}
+	if sortOrder != "" && sortOrder != "asc" && sortOrder != "desc" {
+		fields = append(fields, FieldError{Field: "sortOrder", Message: "must be asc or desc"})
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		messages[i] = field.Field + " " + field.Message
+	}
+	return apperrors.NewInvalidInput("invalid list parameters: " + strings.Join(messages, "; ")).
+		WithDetails(gin.H{"fields": fields})
+}
+
+// paginationLinks returns the URLs of the next and previous pages of a list request
+// (empty when there is none) and advertises them, along with the first and last pages,
+// in an RFC 5988 Link header. URLs are relative and keep all other query parameters.
+func paginationLinks(c *gin.Context, total, page, pageSize int) (next, prev string) {
+	lastPage := 1
+	if pageSize > 0 && total > 0 {
+		lastPage = (total + pageSize - 1) / pageSize
+	}
+
+	pageURL := func(p int) string {
+		u := *c.Request.URL
+		query := u.Query()
+		query.Set("page", strconv.Itoa(p))
+		query.Set("pageSize", strconv.Itoa(pageSize))
+		u.RawQuery = query.Encode()
+		u.Scheme, u.Host = "", ""
+		return u.RequestURI()
+	}
+
+	links := []string{}
+	if page < lastPage {
+		next = pageURL(page + 1)
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, next))
+	}
+	if page > 1 {
+		prev = pageURL(min(page-1, lastPage))
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, prev))
+	}
+	links = append(links,
+		fmt.Sprintf(`<%s>; rel="first"`, pageURL(1)),
+		fmt.Sprintf(`<%s>; rel="last"`, pageURL(lastPage)),
+	)
+	c.Header("Link", strings.Join(links, ", "))
+
+	return next, prev
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lazycatapps/gosmee/backend/internal/models"
 	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
 	"github.com/lazycatapps/gosmee/backend/internal/service"
 )
@@ -32,13 +33,14 @@ func (h *QuotaHandler) GetQuota(c *gin.Context) {
 
 	quota, err := h.quotaService.GetQuota(userID)
 	if err != nil {
-		h.log.Error("Failed to get quota: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		requestLog(c, h.log).Error("Failed to get quota: %v", err)
+		respondError(c, err)
 		return
 	}
 
-	// Add warning if needed
+	// Add warnings if needed
 	warning, _ := h.quotaService.GetStorageWarning(userID)
+	clientsWarning, _ := h.quotaService.GetClientsWarning(userID)
 
 	response := gin.H{
 		"quota": quota,
@@ -47,6 +49,60 @@ func (h *QuotaHandler) GetQuota(c *gin.Context) {
 	if warning != "" {
 		response["warning"] = warning
 	}
+	if clientsWarning != "" {
+		response["clientsWarning"] = clientsWarning
+	}
 
 	c.JSON(http.StatusOK, response)
 }
+
+// GetHistory retrieves the quota usage history of the current user with its growth trend.
+// GET /api/v1/quota/history
+func (h *QuotaHandler) GetHistory(c *gin.Context) {
+	var req models.QuotaHistoryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+
+	history, err := h.quotaService.GetHistory(getUserID(c), &req)
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to get quota history: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// GetSettings retrieves the storage threshold overrides of the current user.
+// GET /api/v1/settings/quota
+func (h *QuotaHandler) GetSettings(c *gin.Context) {
+	settings, err := h.quotaService.GetSettings(getUserID(c))
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to get quota settings: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateSettings replaces the storage threshold overrides of the current user.
+// PUT /api/v1/settings/quota
+func (h *QuotaHandler) UpdateSettings(c *gin.Context) {
+	var req models.QuotaSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+
+	settings, err := h.quotaService.UpdateSettings(getUserID(c), &req)
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to update quota settings: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
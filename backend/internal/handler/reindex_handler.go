@@ -0,0 +1,39 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+// ReindexHandler handles the admin API rebuilding derived indices and rollups.
+type ReindexHandler struct {
+	reindexService *service.ReindexService
+	log            logger.Logger
+}
+
+// NewReindexHandler creates a new reindex handler.
+func NewReindexHandler(reindexService *service.ReindexService, log logger.Logger) *ReindexHandler {
+	return &ReindexHandler{
+		reindexService: reindexService,
+		log:            log,
+	}
+}
+
+// Reindex rebuilds the indices and rollups of all clients as an asynchronous job.
+// POST /api/v1/admin/reindex
+func (h *ReindexHandler) Reindex(c *gin.Context) {
+	job, err := h.reindexService.Start(getUserID(c))
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to start reindex: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
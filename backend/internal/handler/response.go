@@ -0,0 +1,59 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+)
+
+// ErrorResponse is the envelope of every API error response.
+type ErrorResponse struct {
+	Code      string      `json:"code"`              // Machine-readable error code (e.g. "QUOTA_EXCEEDED")
+	Message   string      `json:"message"`           // Human-readable error message
+	Details   interface{} `json:"details,omitempty"` // Optional structured details
+	RequestID string      `json:"requestId"`         // Request ID, to be cited in bug reports
+}
+
+// getRequestID returns the request ID set by the request ID middleware.
+func getRequestID(c *gin.Context) string {
+	return c.GetString("requestID")
+}
+
+// requestLog returns a logger tagging messages with the current request ID.
+func requestLog(c *gin.Context, log logger.Logger) logger.Logger {
+	return logger.WithRequestID(log, getRequestID(c))
+}
+
+// respondError writes an error envelope. Application errors keep their code and status,
+// any other error is reported as an internal error.
+func respondError(c *gin.Context, err error) {
+	var appErr *apperrors.AppError
+	if !errors.As(err, &appErr) {
+		appErr = apperrors.New(apperrors.CodeInternal, err.Error(), http.StatusInternalServerError)
+	}
+
+	c.JSON(appErr.StatusCode, ErrorResponse{
+		Code:      appErr.Code,
+		Message:   appErr.Message,
+		Details:   appErr.Details,
+		RequestID: getRequestID(c),
+	})
+}
+
+// respondInvalidInput writes an INVALID_INPUT error for malformed requests,
+// or REQUEST_TOO_LARGE when reading the body hit the body size limit.
+func respondInvalidInput(c *gin.Context, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		respondError(c, apperrors.ErrRequestTooLarge.WithDetails(gin.H{"limit": maxBytesErr.Limit}))
+		return
+	}
+
+	respondError(c, apperrors.NewInvalidInput(err.Error()))
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+// ServiceAccountHandler handles the admin API for service accounts.
+type ServiceAccountHandler struct {
+	serviceAccountService *service.ServiceAccountService
+	log                   logger.Logger
+}
+
+// NewServiceAccountHandler creates a new service account handler.
+func NewServiceAccountHandler(serviceAccountService *service.ServiceAccountService, log logger.Logger) *ServiceAccountHandler {
+	return &ServiceAccountHandler{
+		serviceAccountService: serviceAccountService,
+		log:                   log,
+	}
+}
+
+// List retrieves all service accounts.
+// GET /api/v1/admin/service-accounts
+func (h *ServiceAccountHandler) List(c *gin.Context) {
+	response, err := h.serviceAccountService.List()
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to list service accounts: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Create creates a service account and returns its token (shown only once).
+// POST /api/v1/admin/service-accounts
+func (h *ServiceAccountHandler) Create(c *gin.Context) {
+	var req models.ServiceAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+
+	response, err := h.serviceAccountService.Create(getUserID(c), &req)
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to create service account: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// Rotate issues a new token for a service account, revoking the previous one.
+// POST /api/v1/admin/service-accounts/:accountId/rotate
+func (h *ServiceAccountHandler) Rotate(c *gin.Context) {
+	response, err := h.serviceAccountService.Rotate(c.Param("accountId"))
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to rotate service account token: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Delete deletes a service account, revoking its token.
+// DELETE /api/v1/admin/service-accounts/:accountId
+func (h *ServiceAccountHandler) Delete(c *gin.Context) {
+	if err := h.serviceAccountService.Delete(c.Param("accountId")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Service account deleted successfully"})
+}
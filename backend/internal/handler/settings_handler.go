@@ -0,0 +1,59 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+// SettingsHandler handles HTTP requests for user settings.
+type SettingsHandler struct {
+	settingsService *service.SettingsService
+	log             logger.Logger
+}
+
+// NewSettingsHandler creates a new settings handler.
+func NewSettingsHandler(settingsService *service.SettingsService, log logger.Logger) *SettingsHandler {
+	return &SettingsHandler{
+		settingsService: settingsService,
+		log:             log,
+	}
+}
+
+// GetMasking retrieves the secret masking settings of the current user.
+// GET /api/v1/settings/masking
+func (h *SettingsHandler) GetMasking(c *gin.Context) {
+	settings, err := h.settingsService.GetMasking(getUserID(c))
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to get masking settings: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateMasking replaces the secret masking settings of the current user.
+// PUT /api/v1/settings/masking
+func (h *SettingsHandler) UpdateMasking(c *gin.Context) {
+	var req models.MaskingSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+
+	settings, err := h.settingsService.UpdateMasking(getUserID(c), &req)
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to update masking settings: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
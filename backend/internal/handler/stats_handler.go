@@ -0,0 +1,122 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package handler
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+// StatsHandler handles HTTP requests for client statistics.
+type StatsHandler struct {
+	statsService *service.StatsService
+	log          logger.Logger
+}
+
+// NewStatsHandler creates a new statistics handler.
+func NewStatsHandler(statsService *service.StatsService, log logger.Logger) *StatsHandler {
+	return &StatsHandler{
+		statsService: statsService,
+		log:          log,
+	}
+}
+
+// GetDaily retrieves the daily statistics rollups of a client.
+// GET /api/v1/clients/:id/stats/daily
+func (h *StatsHandler) GetDaily(c *gin.Context) {
+	clientID := c.Param("id")
+
+	var req models.DailyStatsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+	if !req.DateFrom.IsZero() && !req.DateTo.IsZero() && req.DateFrom.After(req.DateTo) {
+		respondError(c, apperrors.NewInvalidInput("dateFrom must not be after dateTo"))
+		return
+	}
+
+	response, err := h.statsService.Daily(clientID, &req)
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to get daily stats: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Query retrieves bucketed statistics of a client for a date range.
+// GET /api/v1/clients/:id/stats/query
+func (h *StatsHandler) Query(c *gin.Context) {
+	clientID := c.Param("id")
+
+	var req models.StatsQueryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+
+	response, err := h.statsService.Query(clientID, &req)
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to query stats: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Heatmap retrieves the event counts of a client by weekday and hour of the day.
+// GET /api/v1/clients/:id/stats/heatmap
+func (h *StatsHandler) Heatmap(c *gin.Context) {
+	clientID := c.Param("id")
+
+	var req models.StatsHeatmapRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+
+	response, err := h.statsService.Heatmap(clientID, &req)
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to get activity heatmap: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Metrics exposes the statistics of the user's clients to Prometheus.
+// GET /api/v1/metrics
+func (h *StatsHandler) Metrics(c *gin.Context) {
+	var buf bytes.Buffer
+	if err := h.statsService.WriteMetrics(&buf, getUserID(c)); err != nil {
+		requestLog(c, h.log).Error("Failed to write metrics: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	c.Data(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", buf.Bytes())
+}
+
+// Overview retrieves aggregate statistics of all of the user's clients for dashboards.
+// GET /api/v1/stats/overview
+func (h *StatsHandler) Overview(c *gin.Context) {
+	response, err := h.statsService.Overview(getUserID(c))
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to get stats overview: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
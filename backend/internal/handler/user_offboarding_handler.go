@@ -0,0 +1,47 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+// UserOffboardingHandler handles the admin API offboarding users.
+type UserOffboardingHandler struct {
+	offboardingService *service.UserOffboardingService
+	log                logger.Logger
+}
+
+// NewUserOffboardingHandler creates a new user offboarding handler.
+func NewUserOffboardingHandler(offboardingService *service.UserOffboardingService, log logger.Logger) *UserOffboardingHandler {
+	return &UserOffboardingHandler{
+		offboardingService: offboardingService,
+		log:                log,
+	}
+}
+
+// Offboard stops and removes all clients of a user, deletes or archives the user's data and
+// revokes the user's sessions and service accounts.
+// DELETE /api/v1/admin/users/:userId
+func (h *UserOffboardingHandler) Offboard(c *gin.Context) {
+	var req models.UserOffboardRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+
+	result, err := h.offboardingService.Offboard(getUserID(c), c.Param("userId"), &req)
+	if err != nil {
+		requestLog(c, h.log).Error("Failed to offboard user: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
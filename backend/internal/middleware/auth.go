@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
 )
 
 // SessionValidator is an interface for validating sessions.
@@ -23,18 +24,50 @@ type SessionInfo interface {
 	GetGroups() []string
 }
 
+// TokenValidator is an interface for validating service account bearer tokens.
+type TokenValidator interface {
+	ValidateToken(token string) (interface{}, bool)
+}
+
+// TokenInfo defines the interface for service account token information.
+type TokenInfo interface {
+	GetUserID() string
+	GetScopes() []string
+}
+
+// AdminGroup is the OIDC group granting administrator privileges.
+const AdminGroup = "ADMIN"
+
 // Auth is a middleware that validates OIDC authentication.
 // It checks for a valid session cookie and redirects to login if not authenticated.
-func Auth(oidcEnabled bool, sessionValidator SessionValidator) gin.HandlerFunc {
+//
+// Requests carrying an "Authorization: Bearer <token>" header are authenticated as
+// service accounts instead (even when OIDC is disabled); their scopes are stored in
+// the context as "scopes" and enforced by RequireScope.
+func Auth(oidcEnabled bool, sessionValidator SessionValidator, tokenValidator TokenValidator) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Skip authentication if OIDC is not enabled
-		if !oidcEnabled {
+		// Skip authentication for public endpoints and the frontend
+		if isPublicEndpoint(c.FullPath()) || isFrontendRequest(c) {
+			c.Next()
+			return
+		}
+
+		// Service account tokens
+		if token, ok := bearerToken(c); ok {
+			tokenInfo, valid := validateToken(tokenValidator, token)
+			if !valid {
+				abortWithError(c, http.StatusUnauthorized, apperrors.CodeUnauthorized, "Invalid or expired token")
+				return
+			}
+			c.Set("serviceAccount", tokenInfo)
+			c.Set("userID", tokenInfo.GetUserID())
+			c.Set("scopes", tokenInfo.GetScopes())
 			c.Next()
 			return
 		}
 
-		// Skip authentication for public endpoints
-		if isPublicEndpoint(c.FullPath()) {
+		// Skip authentication if OIDC is not enabled
+		if !oidcEnabled {
 			c.Next()
 			return
 		}
@@ -44,8 +77,7 @@ func Auth(oidcEnabled bool, sessionValidator SessionValidator) gin.HandlerFunc {
 		if err != nil || sessionCookie == "" {
 			// No session, redirect to login
 			if isAPIRequest(c) {
-				c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
-				c.Abort()
+				abortWithError(c, http.StatusUnauthorized, apperrors.CodeUnauthorized, "Authentication required")
 				return
 			}
 			// For browser requests, redirect to login
@@ -59,8 +91,7 @@ func Auth(oidcEnabled bool, sessionValidator SessionValidator) gin.HandlerFunc {
 			sessionInfo, exists := sessionValidator.GetSession(sessionCookie)
 			if !exists {
 				if isAPIRequest(c) {
-					c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired session"})
-					c.Abort()
+					abortWithError(c, http.StatusUnauthorized, apperrors.CodeUnauthorized, "Invalid or expired session")
 					return
 				}
 				c.Redirect(http.StatusFound, "/api/v1/auth/login")
@@ -80,10 +111,64 @@ func Auth(oidcEnabled bool, sessionValidator SessionValidator) gin.HandlerFunc {
 	}
 }
 
+// RequireScope is a middleware restricting service account tokens to routes covered by
+// their scopes. Session (and unauthenticated, OIDC disabled) requests are not affected.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, isToken := c.Get("scopes")
+		if !isToken {
+			c.Next()
+			return
+		}
+
+		scopes, _ := value.([]string)
+		for _, granted := range scopes {
+			if granted == "*" || granted == scope {
+				c.Next()
+				return
+			}
+		}
+
+		abortWithError(c, http.StatusForbidden, apperrors.CodeInsufficientScope, "Token lacks the required scope: "+scope)
+	}
+}
+
+// bearerToken extracts the token of an "Authorization: Bearer" header.
+func bearerToken(c *gin.Context) (string, bool) {
+	scheme, token, found := strings.Cut(c.GetHeader("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+// validateToken validates a bearer token, rejecting it when no validator is configured.
+func validateToken(tokenValidator TokenValidator, token string) (TokenInfo, bool) {
+	if tokenValidator == nil {
+		return nil, false
+	}
+	value, valid := tokenValidator.ValidateToken(token)
+	if !valid {
+		return nil, false
+	}
+	tokenInfo, ok := value.(TokenInfo)
+	return tokenInfo, ok
+}
+
+// abortWithError aborts the request with an error envelope.
+func abortWithError(c *gin.Context, status int, code, message string) {
+	c.AbortWithStatusJSON(status, gin.H{
+		"code":      code,
+		"message":   message,
+		"requestId": c.GetString("requestID"),
+	})
+}
+
 // isPublicEndpoint checks if the endpoint is public (no auth required).
 func isPublicEndpoint(path string) bool {
 	publicPaths := []string{
 		"/api/v1/health",
+		"/api/v1/auth/providers",
 		"/api/v1/auth/login",
 		"/api/v1/auth/callback",
 		"/api/v1/auth/userinfo",
@@ -97,6 +182,12 @@ func isPublicEndpoint(path string) bool {
 	return false
 }
 
+// isFrontendRequest checks if the request targets the frontend, i.e. matches no route
+// and lies outside /api/.
+func isFrontendRequest(c *gin.Context) bool {
+	return c.FullPath() == "" && !strings.HasPrefix(c.Request.URL.Path, "/api/")
+}
+
 // isAPIRequest checks if the request is an API request based on headers
 func isAPIRequest(c *gin.Context) bool {
 	// Check Accept header
@@ -0,0 +1,77 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type testToken struct {
+	userID string
+	scopes []string
+}
+
+func (t *testToken) GetUserID() string   { return t.userID }
+func (t *testToken) GetScopes() []string { return t.scopes }
+
+type testTokenValidator map[string]*testToken
+
+func (v testTokenValidator) ValidateToken(token string) (interface{}, bool) {
+	info, ok := v[token]
+	return info, ok
+}
+
+func TestAuthServiceAccountScopes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	validator := testTokenValidator{
+		"gsa_reader": {userID: "alice", scopes: []string{"events:read"}},
+		"gsa_admin":  {userID: "alice", scopes: []string{"*"}},
+	}
+
+	tests := []struct {
+		name           string
+		oidcEnabled    bool
+		authorization  string
+		path           string
+		expectedStatus int
+	}{
+		{name: "Token with the required scope", oidcEnabled: true, authorization: "Bearer gsa_reader", path: "/events", expectedStatus: http.StatusOK},
+		{name: "Token lacking the required scope", oidcEnabled: true, authorization: "Bearer gsa_reader", path: "/start", expectedStatus: http.StatusForbidden},
+		{name: "Wildcard scope", oidcEnabled: true, authorization: "Bearer gsa_admin", path: "/start", expectedStatus: http.StatusOK},
+		{name: "Unknown token", oidcEnabled: true, authorization: "Bearer gsa_unknown", path: "/events", expectedStatus: http.StatusUnauthorized},
+		{name: "Unknown token with OIDC disabled", oidcEnabled: false, authorization: "Bearer gsa_unknown", path: "/events", expectedStatus: http.StatusUnauthorized},
+		{name: "Token scopes enforced with OIDC disabled", oidcEnabled: false, authorization: "Bearer gsa_reader", path: "/start", expectedStatus: http.StatusForbidden},
+		{name: "No token with OIDC disabled", oidcEnabled: false, path: "/start", expectedStatus: http.StatusOK},
+		{name: "No token or session with OIDC enabled", oidcEnabled: true, path: "/events", expectedStatus: http.StatusUnauthorized},
+		{name: "Tokens cannot reach admin routes", oidcEnabled: false, authorization: "Bearer gsa_admin", path: "/admin", expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(Auth(tt.oidcEnabled, nil, validator))
+			ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+			router.GET("/events", RequireScope("events:read"), ok)
+			router.GET("/start", RequireScope("clients:start"), ok)
+			router.GET("/admin", NewAuthorizer(DefaultPolicy(), tt.oidcEnabled).Require(RouteGroupAdmin), ok)
+
+			req := httptest.NewRequest("GET", tt.path, nil)
+			req.Header.Set("Accept", "application/json")
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d (%s)", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+)
+
+// BodyLimit creates a middleware limiting the size of request bodies.
+// routeLimits overrides the default limit for specific routes, keyed by the
+// route pattern (e.g. "/api/v1/clients/:id/events"). A limit <= 0 disables the check.
+//
+// Requests announcing a larger Content-Length are rejected immediately with 413.
+// Other bodies are wrapped so reading past the limit fails, which handlers report as 413.
+func BodyLimit(defaultLimit int64, routeLimits map[string]int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := defaultLimit
+		if routeLimit, ok := routeLimits[c.FullPath()]; ok {
+			limit = routeLimit
+		}
+
+		if limit <= 0 || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > limit {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+				"code":      apperrors.CodeRequestTooLarge,
+				"message":   apperrors.ErrRequestTooLarge.Message,
+				"details":   gin.H{"limit": limit},
+				"requestId": c.GetString("requestID"),
+			})
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestBodyLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		path           string
+		body           string
+		chunked        bool
+		expectedStatus int
+	}{
+		{name: "Body within the default limit", path: "/clients", body: strings.Repeat("a", 10), expectedStatus: http.StatusOK},
+		{name: "Content-Length above the default limit", path: "/clients", body: strings.Repeat("a", 11), expectedStatus: http.StatusRequestEntityTooLarge},
+		{name: "Chunked body above the default limit", path: "/clients", body: strings.Repeat("a", 11), chunked: true, expectedStatus: http.StatusRequestEntityTooLarge},
+		{name: "Route override allows larger bodies", path: "/clients/abc/events", body: strings.Repeat("a", 50), expectedStatus: http.StatusOK},
+		{name: "Route override is still enforced", path: "/clients/abc/events", body: strings.Repeat("a", 101), expectedStatus: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(BodyLimit(10, map[string]int64{"/clients/:id/events": 100}))
+			handler := func(c *gin.Context) {
+				if _, err := io.ReadAll(c.Request.Body); err != nil {
+					var maxBytesErr *http.MaxBytesError
+					if errors.As(err, &maxBytesErr) {
+						c.Status(http.StatusRequestEntityTooLarge)
+						return
+					}
+					c.Status(http.StatusBadRequest)
+					return
+				}
+				c.Status(http.StatusOK)
+			}
+			router.POST("/clients", handler)
+			router.POST("/clients/:id/events", handler)
+
+			req := httptest.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
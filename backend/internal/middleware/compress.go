@@ -0,0 +1,190 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+// Compress creates a response compression middleware.
+// It negotiates brotli ("br") or gzip from the Accept-Encoding header and only
+// compresses responses of at least minSize bytes.
+//
+// Excluded from compression:
+//   - Server-Sent Events (text/event-stream), which must be delivered unbuffered
+//   - Responses that are already encoded or partial (Content-Encoding, Content-Range)
+//   - Responses flushed by the handler before minSize bytes were written
+func Compress(minSize int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodHead || strings.Contains(c.GetHeader("Accept"), "text/event-stream") {
+			c.Next()
+			return
+		}
+
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" {
+			c.Next()
+			return
+		}
+
+		c.Header("Vary", "Accept-Encoding")
+
+		writer := &compressWriter{
+			ResponseWriter: c.Writer,
+			encoding:       encoding,
+			minSize:        minSize,
+		}
+		c.Writer = writer
+		defer writer.finish()
+
+		c.Next()
+	}
+}
+
+// negotiateEncoding picks the preferred supported encoding, "" if none is acceptable.
+func negotiateEncoding(acceptEncoding string) string {
+	var brAccepted, gzipAccepted bool
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if q, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			if value, err := strconv.ParseFloat(q, 64); err == nil && value == 0 {
+				continue
+			}
+		}
+
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "br":
+			brAccepted = true
+		case "gzip":
+			gzipAccepted = true
+		}
+	}
+
+	switch {
+	case brAccepted:
+		return "br"
+	case gzipAccepted:
+		return "gzip"
+	default:
+		return ""
+	}
+}
+
+// compressWriter buffers the beginning of a response until it knows whether
+// the response is worth compressing, then either compresses or passes it through.
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding string
+	minSize  int
+	buf      []byte
+	decided  bool
+	encoder  io.WriteCloser // nil when the response is passed through
+}
+
+// Write implements io.Writer.
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		if !w.compressible() {
+			if err := w.passThrough(); err != nil {
+				return 0, err
+			}
+			return w.ResponseWriter.Write(data)
+		}
+
+		w.buf = append(w.buf, data...)
+		if len(w.buf) < w.minSize {
+			return len(data), nil
+		}
+		if err := w.startCompression(); err != nil {
+			return 0, err
+		}
+		return len(data), nil
+	}
+
+	if w.encoder != nil {
+		return w.encoder.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString implements io.StringWriter.
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush sends buffered data to the client. A flush before the compression
+// decision means the handler is streaming, so the response is passed through.
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.passThrough()
+	}
+	if flusher, ok := w.encoder.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// compressible reports whether the response headers allow compression.
+func (w *compressWriter) compressible() bool {
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" || header.Get("Content-Range") != "" {
+		return false
+	}
+	if strings.HasPrefix(header.Get("Content-Type"), "text/event-stream") {
+		return false
+	}
+	status := w.Status()
+	return status != http.StatusNoContent && status != http.StatusNotModified && status != http.StatusPartialContent
+}
+
+// startCompression switches to compressed output and writes the buffered data.
+func (w *compressWriter) startCompression() error {
+	w.decided = true
+
+	header := w.Header()
+	header.Set("Content-Encoding", w.encoding)
+	header.Del("Content-Length")
+
+	if w.encoding == "br" {
+		w.encoder = brotli.NewWriter(w.ResponseWriter)
+	} else {
+		w.encoder = gzip.NewWriter(w.ResponseWriter)
+	}
+
+	buf := w.buf
+	w.buf = nil
+	_, err := w.encoder.Write(buf)
+	return err
+}
+
+// passThrough writes the buffered data uncompressed and disables compression.
+func (w *compressWriter) passThrough() error {
+	w.decided = true
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+// finish completes the response once the handler chain has returned.
+func (w *compressWriter) finish() {
+	if !w.decided {
+		w.passThrough()
+		return
+	}
+	if w.encoder != nil {
+		w.encoder.Close()
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+func TestCompress(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	largeBody := strings.Repeat("gosmee event payload ", 100)
+
+	tests := []struct {
+		name             string
+		acceptEncoding   string
+		accept           string
+		contentType      string
+		body             string
+		expectedEncoding string
+	}{
+		{
+			name:             "Prefers brotli when accepted",
+			acceptEncoding:   "gzip, deflate, br",
+			contentType:      "application/json",
+			body:             largeBody,
+			expectedEncoding: "br",
+		},
+		{
+			name:             "Falls back to gzip",
+			acceptEncoding:   "gzip, deflate",
+			contentType:      "application/json",
+			body:             largeBody,
+			expectedEncoding: "gzip",
+		},
+		{
+			name:             "Respects q=0",
+			acceptEncoding:   "br;q=0, gzip",
+			contentType:      "application/json",
+			body:             largeBody,
+			expectedEncoding: "gzip",
+		},
+		{
+			name:           "No supported encoding",
+			acceptEncoding: "deflate",
+			contentType:    "application/json",
+			body:           largeBody,
+		},
+		{
+			name:           "Small responses are not compressed",
+			acceptEncoding: "gzip",
+			contentType:    "application/json",
+			body:           `{"status":"ok"}`,
+		},
+		{
+			name:           "Event streams are excluded by request",
+			acceptEncoding: "gzip",
+			accept:         "text/event-stream",
+			contentType:    "text/event-stream",
+			body:           largeBody,
+		},
+		{
+			name:           "Event streams are excluded by response type",
+			acceptEncoding: "gzip",
+			contentType:    "text/event-stream",
+			body:           largeBody,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(Compress(1024))
+			router.GET("/test", func(c *gin.Context) {
+				c.Data(http.StatusOK, tt.contentType, []byte(tt.body))
+			})
+
+			req := httptest.NewRequest("GET", "/test", nil)
+			req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if got := w.Header().Get("Content-Encoding"); got != tt.expectedEncoding {
+				t.Fatalf("Expected Content-Encoding %q, got %q", tt.expectedEncoding, got)
+			}
+
+			var reader io.Reader = w.Body
+			switch tt.expectedEncoding {
+			case "br":
+				reader = brotli.NewReader(w.Body)
+			case "gzip":
+				gz, err := gzip.NewReader(w.Body)
+				if err != nil {
+					t.Fatalf("Failed to open gzip body: %v", err)
+				}
+				reader = gz
+			}
+
+			body, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("Failed to read body: %v", err)
+			}
+			if string(body) != tt.body {
+				t.Errorf("Body mismatch: got %d bytes, expected %d bytes", len(body), len(tt.body))
+			}
+		})
+	}
+}
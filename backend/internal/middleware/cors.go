@@ -56,8 +56,9 @@ func CORS(allowedOrigins []string) gin.HandlerFunc {
 
 		// Only set CORS headers if origin is allowed
 		if allowed {
-			c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+			c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+			c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, If-Match, "+ImpersonateUserHeader+", "+ImpersonateReasonHeader)
+			c.Writer.Header().Set("Access-Control-Expose-Headers", RequestIDHeader+", ETag, "+ImpersonatedUserHeader)
 			if allowCredentials {
 				c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 			}
@@ -32,8 +32,8 @@ func TestCORS(t *testing.T) {
 			requestOrigin:         "https://example.com",
 			requestMethod:         "GET",
 			expectedOrigin:        "https://example.com",
-			expectedMethods:       "GET, POST, PUT, DELETE, OPTIONS",
-			expectedHeaders:       "Content-Type, Authorization",
+			expectedMethods:       "GET, POST, PUT, PATCH, DELETE, OPTIONS",
+			expectedHeaders:       "Content-Type, Authorization, If-Match, X-Impersonate-User, X-Impersonate-Reason",
 			expectedCredentials:   "true",
 			expectedStatus:        http.StatusOK,
 			shouldHaveCORSHeaders: true,
@@ -44,8 +44,8 @@ func TestCORS(t *testing.T) {
 			requestOrigin:         "",
 			requestMethod:         "GET",
 			expectedOrigin:        "*",
-			expectedMethods:       "GET, POST, PUT, DELETE, OPTIONS",
-			expectedHeaders:       "Content-Type, Authorization",
+			expectedMethods:       "GET, POST, PUT, PATCH, DELETE, OPTIONS",
+			expectedHeaders:       "Content-Type, Authorization, If-Match, X-Impersonate-User, X-Impersonate-Reason",
 			expectedCredentials:   "",
 			expectedStatus:        http.StatusOK,
 			shouldHaveCORSHeaders: true,
@@ -56,8 +56,8 @@ func TestCORS(t *testing.T) {
 			requestOrigin:         "https://app.example.com",
 			requestMethod:         "POST",
 			expectedOrigin:        "https://app.example.com",
-			expectedMethods:       "GET, POST, PUT, DELETE, OPTIONS",
-			expectedHeaders:       "Content-Type, Authorization",
+			expectedMethods:       "GET, POST, PUT, PATCH, DELETE, OPTIONS",
+			expectedHeaders:       "Content-Type, Authorization, If-Match, X-Impersonate-User, X-Impersonate-Reason",
 			expectedCredentials:   "true",
 			expectedStatus:        http.StatusOK,
 			shouldHaveCORSHeaders: true,
@@ -80,8 +80,8 @@ func TestCORS(t *testing.T) {
 			requestOrigin:         "https://app1.com",
 			requestMethod:         "GET",
 			expectedOrigin:        "https://app1.com",
-			expectedMethods:       "GET, POST, PUT, DELETE, OPTIONS",
-			expectedHeaders:       "Content-Type, Authorization",
+			expectedMethods:       "GET, POST, PUT, PATCH, DELETE, OPTIONS",
+			expectedHeaders:       "Content-Type, Authorization, If-Match, X-Impersonate-User, X-Impersonate-Reason",
 			expectedCredentials:   "true",
 			expectedStatus:        http.StatusOK,
 			shouldHaveCORSHeaders: true,
@@ -92,8 +92,8 @@ func TestCORS(t *testing.T) {
 			requestOrigin:         "https://app2.com",
 			requestMethod:         "GET",
 			expectedOrigin:        "https://app2.com",
-			expectedMethods:       "GET, POST, PUT, DELETE, OPTIONS",
-			expectedHeaders:       "Content-Type, Authorization",
+			expectedMethods:       "GET, POST, PUT, PATCH, DELETE, OPTIONS",
+			expectedHeaders:       "Content-Type, Authorization, If-Match, X-Impersonate-User, X-Impersonate-Reason",
 			expectedCredentials:   "true",
 			expectedStatus:        http.StatusOK,
 			shouldHaveCORSHeaders: true,
@@ -104,8 +104,8 @@ func TestCORS(t *testing.T) {
 			requestOrigin:         "https://example.com",
 			requestMethod:         "OPTIONS",
 			expectedOrigin:        "https://example.com",
-			expectedMethods:       "GET, POST, PUT, DELETE, OPTIONS",
-			expectedHeaders:       "Content-Type, Authorization",
+			expectedMethods:       "GET, POST, PUT, PATCH, DELETE, OPTIONS",
+			expectedHeaders:       "Content-Type, Authorization, If-Match, X-Impersonate-User, X-Impersonate-Reason",
 			expectedCredentials:   "true",
 			expectedStatus:        http.StatusNoContent,
 			shouldHaveCORSHeaders: true,
@@ -116,8 +116,8 @@ func TestCORS(t *testing.T) {
 			requestOrigin:         "https://app.example.com",
 			requestMethod:         "OPTIONS",
 			expectedOrigin:        "https://app.example.com",
-			expectedMethods:       "GET, POST, PUT, DELETE, OPTIONS",
-			expectedHeaders:       "Content-Type, Authorization",
+			expectedMethods:       "GET, POST, PUT, PATCH, DELETE, OPTIONS",
+			expectedHeaders:       "Content-Type, Authorization, If-Match, X-Impersonate-User, X-Impersonate-Reason",
 			expectedCredentials:   "true",
 			expectedStatus:        http.StatusNoContent,
 			shouldHaveCORSHeaders: true,
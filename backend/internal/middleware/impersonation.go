@@ -0,0 +1,109 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+)
+
+// Headers of impersonated requests. Administrators send ImpersonateUserHeader and
+// ImpersonateReasonHeader to act on behalf of a user; the response echoes the user in
+// ImpersonatedUserHeader.
+const (
+	ImpersonateUserHeader   = "X-Impersonate-User"
+	ImpersonateReasonHeader = "X-Impersonate-Reason"
+	ImpersonatedUserHeader  = "X-Impersonated-User"
+)
+
+// maxImpersonationReasonLength limits the reason recorded in the audit log.
+const maxImpersonationReasonLength = 500
+
+// ImpersonationAuditor records requests administrators made on behalf of other users.
+type ImpersonationAuditor interface {
+	RecordImpersonation(record *models.ImpersonationRecord)
+}
+
+// Impersonate is a middleware letting administrators act on behalf of the user named in
+// the X-Impersonate-User header, e.g. to reproduce a tenant's issue. The header requires
+// the admin role and a reason in X-Impersonate-Reason; service account tokens cannot
+// impersonate. The impersonated request runs as the user ("userID") with the user role
+// only, the administrator is stored in the context as "impersonator", and every request is
+// recorded by the auditor once answered.
+func (a *Authorizer) Impersonate(auditor ImpersonationAuditor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		targetUserID := strings.TrimSpace(c.GetHeader(ImpersonateUserHeader))
+		if targetUserID == "" || isPublicEndpoint(c.FullPath()) || isFrontendRequest(c) {
+			c.Next()
+			return
+		}
+
+		if _, isToken := c.Get("serviceAccount"); isToken {
+			abortWithError(c, http.StatusForbidden, apperrors.CodeAdminRequired, "Service account tokens cannot impersonate users")
+			return
+		}
+		if !contains(a.Roles(c), RoleAdmin) {
+			abortWithError(c, http.StatusForbidden, apperrors.CodeAdminRequired, "Administrator privileges required to impersonate users")
+			return
+		}
+
+		reason := strings.TrimSpace(c.GetHeader(ImpersonateReasonHeader))
+		if reason == "" || len(reason) > maxImpersonationReasonLength {
+			abortWithError(c, http.StatusBadRequest, apperrors.CodeInvalidInput,
+				ImpersonateReasonHeader+" header is required when impersonating (at most 500 characters)")
+			return
+		}
+		if !isValidImpersonationTarget(targetUserID) {
+			abortWithError(c, http.StatusBadRequest, apperrors.CodeInvalidInput, "Invalid "+ImpersonateUserHeader+" header")
+			return
+		}
+
+		adminID := c.GetString("userID")
+		if adminID == "" {
+			adminID = "default"
+		}
+		c.Set("impersonator", adminID)
+		c.Set("userID", targetUserID)
+		c.Header(ImpersonatedUserHeader, targetUserID)
+
+		c.Next()
+
+		if auditor != nil {
+			auditor.RecordImpersonation(&models.ImpersonationRecord{
+				Time:      time.Now(),
+				AdminID:   adminID,
+				UserID:    targetUserID,
+				Reason:    reason,
+				Method:    c.Request.Method,
+				Path:      c.Request.URL.Path,
+				Status:    c.Writer.Status(),
+				RequestID: c.GetString("requestID"),
+			})
+		}
+	}
+}
+
+// isImpersonated reports whether the request acts on behalf of another user.
+func isImpersonated(c *gin.Context) bool {
+	_, ok := c.Get("impersonator")
+	return ok
+}
+
+// isValidImpersonationTarget checks that a user ID is usable as a data directory name.
+func isValidImpersonationTarget(userID string) bool {
+	if len(userID) > 255 || userID == "." || userID == ".." {
+		return false
+	}
+	for i := 0; i < len(userID); i++ {
+		if userID[i] < ' ' || userID[i] > '~' || userID[i] == '/' || userID[i] == '\\' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+)
+
+type testAuditor []*models.ImpersonationRecord
+
+func (a *testAuditor) RecordImpersonation(record *models.ImpersonationRecord) {
+	*a = append(*a, record)
+}
+
+func TestImpersonate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessions := testSessionValidator{
+		"user":  {groups: []string{"DEV"}},
+		"admin": {groups: []string{AdminGroup}},
+	}
+	tokens := testTokenValidator{"gsa_all": {userID: "alice", scopes: []string{"*"}}}
+
+	tests := []struct {
+		name           string
+		session        string
+		authorization  string
+		impersonate    string
+		reason         string
+		path           string
+		expectedStatus int
+		expectedCode   string
+		expectedUserID string
+	}{
+		{name: "Without impersonation", session: "admin", path: "/user", expectedStatus: http.StatusOK, expectedUserID: "alice"},
+		{name: "Admin impersonating a user", session: "admin", impersonate: "bob", reason: "TICKET-42", path: "/user", expectedStatus: http.StatusOK, expectedUserID: "bob"},
+		{name: "Impersonated request on admin routes", session: "admin", impersonate: "bob", reason: "TICKET-42", path: "/admin", expectedStatus: http.StatusForbidden, expectedCode: "ACCESS_DENIED"},
+		{name: "Impersonated request on session routes", session: "admin", impersonate: "bob", reason: "TICKET-42", path: "/sessions", expectedStatus: http.StatusForbidden, expectedCode: "ACCESS_DENIED"},
+		{name: "Missing reason", session: "admin", impersonate: "bob", path: "/user", expectedStatus: http.StatusBadRequest, expectedCode: "INVALID_INPUT"},
+		{name: "Invalid user ID", session: "admin", impersonate: "../bob", reason: "TICKET-42", path: "/user", expectedStatus: http.StatusBadRequest, expectedCode: "INVALID_INPUT"},
+		{name: "Non-admin impersonating", session: "user", impersonate: "bob", reason: "TICKET-42", path: "/user", expectedStatus: http.StatusForbidden, expectedCode: "ADMIN_REQUIRED"},
+		{name: "Token impersonating", authorization: "Bearer gsa_all", impersonate: "bob", reason: "TICKET-42", path: "/user", expectedStatus: http.StatusForbidden, expectedCode: "ADMIN_REQUIRED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auditor := &testAuditor{}
+			authorizer := NewAuthorizer(DefaultPolicy(), true)
+			router := gin.New()
+			router.Use(Auth(true, sessions, tokens))
+			router.Use(authorizer.Impersonate(auditor))
+			ok := func(c *gin.Context) { c.String(http.StatusOK, c.GetString("userID")) }
+			router.GET("/user", authorizer.Require(RouteGroupUser), ok)
+			router.GET("/sessions", authorizer.Require(RouteGroupSessions), ok)
+			router.GET("/admin", authorizer.Require(RouteGroupAdmin), ok)
+
+			req := httptest.NewRequest("GET", tt.path, nil)
+			req.Header.Set("Accept", "application/json")
+			if tt.session != "" {
+				req.AddCookie(&http.Cookie{Name: "session", Value: tt.session})
+			}
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			if tt.impersonate != "" {
+				req.Header.Set(ImpersonateUserHeader, tt.impersonate)
+			}
+			if tt.reason != "" {
+				req.Header.Set(ImpersonateReasonHeader, tt.reason)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d (%s)", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedCode != "" && !strings.Contains(w.Body.String(), `"code":"`+tt.expectedCode+`"`) {
+				t.Errorf("Expected error code %s, got %s", tt.expectedCode, w.Body.String())
+			}
+			if tt.expectedUserID != "" && w.Body.String() != tt.expectedUserID {
+				t.Errorf("Expected request to act as %s, got %s", tt.expectedUserID, w.Body.String())
+			}
+
+			// Every request that passed the impersonation checks is audited
+			impersonated := tt.impersonate != "" && w.Header().Get(ImpersonatedUserHeader) != ""
+			if impersonated != (len(*auditor) == 1) {
+				t.Fatalf("Expected an audit entry for impersonated requests, got %d", len(*auditor))
+			}
+			if impersonated {
+				record := (*auditor)[0]
+				if record.AdminID != "alice" || record.UserID != "bob" || record.Reason != "TICKET-42" || record.Status != w.Code {
+					t.Errorf("Unexpected audit entry %+v", record)
+				}
+			}
+		})
+	}
+}
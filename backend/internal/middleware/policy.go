@@ -0,0 +1,182 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+)
+
+// Roles granted to authenticated requests.
+const (
+	RoleUser    = "user"    // Users signed in with a session (every user when OIDC is disabled)
+	RoleAdmin   = "admin"   // Administrators (ADMIN group, or every user when OIDC is disabled)
+	RoleService = "service" // Service account tokens, further limited by their scopes
+)
+
+// Route groups access is granted to.
+const (
+	RouteGroupUser     = "user"     // The user's own clients, events, logs, jobs, notifications, quota and settings
+	RouteGroupSessions = "sessions" // The user's login sessions
+	RouteGroupMetrics  = "metrics"  // Prometheus metrics
+	RouteGroupAdmin    = "admin"    // Administration of the instance
+)
+
+// knownRoles and knownRouteGroups are the values a policy may refer to.
+var (
+	knownRoles       = []string{RoleUser, RoleAdmin, RoleService}
+	knownRouteGroups = []string{RouteGroupUser, RouteGroupSessions, RouteGroupMetrics, RouteGroupAdmin}
+)
+
+// Policy maps the roles of authenticated requests to the route groups they may access.
+type Policy struct {
+	SessionRoles []string            `json:"sessionRoles"` // Roles of every user signed in with a session
+	GroupRoles   map[string][]string `json:"groupRoles"`   // Additional roles of OIDC groups
+	Routes       map[string][]string `json:"routes"`       // Roles allowed on each route group
+}
+
+// DefaultPolicy returns the built-in policy: users access their own resources, sessions
+// and metrics, service accounts everything but sessions and administration (as far as
+// their scopes allow), and only the ADMIN group the administration routes.
+func DefaultPolicy() *Policy {
+	return &Policy{
+		SessionRoles: []string{RoleUser},
+		GroupRoles:   map[string][]string{AdminGroup: {RoleAdmin}},
+		Routes: map[string][]string{
+			RouteGroupUser:     {RoleUser, RoleAdmin, RoleService},
+			RouteGroupSessions: {RoleUser, RoleAdmin},
+			RouteGroupMetrics:  {RoleUser, RoleAdmin, RoleService},
+			RouteGroupAdmin:    {RoleAdmin},
+		},
+	}
+}
+
+// LoadPolicy reads a JSON policy file. Settings missing from the file, including the
+// roles of route groups it doesn't list, keep their defaults.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file Policy
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	policy := DefaultPolicy()
+	if file.SessionRoles != nil {
+		policy.SessionRoles = file.SessionRoles
+	}
+	if file.GroupRoles != nil {
+		policy.GroupRoles = file.GroupRoles
+	}
+	for group, roles := range file.Routes {
+		if !contains(knownRouteGroups, group) {
+			return nil, fmt.Errorf("unknown route group %q (known: %v)", group, knownRouteGroups)
+		}
+		policy.Routes[group] = roles
+	}
+
+	allRoles := [][]string{policy.SessionRoles}
+	for _, roles := range policy.GroupRoles {
+		allRoles = append(allRoles, roles)
+	}
+	for _, roles := range policy.Routes {
+		allRoles = append(allRoles, roles)
+	}
+	for _, roles := range allRoles {
+		for _, role := range roles {
+			if !contains(knownRoles, role) {
+				return nil, fmt.Errorf("unknown role %q (known: %v)", role, knownRoles)
+			}
+		}
+	}
+	return policy, nil
+}
+
+// Authorizer enforces a policy on route groups.
+type Authorizer struct {
+	policy      *Policy
+	oidcEnabled bool
+}
+
+// NewAuthorizer creates an authorizer for the policy. When OIDC is disabled there is a
+// single implicit user, who holds the user and admin roles.
+func NewAuthorizer(policy *Policy, oidcEnabled bool) *Authorizer {
+	return &Authorizer{policy: policy, oidcEnabled: oidcEnabled}
+}
+
+// Require is a middleware restricting a route group to the roles the policy allows on it.
+// Requests of service accounts need the required scopes of the routes in addition.
+func (a *Authorizer) Require(group string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Impersonated requests are limited to the user's own resources
+		if isImpersonated(c) && group != RouteGroupUser {
+			abortWithError(c, http.StatusForbidden, apperrors.CodeAccessDenied, "Impersonated requests may only access the user's own resources")
+			return
+		}
+
+		roles := a.Roles(c)
+		for _, role := range a.policy.Routes[group] {
+			if contains(roles, role) {
+				c.Next()
+				return
+			}
+		}
+
+		if group == RouteGroupAdmin {
+			abortWithError(c, http.StatusForbidden, apperrors.CodeAdminRequired, apperrors.ErrAdminRequired.Message)
+			return
+		}
+		abortWithError(c, http.StatusForbidden, apperrors.CodeAccessDenied, apperrors.ErrAccessDenied.Message)
+	}
+}
+
+// Roles returns the roles of the request, as identified by the Auth middleware.
+// Impersonated requests hold the user role only.
+func (a *Authorizer) Roles(c *gin.Context) []string {
+	if isImpersonated(c) {
+		return []string{RoleUser}
+	}
+	if _, isToken := c.Get("serviceAccount"); isToken {
+		return []string{RoleService}
+	}
+	if !a.oidcEnabled {
+		return []string{RoleUser, RoleAdmin}
+	}
+	if session, ok := c.Get("session"); ok {
+		if si, ok := session.(SessionInfo); ok {
+			return a.SessionRoles(si.GetGroups())
+		}
+	}
+	return nil
+}
+
+// SessionRoles returns the roles of a user signed in with a session in the given groups.
+func (a *Authorizer) SessionRoles(groups []string) []string {
+	roles := append([]string{}, a.policy.SessionRoles...)
+	for _, group := range groups {
+		for _, role := range a.policy.GroupRoles[group] {
+			if !contains(roles, role) {
+				roles = append(roles, role)
+			}
+		}
+	}
+	return roles
+}
+
+// contains reports whether values includes value.
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
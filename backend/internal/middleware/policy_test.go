@@ -0,0 +1,136 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type testSession struct {
+	groups []string
+}
+
+func (s *testSession) GetUserID() string   { return "alice" }
+func (s *testSession) GetEmail() string    { return "alice@example.com" }
+func (s *testSession) GetGroups() []string { return s.groups }
+
+type testSessionValidator map[string]*testSession
+
+func (v testSessionValidator) GetSession(sessionID string) (interface{}, bool) {
+	info, ok := v[sessionID]
+	return info, ok
+}
+
+func TestAuthorizerRouteGroups(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessions := testSessionValidator{
+		"user":    {groups: []string{"DEV"}},
+		"admin":   {groups: []string{AdminGroup}},
+		"support": {groups: []string{"SUPPORT"}},
+	}
+	tokens := testTokenValidator{"gsa_all": {userID: "alice", scopes: []string{"*"}}}
+
+	restricted := DefaultPolicy()
+	restricted.SessionRoles = nil
+	restricted.GroupRoles = map[string][]string{"SUPPORT": {RoleUser}, AdminGroup: {RoleUser, RoleAdmin}}
+	restricted.Routes[RouteGroupMetrics] = []string{RoleAdmin}
+
+	tests := []struct {
+		name           string
+		policy         *Policy
+		session        string
+		authorization  string
+		path           string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "User on user routes", policy: DefaultPolicy(), session: "user", path: "/user", expectedStatus: http.StatusOK},
+		{name: "User on admin routes", policy: DefaultPolicy(), session: "user", path: "/admin", expectedStatus: http.StatusForbidden, expectedCode: "ADMIN_REQUIRED"},
+		{name: "Admin on admin routes", policy: DefaultPolicy(), session: "admin", path: "/admin", expectedStatus: http.StatusOK},
+		{name: "Token on user routes", policy: DefaultPolicy(), authorization: "Bearer gsa_all", path: "/user", expectedStatus: http.StatusOK},
+		{name: "Token on session routes", policy: DefaultPolicy(), authorization: "Bearer gsa_all", path: "/sessions", expectedStatus: http.StatusForbidden, expectedCode: "ACCESS_DENIED"},
+		{name: "User outside the allowed groups", policy: restricted, session: "user", path: "/user", expectedStatus: http.StatusForbidden, expectedCode: "ACCESS_DENIED"},
+		{name: "User in an allowed group", policy: restricted, session: "support", path: "/user", expectedStatus: http.StatusOK},
+		{name: "Metrics restricted to admins", policy: restricted, session: "support", path: "/metrics", expectedStatus: http.StatusForbidden, expectedCode: "ACCESS_DENIED"},
+		{name: "Admin on restricted metrics", policy: restricted, session: "admin", path: "/metrics", expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authorizer := NewAuthorizer(tt.policy, true)
+			router := gin.New()
+			router.Use(Auth(true, sessions, tokens))
+			ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+			router.GET("/user", authorizer.Require(RouteGroupUser), ok)
+			router.GET("/sessions", authorizer.Require(RouteGroupSessions), ok)
+			router.GET("/metrics", authorizer.Require(RouteGroupMetrics), ok)
+			router.GET("/admin", authorizer.Require(RouteGroupAdmin), ok)
+
+			req := httptest.NewRequest("GET", tt.path, nil)
+			req.Header.Set("Accept", "application/json")
+			if tt.session != "" {
+				req.AddCookie(&http.Cookie{Name: "session", Value: tt.session})
+			}
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d (%s)", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedCode != "" && !strings.Contains(w.Body.String(), `"code":"`+tt.expectedCode+`"`) {
+				t.Errorf("Expected error code %s, got %s", tt.expectedCode, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	write := func(t *testing.T, content string) string {
+		path := filepath.Join(t.TempDir(), "policy.json")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	t.Run("Merges the file over the defaults", func(t *testing.T) {
+		policy, err := LoadPolicy(write(t, `{"routes": {"metrics": ["admin"]}}`))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := policy.Routes[RouteGroupMetrics]; len(got) != 1 || got[0] != RoleAdmin {
+			t.Errorf("Expected metrics to be restricted to admins, got %v", got)
+		}
+		if got := policy.Routes[RouteGroupUser]; len(got) != 3 {
+			t.Errorf("Expected the default roles of user routes, got %v", got)
+		}
+		if got := policy.SessionRoles; len(got) != 1 || got[0] != RoleUser {
+			t.Errorf("Expected the default session roles, got %v", got)
+		}
+	})
+
+	invalid := map[string]string{
+		"Unknown route group": `{"routes": {"billing": ["admin"]}}`,
+		"Unknown role":        `{"groupRoles": {"OPS": ["operator"]}}`,
+		"Malformed JSON":      `{"routes": `,
+	}
+	for name, content := range invalid {
+		t.Run(name, func(t *testing.T) {
+			if _, err := LoadPolicy(write(t, content)); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
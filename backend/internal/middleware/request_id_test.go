@@ -0,0 +1,57 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package middleware
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		requestID    string
+		expectReused bool
+	}{
+		{name: "Reuses a valid client request ID", requestID: "abc-123", expectReused: true},
+		{name: "Generates an ID when missing", requestID: ""},
+		{name: "Replaces IDs with spaces", requestID: "bad id"},
+		{name: "Replaces overlong IDs", requestID: strings.Repeat("a", maxRequestIDLength+1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var contextID string
+			router := gin.New()
+			router.Use(RequestID())
+			router.GET("/test", func(c *gin.Context) {
+				contextID = c.GetString("requestID")
+				c.Status(204)
+			})
+
+			req := httptest.NewRequest("GET", "/test", nil)
+			if tt.requestID != "" {
+				req.Header.Set(RequestIDHeader, tt.requestID)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			responseID := w.Header().Get(RequestIDHeader)
+			if responseID == "" {
+				t.Fatal("Expected a request ID in the response")
+			}
+			if responseID != contextID {
+				t.Errorf("Response ID %q does not match context ID %q", responseID, contextID)
+			}
+			if reused := responseID == tt.requestID; reused != tt.expectReused {
+				t.Errorf("Expected reused=%v, got ID %q", tt.expectReused, responseID)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import (
+	"time"
+)
+
+// CircuitState represents the state of a client's delivery circuit breaker.
+type CircuitState string
+
+const (
+	CircuitStateClosed   CircuitState = "closed"    // Deliveries flow normally
+	CircuitStateOpen     CircuitState = "open"      // Deliveries are paused after repeated failures
+	CircuitStateHalfOpen CircuitState = "half_open" // A single probe delivery is allowed
+)
+
+// CircuitStatus represents the delivery circuit breaker status of a client.
+type CircuitStatus struct {
+	ClientID            string       `json:"clientId"`              // Client instance ID
+	State               CircuitState `json:"state"`                 // Current state
+	ConsecutiveFailures int          `json:"consecutiveFailures"`   // Consecutive failed deliveries
+	Threshold           int          `json:"threshold"`             // Failures required to open the circuit
+	OpenedAt            *time.Time   `json:"openedAt,omitempty"`    // Time the circuit was last opened
+	NextProbeAt         *time.Time   `json:"nextProbeAt,omitempty"` // Earliest time of the next probe delivery
+	LastError           string       `json:"lastError,omitempty"`   // Error of the last failed delivery
+}
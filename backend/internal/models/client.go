@@ -5,6 +5,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 )
 
@@ -12,9 +13,11 @@ import (
 type ClientStatus string
 
 const (
-	ClientStatusRunning ClientStatus = "running" // Client process is running
-	ClientStatusStopped ClientStatus = "stopped" // Client process is stopped
-	ClientStatusError   ClientStatus = "error"   // Client process encountered an error
+	ClientStatusStarting ClientStatus = "starting" // Client process is starting up or queued for a start slot
+	ClientStatusRunning  ClientStatus = "running"  // Client process is running
+	ClientStatusStopping ClientStatus = "stopping" // Client process is shutting down
+	ClientStatusStopped  ClientStatus = "stopped"  // Client process is stopped
+	ClientStatusError    ClientStatus = "error"    // Client process encountered an error
 )
 
 // Client represents a gosmee client instance configuration and status.
@@ -22,24 +25,50 @@ type Client struct {
 	ID          string       `json:"id"`          // Unique client identifier (UUID)
 	UserID      string       `json:"userId"`      // User ID (for OIDC multi-tenancy)
 	Name        string       `json:"name"`        // User-friendly name
+	Slug        string       `json:"slug"`        // URL-friendly name, unique per user, usable instead of the ID in API paths
 	Description string       `json:"description"` // Instance description
 	Status      ClientStatus `json:"status"`      // Current status
 
+	Runbook string `json:"runbook,omitempty"` // Long-form Markdown notes: what the relay feeds, escalation contacts, replay procedures
+
+	StatusChangedAt *time.Time         `json:"statusChangedAt,omitempty"` // Time of the last status transition
+	Transitions     []ClientTransition `json:"transitions,omitempty"`     // Most recent status transitions, oldest first
+
 	// Gosmee configuration
 	SmeeURL       string   `json:"smeeUrl"`                // Gosmee server event source URL
 	TargetURL     string   `json:"targetUrl"`              // Target webhook receiver URL
+	Provider      string   `json:"provider,omitempty"`     // Webhook provider (github, gitlab, bitbucket, stripe or custom)
 	TargetTimeout int      `json:"targetTimeout"`          // Target connection timeout in seconds
 	HTTPie        bool     `json:"httpie"`                 // Generate HTTPie scripts instead of cURL
 	IgnoreEvents  []string `json:"ignoreEvents,omitempty"` // Event types to filter
 	NoReplay      bool     `json:"noReplay"`               // Save only, don't forward events
 	SSEBufferSize int      `json:"sseBufferSize"`          // SSE buffer size in bytes
+	LogLevel      string   `json:"logLevel,omitempty"`     // Verbosity of the gosmee process logs (default: info)
+
+	// Delivery configuration
+	RetryPolicy *RetryPolicy `json:"retryPolicy,omitempty"` // Automatic retry of failed deliveries (optional)
+	Paused      bool         `json:"paused"`                // Forwarding temporarily paused (events are saved only)
+	TargetAuth  *TargetAuth  `json:"targetAuth,omitempty"`  // Credentials sent to the target (optional, secrets stored separately)
+	Ack         *AckConfig   `json:"ack,omitempty"`         // Delivery receipts returned by the target (optional)
+
+	// Storage configuration
+	RedactionRules []RedactionRule `json:"redactionRules,omitempty"` // Payload values replaced before events are stored
+	Sampling       *SamplingPolicy `json:"sampling,omitempty"`       // Share of new events kept (optional, default: all)
+
+	// Payload validation
+	PayloadSchema *PayloadSchemaConfig `json:"payloadSchema,omitempty"` // JSON Schemas event payloads are validated against (optional)
+
+	// Scheduling
+	Schedule *ClientSchedule `json:"schedule,omitempty"` // Automatic start/stop schedule (optional)
 
 	// Process information
-	PID          int        `json:"pid,omitempty"`       // Process ID (when running)
-	StartedAt    *time.Time `json:"startedAt,omitempty"` // Last start time
-	StoppedAt    *time.Time `json:"stoppedAt,omitempty"` // Last stop time
-	RestartCount int        `json:"restartCount"`        // Number of restarts
-	LastError    string     `json:"lastError,omitempty"` // Last error message
+	PID               int        `json:"pid,omitempty"`               // Process ID (when running)
+	StartedAt         *time.Time `json:"startedAt,omitempty"`         // Last start time
+	StoppedAt         *time.Time `json:"stoppedAt,omitempty"`         // Last stop time
+	RestartCount      int        `json:"restartCount"`                // Number of restarts
+	LastError         string     `json:"lastError,omitempty"`         // Last error message
+	LastExitCode      *int       `json:"lastExitCode,omitempty"`      // Exit code of the last unexpected process exit
+	LastErrorCategory string     `json:"lastErrorCategory,omitempty"` // Category of the last error (e.g. "dns_error")
 
 	// Statistics
 	TodayEvents  int        `json:"todayEvents"`            // Events forwarded today
@@ -49,6 +78,34 @@ type Client struct {
 	// Metadata
 	CreatedAt time.Time `json:"createdAt"` // Creation timestamp
 	UpdatedAt time.Time `json:"updatedAt"` // Last update timestamp
+	Version   int       `json:"version"`   // Revision of the configuration, incremented on every configuration update
+}
+
+// Client log levels, controlling the verbosity of a client's gosmee process.
+const (
+	ClientLogLevelInfo  = "info"  // Default gosmee output
+	ClientLogLevelDebug = "debug" // Verbose relay logs, e.g. to troubleshoot a single client
+)
+
+// Client error categories, classifying why a client's gosmee process failed.
+const (
+	ClientErrorBinaryMissing   = "binary_missing"   // The gosmee executable is not installed
+	ClientErrorInvalidSmeeURL  = "invalid_smee_url" // The smee URL is malformed or the channel does not exist
+	ClientErrorSmeeUnreachable = "smee_unreachable" // The smee server refused the connection
+	ClientErrorDNS             = "dns_error"        // A host name could not be resolved
+	ClientErrorTargetRefused   = "target_refused"   // The target refused the connection
+	ClientErrorUnknown         = "unknown"          // Any other failure, see the error message
+)
+
+// HasTargetTemplate reports whether the target URL contains template variables resolved
+// per event (e.g. "https://api.internal/hooks/{{.EventType}}").
+func (c *Client) HasTargetTemplate() bool {
+	return IsTargetTemplate(c.TargetURL)
+}
+
+// IsTargetTemplate reports whether a target URL contains template variables.
+func IsTargetTemplate(targetURL string) bool {
+	return strings.Contains(targetURL, "{{")
 }
 
 // NewClient creates a new client instance with default values.
@@ -71,46 +128,268 @@ func NewClient(id, userID, name, description, smeeURL, targetURL string) *Client
 		TotalEvents:   0,
 		CreatedAt:     now,
 		UpdatedAt:     now,
+		Version:       1,
 	}
 }
 
 // ToSummary converts a Client to ClientSummary (for list queries).
 func (c *Client) ToSummary() *ClientSummary {
 	return &ClientSummary{
-		ID:           c.ID,
-		Name:         c.Name,
-		Status:       string(c.Status),
-		SmeeURL:      c.SmeeURL,
-		TargetURL:    c.TargetURL,
-		TodayEvents:  c.TodayEvents,
-		TotalEvents:  c.TotalEvents,
-		LastActivity: c.LastActivity,
+		ID:              c.ID,
+		Name:            c.Name,
+		Slug:            c.Slug,
+		Status:          string(c.Status),
+		StatusChangedAt: c.StatusChangedAt,
+		SmeeURL:         c.SmeeURL,
+		TargetURL:       c.TargetURL,
+		TodayEvents:     c.TodayEvents,
+		TotalEvents:     c.TotalEvents,
+		LastActivity:    c.LastActivity,
+		Paused:          c.Paused,
 	}
 }
 
 // ClientSummary represents a summarized view of a client (for list queries).
 type ClientSummary struct {
-	ID           string     `json:"id"`
-	Name         string     `json:"name"`
-	Status       string     `json:"status"`
-	SmeeURL      string     `json:"smeeUrl"`
-	TargetURL    string     `json:"targetUrl"`
-	TodayEvents  int        `json:"todayEvents"`
-	TotalEvents  int        `json:"totalEvents"`
-	LastActivity *time.Time `json:"lastActivity,omitempty"`
+	ID              string     `json:"id"`
+	Name            string     `json:"name"`
+	Slug            string     `json:"slug"`
+	Status          string     `json:"status"`
+	StatusChangedAt *time.Time `json:"statusChangedAt,omitempty"`
+	SmeeURL         string     `json:"smeeUrl"`
+	TargetURL       string     `json:"targetUrl"`
+	TodayEvents     int        `json:"todayEvents"`
+	TotalEvents     int        `json:"totalEvents"`
+	LastActivity    *time.Time `json:"lastActivity,omitempty"`
+	Paused          bool       `json:"paused"`
 }
 
 // ClientRequest represents the request body for creating/updating a client.
 type ClientRequest struct {
-	Name          string   `json:"name" binding:"required"`      // Instance name (required)
-	Description   string   `json:"description"`                  // Instance description (optional)
-	SmeeURL       string   `json:"smeeUrl" binding:"required"`   // Smee server URL (required)
-	TargetURL     string   `json:"targetUrl" binding:"required"` // Target URL (required)
-	TargetTimeout int      `json:"targetTimeout"`                // Target timeout (optional, default: 60)
-	HTTPie        bool     `json:"httpie"`                       // Use HTTPie format (optional)
-	IgnoreEvents  []string `json:"ignoreEvents"`                 // Events to ignore (optional)
-	NoReplay      bool     `json:"noReplay"`                     // Save only mode (optional)
-	SSEBufferSize int      `json:"sseBufferSize"`                // SSE buffer size (optional, default: 1048576)
+	Name          string   `json:"name" binding:"required"`                       // Instance name (required)
+	Slug          string   `json:"slug" binding:"omitempty,max=63"`               // URL-friendly name (optional, default: derived from the name, kept on rename)
+	Description   string   `json:"description"`                                   // Instance description (optional)
+	Runbook       string   `json:"runbook" binding:"max=20000"`                   // Markdown runbook (optional, at most 20000 characters)
+	SmeeURL       string   `json:"smeeUrl" binding:"required"`                    // Smee server URL (required)
+	TargetURL     string   `json:"targetUrl" binding:"required"`                  // Target URL (required)
+	TargetTimeout int      `json:"targetTimeout"`                                 // Target timeout (optional, default: 60)
+	HTTPie        bool     `json:"httpie"`                                        // Use HTTPie format (optional)
+	IgnoreEvents  []string `json:"ignoreEvents"`                                  // Events to ignore (optional)
+	NoReplay      bool     `json:"noReplay"`                                      // Save only mode (optional)
+	SSEBufferSize int      `json:"sseBufferSize"`                                 // SSE buffer size (optional, default: 1048576)
+	LogLevel      string   `json:"logLevel" binding:"omitempty,oneof=info debug"` // gosmee log verbosity (optional, default: info)
+
+	Provider string `json:"provider" binding:"omitempty,oneof=github gitlab bitbucket stripe custom"` // Webhook provider (optional, default: custom)
+
+	RetryPolicy    *RetryPolicy         `json:"retryPolicy"`                          // Automatic retry policy (optional)
+	Schedule       *ClientSchedule      `json:"schedule"`                             // Automatic start/stop schedule (optional)
+	RedactionRules []RedactionRule      `json:"redactionRules" binding:"max=50,dive"` // Payload redaction rules (optional)
+	TargetAuth     *TargetAuthRequest   `json:"targetAuth"`                           // Target credentials (optional, nil removes them)
+	Ack            *AckConfig           `json:"ack"`                                  // Delivery receipts (optional)
+	Sampling       *SamplingPolicy      `json:"sampling"`                             // Event sampling (optional, empty mode keeps all events)
+	PayloadSchema  *PayloadSchemaConfig `json:"payloadSchema"`                        // Payload JSON Schemas (optional, nil removes them)
+
+	Version          int  `json:"version" binding:"omitempty,min=1"` // Only update the client at this version (optional, updates only, see If-Match)
+	StartImmediately bool `json:"startImmediately"`                  // Start the client right after creating it, deleting it again if it fails to start (optional, creation only)
+}
+
+// ClientPatchRequest represents the request body for partially updating a client: only the
+// fields present in the request are changed, the others keep their current value.
+type ClientPatchRequest struct {
+	Name          *string   `json:"name" binding:"omitempty,min=1"`                                           // Instance name
+	Slug          *string   `json:"slug" binding:"omitempty,max=63"`                                          // URL-friendly name
+	Description   *string   `json:"description"`                                                              // Instance description
+	Runbook       *string   `json:"runbook" binding:"omitempty,max=20000"`                                    // Markdown runbook (empty removes it)
+	TargetURL     *string   `json:"targetUrl" binding:"omitempty,min=1"`                                      // Target URL
+	TargetTimeout *int      `json:"targetTimeout" binding:"omitempty,min=0"`                                  // Target timeout
+	HTTPie        *bool     `json:"httpie"`                                                                   // Use HTTPie format
+	IgnoreEvents  *[]string `json:"ignoreEvents"`                                                             // Events to ignore (empty list ignores none)
+	NoReplay      *bool     `json:"noReplay"`                                                                 // Save only mode
+	SSEBufferSize *int      `json:"sseBufferSize" binding:"omitempty,min=0"`                                  // SSE buffer size
+	LogLevel      *string   `json:"logLevel" binding:"omitempty,oneof=info debug"`                            // gosmee log verbosity
+	Provider      *string   `json:"provider" binding:"omitempty,oneof=github gitlab bitbucket stripe custom"` // Webhook provider
+
+	RetryPolicy    *RetryPolicy         `json:"retryPolicy"`                                    // Automatic retry policy (disabled removes it)
+	Schedule       *ClientSchedule      `json:"schedule"`                                       // Automatic start/stop schedule
+	RedactionRules *[]RedactionRule     `json:"redactionRules" binding:"omitempty,max=50,dive"` // Payload redaction rules (empty list removes them)
+	TargetAuth     *TargetAuthRequest   `json:"targetAuth"`                                     // Target credentials (empty secrets keep the stored ones)
+	Ack            *AckConfig           `json:"ack"`                                            // Delivery receipts (disabled removes them)
+	Sampling       *SamplingPolicy      `json:"sampling"`                                       // Event sampling (empty mode removes it)
+	PayloadSchema  *PayloadSchemaConfig `json:"payloadSchema"`                                  // Payload JSON Schemas (no schemas removes them)
+
+	Version int `json:"version" binding:"omitempty,min=1"` // Only update the client at this version (optional, see If-Match)
+}
+
+// ApplyTo overwrites the fields of req present in the patch.
+func (p *ClientPatchRequest) ApplyTo(req *ClientRequest) {
+	if p.Name != nil {
+		req.Name = *p.Name
+	}
+	if p.Slug != nil {
+		req.Slug = *p.Slug
+	}
+	if p.Description != nil {
+		req.Description = *p.Description
+	}
+	if p.Runbook != nil {
+		req.Runbook = *p.Runbook
+	}
+	if p.TargetURL != nil {
+		req.TargetURL = *p.TargetURL
+	}
+	if p.TargetTimeout != nil {
+		req.TargetTimeout = *p.TargetTimeout
+	}
+	if p.HTTPie != nil {
+		req.HTTPie = *p.HTTPie
+	}
+	if p.IgnoreEvents != nil {
+		req.IgnoreEvents = *p.IgnoreEvents
+	}
+	if p.NoReplay != nil {
+		req.NoReplay = *p.NoReplay
+	}
+	if p.SSEBufferSize != nil {
+		req.SSEBufferSize = *p.SSEBufferSize
+	}
+	if p.LogLevel != nil {
+		req.LogLevel = *p.LogLevel
+	}
+	if p.Provider != nil {
+		req.Provider = *p.Provider
+	}
+	if p.RetryPolicy != nil {
+		req.RetryPolicy = p.RetryPolicy
+	}
+	if p.Schedule != nil {
+		req.Schedule = p.Schedule
+	}
+	if p.RedactionRules != nil {
+		req.RedactionRules = *p.RedactionRules
+	}
+	if p.TargetAuth != nil {
+		req.TargetAuth = p.TargetAuth
+	}
+	if p.Ack != nil {
+		req.Ack = p.Ack
+	}
+	if p.Sampling != nil {
+		req.Sampling = p.Sampling
+	}
+	if p.PayloadSchema != nil {
+		req.PayloadSchema = p.PayloadSchema
+	}
+	req.Version = p.Version
+}
+
+// DefaultAckHeader is the response header the target returns delivery tokens in by default.
+const DefaultAckHeader = "X-Delivery-Token"
+
+// AckConfig enables delivery receipts for a client: the target acknowledges a delivery by
+// returning a delivery token in a response header, which is recorded on the event.
+type AckConfig struct {
+	Enabled bool   `json:"enabled"`                            // Whether delivery receipts are recorded
+	Header  string `json:"header,omitempty" binding:"max=100"` // Response header with the token (default: X-Delivery-Token)
+}
+
+// Target authentication types.
+const (
+	TargetAuthBasic  = "basic"  // HTTP basic authentication with username and password
+	TargetAuthBearer = "bearer" // Bearer token in the Authorization header
+	TargetAuthOAuth2 = "oauth2" // Bearer token acquired with the OAuth2 client credentials flow
+)
+
+// TargetAuth describes the credentials sent to the target with forwarded and replayed events.
+// The password or token itself is kept in the client's secrets, never in the client config.
+type TargetAuth struct {
+	Type     string   `json:"type"`               // Authentication type: basic, bearer or oauth2
+	Username string   `json:"username,omitempty"` // Username (basic only)
+	TokenURL string   `json:"tokenUrl,omitempty"` // Token endpoint (oauth2 only)
+	ClientID string   `json:"clientId,omitempty"` // OAuth2 client ID (oauth2 only)
+	Scopes   []string `json:"scopes,omitempty"`   // Requested scopes (oauth2 only)
+	Audience string   `json:"audience,omitempty"` // Requested audience, for providers requiring one (oauth2 only)
+}
+
+// TargetAuthRequest configures the target credentials of a client. When updating a client,
+// an empty password, token or client secret keeps the stored one if the authentication type
+// is unchanged.
+type TargetAuthRequest struct {
+	Type         string   `json:"type" binding:"required,oneof=basic bearer oauth2"` // Authentication type
+	Username     string   `json:"username" binding:"max=200"`                        // Username (basic only)
+	Password     string   `json:"password" binding:"max=1000"`                       // Password (basic only, write-only)
+	Token        string   `json:"token" binding:"max=4000"`                          // Bearer token (bearer only, write-only)
+	TokenURL     string   `json:"tokenUrl" binding:"max=2000"`                       // Token endpoint (oauth2 only)
+	ClientID     string   `json:"clientId" binding:"max=200"`                        // OAuth2 client ID (oauth2 only)
+	ClientSecret string   `json:"clientSecret" binding:"max=1000"`                   // OAuth2 client secret (oauth2 only, write-only)
+	Scopes       []string `json:"scopes" binding:"max=20,dive,max=200"`              // Requested scopes (oauth2 only)
+	Audience     string   `json:"audience" binding:"max=500"`                        // Requested audience (oauth2 only)
+}
+
+// ClientSecrets holds the secrets of a client, stored apart from the client configuration
+// and encrypted when encryption at rest is enabled.
+type ClientSecrets struct {
+	TargetPassword     string `json:"targetPassword,omitempty"`     // Basic authentication password of the target
+	TargetToken        string `json:"targetToken,omitempty"`        // Bearer token of the target
+	TargetClientSecret string `json:"targetClientSecret,omitempty"` // OAuth2 client secret of the target
+}
+
+// IsEmpty reports whether no secret is set.
+func (s *ClientSecrets) IsEmpty() bool {
+	return *s == ClientSecrets{}
+}
+
+// RedactionRule replaces the payload values selected by a JSONPath expression before an event
+// is stored, so that secrets and personal data in webhook payloads are never written to disk.
+type RedactionRule struct {
+	Path    string `json:"path" binding:"required,max=200"`     // JSONPath of the values to replace (e.g. "$.pusher.email", "$..token")
+	Replace string `json:"replace,omitempty" binding:"max=200"` // Replacement value (default: "[REDACTED]")
+}
+
+// RetryPolicy configures automatic retries of failed deliveries for a client.
+// Retries are spaced with exponential backoff: InitialBackoffSeconds * 2^(attempt-1), capped at MaxBackoffSeconds.
+type RetryPolicy struct {
+	Enabled               bool `json:"enabled"`               // Whether automatic retry is enabled
+	MaxAttempts           int  `json:"maxAttempts"`           // Maximum number of automatic retries (default: 5)
+	InitialBackoffSeconds int  `json:"initialBackoffSeconds"` // Delay before the first retry (default: 30)
+	MaxBackoffSeconds     int  `json:"maxBackoffSeconds"`     // Upper bound of the retry delay (default: 3600)
+}
+
+// Normalize fills unset retry policy values with defaults.
+func (p *RetryPolicy) Normalize() {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 5
+	}
+	if p.InitialBackoffSeconds <= 0 {
+		p.InitialBackoffSeconds = 30
+	}
+	if p.MaxBackoffSeconds <= 0 {
+		p.MaxBackoffSeconds = 3600
+	}
+	if p.MaxBackoffSeconds < p.InitialBackoffSeconds {
+		p.MaxBackoffSeconds = p.InitialBackoffSeconds
+	}
+}
+
+// Backoff returns the delay to wait before the given retry attempt (1-based).
+func (p *RetryPolicy) Backoff(attempt int) time.Duration {
+	delay := time.Duration(p.InitialBackoffSeconds) * time.Second
+	maxDelay := time.Duration(p.MaxBackoffSeconds) * time.Second
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay
+}
+
+// Schedule returns the time from an event to its last retry if every retry is made when due.
+func (p *RetryPolicy) Schedule() time.Duration {
+	var schedule time.Duration
+	for attempt := 1; attempt <= p.MaxAttempts; attempt++ {
+		schedule += p.Backoff(attempt)
+	}
+	return schedule
 }
 
 // ClientListRequest represents query parameters for listing clients.
@@ -123,12 +402,25 @@ type ClientListRequest struct {
 	SortOrder string `form:"sortOrder,default=desc"`   // Sort order: asc/desc (default: desc)
 }
 
+// ClientExportRequest represents query parameters for exporting clients.
+type ClientExportRequest struct {
+	Format string `form:"format,default=csv" binding:"oneof=csv"` // Export format (default: csv)
+}
+
+// ClientStopRequest represents query parameters for stopping a client.
+type ClientStopRequest struct {
+	Force   bool `form:"force"`                                      // Kill the process right away instead of sending SIGTERM first
+	Timeout *int `form:"timeout" binding:"omitempty,min=0,max=3600"` // Seconds to wait for the process to exit before killing it (default: server stop timeout)
+}
+
 // ClientListResponse represents the response for client list queries.
 type ClientListResponse struct {
-	Total    int              `json:"total"`    // Total number of clients matching filter
-	Page     int              `json:"page"`     // Current page number
-	PageSize int              `json:"pageSize"` // Items per page
-	Clients  []*ClientSummary `json:"clients"`  // Client summaries for current page
+	Total    int              `json:"total"`          // Total number of clients matching filter
+	Page     int              `json:"page"`           // Current page number
+	PageSize int              `json:"pageSize"`       // Items per page
+	Clients  []*ClientSummary `json:"clients"`        // Client summaries for current page
+	Next     string           `json:"next,omitempty"` // URL of the next page (absent on the last page)
+	Prev     string           `json:"prev,omitempty"` // URL of the previous page (absent on the first page)
 }
 
 // ClientBatchRequest represents a batch operation request for clients.
@@ -144,6 +436,14 @@ type ClientBatchResult struct {
 	Message  string `json:"message,omitempty"` // Optional error or info message
 }
 
+// ClientRollingRestartRequest represents a rolling restart request for running clients.
+type ClientRollingRestartRequest struct {
+	ClientIDs       []string `json:"clientIds"`       // Client IDs to restart (optional, default: all running clients)
+	BatchSize       int      `json:"batchSize"`       // Clients restarted at a time (optional, default: 1)
+	DelaySeconds    int      `json:"delaySeconds"`    // Delay between batches in seconds (optional, default: 5)
+	ContinueOnError bool     `json:"continueOnError"` // Keep going when a restarted client fails its health check (optional)
+}
+
 // ClientBatchResponse represents the aggregated result of a batch operation.
 type ClientBatchResponse struct {
 	Total      int                  `json:"total"`      // Total number of clients processed
@@ -151,3 +451,23 @@ type ClientBatchResponse struct {
 	Failed     int                  `json:"failed"`     // Number of failed operations
 	Results    []*ClientBatchResult `json:"results"`    // Per-client results
 }
+
+// Client validation check levels.
+const (
+	ValidationOK   = "ok"   // Check passed
+	ValidationWarn = "warn" // The client can be created, but may not work as intended
+	ValidationFail = "fail" // The client cannot be created or will not work
+)
+
+// ClientValidationCheck is the result of a single check of a client validation.
+type ClientValidationCheck struct {
+	Check   string `json:"check"`   // Check name (quota, config, urls, smee, target or gosmee)
+	Level   string `json:"level"`   // Result level (ok, warn or fail)
+	Message string `json:"message"` // What was found
+}
+
+// ClientValidationResponse is the result of validating a client without creating it.
+type ClientValidationResponse struct {
+	Valid  bool                     `json:"valid"`  // No check failed
+	Checks []*ClientValidationCheck `json:"checks"` // Results of the checks that were run
+}
@@ -0,0 +1,26 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import "time"
+
+// ClientBundleManifest describes the content of a single-client bundle, a zip archive with
+// the configuration, events and logs of one client.
+type ClientBundleManifest struct {
+	Version    int       `json:"version"`    // Bundle layout version
+	ExportedAt time.Time `json:"exportedAt"` // Export time
+	ClientID   string    `json:"clientId"`   // ID of the exported client
+	Name       string    `json:"name"`       // Name of the exported client
+	Events     int       `json:"events"`     // Number of events contained in the bundle
+	LogDays    int       `json:"logDays"`    // Number of daily log files contained in the bundle
+}
+
+// ClientBundleImportResult summarizes a bundle imported as a new client.
+type ClientBundleImportResult struct {
+	Client         *Client  `json:"client"`         // The created client (stopped)
+	SourceClientID string   `json:"sourceClientId"` // ID of the client the bundle was exported from
+	Events         int      `json:"events"`         // Number of imported events
+	LogDays        int      `json:"logDays"`        // Number of imported daily log files
+	Warnings       []string `json:"warnings"`       // Settings that were not imported
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaxClientTransitions is the number of status transitions kept on a client.
+const MaxClientTransitions = 20
+
+// clientTransitions lists the statuses a client may move to from each status:
+// stopped -> starting -> running -> stopping -> stopped, with error reachable from every
+// active status. A running client moves to starting when restarted, and a start that was
+// rejected before the process came up returns to the status it started from.
+var clientTransitions = map[ClientStatus][]ClientStatus{
+	ClientStatusStopped:  {ClientStatusStarting},
+	ClientStatusStarting: {ClientStatusRunning, ClientStatusStopped, ClientStatusError},
+	ClientStatusRunning:  {ClientStatusStopping, ClientStatusStarting, ClientStatusError},
+	ClientStatusStopping: {ClientStatusStopped, ClientStatusError},
+	ClientStatusError:    {ClientStatusStarting, ClientStatusStopped},
+}
+
+// ClientTransition records a status change of a client.
+type ClientTransition struct {
+	From   ClientStatus `json:"from"`             // Previous status
+	To     ClientStatus `json:"to"`               // New status
+	At     time.Time    `json:"at"`               // Time of the change
+	Reason string       `json:"reason,omitempty"` // Why the status changed (e.g. the start error)
+}
+
+// CanTransition reports whether a client may move from one status to another.
+// Staying in the same status is always allowed.
+func CanTransition(from, to ClientStatus) bool {
+	if from == to {
+		return true
+	}
+	for _, allowed := range clientTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Transition moves the client to a new status, recording the time and reason of the change.
+// Clients stored before statuses were tracked (empty status) count as stopped. Staying in the
+// same status changes nothing; a transition the state machine does not allow returns an error.
+func (c *Client) Transition(to ClientStatus, reason string) error {
+	from := c.Status
+	if from == "" {
+		from = ClientStatusStopped
+	}
+	if from == to {
+		c.Status = to
+		return nil
+	}
+	if !CanTransition(from, to) {
+		return fmt.Errorf("invalid status transition from %s to %s", from, to)
+	}
+
+	now := time.Now()
+	c.Status = to
+	c.StatusChangedAt = &now
+	c.Transitions = append(c.Transitions, ClientTransition{From: from, To: to, At: now, Reason: reason})
+	if len(c.Transitions) > MaxClientTransitions {
+		c.Transitions = append([]ClientTransition(nil), c.Transitions[len(c.Transitions)-MaxClientTransitions:]...)
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package models_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+)
+
+var _ = Describe("Client status transitions", func() {
+	It("walks through a start and stop, recording each transition", func() {
+		client := models.NewClient("client", "user", "name", "", "https://smee.example.com/a", "http://127.0.0.1/hook")
+
+		for _, status := range []models.ClientStatus{
+			models.ClientStatusStarting,
+			models.ClientStatusRunning,
+			models.ClientStatusStopping,
+			models.ClientStatusStopped,
+		} {
+			Expect(client.Transition(status, "")).To(Succeed())
+		}
+
+		Expect(client.Status).To(Equal(models.ClientStatusStopped))
+		Expect(client.StatusChangedAt).NotTo(BeNil())
+		Expect(client.Transitions).To(HaveLen(4))
+		Expect(client.Transitions[0].From).To(Equal(models.ClientStatusStopped))
+		Expect(client.Transitions[0].To).To(Equal(models.ClientStatusStarting))
+		Expect(client.Transitions[3].To).To(Equal(models.ClientStatusStopped))
+	})
+
+	It("rejects transitions the state machine does not allow", func() {
+		client := models.NewClient("client", "user", "name", "", "https://smee.example.com/a", "http://127.0.0.1/hook")
+
+		Expect(client.Transition(models.ClientStatusRunning, "")).NotTo(Succeed())
+		Expect(client.Transition(models.ClientStatusStopping, "")).NotTo(Succeed())
+		Expect(client.Status).To(Equal(models.ClientStatusStopped))
+		Expect(client.Transitions).To(BeEmpty())
+	})
+
+	It("lets failed clients be started again", func() {
+		client := models.NewClient("client", "user", "name", "", "https://smee.example.com/a", "http://127.0.0.1/hook")
+		Expect(client.Transition(models.ClientStatusStarting, "")).To(Succeed())
+		Expect(client.Transition(models.ClientStatusError, "process exited during startup")).To(Succeed())
+		Expect(client.Transitions[1].Reason).To(Equal("process exited during startup"))
+
+		Expect(client.Transition(models.ClientStatusStarting, "")).To(Succeed())
+	})
+
+	It("treats clients without a status as stopped", func() {
+		client := &models.Client{}
+
+		Expect(client.Transition(models.ClientStatusStarting, "")).To(Succeed())
+		Expect(client.Transitions[0].From).To(Equal(models.ClientStatusStopped))
+	})
+
+	It("keeps only the most recent transitions", func() {
+		client := models.NewClient("client", "user", "name", "", "https://smee.example.com/a", "http://127.0.0.1/hook")
+		for i := 0; i < models.MaxClientTransitions; i++ {
+			Expect(client.Transition(models.ClientStatusStarting, "")).To(Succeed())
+			Expect(client.Transition(models.ClientStatusStopped, "")).To(Succeed())
+		}
+
+		Expect(client.Transitions).To(HaveLen(models.MaxClientTransitions))
+		Expect(client.Transitions[len(client.Transitions)-1].To).To(Equal(models.ClientStatusStopped))
+	})
+})
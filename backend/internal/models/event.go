@@ -4,11 +4,16 @@
 package models
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
+	"reflect"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 // EventStatus represents the forwarding status of an event.
@@ -17,6 +22,7 @@ type EventStatus string
 const (
 	EventStatusSuccess     EventStatus = "success"      // Successfully forwarded
 	EventStatusFailed      EventStatus = "failed"       // Forward failed
+	EventStatusRetrying    EventStatus = "retrying"     // Forward failed, automatic retry scheduled
 	EventStatusNotReplayed EventStatus = "not_replayed" // Saved but not forwarded (noReplay mode)
 )
 
@@ -27,13 +33,123 @@ type Event struct {
 	Timestamp    time.Time         `json:"timestamp"`              // Event received time
 	EventType    string            `json:"eventType"`              // Event type (e.g., "push", "pull_request")
 	Source       string            `json:"source"`                 // Event source (e.g., "github.com/myorg/myrepo")
+	DeliveryID   string            `json:"deliveryId,omitempty"`   // Delivery ID sent by the webhook provider (e.g., X-GitHub-Delivery)
 	Status       EventStatus       `json:"status"`                 // Forward status
 	StatusCode   int               `json:"statusCode"`             // HTTP status code from target
 	LatencyMs    int               `json:"latencyMs"`              // Response latency in milliseconds
 	Headers      map[string]string `json:"headers"`                // Request headers
-	Payload      string            `json:"payload"`                // Request payload (JSON string)
+	Payload      string            `json:"payload"`                // Request payload (JSON string, base64 for binary payloads)
 	Response     string            `json:"response,omitempty"`     // Response body (if available)
 	ErrorMessage string            `json:"errorMessage,omitempty"` // Error message (if failed)
+
+	// Automatic retry tracking
+	RetryAttempts int        `json:"retryAttempts,omitempty"` // Number of automatic retries performed
+	NextRetryAt   *time.Time `json:"nextRetryAt,omitempty"`   // Time of the next scheduled retry
+
+	// Delivery receipt (clients with ACK mode)
+	AckToken string     `json:"ackToken,omitempty"` // Delivery token returned by the target
+	AckedAt  *time.Time `json:"ackedAt,omitempty"`  // Time the target acknowledged the delivery
+
+	// Payload size limit (payloads above --max-payload-size are truncated)
+	PayloadEncoding     string `json:"payloadEncoding,omitempty"`     // "base64" for binary payloads, empty for text
+	PayloadTruncated    bool   `json:"payloadTruncated,omitempty"`    // Only the beginning of the payload is stored
+	OriginalPayloadSize int    `json:"originalPayloadSize,omitempty"` // Size of the payload as received, in bytes
+
+	// Response size limit (responses above --response-capture-size are truncated)
+	ResponseTruncated bool `json:"responseTruncated,omitempty"` // Only the beginning of the target's response is stored
+
+	// Payload schema validation (clients with a payload schema)
+	SchemaStatus SchemaStatus `json:"schemaStatus,omitempty"` // Outcome of the last validation, empty if not validated
+	SchemaErrors []string     `json:"schemaErrors,omitempty"` // Violations of the schema (invalid payloads)
+
+	// Optimistic concurrency (see EventRepository.Save)
+	Version int `json:"version,omitempty"` // Revision of the stored event, incremented on every update
+
+	// Payload interpretation (event details only, not stored)
+	ContentType string              `json:"contentType,omitempty"` // Content type of the payload, from the headers or detected
+	Form        map[string][]string `json:"form,omitempty"`        // Fields of a form-encoded payload
+
+	// Fingerprint of index entries without headers and payload (not stored, see ComputeFingerprint)
+	Fingerprint string `json:"-"`
+}
+
+// PayloadEncodingBase64 marks payloads stored base64-encoded because they are not valid text.
+const PayloadEncodingBase64 = "base64"
+
+// PayloadBytes returns the payload as sent by the webhook provider, decoding binary payloads.
+func (e *Event) PayloadBytes() ([]byte, error) {
+	if e.PayloadEncoding != PayloadEncodingBase64 {
+		return []byte(e.Payload), nil
+	}
+	data, err := base64.StdEncoding.DecodeString(e.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 payload: %w", err)
+	}
+	return data, nil
+}
+
+// SetPayloadBytes stores a payload, base64-encoding it if it is not valid UTF-8 text.
+func (e *Event) SetPayloadBytes(data []byte) {
+	if utf8.Valid(data) {
+		e.Payload = string(data)
+		e.PayloadEncoding = ""
+		return
+	}
+	e.Payload = base64.StdEncoding.EncodeToString(data)
+	e.PayloadEncoding = PayloadEncodingBase64
+}
+
+// Payload content types detected for events without a Content-Type header.
+const (
+	ContentTypeJSON   = "application/json"
+	ContentTypeForm   = "application/x-www-form-urlencoded"
+	ContentTypeXML    = "application/xml"
+	ContentTypeText   = "text/plain"
+	ContentTypeBinary = "application/octet-stream"
+)
+
+// PayloadContentType returns the content type of the payload: the stored Content-Type
+// header, or the type detected from the payload if the header is missing.
+func (e *Event) PayloadContentType() string {
+	for key, value := range e.Headers {
+		if strings.EqualFold(key, "Content-Type") && value != "" {
+			return value
+		}
+	}
+	if e.PayloadEncoding == PayloadEncodingBase64 {
+		return ContentTypeBinary
+	}
+	return DetectContentType(e.Payload)
+}
+
+// DetectContentType guesses the content type of a payload. Empty payloads count as JSON,
+// the content type of most webhooks.
+func DetectContentType(payload string) string {
+	trimmed := strings.TrimSpace(payload)
+	switch {
+	case trimmed == "" || json.Valid([]byte(trimmed)):
+		return ContentTypeJSON
+	case strings.HasPrefix(trimmed, "<"):
+		return ContentTypeXML
+	case isFormEncoded(trimmed):
+		return ContentTypeForm
+	default:
+		return ContentTypeText
+	}
+}
+
+// isFormEncoded reports whether a payload consists of URL-encoded key=value pairs.
+func isFormEncoded(payload string) bool {
+	if strings.ContainsAny(payload, " \t\r\n") {
+		return false
+	}
+	for _, pair := range strings.Split(payload, "&") {
+		if key, _, ok := strings.Cut(pair, "="); !ok || key == "" {
+			return false
+		}
+	}
+	_, err := url.ParseQuery(payload)
+	return err == nil
 }
 
 // UnmarshalJSON implements custom decoding to support multiple event file formats.
@@ -47,6 +163,7 @@ func (e *Event) UnmarshalJSON(data []byte) error {
 	e.ClientID = firstNonEmptyString(raw, "clientId", "client_id")
 	e.EventType = firstNonEmptyString(raw, "eventType", "event_type")
 	e.Source = extractString(raw, "source")
+	e.DeliveryID = extractString(raw, "deliveryId")
 	e.Status = EventStatus(firstNonEmptyString(raw, "status", "forward_status"))
 
 	if ts := firstNonEmptyString(raw, "timestamp", "time", "created_at"); ts != "" {
@@ -100,31 +217,168 @@ func (e *Event) UnmarshalJSON(data []byte) error {
 		e.ErrorMessage = errMsg
 	}
 
+	e.RetryAttempts = firstNonZeroInt(raw, "retryAttempts")
+	e.NextRetryAt = nil
+	if ts := extractString(raw, "nextRetryAt"); ts != "" {
+		if parsed, err := time.Parse(time.RFC3339, ts); err == nil {
+			e.NextRetryAt = &parsed
+		}
+	}
+
+	e.AckToken = extractString(raw, "ackToken")
+	e.AckedAt = nil
+	if ts := extractString(raw, "ackedAt"); ts != "" {
+		if parsed, err := time.Parse(time.RFC3339, ts); err == nil {
+			e.AckedAt = &parsed
+		}
+	}
+
+	e.PayloadEncoding = extractString(raw, "payloadEncoding")
+	e.PayloadTruncated, _ = raw["payloadTruncated"].(bool)
+	e.OriginalPayloadSize = firstNonZeroInt(raw, "originalPayloadSize")
+	e.ResponseTruncated, _ = raw["responseTruncated"].(bool)
+	e.Version = firstNonZeroInt(raw, "version")
+
+	e.SchemaStatus = SchemaStatus(extractString(raw, "schemaStatus"))
+	e.SchemaErrors = nil
+	if values, ok := raw["schemaErrors"].([]interface{}); ok {
+		for _, value := range values {
+			if message, ok := value.(string); ok {
+				e.SchemaErrors = append(e.SchemaErrors, message)
+			}
+		}
+	}
+
 	return nil
 }
 
 // EventSummary represents a summarized view of an event (for list queries).
 type EventSummary struct {
-	ID         string      `json:"id"`
-	Timestamp  time.Time   `json:"timestamp"`
-	EventType  string      `json:"eventType"`
-	Source     string      `json:"source"`
-	Status     EventStatus `json:"status"`
-	StatusCode int         `json:"statusCode"`
-	LatencyMs  int         `json:"latencyMs"`
+	ID           string       `json:"id"`
+	Timestamp    time.Time    `json:"timestamp"`
+	EventType    string       `json:"eventType"`
+	Source       string       `json:"source"`
+	DeliveryID   string       `json:"deliveryId,omitempty"`
+	Status       EventStatus  `json:"status"`
+	StatusCode   int          `json:"statusCode"`
+	LatencyMs    int          `json:"latencyMs"`
+	Acked        bool         `json:"acked,omitempty"`        // Delivery acknowledged by the target (ACK mode)
+	SchemaStatus SchemaStatus `json:"schemaStatus,omitempty"` // Outcome of the payload schema validation
+	Preview      string       `json:"preview,omitempty"`      // Key payload fields or the beginning of the payload
+	Version      int          `json:"version,omitempty"`      // Revision of the stored event (see Event.Version)
+
+	Fingerprint string `json:"fingerprint"`           // Identifies redeliveries of the same event
+	DuplicateOf string `json:"duplicateOf,omitempty"` // ID of the earliest event with the same fingerprint (list with markDuplicates)
 }
 
 // ToSummary converts an Event to EventSummary.
 func (e *Event) ToSummary() *EventSummary {
+	fingerprint := e.Fingerprint
+	if fingerprint == "" {
+		fingerprint = e.ComputeFingerprint()
+	}
 	return &EventSummary{
-		ID:         e.ID,
-		Timestamp:  e.Timestamp,
-		EventType:  e.EventType,
-		Source:     e.Source,
-		Status:     e.Status,
-		StatusCode: e.StatusCode,
-		LatencyMs:  e.LatencyMs,
+		ID:           e.ID,
+		Timestamp:    e.Timestamp,
+		EventType:    e.EventType,
+		Source:       e.Source,
+		DeliveryID:   e.DeliveryID,
+		Version:      e.Version,
+		Status:       e.Status,
+		StatusCode:   e.StatusCode,
+		LatencyMs:    e.LatencyMs,
+		Acked:        e.AckToken != "",
+		SchemaStatus: e.SchemaStatus,
+		Preview:      e.payloadPreview(),
+
+		Fingerprint: fingerprint,
+	}
+}
+
+// payloadPreview returns the preview of the event payload. Binary payloads are described
+// by their size.
+func (e *Event) payloadPreview() string {
+	if e.PayloadEncoding != PayloadEncodingBase64 {
+		return PayloadPreview(e.Payload)
+	}
+	if data, err := e.PayloadBytes(); err == nil {
+		return fmt.Sprintf("binary payload (%d bytes)", len(data))
+	}
+	return "binary payload"
+}
+
+// PayloadPreviewLength is the maximum length of a payload preview in characters.
+const PayloadPreviewLength = 200
+
+// payloadKeyFields are the payload fields of common webhook providers (GitHub, GitLab,
+// Gitea) that identify an event at a glance.
+type payloadKeyFields struct {
+	Action       string      `json:"action"`
+	ObjectKind   string      `json:"object_kind"`
+	Ref          string      `json:"ref"`
+	Number       json.Number `json:"number"`
+	UserUsername string      `json:"user_username"`
+	Repository   struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+	Project struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"project"`
+	Sender struct {
+		Login string `json:"login"`
+	} `json:"sender"`
+}
+
+// PayloadPreview summarizes a payload for event lists: the key fields of well-known webhook
+// payloads (e.g. "action=opened repository=org/repo"), or else the beginning of the payload
+// with whitespace collapsed, at most PayloadPreviewLength characters long.
+func PayloadPreview(payload string) string {
+	var key payloadKeyFields
+	var typeErr *json.UnmarshalTypeError
+	if err := json.Unmarshal([]byte(payload), &key); err == nil || errors.As(err, &typeErr) {
+		var parts []string
+		for _, field := range [][2]string{
+			{"action", key.Action},
+			{"kind", key.ObjectKind},
+			{"repository", firstNonEmpty(key.Repository.FullName, key.Project.PathWithNamespace)},
+			{"ref", key.Ref},
+			{"number", key.Number.String()},
+			{"sender", firstNonEmpty(key.Sender.Login, key.UserUsername)},
+		} {
+			if field[1] != "" {
+				parts = append(parts, field[0]+"="+field[1])
+			}
+		}
+		if len(parts) > 0 {
+			return truncatePreview(strings.Join(parts, " "))
+		}
+	}
+
+	// Only the beginning of large payloads is looked at
+	if limit := PayloadPreviewLength * 8; len(payload) > limit {
+		payload = strings.ToValidUTF8(payload[:limit], "")
+	}
+	return truncatePreview(strings.Join(strings.Fields(payload), " "))
+}
+
+// truncatePreview shortens a preview to PayloadPreviewLength characters, ending truncated
+// previews with an ellipsis.
+func truncatePreview(preview string) string {
+	runes := []rune(preview)
+	if len(runes) <= PayloadPreviewLength {
+		return preview
+	}
+	return string(runes[:PayloadPreviewLength-1]) + "…"
+}
+
+// firstNonEmpty returns the first non-empty value.
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
 	}
+	return ""
 }
 
 func extractString(data map[string]interface{}, key string) string {
@@ -274,15 +528,18 @@ func stringifyValue(value interface{}) string {
 
 // EventListRequest represents query parameters for listing events.
 type EventListRequest struct {
-	Page      int       `form:"page,default=1"`           // Page number
-	PageSize  int       `form:"pageSize,default=20"`      // Items per page
-	EventType string    `form:"eventType"`                // Filter by event type
-	Status    string    `form:"status"`                   // Filter by status
-	Search    string    `form:"search"`                   // Search in source
-	DateFrom  time.Time `form:"dateFrom"`                 // Filter by date range (from)
-	DateTo    time.Time `form:"dateTo"`                   // Filter by date range (to)
-	SortBy    string    `form:"sortBy,default=timestamp"` // Sort field
-	SortOrder string    `form:"sortOrder,default=desc"`   // Sort order
+	Page         int       `form:"page,default=1"`           // Page number
+	PageSize     int       `form:"pageSize,default=20"`      // Items per page
+	EventType    string    `form:"eventType"`                // Filter by event type
+	Status       string    `form:"status"`                   // Filter by status
+	SchemaStatus string    `form:"schemaStatus"`             // Filter by payload schema validation status
+	Search       string    `form:"search"`                   // Search in source
+	DateFrom     time.Time `form:"dateFrom"`                 // Filter by date range (from)
+	DateTo       time.Time `form:"dateTo"`                   // Filter by date range (to)
+	SortBy       string    `form:"sortBy,default=timestamp"` // Sort field
+	SortOrder    string    `form:"sortOrder,default=desc"`   // Sort order
+
+	MarkDuplicates bool `form:"markDuplicates"` // Set duplicateOf on redelivered events (reads all events of the client)
 }
 
 // EventListResponse represents the response for event list queries.
@@ -291,11 +548,195 @@ type EventListResponse struct {
 	Page     int             `json:"page"`
 	PageSize int             `json:"pageSize"`
 	Events   []*EventSummary `json:"events"`
+	Next     string          `json:"next,omitempty"` // URL of the next page (absent on the last page)
+	Prev     string          `json:"prev,omitempty"` // URL of the previous page (absent on the first page)
+}
+
+// EventDetailRequest selects the fields returned by the event detail endpoint, so clients
+// can load metadata first and large payloads only when needed. Field names are the JSON names
+// of Event; the ID is always returned.
+type EventDetailRequest struct {
+	Fields string `form:"fields"` // Comma-separated fields to return (default: all)
+	Omit   string `form:"omit"`   // Comma-separated fields to leave out
+}
+
+// Sparse reports whether the request selects a subset of the event fields.
+func (r *EventDetailRequest) Sparse() bool {
+	return r.Fields != "" || r.Omit != ""
+}
+
+// Validate checks that all selected fields exist.
+func (r *EventDetailRequest) Validate() error {
+	for _, list := range []string{r.Fields, r.Omit} {
+		for _, field := range splitFieldList(list) {
+			if _, ok := eventFieldIndex[field]; !ok {
+				return fmt.Errorf("unknown event field: %s", field)
+			}
+		}
+	}
+	return nil
+}
+
+// SelectFields returns the event fields selected by a request, keyed by their JSON names.
+// Empty optional fields are left out as in the full event.
+func (e *Event) SelectFields(req *EventDetailRequest) map[string]interface{} {
+	selected := make(map[string]bool)
+	if req.Fields == "" {
+		for field := range eventFieldIndex {
+			selected[field] = true
+		}
+	}
+	for _, field := range splitFieldList(req.Fields) {
+		selected[field] = true
+	}
+	for _, field := range splitFieldList(req.Omit) {
+		delete(selected, field)
+	}
+	selected["id"] = true
+
+	value := reflect.ValueOf(e).Elem()
+	fields := make(map[string]interface{}, len(selected))
+	for field := range selected {
+		index, ok := eventFieldIndex[field]
+		if !ok {
+			continue
+		}
+		fieldValue := value.Field(index.position)
+		if index.omitEmpty && fieldValue.IsZero() {
+			continue
+		}
+		fields[field] = fieldValue.Interface()
+	}
+	return fields
 }
 
-// EventReplayRequest represents the request body for replaying an event.
+// eventField locates an Event field by its JSON name.
+type eventField struct {
+	position  int
+	omitEmpty bool
+}
+
+// eventFieldIndex maps the JSON names of the Event fields to the fields.
+var eventFieldIndex = func() map[string]eventField {
+	eventType := reflect.TypeOf(Event{})
+	index := make(map[string]eventField, eventType.NumField())
+	for i := 0; i < eventType.NumField(); i++ {
+		name, options, _ := strings.Cut(eventType.Field(i).Tag.Get("json"), ",")
+		index[name] = eventField{position: i, omitEmpty: options == "omitempty"}
+	}
+	return index
+}()
+
+// splitFieldList splits a comma-separated field list, ignoring blanks.
+func splitFieldList(list string) []string {
+	var fields []string
+	for _, field := range strings.Split(list, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// EventInjectRequest represents the request body for injecting a synthetic event.
+type EventInjectRequest struct {
+	EventType string            `json:"eventType"`                  // Event type (optional, inferred from headers)
+	Source    string            `json:"source"`                     // Event source (optional)
+	Headers   map[string]string `json:"headers"`                    // Request headers (optional)
+	Payload   json.RawMessage   `json:"payload" binding:"required"` // Raw payload (JSON value or string)
+	Forward   bool              `json:"forward"`                    // Forward to the target immediately (optional)
+
+	PayloadEncoding string `json:"payloadEncoding" binding:"omitempty,oneof=base64"` // "base64" if payload is a base64 string of a binary body
+}
+
+// PayloadString returns the payload as stored in Event.Payload.
+// JSON strings are unquoted, any other JSON value is kept verbatim.
+func (r *EventInjectRequest) PayloadString() string {
+	trimmed := strings.TrimSpace(string(r.Payload))
+	if strings.HasPrefix(trimmed, "\"") {
+		var text string
+		if err := json.Unmarshal(r.Payload, &text); err == nil {
+			return text
+		}
+	}
+	return trimmed
+}
+
+// EventInjectResponse represents the response for event injection.
+type EventInjectResponse struct {
+	Event   *Event             `json:"event"`             // Stored event
+	Forward *EventReplayResult `json:"forward,omitempty"` // Forward result (only when forwarding was requested)
+}
+
+// Replay modes.
+const (
+	ReplayModeHTTP   = "http"   // Send with the internal HTTP client (default)
+	ReplayModeScript = "script" // Execute the stored replay script
+)
+
+// EventReplayRequest represents the request body for replaying events, selected either by
+// their IDs or by a filter.
 type EventReplayRequest struct {
-	EventIDs []string `json:"eventIds" binding:"required"` // Event IDs to replay
+	EventIDs []string           `json:"eventIds"`                                   // Event IDs to replay
+	Filter   *EventReplayFilter `json:"filter"`                                     // Replay the events matching the filter instead
+	Mode     string             `json:"mode" binding:"omitempty,oneof=http script"` // Replay mode (optional, default: http)
+}
+
+// MaxReplayFilterEvents is the maximum number of events a replay filter may select.
+const MaxReplayFilterEvents = 5000
+
+// EventReplayFilter selects the events to replay by their attributes, resolved by the server.
+// All conditions are optional; events match if they meet all conditions given.
+type EventReplayFilter struct {
+	Status    string    `json:"status" binding:"omitempty,oneof=success failed retrying not_replayed"` // Forward status
+	EventType string    `json:"eventType"`                                                             // Event type
+	DateFrom  time.Time `json:"dateFrom"`                                                              // Received after this time
+	DateTo    time.Time `json:"dateTo"`                                                                // Received before this time
+}
+
+// ListRequest returns the event list filter selecting the events, oldest first.
+func (f *EventReplayFilter) ListRequest() *EventListRequest {
+	return &EventListRequest{
+		Status:    f.Status,
+		EventType: f.EventType,
+		DateFrom:  f.DateFrom,
+		DateTo:    f.DateTo,
+		SortBy:    "timestamp",
+		SortOrder: "asc",
+	}
+}
+
+// Replay script formats.
+const (
+	ScriptFormatCurl   = "curl"   // cURL script (gosmee default)
+	ScriptFormatHTTPie = "httpie" // HTTPie script
+)
+
+// EventScriptRequest represents the request body for regenerating an event's replay script.
+type EventScriptRequest struct {
+	Format  string            `json:"format" binding:"omitempty,oneof=curl httpie"` // Script format (optional, default: the client's setting)
+	Headers map[string]string `json:"headers"`                                      // Header overrides; an empty value removes the header (optional)
+	DryRun  bool              `json:"dryRun"`                                       // Return the script without storing it (optional)
+}
+
+// EventScriptResponse represents a regenerated replay script.
+type EventScriptResponse struct {
+	EventID   string `json:"eventId"`   // Event ID
+	Format    string `json:"format"`    // Script format ("curl" or "httpie")
+	TargetURL string `json:"targetUrl"` // Target URL the script sends to
+	Script    string `json:"script"`    // Script content (secrets masked)
+	Saved     bool   `json:"saved"`     // Whether the stored script was replaced
+}
+
+// EventDeleteRequest represents query parameters for deleting an event.
+type EventDeleteRequest struct {
+	Version int `form:"version" binding:"omitempty,min=1"` // Only delete the event at this version (optional)
+}
+
+// EventReplayFailedRequest represents query parameters for replaying all failed events.
+type EventReplayFailedRequest struct {
+	DateFrom time.Time `form:"dateFrom"` // Only replay events received after this time (optional)
+	DateTo   time.Time `form:"dateTo"`   // Only replay events received before this time (optional)
 }
 
 // EventReplayResponse represents the response for event replay.
@@ -313,4 +754,48 @@ type EventReplayResult struct {
 	StatusCode   int    `json:"statusCode,omitempty"`
 	LatencyMs    int    `json:"latencyMs,omitempty"`
 	ErrorMessage string `json:"errorMessage,omitempty"`
+	CircuitOpen  bool   `json:"circuitOpen,omitempty"` // Not attempted because the client's circuit is open
+	Paused       bool   `json:"paused,omitempty"`      // Not attempted because forwarding of the client is paused
+	AckToken     string `json:"ackToken,omitempty"`    // Delivery token returned by the target (ACK mode)
+
+	// Payload schema validation of the replayed payload (clients with a payload schema)
+	SchemaStatus SchemaStatus `json:"schemaStatus,omitempty"`
+	SchemaErrors []string     `json:"schemaErrors,omitempty"`
+
+	// Comparison with the response recorded for the previous delivery of the event
+	Comparison *ReplayComparison `json:"comparison,omitempty"`
+
+	// Target response body, stored on the event but not returned with replay results
+	Response          string `json:"-"`
+	ResponseTruncated bool   `json:"-"`
+}
+
+// ReplayComparison compares the target's response to a replay with the response recorded
+// on the event for its previous delivery, to show whether the target's behavior changed.
+type ReplayComparison struct {
+	OriginalStatusCode int  `json:"originalStatusCode"`
+	StatusCode         int  `json:"statusCode"`
+	StatusChanged      bool `json:"statusChanged"`
+
+	OriginalLatencyMs int `json:"originalLatencyMs"`
+	LatencyMs         int `json:"latencyMs"`
+	LatencyDeltaMs    int `json:"latencyDeltaMs"` // Replay latency minus the original latency
+
+	BodyCompared  bool   `json:"bodyCompared"`            // Both response bodies were captured (see --response-capture-size)
+	BodyChanged   bool   `json:"bodyChanged"`             // Captured bodies differ
+	BodyTruncated bool   `json:"bodyTruncated,omitempty"` // Only the beginning of a body was captured and compared
+	BodyDiff      string `json:"bodyDiff,omitempty"`      // Unified line diff from the original to the replay body
+}
+
+// EventAckReportRequest represents query parameters for the delivery receipt reconciliation.
+type EventAckReportRequest struct {
+	DateFrom time.Time `form:"dateFrom"` // Only events received after this time (optional)
+	DateTo   time.Time `form:"dateTo"`   // Only events received before this time (optional)
+}
+
+// EventAckReport lists the events delivered to the target without a delivery receipt.
+type EventAckReport struct {
+	Delivered      int             `json:"delivered"`      // Events delivered successfully in the range
+	Acknowledged   int             `json:"acknowledged"`   // Delivered events the target acknowledged
+	Unacknowledged []*EventSummary `json:"unacknowledged"` // Delivered events without a delivery token, oldest first
 }
@@ -0,0 +1,95 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+// EventCatalogs lists the event types each webhook provider sends, as found in the event
+// type header gosmee matches ignored events against (e.g. X-GitHub-Event).
+var EventCatalogs = map[string][]string{
+	ProviderGitHub: {
+		"branch_protection_rule", "check_run", "check_suite", "code_scanning_alert", "commit_comment",
+		"create", "delete", "dependabot_alert", "deploy_key", "deployment", "deployment_status",
+		"discussion", "discussion_comment", "fork", "gollum", "installation", "installation_repositories",
+		"issue_comment", "issues", "label", "member", "membership", "merge_group", "meta", "milestone",
+		"organization", "package", "page_build", "ping", "project", "project_card", "project_column",
+		"public", "pull_request", "pull_request_review", "pull_request_review_comment",
+		"pull_request_review_thread", "push", "registry_package", "release", "repository",
+		"repository_dispatch", "secret_scanning_alert", "security_advisory", "sponsorship", "star",
+		"status", "team", "team_add", "watch", "workflow_dispatch", "workflow_job", "workflow_run",
+	},
+	ProviderGitLab: {
+		"Push Hook", "Tag Push Hook", "Issue Hook", "Confidential Issue Hook", "Note Hook",
+		"Confidential Note Hook", "Merge Request Hook", "Wiki Page Hook", "Pipeline Hook", "Job Hook",
+		"Deployment Hook", "Feature Flag Hook", "Release Hook", "Emoji Hook", "Member Hook",
+		"Subgroup Hook", "Resource Access Token Hook",
+	},
+	ProviderBitbucket: {
+		"repo:push", "repo:fork", "repo:updated", "repo:commit_comment_created",
+		"repo:commit_status_created", "repo:commit_status_updated", "issue:created", "issue:updated",
+		"issue:comment_created", "pullrequest:created", "pullrequest:updated",
+		"pullrequest:changes_request_created", "pullrequest:changes_request_removed",
+		"pullrequest:approved", "pullrequest:unapproved", "pullrequest:fulfilled", "pullrequest:rejected",
+		"pullrequest:comment_created", "pullrequest:comment_updated", "pullrequest:comment_deleted",
+	},
+	"gitea": {
+		"create", "delete", "fork", "push", "issues", "issue_assign", "issue_label", "issue_milestone",
+		"issue_comment", "pull_request", "pull_request_assign", "pull_request_label",
+		"pull_request_milestone", "pull_request_comment", "pull_request_review_approved",
+		"pull_request_review_rejected", "pull_request_review_comment", "pull_request_sync", "wiki",
+		"repository", "release", "package", "status", "workflow_job", "workflow_run",
+	},
+}
+
+// IgnoreEventPreset is a named set of event types commonly ignored together.
+type IgnoreEventPreset struct {
+	ID          string   `json:"id"`          // Preset ID
+	Name        string   `json:"name"`        // Display name
+	Description string   `json:"description"` // What the events are
+	Provider    string   `json:"provider"`    // Provider sending the events
+	Events      []string `json:"events"`      // Event types to ignore
+}
+
+// IgnoreEventPresets are the presets offered when choosing the events a client ignores.
+var IgnoreEventPresets = []IgnoreEventPreset{
+	{
+		ID: "github-noise", Name: "GitHub noise", Provider: ProviderGitHub,
+		Description: "Webhook pings and commit status updates",
+		Events:      []string{"ping", "check_suite", "status"},
+	},
+	{
+		ID: "github-ci", Name: "GitHub CI", Provider: ProviderGitHub,
+		Description: "Checks and GitHub Actions workflow progress",
+		Events:      []string{"check_run", "check_suite", "status", "workflow_job", "workflow_run"},
+	},
+	{
+		ID: "github-social", Name: "GitHub social", Provider: ProviderGitHub,
+		Description: "Stars, watchers and forks",
+		Events:      []string{"star", "watch", "fork"},
+	},
+	{
+		ID: "gitlab-ci", Name: "GitLab CI", Provider: ProviderGitLab,
+		Description: "Pipeline and job progress",
+		Events:      []string{"Pipeline Hook", "Job Hook"},
+	},
+	{
+		ID: "bitbucket-status", Name: "Bitbucket build status", Provider: ProviderBitbucket,
+		Description: "Commit build status updates",
+		Events:      []string{"repo:commit_status_created", "repo:commit_status_updated"},
+	},
+	{
+		ID: "gitea-ci", Name: "Gitea CI", Provider: "gitea",
+		Description: "Commit statuses and Actions workflow progress",
+		Events:      []string{"status", "workflow_job", "workflow_run"},
+	},
+}
+
+// IgnoreEventPresetsRequest represents query parameters for the ignore event presets.
+type IgnoreEventPresetsRequest struct {
+	Provider string `form:"provider" binding:"omitempty,oneof=github gitlab bitbucket stripe custom"` // Only presets for clients of the provider (optional)
+}
+
+// IgnoreEventPresetsResponse represents the ignore event presets and provider event catalogs.
+type IgnoreEventPresetsResponse struct {
+	Presets  []IgnoreEventPreset `json:"presets"`
+	Catalogs map[string][]string `json:"catalogs"`
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ComputeFingerprint returns the key identifying redeliveries of the event: the delivery ID
+// the webhook provider sent, or a hash of the event type and payload for events without one.
+func (e *Event) ComputeFingerprint() string {
+	deliveryID := e.DeliveryID
+	if deliveryID == "" {
+		deliveryID = e.deliveryID()
+	}
+	if deliveryID != "" {
+		return "delivery:" + deliveryID
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(e.EventType))
+	hash.Write([]byte{0})
+	hash.Write([]byte(e.Payload))
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil)[:16])
+}
+
+// ResolveDeliveryID sets DeliveryID from the delivery header of a known provider, for
+// events gosmee stored and events stored before the field existed.
+func (e *Event) ResolveDeliveryID() {
+	if e.DeliveryID == "" {
+		e.DeliveryID = e.deliveryID()
+	}
+}
+
+// deliveryID returns the value of the first delivery header of a known provider, in order of
+// the provider IDs.
+func (e *Event) deliveryID() string {
+	providerIDs := make([]string, 0, len(WebhookProviders))
+	for id := range WebhookProviders {
+		providerIDs = append(providerIDs, id)
+	}
+	sort.Strings(providerIDs)
+
+	for _, id := range providerIDs {
+		deliveryHeader := WebhookProviders[id].DeliveryHeader
+		if deliveryHeader == "" {
+			continue
+		}
+		for name, value := range e.Headers {
+			if strings.EqualFold(name, deliveryHeader) && value != "" {
+				return value
+			}
+		}
+	}
+	return ""
+}
+
+// EventDeduplicateRequest represents query parameters for deleting redelivered events.
+type EventDeduplicateRequest struct {
+	DateFrom time.Time `form:"dateFrom"` // Only events received after this time (optional)
+	DateTo   time.Time `form:"dateTo"`   // Only events received before this time (optional)
+	DryRun   bool      `form:"dryRun"`   // Report the duplicates without deleting them (optional)
+}
+
+// EventDeduplicateResponse represents the result of deleting redelivered events.
+type EventDeduplicateResponse struct {
+	Groups   int      `json:"groups"`   // Events received more than once
+	Deleted  int      `json:"deleted"`  // Redeliveries deleted (0 in a dry run)
+	EventIDs []string `json:"eventIds"` // IDs of the redeliveries, the earliest event of each group is kept
+	DryRun   bool     `json:"dryRun"`
+}
+
+// EventDeliveryMatch is an event found by the delivery ID of the webhook provider.
+type EventDeliveryMatch struct {
+	ClientID   string        `json:"clientId"`   // Client that received the event
+	ClientName string        `json:"clientName"` // Client name
+	Event      *EventSummary `json:"event"`      // Event summary
+}
+
+// EventDeliveryLookupResponse lists the events received with a delivery ID.
+type EventDeliveryLookupResponse struct {
+	DeliveryID string                `json:"deliveryId"`
+	Matches    []*EventDeliveryMatch `json:"matches"` // Events with the delivery ID, oldest first
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import "time"
+
+// EventRetention defines how long events are kept, by delivery status. Failed events are
+// what people come back to, so they may be kept longer than the others.
+type EventRetention struct {
+	Days       int // Days to keep events (0 = forever)
+	FailedDays int // Days to keep failed and retrying events (0 = same as Days)
+}
+
+// Enabled reports whether any events are deleted.
+func (r EventRetention) Enabled() bool {
+	return r.Days > 0 || r.FailedDays > 0
+}
+
+// DaysFor returns the days events of a status are kept (0 = forever).
+func (r EventRetention) DaysFor(status EventStatus) int {
+	if r.FailedDays > 0 && (status == EventStatusFailed || status == EventStatusRetrying) {
+		return r.FailedDays
+	}
+	return r.Days
+}
+
+// Expired reports whether an event of a status stored on a day is past its retention at now.
+// Events are kept for whole days, like the date directories they are stored in.
+func (r EventRetention) Expired(status EventStatus, day, now time.Time) bool {
+	days := r.DaysFor(status)
+	return days > 0 && day.Before(now.AddDate(0, 0, -days))
+}
+
+// ExpiresAll reports whether all events stored on a day are past their retention at now,
+// whatever their status.
+func (r EventRetention) ExpiresAll(day, now time.Time) bool {
+	return r.Expired(EventStatusSuccess, day, now) && r.Expired(EventStatusFailed, day, now)
+}
+
+// ExpiresAny reports whether events of some status stored on a day are past their retention at now.
+func (r EventRetention) ExpiresAny(day, now time.Time) bool {
+	return r.Expired(EventStatusSuccess, day, now) || r.Expired(EventStatusFailed, day, now)
+}
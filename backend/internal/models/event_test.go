@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -107,6 +108,74 @@ var _ = Describe("Event decoding compatibility", func() {
 	)
 })
 
+var _ = Describe("Event field selection", func() {
+	event := &models.Event{
+		ID:         "evt-1",
+		EventType:  "push",
+		Status:     models.EventStatusSuccess,
+		StatusCode: 200,
+		Headers:    map[string]string{"X-GitHub-Event": "push"},
+		Payload:    `{"ref":"main"}`,
+	}
+
+	It("returns only the requested fields and the ID", func() {
+		fields := event.SelectFields(&models.EventDetailRequest{Fields: "headers, statusCode"})
+		Expect(fields).To(Equal(map[string]interface{}{
+			"id":         "evt-1",
+			"headers":    map[string]string{"X-GitHub-Event": "push"},
+			"statusCode": 200,
+		}))
+	})
+
+	It("leaves out omitted fields and empty optional ones", func() {
+		fields := event.SelectFields(&models.EventDetailRequest{Omit: "payload,headers"})
+		Expect(fields).To(HaveKeyWithValue("eventType", "push"))
+		Expect(fields).To(HaveKey("latencyMs"))
+		Expect(fields).NotTo(HaveKey("payload"))
+		Expect(fields).NotTo(HaveKey("headers"))
+		Expect(fields).NotTo(HaveKey("errorMessage"))
+	})
+
+	It("rejects unknown fields", func() {
+		Expect((&models.EventDetailRequest{Fields: "payload,body"}).Validate()).To(MatchError("unknown event field: body"))
+		Expect((&models.EventDetailRequest{Omit: "payload"}).Validate()).To(Succeed())
+	})
+})
+
+var _ = DescribeTable("PayloadPreview",
+	func(payload, expected string) {
+		Expect(models.PayloadPreview(payload)).To(Equal(expected))
+		Expect(models.PayloadPreview(expected)).To(Equal(expected))
+	},
+	Entry("GitHub pull request",
+		`{"action":"opened","number":42,"repository":{"full_name":"org/repo"},"sender":{"login":"octocat"}}`,
+		"action=opened repository=org/repo number=42 sender=octocat"),
+	Entry("GitLab push",
+		`{"object_kind":"push","ref":"refs/heads/main","project":{"path_with_namespace":"group/app"},"user_username":"dev"}`,
+		"kind=push repository=group/app ref=refs/heads/main sender=dev"),
+	Entry("unexpected field types",
+		`{"action":"created","repository":"org/repo"}`,
+		"action=created"),
+	Entry("other JSON",
+		"{\n  \"status\": \"ok\",\n  \"count\": 3\n}",
+		`{ "status": "ok", "count": 3 }`),
+	Entry("long text",
+		strings.Repeat("ab ", 100),
+		strings.Repeat("ab ", 66)+"a…"),
+)
+
+var _ = DescribeTable("DetectContentType",
+	func(payload, expected string) {
+		Expect(models.DetectContentType(payload)).To(Equal(expected))
+	},
+	Entry("JSON object", `{"ref":"main"}`, models.ContentTypeJSON),
+	Entry("empty payload", "", models.ContentTypeJSON),
+	Entry("form fields", "payload=%7B%7D&token=", models.ContentTypeForm),
+	Entry("XML document", `<?xml version="1.0"?><push/>`, models.ContentTypeXML),
+	Entry("plain text", "build finished", models.ContentTypeText),
+	Entry("text with an equals sign", "a=b c", models.ContentTypeText),
+)
+
 func mustLoadYAML[T any](path string) T {
 	data, err := os.ReadFile(path)
 	Expect(err).NotTo(HaveOccurred(), "failed to read yaml file %s", path)
@@ -0,0 +1,26 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import "time"
+
+// ImpersonationRecord is an audit log entry of a request an administrator made on behalf of
+// another user.
+type ImpersonationRecord struct {
+	Time      time.Time `json:"time"`                // When the request was answered
+	AdminID   string    `json:"adminId"`             // Administrator who made the request
+	UserID    string    `json:"userId"`              // User the request acted as
+	Reason    string    `json:"reason"`              // Reason given by the administrator (e.g. a ticket)
+	Method    string    `json:"method"`              // HTTP method
+	Path      string    `json:"path"`                // Request path
+	Status    int       `json:"status"`              // HTTP status of the response
+	RequestID string    `json:"requestId,omitempty"` // Request ID, for correlating with the access log
+}
+
+// ImpersonationListRequest represents query parameters for listing the impersonation audit log.
+type ImpersonationListRequest struct {
+	UserID  string `form:"userId"`                                               // Only requests acting as this user (optional)
+	AdminID string `form:"adminId"`                                              // Only requests of this administrator (optional)
+	Limit   int    `form:"limit,default=100" binding:"omitempty,min=1,max=1000"` // Maximum number of entries (default: 100, max: 1000)
+}
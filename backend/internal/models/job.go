@@ -0,0 +1,38 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import (
+	"time"
+)
+
+// JobStatus represents the lifecycle state of an asynchronous job.
+type JobStatus string
+
+const (
+	JobStatusPending   JobStatus = "pending"   // Job is queued
+	JobStatusRunning   JobStatus = "running"   // Job is executing
+	JobStatusCompleted JobStatus = "completed" // Job finished successfully
+	JobStatusFailed    JobStatus = "failed"    // Job finished with an error
+)
+
+// Job represents an asynchronous background job (e.g. bulk replay).
+type Job struct {
+	ID         string      `json:"id"`                   // Job ID (UUID)
+	UserID     string      `json:"userId"`               // Owner user ID
+	Type       string      `json:"type"`                 // Job type (e.g. "replay-failed")
+	Status     JobStatus   `json:"status"`               // Current status
+	Total      int         `json:"total"`                // Total number of items to process
+	Processed  int         `json:"processed"`            // Number of items processed so far
+	Result     interface{} `json:"result,omitempty"`     // Job result (available when completed)
+	Error      string      `json:"error,omitempty"`      // Error message (available when failed)
+	CreatedAt  time.Time   `json:"createdAt"`            // Submission time
+	StartedAt  *time.Time  `json:"startedAt,omitempty"`  // Execution start time
+	FinishedAt *time.Time  `json:"finishedAt,omitempty"` // Completion time
+}
+
+// IsFinished reports whether the job reached a terminal state.
+func (j *Job) IsFinished() bool {
+	return j.Status == JobStatusCompleted || j.Status == JobStatusFailed
+}
@@ -0,0 +1,23 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import "time"
+
+// LogAnnotation is a note a user attached to a moment of a client's log, e.g. "incident
+// started here". Annotations are stored per client and day, next to the day's log file.
+type LogAnnotation struct {
+	ID        string    `json:"id"`                 // Annotation ID (UUID)
+	ClientID  string    `json:"clientId,omitempty"` // Client the annotation belongs to (logs of several clients only)
+	Time      time.Time `json:"time"`               // Log timestamp the note refers to
+	Note      string    `json:"note"`               // Note text
+	CreatedBy string    `json:"createdBy"`          // User who added the note
+	CreatedAt time.Time `json:"createdAt"`          // When the note was added
+}
+
+// LogAnnotationRequest represents the request body for annotating a log timestamp.
+type LogAnnotationRequest struct {
+	Time time.Time `json:"time" binding:"required"`          // Log timestamp to annotate (RFC 3339)
+	Note string    `json:"note" binding:"required,max=1000"` // Note text
+}
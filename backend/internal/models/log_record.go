@@ -0,0 +1,114 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// LogRecord is a structured log line of a client process.
+// Sequence numbers start at 1 for every process and are used as SSE event IDs; records read
+// from log files have none.
+type LogRecord struct {
+	ClientID string       `json:"clientId,omitempty"` // Client the record belongs to (logs of several clients only)
+	Seq      int64        `json:"seq,omitempty"`      // Sequence number within the process (buffered and streamed records only)
+	Time     time.Time    `json:"time"`               // When the line was read
+	Source   string       `json:"source"`             // Output stream of the process (stdout or stderr)
+	Level    string       `json:"level"`              // Severity (debug, info, warn or error)
+	Message  string       `json:"message"`            // Line as printed by the process
+	Delivery *LogDelivery `json:"delivery,omitempty"` // Delivery the line reports on, if any
+}
+
+// LogDelivery is the delivery information parsed from a process log line.
+type LogDelivery struct {
+	EventType  string `json:"eventType,omitempty"`  // Webhook event type (e.g. "push")
+	DeliveryID string `json:"deliveryId,omitempty"` // Delivery or event ID
+	StatusCode int    `json:"statusCode,omitempty"` // HTTP status returned by the target
+}
+
+// Process log sources, the output streams log lines are read from.
+const (
+	LogSourceStdout = "stdout"
+	LogSourceStderr = "stderr"
+)
+
+// Process log levels, ordered from the least to the most severe.
+const (
+	LogLevelDebug = "debug"
+	LogLevelInfo  = "info"
+	LogLevelWarn  = "warn"
+	LogLevelError = "error"
+)
+
+// logLevelRanks orders the log levels by severity.
+var logLevelRanks = map[string]int{LogLevelDebug: 0, LogLevelInfo: 1, LogLevelWarn: 2, LogLevelError: 3}
+
+// IsLogSource reports whether source is a known log source.
+func IsLogSource(source string) bool {
+	return source == LogSourceStdout || source == LogSourceStderr
+}
+
+// IsLogLevel reports whether level is a known log level.
+func IsLogLevel(level string) bool {
+	_, ok := logLevelRanks[level]
+	return ok
+}
+
+// String formats the record as a plain text log line ("[2006-01-02 15:04:05] [stdout] message"),
+// prefixed with the client ID if it is set ("[<clientId>] [2006-01-02 15:04:05] ...").
+func (r LogRecord) String() string {
+	line := fmt.Sprintf("[%s] [%s] %s", r.Time.Format("2006-01-02 15:04:05"), r.Source, r.Message)
+	if r.ClientID != "" {
+		return fmt.Sprintf("[%s] %s", r.ClientID, line)
+	}
+	return line
+}
+
+// LogListRequest represents query parameters for listing historical logs. The filter
+// parameters are read into a LogFilter.
+type LogListRequest struct {
+	Date     string `form:"date"`                 // Day (YYYY-MM-DD, default: today)
+	Page     int    `form:"page,default=1"`       // Page number (default: 1)
+	PageSize int    `form:"pageSize,default=100"` // Lines per page (default: 100, max: 1000)
+}
+
+// MergedLogListRequest represents query parameters for listing the logs of several clients.
+type MergedLogListRequest struct {
+	LogListRequest
+	Clients string `form:"clients" binding:"required"` // Comma-separated client IDs or slugs
+}
+
+// MergedLogStreamRequest represents query parameters for streaming the logs of several
+// clients.
+type MergedLogStreamRequest struct {
+	Clients string `form:"clients"` // Comma-separated client IDs or slugs
+	All     bool   `form:"all"`     // Stream all running clients of the user instead
+}
+
+// LogFilter selects process log records. Empty fields match every record.
+type LogFilter struct {
+	Search   string // Case-insensitive substring of the message
+	Source   string // Output stream (stdout or stderr)
+	Level    string // Minimum severity
+	Delivery bool   // Only records reporting on a delivery
+}
+
+// Matches reports whether a record passes the filter.
+func (f LogFilter) Matches(r LogRecord) bool {
+	if f.Source != "" && r.Source != f.Source {
+		return false
+	}
+	if f.Level != "" && logLevelRanks[r.Level] < logLevelRanks[f.Level] {
+		return false
+	}
+	if f.Delivery && r.Delivery == nil {
+		return false
+	}
+	if f.Search != "" && !strings.Contains(strings.ToLower(r.Message), strings.ToLower(f.Search)) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,35 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import (
+	"time"
+)
+
+// NotificationLevel represents the severity of a notification.
+type NotificationLevel string
+
+const (
+	NotificationLevelInfo    NotificationLevel = "info"    // Informational
+	NotificationLevelWarning NotificationLevel = "warning" // Requires attention
+	NotificationLevelError   NotificationLevel = "error"   // Something is broken
+)
+
+// Notification represents a message shown to a user about their clients.
+type Notification struct {
+	ID        string            `json:"id"`                 // Notification ID (UUID)
+	UserID    string            `json:"userId"`             // Recipient user ID (empty for administrator notifications)
+	ClientID  string            `json:"clientId,omitempty"` // Related client ID (optional)
+	Type      string            `json:"type"`               // Notification type (e.g. "circuit_open")
+	Level     NotificationLevel `json:"level"`              // Severity
+	Message   string            `json:"message"`            // Human readable message
+	Read      bool              `json:"read"`               // Whether the user has read it
+	CreatedAt time.Time         `json:"createdAt"`          // Creation time
+}
+
+// NotificationListResponse represents the response for notification list queries.
+type NotificationListResponse struct {
+	Unread        int             `json:"unread"`        // Number of unread notifications
+	Notifications []*Notification `json:"notifications"` // Notifications, newest first
+}
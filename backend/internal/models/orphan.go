@@ -0,0 +1,40 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+// Kinds of orphaned client data.
+const (
+	OrphanMissingConfig = "missing_config" // Client directory (events, logs) without a config.json
+	OrphanUserMismatch  = "user_mismatch"  // config.json of another user or client than the directory it is stored in
+)
+
+// Orphan is client data in the data directory that no client tracks, e.g. left behind by
+// a partial delete. It still counts towards the storage quota of the user owning the directory.
+type Orphan struct {
+	UserID   string `json:"userId"`   // User directory the data is stored under
+	ClientID string `json:"clientId"` // Client directory the data is stored under
+	Kind     string `json:"kind"`     // Kind of orphan (missing_config or user_mismatch)
+	Path     string `json:"path"`     // Directory relative to the data directory
+	Cold     bool   `json:"cold"`     // Data (also) stored in the cold data directory
+	Size     int64  `json:"size"`     // Total size in bytes, in both data directories
+	Events   int    `json:"events"`   // Number of event files
+
+	ConfigUserID   string `json:"configUserId,omitempty"`   // User the config.json refers to (user_mismatch)
+	ConfigClientID string `json:"configClientId,omitempty"` // Client the config.json refers to (user_mismatch)
+}
+
+// OrphanListResponse represents the result of an orphan scan.
+type OrphanListResponse struct {
+	Orphans   []*Orphan `json:"orphans"`   // Orphaned client directories
+	TotalSize int64     `json:"totalSize"` // Total size of all orphans in bytes
+}
+
+// OrphanAdoptRequest represents the request to re-adopt orphaned data as a client of the
+// user whose directory holds it. A client is created for missing_config orphans; the
+// config.json of user_mismatch orphans is rewritten to match its directory.
+type OrphanAdoptRequest struct {
+	Name      string `json:"name"`      // Name of the created client (default: "Recovered <id>")
+	SmeeURL   string `json:"smeeUrl"`   // Smee server URL of the created client (optional)
+	TargetURL string `json:"targetUrl"` // Target URL of the created client (optional)
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import "encoding/json"
+
+// Payload schema validation limits.
+const (
+	MaxPayloadSchemaEventTypes = 50        // Event types with their own schema
+	MaxPayloadSchemaSize       = 64 * 1024 // Size of all schemas of a client, in bytes
+)
+
+// PayloadSchemaConfig attaches JSON Schemas to a client. The payloads of new and replayed
+// events are validated against the schema of their event type, or the default schema, and
+// the events are marked with the outcome; events are stored and forwarded either way.
+type PayloadSchemaConfig struct {
+	Default    json.RawMessage            `json:"default,omitempty"`    // Schema of events without an event type schema (optional)
+	EventTypes map[string]json.RawMessage `json:"eventTypes,omitempty"` // Event type -> schema (optional)
+}
+
+// SchemaFor returns the schema an event type is validated against, nil if there is none.
+func (c *PayloadSchemaConfig) SchemaFor(eventType string) json.RawMessage {
+	if schema, ok := c.EventTypes[eventType]; ok {
+		return schema
+	}
+	return c.Default
+}
+
+// Size returns the size of all schemas in bytes.
+func (c *PayloadSchemaConfig) Size() int {
+	size := len(c.Default)
+	for _, schema := range c.EventTypes {
+		size += len(schema)
+	}
+	return size
+}
+
+// SchemaStatus is the outcome of validating an event payload against the client's schema.
+type SchemaStatus string
+
+const (
+	SchemaStatusValid   SchemaStatus = "valid"   // Payload matches the schema
+	SchemaStatusInvalid SchemaStatus = "invalid" // Payload violates the schema (see Event.SchemaErrors)
+)
@@ -18,36 +18,58 @@ type ProcessInfo struct {
 	LastError    string       `json:"lastError,omitempty"`
 
 	// Log streaming
-	LogLines     []string      `json:"-"` // In-memory log lines (not serialized)
-	LogListeners []chan string `json:"-"` // Active log stream subscribers (SSE)
-	logMu        sync.Mutex    // Mutex for thread-safe log operations
+	LogListeners []chan LogRecord `json:"-"` // Active log stream subscribers (SSE)
+	logLines     []LogRecord      // Ring buffer of the most recent log lines
+	logStart     int              // Index of the oldest line in logLines
+	logCount     int              // Number of lines currently buffered
+	logSeq       int64            // Sequence number of the last added line
+	logMu        sync.Mutex       // Mutex for thread-safe log operations
 }
 
-// NewProcessInfo creates a new ProcessInfo instance.
-func NewProcessInfo(clientID string, pid int) *ProcessInfo {
+// DefaultLogBufferLines is the default number of log lines kept in memory per process.
+const DefaultLogBufferLines = 5000
+
+// NewProcessInfo creates a new ProcessInfo instance keeping at most maxLogLines
+// recent log lines in memory (DefaultLogBufferLines if maxLogLines <= 0).
+func NewProcessInfo(clientID string, pid int, maxLogLines int) *ProcessInfo {
+	if maxLogLines <= 0 {
+		maxLogLines = DefaultLogBufferLines
+	}
 	return &ProcessInfo{
 		ClientID:     clientID,
 		PID:          pid,
 		Status:       ClientStatusRunning,
 		StartedAt:    time.Now(),
 		RestartCount: 0,
-		LogLines:     []string{},
-		LogListeners: []chan string{},
+		LogListeners: []chan LogRecord{},
+		logLines:     make([]LogRecord, maxLogLines),
 	}
 }
 
-// AddLog appends a log line to the process and broadcasts it to all active listeners.
+// AddLog appends a log record to the process, numbering it with the next sequence number, and
+// broadcasts it to all active listeners. Once the buffer is full the oldest record is overwritten.
 // Thread-safe for concurrent access.
-func (p *ProcessInfo) AddLog(line string) {
+func (p *ProcessInfo) AddLog(record LogRecord) {
 	p.logMu.Lock()
 	defer p.logMu.Unlock()
 
-	p.LogLines = append(p.LogLines, line)
+	p.logSeq++
+	entry := record
+	entry.Seq = p.logSeq
+
+	capacity := len(p.logLines)
+	if p.logCount < capacity {
+		p.logLines[(p.logStart+p.logCount)%capacity] = entry
+		p.logCount++
+	} else {
+		p.logLines[p.logStart] = entry
+		p.logStart = (p.logStart + 1) % capacity
+	}
 
 	// Broadcast to all SSE listeners
 	for _, ch := range p.LogListeners {
 		select {
-		case ch <- line:
+		case ch <- entry:
 			// Successfully sent
 		default:
 			// Channel is full or closed, skip this listener
@@ -57,18 +79,45 @@ func (p *ProcessInfo) AddLog(line string) {
 
 // AddLogListener creates a new log listener channel for SSE streaming.
 // Returns a buffered channel (100 messages) that will receive new log lines.
-func (p *ProcessInfo) AddLogListener() chan string {
+func (p *ProcessInfo) AddLogListener() chan LogRecord {
+	p.logMu.Lock()
+	defer p.logMu.Unlock()
+
+	return p.addLogListenerLocked()
+}
+
+// AddLogListenerSince creates a log listener and returns the buffered lines after seq,
+// so a reconnecting SSE client can resume without gaps or duplicates.
+// If seq is ahead of this process (it was restarted since), all buffered lines are returned.
+func (p *ProcessInfo) AddLogListenerSince(seq int64) (chan LogRecord, []LogRecord) {
 	p.logMu.Lock()
 	defer p.logMu.Unlock()
 
-	ch := make(chan string, 100)
+	if seq > p.logSeq {
+		seq = 0
+	}
+
+	capacity := len(p.logLines)
+	backlog := []LogRecord{}
+	for i := 0; i < p.logCount; i++ {
+		if line := p.logLines[(p.logStart+i)%capacity]; line.Seq > seq {
+			backlog = append(backlog, line)
+		}
+	}
+
+	return p.addLogListenerLocked(), backlog
+}
+
+// addLogListenerLocked registers a new listener channel. Caller must hold logMu.
+func (p *ProcessInfo) addLogListenerLocked() chan LogRecord {
+	ch := make(chan LogRecord, 100)
 	p.LogListeners = append(p.LogListeners, ch)
 	return ch
 }
 
 // RemoveLogListener removes and closes a log listener channel.
 // Should be called when an SSE client disconnects.
-func (p *ProcessInfo) RemoveLogListener(ch chan string) {
+func (p *ProcessInfo) RemoveLogListener(ch chan LogRecord) {
 	p.logMu.Lock()
 	defer p.logMu.Unlock()
 
@@ -90,18 +139,49 @@ func (p *ProcessInfo) CloseAllLogListeners() {
 	for _, ch := range p.LogListeners {
 		close(ch)
 	}
-	p.LogListeners = []chan string{}
+	p.LogListeners = []chan LogRecord{}
 }
 
-// GetLogLines returns a copy of all log lines.
+// GetLogLines returns the messages of the buffered log records, oldest first.
 // Thread-safe for concurrent access.
 func (p *ProcessInfo) GetLogLines() []string {
+	records := p.GetLogRecords()
+	logs := make([]string, len(records))
+	for i, record := range records {
+		logs[i] = record.Message
+	}
+	return logs
+}
+
+// GetLogRecords returns a copy of the buffered log records, oldest first.
+// Thread-safe for concurrent access.
+func (p *ProcessInfo) GetLogRecords() []LogRecord {
 	p.logMu.Lock()
 	defer p.logMu.Unlock()
 
-	logs := make([]string, len(p.LogLines))
-	copy(logs, p.LogLines)
-	return logs
+	capacity := len(p.logLines)
+	entries := make([]LogRecord, p.logCount)
+	for i := range entries {
+		entries[i] = p.logLines[(p.logStart+i)%capacity]
+	}
+	return entries
+}
+
+// DefaultLastRunLogLines is the default number of log lines kept of a stopped process.
+const DefaultLastRunLogLines = 200
+
+// ProcessLastRun holds the last log lines of a client's most recently stopped process,
+// available for a grace period after the process exited.
+type ProcessLastRun struct {
+	ClientID  string      `json:"clientId"`
+	PID       int         `json:"pid"`
+	StartedAt time.Time   `json:"startedAt"`
+	StoppedAt time.Time   `json:"stoppedAt"`
+	ExitCode  int         `json:"exitCode"`        // Process exit code (-1 if unknown or killed by a signal)
+	Crashed   bool        `json:"crashed"`         // Whether the process exited unexpectedly
+	Error     string      `json:"error,omitempty"` // Exit error of a crashed process
+	Logs      []LogRecord `json:"logs"`            // Last log records, oldest first
+	ExpiresAt time.Time   `json:"expiresAt"`       // When the lines are dropped from memory
 }
 
 // ClientStats represents statistics for a client instance.
@@ -114,4 +194,6 @@ type ClientStats struct {
 	SSEConnected     bool       `json:"sseConnected"`     // SSE connection status
 	ReconnectCount   int        `json:"reconnectCount"`   // SSE reconnect count
 	LastEventTime    *time.Time `json:"lastEventTime,omitempty"` // Last event time
+	DroppedEvents    int64      `json:"droppedEvents"`    // Events dropped over the ingestion limit since startup
+	SampledOutEvents int64      `json:"sampledOutEvents"` // Events discarded by the sampling policy since startup
 }
@@ -0,0 +1,30 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+// Reasons gosmee processes are left unmanaged.
+const (
+	UnmanagedUnknownClient = "unknown_client" // Saves events for a client that doesn't exist (anymore)
+	UnmanagedDuplicate     = "duplicate"      // Another process of the same client is managed already
+	UnmanagedNotAdopted    = "not_adopted"    // Started outside the backend after the startup scan
+	UnmanagedStale         = "stale"          // Left running by a previous backend (terminated by the "terminate" policy)
+)
+
+// UnmanagedProcess is a gosmee process saving events to the data directory that the
+// backend doesn't manage, e.g. left running by a crashed backend.
+type UnmanagedProcess struct {
+	PID      int    `json:"pid"`      // Process ID
+	UserID   string `json:"userId"`   // User directory the process saves events to
+	ClientID string `json:"clientId"` // Client directory the process saves events to
+	SaveDir  string `json:"saveDir"`  // Events directory relative to the data directory
+	Reason   string `json:"reason"`   // Why the process is not managed (one of the Unmanaged* constants)
+}
+
+// ProcessReconcileReport represents the result of matching running gosmee processes against
+// the clients.
+type ProcessReconcileReport struct {
+	Adopted    []string            `json:"adopted"`    // IDs of the clients whose processes were adopted
+	Terminated []*UnmanagedProcess `json:"terminated"` // Processes stopped by the "terminate" policy
+	Unmanaged  []*UnmanagedProcess `json:"unmanaged"`  // Processes left running unmanaged
+}
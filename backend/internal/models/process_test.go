@@ -0,0 +1,92 @@
+package models_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+)
+
+// stdoutRecord returns an info record read from stdout.
+func stdoutRecord(message string) models.LogRecord {
+	return models.LogRecord{Source: models.LogSourceStdout, Level: models.LogLevelInfo, Message: message}
+}
+
+// numbered returns the record with a sequence number.
+func numbered(seq int64, record models.LogRecord) models.LogRecord {
+	record.Seq = seq
+	return record
+}
+
+var _ = Describe("ProcessInfo log buffer", func() {
+	It("keeps only the most recent lines, oldest first", func() {
+		info := models.NewProcessInfo("client", 1, 3)
+		for i := 1; i <= 5; i++ {
+			info.AddLog(stdoutRecord(fmt.Sprintf("line %d", i)))
+		}
+
+		Expect(info.GetLogLines()).To(Equal([]string{"line 3", "line 4", "line 5"}))
+	})
+
+	It("returns every line before the buffer is full", func() {
+		info := models.NewProcessInfo("client", 1, 3)
+		info.AddLog(stdoutRecord("line 1"))
+		info.AddLog(stdoutRecord("line 2"))
+
+		Expect(info.GetLogLines()).To(Equal([]string{"line 1", "line 2"}))
+	})
+
+	It("replays the buffered lines after a sequence number when resuming", func() {
+		info := models.NewProcessInfo("client", 1, 10)
+		for i := 1; i <= 4; i++ {
+			info.AddLog(stdoutRecord(fmt.Sprintf("line %d", i)))
+		}
+
+		listener, backlog := info.AddLogListenerSince(2)
+		Expect(backlog).To(Equal([]models.LogRecord{numbered(3, stdoutRecord("line 3")), numbered(4, stdoutRecord("line 4"))}))
+
+		info.AddLog(stdoutRecord("line 5"))
+		Expect(<-listener).To(Equal(numbered(5, stdoutRecord("line 5"))))
+	})
+
+	It("replays everything when the sequence number belongs to a previous process", func() {
+		info := models.NewProcessInfo("client", 1, 10)
+		info.AddLog(stdoutRecord("line 1"))
+
+		_, backlog := info.AddLogListenerSince(500)
+		Expect(backlog).To(Equal([]models.LogRecord{numbered(1, stdoutRecord("line 1"))}))
+	})
+
+	It("keeps the source of buffered records", func() {
+		info := models.NewProcessInfo("client", 1, 10)
+		info.AddLog(stdoutRecord("connected"))
+		info.AddLog(models.LogRecord{Source: models.LogSourceStderr, Level: models.LogLevelError, Message: "connection refused"})
+
+		records := info.GetLogRecords()
+		Expect(records).To(HaveLen(2))
+		Expect(records[0].Source).To(Equal(models.LogSourceStdout))
+		Expect(records[1].Source).To(Equal(models.LogSourceStderr))
+		Expect(records[1].Seq).To(Equal(int64(2)))
+	})
+})
+
+var _ = Describe("LogFilter", func() {
+	It("matches records by source, minimum level, delivery and message", func() {
+		record := models.LogRecord{
+			Source:   models.LogSourceStdout,
+			Level:    models.LogLevelWarn,
+			Message:  "Replayed push, status: 502",
+			Delivery: &models.LogDelivery{EventType: "push", StatusCode: 502},
+		}
+
+		Expect(models.LogFilter{}.Matches(record)).To(BeTrue())
+		Expect(models.LogFilter{Source: models.LogSourceStderr}.Matches(record)).To(BeFalse())
+		Expect(models.LogFilter{Level: models.LogLevelInfo}.Matches(record)).To(BeTrue())
+		Expect(models.LogFilter{Level: models.LogLevelError}.Matches(record)).To(BeFalse())
+		Expect(models.LogFilter{Delivery: true}.Matches(record)).To(BeTrue())
+		Expect(models.LogFilter{Delivery: true}.Matches(stdoutRecord("connected"))).To(BeFalse())
+		Expect(models.LogFilter{Search: "PUSH"}.Matches(record)).To(BeTrue())
+	})
+})
@@ -0,0 +1,63 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+// Webhook providers a client can receive events from.
+const (
+	ProviderGitHub    = "github"
+	ProviderGitLab    = "gitlab"
+	ProviderBitbucket = "bitbucket"
+	ProviderStripe    = "stripe"
+	ProviderCustom    = "custom" // Any other sender; event types are detected from well-known headers
+)
+
+// WebhookProvider describes where a provider puts the event type, signature and delivery ID
+// of its webhooks.
+type WebhookProvider struct {
+	ID               string   `json:"id"`                         // Provider ID
+	Name             string   `json:"name"`                       // Display name
+	EventHeader      string   `json:"eventHeader,omitempty"`      // Header naming the event type
+	EventField       string   `json:"eventField,omitempty"`       // Top-level payload field naming the event type
+	SignatureHeaders []string `json:"signatureHeaders,omitempty"` // Headers signing the payload
+	DeliveryHeader   string   `json:"deliveryHeader,omitempty"`   // Header with the unique delivery ID
+}
+
+// WebhookProviders lists the supported providers by ID.
+var WebhookProviders = map[string]WebhookProvider{
+	ProviderGitHub: {
+		ID: ProviderGitHub, Name: "GitHub",
+		EventHeader:      "X-GitHub-Event",
+		SignatureHeaders: []string{"X-Hub-Signature-256", "X-Hub-Signature"},
+		DeliveryHeader:   "X-GitHub-Delivery",
+	},
+	ProviderGitLab: {
+		ID: ProviderGitLab, Name: "GitLab",
+		EventHeader:      "X-Gitlab-Event",
+		SignatureHeaders: []string{"X-Gitlab-Token"},
+		DeliveryHeader:   "X-Gitlab-Event-UUID",
+	},
+	ProviderBitbucket: {
+		ID: ProviderBitbucket, Name: "Bitbucket",
+		EventHeader:      "X-Event-Key",
+		SignatureHeaders: []string{"X-Hub-Signature"},
+		DeliveryHeader:   "X-Request-UUID",
+	},
+	ProviderStripe: {
+		ID: ProviderStripe, Name: "Stripe",
+		EventField:       "type",
+		SignatureHeaders: []string{"Stripe-Signature"},
+	},
+	ProviderCustom: {
+		ID: ProviderCustom, Name: "Custom",
+	},
+}
+
+// WebhookProvider returns the provider the client receives events from. Clients without a
+// known provider are custom.
+func (c *Client) WebhookProvider() WebhookProvider {
+	if provider, ok := WebhookProviders[c.Provider]; ok {
+		return provider
+	}
+	return WebhookProviders[ProviderCustom]
+}
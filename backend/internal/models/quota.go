@@ -7,27 +7,40 @@ import (
 	"time"
 )
 
+// Default storage thresholds in percent of the quota.
+const (
+	DefaultStorageWarningThreshold = 80.0  // Usage that triggers a storage warning
+	DefaultStorageFullThreshold    = 100.0 // Usage at which storage counts as full
+)
+
 // Quota represents user storage quota information.
 type Quota struct {
-	UserID       string    `json:"userId"`       // User ID
-	TotalBytes   int64     `json:"totalBytes"`   // Total quota in bytes
-	UsedBytes    int64     `json:"usedBytes"`    // Used storage in bytes
-	Percentage   float64   `json:"percentage"`   // Usage percentage (0-100)
-	ClientsCount int       `json:"clientsCount"` // Current number of clients
-	MaxClients   int       `json:"maxClients"`   // Maximum allowed clients
-	UpdatedAt    time.Time `json:"updatedAt"`    // Last update time
+	UserID            string    `json:"userId"`            // User ID
+	TotalBytes        int64     `json:"totalBytes"`        // Total quota in bytes
+	UsedBytes         int64     `json:"usedBytes"`         // Used storage in bytes
+	Percentage        float64   `json:"percentage"`        // Usage percentage (0-100)
+	ClientsCount      int       `json:"clientsCount"`      // Current number of clients
+	MaxClients        int       `json:"maxClients"`        // Hard client limit, creating more clients is blocked
+	SoftMaxClients    int       `json:"softMaxClients"`    // Soft client limit, more clients are allowed with a warning (0 = none)
+	RunningClients    int       `json:"runningClients"`    // Number of clients running now
+	MaxRunningClients int       `json:"maxRunningClients"` // Clients that may run at the same time, starting more is blocked (0 = unlimited)
+	WarningThreshold  float64   `json:"warningThreshold"`  // Usage percentage that triggers a storage warning
+	FullThreshold     float64   `json:"fullThreshold"`     // Usage percentage at which storage counts as full
+	UpdatedAt         time.Time `json:"updatedAt"`         // Last update time
 }
 
 // NewQuota creates a new Quota instance.
 func NewQuota(userID string, totalBytes int64, maxClients int) *Quota {
 	return &Quota{
-		UserID:       userID,
-		TotalBytes:   totalBytes,
-		UsedBytes:    0,
-		Percentage:   0.0,
-		ClientsCount: 0,
-		MaxClients:   maxClients,
-		UpdatedAt:    time.Now(),
+		UserID:           userID,
+		TotalBytes:       totalBytes,
+		UsedBytes:        0,
+		Percentage:       0.0,
+		ClientsCount:     0,
+		MaxClients:       maxClients,
+		WarningThreshold: DefaultStorageWarningThreshold,
+		FullThreshold:    DefaultStorageFullThreshold,
+		UpdatedAt:        time.Now(),
 	}
 }
 
@@ -41,17 +54,22 @@ func (q *Quota) UpdateUsage(usedBytes int64, clientsCount int) {
 	q.UpdatedAt = time.Now()
 }
 
-// IsStorageFull checks if storage quota is full (100%).
+// IsStorageFull checks if storage usage reached the full threshold (100% by default).
 func (q *Quota) IsStorageFull() bool {
-	return q.Percentage >= 100.0
+	return q.Percentage >= q.FullThreshold
 }
 
-// IsStorageWarning checks if storage usage is above 80%.
+// IsStorageWarning checks if storage usage reached the warning threshold (80% by default).
 func (q *Quota) IsStorageWarning() bool {
-	return q.Percentage >= 80.0
+	return q.Percentage >= q.WarningThreshold
 }
 
-// IsClientsLimitReached checks if the maximum number of clients is reached.
+// IsClientsSoftLimitReached checks if the soft client limit is set and reached.
+func (q *Quota) IsClientsSoftLimitReached() bool {
+	return q.SoftMaxClients > 0 && q.ClientsCount >= q.SoftMaxClients
+}
+
+// IsClientsLimitReached checks if the hard client limit is reached.
 func (q *Quota) IsClientsLimitReached() bool {
 	return q.ClientsCount >= q.MaxClients
 }
@@ -60,3 +78,24 @@ func (q *Quota) IsClientsLimitReached() bool {
 func (q *Quota) CanCreateClient() bool {
 	return !q.IsClientsLimitReached()
 }
+
+// QuotaSnapshot is a point-in-time sample of a user's quota usage.
+type QuotaSnapshot struct {
+	Time         time.Time `json:"time"`         // When the usage was sampled
+	UsedBytes    int64     `json:"usedBytes"`    // Used storage in bytes
+	TotalBytes   int64     `json:"totalBytes"`   // Total quota in bytes at that time
+	ClientsCount int       `json:"clientsCount"` // Number of clients at that time
+}
+
+// QuotaHistoryRequest represents the query parameters of the quota history.
+type QuotaHistoryRequest struct {
+	Days int `form:"days,default=30" binding:"min=1,max=90"` // Number of days to return
+}
+
+// QuotaHistoryResponse represents the quota usage history of a user with its growth trend.
+type QuotaHistoryResponse struct {
+	Snapshots         []*QuotaSnapshot `json:"snapshots"`                 // Snapshots in ascending time order
+	GrowthBytesPerDay float64          `json:"growthBytesPerDay"`         // Storage growth trend (linear fit, may be negative)
+	DaysUntilFull     *float64         `json:"daysUntilFull,omitempty"`   // Days until the quota is reached at the current trend
+	EstimatedFullAt   *time.Time       `json:"estimatedFullAt,omitempty"` // When the quota is reached at the current trend
+}
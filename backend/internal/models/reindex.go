@@ -0,0 +1,20 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+// ReindexReport is the result of rebuilding the indices and rollups derived from the raw
+// event files.
+type ReindexReport struct {
+	Clients     int               `json:"clients"`     // Clients reindexed
+	StatsDays   int               `json:"statsDays"`   // Daily rollups rewritten
+	DeliveryIDs int               `json:"deliveryIds"` // Events indexed by delivery ID
+	S3Events    int               `json:"s3Events"`    // Event objects indexed (S3 event storage only)
+	Failed      []*ReindexFailure `json:"failed"`      // Clients whose indices could not be rebuilt
+}
+
+// ReindexFailure is a client whose indices could not be rebuilt.
+type ReindexFailure struct {
+	ClientID string `json:"clientId"` // Client ID
+	Error    string `json:"error"`    // Why the rebuild failed
+}
@@ -0,0 +1,31 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import "fmt"
+
+// Event sampling modes.
+const (
+	SamplingModeOneInN   = "one_in_n" // Keep one in every Rate new events
+	SamplingModeFailures = "failures" // Keep only events whose delivery failed
+)
+
+// SamplingPolicy thins out the new events stored for a client, so a very chatty channel can
+// be monitored without using up the storage quota. Discarded events are deleted as soon as
+// gosmee stored them, in the failures mode once they were delivered successfully.
+type SamplingPolicy struct {
+	Mode string `json:"mode" binding:"omitempty,oneof=one_in_n failures"`    // Sampling mode (empty keeps all events)
+	Rate int    `json:"rate,omitempty" binding:"omitempty,min=2,max=100000"` // Keep one in every Rate events (one_in_n only)
+}
+
+// Validate checks that the one_in_n mode has a rate.
+func (p *SamplingPolicy) Validate() error {
+	if p.Mode == SamplingModeOneInN && p.Rate < 2 {
+		return fmt.Errorf("rate must be at least 2 to keep one in every rate events")
+	}
+	if p.Mode != SamplingModeOneInN && p.Rate != 0 {
+		return fmt.Errorf("rate only applies to the %s mode", SamplingModeOneInN)
+	}
+	return nil
+}
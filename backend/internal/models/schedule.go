@@ -0,0 +1,131 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// weekdayNames maps schedule day names to weekdays.
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// ClientSchedule defines the time windows during which a client should be running.
+type ClientSchedule struct {
+	Enabled  bool             `json:"enabled"`            // Whether the schedule is enforced
+	Timezone string           `json:"timezone,omitempty"` // IANA time zone (default: server local time)
+	Windows  []ScheduleWindow `json:"windows"`            // Time windows the client runs in
+}
+
+// ScheduleWindow is a daily time window, e.g. 08:00-20:00 on weekdays.
+// A window whose end is not after its start spans midnight into the next day.
+type ScheduleWindow struct {
+	Days  []string `json:"days,omitempty"` // Days the window starts on ("mon".."sun", empty = every day)
+	Start string   `json:"start"`          // Start time (HH:MM)
+	End   string   `json:"end"`            // End time (HH:MM)
+}
+
+// Validate checks the schedule for unknown days, malformed times and time zones.
+func (s *ClientSchedule) Validate() error {
+	if s.Timezone != "" {
+		if _, err := time.LoadLocation(s.Timezone); err != nil {
+			return fmt.Errorf("unknown timezone %q", s.Timezone)
+		}
+	}
+	if s.Enabled && len(s.Windows) == 0 {
+		return fmt.Errorf("at least one window is required")
+	}
+
+	for i, window := range s.Windows {
+		for _, day := range window.Days {
+			if _, ok := weekdayNames[strings.ToLower(day)]; !ok {
+				return fmt.Errorf("window %d: unknown day %q", i+1, day)
+			}
+		}
+		if _, err := parseClock(window.Start); err != nil {
+			return fmt.Errorf("window %d: invalid start: %w", i+1, err)
+		}
+		if _, err := parseClock(window.End); err != nil {
+			return fmt.Errorf("window %d: invalid end: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// Active reports whether t falls inside one of the schedule windows.
+func (s *ClientSchedule) Active(t time.Time) bool {
+	local := t.In(s.location())
+	minute := local.Hour()*60 + local.Minute()
+	today := local.Weekday()
+	yesterday := (today + 6) % 7
+
+	for _, window := range s.Windows {
+		start, errStart := parseClock(window.Start)
+		end, errEnd := parseClock(window.End)
+		if errStart != nil || errEnd != nil {
+			continue
+		}
+
+		if start < end {
+			if window.onDay(today) && minute >= start && minute < end {
+				return true
+			}
+			continue
+		}
+
+		// Overnight window: from start until midnight, then until end on the following day
+		if window.onDay(today) && minute >= start {
+			return true
+		}
+		if window.onDay(yesterday) && minute < end {
+			return true
+		}
+	}
+
+	return false
+}
+
+// location returns the schedule time zone, falling back to server local time.
+func (s *ClientSchedule) location() *time.Location {
+	if s.Timezone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// onDay reports whether the window starts on the given weekday.
+func (w *ScheduleWindow) onDay(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, name := range w.Days {
+		if weekday, ok := weekdayNames[strings.ToLower(name)]; ok && weekday == day {
+			return true
+		}
+	}
+	return false
+}
+
+// parseClock parses an HH:MM time of day into minutes since midnight.
+func parseClock(value string) (int, error) {
+	parsed, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("expected HH:MM, got %q", value)
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
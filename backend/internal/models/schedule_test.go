@@ -0,0 +1,48 @@
+package models_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+)
+
+var _ = Describe("ClientSchedule", func() {
+	weekdays := []string{"mon", "tue", "wed", "thu", "fri"}
+
+	// 2025-10-06 is a Monday
+	at := func(value string) time.Time {
+		t, err := time.Parse("2006-01-02 15:04", value)
+		Expect(err).NotTo(HaveOccurred())
+		return t
+	}
+
+	DescribeTable("evaluates whether a time falls inside the schedule",
+		func(window models.ScheduleWindow, now string, expected bool) {
+			schedule := &models.ClientSchedule{
+				Enabled:  true,
+				Timezone: "UTC",
+				Windows:  []models.ScheduleWindow{window},
+			}
+			Expect(schedule.Validate()).To(Succeed())
+			Expect(schedule.Active(at(now))).To(Equal(expected))
+		},
+		Entry("inside business hours", models.ScheduleWindow{Days: weekdays, Start: "08:00", End: "20:00"}, "2025-10-06 09:30", true),
+		Entry("before business hours", models.ScheduleWindow{Days: weekdays, Start: "08:00", End: "20:00"}, "2025-10-06 07:59", false),
+		Entry("end is exclusive", models.ScheduleWindow{Days: weekdays, Start: "08:00", End: "20:00"}, "2025-10-06 20:00", false),
+		Entry("weekend is excluded", models.ScheduleWindow{Days: weekdays, Start: "08:00", End: "20:00"}, "2025-10-11 10:00", false),
+		Entry("empty days means every day", models.ScheduleWindow{Start: "08:00", End: "20:00"}, "2025-10-11 10:00", true),
+		Entry("overnight window before midnight", models.ScheduleWindow{Days: []string{"fri"}, Start: "22:00", End: "06:00"}, "2025-10-10 23:00", true),
+		Entry("overnight window after midnight", models.ScheduleWindow{Days: []string{"fri"}, Start: "22:00", End: "06:00"}, "2025-10-11 05:00", true),
+		Entry("overnight window on another day", models.ScheduleWindow{Days: []string{"fri"}, Start: "22:00", End: "06:00"}, "2025-10-12 05:00", false),
+	)
+
+	It("rejects malformed schedules", func() {
+		Expect((&models.ClientSchedule{Timezone: "Mars/Olympus"}).Validate()).NotTo(Succeed())
+		Expect((&models.ClientSchedule{Enabled: true}).Validate()).NotTo(Succeed())
+		Expect((&models.ClientSchedule{Windows: []models.ScheduleWindow{{Days: []string{"someday"}, Start: "08:00", End: "20:00"}}}).Validate()).NotTo(Succeed())
+		Expect((&models.ClientSchedule{Windows: []models.ScheduleWindow{{Start: "8am", End: "20:00"}}}).Validate()).NotTo(Succeed())
+	})
+})
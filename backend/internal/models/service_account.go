@@ -0,0 +1,125 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Permission scopes granted to service account tokens.
+const (
+	ScopeAll               = "*"                  // All scopes
+	ScopeClientsRead       = "clients:read"       // List and inspect clients, stats and circuit state
+	ScopeClientsWrite      = "clients:write"      // Create, update and delete clients, reset circuits
+	ScopeClientsStart      = "clients:start"      // Start, restart and resume clients (incl. batch and rolling restart)
+	ScopeClientsStop       = "clients:stop"       // Stop and pause clients (incl. batch stop)
+	ScopeLogsRead          = "logs:read"          // Read, stream and download logs
+	ScopeLogsWrite         = "logs:write"         // Add and delete log annotations
+	ScopeEventsRead        = "events:read"        // List and inspect events
+	ScopeEventsWrite       = "events:write"       // Inject, replay and delete events
+	ScopeJobsRead          = "jobs:read"          // Inspect background jobs
+	ScopeNotificationsRead = "notifications:read" // List and acknowledge notifications
+	ScopeQuotaRead         = "quota:read"         // Read quota usage
+	ScopeSettingsRead      = "settings:read"      // Read user settings
+	ScopeSettingsWrite     = "settings:write"     // Update user settings
+	ScopeMetricsRead       = "metrics:read"       // Scrape Prometheus metrics
+)
+
+// AllScopes lists every scope that can be granted to a service account.
+var AllScopes = []string{
+	ScopeAll,
+	ScopeClientsRead,
+	ScopeClientsWrite,
+	ScopeClientsStart,
+	ScopeClientsStop,
+	ScopeLogsRead,
+	ScopeLogsWrite,
+	ScopeEventsRead,
+	ScopeEventsWrite,
+	ScopeJobsRead,
+	ScopeNotificationsRead,
+	ScopeQuotaRead,
+	ScopeSettingsRead,
+	ScopeSettingsWrite,
+	ScopeMetricsRead,
+}
+
+// ServiceAccount is a non-interactive identity authenticating with a bearer token.
+// It acts on behalf of UserID, limited to its scopes.
+type ServiceAccount struct {
+	ID          string     `json:"id"`                    // Service account ID (UUID)
+	Name        string     `json:"name"`                  // Display name (e.g. "deploy-hook")
+	Description string     `json:"description,omitempty"` // Optional description
+	UserID      string     `json:"userId"`                // User whose clients the account acts on
+	Scopes      []string   `json:"scopes"`                // Granted scopes
+	TokenPrefix string     `json:"tokenPrefix"`           // First characters of the token, for identification
+	TokenHash   string     `json:"-"`                     // SHA-256 of the token (never exposed via the API)
+	CreatedBy   string     `json:"createdBy"`             // Admin who created the account
+	CreatedAt   time.Time  `json:"createdAt"`             // Creation time
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`   // Token expiry (never when absent)
+	LastUsedAt  *time.Time `json:"lastUsedAt,omitempty"`  // Last successful authentication
+}
+
+// HasScope reports whether the account was granted scope.
+func (a *ServiceAccount) HasScope(scope string) bool {
+	for _, granted := range a.Scopes {
+		if granted == ScopeAll || granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IsExpired reports whether the account's token has expired at now.
+func (a *ServiceAccount) IsExpired(now time.Time) bool {
+	return a.ExpiresAt != nil && now.After(*a.ExpiresAt)
+}
+
+// GetUserID returns the user the account acts on behalf of.
+func (a *ServiceAccount) GetUserID() string {
+	return a.UserID
+}
+
+// GetScopes returns the granted scopes.
+func (a *ServiceAccount) GetScopes() []string {
+	return a.Scopes
+}
+
+// ServiceAccountRequest represents the request body for creating a service account.
+type ServiceAccountRequest struct {
+	Name          string   `json:"name" binding:"required,min=1,max=50"` // Display name
+	Description   string   `json:"description" binding:"max=200"`        // Optional description
+	UserID        string   `json:"userId"`                               // User to act on behalf of (default: the admin)
+	Scopes        []string `json:"scopes" binding:"required,min=1"`      // Granted scopes
+	ExpiresInDays int      `json:"expiresInDays" binding:"min=0"`        // Token lifetime in days (0 = never expires)
+}
+
+// Validate checks that all requested scopes exist.
+func (r *ServiceAccountRequest) Validate() error {
+	for _, scope := range r.Scopes {
+		known := false
+		for _, candidate := range AllScopes {
+			if scope == candidate {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown scope: %s", scope)
+		}
+	}
+	return nil
+}
+
+// ServiceAccountTokenResponse is returned when a token is issued. The token is only shown once.
+type ServiceAccountTokenResponse struct {
+	ServiceAccount *ServiceAccount `json:"serviceAccount"`
+	Token          string          `json:"token"`
+}
+
+// ServiceAccountListResponse represents the response for service account list queries.
+type ServiceAccountListResponse struct {
+	ServiceAccounts []*ServiceAccount `json:"serviceAccounts"`
+}
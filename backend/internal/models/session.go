@@ -0,0 +1,21 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import "time"
+
+// Session is an active login session of a user, as listed by the session API.
+type Session struct {
+	ID        string    `json:"id"`                  // Public session ID (not the session cookie)
+	IP        string    `json:"ip,omitempty"`        // Client IP the session was created from
+	UserAgent string    `json:"userAgent,omitempty"` // User agent the session was created with
+	CreatedAt time.Time `json:"createdAt"`           // Login time
+	ExpiresAt time.Time `json:"expiresAt"`           // Expiry time
+	Current   bool      `json:"current"`             // Whether this is the session of the request
+}
+
+// SessionListResponse represents the response for listing sessions.
+type SessionListResponse struct {
+	Sessions []*Session `json:"sessions"`
+}
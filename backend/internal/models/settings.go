@@ -0,0 +1,42 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import (
+	"time"
+)
+
+// UserSettings holds per-user preferences that apply to all of the user's clients.
+type UserSettings struct {
+	UserID    string          `json:"userId"`    // User ID
+	Masking   MaskingSettings `json:"masking"`   // Secret masking configuration
+	Quota     QuotaSettings   `json:"quota"`     // Storage threshold overrides
+	UpdatedAt time.Time       `json:"updatedAt"` // Last update time
+}
+
+// MaskingSettings extends the built-in secret masking applied to logs, event details and scripts.
+// Authorization, signature, cookie and token headers are always masked.
+type MaskingSettings struct {
+	Headers  []string `json:"headers"`  // Additional header or field names to redact (case-insensitive)
+	Patterns []string `json:"patterns"` // Regular expressions whose matches (or capture groups) are redacted
+}
+
+// MaskingSettingsRequest represents a request to update masking settings.
+type MaskingSettingsRequest struct {
+	Headers  []string `json:"headers" binding:"max=50,dive,min=1,max=100"`
+	Patterns []string `json:"patterns" binding:"max=50,dive,min=1,max=500"`
+}
+
+// QuotaSettings overrides the server's storage thresholds for a user, in percent of the quota.
+// 0 uses the server default. The full threshold cannot exceed the server's.
+type QuotaSettings struct {
+	WarningThreshold float64 `json:"warningThreshold"` // Usage that triggers a storage warning
+	FullThreshold    float64 `json:"fullThreshold"`    // Usage at which storage counts as full
+}
+
+// QuotaSettingsRequest represents a request to update quota settings.
+type QuotaSettingsRequest struct {
+	WarningThreshold float64 `json:"warningThreshold" binding:"gte=0,lte=100"`
+	FullThreshold    float64 `json:"fullThreshold" binding:"gte=0,lte=100"`
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import (
+	"time"
+)
+
+// StatsDateFormat is the layout of rollup dates (server local time).
+const StatsDateFormat = "2006-01-02"
+
+// LatencyBucketsMs are the upper bounds (inclusive) of the delivery latency histogram buckets.
+// Histograms have one more bucket for latencies above the last bound.
+var LatencyBucketsMs = []int{10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// DailyStats is the persisted rollup of a client's events on one day. Rollups are kept
+// independently of event retention, so historic statistics survive event cleanup.
+type DailyStats struct {
+	ClientID         string    `json:"clientId"`                   // Client ID
+	Date             string    `json:"date"`                       // Day (YYYY-MM-DD, server local time)
+	Events           int       `json:"events"`                     // Events received
+	Successful       int       `json:"successful"`                 // Events delivered successfully
+	Failed           int       `json:"failed"`                     // Events whose delivery failed
+	LatencyP50Ms     int       `json:"latencyP50Ms"`               // Median delivery latency in ms
+	LatencyP95Ms     int       `json:"latencyP95Ms"`               // 95th percentile delivery latency in ms
+	AverageLatencyMs int       `json:"averageLatencyMs"`           // Average delivery latency in ms
+	LatencyHistogram []int     `json:"latencyHistogram,omitempty"` // Deliveries per LatencyBucketsMs bucket, the last one above all bounds
+	LatencySumMs     int64     `json:"latencySumMs,omitempty"`     // Sum of delivery latencies in ms
+	StorageBytes     int64     `json:"storageBytes"`               // Storage used by the client at the end of the day (last rollup)
+	UpdatedAt        time.Time `json:"updatedAt"`                  // Time of the rollup
+}
+
+// LatencyBucket is a bucket of a delivery latency histogram.
+type LatencyBucket struct {
+	UpperMs int `json:"upperMs,omitempty"` // Inclusive upper bound in ms (absent for the last, unbounded bucket)
+	Count   int `json:"count"`             // Deliveries in the bucket (not cumulative)
+}
+
+// Statistics query granularities.
+const (
+	StatsGranularityDay   = "day"
+	StatsGranularityWeek  = "week" // Weeks start on Monday
+	StatsGranularityMonth = "month"
+)
+
+// StatsQueryRequest represents query parameters for bucketed statistics.
+type StatsQueryRequest struct {
+	From        time.Time `form:"from" time_format:"2006-01-02"`                          // First day (optional, default: 29 days before to)
+	To          time.Time `form:"to" time_format:"2006-01-02"`                            // Last day (optional, default: today)
+	Granularity string    `form:"granularity,default=day" binding:"oneof=day week month"` // Bucket size (default: day)
+}
+
+// StatsBucket aggregates the daily rollups of a time range.
+type StatsBucket struct {
+	Start            string  `json:"start"`            // First day of the bucket (YYYY-MM-DD)
+	End              string  `json:"end"`              // Last day of the bucket (YYYY-MM-DD)
+	Events           int     `json:"events"`           // Events received
+	Successful       int     `json:"successful"`       // Events delivered successfully
+	Failed           int     `json:"failed"`           // Events whose delivery failed
+	SuccessRate      float64 `json:"successRate"`      // Successful deliveries in percent (0 without deliveries)
+	LatencyP50Ms     int     `json:"latencyP50Ms"`     // Median delivery latency in ms
+	LatencyP95Ms     int     `json:"latencyP95Ms"`     // 95th percentile delivery latency in ms
+	AverageLatencyMs int     `json:"averageLatencyMs"` // Average delivery latency in ms
+
+	LatencyHistogram []LatencyBucket `json:"latencyHistogram"` // Delivery latency distribution
+}
+
+// StatsQueryResponse represents bucketed statistics of a client.
+type StatsQueryResponse struct {
+	ClientID    string         `json:"clientId"`    // Client ID
+	Granularity string         `json:"granularity"` // Bucket size
+	From        string         `json:"from"`        // First day of the range (YYYY-MM-DD)
+	To          string         `json:"to"`          // Last day of the range (YYYY-MM-DD)
+	Buckets     []*StatsBucket `json:"buckets"`     // Buckets in ascending order, including empty ones
+	Total       *StatsBucket   `json:"total"`       // Aggregate of the whole range
+}
+
+// StatsOverviewResponse aggregates the statistics of all of a user's clients.
+type StatsOverviewResponse struct {
+	From              string            `json:"from"`              // First day (YYYY-MM-DD)
+	To                string            `json:"to"`                // Last day (YYYY-MM-DD)
+	Clients           int               `json:"clients"`           // Number of clients
+	Total             *StatsBucket      `json:"total"`             // Aggregate of all clients over the whole range
+	Days              []*OverviewDay    `json:"days"`              // Per-day totals in ascending order, including empty days
+	TopFailingClients []*ClientFailures `json:"topFailingClients"` // Clients with the most failed deliveries in the range
+}
+
+// OverviewDay holds the totals of all of a user's clients on one day.
+type OverviewDay struct {
+	Date         string `json:"date"`         // Day (YYYY-MM-DD)
+	Events       int    `json:"events"`       // Events received
+	Successful   int    `json:"successful"`   // Events delivered successfully
+	Failed       int    `json:"failed"`       // Events whose delivery failed
+	StorageBytes int64  `json:"storageBytes"` // Storage used by the clients (0 before storage was recorded)
+}
+
+// ClientFailures summarizes the failed deliveries of a client.
+type ClientFailures struct {
+	ClientID    string  `json:"clientId"`    // Client ID
+	Name        string  `json:"name"`        // Client name
+	Failed      int     `json:"failed"`      // Failed deliveries
+	Deliveries  int     `json:"deliveries"`  // All deliveries
+	FailureRate float64 `json:"failureRate"` // Failed deliveries in percent
+}
+
+// DailyStatsRequest represents query parameters for daily statistics.
+type DailyStatsRequest struct {
+	DateFrom time.Time `form:"dateFrom" time_format:"2006-01-02"` // First day (optional, default: 30 days ago)
+	DateTo   time.Time `form:"dateTo" time_format:"2006-01-02"`   // Last day (optional, default: today)
+}
+
+// DailyStatsResponse represents the daily statistics of a client.
+type DailyStatsResponse struct {
+	ClientID string        `json:"clientId"` // Client ID
+	Days     []*DailyStats `json:"days"`     // Rollups in ascending date order (days without events are omitted)
+}
+
+// StatsHeatmapRequest represents query parameters for the activity heatmap.
+type StatsHeatmapRequest struct {
+	Weeks    int    `form:"weeks,default=4" binding:"min=1,max=52"` // Number of weeks back from now (default: 4)
+	Timezone string `form:"timezone"`                               // IANA time zone of the buckets (optional, default: server local time)
+}
+
+// StatsHeatmapResponse counts a client's events by weekday and hour of the day.
+type StatsHeatmapResponse struct {
+	ClientID string     `json:"clientId"` // Client ID
+	Weeks    int        `json:"weeks"`    // Number of weeks covered
+	Timezone string     `json:"timezone"` // Time zone of the buckets
+	From     time.Time  `json:"from"`     // Start of the covered range
+	To       time.Time  `json:"to"`       // End of the covered range
+	Total    int        `json:"total"`    // Events in the range
+	Counts   [7][24]int `json:"counts"`   // Events per weekday (0 = Monday) and hour (0-23)
+}
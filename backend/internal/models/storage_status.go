@@ -0,0 +1,32 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import "time"
+
+// StorageLevel rates the free space of a data volume.
+type StorageLevel string
+
+const (
+	StorageLevelOK       StorageLevel = "ok"       // Enough free space
+	StorageLevelWarning  StorageLevel = "warning"  // Free space below the warning threshold
+	StorageLevelCritical StorageLevel = "critical" // Free space below the critical threshold, or unknown
+)
+
+// StorageVolume describes the free space of the volume holding a data directory.
+type StorageVolume struct {
+	Path        string       `json:"path"`            // Data directory
+	TotalBytes  int64        `json:"totalBytes"`      // Size of the volume
+	FreeBytes   int64        `json:"freeBytes"`       // Space available to the server
+	FreePercent float64      `json:"freePercent"`     // FreeBytes in percent of TotalBytes
+	Level       StorageLevel `json:"level"`           // Rating of the free space
+	Error       string       `json:"error,omitempty"` // Why the free space could not be determined
+}
+
+// StorageStatus is the result of the latest check of the data volumes.
+type StorageStatus struct {
+	Level     StorageLevel     `json:"level"`     // Worst level of all volumes
+	CheckedAt time.Time        `json:"checkedAt"` // Time of the check
+	Volumes   []*StorageVolume `json:"volumes"`   // Data directories checked
+}
@@ -0,0 +1,32 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package models
+
+import "time"
+
+// UserOffboardRequest represents query parameters for offboarding a user.
+type UserOffboardRequest struct {
+	Archive bool   `form:"archive"`                            // Move the user's data to the archive directory instead of deleting it
+	Reason  string `form:"reason" binding:"omitempty,max=500"` // Reason recorded in the audit log (optional)
+}
+
+// UserOffboardResult summarizes an offboarded user.
+type UserOffboardResult struct {
+	UserID                 string `json:"userId"`                 // Offboarded user
+	Clients                int    `json:"clients"`                // Number of removed clients
+	ClientsStopped         int    `json:"clientsStopped"`         // Number of clients that were running
+	Size                   int64  `json:"size"`                   // Bytes of data deleted or archived
+	Archived               bool   `json:"archived"`               // Whether the data was archived instead of deleted
+	ArchivePath            string `json:"archivePath,omitempty"`  // Archive directory, relative to the data directory
+	SessionsRevoked        int    `json:"sessionsRevoked"`        // Number of revoked login sessions
+	ServiceAccountsDeleted int    `json:"serviceAccountsDeleted"` // Number of deleted service accounts acting as the user
+}
+
+// UserOffboardingRecord is an audit log entry of an offboarded user.
+type UserOffboardingRecord struct {
+	Time    time.Time `json:"time"`             // When the user was offboarded
+	AdminID string    `json:"adminId"`          // Administrator who offboarded the user
+	Reason  string    `json:"reason,omitempty"` // Reason given by the administrator
+	UserOffboardResult
+}
@@ -0,0 +1,180 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package encryption provides AES-256-GCM encryption for event files and logs at rest.
+//
+// Files are sealed as a whole (magic header, nonce, ciphertext). Log files are appended to
+// line by line, so each log line is sealed on its own and stored base64-encoded behind a
+// line prefix. Plaintext data written before encryption was enabled is passed through on
+// read, so existing data directories keep working.
+//
+// All methods accept a nil *Cipher, which leaves data unencrypted.
+package encryption
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// KeySize is the size of an encryption key in bytes (AES-256).
+const KeySize = 32
+
+// fileMagic starts every sealed file.
+var fileMagic = []byte("GSENC1\x00")
+
+// linePrefix starts every sealed log line.
+const linePrefix = "gsenc1:"
+
+// ErrNoKey is returned when encrypted data is read without a configured key.
+var ErrNoKey = errors.New("data is encrypted but no encryption key is configured")
+
+// Cipher seals and opens data with AES-256-GCM.
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher creates a cipher for a 32-byte key.
+func NewCipher(key []byte) (*Cipher, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes, got %d", KeySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cipher{aead: aead}, nil
+}
+
+// ParseKey decodes a key given as base64 (standard or URL encoding) or as 64 hex characters.
+func ParseKey(value string) ([]byte, error) {
+	value = strings.TrimSpace(value)
+
+	if len(value) == 2*KeySize {
+		if key, err := hex.DecodeString(value); err == nil {
+			return key, nil
+		}
+	}
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		if key, err := encoding.DecodeString(value); err == nil && len(key) == KeySize {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("encryption key must be %d bytes encoded as base64 or hex", KeySize)
+}
+
+// LoadCipher creates a cipher from a key given directly or read from keyFile (e.g. a secret
+// mounted by a KMS or secret manager). It returns nil if neither is set.
+func LoadCipher(key, keyFile string) (*Cipher, error) {
+	if key != "" && keyFile != "" {
+		return nil, fmt.Errorf("encryption key and key file are mutually exclusive")
+	}
+	if keyFile != "" {
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read encryption key file: %w", err)
+		}
+		key = string(data)
+	}
+	if key == "" {
+		return nil, nil
+	}
+
+	raw, err := ParseKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return NewCipher(raw)
+}
+
+// IsSealed reports whether data is a sealed file.
+func IsSealed(data []byte) bool {
+	return bytes.HasPrefix(data, fileMagic)
+}
+
+// Seal encrypts file contents. With a nil cipher, plaintext is returned unchanged.
+func (c *Cipher) Seal(plaintext []byte) ([]byte, error) {
+	if c == nil {
+		return plaintext, nil
+	}
+
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := make([]byte, 0, len(fileMagic)+len(nonce)+len(plaintext)+c.aead.Overhead())
+	sealed = append(sealed, fileMagic...)
+	sealed = append(sealed, nonce...)
+	return c.aead.Seal(sealed, nonce, plaintext, fileMagic), nil
+}
+
+// Open decrypts file contents sealed by Seal. Plaintext data is returned unchanged.
+func (c *Cipher) Open(data []byte) ([]byte, error) {
+	if !IsSealed(data) {
+		return data, nil
+	}
+	if c == nil {
+		return nil, ErrNoKey
+	}
+
+	data = data[len(fileMagic):]
+	if len(data) < c.aead.NonceSize() {
+		return nil, fmt.Errorf("encrypted data is truncated")
+	}
+	nonce, ciphertext := data[:c.aead.NonceSize()], data[c.aead.NonceSize():]
+
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext, fileMagic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data (wrong key?): %w", err)
+	}
+	return plaintext, nil
+}
+
+// SealLine encrypts a single log line. With a nil cipher, the line is returned unchanged.
+func (c *Cipher) SealLine(line string) (string, error) {
+	if c == nil {
+		return line, nil
+	}
+
+	sealed, err := c.Seal([]byte(line))
+	if err != nil {
+		return "", err
+	}
+	return linePrefix + base64.RawStdEncoding.EncodeToString(sealed[len(fileMagic):]), nil
+}
+
+// OpenLine decrypts a log line sealed by SealLine. Plaintext lines are returned unchanged.
+func (c *Cipher) OpenLine(line string) (string, error) {
+	encoded, ok := strings.CutPrefix(line, linePrefix)
+	if !ok {
+		return line, nil
+	}
+	if c == nil {
+		return "", ErrNoKey
+	}
+
+	data, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("malformed encrypted log line: %w", err)
+	}
+	plaintext, err := c.Open(append(append([]byte{}, fileMagic...), data...))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
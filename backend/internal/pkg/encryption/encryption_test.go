@@ -0,0 +1,125 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package encryption
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=" // base64 of "0123456789abcdef0123456789abcdef"
+
+func newTestCipher(t *testing.T) *Cipher {
+	t.Helper()
+	c, err := LoadCipher(testKey, "")
+	if err != nil {
+		t.Fatalf("LoadCipher() error = %v", err)
+	}
+	return c
+}
+
+func TestSealOpen(t *testing.T) {
+	c := newTestCipher(t)
+	plaintext := []byte(`{"ref":"refs/heads/main"}`)
+
+	sealed, err := c.Seal(plaintext)
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	if !IsSealed(sealed) || bytes.Contains(sealed, plaintext) {
+		t.Fatalf("Seal() did not encrypt the data")
+	}
+
+	opened, err := c.Open(sealed)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if !bytes.Equal(opened, plaintext) {
+		t.Errorf("Open() = %q, want %q", opened, plaintext)
+	}
+
+	// Plaintext written before encryption was enabled is passed through
+	if opened, err := c.Open(plaintext); err != nil || !bytes.Equal(opened, plaintext) {
+		t.Errorf("Open(plaintext) = %q, %v", opened, err)
+	}
+
+	// Tampering is detected
+	sealed[len(sealed)-1] ^= 0xff
+	if _, err := c.Open(sealed); err == nil {
+		t.Errorf("Open() expected error for tampered data")
+	}
+}
+
+func TestSealOpenLine(t *testing.T) {
+	c := newTestCipher(t)
+	line := "[2025-10-01 12:00:00] [stdout] forwarded event"
+
+	sealed, err := c.SealLine(line)
+	if err != nil {
+		t.Fatalf("SealLine() error = %v", err)
+	}
+	if strings.ContainsAny(sealed, "\n\r") || strings.Contains(sealed, "forwarded") {
+		t.Fatalf("SealLine() = %q, want a single encrypted line", sealed)
+	}
+
+	opened, err := c.OpenLine(sealed)
+	if err != nil || opened != line {
+		t.Errorf("OpenLine() = %q, %v; want %q", opened, err, line)
+	}
+	if opened, err := c.OpenLine(line); err != nil || opened != line {
+		t.Errorf("OpenLine(plaintext) = %q, %v", opened, err)
+	}
+}
+
+func TestNilCipher(t *testing.T) {
+	var c *Cipher
+	data := []byte("plain")
+
+	if sealed, _ := c.Seal(data); !bytes.Equal(sealed, data) {
+		t.Errorf("nil Seal() = %q, want unchanged", sealed)
+	}
+
+	sealed, _ := newTestCipher(t).Seal(data)
+	if _, err := c.Open(sealed); !errors.Is(err, ErrNoKey) {
+		t.Errorf("nil Open(sealed) error = %v, want ErrNoKey", err)
+	}
+}
+
+func TestLoadCipher(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(keyFile, []byte(strings.Repeat("ab", KeySize)+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		key     string
+		keyFile string
+		wantNil bool
+		wantErr bool
+	}{
+		{"disabled", "", "", true, false},
+		{"base64 key", testKey, "", false, false},
+		{"hex key file", "", keyFile, false, false},
+		{"short key", "c2hvcnQ=", "", false, true},
+		{"both", testKey, keyFile, false, true},
+		{"missing file", "", keyFile + ".missing", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := LoadCipher(tt.key, tt.keyFile)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadCipher() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (c == nil) != tt.wantNil {
+				t.Errorf("LoadCipher() = %v, wantNil %v", c, tt.wantNil)
+			}
+		})
+	}
+}
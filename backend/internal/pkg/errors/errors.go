@@ -12,12 +12,49 @@ import (
 // AppError represents an application error with HTTP status code and error code.
 // It implements the error interface and supports error wrapping (Go 1.13+).
 type AppError struct {
-	Code       string `json:"code"`    // Error code (e.g., "TASK_NOT_FOUND")
-	Message    string `json:"message"` // Human-readable error message
-	StatusCode int    `json:"-"`       // HTTP status code (not serialized)
-	Err        error  `json:"-"`       // Wrapped error (not serialized)
+	Code       string      `json:"code"`              // Error code (e.g., "TASK_NOT_FOUND")
+	Message    string      `json:"message"`           // Human-readable error message
+	Details    interface{} `json:"details,omitempty"` // Optional structured details
+	StatusCode int         `json:"-"`                 // HTTP status code (not serialized)
+	Err        error       `json:"-"`                 // Wrapped error (not serialized)
 }
 
+// Machine-readable error codes returned in API error responses.
+const (
+	CodeInvalidInput         = "INVALID_INPUT"          // Malformed or invalid request parameters
+	CodeRequestTooLarge      = "REQUEST_TOO_LARGE"      // Request body exceeds the configured limit
+	CodeRateLimited          = "RATE_LIMITED"           // Event ingestion rate limit reached
+	CodeInternal             = "INTERNAL_ERROR"         // Unexpected server-side failure
+	CodeUnauthorized         = "UNAUTHORIZED"           // Missing or expired session
+	CodeAuthFailed           = "AUTH_FAILED"            // OIDC login flow failed
+	CodeOIDCDisabled         = "OIDC_DISABLED"          // OIDC authentication is not enabled
+	CodeNotOwner             = "NOT_OWNER"              // Resource belongs to another user
+	CodeInsufficientScope    = "INSUFFICIENT_SCOPE"     // Service account token lacks the required scope
+	CodeAdminRequired        = "ADMIN_REQUIRED"         // Operation restricted to administrators
+	CodeAccessDenied         = "ACCESS_DENIED"          // Route group not allowed for the caller's roles
+	CodeQuotaExceeded        = "QUOTA_EXCEEDED"         // Client count or storage quota reached
+	CodeClientNotFound       = "CLIENT_NOT_FOUND"       // Client does not exist
+	CodeClientRunning        = "CLIENT_RUNNING"         // Operation not allowed while the client is running
+	CodeClientNotRunning     = "CLIENT_NOT_RUNNING"     // Operation requires a running client
+	CodeInvalidTransition    = "INVALID_TRANSITION"     // Client status cannot change as requested
+	CodeClientConflict       = "CLIENT_CONFLICT"        // Client was changed since the caller read it
+	CodeClientPaused         = "CLIENT_PAUSED"          // Forwarding of the client is paused
+	CodeCapacityReached      = "CAPACITY_REACHED"       // Server-wide limit of running clients reached
+	CodeEventNotFound        = "EVENT_NOT_FOUND"        // Event does not exist
+	CodeEventConflict        = "EVENT_CONFLICT"         // Event was changed since the client read it
+	CodeJobNotFound          = "JOB_NOT_FOUND"          // Job does not exist
+	CodeNotificationNotFound = "NOTIFICATION_NOT_FOUND" // Notification does not exist
+
+	CodeServiceAccountNotFound = "SERVICE_ACCOUNT_NOT_FOUND" // Service account does not exist
+	CodeSessionNotFound        = "SESSION_NOT_FOUND"         // Session does not exist or belongs to another user
+	CodeOrphanNotFound         = "ORPHAN_NOT_FOUND"          // No orphaned data in the client directory
+	CodeLastRunNotFound        = "LAST_RUN_NOT_FOUND"        // No recently stopped process of the client
+	CodeReindexRunning         = "REINDEX_RUNNING"           // An index rebuild is already running
+	CodeAnnotationNotFound     = "ANNOTATION_NOT_FOUND"      // Log annotation does not exist
+	CodeUserNotFound           = "USER_NOT_FOUND"            // No data, session or service account of the user
+	CodeNotFound               = "NOT_FOUND"                 // No API endpoint matches the request
+)
+
 // Error returns the error message string.
 // Implements the error interface.
 func (e *AppError) Error() string {
@@ -27,6 +64,13 @@ func (e *AppError) Error() string {
 	return e.Message
 }
 
+// WithDetails returns a copy of the error carrying structured details.
+func (e *AppError) WithDetails(details interface{}) *AppError {
+	withDetails := *e
+	withDetails.Details = details
+	return &withDetails
+}
+
 // Unwrap returns the wrapped error.
 // Enables Go 1.13+ error unwrapping with errors.Is() and errors.As().
 func (e *AppError) Unwrap() error {
@@ -58,6 +102,30 @@ var (
 	ErrInvalidInput  = New("INVALID_INPUT", "Invalid input parameters", http.StatusBadRequest)
 	ErrInternal      = New("INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
 	ErrCommandFailed = New("COMMAND_FAILED", "Command execution failed", http.StatusInternalServerError)
+
+	ErrUnauthorized         = New(CodeUnauthorized, "Authentication required", http.StatusUnauthorized)
+	ErrNotOwner             = New(CodeNotOwner, "Client belongs to another user", http.StatusForbidden)
+	ErrClientNotFound       = New(CodeClientNotFound, "Client not found", http.StatusNotFound)
+	ErrClientConflict       = New(CodeClientConflict, "Client was changed since it was read", http.StatusConflict)
+	ErrClientPaused         = New(CodeClientPaused, "Forwarding of the client is paused", http.StatusConflict)
+	ErrEventNotFound        = New(CodeEventNotFound, "Event not found", http.StatusNotFound)
+	ErrEventConflict        = New(CodeEventConflict, "Event was changed since it was read", http.StatusConflict)
+	ErrJobNotFound          = New(CodeJobNotFound, "Job not found", http.StatusNotFound)
+	ErrNotificationNotFound = New(CodeNotificationNotFound, "Notification not found", http.StatusNotFound)
+	ErrInsufficientScope    = New(CodeInsufficientScope, "Token lacks the required scope", http.StatusForbidden)
+	ErrAdminRequired        = New(CodeAdminRequired, "Administrator privileges required", http.StatusForbidden)
+	ErrAccessDenied         = New(CodeAccessDenied, "Access denied by the authorization policy", http.StatusForbidden)
+	ErrOIDCDisabled         = New(CodeOIDCDisabled, "OIDC authentication is not enabled", http.StatusServiceUnavailable)
+	ErrRequestTooLarge      = New(CodeRequestTooLarge, "Request body too large", http.StatusRequestEntityTooLarge)
+
+	ErrServiceAccountNotFound = New(CodeServiceAccountNotFound, "Service account not found", http.StatusNotFound)
+	ErrSessionNotFound        = New(CodeSessionNotFound, "Session not found", http.StatusNotFound)
+	ErrOrphanNotFound         = New(CodeOrphanNotFound, "Orphaned client data not found", http.StatusNotFound)
+	ErrLastRunNotFound        = New(CodeLastRunNotFound, "No recently stopped process", http.StatusNotFound)
+	ErrReindexRunning         = New(CodeReindexRunning, "An index rebuild is already running", http.StatusConflict)
+	ErrAnnotationNotFound     = New(CodeAnnotationNotFound, "Log annotation not found", http.StatusNotFound)
+	ErrUserNotFound           = New(CodeUserNotFound, "User not found", http.StatusNotFound)
+	ErrNotFound               = New(CodeNotFound, "API endpoint not found", http.StatusNotFound)
 )
 
 // WrapTaskNotFound wraps an error as a task not found error (404).
@@ -84,3 +152,35 @@ func WrapInternal(err error, message string) *AppError {
 func WrapCommandFailed(err error, message string) *AppError {
 	return Wrap(err, "COMMAND_FAILED", message, http.StatusInternalServerError)
 }
+
+// NewQuotaExceeded creates a quota exceeded error (403).
+func NewQuotaExceeded(message string) *AppError {
+	return New(CodeQuotaExceeded, message, http.StatusForbidden)
+}
+
+// NewClientRunning creates an error for operations rejected while a client is running (409).
+func NewClientRunning(message string) *AppError {
+	return New(CodeClientRunning, message, http.StatusConflict)
+}
+
+// NewClientNotRunning creates an error for operations that require a running client (409).
+func NewClientNotRunning(message string) *AppError {
+	return New(CodeClientNotRunning, message, http.StatusConflict)
+}
+
+// NewInvalidTransition creates an error for client status changes the state machine does not
+// allow (409).
+func NewInvalidTransition(message string) *AppError {
+	return New(CodeInvalidTransition, message, http.StatusConflict)
+}
+
+// NewCapacityReached creates an error for starts rejected because the server runs the
+// maximum number of clients (503).
+func NewCapacityReached(message string) *AppError {
+	return New(CodeCapacityReached, message, http.StatusServiceUnavailable)
+}
+
+// NewAuthFailed creates an OIDC login failure error (400 or 500 depending on the cause).
+func NewAuthFailed(message string, statusCode int) *AppError {
+	return New(CodeAuthFailed, message, statusCode)
+}
@@ -5,6 +5,7 @@ package errors
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"testing"
 )
@@ -194,3 +195,30 @@ func TestNewInvalidInput(t *testing.T) {
 		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, err.StatusCode)
 	}
 }
+
+func TestAppError_WithDetails(t *testing.T) {
+	details := map[string]string{"field": "name"}
+	err := ErrClientNotFound.WithDetails(details)
+
+	if err.Details == nil {
+		t.Fatal("Expected details to be set")
+	}
+	if ErrClientNotFound.Details != nil {
+		t.Error("Expected the predefined error to be left unchanged")
+	}
+	if err.Code != CodeClientNotFound || err.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected code and status to be kept, got %s/%d", err.Code, err.StatusCode)
+	}
+}
+
+func TestAppError_AsThroughWrapping(t *testing.T) {
+	wrapped := fmt.Errorf("failed to start client: %w", NewClientRunning("client already running"))
+
+	var appErr *AppError
+	if !errors.As(wrapped, &appErr) {
+		t.Fatal("Expected errors.As to find the AppError")
+	}
+	if appErr.Code != CodeClientRunning || appErr.StatusCode != http.StatusConflict {
+		t.Errorf("Expected CLIENT_RUNNING/409, got %s/%d", appErr.Code, appErr.StatusCode)
+	}
+}
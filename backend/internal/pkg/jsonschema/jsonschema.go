@@ -0,0 +1,682 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package jsonschema validates JSON documents against JSON Schemas. The supported subset
+// covers the structural keywords of draft 2020-12 (and draft-07): type, enum, const,
+// properties, required, additionalProperties, patternProperties, items, the size and range
+// keywords, pattern, allOf/anyOf/oneOf/not and local references ($ref to #/$defs/... or
+// #/definitions/...). Annotations such as format, title and description are ignored.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MaxErrors is the maximum number of errors Validate reports for a document.
+const MaxErrors = 20
+
+// Schema is a compiled JSON Schema.
+type Schema struct {
+	root *node
+}
+
+// node is a compiled schema or subschema.
+type node struct {
+	always *bool // Boolean schema: true accepts and false rejects every value
+
+	types   []string
+	enum    []interface{}
+	konst   *interface{}
+	ref     string
+	pattern *regexp.Regexp
+
+	properties           map[string]*node
+	patternProperties    map[*regexp.Regexp]*node
+	additionalProperties *node
+	required             []string
+	minProperties        *int
+	maxProperties        *int
+
+	items       *node
+	minItems    *int
+	maxItems    *int
+	uniqueItems bool
+
+	minLength *int
+	maxLength *int
+
+	minimum          *float64
+	maximum          *float64
+	exclusiveMinimum *float64
+	exclusiveMaximum *float64
+	multipleOf       *float64
+
+	allOf []*node
+	anyOf []*node
+	oneOf []*node
+	not   *node
+}
+
+// compiler compiles a schema document, resolving local references.
+type compiler struct {
+	doc  interface{}
+	refs map[string]*node
+}
+
+// Compile compiles a JSON Schema document.
+func Compile(data []byte) (*Schema, error) {
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+
+	c := &compiler{doc: doc, refs: make(map[string]*node)}
+	root, err := c.compile(doc, "#")
+	if err != nil {
+		return nil, err
+	}
+	// Compile referenced subschemas, which may reference further ones
+	for {
+		pending := false
+		for ref, n := range c.refs {
+			if n != nil {
+				continue
+			}
+			pending = true
+			target, err := c.resolve(ref)
+			if err != nil {
+				return nil, err
+			}
+			c.refs[ref] = &node{} // Guards against self references while compiling
+			compiled, err := c.compile(target, ref)
+			if err != nil {
+				return nil, err
+			}
+			c.refs[ref] = compiled
+		}
+		if !pending {
+			break
+		}
+	}
+	linkRefs(root, c.refs, make(map[*node]bool))
+
+	return &Schema{root: root}, nil
+}
+
+// compile compiles a (sub)schema at location, a JSON Pointer fragment used in errors.
+func (c *compiler) compile(v interface{}, location string) (*node, error) {
+	if b, ok := v.(bool); ok {
+		return &node{always: &b}, nil
+	}
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: schema must be an object or a boolean", location)
+	}
+
+	n := &node{}
+	var err error
+	for keyword, value := range obj {
+		at := location + "/" + keyword
+		switch keyword {
+		case "type":
+			n.types, err = typeNames(value, at)
+		case "enum":
+			values, ok := value.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%s: must be an array", at)
+			}
+			n.enum = values
+		case "const":
+			n.konst = &value
+		case "$ref":
+			ref, ok := value.(string)
+			if !ok || !strings.HasPrefix(ref, "#") {
+				return nil, fmt.Errorf("%s: only local references (#/...) are supported", at)
+			}
+			n.ref = ref
+			if _, exists := c.refs[ref]; !exists {
+				c.refs[ref] = nil
+			}
+		case "pattern":
+			n.pattern, err = compilePattern(value, at)
+		case "properties", "$defs", "definitions", "patternProperties":
+			props, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%s: must be an object", at)
+			}
+			switch keyword {
+			case "properties":
+				n.properties = make(map[string]*node, len(props))
+				for name, sub := range props {
+					if n.properties[name], err = c.compile(sub, at+"/"+escapePointer(name)); err != nil {
+						return nil, err
+					}
+				}
+			case "patternProperties":
+				n.patternProperties = make(map[*regexp.Regexp]*node, len(props))
+				for expr, sub := range props {
+					re, err := compilePattern(expr, at)
+					if err != nil {
+						return nil, err
+					}
+					if n.patternProperties[re], err = c.compile(sub, at+"/"+escapePointer(expr)); err != nil {
+						return nil, err
+					}
+				}
+			}
+		case "additionalProperties":
+			n.additionalProperties, err = c.compile(value, at)
+		case "required":
+			values, ok := value.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%s: must be an array of strings", at)
+			}
+			for _, value := range values {
+				name, ok := value.(string)
+				if !ok {
+					return nil, fmt.Errorf("%s: must be an array of strings", at)
+				}
+				n.required = append(n.required, name)
+			}
+		case "items":
+			n.items, err = c.compile(value, at)
+		case "uniqueItems":
+			n.uniqueItems, _ = value.(bool)
+		case "minProperties":
+			n.minProperties, err = count(value, at)
+		case "maxProperties":
+			n.maxProperties, err = count(value, at)
+		case "minItems":
+			n.minItems, err = count(value, at)
+		case "maxItems":
+			n.maxItems, err = count(value, at)
+		case "minLength":
+			n.minLength, err = count(value, at)
+		case "maxLength":
+			n.maxLength, err = count(value, at)
+		case "minimum":
+			n.minimum, err = number(value, at)
+		case "maximum":
+			n.maximum, err = number(value, at)
+		case "exclusiveMinimum":
+			n.exclusiveMinimum, err = number(value, at)
+		case "exclusiveMaximum":
+			n.exclusiveMaximum, err = number(value, at)
+		case "multipleOf":
+			if n.multipleOf, err = number(value, at); err == nil && *n.multipleOf <= 0 {
+				err = fmt.Errorf("%s: must be greater than 0", at)
+			}
+		case "allOf", "anyOf", "oneOf":
+			values, ok := value.([]interface{})
+			if !ok || len(values) == 0 {
+				return nil, fmt.Errorf("%s: must be a non-empty array of schemas", at)
+			}
+			subs := make([]*node, len(values))
+			for i, sub := range values {
+				if subs[i], err = c.compile(sub, fmt.Sprintf("%s/%d", at, i)); err != nil {
+					return nil, err
+				}
+			}
+			switch keyword {
+			case "allOf":
+				n.allOf = subs
+			case "anyOf":
+				n.anyOf = subs
+			case "oneOf":
+				n.oneOf = subs
+			}
+		case "not":
+			n.not, err = c.compile(value, at)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return n, nil
+}
+
+// resolve returns the subschema a local reference points to.
+func (c *compiler) resolve(ref string) (interface{}, error) {
+	target := c.doc
+	pointer := strings.TrimPrefix(ref, "#")
+	if pointer == "" {
+		return target, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("unsupported reference %q: only JSON Pointers are supported", ref)
+	}
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch value := target.(type) {
+		case map[string]interface{}:
+			next, ok := value[token]
+			if !ok {
+				return nil, fmt.Errorf("unresolved reference %q", ref)
+			}
+			target = next
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(value) {
+				return nil, fmt.Errorf("unresolved reference %q", ref)
+			}
+			target = value[i]
+		default:
+			return nil, fmt.Errorf("unresolved reference %q", ref)
+		}
+	}
+	return target, nil
+}
+
+// linkRefs replaces the references of compiled nodes with the nodes they point to.
+func linkRefs(n *node, refs map[string]*node, visited map[*node]bool) {
+	if n == nil || visited[n] {
+		return
+	}
+	visited[n] = true
+
+	children := []*node{n.additionalProperties, n.items, n.not}
+	for _, sub := range n.properties {
+		children = append(children, sub)
+	}
+	for _, sub := range n.patternProperties {
+		children = append(children, sub)
+	}
+	children = append(children, n.allOf...)
+	children = append(children, n.anyOf...)
+	children = append(children, n.oneOf...)
+	if n.ref != "" {
+		target := refs[n.ref]
+		n.allOf = append(n.allOf, target)
+		children = append(children, target)
+		n.ref = ""
+	}
+	for _, child := range children {
+		linkRefs(child, refs, visited)
+	}
+}
+
+// Error is a validation error at a location of the validated document.
+type Error struct {
+	Path    string // JSONPath of the invalid value, e.g. $.commits[0].id
+	Message string
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// Validate validates a JSON document against the schema, returning at most MaxErrors
+// errors. Documents that aren't valid JSON fail with a single error.
+func (s *Schema) Validate(data []byte) []*Error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return []*Error{{Path: "$", Message: "payload is not valid JSON"}}
+	}
+
+	v := &validation{}
+	v.validate(s.root, normalize(doc), "$", 0)
+	return v.errors
+}
+
+// maxDepth bounds the recursion of validation, e.g. for recursive references.
+const maxDepth = 64
+
+// validation collects the errors of validating a document.
+type validation struct {
+	errors []*Error
+}
+
+func (v *validation) fail(path, format string, args ...interface{}) {
+	if len(v.errors) < MaxErrors {
+		v.errors = append(v.errors, &Error{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+}
+
+// valid reports whether value is valid against n, without recording errors.
+func (v *validation) valid(n *node, value interface{}, path string, depth int) bool {
+	sub := &validation{}
+	sub.validate(n, value, path, depth)
+	return len(sub.errors) == 0
+}
+
+func (v *validation) validate(n *node, value interface{}, path string, depth int) {
+	if depth > maxDepth {
+		v.fail(path, "schema nesting is too deep")
+		return
+	}
+	if n.always != nil {
+		if !*n.always {
+			v.fail(path, "no value is allowed here")
+		}
+		return
+	}
+
+	if len(n.types) > 0 && !matchesType(n.types, value) {
+		v.fail(path, "expected %s, got %s", strings.Join(n.types, " or "), typeOf(value))
+		return
+	}
+	if n.konst != nil && !equal(value, normalize(*n.konst)) {
+		v.fail(path, "must be %s", encode(*n.konst))
+	}
+	if n.enum != nil {
+		found := false
+		for _, allowed := range n.enum {
+			if equal(value, normalize(allowed)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.fail(path, "must be one of %s", encode(n.enum))
+		}
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		v.validateObject(n, value, path, depth)
+	case []interface{}:
+		v.validateArray(n, value, path, depth)
+	case string:
+		length := len([]rune(value))
+		if n.minLength != nil && length < *n.minLength {
+			v.fail(path, "must be at least %d characters long", *n.minLength)
+		}
+		if n.maxLength != nil && length > *n.maxLength {
+			v.fail(path, "must be at most %d characters long", *n.maxLength)
+		}
+		if n.pattern != nil && !n.pattern.MatchString(value) {
+			v.fail(path, "must match pattern %q", n.pattern.String())
+		}
+	case float64:
+		v.validateNumber(n, value, path)
+	}
+
+	for _, sub := range n.allOf {
+		v.validate(sub, value, path, depth+1)
+	}
+	if len(n.anyOf) > 0 {
+		matched := false
+		for _, sub := range n.anyOf {
+			if v.valid(sub, value, path, depth+1) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.fail(path, "must match at least one schema of anyOf")
+		}
+	}
+	if len(n.oneOf) > 0 {
+		matched := 0
+		for _, sub := range n.oneOf {
+			if v.valid(sub, value, path, depth+1) {
+				matched++
+			}
+		}
+		if matched != 1 {
+			v.fail(path, "must match exactly one schema of oneOf, matched %d", matched)
+		}
+	}
+	if n.not != nil && v.valid(n.not, value, path, depth+1) {
+		v.fail(path, "must not match the schema of not")
+	}
+}
+
+func (v *validation) validateObject(n *node, obj map[string]interface{}, path string, depth int) {
+	for _, name := range n.required {
+		if _, ok := obj[name]; !ok {
+			v.fail(path, "missing required property %q", name)
+		}
+	}
+	if n.minProperties != nil && len(obj) < *n.minProperties {
+		v.fail(path, "must have at least %d properties", *n.minProperties)
+	}
+	if n.maxProperties != nil && len(obj) > *n.maxProperties {
+		v.fail(path, "must have at most %d properties", *n.maxProperties)
+	}
+
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names) // Report errors in a stable order
+	for _, name := range names {
+		at := childPath(path, name)
+		matched := false
+		if sub, ok := n.properties[name]; ok {
+			matched = true
+			v.validate(sub, obj[name], at, depth+1)
+		}
+		for re, sub := range n.patternProperties {
+			if re.MatchString(name) {
+				matched = true
+				v.validate(sub, obj[name], at, depth+1)
+			}
+		}
+		if !matched && n.additionalProperties != nil {
+			if n.additionalProperties.always != nil && !*n.additionalProperties.always {
+				v.fail(path, "property %q is not allowed", name)
+				continue
+			}
+			v.validate(n.additionalProperties, obj[name], at, depth+1)
+		}
+	}
+}
+
+func (v *validation) validateArray(n *node, items []interface{}, path string, depth int) {
+	if n.minItems != nil && len(items) < *n.minItems {
+		v.fail(path, "must have at least %d items", *n.minItems)
+	}
+	if n.maxItems != nil && len(items) > *n.maxItems {
+		v.fail(path, "must have at most %d items", *n.maxItems)
+	}
+	if n.uniqueItems {
+	unique:
+		for i := range items {
+			for j := i + 1; j < len(items); j++ {
+				if equal(items[i], items[j]) {
+					v.fail(path, "items %d and %d are equal", i, j)
+					break unique
+				}
+			}
+		}
+	}
+	if n.items != nil {
+		for i, item := range items {
+			v.validate(n.items, item, fmt.Sprintf("%s[%d]", path, i), depth+1)
+		}
+	}
+}
+
+func (v *validation) validateNumber(n *node, value float64, path string) {
+	if n.minimum != nil && value < *n.minimum {
+		v.fail(path, "must be at least %v", *n.minimum)
+	}
+	if n.maximum != nil && value > *n.maximum {
+		v.fail(path, "must be at most %v", *n.maximum)
+	}
+	if n.exclusiveMinimum != nil && value <= *n.exclusiveMinimum {
+		v.fail(path, "must be greater than %v", *n.exclusiveMinimum)
+	}
+	if n.exclusiveMaximum != nil && value >= *n.exclusiveMaximum {
+		v.fail(path, "must be less than %v", *n.exclusiveMaximum)
+	}
+	if n.multipleOf != nil {
+		quotient := value / *n.multipleOf
+		if math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+			v.fail(path, "must be a multiple of %v", *n.multipleOf)
+		}
+	}
+}
+
+// typeNames returns the type names of the type keyword.
+func typeNames(value interface{}, at string) ([]string, error) {
+	var names []string
+	switch value := value.(type) {
+	case string:
+		names = []string{value}
+	case []interface{}:
+		for _, name := range value {
+			s, ok := name.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s: must be a string or an array of strings", at)
+			}
+			names = append(names, s)
+		}
+	default:
+		return nil, fmt.Errorf("%s: must be a string or an array of strings", at)
+	}
+	for _, name := range names {
+		switch name {
+		case "null", "boolean", "object", "array", "number", "integer", "string":
+		default:
+			return nil, fmt.Errorf("%s: unknown type %q", at, name)
+		}
+	}
+	return names, nil
+}
+
+// matchesType reports whether a value has one of the types.
+func matchesType(types []string, value interface{}) bool {
+	actual := typeOf(value)
+	for _, name := range types {
+		if name == actual || (name == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// typeOf returns the JSON Schema type of a decoded value.
+func typeOf(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case float64:
+		if value == math.Trunc(value) && !math.IsInf(value, 0) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	}
+	return "unknown"
+}
+
+// normalize converts json.Number values to float64, so documents and schema values compare equal.
+func normalize(value interface{}) interface{} {
+	switch value := value.(type) {
+	case json.Number:
+		f, _ := value.Float64()
+		return f
+	case map[string]interface{}:
+		for k, item := range value {
+			value[k] = normalize(item)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = normalize(item)
+		}
+	}
+	return value
+}
+
+// equal reports whether two normalized values are equal.
+func equal(a, b interface{}) bool {
+	switch a := a.(type) {
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, item := range a {
+			other, ok := b[k]
+			if !ok || !equal(item, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equal(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
+
+// count parses a non-negative integer keyword value.
+func count(value interface{}, at string) (*int, error) {
+	f, ok := value.(float64)
+	if !ok || f < 0 || f != math.Trunc(f) {
+		return nil, fmt.Errorf("%s: must be a non-negative integer", at)
+	}
+	n := int(f)
+	return &n, nil
+}
+
+// number parses a numeric keyword value.
+func number(value interface{}, at string) (*float64, error) {
+	f, ok := value.(float64)
+	if !ok {
+		return nil, fmt.Errorf("%s: must be a number", at)
+	}
+	return &f, nil
+}
+
+// compilePattern compiles a regular expression keyword value.
+func compilePattern(value interface{}, at string) (*regexp.Regexp, error) {
+	expr, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("%s: must be a string", at)
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid regular expression %q: %v", at, expr, err)
+	}
+	return re, nil
+}
+
+// childPath returns the JSONPath of a property.
+func childPath(path, name string) string {
+	for _, r := range name {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return path + "[" + strconv.Quote(name) + "]"
+		}
+	}
+	return path + "." + name
+}
+
+// escapePointer escapes a JSON Pointer token.
+func escapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// encode returns the JSON encoding of a schema value for error messages.
+func encode(value interface{}) string {
+	data, _ := json.Marshal(value)
+	return string(data)
+}
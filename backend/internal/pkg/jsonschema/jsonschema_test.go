@@ -0,0 +1,119 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package jsonschema
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	schema := `{
+		"type": "object",
+		"required": ["action", "repository"],
+		"properties": {
+			"action": {"enum": ["opened", "closed"]},
+			"number": {"type": "integer", "minimum": 1},
+			"repository": {"$ref": "#/$defs/repository"},
+			"labels": {"type": "array", "items": {"type": "string", "minLength": 1}, "uniqueItems": true}
+		},
+		"$defs": {
+			"repository": {
+				"type": "object",
+				"required": ["full_name"],
+				"properties": {"full_name": {"type": "string", "pattern": "^[^/]+/[^/]+$"}},
+				"additionalProperties": false
+			}
+		}
+	}`
+
+	tests := []struct {
+		name    string
+		payload string
+		want    []string
+	}{
+		{"valid", `{"action":"opened","number":3,"repository":{"full_name":"org/repo"},"labels":["bug"]}`, nil},
+		{"missing required", `{"action":"opened"}`, []string{`$: missing required property "repository"`}},
+		{"enum", `{"action":"edited","repository":{"full_name":"org/repo"}}`, []string{`$.action: must be one of ["opened","closed"]`}},
+		{"integer", `{"action":"opened","number":1.5,"repository":{"full_name":"org/repo"}}`, []string{`$.number: expected integer, got number`}},
+		{"reference", `{"action":"opened","repository":{"full_name":"repo","private":true}}`, []string{
+			`$.repository.full_name: must match pattern "^[^/]+/[^/]+$"`,
+			`$.repository: property "private" is not allowed`,
+		}},
+		{"items", `{"action":"opened","repository":{"full_name":"org/repo"},"labels":["a","a",""]}`, []string{
+			`$.labels: items 0 and 1 are equal`,
+			`$.labels[2]: must be at least 1 characters long`,
+		}},
+		{"not JSON", `action=opened`, []string{`$: payload is not valid JSON`}},
+	}
+
+	compiled, err := Compile([]byte(schema))
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, err := range compiled.Validate([]byte(tt.payload)) {
+				got = append(got, err.Error())
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Validate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateCombinators(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		payload string
+		valid   bool
+	}{
+		{"anyOf match", `{"anyOf":[{"type":"string"},{"type":"number"}]}`, `1`, true},
+		{"anyOf mismatch", `{"anyOf":[{"type":"string"},{"type":"number"}]}`, `true`, false},
+		{"oneOf ambiguous", `{"oneOf":[{"type":"number"},{"type":"integer"}]}`, `1`, false},
+		{"not", `{"not":{"const":"ping"}}`, `"ping"`, false},
+		{"false schema", `false`, `{}`, false},
+		{"multipleOf", `{"multipleOf":0.01}`, `12.34`, true},
+		{"recursive reference", `{"type":"object","properties":{"children":{"type":"array","items":{"$ref":"#"}}}}`, `{"children":[{"children":[{"children":"x"}]}]}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compiled, err := Compile([]byte(tt.schema))
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			if errs := compiled.Validate([]byte(tt.payload)); (len(errs) == 0) != tt.valid {
+				t.Errorf("Validate() = %v, want valid %v", errs, tt.valid)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   string
+	}{
+		{"not JSON", `{`, "invalid JSON"},
+		{"not a schema", `[]`, "schema must be an object or a boolean"},
+		{"unknown type", `{"type":"text"}`, `#/type: unknown type "text"`},
+		{"bad pattern", `{"pattern":"("}`, "#/pattern: invalid regular expression"},
+		{"remote reference", `{"$ref":"https://example.com/schema.json"}`, "only local references"},
+		{"unresolved reference", `{"$ref":"#/$defs/missing"}`, `unresolved reference "#/$defs/missing"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile([]byte(tt.schema))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Compile() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -48,3 +48,33 @@ func (l *StandardLogger) Error(format string, args ...interface{}) {
 func (l *StandardLogger) Debug(format string, args ...interface{}) {
 	l.debugLogger.Printf(format, args...)
 }
+
+// prefixedLogger prepends a fixed prefix to every message of the wrapped logger.
+type prefixedLogger struct {
+	base   Logger
+	prefix string
+}
+
+// WithRequestID returns a logger that tags every message with the given request ID,
+// so log lines emitted while serving a request can be correlated.
+func WithRequestID(base Logger, requestID string) Logger {
+	if requestID == "" {
+		return base
+	}
+	return &prefixedLogger{base: base, prefix: "[req:" + requestID + "] "}
+}
+
+// Info logs an informational message with the prefix.
+func (l *prefixedLogger) Info(format string, args ...interface{}) {
+	l.base.Info(l.prefix+format, args...)
+}
+
+// Error logs an error message with the prefix.
+func (l *prefixedLogger) Error(format string, args ...interface{}) {
+	l.base.Error(l.prefix+format, args...)
+}
+
+// Debug logs a debug message with the prefix.
+func (l *prefixedLogger) Debug(format string, args ...interface{}) {
+	l.base.Debug(l.prefix+format, args...)
+}
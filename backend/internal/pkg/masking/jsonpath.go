@@ -0,0 +1,223 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package masking
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// stepKind is the kind of a JSONPath step.
+type stepKind int
+
+const (
+	stepChild    stepKind = iota // .name or ['name']
+	stepIndex                    // [n], negative counts from the end
+	stepWildcard                 // .* or [*]
+)
+
+// pathStep is a single step of a JSONPath expression.
+type pathStep struct {
+	kind      stepKind
+	name      string
+	index     int
+	recursive bool // Preceded by "..": applies at any depth
+}
+
+// JSONPath is a compiled JSONPath expression. The supported subset is child access
+// ($.a.b, $['a']), array indexes ($.a[0], $.a[-1]), wildcards ($.a[*], $.a.*) and
+// recursive descent ($..password); filters and slices are not supported.
+type JSONPath struct {
+	expr  string
+	steps []pathStep
+}
+
+// ParseJSONPath compiles a JSONPath expression.
+func ParseJSONPath(expr string) (*JSONPath, error) {
+	if !strings.HasPrefix(expr, "$") {
+		return nil, fmt.Errorf("invalid JSONPath %q: must start with $", expr)
+	}
+
+	path := &JSONPath{expr: expr}
+	for i := 1; i < len(expr); {
+		recursive := false
+		switch {
+		case strings.HasPrefix(expr[i:], ".."):
+			recursive = true
+			i += 2
+		case expr[i] == '.':
+			i++
+		case expr[i] == '[':
+		default:
+			return nil, fmt.Errorf("invalid JSONPath %q: unexpected %q at offset %d", expr, expr[i], i)
+		}
+
+		var (
+			step pathStep
+			err  error
+		)
+		if i < len(expr) && expr[i] == '[' {
+			step, i, err = parseBracketStep(expr, i)
+		} else {
+			step, i, err = parseNameStep(expr, i)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if recursive && step.kind == stepIndex {
+			return nil, fmt.Errorf("invalid JSONPath %q: recursive descent into an index is not supported", expr)
+		}
+		step.recursive = recursive
+		path.steps = append(path.steps, step)
+	}
+
+	if len(path.steps) == 0 {
+		return nil, fmt.Errorf("invalid JSONPath %q: must select a field below the root", expr)
+	}
+	return path, nil
+}
+
+// parseNameStep parses a dotted name or wildcard starting at i.
+func parseNameStep(expr string, i int) (pathStep, int, error) {
+	end := i
+	for end < len(expr) && expr[end] != '.' && expr[end] != '[' {
+		end++
+	}
+	name := expr[i:end]
+	switch name {
+	case "":
+		return pathStep{}, end, fmt.Errorf("invalid JSONPath %q: empty name at offset %d", expr, i)
+	case "*":
+		return pathStep{kind: stepWildcard}, end, nil
+	default:
+		return pathStep{kind: stepChild, name: name}, end, nil
+	}
+}
+
+// parseBracketStep parses a ['name'], [n] or [*] step starting at the bracket at i.
+func parseBracketStep(expr string, i int) (pathStep, int, error) {
+	end := strings.IndexByte(expr[i:], ']')
+	if end < 0 {
+		return pathStep{}, i, fmt.Errorf("invalid JSONPath %q: unclosed bracket at offset %d", expr, i)
+	}
+	end += i
+	content := strings.TrimSpace(expr[i+1 : end])
+
+	switch {
+	case content == "*":
+		return pathStep{kind: stepWildcard}, end + 1, nil
+	case len(content) >= 2 && (content[0] == '\'' || content[0] == '"') && content[len(content)-1] == content[0]:
+		return pathStep{kind: stepChild, name: content[1 : len(content)-1]}, end + 1, nil
+	}
+
+	index, err := strconv.Atoi(content)
+	if err != nil {
+		return pathStep{}, i, fmt.Errorf("invalid JSONPath %q: unsupported selector [%s]", expr, content)
+	}
+	return pathStep{kind: stepIndex, index: index}, end + 1, nil
+}
+
+// String returns the source expression.
+func (p *JSONPath) String() string {
+	return p.expr
+}
+
+// Replace replaces every value selected by the path in doc (as decoded by encoding/json)
+// with value and returns the updated document and the number of replaced values.
+func (p *JSONPath) Replace(doc interface{}, value interface{}) (interface{}, int) {
+	return replaceSteps(doc, p.steps, value)
+}
+
+// replaceSteps applies the remaining steps to node.
+func replaceSteps(node interface{}, steps []pathStep, value interface{}) (interface{}, int) {
+	if len(steps) == 0 {
+		return value, 1
+	}
+
+	step, rest := steps[0], steps[1:]
+	count := 0
+
+	// Apply the step at this level
+	switch container := node.(type) {
+	case map[string]interface{}:
+		for key, child := range container {
+			if step.kind == stepWildcard || (step.kind == stepChild && key == step.name) {
+				var n int
+				container[key], n = replaceSteps(child, rest, value)
+				count += n
+			}
+		}
+	case []interface{}:
+		for i, child := range container {
+			if step.kind == stepWildcard || (step.kind == stepIndex && (i == step.index || i == len(container)+step.index)) {
+				var n int
+				container[i], n = replaceSteps(child, rest, value)
+				count += n
+			}
+		}
+	}
+
+	// Recursive steps also apply at every depth below
+	if step.recursive {
+		switch container := node.(type) {
+		case map[string]interface{}:
+			for key, child := range container {
+				var n int
+				container[key], n = replaceSteps(child, steps, value)
+				count += n
+			}
+		case []interface{}:
+			for i, child := range container {
+				var n int
+				container[i], n = replaceSteps(child, steps, value)
+				count += n
+			}
+		}
+	}
+
+	return node, count
+}
+
+// Redaction replaces the values selected by Path with Replacement.
+type Redaction struct {
+	Path        *JSONPath
+	Replacement string
+}
+
+// RedactJSON applies redactions to a JSON document. It returns the document unchanged
+// and a count of 0 if it is not valid JSON or nothing matched. Object keys of a
+// redacted document are re-encoded in sorted order.
+func RedactJSON(payload string, redactions []Redaction) (string, int) {
+	if len(redactions) == 0 {
+		return payload, 0
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(payload))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return payload, 0
+	}
+
+	total := 0
+	for _, redaction := range redactions {
+		var n int
+		doc, n = redaction.Path.Replace(doc, redaction.Replacement)
+		total += n
+	}
+	if total == 0 {
+		return payload, 0
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(doc); err != nil {
+		return payload, 0
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), total
+}
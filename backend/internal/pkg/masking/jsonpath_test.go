@@ -0,0 +1,64 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package masking
+
+import (
+	"testing"
+)
+
+func TestRedactJSON(t *testing.T) {
+	payload := `{"user":{"email":"a@example.com","name":"alice"},"commits":[{"author":{"email":"b@example.com"}},{"author":{"email":"c@example.com"}}],"token":"t0k","amount":12.50}`
+
+	tests := []struct {
+		name  string
+		paths []string
+		want  string
+		count int
+	}{
+		{"child", []string{"$.user.email"}, `{"amount":12.50,"commits":[{"author":{"email":"b@example.com"}},{"author":{"email":"c@example.com"}}],"token":"t0k","user":{"email":"***","name":"alice"}}`, 1},
+		{"bracket child", []string{"$['token']"}, `{"amount":12.50,"commits":[{"author":{"email":"b@example.com"}},{"author":{"email":"c@example.com"}}],"token":"***","user":{"email":"a@example.com","name":"alice"}}`, 1},
+		{"wildcard", []string{"$.commits[*].author.email"}, `{"amount":12.50,"commits":[{"author":{"email":"***"}},{"author":{"email":"***"}}],"token":"t0k","user":{"email":"a@example.com","name":"alice"}}`, 2},
+		{"negative index", []string{"$.commits[-1].author"}, `{"amount":12.50,"commits":[{"author":{"email":"b@example.com"}},{"author":"***"}],"token":"t0k","user":{"email":"a@example.com","name":"alice"}}`, 1},
+		{"recursive descent", []string{"$..email"}, `{"amount":12.50,"commits":[{"author":{"email":"***"}},{"author":{"email":"***"}}],"token":"t0k","user":{"email":"***","name":"alice"}}`, 3},
+		{"no match", []string{"$.missing", "$.user[0]"}, payload, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var redactions []Redaction
+			for _, expr := range tt.paths {
+				path, err := ParseJSONPath(expr)
+				if err != nil {
+					t.Fatalf("ParseJSONPath(%q) error = %v", expr, err)
+				}
+				redactions = append(redactions, Redaction{Path: path, Replacement: "***"})
+			}
+
+			got, count := RedactJSON(payload, redactions)
+			if got != tt.want {
+				t.Errorf("RedactJSON() = %s, want %s", got, tt.want)
+			}
+			if count != tt.count {
+				t.Errorf("RedactJSON() count = %d, want %d", count, tt.count)
+			}
+		})
+	}
+}
+
+func TestRedactJSONIgnoresNonJSON(t *testing.T) {
+	path, _ := ParseJSONPath("$.token")
+	payload := "payload=%7B%22token%22%3A%22x%22%7D"
+
+	if got, count := RedactJSON(payload, []Redaction{{Path: path, Replacement: "***"}}); got != payload || count != 0 {
+		t.Errorf("RedactJSON() = %q, %d; want input unchanged", got, count)
+	}
+}
+
+func TestParseJSONPathErrors(t *testing.T) {
+	for _, expr := range []string{"", "user.email", "$", "$.", "$.a[", "$.a[?(@.x)]", "$..[0]", "$.a..", "$a"} {
+		if _, err := ParseJSONPath(expr); err == nil {
+			t.Errorf("ParseJSONPath(%q) expected error", expr)
+		}
+	}
+}
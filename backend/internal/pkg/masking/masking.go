@@ -0,0 +1,192 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package masking redacts secrets (credentials in headers, tokens) from text shown to users.
+package masking
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces masked values.
+const Redacted = "[REDACTED]"
+
+const (
+	// Maximum number of user-defined headers and patterns
+	MaxHeaders  = 50
+	MaxPatterns = 50
+)
+
+// DefaultHeaders are header names that are always redacted (case-insensitive).
+var DefaultHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Hub-Signature",
+	"X-Hub-Signature-256",
+	"X-Gitlab-Token",
+	"X-Gitea-Signature",
+	"X-Gogs-Signature",
+	"X-Api-Key",
+}
+
+// sensitiveWords mark header and field names as sensitive when contained in them.
+var sensitiveWords = []string{"token", "secret", "signature", "password", "api-key", "apikey", "api_key"}
+
+var (
+	// Well-known credential formats, redacted wherever they appear
+	defaultPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)\b(?:bearer|basic)\s+([A-Za-z0-9._~+/=-]{8,})`),
+		regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{20,})`),
+		regexp.MustCompile(`\b(glpat-[A-Za-z0-9_-]{20,})`),
+		regexp.MustCompile(`\b(xox[abprs]-[A-Za-z0-9-]{10,})`),
+		regexp.MustCompile(`\b(gsa_[A-Za-z0-9_-]{20,})`),
+	}
+
+	// Names of "name: value" and "name=value" pairs, as found in logs, scripts and payloads
+	fieldPattern = regexp.MustCompile(`([A-Za-z][A-Za-z0-9_-]*)["']?[ \t]*[:=][ \t]*["']?`)
+)
+
+// fieldTerminators end the value of a "name: value" pair.
+const fieldTerminators = "\"'\r\n,;&}"
+
+// Masker redacts sensitive headers and values.
+type Masker struct {
+	headers  map[string]bool  // Sensitive header names, lowercased
+	patterns []*regexp.Regexp // Value patterns, including user-defined ones
+}
+
+// Default is the masker without user-defined headers and patterns.
+var Default, _ = New(nil, nil)
+
+// New creates a masker redacting the default headers and credential formats, plus
+// extraHeaders and the regular expressions in patterns. A pattern with capture groups
+// only redacts the groups, otherwise the whole match.
+func New(extraHeaders, patterns []string) (*Masker, error) {
+	if len(extraHeaders) > MaxHeaders {
+		return nil, fmt.Errorf("too many headers (max %d)", MaxHeaders)
+	}
+	if len(patterns) > MaxPatterns {
+		return nil, fmt.Errorf("too many patterns (max %d)", MaxPatterns)
+	}
+
+	m := &Masker{
+		headers:  make(map[string]bool),
+		patterns: append([]*regexp.Regexp{}, defaultPatterns...),
+	}
+	for _, header := range append(append([]string{}, DefaultHeaders...), extraHeaders...) {
+		header = strings.ToLower(strings.TrimSpace(header))
+		if header != "" {
+			m.headers[header] = true
+		}
+	}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		m.patterns = append(m.patterns, re)
+	}
+
+	return m, nil
+}
+
+// IsSensitive reports whether a header or field name holds a secret.
+func (m *Masker) IsSensitive(name string) bool {
+	name = strings.ToLower(name)
+	if m.headers[name] {
+		return true
+	}
+	for _, word := range sensitiveWords {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// MaskHeaders returns a copy of headers with sensitive values redacted.
+func (m *Masker) MaskHeaders(headers map[string]string) map[string]string {
+	if headers == nil {
+		return nil
+	}
+
+	masked := make(map[string]string, len(headers))
+	for name, value := range headers {
+		if m.IsSensitive(name) {
+			masked[name] = Redacted
+		} else {
+			masked[name] = m.maskPatterns(value)
+		}
+	}
+	return masked
+}
+
+// MaskText redacts the values of sensitive "name: value" / "name=value" pairs and
+// everything matching the masker's patterns in free text such as log lines and scripts.
+func (m *Masker) MaskText(text string) string {
+	if text == "" {
+		return text
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, loc := range fieldPattern.FindAllStringSubmatchIndex(text, -1) {
+		if loc[0] < last || !m.IsSensitive(text[loc[2]:loc[3]]) {
+			continue
+		}
+		end := loc[1] + strings.IndexAny(text[loc[1]:]+"\n", fieldTerminators)
+		if end == loc[1] {
+			continue
+		}
+		b.WriteString(text[last:loc[1]])
+		b.WriteString(Redacted)
+		last = end
+	}
+	b.WriteString(text[last:])
+
+	return m.maskPatterns(b.String())
+}
+
+// maskPatterns redacts all matches of the masker's patterns.
+func (m *Masker) maskPatterns(text string) string {
+	for _, re := range m.patterns {
+		if re.NumSubexp() == 0 {
+			text = re.ReplaceAllLiteralString(text, Redacted)
+			continue
+		}
+		groups := make([]int, re.NumSubexp())
+		for i := range groups {
+			groups[i] = i + 1
+		}
+		text = replaceGroups(re, text, groups...)
+	}
+	return text
+}
+
+// replaceGroups replaces the given capture groups of every match of re with Redacted.
+func replaceGroups(re *regexp.Regexp, text string, groups ...int) string {
+	indexes := re.FindAllStringSubmatchIndex(text, -1)
+	if len(indexes) == 0 {
+		return text
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, loc := range indexes {
+		for _, group := range groups {
+			start, end := loc[2*group], loc[2*group+1]
+			if start < last || start < 0 {
+				continue
+			}
+			b.WriteString(text[last:start])
+			b.WriteString(Redacted)
+			last = end
+		}
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
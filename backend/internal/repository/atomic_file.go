@@ -0,0 +1,53 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// writeFileAtomic replaces a file with data: the data is written to a temporary file and
+// synced to disk before it is renamed over the file, so a crash leaves either the old or the
+// new content, never a truncated file.
+func writeFileAtomic(path string, data []byte, perm fs.FileMode) error {
+	tmpPath := path + ".tmp"
+	if err := writeSynced(tmpPath, data, perm); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	syncDir(filepath.Dir(path))
+	return nil
+}
+
+// writeSynced writes a file and syncs it to disk before returning.
+func writeSynced(path string, data []byte, perm fs.FileMode) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// syncDir syncs a directory, so a rename in it survives a crash. File systems that can't
+// sync directories are ignored.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
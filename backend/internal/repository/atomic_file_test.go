@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("writeFileAtomic", func() {
+	It("replaces the file without leaving the temporary file behind", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "settings.json")
+		Expect(writeFileAtomic(path, []byte(`{"v":1}`), 0600)).To(Succeed())
+		Expect(writeFileAtomic(path, []byte(`{"v":2}`), 0600)).To(Succeed())
+
+		Expect(os.ReadFile(path)).To(Equal([]byte(`{"v":2}`)))
+		info, err := os.Stat(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+		Expect(path + ".tmp").NotTo(BeAnExistingFile())
+	})
+
+	It("keeps the previous content when the file can't be replaced", func() {
+		dir := GinkgoT().TempDir()
+		path := filepath.Join(dir, "stats.json")
+		Expect(writeFileAtomic(path, []byte("old"), 0644)).To(Succeed())
+		// A directory in place of the temporary file makes the write fail
+		Expect(os.Mkdir(path+".tmp", 0755)).To(Succeed())
+
+		Expect(writeFileAtomic(path, []byte("new"), 0644)).NotTo(Succeed())
+		Expect(os.ReadFile(path)).To(Equal([]byte("old")))
+	})
+})
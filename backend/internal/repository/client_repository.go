@@ -23,6 +23,8 @@ type ClientRepository interface {
 	Get(id string) (*models.Client, error)
 	// GetByUserID retrieves all clients for a user
 	GetByUserID(userID string) ([]*models.Client, error)
+	// ListAll retrieves the clients of all users
+	ListAll() ([]*models.Client, error)
 	// Update updates an existing client
 	Update(client *models.Client) error
 	// Delete deletes a client by ID
@@ -94,6 +96,11 @@ func (r *FileClientRepository) Get(id string) (*models.Client, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	return r.get(id)
+}
+
+// get retrieves a client by ID. The caller must hold r.mu.
+func (r *FileClientRepository) get(id string) (*models.Client, error) {
 	// We need to search through all users to find the client
 	// This is inefficient but acceptable for MVP
 	// TODO: Add index for faster lookups
@@ -150,6 +157,32 @@ func (r *FileClientRepository) GetByUserID(userID string) ([]*models.Client, err
 	return clients, nil
 }
 
+// ListAll retrieves the clients of all users.
+func (r *FileClientRepository) ListAll() ([]*models.Client, error) {
+	usersDir := filepath.Join(r.baseDir, "users")
+	userDirs, err := os.ReadDir(usersDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*models.Client{}, nil
+		}
+		return nil, fmt.Errorf("failed to read users directory: %w", err)
+	}
+
+	var clients []*models.Client
+	for _, userDir := range userDirs {
+		if !userDir.IsDir() {
+			continue
+		}
+		userClients, err := r.GetByUserID(userDir.Name())
+		if err != nil {
+			return nil, err
+		}
+		clients = append(clients, userClients...)
+	}
+
+	return clients, nil
+}
+
 // Update updates an existing client.
 func (r *FileClientRepository) Update(client *models.Client) error {
 	r.mu.Lock()
@@ -172,7 +205,7 @@ func (r *FileClientRepository) Delete(id string) error {
 	defer r.mu.Unlock()
 
 	// Find client first
-	client, err := r.Get(id)
+	client, err := r.get(id)
 	if err != nil {
 		return err
 	}
@@ -282,6 +315,9 @@ func (r *FileClientRepository) readClientConfig(path string) (*models.Client, er
 	if err := json.Unmarshal(data, &client); err != nil {
 		return nil, fmt.Errorf("failed to parse client config: %w", err)
 	}
+	if client.Version == 0 {
+		client.Version = 1 // Saved before configurations were versioned
+	}
 
 	return &client, nil
 }
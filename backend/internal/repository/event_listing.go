@@ -0,0 +1,192 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+)
+
+// dayBucketSlack widens the time range of a day bucket, since date directories and event
+// IDs may be in a different time zone than the event timestamps.
+const dayBucketSlack = 24 * time.Hour
+
+// dayBucket holds the event files belonging to one day, found by their date directory
+// (YYYY-MM-DD) or timestamped event ID without reading them.
+type dayBucket struct {
+	day   time.Time
+	paths []string
+}
+
+// from returns the earliest timestamp an event of the bucket can have.
+func (b *dayBucket) from() time.Time {
+	return b.day.Add(-dayBucketSlack)
+}
+
+// until returns the time all events of the bucket are before.
+func (b *dayBucket) until() time.Time {
+	return b.day.Add(24*time.Hour + dayBucketSlack)
+}
+
+// canListLazily reports whether a list request can be answered without reading every event:
+// events are sorted by timestamp and filtered at most by date range.
+func canListLazily(req *models.EventListRequest) bool {
+	return req.EventType == "" && req.Status == "" && req.Search == "" &&
+		(req.SortBy == "" || req.SortBy == "timestamp")
+}
+
+// listEventsLazily lists events sorted by timestamp, reading event files day by day in sort
+// order and stopping once the requested page is complete. Days outside the date range are
+// skipped, and fully matching days are counted by their files without reading them.
+func (r *FileEventRepository) listEventsLazily(eventsDir string, req *models.EventListRequest) (*models.EventListResponse, error) {
+	buckets, undated, err := r.scanEventFiles(eventsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Files without a known day, and days partially in the date range, are read up front
+	events := r.readEventFiles(undated)
+	var pending []*dayBucket
+	total := 0
+	for _, bucket := range buckets {
+		if (!req.DateFrom.IsZero() && !bucket.until().After(req.DateFrom)) ||
+			(!req.DateTo.IsZero() && bucket.from().After(req.DateTo)) {
+			continue
+		}
+		if (!req.DateFrom.IsZero() && bucket.from().Before(req.DateFrom)) ||
+			(!req.DateTo.IsZero() && bucket.until().After(req.DateTo)) {
+			events = append(events, r.readEventFiles(bucket.paths)...)
+			continue
+		}
+		pending = append(pending, bucket)
+		total += len(bucket.paths)
+	}
+	events = filterEvents(events, req)
+	total += len(events)
+	if (req.Page-1)*req.PageSize >= total {
+		return pageEvents(nil, total, req), nil
+	}
+
+	ascending := req.SortOrder == "asc"
+	sort.Slice(pending, func(i, j int) bool {
+		if ascending {
+			return pending[i].day.Before(pending[j].day)
+		}
+		return pending[i].day.After(pending[j].day)
+	})
+
+	// Read days until enough events are known to sort before every unread one
+	needed := req.Page * req.PageSize
+	for _, bucket := range pending {
+		ahead := 0
+		for _, event := range events {
+			if (ascending && event.Timestamp.Before(bucket.from())) ||
+				(!ascending && !event.Timestamp.Before(bucket.until())) {
+				ahead++
+			}
+		}
+		if ahead >= needed {
+			break
+		}
+
+		events = append(events, filterEvents(r.readEventFiles(bucket.paths), req)...)
+	}
+
+	sortEvents(events, req.SortBy, req.SortOrder)
+	return pageEvents(events, total, req), nil
+}
+
+// scanEventFiles lists the event files of all storage tiers without reading them, grouped
+// by day. Files whose day is unknown are returned separately.
+func (r *FileEventRepository) scanEventFiles(eventsDir string) ([]*dayBucket, []string, error) {
+	byDay := make(map[time.Time]*dayBucket)
+	var undated []string
+	add := func(day time.Time, path string) {
+		bucket, ok := byDay[day]
+		if !ok {
+			bucket = &dayBucket{day: day}
+			byDay[day] = bucket
+		}
+		bucket.paths = append(bucket.paths, path)
+	}
+
+	for _, dir := range r.tierDirs(eventsDir) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, nil, fmt.Errorf("failed to read events: %w", err)
+		}
+
+		for _, entry := range entries {
+			path := filepath.Join(dir, entry.Name())
+			if entry.IsDir() {
+				day, err := time.Parse("2006-01-02", entry.Name())
+				files, walkErr := jsonFilesIn(path)
+				if walkErr != nil {
+					return nil, nil, fmt.Errorf("failed to read events: %w", walkErr)
+				}
+				for _, file := range files {
+					if err == nil && filepath.Dir(file) == path {
+						add(day, file)
+					} else {
+						undated = append(undated, file)
+					}
+				}
+				continue
+			}
+
+			eventID, ok := strings.CutSuffix(entry.Name(), ".json")
+			if !ok {
+				continue
+			}
+			if ts, ok := parseTimestampFromEventID(eventID); ok {
+				add(ts.Truncate(24*time.Hour), path)
+			} else {
+				undated = append(undated, path)
+			}
+		}
+	}
+
+	buckets := make([]*dayBucket, 0, len(byDay))
+	for _, bucket := range byDay {
+		buckets = append(buckets, bucket)
+	}
+	return buckets, undated, nil
+}
+
+// jsonFilesIn returns the JSON files below a directory.
+func jsonFilesIn(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			if errors.Is(walkErr, fs.ErrNotExist) {
+				return nil
+			}
+			return walkErr
+		}
+		if !d.IsDir() && strings.HasSuffix(d.Name(), ".json") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// readEventFiles reads the events of the given files concurrently, skipping files that
+// can't be read (e.g. removed in the meantime).
+func (r *FileEventRepository) readEventFiles(paths []string) []*models.Event {
+	return readConcurrently(len(paths), func(i int) (*models.Event, error) {
+		return r.readEventFile(paths[i])
+	})
+}
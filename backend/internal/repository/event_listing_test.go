@@ -0,0 +1,101 @@
+package repository_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+)
+
+var _ = Describe("FileEventRepository listing", func() {
+	var (
+		repo *repository.FileEventRepository
+		ids  []string // All event IDs, oldest first
+	)
+
+	BeforeEach(func() {
+		baseDir := GinkgoT().TempDir()
+		eventsDir := filepath.Join(baseDir, "users", "test-user", "clients", "client-list", "events")
+		Expect(os.MkdirAll(eventsDir, 0o755)).To(Succeed())
+		repo = repository.NewFileEventRepository(baseDir)
+
+		ids = nil
+		start := time.Date(2025, 3, 1, 22, 0, 0, 0, time.UTC)
+		for i := 0; i < 12; i++ {
+			ts := start.Add(time.Duration(i) * 5 * time.Hour)
+			if i%3 == 0 {
+				// Stored by gosmee in the flat layout, named after the timestamp
+				id := ts.Format("2006-01-02T15.04.05.000")
+				Expect(os.WriteFile(filepath.Join(eventsDir, id+".json"), []byte(`{"n":1}`), 0o644)).To(Succeed())
+				ids = append(ids, id)
+				continue
+			}
+			id := fmt.Sprintf("evt-%02d", i)
+			// Timestamps with an offset are stored in the date directory of the local day
+			Expect(repo.Save("client-list", &models.Event{ID: id, Timestamp: ts.In(time.FixedZone("UTC+8", 8*3600)), Payload: "{}"})).To(Succeed())
+			ids = append(ids, id)
+		}
+		// Events of unknown day
+		Expect(os.WriteFile(filepath.Join(eventsDir, "legacy.json"), []byte(`{"id":"legacy","timestamp":"2025-03-02T09:30:00Z"}`), 0o644)).To(Succeed())
+		ids = append(ids[:3:3], append([]string{"legacy"}, ids[3:]...)...)
+	})
+
+	listAll := func(req models.EventListRequest) ([]string, int) {
+		var listed []string
+		total := 0
+		for page := 1; ; page++ {
+			req.Page = page
+			req.PageSize = 4
+			response, err := repo.GetByClientID("client-list", &req)
+			Expect(err).NotTo(HaveOccurred())
+			if len(response.Events) == 0 {
+				return listed, total
+			}
+			total = response.Total
+			for _, summary := range response.Events {
+				listed = append(listed, summary.ID)
+			}
+		}
+	}
+
+	reversed := func(in []string) []string {
+		out := make([]string, len(in))
+		for i, id := range in {
+			out[len(in)-1-i] = id
+		}
+		return out
+	}
+
+	It("pages through events in timestamp order", func() {
+		listed, total := listAll(models.EventListRequest{SortBy: "timestamp", SortOrder: "asc"})
+		Expect(listed).To(Equal(ids))
+		Expect(total).To(Equal(len(ids)))
+
+		listed, total = listAll(models.EventListRequest{SortOrder: "desc"})
+		Expect(listed).To(Equal(reversed(ids)))
+		Expect(total).To(Equal(len(ids)))
+	})
+
+	It("counts and lists only events in the date range", func() {
+		listed, total := listAll(models.EventListRequest{
+			SortOrder: "asc",
+			DateFrom:  time.Date(2025, 3, 2, 8, 0, 0, 0, time.UTC),
+			DateTo:    time.Date(2025, 3, 3, 8, 0, 0, 0, time.UTC),
+		})
+		Expect(listed).To(Equal([]string{"evt-02", "legacy", "2025-03-02T13.00.00.000", "evt-04", "evt-05", "2025-03-03T04.00.00.000"}))
+		Expect(total).To(Equal(6))
+	})
+
+	It("returns no events past the last page", func() {
+		response, err := repo.GetByClientID("client-list", &models.EventListRequest{Page: 5, PageSize: 4})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.Total).To(Equal(len(ids)))
+		Expect(response.Events).To(BeEmpty())
+	})
+})
@@ -4,6 +4,9 @@
 package repository
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -14,30 +17,60 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/encryption"
 )
 
+// journalSuffix is appended to the path of an event file to name the journal of its update.
+const journalSuffix = ".journal"
+
+// ErrEventConflict is returned by Save when the event was changed or deleted since it was read.
+var ErrEventConflict = errors.New("event was changed or deleted since it was read")
+
 // EventRepository defines the interface for event storage operations.
 type EventRepository interface {
 	// GetByClientID retrieves events for a specific client
 	GetByClientID(clientID string, req *models.EventListRequest) (*models.EventListResponse, error)
 	// Get retrieves a single event by ID
 	Get(clientID, eventID string) (*models.Event, error)
+	// GetScript retrieves the replay script (.sh) gosmee generated for an event
+	GetScript(clientID, eventID string) ([]byte, error)
+	// SaveScript creates or replaces the replay script (.sh) of an event
+	SaveScript(clientID, eventID string, script []byte) error
+	// Save creates or overwrites an event, failing with ErrEventConflict if the stored
+	// version differs from the event's (see checkEventVersion)
+	Save(clientID string, event *models.Event) error
 	// Delete deletes an event
 	Delete(clientID, eventID string) error
 	// DeleteBatch deletes multiple events
 	DeleteBatch(clientID string, eventIDs []string) error
-	// CleanupOldEvents removes events older than retention period
-	CleanupOldEvents(clientID string, retentionDays int) error
+	// CleanupOldEvents removes events past their retention period, returning how many were removed
+	CleanupOldEvents(clientID string, retention models.EventRetention) (int, error)
 	// GetLatestEventTimestamp returns the latest event timestamp for a client
 	GetLatestEventTimestamp(clientID string) (*time.Time, error)
+	// RewritePayloads rewrites the payloads of event files modified within (since, until] and
+	// their replay scripts, removing the headers in dropHeaders from the scripts
+	RewritePayloads(clientID string, since, until time.Time, rewrite func(eventID, payload string) (string, bool), dropHeaders []string) (int, error)
+	// EncryptPlaintextFiles encrypts unencrypted event files modified within (since, until]
+	EncryptPlaintextFiles(clientID string, since, until time.Time) (int, error)
+	// MoveToCold moves events older than the given number of days to cold storage
+	MoveToCold(clientID string, olderThanDays int) (int, error)
+	// PurgeClientEvents deletes the events of a client stored outside its client directory
+	PurgeClientEvents(clientID string) error
 }
 
 // FileEventRepository implements EventRepository using file system storage.
+//
+// With a cold data directory, old events are moved to the same path below it (e.g.
+// <coldDir>/users/<userID>/clients/<clientID>/events). Reads, updates and deletes cover
+// both directories; new events are always written to the base (hot) directory.
 type FileEventRepository struct {
-	baseDir string       // Base data directory
-	mu      sync.RWMutex // Mutex for thread-safe operations
+	baseDir string             // Base data directory
+	coldDir string             // Cold storage data directory (empty = disabled)
+	cipher  *encryption.Cipher // Encryption at rest (nil = disabled)
+	mu      sync.RWMutex       // Mutex for thread-safe operations
 }
 
 // NewFileEventRepository creates a new file-based event repository.
@@ -47,6 +80,46 @@ func NewFileEventRepository(baseDir string) *FileEventRepository {
 	}
 }
 
+// SetCipher enables encryption at rest: events are encrypted when written and decrypted
+// transparently when read. Unencrypted files remain readable.
+func (r *FileEventRepository) SetCipher(cipher *encryption.Cipher) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cipher = cipher
+}
+
+// SetColdDir enables cold storage in a secondary data directory, e.g. on a slower, bigger disk.
+func (r *FileEventRepository) SetColdDir(coldDir string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.coldDir = coldDir
+}
+
+// tierDirs returns the events directory and, if it exists, its cold storage counterpart.
+func (r *FileEventRepository) tierDirs(eventsDir string) []string {
+	dirs := []string{eventsDir}
+	if coldEventsDir := r.coldEventsDir(eventsDir); coldEventsDir != "" {
+		if _, err := os.Stat(coldEventsDir); err == nil {
+			dirs = append(dirs, coldEventsDir)
+		}
+	}
+	return dirs
+}
+
+// coldEventsDir maps an events directory to its path in cold storage ("" = disabled).
+func (r *FileEventRepository) coldEventsDir(eventsDir string) string {
+	if r.coldDir == "" {
+		return ""
+	}
+	rel, err := filepath.Rel(r.baseDir, eventsDir)
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(r.coldDir, rel)
+}
+
 // getEventsDir returns the events directory for a client.
 func (r *FileEventRepository) getEventsDir(clientID string) (string, error) {
 	// We need to find the client's user directory first
@@ -88,48 +161,52 @@ func (r *FileEventRepository) GetByClientID(clientID string, req *models.EventLi
 		}, nil
 	}
 
-	// Read all event files
-	events, err := r.readAllEvents(eventsDir)
+	if canListLazily(req) {
+		return r.listEventsLazily(eventsDir, req)
+	}
+
+	events, err := r.allEvents(eventsDir)
 	if err != nil {
 		return nil, err
 	}
 
-	// Apply filters
-	filtered := r.filterEvents(events, req)
-
-	// Sort
-	r.sortEvents(filtered, req.SortBy, req.SortOrder)
+	return listEvents(events, req), nil
+}
 
-	// Apply pagination
-	total := len(filtered)
-	start := (req.Page - 1) * req.PageSize
-	end := start + req.PageSize
-	if start >= total {
-		start = 0
-		end = 0
-	}
-	if end > total {
-		end = total
+// allEvents reads the events of a client from both storage tiers.
+func (r *FileEventRepository) allEvents(eventsDir string) ([]*models.Event, error) {
+	var events []*models.Event
+	for _, dir := range r.tierDirs(eventsDir) {
+		dirEvents, err := r.readAllEvents(dir)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, dirEvents...)
 	}
+	return events, nil
+}
 
-	paged := filtered[start:end]
+// Get retrieves a single event by ID.
+func (r *FileEventRepository) Get(clientID, eventID string) (*models.Event, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 
-	// Convert to summaries
-	summaries := make([]*models.EventSummary, len(paged))
-	for i, event := range paged {
-		summaries[i] = event.ToSummary()
+	eventsDir, err := r.getEventsDir(clientID)
+	if err != nil {
+		return nil, err
 	}
 
-	return &models.EventListResponse{
-		Total:    total,
-		Page:     req.Page,
-		PageSize: req.PageSize,
-		Events:   summaries,
-	}, nil
+	eventPath, err := r.findEventPathIn(r.tierDirs(eventsDir), eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.readEventFile(eventPath)
 }
 
-// Get retrieves a single event by ID.
-func (r *FileEventRepository) Get(clientID, eventID string) (*models.Event, error) {
+// GetScript retrieves the replay script (cURL or HTTPie) stored next to an event.
+// It returns an error wrapping fs.ErrNotExist if the event has no script.
+func (r *FileEventRepository) GetScript(clientID, eventID string) ([]byte, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -138,16 +215,124 @@ func (r *FileEventRepository) Get(clientID, eventID string) (*models.Event, erro
 		return nil, err
 	}
 
+	eventPath, err := r.findEventPathIn(r.tierDirs(eventsDir), eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.readFile(strings.TrimSuffix(eventPath, ".json") + ".sh")
+}
+
+// SaveScript creates or replaces the replay script stored next to an event.
+func (r *FileEventRepository) SaveScript(clientID, eventID string, script []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	eventsDir, err := r.getEventsDir(clientID)
+	if err != nil {
+		return err
+	}
+
+	eventPath, err := r.findEventPathIn(r.tierDirs(eventsDir), eventID)
+	if err != nil {
+		return err
+	}
+
+	if err := r.writeFile(strings.TrimSuffix(eventPath, ".json")+".sh", script, 0755); err != nil {
+		return fmt.Errorf("failed to write replay script: %w", err)
+	}
+	return nil
+}
+
+// Save writes an event to the client's events directory.
+// Existing events are overwritten in place, new events are stored in the
+// date directory (YYYY-MM-DD) matching the event timestamp.
+func (r *FileEventRepository) Save(clientID string, event *models.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	eventsDir, err := r.getEventsDir(clientID)
+	if err != nil {
+		return err
+	}
+
+	var stored *models.Event
+	eventPath, err := r.findEventPathIn(r.tierDirs(eventsDir), event.ID)
+	if err == nil {
+		if stored, err = r.readEventFile(eventPath); err != nil {
+			return fmt.Errorf("failed to read stored event: %w", err)
+		}
+	}
+	version, err := checkEventVersion(event, stored)
+	if err != nil {
+		return err
+	}
+
+	if stored == nil {
+		if event.Timestamp.IsZero() {
+			event.Timestamp = time.Now().UTC()
+		}
+		dateDir := filepath.Join(eventsDir, event.Timestamp.Format("2006-01-02"))
+		if err := os.MkdirAll(dateDir, 0755); err != nil {
+			return fmt.Errorf("failed to create event directory: %w", err)
+		}
+		eventPath = filepath.Join(dateDir, fmt.Sprintf("%s.json", event.ID))
+	}
+
+	saved := *event
+	saved.Version = version
+	data, err := json.MarshalIndent(&saved, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	if err := r.writeJournaled(eventPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+	event.Version = version
+
+	return nil
+}
+
+// checkEventVersion implements optimistic concurrency for event updates: an event read from
+// storage is only saved over the same stored version, so concurrent updates (e.g. replays
+// from two browser tabs) can't overwrite each other and deleted events are not recreated.
+// Events with version 0 are new and saved unconditionally. It returns the version to save.
+func checkEventVersion(event, stored *models.Event) (int, error) {
+	switch {
+	case stored == nil && event.Version > 0:
+		return 0, fmt.Errorf("%w: %s was deleted", ErrEventConflict, event.ID)
+	case stored == nil:
+		return 1, nil
+	case event.Version > 0 && event.Version != stored.Version:
+		return 0, fmt.Errorf("%w: %s is at version %d, not %d", ErrEventConflict, event.ID, stored.Version, event.Version)
+	default:
+		return stored.Version + 1, nil
+	}
+}
+
+// findEventPathIn locates the JSON file of an event in any of the given events directories.
+func (r *FileEventRepository) findEventPathIn(eventsDirs []string, eventID string) (string, error) {
+	for _, eventsDir := range eventsDirs {
+		if eventPath, err := r.findEventPath(eventsDir, eventID); err == nil {
+			return eventPath, nil
+		}
+	}
+	return "", fmt.Errorf("event not found: %s", eventID)
+}
+
+// findEventPath locates the JSON file of an event in either the flat or the date directory layout.
+func (r *FileEventRepository) findEventPath(eventsDir, eventID string) (string, error) {
 	// Check flat layout first
 	flatPath := filepath.Join(eventsDir, fmt.Sprintf("%s.json", eventID))
 	if _, err := os.Stat(flatPath); err == nil {
-		return r.readEventFile(flatPath)
+		return flatPath, nil
 	}
 
 	// Search through date directories
 	dateDirs, err := os.ReadDir(eventsDir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read events directory: %w", err)
+		return "", fmt.Errorf("failed to read events directory: %w", err)
 	}
 
 	for _, dateDir := range dateDirs {
@@ -156,12 +341,12 @@ func (r *FileEventRepository) Get(clientID, eventID string) (*models.Event, erro
 		}
 
 		eventPath := filepath.Join(eventsDir, dateDir.Name(), fmt.Sprintf("%s.json", eventID))
-		if event, err := r.readEventFile(eventPath); err == nil {
-			return event, nil
+		if _, err := os.Stat(eventPath); err == nil {
+			return eventPath, nil
 		}
 	}
 
-	return nil, fmt.Errorf("event not found: %s", eventID)
+	return "", fmt.Errorf("event not found: %s", eventID)
 }
 
 // Delete deletes an event.
@@ -174,19 +359,34 @@ func (r *FileEventRepository) Delete(clientID, eventID string) error {
 		return err
 	}
 
+	for _, dir := range r.tierDirs(eventsDir) {
+		deleted, err := r.deleteEventIn(dir, eventID)
+		if err != nil {
+			return err
+		}
+		if deleted {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("event not found: %s", eventID)
+}
+
+// deleteEventIn deletes an event from an events directory and reports whether it was found there.
+func (r *FileEventRepository) deleteEventIn(eventsDir, eventID string) (bool, error) {
 	// Delete from flat layout if present
 	flatJSONPath := filepath.Join(eventsDir, fmt.Sprintf("%s.json", eventID))
 	flatShPath := filepath.Join(eventsDir, fmt.Sprintf("%s.sh", eventID))
 	if _, err := os.Stat(flatJSONPath); err == nil {
 		os.Remove(flatJSONPath)
 		os.Remove(flatShPath)
-		return nil
+		return true, nil
 	}
 
 	// Search through date directories
 	dateDirs, err := os.ReadDir(eventsDir)
 	if err != nil {
-		return fmt.Errorf("failed to read events directory: %w", err)
+		return false, fmt.Errorf("failed to read events directory: %w", err)
 	}
 
 	for _, dateDir := range dateDirs {
@@ -201,11 +401,11 @@ func (r *FileEventRepository) Delete(clientID, eventID string) error {
 		if _, err := os.Stat(eventJSONPath); err == nil {
 			os.Remove(eventJSONPath)
 			os.Remove(eventShPath) // Ignore error if .sh doesn't exist
-			return nil
+			return true, nil
 		}
 	}
 
-	return fmt.Errorf("event not found: %s", eventID)
+	return false, nil
 }
 
 // DeleteBatch deletes multiple events.
@@ -219,47 +419,89 @@ func (r *FileEventRepository) DeleteBatch(clientID string, eventIDs []string) er
 	return nil
 }
 
-// CleanupOldEvents removes events older than retention period.
-func (r *FileEventRepository) CleanupOldEvents(clientID string, retentionDays int) error {
+// CleanupOldEvents removes events past their retention period, by the date directory they
+// are stored in. Date directories past the retention of all statuses are removed as a whole;
+// otherwise the events are read to delete only those whose status has expired. It returns
+// the number of removed events.
+func (r *FileEventRepository) CleanupOldEvents(clientID string, retention models.EventRetention) (int, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if retentionDays == 0 {
-		return nil // Keep forever
+	if !retention.Enabled() {
+		return 0, nil // Keep forever
 	}
 
 	eventsDir, err := r.getEventsDir(clientID)
 	if err != nil {
-		return err
+		return 0, err
+	}
+
+	now := time.Now()
+	removed := 0
+	for _, dir := range r.tierDirs(eventsDir) {
+		// Read date directories
+		dateDirs, err := os.ReadDir(dir)
+		if err != nil {
+			return removed, fmt.Errorf("failed to read events directory: %w", err)
+		}
+
+		for _, dateDir := range dateDirs {
+			if !dateDir.IsDir() {
+				continue
+			}
+
+			// Parse date from directory name (YYYY-MM-DD)
+			dirDate, err := time.Parse("2006-01-02", dateDir.Name())
+			if err != nil || !retention.ExpiresAny(dirDate, now) {
+				continue
+			}
+
+			dateDirPath := filepath.Join(dir, dateDir.Name())
+			count, err := r.cleanupDateDir(dateDirPath, dirDate, now, retention)
+			if err != nil {
+				return removed, err
+			}
+			removed += count
+		}
 	}
 
-	cutoffDate := time.Now().AddDate(0, 0, -retentionDays)
+	return removed, nil
+}
 
-	// Read date directories
-	dateDirs, err := os.ReadDir(eventsDir)
+// cleanupDateDir removes the expired events of a date directory and the directory itself
+// once it is empty.
+func (r *FileEventRepository) cleanupDateDir(dateDirPath string, dirDate, now time.Time, retention models.EventRetention) (int, error) {
+	files, err := os.ReadDir(dateDirPath)
 	if err != nil {
-		return fmt.Errorf("failed to read events directory: %w", err)
+		return 0, fmt.Errorf("failed to read event directory: %w", err)
 	}
 
-	for _, dateDir := range dateDirs {
-		if !dateDir.IsDir() {
+	removed := 0
+	expiresAll := retention.ExpiresAll(dirDate, now)
+	for _, file := range files {
+		eventID, ok := strings.CutSuffix(file.Name(), ".json")
+		if file.IsDir() || !ok {
 			continue
 		}
-
-		// Parse date from directory name (YYYY-MM-DD)
-		dirDate, err := time.Parse("2006-01-02", dateDir.Name())
-		if err != nil {
-			continue
-		}
-
-		// Delete if older than retention period
-		if dirDate.Before(cutoffDate) {
-			dateDirPath := filepath.Join(eventsDir, dateDir.Name())
-			os.RemoveAll(dateDirPath)
+		eventPath := filepath.Join(dateDirPath, file.Name())
+		if !expiresAll {
+			event, err := r.readEventFile(eventPath)
+			if err != nil || !retention.Expired(event.Status, dirDate, now) {
+				// Unreadable events are kept, they may be failed ones
+				continue
+			}
 		}
+		os.Remove(eventPath)
+		os.Remove(filepath.Join(dateDirPath, eventID+".sh")) // Ignore error if .sh doesn't exist
+		removed++
 	}
 
-	return nil
+	if expiresAll {
+		os.RemoveAll(dateDirPath)
+	} else {
+		os.Remove(dateDirPath) // Only succeeds if it is empty now
+	}
+	return removed, nil
 }
 
 // GetLatestEventTimestamp returns the most recent event timestamp for a client.
@@ -275,6 +517,19 @@ func (r *FileEventRepository) GetLatestEventTimestamp(clientID string) (*time.Ti
 		return nil, err
 	}
 
+	// Cold storage only holds older events, so it is only searched if the hot directory is empty
+	for _, dir := range r.tierDirs(eventsDir) {
+		latest, err := r.latestEventTimestampIn(dir)
+		if err != nil || latest != nil {
+			return latest, err
+		}
+	}
+
+	return nil, nil
+}
+
+// latestEventTimestampIn returns the most recent event timestamp in an events directory.
+func (r *FileEventRepository) latestEventTimestampIn(eventsDir string) (*time.Time, error) {
 	entries, err := os.ReadDir(eventsDir)
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
@@ -345,44 +600,476 @@ func (r *FileEventRepository) GetLatestEventTimestamp(clientID string) (*time.Ti
 	return latest, nil
 }
 
-// readAllEvents reads all events from the events directory.
-func (r *FileEventRepository) readAllEvents(eventsDir string) ([]*models.Event, error) {
-	var events []*models.Event
+// RewritePayloads rewrites the payloads of event files modified after since and not after until,
+// both for files written by gosmee (the raw webhook body) and for events stored by Save.
+// rewrite receives the event ID and reports whether the payload changed; unchanged files are
+// left untouched. The replay script of a rewritten event is rewritten with it, without the
+// headers named in dropHeaders (e.g. signatures no longer matching the payload).
+// It returns the number of rewritten files.
+func (r *FileEventRepository) RewritePayloads(clientID string, since, until time.Time, rewrite func(eventID, payload string) (string, bool), dropHeaders []string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	eventsDir, err := r.getEventsDir(clientID)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
 
-	err := filepath.WalkDir(eventsDir, func(path string, d fs.DirEntry, walkErr error) error {
+	rewritten := 0
+	rewriteFile := func(path string, d fs.DirEntry, walkErr error) error {
 		if walkErr != nil {
 			if errors.Is(walkErr, fs.ErrNotExist) {
 				return nil
 			}
 			return walkErr
 		}
-		if d.IsDir() {
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".json") {
 			return nil
 		}
-		if !strings.HasSuffix(d.Name(), ".json") {
+		info, err := d.Info()
+		if err != nil || !info.ModTime().After(since) || info.ModTime().After(until) {
 			return nil
 		}
 
-		event, err := r.readEventFile(path)
+		eventID := strings.TrimSuffix(d.Name(), ".json")
+		changed, err := r.rewriteEventPayload(path, info.Mode().Perm(), func(payload string) (string, bool) {
+			return rewrite(eventID, payload)
+		}, dropHeaders)
 		if err != nil {
+			return fmt.Errorf("failed to rewrite %s: %w", path, err)
+		}
+		if changed {
+			rewritten++
+		}
+		return nil
+	}
+
+	for _, dir := range r.tierDirs(eventsDir) {
+		if err := filepath.WalkDir(dir, rewriteFile); err != nil {
+			return rewritten, err
+		}
+	}
+
+	return rewritten, nil
+}
+
+// rewriteEventPayload rewrites the payload of a single event file and its replay script.
+func (r *FileEventRepository) rewriteEventPayload(path string, perm fs.FileMode, rewrite func(payload string) (string, bool), dropHeaders []string) (bool, error) {
+	data, err := r.readFile(path)
+	if err != nil {
+		return false, err
+	}
+
+	var payload, rewritten string
+	var changed bool
+	var output []byte
+	if stored, ok := decodeStoredEvent(data); ok {
+		// Event stored by Save: only the payload field is rewritten
+		payload = stored["payload"].(string)
+		if rewritten, changed = rewrite(payload); !changed {
+			return false, nil
+		}
+		stored["payload"] = rewritten
+		stored["version"] = storedVersion(stored) + 1
+		if output, err = json.MarshalIndent(stored, "", "  "); err != nil {
+			return false, err
+		}
+	} else {
+		// Raw webhook body written by gosmee
+		payload = strings.TrimSpace(string(data))
+		if rewritten, changed = rewrite(payload); !changed {
+			return false, nil
+		}
+		output = []byte(rewritten)
+	}
+
+	if err := r.writeJournaled(path, output, perm); err != nil {
+		return false, err
+	}
+	if err := r.rewriteScriptFile(strings.TrimSuffix(path, ".json")+".sh", payload, rewritten, dropHeaders); err != nil {
+		return true, fmt.Errorf("failed to rewrite replay script: %w", err)
+	}
+	return true, nil
+}
+
+// rewriteScriptFile rewrites the replay script of an event whose payload was rewritten, if
+// it has one (see rewriteScript).
+func (r *FileEventRepository) rewriteScriptFile(path, payload, rewritten string, dropHeaders []string) error {
+	data, err := r.readFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
 			return nil
 		}
-		events = append(events, event)
+		return err
+	}
+
+	script := rewriteScript(string(data), payload, rewritten, dropHeaders)
+	if script == string(data) {
 		return nil
-	})
+	}
+	return r.writeFile(path, []byte(script), 0755)
+}
+
+// EncryptPlaintextFiles encrypts the event files (.json and .sh) gosmee wrote in plaintext,
+// if they were last modified after since and not after until. It returns the number of
+// encrypted files.
+func (r *FileEventRepository) EncryptPlaintextFiles(clientID string, since, until time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cipher == nil {
+		return 0, nil
+	}
+
+	eventsDir, err := r.getEventsDir(clientID)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	encrypted := 0
+	encryptFile := func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			if errors.Is(walkErr, fs.ErrNotExist) {
+				return nil
+			}
+			return walkErr
+		}
+		if d.IsDir() || (!strings.HasSuffix(d.Name(), ".json") && !strings.HasSuffix(d.Name(), ".sh")) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || !info.ModTime().After(since) || info.ModTime().After(until) {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil || encryption.IsSealed(data) {
+			return nil
+		}
+		if err := r.writeFile(path, data, info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to encrypt %s: %w", path, err)
+		}
+		encrypted++
+		return nil
+	}
+
+	for _, dir := range r.tierDirs(eventsDir) {
+		if err := filepath.WalkDir(dir, encryptFile); err != nil {
+			return encrypted, err
+		}
+	}
+
+	return encrypted, nil
+}
+
+// MoveToCold moves the events of a client older than olderThanDays from the hot to the cold
+// data directory, keeping their layout, permissions and modification times. Date directories
+// (YYYY-MM-DD) are moved by their date, flat event files by the timestamp in their name or,
+// if it has none, their modification time. It returns the number of moved events.
+func (r *FileEventRepository) MoveToCold(clientID string, olderThanDays int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.coldDir == "" {
+		return 0, nil
+	}
+
+	eventsDir, err := r.getEventsDir(clientID)
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
-			return []*models.Event{}, nil
+			return 0, nil
+		}
+		return 0, err
+	}
+	coldEventsDir := r.coldEventsDir(eventsDir)
+	if coldEventsDir == "" {
+		return 0, fmt.Errorf("events directory %s is outside the data directory", eventsDir)
+	}
+
+	entries, err := os.ReadDir(eventsDir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read events directory: %w", err)
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -olderThanDays)
+	moved := 0
+	for _, entry := range entries {
+		if entry.IsDir() {
+			dirDate, err := time.Parse("2006-01-02", entry.Name())
+			if err != nil || !dirDate.Before(cutoff) {
+				continue
+			}
+			files, err := os.ReadDir(filepath.Join(eventsDir, entry.Name()))
+			if err != nil {
+				return moved, fmt.Errorf("failed to read event directory: %w", err)
+			}
+			for _, file := range files {
+				if file.IsDir() {
+					continue
+				}
+				if err := moveFile(filepath.Join(eventsDir, entry.Name(), file.Name()), filepath.Join(coldEventsDir, entry.Name(), file.Name())); err != nil {
+					return moved, fmt.Errorf("failed to move %s to cold storage: %w", file.Name(), err)
+				}
+				if strings.HasSuffix(file.Name(), ".json") {
+					moved++
+				}
+			}
+			os.Remove(filepath.Join(eventsDir, entry.Name())) // Only succeeds if it is empty now
+			continue
+		}
+
+		eventID, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok {
+			continue
+		}
+		timestamp, ok := parseTimestampFromEventID(eventID)
+		if !ok {
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			timestamp = info.ModTime()
+		}
+		if !timestamp.Before(cutoff) {
+			continue
+		}
+
+		scriptName := eventID + ".sh"
+		if _, err := os.Stat(filepath.Join(eventsDir, scriptName)); err == nil {
+			if err := moveFile(filepath.Join(eventsDir, scriptName), filepath.Join(coldEventsDir, scriptName)); err != nil {
+				return moved, fmt.Errorf("failed to move %s to cold storage: %w", scriptName, err)
+			}
+		}
+		if err := moveFile(filepath.Join(eventsDir, entry.Name()), filepath.Join(coldEventsDir, entry.Name())); err != nil {
+			return moved, fmt.Errorf("failed to move %s to cold storage: %w", entry.Name(), err)
+		}
+		moved++
+	}
+
+	return moved, nil
+}
+
+// PurgeClientEvents deletes all events of a client in cold storage, e.g. before the client
+// is deleted. Events in the hot directory are removed with the client directory.
+func (r *FileEventRepository) PurgeClientEvents(clientID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.coldDir == "" {
+		return nil
+	}
+
+	eventsDir, err := r.getEventsDir(clientID)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	// Remove the client directory in cold storage, not only its events directory
+	if coldEventsDir := r.coldEventsDir(eventsDir); coldEventsDir != "" {
+		if err := os.RemoveAll(filepath.Dir(coldEventsDir)); err != nil {
+			return fmt.Errorf("failed to delete cold events: %w", err)
+		}
+	}
+	return nil
+}
+
+// moveFile moves a file, copying it if source and target are on different file systems.
+// The copy keeps the permissions and the modification time of the source.
+func moveFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(dst, data, info.Mode().Perm()); err != nil {
+		return err
+	}
+	if err := os.Chtimes(dst, info.ModTime(), info.ModTime()); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
+
+// readFile reads an event file, decrypting it if needed.
+func (r *FileEventRepository) readFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return r.cipher.Open(data)
+}
+
+// writeFile writes an event file, encrypted if encryption is enabled. The file is
+// replaced atomically so readers never see a partial file.
+func (r *FileEventRepository) writeFile(path string, data []byte, perm fs.FileMode) error {
+	data, err := r.cipher.Seal(data)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, perm)
+}
+
+// writeJournaled writes an event file like writeFile, through a journal: the new content
+// is first written and synced to <path>.journal with its checksum, then applied to the
+// event file, then the journal is removed. If the process crashes while applying it,
+// RecoverJournals completes the update, so an update never leaves a half-written file
+// that would be read as a raw webhook body.
+func (r *FileEventRepository) writeJournaled(path string, data []byte, perm fs.FileMode) error {
+	data, err := r.cipher.Seal(data)
+	if err != nil {
+		return err
+	}
+
+	journalPath := path + journalSuffix
+	sum := sha256.Sum256(data)
+	record := append([]byte(hex.EncodeToString(sum[:])+"\n"), data...)
+	if err := writeSynced(journalPath, record, perm); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+
+	if err := writeFileAtomic(path, data, perm); err != nil {
+		return err // The journal is applied on the next start
+	}
+	return os.Remove(journalPath)
+}
+
+// RecoverJournals completes the event file updates interrupted by a crash, in the data
+// directory and in cold storage. Complete journals are applied, partially written ones are
+// discarded (the event file was not touched yet), and temporary files of interrupted writes
+// are removed. It must run before events are read or written, and returns the number of
+// applied journals.
+func (r *FileEventRepository) RecoverJournals() (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	roots := []string{r.baseDir}
+	if r.coldDir != "" {
+		roots = append(roots, r.coldDir)
+	}
+
+	applied := 0
+	for _, root := range roots {
+		eventsDirs, err := filepath.Glob(filepath.Join(root, "users", "*", "clients", "*", "events"))
+		if err != nil {
+			return applied, err
+		}
+		for _, eventsDir := range eventsDirs {
+			err := filepath.WalkDir(eventsDir, func(path string, d fs.DirEntry, walkErr error) error {
+				if walkErr != nil || d.IsDir() {
+					return walkErr
+				}
+				switch {
+				case strings.HasSuffix(d.Name(), ".tmp"):
+					// Applying a journal may already have replaced it
+					if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+						return err
+					}
+				case strings.HasSuffix(d.Name(), journalSuffix):
+					ok, err := recoverJournal(path)
+					if err != nil {
+						return fmt.Errorf("failed to recover %s: %w", path, err)
+					}
+					if ok {
+						applied++
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return applied, err
+			}
 		}
+	}
+
+	return applied, nil
+}
+
+// recoverJournal applies a journal written by writeJournaled if it is complete, and removes it.
+func recoverJournal(journalPath string) (bool, error) {
+	info, err := os.Stat(journalPath)
+	if err != nil {
+		return false, err
+	}
+	record, err := os.ReadFile(journalPath)
+	if err != nil {
+		return false, err
+	}
+
+	applied := false
+	checksum, data, ok := bytes.Cut(record, []byte("\n"))
+	sum := sha256.Sum256(data)
+	if ok && string(checksum) == hex.EncodeToString(sum[:]) {
+		if err := writeFileAtomic(strings.TrimSuffix(journalPath, journalSuffix), data, info.Mode().Perm()); err != nil {
+			return false, err
+		}
+		applied = true
+	}
+
+	return applied, os.Remove(journalPath)
+}
+
+// decodeStoredEvent decodes an event file written by Save, which carries its payload as a
+// string next to the event metadata. Raw webhook bodies are reported as not stored events.
+func decodeStoredEvent(data []byte) (map[string]interface{}, bool) {
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+
+	var raw map[string]interface{}
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, false
+	}
+	if _, ok := raw["clientId"].(string); !ok {
+		return nil, false
+	}
+	if _, ok := raw["payload"].(string); !ok {
+		return nil, false
+	}
+	return raw, true
+}
+
+// storedVersion returns the version of an event decoded by decodeStoredEvent.
+func storedVersion(stored map[string]interface{}) int {
+	number, _ := stored["version"].(json.Number)
+	version, err := number.Int64()
+	if err != nil || version < 1 {
+		return 1
+	}
+	return int(version)
+}
+
+// readAllEvents reads all events from the events directory. Files are read concurrently,
+// which matters for cold listings of clients with many events.
+func (r *FileEventRepository) readAllEvents(eventsDir string) ([]*models.Event, error) {
+	paths, err := jsonFilesIn(eventsDir)
+	if err != nil {
 		return nil, fmt.Errorf("failed to read events: %w", err)
 	}
 
-	return events, nil
+	return r.readEventFiles(paths), nil
 }
 
 // readEventFile reads an event from a JSON file.
 func (r *FileEventRepository) readEventFile(path string) (*models.Event, error) {
-	data, err := os.ReadFile(path)
+	data, err := r.readFile(path)
 	if err != nil {
 		return nil, err
 	}
@@ -397,12 +1084,16 @@ func (r *FileEventRepository) readEventFile(path string) (*models.Event, error)
 	return &event, nil
 }
 
+// buildEventFromRaw builds an event from a file holding only the payload, as gosmee stores
+// them. Binary payloads are kept byte for byte, base64-encoded.
 func (r *FileEventRepository) buildEventFromRaw(path string, data []byte) *models.Event {
-	payload := strings.TrimSpace(string(data))
-
 	event := &models.Event{
-		Payload: payload,
-		Status:  models.EventStatusNotReplayed,
+		Status: models.EventStatusNotReplayed,
+	}
+	if utf8.Valid(data) {
+		event.Payload = strings.TrimSpace(string(data))
+	} else {
+		event.SetPayloadBytes(data)
 	}
 
 	r.enrichEventFromPath(event, path, data)
@@ -410,8 +1101,51 @@ func (r *FileEventRepository) buildEventFromRaw(path string, data []byte) *model
 	return event
 }
 
+// listEvents filters, sorts and paginates events.
+func listEvents(events []*models.Event, req *models.EventListRequest) *models.EventListResponse {
+	// Apply filters
+	filtered := filterEvents(events, req)
+
+	// Sort
+	sortEvents(filtered, req.SortBy, req.SortOrder)
+
+	return pageEvents(filtered, len(filtered), req)
+}
+
+// pageEvents returns the requested page of sorted events as summaries. total is the number
+// of events matching the request, which may be more than the events passed in.
+func pageEvents(events []*models.Event, total int, req *models.EventListRequest) *models.EventListResponse {
+	start := (req.Page - 1) * req.PageSize
+	end := start + req.PageSize
+	if start >= total {
+		start = 0
+		end = 0
+	}
+	if end > len(events) {
+		end = len(events)
+	}
+	if start > end {
+		start = end
+	}
+
+	paged := events[start:end]
+
+	// Convert to summaries
+	summaries := make([]*models.EventSummary, len(paged))
+	for i, event := range paged {
+		summaries[i] = event.ToSummary()
+	}
+
+	return &models.EventListResponse{
+		Total:    total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+		Events:   summaries,
+	}
+}
+
 // filterEvents applies filters to event list.
-func (r *FileEventRepository) filterEvents(events []*models.Event, req *models.EventListRequest) []*models.Event {
+func filterEvents(events []*models.Event, req *models.EventListRequest) []*models.Event {
 	var filtered []*models.Event
 
 	for _, event := range events {
@@ -425,6 +1159,11 @@ func (r *FileEventRepository) filterEvents(events []*models.Event, req *models.E
 			continue
 		}
 
+		// Filter by payload schema validation status
+		if req.SchemaStatus != "" && string(event.SchemaStatus) != req.SchemaStatus {
+			continue
+		}
+
 		// Filter by search (source contains)
 		if req.Search != "" && !strings.Contains(strings.ToLower(event.Source), strings.ToLower(req.Search)) {
 			continue
@@ -445,7 +1184,7 @@ func (r *FileEventRepository) filterEvents(events []*models.Event, req *models.E
 }
 
 // sortEvents sorts events by field and order.
-func (r *FileEventRepository) sortEvents(events []*models.Event, sortBy, sortOrder string) {
+func sortEvents(events []*models.Event, sortBy, sortOrder string) {
 	sort.Slice(events, func(i, j int) bool {
 		var less bool
 		switch sortBy {
@@ -504,10 +1243,14 @@ func (r *FileEventRepository) enrichEventFromPath(event *models.Event, path stri
 			event.Headers = nil
 		}
 	}
+	event.ResolveDeliveryID()
 
 	if event.Status == "" {
 		event.Status = models.EventStatusNotReplayed
 	}
+	if event.Version == 0 {
+		event.Version = 1 // Written by gosmee or before events were versioned
+	}
 }
 
 func inferClientIDFromPath(path string) string {
@@ -544,7 +1287,7 @@ func (r *FileEventRepository) loadHeadersFromShellScript(jsonPath string) map[st
 	}
 
 	// Read shell script
-	content, err := os.ReadFile(shPath)
+	content, err := r.readFile(shPath)
 	if err != nil {
 		return nil
 	}
@@ -0,0 +1,78 @@
+package repository_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/encryption"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+)
+
+var _ = Describe("FileEventRepository encryption at rest", func() {
+	const clientID = "client-enc"
+
+	var (
+		repo      *repository.FileEventRepository
+		eventsDir string
+	)
+
+	BeforeEach(func() {
+		baseDir := GinkgoT().TempDir()
+		eventsDir = filepath.Join(baseDir, "users", "test-user", "clients", clientID, "events")
+		Expect(os.MkdirAll(eventsDir, 0o755)).To(Succeed())
+
+		cipher, err := encryption.LoadCipher("MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=", "")
+		Expect(err).NotTo(HaveOccurred())
+		repo = repository.NewFileEventRepository(baseDir)
+		repo.SetCipher(cipher)
+	})
+
+	It("encrypts saved events and decrypts them on read", func() {
+		event := &models.Event{ID: "evt-1", ClientID: clientID, Payload: `{"secret":"value"}`, Status: models.EventStatusSuccess}
+		Expect(repo.Save(clientID, event)).To(Succeed())
+
+		files, err := filepath.Glob(filepath.Join(eventsDir, "*", "evt-1.json"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(files).To(HaveLen(1))
+		data, err := os.ReadFile(files[0])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(encryption.IsSealed(data)).To(BeTrue())
+		Expect(string(data)).NotTo(ContainSubstring("secret"))
+
+		stored, err := repo.Get(clientID, "evt-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored.Payload).To(Equal(`{"secret":"value"}`))
+	})
+
+	It("encrypts files written in plaintext by gosmee", func() {
+		jsonPath := filepath.Join(eventsDir, "20251001T120000.json")
+		shPath := filepath.Join(eventsDir, "20251001T120000.sh")
+		Expect(os.WriteFile(jsonPath, []byte(`{"ref":"refs/heads/main"}`), 0o644)).To(Succeed())
+		Expect(os.WriteFile(shPath, []byte("curl -H 'X-GitHub-Event: push' http://target\n"), 0o755)).To(Succeed())
+
+		encrypted, err := repo.EncryptPlaintextFiles(clientID, time.Time{}, time.Now())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(encrypted).To(Equal(2))
+
+		for _, path := range []string{jsonPath, shPath} {
+			data, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(encryption.IsSealed(data)).To(BeTrue())
+		}
+
+		stored, err := repo.Get(clientID, "20251001T120000")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored.Payload).To(Equal(`{"ref":"refs/heads/main"}`))
+		Expect(stored.Headers).To(HaveKeyWithValue("X-GitHub-Event", "push"))
+
+		// Already encrypted files are left alone
+		encrypted, err = repo.EncryptPlaintextFiles(clientID, time.Time{}, time.Now())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(encrypted).To(BeZero())
+	})
+})
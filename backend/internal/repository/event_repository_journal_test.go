@@ -0,0 +1,78 @@
+package repository_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+)
+
+var _ = Describe("FileEventRepository journal", func() {
+	const clientID = "client-journal"
+
+	var (
+		repo      *repository.FileEventRepository
+		eventPath string
+	)
+
+	BeforeEach(func() {
+		baseDir := GinkgoT().TempDir()
+		eventsDir := filepath.Join(baseDir, "users", "test-user", "clients", clientID, "events")
+		Expect(os.MkdirAll(eventsDir, 0o755)).To(Succeed())
+
+		repo = repository.NewFileEventRepository(baseDir)
+		timestamp := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+		Expect(repo.Save(clientID, &models.Event{ID: "evt-1", ClientID: clientID, Timestamp: timestamp, Payload: `{"a":1}`})).To(Succeed())
+		eventPath = filepath.Join(eventsDir, "2025-10-01", "evt-1.json")
+		Expect(eventPath).To(BeAnExistingFile())
+		Expect(eventPath + ".journal").NotTo(BeAnExistingFile())
+	})
+
+	It("completes an update interrupted after the journal was written", func() {
+		event, err := repo.Get(clientID, "evt-1")
+		Expect(err).NotTo(HaveOccurred())
+		event.Status = models.EventStatusSuccess
+		event.LatencyMs = 42
+		updated, err := json.MarshalIndent(event, "", "  ")
+		Expect(err).NotTo(HaveOccurred())
+
+		// Crash while applying: complete journal, half-written event file
+		sum := sha256.Sum256(updated)
+		Expect(os.WriteFile(eventPath+".journal", append([]byte(hex.EncodeToString(sum[:])+"\n"), updated...), 0o644)).To(Succeed())
+		Expect(os.WriteFile(eventPath, updated[:len(updated)/2], 0o644)).To(Succeed())
+		Expect(os.WriteFile(eventPath+".tmp", updated[:10], 0o644)).To(Succeed())
+
+		recovered, err := repo.RecoverJournals()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recovered).To(Equal(1))
+		Expect(eventPath + ".journal").NotTo(BeAnExistingFile())
+		Expect(eventPath + ".tmp").NotTo(BeAnExistingFile())
+
+		event, err = repo.Get(clientID, "evt-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(event.Status).To(Equal(models.EventStatusSuccess))
+		Expect(event.LatencyMs).To(Equal(42))
+		Expect(event.Payload).To(Equal(`{"a":1}`))
+	})
+
+	It("discards a partially written journal", func() {
+		Expect(os.WriteFile(eventPath+".journal", []byte("0123abcd\n{\"id\":"), 0o644)).To(Succeed())
+
+		recovered, err := repo.RecoverJournals()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recovered).To(BeZero())
+		Expect(eventPath + ".journal").NotTo(BeAnExistingFile())
+
+		event, err := repo.Get(clientID, "evt-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(event.Payload).To(Equal(`{"a":1}`))
+	})
+})
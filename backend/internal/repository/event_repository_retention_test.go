@@ -0,0 +1,72 @@
+package repository_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+)
+
+var _ = Describe("FileEventRepository retention", func() {
+	const clientID = "client-retention"
+
+	var (
+		repo      *repository.FileEventRepository
+		eventsDir string
+	)
+
+	BeforeEach(func() {
+		baseDir := GinkgoT().TempDir()
+		eventsDir = filepath.Join(baseDir, "users", "test-user", "clients", clientID, "events")
+		Expect(os.MkdirAll(eventsDir, 0o755)).To(Succeed())
+		repo = repository.NewFileEventRepository(baseDir)
+	})
+
+	save := func(id string, daysAgo int, status models.EventStatus) {
+		timestamp := time.Now().AddDate(0, 0, -daysAgo).UTC()
+		Expect(repo.Save(clientID, &models.Event{ID: id, ClientID: clientID, Timestamp: timestamp, Status: status, Payload: "{}"})).To(Succeed())
+	}
+
+	remaining := func() []string {
+		list, err := repo.GetByClientID(clientID, &models.EventListRequest{Page: 1, PageSize: 100, SortBy: "timestamp", SortOrder: "asc"})
+		Expect(err).NotTo(HaveOccurred())
+		ids := []string{}
+		for _, event := range list.Events {
+			ids = append(ids, event.ID)
+		}
+		return ids
+	}
+
+	It("keeps failed events longer than the others", func() {
+		save("evt-ancient-failed", 100, models.EventStatusFailed)
+		save("evt-old-success", 30, models.EventStatusSuccess)
+		save("evt-old-failed", 30, models.EventStatusFailed)
+		save("evt-old-retrying", 30, models.EventStatusRetrying)
+		save("evt-recent", 3, models.EventStatusSuccess)
+
+		removed, err := repo.CleanupOldEvents(clientID, models.EventRetention{Days: 7, FailedDays: 90})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(removed).To(Equal(2))
+		Expect(remaining()).To(ConsistOf("evt-old-failed", "evt-old-retrying", "evt-recent"))
+		Expect(filepath.Join(eventsDir, time.Now().AddDate(0, 0, -100).UTC().Format("2006-01-02"))).NotTo(BeADirectory())
+	})
+
+	It("treats all statuses alike without a failed event retention", func() {
+		save("evt-old-failed", 30, models.EventStatusFailed)
+		save("evt-recent", 3, models.EventStatusFailed)
+
+		removed, err := repo.CleanupOldEvents(clientID, models.EventRetention{Days: 7})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(removed).To(Equal(1))
+		Expect(remaining()).To(ConsistOf("evt-recent"))
+
+		removed, err = repo.CleanupOldEvents(clientID, models.EventRetention{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(removed).To(BeZero())
+	})
+})
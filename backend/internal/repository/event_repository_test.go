@@ -3,6 +3,7 @@ package repository_test
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -107,6 +108,102 @@ var _ = Describe("FileEventRepository", func() {
 	)
 })
 
+var _ = Describe("FileEventRepository.Save", func() {
+	It("stores new events in a date directory and overwrites them in place", func() {
+		baseDir := GinkgoT().TempDir()
+		eventsDir := filepath.Join(baseDir, "users", "test-user", "clients", "client-save", "events")
+		Expect(os.MkdirAll(eventsDir, 0o755)).To(Succeed())
+
+		repo := repository.NewFileEventRepository(baseDir)
+		event := &models.Event{
+			ID:        "evt-save",
+			ClientID:  "client-save",
+			Timestamp: time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC),
+			EventType: "push",
+			Status:    models.EventStatusNotReplayed,
+			Headers:   map[string]string{"Content-Type": "application/json"},
+			Payload:   `{"hello":"world"}`,
+		}
+		Expect(repo.Save("client-save", event)).To(Succeed())
+		Expect(filepath.Join(eventsDir, "2025-03-04", "evt-save.json")).To(BeAnExistingFile())
+
+		event.Status = models.EventStatusSuccess
+		event.StatusCode = 200
+		event.Timestamp = time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC)
+		Expect(repo.Save("client-save", event)).To(Succeed())
+		Expect(filepath.Join(eventsDir, "2025-03-05")).NotTo(BeADirectory())
+
+		stored, err := repo.Get("client-save", "evt-save")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored.Status).To(Equal(models.EventStatusSuccess))
+		Expect(stored.StatusCode).To(Equal(200))
+		Expect(stored.EventType).To(Equal("push"))
+		Expect(stored.Payload).To(Equal(`{"hello":"world"}`))
+	})
+})
+
+var _ = Describe("FileEventRepository.RewritePayloads", func() {
+	var (
+		baseDir   string
+		eventsDir string
+		repo      *repository.FileEventRepository
+	)
+
+	BeforeEach(func() {
+		baseDir = GinkgoT().TempDir()
+		eventsDir = filepath.Join(baseDir, "users", "test-user", "clients", "client-rewrite", "events")
+		Expect(os.MkdirAll(eventsDir, 0o755)).To(Succeed())
+		repo = repository.NewFileEventRepository(baseDir)
+	})
+
+	// replace returns a rewrite replacing old with new in payloads
+	replace := func(old, new string) func(eventID, payload string) (string, bool) {
+		return func(eventID, payload string) (string, bool) {
+			return strings.ReplaceAll(payload, old, new), strings.Contains(payload, old)
+		}
+	}
+
+	It("rewrites the payload embedded in the replay script of stored events", func() {
+		event := &models.Event{ID: "evt-1", ClientID: "client-rewrite", Timestamp: time.Now().UTC(), Payload: `{"token":"it's s3cr3t"}`}
+		Expect(repo.Save("client-rewrite", event)).To(Succeed())
+		script := "#!/usr/bin/env bash\n" +
+			"payload='{\"token\":\"it'\\''s s3cr3t\"}'\n\n" +
+			`printf '%s' "${payload}" | curl -sSi -X POST -H 'Content-Type: application/json' -H 'X-Hub-Signature-256: sha256=abc' --data-binary @- "${targetURL}"` + "\n"
+		Expect(repo.SaveScript("client-rewrite", "evt-1", []byte(script))).To(Succeed())
+
+		dropHeaders := []string{"X-Hub-Signature-256", "X-Hub-Signature"}
+		rewritten, err := repo.RewritePayloads("client-rewrite", time.Time{}, time.Now().Add(time.Second), replace("s3cr3t", "***"), dropHeaders)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rewritten).To(Equal(1))
+		// Rewritten again, e.g. after the rules changed
+		_, err = repo.RewritePayloads("client-rewrite", time.Time{}, time.Now().Add(time.Second), replace("it's", "[REDACTED]"), dropHeaders)
+		Expect(err).NotTo(HaveOccurred())
+
+		stored, err := repo.GetScript("client-rewrite", "evt-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(stored)).To(Equal("#!/usr/bin/env bash\n" +
+			"payload='{\"token\":\"[REDACTED] ***\"}'\n\n" +
+			`printf '%s' "${payload}" | curl -sSi -X POST -H 'Content-Type: application/json' --data-binary @- "${targetURL}"` + "\n"))
+	})
+
+	It("removes the dropped headers from the scripts gosmee wrote", func() {
+		Expect(os.WriteFile(filepath.Join(eventsDir, "gosmee-1.json"), []byte(`{"token":"s3cr3t"}`), 0o644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(eventsDir, "gosmee-1.sh"), []byte(
+			`curl -sSi -H "Content-Type: application/json" -H 'X-GitHub-Event: push' -H 'x-hub-signature-256: sha256=abc' -X POST -d @./gosmee-1.json ${targetURL}`+"\n"), 0o755)).To(Succeed())
+
+		_, err := repo.RewritePayloads("client-rewrite", time.Time{}, time.Now().Add(time.Second), replace("s3cr3t", "***"), []string{"X-Hub-Signature-256"})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.ReadFile(filepath.Join(eventsDir, "gosmee-1.json"))).To(Equal([]byte(`{"token":"***"}`)))
+		Expect(os.ReadFile(filepath.Join(eventsDir, "gosmee-1.sh"))).To(Equal([]byte(
+			`curl -sSi -H "Content-Type: application/json" -H 'X-GitHub-Event: push' -X POST -d @./gosmee-1.json ${targetURL}` + "\n")))
+		event, err := repo.Get("client-rewrite", "gosmee-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(event.Headers).To(HaveKeyWithValue("X-GitHub-Event", "push"))
+		Expect(event.Headers).NotTo(HaveKey("x-hub-signature-256"))
+	})
+})
+
 func MustLoadYaml[T any](path string) T {
 	data, err := os.ReadFile(path)
 	Expect(err).NotTo(HaveOccurred(), "failed to read yaml file %s", path)
@@ -0,0 +1,84 @@
+package repository_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+)
+
+var _ = Describe("FileEventRepository cold storage", func() {
+	const clientID = "client-tier"
+
+	var (
+		repo          *repository.FileEventRepository
+		eventsDir     string
+		coldEventsDir string
+	)
+
+	BeforeEach(func() {
+		baseDir := GinkgoT().TempDir()
+		coldDir := GinkgoT().TempDir()
+		eventsDir = filepath.Join(baseDir, "users", "test-user", "clients", clientID, "events")
+		coldEventsDir = filepath.Join(coldDir, "users", "test-user", "clients", clientID, "events")
+		Expect(os.MkdirAll(eventsDir, 0o755)).To(Succeed())
+
+		repo = repository.NewFileEventRepository(baseDir)
+		repo.SetColdDir(coldDir)
+	})
+
+	It("moves old events to cold storage and keeps them readable", func() {
+		old := time.Now().AddDate(0, 0, -10).UTC()
+		Expect(repo.Save(clientID, &models.Event{ID: "evt-old", ClientID: clientID, Timestamp: old, Payload: "{}"})).To(Succeed())
+		Expect(repo.Save(clientID, &models.Event{ID: "evt-new", ClientID: clientID, Timestamp: time.Now().UTC(), Payload: "{}"})).To(Succeed())
+
+		// Flat file written by gosmee, dated by its modification time
+		flatPath := filepath.Join(eventsDir, "gosmee-old.json")
+		Expect(os.WriteFile(flatPath, []byte(`{"ref":"main"}`), 0o644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(eventsDir, "gosmee-old.sh"), []byte("curl -H 'X-GitHub-Event: push' http://target\n"), 0o755)).To(Succeed())
+		Expect(os.Chtimes(flatPath, old, old)).To(Succeed())
+
+		moved, err := repo.MoveToCold(clientID, 7)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(moved).To(Equal(2))
+
+		Expect(filepath.Join(coldEventsDir, old.Format("2006-01-02"), "evt-old.json")).To(BeAnExistingFile())
+		Expect(filepath.Join(coldEventsDir, "gosmee-old.sh")).To(BeAnExistingFile())
+		Expect(filepath.Join(eventsDir, old.Format("2006-01-02"))).NotTo(BeADirectory())
+		Expect(flatPath).NotTo(BeAnExistingFile())
+
+		list, err := repo.GetByClientID(clientID, &models.EventListRequest{Page: 1, PageSize: 10})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.Total).To(Equal(3))
+
+		event, err := repo.Get(clientID, "gosmee-old")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(event.Headers).To(HaveKeyWithValue("X-GitHub-Event", "push"))
+
+		// Updates of cold events stay in cold storage
+		event, err = repo.Get(clientID, "evt-old")
+		Expect(err).NotTo(HaveOccurred())
+		event.Status = models.EventStatusSuccess
+		Expect(repo.Save(clientID, event)).To(Succeed())
+		Expect(filepath.Join(eventsDir, old.Format("2006-01-02"))).NotTo(BeADirectory())
+
+		Expect(repo.Delete(clientID, "evt-old")).To(Succeed())
+		_, err = repo.Get(clientID, "evt-old")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("deletes a client's cold events", func() {
+		Expect(repo.Save(clientID, &models.Event{ID: "evt-old", ClientID: clientID, Timestamp: time.Now().AddDate(0, 0, -10), Payload: "{}"})).To(Succeed())
+		_, err := repo.MoveToCold(clientID, 7)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(coldEventsDir).To(BeADirectory())
+
+		Expect(repo.PurgeClientEvents(clientID)).To(Succeed())
+		Expect(filepath.Dir(coldEventsDir)).NotTo(BeADirectory())
+	})
+})
@@ -0,0 +1,38 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+import (
+	"regexp"
+	"strings"
+)
+
+// rewriteScript rewrites the replay script of an event whose payload was rewritten from
+// oldPayload to newPayload. Scripts generated by the backend embed the payload as a quoted
+// shell word (payload='...'), which is replaced; gosmee's scripts send the event file, which
+// is rewritten already. The headers named in dropHeaders are removed from the request.
+func rewriteScript(script, oldPayload, newPayload string, dropHeaders []string) string {
+	embedded := "payload=" + shellQuote(oldPayload)
+	before, after, found := strings.Cut(script, embedded)
+	if !found {
+		return dropScriptHeaders(script, dropHeaders)
+	}
+	return dropScriptHeaders(before, dropHeaders) + "payload=" + shellQuote(newPayload) + dropScriptHeaders(after, dropHeaders)
+}
+
+// dropScriptHeaders removes the headers of a cURL (-H 'Name: value') or HTTPie ('Name:value')
+// command. Header names are matched case-insensitively.
+func dropScriptHeaders(script string, names []string) string {
+	for _, name := range names {
+		quoted := regexp.QuoteMeta(name)
+		header := regexp.MustCompile(`(?i)[ \t]+(?:-H[ \t]+)?(?:'` + quoted + `:(?:[^']|'\\'')*'|"` + quoted + `:[^"]*")`)
+		script = header.ReplaceAllString(script, "")
+	}
+	return script
+}
+
+// shellQuote quotes s as a single-quoted shell word, like the scripts generated by the backend.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+)
+
+// impersonationAuditFile is the server-wide audit log of impersonated requests.
+const impersonationAuditFile = "impersonation_audit.jsonl"
+
+// ImpersonationRepository defines the interface for the impersonation audit log.
+type ImpersonationRepository interface {
+	// Append adds an entry to the audit log
+	Append(record *models.ImpersonationRecord) error
+	// List retrieves all entries in the order they were added
+	List() ([]*models.ImpersonationRecord, error)
+}
+
+// FileImpersonationRepository implements ImpersonationRepository with a JSON Lines file
+// (impersonation_audit.jsonl in the data directory). Entries are only ever appended.
+type FileImpersonationRepository struct {
+	baseDir string       // Base data directory
+	mu      sync.RWMutex // Mutex for thread-safe operations
+}
+
+// NewFileImpersonationRepository creates a new file-based impersonation audit log.
+func NewFileImpersonationRepository(baseDir string) *FileImpersonationRepository {
+	return &FileImpersonationRepository{
+		baseDir: baseDir,
+	}
+}
+
+// Append adds an entry to the audit log.
+func (r *FileImpersonationRepository) Append(record *models.ImpersonationRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := os.MkdirAll(r.baseDir, 0755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal impersonation record: %w", err)
+	}
+
+	file, err := os.OpenFile(r.auditPath(), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open impersonation audit log: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write impersonation record: %w", err)
+	}
+
+	return nil
+}
+
+// List retrieves all entries in the order they were added, skipping malformed lines
+// (e.g. a line cut short by a crash while appending).
+func (r *FileImpersonationRepository) List() ([]*models.ImpersonationRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	file, err := os.Open(r.auditPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*models.ImpersonationRecord{}, nil
+		}
+		return nil, fmt.Errorf("failed to open impersonation audit log: %w", err)
+	}
+	defer file.Close()
+
+	records := []*models.ImpersonationRecord{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		record := &models.ImpersonationRecord{}
+		if err := json.Unmarshal(scanner.Bytes(), record); err != nil {
+			continue
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read impersonation audit log: %w", err)
+	}
+
+	return records, nil
+}
+
+// auditPath returns the path of the audit log.
+func (r *FileImpersonationRepository) auditPath() string {
+	return filepath.Join(r.baseDir, impersonationAuditFile)
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/encryption"
+)
+
+// LogAnnotationsSuffix is the file name suffix of a day's annotations, stored next to the
+// day's log (logs/YYYY-MM-DD.annotations.json).
+const LogAnnotationsSuffix = ".annotations.json"
+
+// LogAnnotationRepository defines the interface for log annotation storage operations.
+type LogAnnotationRepository interface {
+	// Get retrieves the annotations of a client's log of a day (empty when there are none)
+	Get(userID, clientID, date string) ([]*models.LogAnnotation, error)
+	// Save stores the annotations of a client's log of a day, replacing the existing ones
+	Save(userID, clientID, date string, annotations []*models.LogAnnotation) error
+}
+
+// FileLogAnnotationRepository implements LogAnnotationRepository with one file per client
+// and day next to the day's log (users/<userID>/clients/<clientID>/logs/<date>.annotations.json
+// in the data directory). Files are encrypted when encryption at rest is enabled.
+type FileLogAnnotationRepository struct {
+	baseDir string             // Base data directory
+	cipher  *encryption.Cipher // Encryption at rest (nil = disabled)
+	mu      sync.RWMutex       // Mutex for thread-safe operations
+}
+
+// NewFileLogAnnotationRepository creates a new file-based log annotation repository.
+func NewFileLogAnnotationRepository(baseDir string) *FileLogAnnotationRepository {
+	return &FileLogAnnotationRepository{
+		baseDir: baseDir,
+	}
+}
+
+// SetCipher enables encryption at rest for annotation files. Unencrypted files remain
+// readable and are encrypted the next time they are saved.
+func (r *FileLogAnnotationRepository) SetCipher(cipher *encryption.Cipher) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cipher = cipher
+}
+
+// Get retrieves the annotations of a client's log of a day.
+func (r *FileLogAnnotationRepository) Get(userID, clientID, date string) ([]*models.LogAnnotation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	data, err := os.ReadFile(r.annotationsPath(userID, clientID, date))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*models.LogAnnotation{}, nil
+		}
+		return nil, fmt.Errorf("failed to read annotations: %w", err)
+	}
+	if data, err = r.cipher.Open(data); err != nil {
+		return nil, fmt.Errorf("failed to decrypt annotations: %w", err)
+	}
+
+	annotations := []*models.LogAnnotation{}
+	if err := json.Unmarshal(data, &annotations); err != nil {
+		return nil, fmt.Errorf("failed to parse annotations: %w", err)
+	}
+	return annotations, nil
+}
+
+// Save stores the annotations of a client's log of a day. No annotations remove the file.
+func (r *FileLogAnnotationRepository) Save(userID, clientID, date string, annotations []*models.LogAnnotation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	path := r.annotationsPath(userID, clientID, date)
+	if len(annotations) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove annotations: %w", err)
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create logs directory: %w", err)
+	}
+	data, err := json.MarshalIndent(annotations, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal annotations: %w", err)
+	}
+	if data, err = r.cipher.Seal(data); err != nil {
+		return fmt.Errorf("failed to encrypt annotations: %w", err)
+	}
+
+	if err := writeFileAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to save annotations: %w", err)
+	}
+	return nil
+}
+
+// annotationsPath returns the path of a client's annotations file of a day.
+func (r *FileLogAnnotationRepository) annotationsPath(userID, clientID, date string) string {
+	return filepath.Join(r.baseDir, "users", userID, "clients", clientID, "logs", date+LogAnnotationsSuffix)
+}
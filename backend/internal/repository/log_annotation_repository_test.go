@@ -0,0 +1,55 @@
+package repository_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/encryption"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+)
+
+var _ = Describe("FileLogAnnotationRepository", func() {
+	var (
+		baseDir string
+		repo    *repository.FileLogAnnotationRepository
+		path    string
+	)
+
+	BeforeEach(func() {
+		baseDir = GinkgoT().TempDir()
+		repo = repository.NewFileLogAnnotationRepository(baseDir)
+		path = filepath.Join(baseDir, "users", "user", "clients", "client", "logs", "2025-10-01.annotations.json")
+	})
+
+	It("stores the annotations of a day encrypted next to its log", func() {
+		cipher, err := encryption.LoadCipher("MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=", "")
+		Expect(err).NotTo(HaveOccurred())
+		repo.SetCipher(cipher)
+
+		Expect(repo.Get("user", "client", "2025-10-01")).To(BeEmpty())
+		annotation := &models.LogAnnotation{ID: "a-1", Time: time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC), Note: "deploy"}
+		Expect(repo.Save("user", "client", "2025-10-01", []*models.LogAnnotation{annotation})).To(Succeed())
+
+		data, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(encryption.IsSealed(data)).To(BeTrue())
+		annotations, err := repo.Get("user", "client", "2025-10-01")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(annotations).To(HaveLen(1))
+		Expect(annotations[0].Note).To(Equal("deploy"))
+	})
+
+	It("removes the file when no annotations are left", func() {
+		Expect(repo.Save("user", "client", "2025-10-01", []*models.LogAnnotation{{ID: "a-1", Note: "deploy"}})).To(Succeed())
+		Expect(path).To(BeAnExistingFile())
+
+		Expect(repo.Save("user", "client", "2025-10-01", nil)).To(Succeed())
+		Expect(path).NotTo(BeAnExistingFile())
+		Expect(repo.Get("user", "client", "2025-10-01")).To(BeEmpty())
+	})
+})
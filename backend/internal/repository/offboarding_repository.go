@@ -0,0 +1,64 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+)
+
+// offboardingAuditFile is the server-wide audit log of offboarded users.
+const offboardingAuditFile = "offboarding_audit.jsonl"
+
+// OffboardingRepository defines the interface for the user offboarding audit log.
+type OffboardingRepository interface {
+	// Append adds an entry to the audit log
+	Append(record *models.UserOffboardingRecord) error
+}
+
+// FileOffboardingRepository implements OffboardingRepository with a JSON Lines file
+// (offboarding_audit.jsonl in the data directory). Entries are only ever appended.
+type FileOffboardingRepository struct {
+	baseDir string     // Base data directory
+	mu      sync.Mutex // Mutex for thread-safe operations
+}
+
+// NewFileOffboardingRepository creates a new file-based offboarding audit log.
+func NewFileOffboardingRepository(baseDir string) *FileOffboardingRepository {
+	return &FileOffboardingRepository{
+		baseDir: baseDir,
+	}
+}
+
+// Append adds an entry to the audit log.
+func (r *FileOffboardingRepository) Append(record *models.UserOffboardingRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := os.MkdirAll(r.baseDir, 0755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal offboarding record: %w", err)
+	}
+
+	file, err := os.OpenFile(filepath.Join(r.baseDir, offboardingAuditFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open offboarding audit log: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write offboarding record: %w", err)
+	}
+
+	return nil
+}
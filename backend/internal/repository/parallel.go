@@ -0,0 +1,46 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+import "sync"
+
+// eventReadWorkers bounds how many event files or objects are read concurrently when all
+// events of a client are scanned.
+const eventReadWorkers = 8
+
+// readConcurrently calls read for every index below n on a bounded pool of workers and
+// returns the results of the successful calls in index order.
+func readConcurrently[T any](n int, read func(i int) (T, error)) []T {
+	results := make([]T, n)
+	ok := make([]bool, n)
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(eventReadWorkers, n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				result, err := read(i)
+				if err == nil {
+					results[i] = result
+					ok[i] = true
+				}
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	kept := results[:0]
+	for i, result := range results {
+		if ok[i] {
+			kept = append(kept, result)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("readConcurrently", func() {
+	// read returns "item-<i>" after a random delay, so reads finish out of order, and fails
+	// for the indexes in failing
+	read := func(failing map[int]bool) func(i int) (string, error) {
+		return func(i int) (string, error) {
+			time.Sleep(time.Duration(rand.Intn(3)) * time.Millisecond)
+			if failing[i] {
+				return "", errors.New("unreadable")
+			}
+			return fmt.Sprintf("item-%d", i), nil
+		}
+	}
+
+	It("returns the results in input order", func() {
+		results := readConcurrently(50, read(nil))
+
+		Expect(results).To(HaveLen(50))
+		for i, result := range results {
+			Expect(result).To(Equal(fmt.Sprintf("item-%d", i)))
+		}
+	})
+
+	It("skips failed reads and keeps the order of the others", func() {
+		results := readConcurrently(10, read(map[int]bool{0: true, 3: true, 4: true, 9: true}))
+
+		Expect(results).To(Equal([]string{"item-1", "item-2", "item-5", "item-6", "item-7", "item-8"}))
+	})
+
+	It("returns nothing when every read fails or there is nothing to read", func() {
+		Expect(readConcurrently(3, read(map[int]bool{0: true, 1: true, 2: true}))).To(BeEmpty())
+		Expect(readConcurrently(0, read(nil))).To(BeEmpty())
+	})
+})
@@ -0,0 +1,158 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+)
+
+// QuotaHistoryRepository defines the interface for quota snapshot storage.
+type QuotaHistoryRepository interface {
+	// Append stores a snapshot of a user's quota usage
+	Append(userID string, snapshot *models.QuotaSnapshot) error
+	// List retrieves the snapshots of a user taken at or after since, in ascending time order
+	List(userID string, since time.Time) ([]*models.QuotaSnapshot, error)
+	// Prune deletes the snapshots of a user taken before the given time
+	Prune(userID string, before time.Time) error
+}
+
+// FileQuotaHistoryRepository implements QuotaHistoryRepository with one JSON Lines file per user
+// (users/<userID>/quota_history.jsonl in the data directory).
+type FileQuotaHistoryRepository struct {
+	baseDir string       // Base data directory
+	mu      sync.RWMutex // Mutex for thread-safe operations
+}
+
+// NewFileQuotaHistoryRepository creates a new file-based quota history repository.
+func NewFileQuotaHistoryRepository(baseDir string) *FileQuotaHistoryRepository {
+	return &FileQuotaHistoryRepository{
+		baseDir: baseDir,
+	}
+}
+
+// Append stores a snapshot of a user's quota usage.
+func (r *FileQuotaHistoryRepository) Append(userID string, snapshot *models.QuotaSnapshot) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	path := r.historyPath(userID)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create user directory: %w", err)
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to marshal quota snapshot: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open quota history: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write quota snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// List retrieves the snapshots of a user taken at or after since, in ascending time order.
+func (r *FileQuotaHistoryRepository) List(userID string, since time.Time) ([]*models.QuotaSnapshot, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	snapshots, err := r.read(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*models.QuotaSnapshot{}
+	for _, snapshot := range snapshots {
+		if !snapshot.Time.Before(since) {
+			result = append(result, snapshot)
+		}
+	}
+
+	return result, nil
+}
+
+// Prune deletes the snapshots of a user taken before the given time.
+// The file is only rewritten when snapshots were deleted.
+func (r *FileQuotaHistoryRepository) Prune(userID string, before time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snapshots, err := r.read(userID)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	pruned := false
+	for _, snapshot := range snapshots {
+		if snapshot.Time.Before(before) {
+			pruned = true
+			continue
+		}
+		data, err := json.Marshal(snapshot)
+		if err != nil {
+			return fmt.Errorf("failed to marshal quota snapshot: %w", err)
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	if !pruned {
+		return nil
+	}
+
+	if err := writeFileAtomic(r.historyPath(userID), buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to save quota history: %w", err)
+	}
+
+	return nil
+}
+
+// read parses all snapshots of a user in ascending time order, skipping malformed lines
+// (e.g. a line cut short by a crash while appending).
+func (r *FileQuotaHistoryRepository) read(userID string) ([]*models.QuotaSnapshot, error) {
+	file, err := os.Open(r.historyPath(userID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*models.QuotaSnapshot{}, nil
+		}
+		return nil, fmt.Errorf("failed to open quota history: %w", err)
+	}
+	defer file.Close()
+
+	snapshots := []*models.QuotaSnapshot{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		snapshot := &models.QuotaSnapshot{}
+		if err := json.Unmarshal(scanner.Bytes(), snapshot); err != nil {
+			continue
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read quota history: %w", err)
+	}
+
+	return snapshots, nil
+}
+
+// historyPath returns the quota history file of a user.
+func (r *FileQuotaHistoryRepository) historyPath(userID string) string {
+	return filepath.Join(r.baseDir, "users", userID, "quota_history.jsonl")
+}
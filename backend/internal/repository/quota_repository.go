@@ -20,8 +20,14 @@ type QuotaRepository interface {
 	GetQuota(userID string) (*models.Quota, error)
 	// CalculateUsage calculates current storage usage for a user
 	CalculateUsage(userID string) (int64, error)
+	// CalculateClientUsage calculates current storage usage of a single client
+	CalculateClientUsage(userID, clientID string) (int64, error)
 	// CountClients counts the number of clients for a user
 	CountClients(userID string) (int, error)
+	// ListUsers lists the IDs of all users with data
+	ListUsers() ([]string, error)
+	// InvalidateCache drops the cached quota of a user, e.g. after its usage changed
+	InvalidateCache(userID string)
 }
 
 // FileQuotaRepository implements QuotaRepository using file system storage.
@@ -32,6 +38,9 @@ type FileQuotaRepository struct {
 	cache             sync.Map     // Cache of quota information (key: userID, value: *quotaCache)
 	cacheTTL          time.Duration // Cache TTL
 	mu                sync.RWMutex // Mutex for thread-safe operations
+
+	softClientsPerUser int    // Soft client limit per user (0 = none)
+	coldDir            string // Cold storage data directory, counted towards usage (empty = disabled)
 }
 
 // quotaCache represents cached quota information.
@@ -50,6 +59,17 @@ func NewFileQuotaRepository(baseDir string, maxStoragePerUser int64, maxClientsP
 	}
 }
 
+// SetSoftClientLimit sets the number of clients per user above which creating clients is
+// still allowed but the user is warned (0 = no soft limit).
+func (r *FileQuotaRepository) SetSoftClientLimit(limit int) {
+	r.softClientsPerUser = limit
+}
+
+// SetColdDir makes storage usage include the events moved to a cold data directory.
+func (r *FileQuotaRepository) SetColdDir(coldDir string) {
+	r.coldDir = coldDir
+}
+
 // GetQuota retrieves quota information for a user.
 func (r *FileQuotaRepository) GetQuota(userID string) (*models.Quota, error) {
 	// Check cache first
@@ -77,17 +97,41 @@ func (r *FileQuotaRepository) GetQuota(userID string) (*models.Quota, error) {
 
 // CalculateUsage calculates current storage usage for a user.
 func (r *FileQuotaRepository) CalculateUsage(userID string) (int64, error) {
-	userDir := filepath.Join(r.baseDir, "users", userID)
+	return r.tieredSize(filepath.Join("users", userID))
+}
+
+// CalculateClientUsage calculates current storage usage of a single client
+// (configuration, events, scripts, logs and statistics).
+func (r *FileQuotaRepository) CalculateClientUsage(userID, clientID string) (int64, error) {
+	return r.tieredSize(filepath.Join("users", userID, "clients", clientID))
+}
+
+// tieredSize sums the size of a directory (relative to the data directory) in the data
+// directory and in cold storage.
+func (r *FileQuotaRepository) tieredSize(relDir string) (int64, error) {
+	size, err := dirSize(filepath.Join(r.baseDir, relDir))
+	if err != nil || r.coldDir == "" {
+		return size, err
+	}
+
+	coldSize, err := dirSize(filepath.Join(r.coldDir, relDir))
+	if err != nil {
+		return 0, err
+	}
+	return size + coldSize, nil
+}
 
-	// Check if user directory exists
-	if _, err := os.Stat(userDir); os.IsNotExist(err) {
+// dirSize sums the sizes of all files below dir; a missing directory has size 0.
+func dirSize(dir string) (int64, error) {
+	// Check if the directory exists
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
 		return 0, nil
 	}
 
 	var totalSize int64
 
-	// Walk through user directory and sum file sizes
-	err := filepath.WalkDir(userDir, func(path string, d fs.DirEntry, err error) error {
+	// Walk through the directory and sum file sizes
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
@@ -132,9 +176,30 @@ func (r *FileQuotaRepository) CountClients(userID string) (int, error) {
 	return count, nil
 }
 
+// ListUsers lists the IDs of all users with a directory in the data directory.
+func (r *FileQuotaRepository) ListUsers() ([]string, error) {
+	userDirs, err := os.ReadDir(filepath.Join(r.baseDir, "users"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, fmt.Errorf("failed to read users directory: %w", err)
+	}
+
+	userIDs := []string{}
+	for _, dir := range userDirs {
+		if dir.IsDir() {
+			userIDs = append(userIDs, dir.Name())
+		}
+	}
+
+	return userIDs, nil
+}
+
 // calculateQuota calculates fresh quota information.
 func (r *FileQuotaRepository) calculateQuota(userID string) (*models.Quota, error) {
 	quota := models.NewQuota(userID, r.maxStoragePerUser, r.maxClientsPerUser)
+	quota.SoftMaxClients = r.softClientsPerUser
 
 	// Calculate storage usage
 	usedBytes, err := r.CalculateUsage(userID)
@@ -0,0 +1,659 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/encryption"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/s3"
+)
+
+// S3EventRepository implements EventRepository on S3-compatible object storage.
+//
+// Events are stored as objects <prefix>clients/<clientID>/events/<YYYY-MM-DD>/<eventID>.json,
+// next to their replay script (<eventID>.sh). gosmee still writes new events to the local
+// data directory; UploadLocalEvents moves them to the bucket. Until then, they are served
+// from the local directory, so reads always cover both.
+//
+// Event metadata is kept in an in-memory index per client, built from the bucket on first
+// access, so listing events does not download every object.
+type S3EventRepository struct {
+	client *s3.Client
+	prefix string               // Key prefix of all objects (e.g. "gosmee/")
+	local  *FileEventRepository // Events gosmee wrote that are not uploaded yet
+	cipher *encryption.Cipher   // Encryption at rest (nil = disabled)
+	mu     sync.Mutex
+	index  map[string]map[string]*s3IndexEntry // clientID -> event ID -> entry
+}
+
+// s3IndexEntry describes an event object.
+type s3IndexEntry struct {
+	event    *models.Event // Event metadata, without headers and response, with a payload preview
+	key      string        // Object key of the event
+	modified time.Time     // Last time the object was written
+}
+
+// NewS3EventRepository creates an event repository storing events in a bucket.
+// local is the repository of the data directory gosmee writes events to.
+func NewS3EventRepository(client *s3.Client, prefix string, local *FileEventRepository) *S3EventRepository {
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return &S3EventRepository{
+		client: client,
+		prefix: prefix,
+		local:  local,
+		index:  make(map[string]map[string]*s3IndexEntry),
+	}
+}
+
+// SetCipher enables encryption at rest for objects. Unencrypted objects remain readable.
+func (r *S3EventRepository) SetCipher(cipher *encryption.Cipher) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cipher = cipher
+	r.local.SetCipher(cipher)
+}
+
+// GetByClientID retrieves events for a specific client with filters and pagination.
+func (r *S3EventRepository) GetByClientID(clientID string, req *models.EventListRequest) (*models.EventListResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	index, err := r.clientIndex(clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := r.localEvents(clientID)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range index {
+		events = append(events, entry.event)
+	}
+
+	return listEvents(events, req), nil
+}
+
+// Get retrieves a single event by ID.
+func (r *S3EventRepository) Get(clientID, eventID string) (*models.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, err := r.lookup(clientID, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return r.local.Get(clientID, eventID)
+	}
+
+	return r.readEvent(entry.key)
+}
+
+// GetScript retrieves the replay script stored next to an event.
+// It returns an error wrapping fs.ErrNotExist if the event has no script.
+func (r *S3EventRepository) GetScript(clientID, eventID string) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, err := r.lookup(clientID, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return r.local.GetScript(clientID, eventID)
+	}
+
+	data, err := r.client.Get(scriptKey(entry.key))
+	if err != nil {
+		if errors.Is(err, s3.ErrNotFound) {
+			return nil, fmt.Errorf("replay script of event %s: %w", eventID, fs.ErrNotExist)
+		}
+		return nil, err
+	}
+	return r.cipher.Open(data)
+}
+
+// SaveScript creates or replaces the replay script stored next to an event.
+func (r *S3EventRepository) SaveScript(clientID, eventID string, script []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, err := r.lookup(clientID, eventID)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		return r.local.SaveScript(clientID, eventID, script)
+	}
+
+	if err := r.writeObject(scriptKey(entry.key), script); err != nil {
+		return fmt.Errorf("failed to write replay script: %w", err)
+	}
+	return nil
+}
+
+// Save creates or overwrites an event. Events not uploaded yet are updated in the local
+// directory, new events are stored in the bucket.
+func (r *S3EventRepository) Save(clientID string, event *models.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, err := r.lookup(clientID, event.ID)
+	if err != nil {
+		return err
+	}
+	var stored *models.Event
+	if entry == nil {
+		if _, err := r.local.Get(clientID, event.ID); err == nil {
+			return r.local.Save(clientID, event)
+		}
+		if event.Timestamp.IsZero() {
+			event.Timestamp = time.Now().UTC()
+		}
+		entry = &s3IndexEntry{key: r.eventKey(clientID, event)}
+	} else {
+		stored = entry.event
+	}
+	version, err := checkEventVersion(event, stored)
+	if err != nil {
+		return err
+	}
+
+	saved := *event
+	saved.Version = version
+	if err := r.writeEvent(entry.key, &saved); err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+	event.Version = version
+	r.index[clientID][event.ID] = newS3IndexEntry(event, entry.key)
+	return nil
+}
+
+// Delete deletes an event and its replay script.
+func (r *S3EventRepository) Delete(clientID, eventID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, err := r.lookup(clientID, eventID)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		return r.local.Delete(clientID, eventID)
+	}
+
+	if err := r.client.Delete(scriptKey(entry.key)); err != nil {
+		return fmt.Errorf("failed to delete replay script: %w", err)
+	}
+	if err := r.client.Delete(entry.key); err != nil {
+		return fmt.Errorf("failed to delete event: %w", err)
+	}
+	delete(r.index[clientID], eventID)
+	return nil
+}
+
+// DeleteBatch deletes multiple events.
+func (r *S3EventRepository) DeleteBatch(clientID string, eventIDs []string) error {
+	for _, eventID := range eventIDs {
+		if err := r.Delete(clientID, eventID); err != nil {
+			// Continue deleting even if one fails
+			continue
+		}
+	}
+	return nil
+}
+
+// CleanupOldEvents removes events past their retention period.
+func (r *S3EventRepository) CleanupOldEvents(clientID string, retention models.EventRetention) (int, error) {
+	if !retention.Enabled() {
+		return 0, nil // Keep forever
+	}
+
+	removed, err := r.local.CleanupOldEvents(clientID, retention)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return removed, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	index, err := r.clientIndex(clientID)
+	if err != nil {
+		return removed, err
+	}
+
+	now := time.Now()
+	for eventID, entry := range index {
+		// Objects are grouped by date like the date directories of the local layout
+		keyDate, err := time.Parse("2006-01-02", path.Base(path.Dir(entry.key)))
+		if err != nil || !retention.Expired(entry.event.Status, keyDate, now) {
+			continue
+		}
+		if err := r.client.Delete(scriptKey(entry.key)); err != nil {
+			return removed, fmt.Errorf("failed to delete replay script: %w", err)
+		}
+		if err := r.client.Delete(entry.key); err != nil {
+			return removed, fmt.Errorf("failed to delete event: %w", err)
+		}
+		delete(index, eventID)
+		removed++
+	}
+
+	return removed, nil
+}
+
+// GetLatestEventTimestamp returns the most recent event timestamp for a client.
+func (r *S3EventRepository) GetLatestEventTimestamp(clientID string) (*time.Time, error) {
+	latest, err := r.local.GetLatestEventTimestamp(clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	index, err := r.clientIndex(clientID)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range index {
+		if latest == nil || entry.event.Timestamp.After(*latest) {
+			ts := entry.event.Timestamp
+			latest = &ts
+		}
+	}
+
+	return latest, nil
+}
+
+// RewritePayloads rewrites the payloads of local event files and event objects written
+// after since and not after until, and their replay scripts without the headers named in
+// dropHeaders. It returns the number of rewritten events.
+func (r *S3EventRepository) RewritePayloads(clientID string, since, until time.Time, rewrite func(eventID, payload string) (string, bool), dropHeaders []string) (int, error) {
+	rewritten, err := r.local.RewritePayloads(clientID, since, until, rewrite, dropHeaders)
+	if err != nil {
+		return rewritten, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries, err := r.entriesModified(clientID, since, until)
+	if err != nil {
+		return rewritten, err
+	}
+	for _, entry := range entries {
+		event, err := r.readEvent(entry.key)
+		if err != nil {
+			return rewritten, fmt.Errorf("failed to read %s: %w", entry.key, err)
+		}
+		payload, changed := rewrite(event.ID, event.Payload)
+		if !changed {
+			continue
+		}
+		original := event.Payload
+		event.Payload = payload
+		if err := r.writeEvent(entry.key, event); err != nil {
+			return rewritten, fmt.Errorf("failed to rewrite %s: %w", entry.key, err)
+		}
+		rewritten++
+		if err := r.rewriteScriptObject(scriptKey(entry.key), original, payload, dropHeaders); err != nil {
+			return rewritten, fmt.Errorf("failed to rewrite %s: %w", scriptKey(entry.key), err)
+		}
+	}
+
+	return rewritten, nil
+}
+
+// rewriteScriptObject rewrites the replay script object of an event whose payload was
+// rewritten, if it has one (see rewriteScript).
+func (r *S3EventRepository) rewriteScriptObject(key, payload, rewritten string, dropHeaders []string) error {
+	data, err := r.client.Get(key)
+	if err != nil {
+		if errors.Is(err, s3.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if data, err = r.cipher.Open(data); err != nil {
+		return err
+	}
+
+	script := rewriteScript(string(data), payload, rewritten, dropHeaders)
+	if script == string(data) {
+		return nil
+	}
+	return r.writeObject(key, []byte(script))
+}
+
+// EncryptPlaintextFiles encrypts the event files gosmee wrote in plaintext and the objects
+// uploaded before encryption was enabled, if they were written after since and not after
+// until. It returns the number of encrypted files and objects.
+func (r *S3EventRepository) EncryptPlaintextFiles(clientID string, since, until time.Time) (int, error) {
+	encrypted, err := r.local.EncryptPlaintextFiles(clientID, since, until)
+	if err != nil {
+		return encrypted, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cipher == nil {
+		return encrypted, nil
+	}
+
+	entries, err := r.entriesModified(clientID, since, until)
+	if err != nil {
+		return encrypted, err
+	}
+	for _, entry := range entries {
+		for _, key := range []string{entry.key, scriptKey(entry.key)} {
+			data, err := r.client.Get(key)
+			if err != nil {
+				if errors.Is(err, s3.ErrNotFound) {
+					continue
+				}
+				return encrypted, err
+			}
+			if encryption.IsSealed(data) {
+				continue
+			}
+			if err := r.writeObject(key, data); err != nil {
+				return encrypted, fmt.Errorf("failed to encrypt %s: %w", key, err)
+			}
+			encrypted++
+		}
+	}
+
+	return encrypted, nil
+}
+
+// MoveToCold does nothing: object storage has no cold tier.
+func (r *S3EventRepository) MoveToCold(clientID string, olderThanDays int) (int, error) {
+	return 0, nil
+}
+
+// PurgeClientEvents deletes all objects of a client, e.g. before the client is deleted.
+func (r *S3EventRepository) PurgeClientEvents(clientID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	objects, err := r.client.List(r.clientPrefix(clientID))
+	if err != nil {
+		return fmt.Errorf("failed to list client objects: %w", err)
+	}
+	for _, object := range objects {
+		if err := r.client.Delete(object.Key); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", object.Key, err)
+		}
+	}
+	delete(r.index, clientID)
+
+	return r.local.PurgeClientEvents(clientID)
+}
+
+// UploadLocalEvents moves the events of a client that gosmee wrote to the local data
+// directory to the bucket, if they were last modified before until. Raw webhook bodies
+// are stored as complete events, with the headers of their replay script. It returns the
+// number of uploaded events.
+func (r *S3EventRepository) UploadLocalEvents(clientID string, until time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	index, err := r.clientIndex(clientID)
+	if err != nil {
+		return 0, err
+	}
+
+	r.local.mu.Lock()
+	defer r.local.mu.Unlock()
+
+	eventsDir, err := r.local.getEventsDir(clientID)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	var paths []string
+	err = filepath.WalkDir(eventsDir, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			if errors.Is(walkErr, fs.ErrNotExist) {
+				return nil
+			}
+			return walkErr
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".json") {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && !info.ModTime().After(until) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to read events: %w", err)
+	}
+
+	uploaded := 0
+	for _, eventPath := range paths {
+		event, err := r.local.readEventFile(eventPath)
+		if err != nil {
+			return uploaded, fmt.Errorf("failed to read %s: %w", eventPath, err)
+		}
+		event.ClientID = clientID
+		key := r.eventKey(clientID, event)
+
+		// Upload the script first: an event object without script is still complete
+		scriptPath := strings.TrimSuffix(eventPath, ".json") + ".sh"
+		script, err := r.local.readFile(scriptPath)
+		switch {
+		case err == nil:
+			if err := r.writeObject(scriptKey(key), script); err != nil {
+				return uploaded, fmt.Errorf("failed to upload replay script of event %s: %w", event.ID, err)
+			}
+		case !errors.Is(err, fs.ErrNotExist):
+			return uploaded, fmt.Errorf("failed to read %s: %w", scriptPath, err)
+		}
+
+		if err := r.writeEvent(key, event); err != nil {
+			return uploaded, fmt.Errorf("failed to upload event %s: %w", event.ID, err)
+		}
+		index[event.ID] = newS3IndexEntry(event, key)
+
+		os.Remove(scriptPath)
+		if err := os.Remove(eventPath); err != nil {
+			return uploaded, fmt.Errorf("failed to remove uploaded event: %w", err)
+		}
+		if dir := filepath.Dir(eventPath); dir != eventsDir {
+			os.Remove(dir) // Only succeeds if it is empty now
+		}
+		uploaded++
+	}
+
+	return uploaded, nil
+}
+
+// RebuildIndex drops the index of a client's event objects and builds it again from the
+// bucket, e.g. after objects were changed outside the server. It returns the number of
+// indexed events.
+func (r *S3EventRepository) RebuildIndex(clientID string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.index, clientID)
+	index, err := r.clientIndex(clientID)
+	if err != nil {
+		return 0, err
+	}
+	return len(index), nil
+}
+
+// clientPrefix returns the key prefix of all objects of a client.
+func (r *S3EventRepository) clientPrefix(clientID string) string {
+	return r.prefix + "clients/" + clientID + "/"
+}
+
+// eventKey returns the object key of a new event.
+func (r *S3EventRepository) eventKey(clientID string, event *models.Event) string {
+	return r.clientPrefix(clientID) + "events/" + event.Timestamp.UTC().Format("2006-01-02") + "/" + event.ID + ".json"
+}
+
+// scriptKey returns the object key of the replay script of an event.
+func scriptKey(eventKey string) string {
+	return strings.TrimSuffix(eventKey, ".json") + ".sh"
+}
+
+// clientIndex returns the index of a client's event objects, building it on first access.
+// The caller must hold r.mu.
+func (r *S3EventRepository) clientIndex(clientID string) (map[string]*s3IndexEntry, error) {
+	if index, ok := r.index[clientID]; ok {
+		return index, nil
+	}
+
+	objects, err := r.client.List(r.clientPrefix(clientID) + "events/")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+
+	var eventObjects []s3.Object
+	for _, object := range objects {
+		if strings.HasSuffix(object.Key, ".json") {
+			eventObjects = append(eventObjects, object)
+		}
+	}
+
+	// Objects are downloaded concurrently, building the index is a full scan
+	entries := readConcurrently(len(eventObjects), func(i int) (*s3IndexEntry, error) {
+		event, err := r.readEvent(eventObjects[i].Key)
+		if err != nil {
+			return nil, err
+		}
+		entry := newS3IndexEntry(event, eventObjects[i].Key)
+		entry.modified = eventObjects[i].LastModified
+		return entry, nil
+	})
+
+	index := make(map[string]*s3IndexEntry)
+	for _, entry := range entries {
+		index[entry.event.ID] = entry
+	}
+
+	r.index[clientID] = index
+	return index, nil
+}
+
+// lookup returns the index entry of an event, or nil if it is not in the bucket.
+// The caller must hold r.mu.
+func (r *S3EventRepository) lookup(clientID, eventID string) (*s3IndexEntry, error) {
+	index, err := r.clientIndex(clientID)
+	if err != nil {
+		return nil, err
+	}
+	return index[eventID], nil
+}
+
+// entriesModified returns the index entries of objects written after since and not after
+// until. The caller must hold r.mu.
+func (r *S3EventRepository) entriesModified(clientID string, since, until time.Time) ([]*s3IndexEntry, error) {
+	index, err := r.clientIndex(clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*s3IndexEntry
+	for _, entry := range index {
+		if entry.modified.After(since) && !entry.modified.After(until) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// localEvents reads the events of a client that are not uploaded yet.
+func (r *S3EventRepository) localEvents(clientID string) ([]*models.Event, error) {
+	r.local.mu.RLock()
+	defer r.local.mu.RUnlock()
+
+	eventsDir, err := r.local.getEventsDir(clientID)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return r.local.allEvents(eventsDir)
+}
+
+// readEvent downloads an event object.
+func (r *S3EventRepository) readEvent(key string) (*models.Event, error) {
+	data, err := r.client.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = r.cipher.Open(data); err != nil {
+		return nil, err
+	}
+
+	var event models.Event
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, fmt.Errorf("malformed event object %s: %w", key, err)
+	}
+	if event.ID == "" {
+		event.ID = strings.TrimSuffix(path.Base(key), ".json")
+	}
+	event.ResolveDeliveryID()
+	if event.Status == "" {
+		event.Status = models.EventStatusNotReplayed
+	}
+	if event.Version == 0 {
+		event.Version = 1
+	}
+	return &event, nil
+}
+
+// writeEvent uploads an event object.
+func (r *S3EventRepository) writeEvent(key string, event *models.Event) error {
+	data, err := json.MarshalIndent(event, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	return r.writeObject(key, data)
+}
+
+// writeObject uploads an object, encrypted if encryption is enabled.
+func (r *S3EventRepository) writeObject(key string, data []byte) error {
+	data, err := r.cipher.Seal(data)
+	if err != nil {
+		return err
+	}
+	return r.client.Put(key, data)
+}
+
+// newS3IndexEntry creates the index entry of an event that was just written.
+func newS3IndexEntry(event *models.Event, key string) *s3IndexEntry {
+	meta := *event
+	meta.Fingerprint = event.ComputeFingerprint()
+	meta.Headers = nil
+	meta.Payload = event.ToSummary().Preview // The preview of a preview is the same
+	meta.PayloadEncoding = ""
+	meta.Response = ""
+	return &s3IndexEntry{event: &meta, key: key, modified: time.Now()}
+}
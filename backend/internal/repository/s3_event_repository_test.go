@@ -0,0 +1,186 @@
+package repository_test
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/s3"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+)
+
+// fakeBucket is an in-memory S3 bucket serving path-style requests for bucket "events".
+type fakeBucket struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+type fakeListResult struct {
+	XMLName  xml.Name `xml:"ListBucketResult"`
+	Contents []struct {
+		Key          string
+		Size         int
+		LastModified time.Time
+	}
+}
+
+func (b *fakeBucket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	key := strings.TrimPrefix(r.URL.Path, "/events/")
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/events":
+		var result fakeListResult
+		keys := make([]string, 0, len(b.objects))
+		for key := range b.objects {
+			if strings.HasPrefix(key, r.URL.Query().Get("prefix")) {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			result.Contents = append(result.Contents, struct {
+				Key          string
+				Size         int
+				LastModified time.Time
+			}{key, len(b.objects[key]), time.Now().UTC()})
+		}
+		xml.NewEncoder(w).Encode(result)
+	case r.Method == http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		b.objects[key] = data
+	case r.Method == http.MethodGet:
+		data, ok := b.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	case r.Method == http.MethodDelete:
+		delete(b.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (b *fakeBucket) keys() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	keys := make([]string, 0, len(b.objects))
+	for key := range b.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var _ = Describe("S3EventRepository", func() {
+	const clientID = "client-s3"
+
+	var (
+		bucket    *fakeBucket
+		server    *httptest.Server
+		client    *s3.Client
+		repo      *repository.S3EventRepository
+		baseDir   string
+		eventsDir string
+	)
+
+	BeforeEach(func() {
+		bucket = &fakeBucket{objects: map[string][]byte{}}
+		server = httptest.NewServer(bucket)
+		DeferCleanup(server.Close)
+
+		var err error
+		client, err = s3.New(s3.Config{Endpoint: server.URL, Bucket: "events", AccessKey: "key", SecretKey: "secret"})
+		Expect(err).NotTo(HaveOccurred())
+
+		baseDir = GinkgoT().TempDir()
+		eventsDir = filepath.Join(baseDir, "users", "test-user", "clients", clientID, "events")
+		Expect(os.MkdirAll(eventsDir, 0o755)).To(Succeed())
+
+		repo = repository.NewS3EventRepository(client, "gosmee", repository.NewFileEventRepository(baseDir))
+	})
+
+	It("uploads events gosmee wrote locally and serves them from the bucket", func() {
+		eventPath := filepath.Join(eventsDir, "2025-10-01T12.00.00.000.json")
+		Expect(os.WriteFile(eventPath, []byte(`{"ref":"main"}`), 0o644)).To(Succeed())
+		Expect(os.WriteFile(strings.TrimSuffix(eventPath, ".json")+".sh", []byte("curl -H 'X-GitHub-Event: push' http://target\n"), 0o755)).To(Succeed())
+
+		// Pending events are listed from the local directory
+		list, err := repo.GetByClientID(clientID, &models.EventListRequest{Page: 1, PageSize: 10})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.Total).To(Equal(1))
+
+		uploaded, err := repo.UploadLocalEvents(clientID, time.Now())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(uploaded).To(Equal(1))
+		Expect(eventPath).NotTo(BeAnExistingFile())
+		Expect(bucket.keys()).To(Equal([]string{
+			"gosmee/clients/client-s3/events/2025-10-01/2025-10-01T12.00.00.000.json",
+			"gosmee/clients/client-s3/events/2025-10-01/2025-10-01T12.00.00.000.sh",
+		}))
+
+		event, err := repo.Get(clientID, "2025-10-01T12.00.00.000")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(event.Payload).To(Equal(`{"ref":"main"}`))
+		Expect(event.Headers).To(HaveKeyWithValue("X-GitHub-Event", "push"))
+
+		script, err := repo.GetScript(clientID, event.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(script)).To(ContainSubstring("X-GitHub-Event"))
+
+		// A fresh repository rebuilds its index from the bucket
+		repo = repository.NewS3EventRepository(client, "gosmee/", repository.NewFileEventRepository(baseDir))
+		list, err = repo.GetByClientID(clientID, &models.EventListRequest{Page: 1, PageSize: 10})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.Total).To(Equal(1))
+		Expect(list.Events[0].ID).To(Equal("2025-10-01T12.00.00.000"))
+	})
+
+	It("saves, rewrites and deletes events in the bucket", func() {
+		event := &models.Event{ID: "evt-1", ClientID: clientID, Timestamp: time.Now().UTC(), Payload: `{"token":"secret"}`}
+		Expect(repo.Save(clientID, event)).To(Succeed())
+
+		event.Status = models.EventStatusSuccess
+		Expect(repo.Save(clientID, event)).To(Succeed())
+		Expect(bucket.keys()).To(HaveLen(1))
+
+		rewritten, err := repo.RewritePayloads(clientID, time.Time{}, time.Now().Add(time.Second), func(eventID, payload string) (string, bool) {
+			return strings.ReplaceAll(payload, "secret", "***"), strings.Contains(payload, "secret")
+		}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rewritten).To(Equal(1))
+
+		stored, err := repo.Get(clientID, "evt-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored.Status).To(Equal(models.EventStatusSuccess))
+		Expect(stored.Payload).To(Equal(`{"token":"***"}`))
+
+		Expect(repo.Delete(clientID, "evt-1")).To(Succeed())
+		_, err = repo.Get(clientID, "evt-1")
+		Expect(err).To(HaveOccurred())
+		Expect(bucket.keys()).To(BeEmpty())
+	})
+
+	It("purges all objects of a client", func() {
+		Expect(repo.Save(clientID, &models.Event{ID: "evt-1", ClientID: clientID, Payload: "{}"})).To(Succeed())
+		Expect(repo.Save("other-client", &models.Event{ID: "evt-2", ClientID: "other-client", Payload: "{}"})).To(Succeed())
+
+		Expect(repo.PurgeClientEvents(clientID)).To(Succeed())
+		Expect(bucket.keys()).To(HaveLen(1))
+		Expect(bucket.keys()[0]).To(HavePrefix("gosmee/clients/other-client/"))
+	})
+})
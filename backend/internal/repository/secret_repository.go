@@ -0,0 +1,111 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/encryption"
+)
+
+// SecretRepository defines the interface for client secret storage operations.
+type SecretRepository interface {
+	// Get retrieves the secrets of a client (empty when none were saved)
+	Get(userID, clientID string) (*models.ClientSecrets, error)
+	// Save stores the secrets of a client
+	Save(userID, clientID string, secrets *models.ClientSecrets) error
+}
+
+// FileSecretRepository implements SecretRepository with one file per client
+// (users/<userID>/clients/<clientID>/secrets.json in the data directory), kept apart from
+// the client configuration so secrets are never returned by the API. The file is only
+// readable by the owner and is encrypted when encryption at rest is enabled.
+type FileSecretRepository struct {
+	baseDir string             // Base data directory
+	cipher  *encryption.Cipher // Encryption at rest (nil = disabled)
+	mu      sync.RWMutex       // Mutex for thread-safe operations
+}
+
+// NewFileSecretRepository creates a new file-based secret repository.
+func NewFileSecretRepository(baseDir string) *FileSecretRepository {
+	return &FileSecretRepository{
+		baseDir: baseDir,
+	}
+}
+
+// SetCipher enables encryption at rest for secret files. Unencrypted files remain readable
+// and are encrypted the next time they are saved.
+func (r *FileSecretRepository) SetCipher(cipher *encryption.Cipher) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cipher = cipher
+}
+
+// Get retrieves the secrets of a client.
+func (r *FileSecretRepository) Get(userID, clientID string) (*models.ClientSecrets, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	secrets := &models.ClientSecrets{}
+
+	data, err := os.ReadFile(r.secretsPath(userID, clientID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return secrets, nil
+		}
+		return nil, fmt.Errorf("failed to read secrets: %w", err)
+	}
+	if data, err = r.cipher.Open(data); err != nil {
+		return nil, fmt.Errorf("failed to decrypt secrets: %w", err)
+	}
+
+	if err := json.Unmarshal(data, secrets); err != nil {
+		return nil, fmt.Errorf("failed to parse secrets: %w", err)
+	}
+
+	return secrets, nil
+}
+
+// Save stores the secrets of a client. Empty secrets remove the file.
+func (r *FileSecretRepository) Save(userID, clientID string, secrets *models.ClientSecrets) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	path := r.secretsPath(userID, clientID)
+	if secrets.IsEmpty() {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove secrets: %w", err)
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create client directory: %w", err)
+	}
+
+	data, err := json.Marshal(secrets)
+	if err != nil {
+		return fmt.Errorf("failed to marshal secrets: %w", err)
+	}
+	if data, err = r.cipher.Seal(data); err != nil {
+		return fmt.Errorf("failed to encrypt secrets: %w", err)
+	}
+
+	if err := writeFileAtomic(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to save secrets: %w", err)
+	}
+
+	return nil
+}
+
+// secretsPath returns the path of a client's secrets file.
+func (r *FileSecretRepository) secretsPath(userID, clientID string) string {
+	return filepath.Join(r.baseDir, "users", userID, "clients", clientID, "secrets.json")
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+)
+
+// ServiceAccountRepository defines the interface for service account storage operations.
+type ServiceAccountRepository interface {
+	// Create stores a new service account
+	Create(account *models.ServiceAccount) error
+	// Get retrieves a service account by ID
+	Get(id string) (*models.ServiceAccount, error)
+	// GetByTokenHash retrieves the service account owning a token hash
+	GetByTokenHash(tokenHash string) (*models.ServiceAccount, error)
+	// List retrieves all service accounts, oldest first
+	List() ([]*models.ServiceAccount, error)
+	// Update updates an existing service account
+	Update(account *models.ServiceAccount) error
+	// Delete deletes a service account by ID
+	Delete(id string) error
+}
+
+// FileServiceAccountRepository implements ServiceAccountRepository with a single JSON file
+// (service_accounts.json in the data directory), kept in memory for token lookups.
+type FileServiceAccountRepository struct {
+	path     string                            // Path to the accounts file
+	accounts map[string]*models.ServiceAccount // Accounts by ID
+	mu       sync.RWMutex                      // Mutex for thread-safe operations
+}
+
+// serviceAccountRecord is the persisted form of a service account, including the token hash.
+type serviceAccountRecord struct {
+	*models.ServiceAccount
+	TokenHash string `json:"tokenHash"`
+}
+
+// NewFileServiceAccountRepository creates a file-based service account repository,
+// loading existing accounts from disk.
+func NewFileServiceAccountRepository(baseDir string) (*FileServiceAccountRepository, error) {
+	r := &FileServiceAccountRepository{
+		path:     filepath.Join(baseDir, "service_accounts.json"),
+		accounts: make(map[string]*models.ServiceAccount),
+	}
+
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return r, nil
+		}
+		return nil, fmt.Errorf("failed to read service accounts: %w", err)
+	}
+
+	var records []serviceAccountRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to parse service accounts: %w", err)
+	}
+	for _, record := range records {
+		record.ServiceAccount.TokenHash = record.TokenHash
+		r.accounts[record.ID] = record.ServiceAccount
+	}
+
+	return r, nil
+}
+
+// Create stores a new service account.
+func (r *FileServiceAccountRepository) Create(account *models.ServiceAccount) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.accounts[account.ID]; exists {
+		return fmt.Errorf("service account already exists: %s", account.ID)
+	}
+
+	stored := *account
+	r.accounts[account.ID] = &stored
+	if err := r.save(); err != nil {
+		delete(r.accounts, account.ID)
+		return err
+	}
+	return nil
+}
+
+// Get retrieves a service account by ID.
+func (r *FileServiceAccountRepository) Get(id string) (*models.ServiceAccount, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	account, exists := r.accounts[id]
+	if !exists {
+		return nil, fmt.Errorf("service account not found: %s", id)
+	}
+
+	snapshot := *account
+	return &snapshot, nil
+}
+
+// GetByTokenHash retrieves the service account owning a token hash.
+func (r *FileServiceAccountRepository) GetByTokenHash(tokenHash string) (*models.ServiceAccount, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, account := range r.accounts {
+		if account.TokenHash == tokenHash {
+			snapshot := *account
+			return &snapshot, nil
+		}
+	}
+
+	return nil, fmt.Errorf("service account not found for token")
+}
+
+// List retrieves all service accounts, oldest first.
+func (r *FileServiceAccountRepository) List() ([]*models.ServiceAccount, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	accounts := make([]*models.ServiceAccount, 0, len(r.accounts))
+	for _, account := range r.accounts {
+		snapshot := *account
+		accounts = append(accounts, &snapshot)
+	}
+
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].CreatedAt.Before(accounts[j].CreatedAt)
+	})
+
+	return accounts, nil
+}
+
+// Update updates an existing service account.
+func (r *FileServiceAccountRepository) Update(account *models.ServiceAccount) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous, exists := r.accounts[account.ID]
+	if !exists {
+		return fmt.Errorf("service account not found: %s", account.ID)
+	}
+
+	stored := *account
+	r.accounts[account.ID] = &stored
+	if err := r.save(); err != nil {
+		r.accounts[account.ID] = previous
+		return err
+	}
+	return nil
+}
+
+// Delete deletes a service account by ID.
+func (r *FileServiceAccountRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous, exists := r.accounts[id]
+	if !exists {
+		return fmt.Errorf("service account not found: %s", id)
+	}
+
+	delete(r.accounts, id)
+	if err := r.save(); err != nil {
+		r.accounts[id] = previous
+		return err
+	}
+	return nil
+}
+
+// save writes all accounts to disk atomically. Caller must hold the write lock.
+func (r *FileServiceAccountRepository) save() error {
+	records := make([]serviceAccountRecord, 0, len(r.accounts))
+	for _, account := range r.accounts {
+		records = append(records, serviceAccountRecord{ServiceAccount: account, TokenHash: account.TokenHash})
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].CreatedAt.Before(records[j].CreatedAt)
+	})
+
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal service accounts: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	if err := writeFileAtomic(r.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write service accounts: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+)
+
+// SettingsRepository defines the interface for user settings storage operations.
+type SettingsRepository interface {
+	// Get retrieves the settings of a user (defaults when none were saved)
+	Get(userID string) (*models.UserSettings, error)
+	// Save stores the settings of a user
+	Save(settings *models.UserSettings) error
+}
+
+// FileSettingsRepository implements SettingsRepository with one JSON file per user
+// (users/<userID>/settings.json in the data directory).
+type FileSettingsRepository struct {
+	baseDir string       // Base data directory
+	mu      sync.RWMutex // Mutex for thread-safe operations
+}
+
+// NewFileSettingsRepository creates a new file-based settings repository.
+func NewFileSettingsRepository(baseDir string) *FileSettingsRepository {
+	return &FileSettingsRepository{
+		baseDir: baseDir,
+	}
+}
+
+// Get retrieves the settings of a user.
+func (r *FileSettingsRepository) Get(userID string) (*models.UserSettings, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	settings := &models.UserSettings{UserID: userID}
+
+	data, err := os.ReadFile(r.settingsPath(userID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return settings, nil
+		}
+		return nil, fmt.Errorf("failed to read settings: %w", err)
+	}
+
+	if err := json.Unmarshal(data, settings); err != nil {
+		return nil, fmt.Errorf("failed to parse settings: %w", err)
+	}
+	settings.UserID = userID
+
+	return settings, nil
+}
+
+// Save stores the settings of a user.
+func (r *FileSettingsRepository) Save(settings *models.UserSettings) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	path := r.settingsPath(settings.UserID)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create user directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal settings: %w", err)
+	}
+
+	if err := writeFileAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to save settings: %w", err)
+	}
+
+	return nil
+}
+
+// settingsPath returns the path of a user's settings file.
+func (r *FileSettingsRepository) settingsPath(userID string) string {
+	return filepath.Join(r.baseDir, "users", userID, "settings.json")
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+)
+
+// StatsRepository defines the interface for daily statistics rollup storage.
+type StatsRepository interface {
+	// Get retrieves the rollup of a day (nil if it was not written)
+	Get(userID, clientID, date string) (*models.DailyStats, error)
+	// List retrieves the rollups of a client within [from, to] (YYYY-MM-DD, empty = unbounded)
+	List(userID, clientID, from, to string) ([]*models.DailyStats, error)
+	// Save creates or replaces the rollup of a day
+	Save(userID string, stats *models.DailyStats) error
+}
+
+// FileStatsRepository implements StatsRepository with one JSON file per client and day
+// (users/<userID>/clients/<clientID>/stats/YYYY-MM-DD.json in the data directory).
+type FileStatsRepository struct {
+	baseDir string       // Base data directory
+	mu      sync.RWMutex // Mutex for thread-safe operations
+}
+
+// NewFileStatsRepository creates a new file-based statistics repository.
+func NewFileStatsRepository(baseDir string) *FileStatsRepository {
+	return &FileStatsRepository{
+		baseDir: baseDir,
+	}
+}
+
+// Get retrieves the rollup of a day, or nil if it was not written.
+func (r *FileStatsRepository) Get(userID, clientID, date string) (*models.DailyStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	data, err := os.ReadFile(filepath.Join(r.statsDir(userID, clientID), date+".json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read stats of %s: %w", date, err)
+	}
+
+	stats := &models.DailyStats{}
+	if err := json.Unmarshal(data, stats); err != nil {
+		return nil, fmt.Errorf("failed to parse stats of %s: %w", date, err)
+	}
+	return stats, nil
+}
+
+// List retrieves the rollups of a client within [from, to], in ascending date order.
+func (r *FileStatsRepository) List(userID, clientID, from, to string) ([]*models.DailyStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	dir := r.statsDir(userID, clientID)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*models.DailyStats{}, nil
+		}
+		return nil, fmt.Errorf("failed to read stats directory: %w", err)
+	}
+
+	days := []*models.DailyStats{}
+	for _, entry := range entries {
+		date, ok := strings.CutSuffix(entry.Name(), ".json")
+		if entry.IsDir() || !ok || (from != "" && date < from) || (to != "" && date > to) {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read stats of %s: %w", date, err)
+		}
+		stats := &models.DailyStats{}
+		if err := json.Unmarshal(data, stats); err != nil {
+			return nil, fmt.Errorf("failed to parse stats of %s: %w", date, err)
+		}
+		days = append(days, stats)
+	}
+
+	sort.Slice(days, func(i, j int) bool { return days[i].Date < days[j].Date })
+	return days, nil
+}
+
+// Save creates or replaces the rollup of a day.
+func (r *FileStatsRepository) Save(userID string, stats *models.DailyStats) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	dir := r.statsDir(userID, stats.ClientID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create stats directory: %w", err)
+	}
+
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return fmt.Errorf("failed to marshal stats: %w", err)
+	}
+
+	if err := writeFileAtomic(filepath.Join(dir, stats.Date+".json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to save stats: %w", err)
+	}
+
+	return nil
+}
+
+// statsDir returns the rollup directory of a client.
+func (r *FileStatsRepository) statsDir(userID, clientID string) string {
+	return filepath.Join(r.baseDir, "users", userID, "clients", clientID, "stats")
+}
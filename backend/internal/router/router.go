@@ -5,22 +5,36 @@
 package router
 
 import (
-	"net/http"
-
 	"github.com/gin-gonic/gin"
 	"github.com/lazycatapps/gosmee/backend/internal/handler"
 	"github.com/lazycatapps/gosmee/backend/internal/middleware"
+	"github.com/lazycatapps/gosmee/backend/internal/models"
 	"github.com/lazycatapps/gosmee/backend/internal/types"
 )
 
 // Router manages HTTP request routing and handler registration.
 type Router struct {
-	clientHandler    *handler.ClientHandler
-	logHandler       *handler.LogHandler
-	eventHandler     *handler.EventHandler
-	quotaHandler     *handler.QuotaHandler
-	authHandler      *handler.AuthHandler
-	sessionValidator middleware.SessionValidator
+	clientHandler         *handler.ClientHandler
+	logHandler            *handler.LogHandler
+	eventHandler          *handler.EventHandler
+	quotaHandler          *handler.QuotaHandler
+	jobHandler            *handler.JobHandler
+	notificationHandler   *handler.NotificationHandler
+	serviceAccountHandler *handler.ServiceAccountHandler
+	orphanHandler         *handler.OrphanHandler
+	settingsHandler       *handler.SettingsHandler
+	statsHandler          *handler.StatsHandler
+	reindexHandler        *handler.ReindexHandler
+	bundleHandler         *handler.ClientBundleHandler
+	impersonationHandler  *handler.ImpersonationHandler
+	offboardingHandler    *handler.UserOffboardingHandler
+	healthHandler         *handler.HealthHandler
+	authHandler           *handler.AuthHandler
+	sessionValidator      middleware.SessionValidator
+	tokenValidator        middleware.TokenValidator
+	authorizer            *middleware.Authorizer
+	impersonationAuditor  middleware.ImpersonationAuditor
+	frontendHandler       *handler.FrontendHandler // Optional, serves the embedded frontend
 }
 
 // New creates a new Router instance with the provided handlers.
@@ -29,90 +43,236 @@ func New(
 	logHandler *handler.LogHandler,
 	eventHandler *handler.EventHandler,
 	quotaHandler *handler.QuotaHandler,
+	jobHandler *handler.JobHandler,
+	notificationHandler *handler.NotificationHandler,
+	serviceAccountHandler *handler.ServiceAccountHandler,
+	orphanHandler *handler.OrphanHandler,
+	settingsHandler *handler.SettingsHandler,
+	statsHandler *handler.StatsHandler,
+	reindexHandler *handler.ReindexHandler,
+	bundleHandler *handler.ClientBundleHandler,
+	impersonationHandler *handler.ImpersonationHandler,
+	offboardingHandler *handler.UserOffboardingHandler,
+	healthHandler *handler.HealthHandler,
 	authHandler *handler.AuthHandler,
 	sessionValidator middleware.SessionValidator,
+	tokenValidator middleware.TokenValidator,
+	authorizer *middleware.Authorizer,
+	impersonationAuditor middleware.ImpersonationAuditor,
+	frontendHandler *handler.FrontendHandler,
 ) *Router {
 	return &Router{
-		clientHandler:    clientHandler,
-		logHandler:       logHandler,
-		eventHandler:     eventHandler,
-		quotaHandler:     quotaHandler,
-		authHandler:      authHandler,
-		sessionValidator: sessionValidator,
+		clientHandler:         clientHandler,
+		logHandler:            logHandler,
+		eventHandler:          eventHandler,
+		quotaHandler:          quotaHandler,
+		jobHandler:            jobHandler,
+		notificationHandler:   notificationHandler,
+		serviceAccountHandler: serviceAccountHandler,
+		orphanHandler:         orphanHandler,
+		settingsHandler:       settingsHandler,
+		statsHandler:          statsHandler,
+		reindexHandler:        reindexHandler,
+		bundleHandler:         bundleHandler,
+		impersonationHandler:  impersonationHandler,
+		offboardingHandler:    offboardingHandler,
+		healthHandler:         healthHandler,
+		authHandler:           authHandler,
+		sessionValidator:      sessionValidator,
+		tokenValidator:        tokenValidator,
+		authorizer:            authorizer,
+		impersonationAuditor:  impersonationAuditor,
+		frontendHandler:       frontendHandler,
 	}
 }
 
 // Setup initializes the Gin engine with middleware and routes.
 func (r *Router) Setup(cfg *types.Config) *gin.Engine {
 	engine := gin.New()
-	engine.Use(gin.Logger())
+	engine.Use(middleware.RequestID())
+	engine.Use(middleware.AccessLog())
 	engine.Use(gin.Recovery())
 	engine.Use(middleware.CORS(cfg.CORS.AllowedOrigins))
-	engine.Use(middleware.Auth(cfg.OIDC.Enabled, r.sessionValidator))
+	if cfg.Server.Compression {
+		engine.Use(middleware.Compress(cfg.Server.CompressionMinSize))
+	}
+	engine.Use(middleware.Auth(cfg.OIDC.Enabled, r.sessionValidator, r.tokenValidator))
+	engine.Use(r.authorizer.Impersonate(r.impersonationAuditor))
+	engine.Use(middleware.BodyLimit(cfg.Server.MaxBodySize, map[string]int64{
+		// Injected events carry full webhook payloads
+		"/api/v1/clients/:id/events": cfg.Server.MaxEventBodySize,
+		// Bundles carry the events and logs of a whole client
+		"/api/v1/clients/import-bundle": cfg.Server.MaxBundleSize,
+	}))
 
 	// Disable trusted proxy feature for security
 	engine.SetTrustedProxies(nil)
 
 	r.registerRoutes(engine)
 
+	// Everything not matched by an API route is part of the single-page frontend
+	if r.frontendHandler != nil {
+		engine.NoRoute(r.frontendHandler.Serve)
+	}
+
 	return engine
 }
 
 // registerRoutes registers all API routes under /api/v1 prefix.
+// Protected routes belong to a route group, whose access the authorization policy grants
+// by role, and declare the scope a service account token needs to call them.
 func (r *Router) registerRoutes(engine *gin.Engine) {
+	scope := middleware.RequireScope
+	require := r.authorizer.Require
+
 	api := engine.Group("/api/v1")
 	{
 		// Public endpoints
-		api.GET("/health", r.healthCheck)
+		api.GET("/health", r.healthHandler.Check)
 
 		// Auth endpoints
 		auth := api.Group("/auth")
 		{
+			auth.GET("/providers", r.authHandler.Providers)
 			auth.GET("/login", r.authHandler.Login)
 			auth.GET("/callback", r.authHandler.Callback)
 			auth.POST("/logout", r.authHandler.Logout)
 			auth.GET("/userinfo", r.authHandler.UserInfo)
+
+			sessions := auth.Group("/sessions", require(middleware.RouteGroupSessions))
+			{
+				sessions.GET("", r.authHandler.ListSessions)
+				sessions.DELETE("", r.authHandler.RevokeAllSessions)
+				sessions.DELETE("/:sessionId", r.authHandler.RevokeSession)
+			}
 		}
 
 		// Protected endpoints (require auth if OIDC enabled)
+		user := api.Group("", require(middleware.RouteGroupUser))
 
 		// Client management endpoints
-		api.POST("/clients", r.clientHandler.Create)
-		api.GET("/clients", r.clientHandler.List)
-		api.GET("/clients/:id", r.clientHandler.Get)
-		api.PUT("/clients/:id", r.clientHandler.Update)
-		api.DELETE("/clients/:id", r.clientHandler.Delete)
-
-		// Client control endpoints
-		api.POST("/clients/batch/start", r.clientHandler.BatchStart)
-		api.POST("/clients/batch/stop", r.clientHandler.BatchStop)
-		api.POST("/clients/:id/start", r.clientHandler.Start)
-		api.POST("/clients/:id/stop", r.clientHandler.Stop)
-		api.POST("/clients/:id/restart", r.clientHandler.Restart)
-
-		// Client stats endpoints
-		api.GET("/clients/:id/stats", r.clientHandler.GetStats)
-
-		// Log endpoints
-		api.GET("/clients/:id/logs", r.logHandler.GetLogs)
-		api.GET("/clients/:id/logs/stream", r.logHandler.StreamLogs)
-		api.GET("/clients/:id/logs/download", r.logHandler.DownloadLog)
-
-		// Event endpoints
-		api.GET("/clients/:id/events", r.eventHandler.List)
-		api.GET("/clients/:id/events/:eventId", r.eventHandler.Get)
-		api.DELETE("/clients/:id/events/:eventId", r.eventHandler.Delete)
-		api.POST("/clients/:id/events/replay", r.eventHandler.Replay)
+		user.POST("/clients", scope(models.ScopeClientsWrite), r.clientHandler.Create)
+		user.POST("/clients/validate", scope(models.ScopeClientsWrite), r.clientHandler.Validate)
+		user.GET("/clients", scope(models.ScopeClientsRead), r.clientHandler.List)
+		user.GET("/clients/export", scope(models.ScopeClientsRead), r.clientHandler.Export)
+		user.POST("/clients/import-bundle", scope(models.ScopeClientsWrite), scope(models.ScopeEventsWrite), r.bundleHandler.Import)
+		user.GET("/clients/ignore-event-presets", scope(models.ScopeClientsRead), r.clientHandler.IgnoreEventPresets)
+
+		// Batch control endpoints
+		user.POST("/clients/batch/start", scope(models.ScopeClientsStart), r.clientHandler.BatchStart)
+		user.POST("/clients/batch/stop", scope(models.ScopeClientsStop), r.clientHandler.BatchStop)
+		user.POST("/clients/batch/rolling-restart", scope(models.ScopeClientsStart), r.clientHandler.RollingRestart)
+
+		// Client-scoped endpoints (only accessible to the client's owner)
+		client := user.Group("/clients/:id", r.clientHandler.RequireOwner)
+		{
+			client.GET("", scope(models.ScopeClientsRead), r.clientHandler.Get)
+			client.PUT("", scope(models.ScopeClientsWrite), r.clientHandler.Update)
+			client.PATCH("", scope(models.ScopeClientsWrite), r.clientHandler.Patch)
+			client.DELETE("", scope(models.ScopeClientsWrite), r.clientHandler.Delete)
+			client.GET("/bundle", scope(models.ScopeClientsRead), scope(models.ScopeEventsRead), r.bundleHandler.Export)
+
+			// Client control endpoints
+			client.POST("/start", scope(models.ScopeClientsStart), r.clientHandler.Start)
+			client.POST("/stop", scope(models.ScopeClientsStop), r.clientHandler.Stop)
+			client.POST("/restart", scope(models.ScopeClientsStart), r.clientHandler.Restart)
+			client.POST("/pause", scope(models.ScopeClientsStop), r.clientHandler.Pause)
+			client.POST("/resume", scope(models.ScopeClientsStart), r.clientHandler.Resume)
+
+			// Client stats endpoints
+			client.GET("/stats", scope(models.ScopeClientsRead), r.clientHandler.GetStats)
+			client.GET("/stats/daily", scope(models.ScopeClientsRead), r.statsHandler.GetDaily)
+			client.GET("/stats/query", scope(models.ScopeClientsRead), r.statsHandler.Query)
+			client.GET("/stats/heatmap", scope(models.ScopeClientsRead), r.statsHandler.Heatmap)
+
+			// Log endpoints
+			client.GET("/logs", scope(models.ScopeLogsRead), r.logHandler.GetLogs)
+			client.GET("/logs/stream", scope(models.ScopeLogsRead), r.logHandler.StreamLogs)
+			client.GET("/logs/download", scope(models.ScopeLogsRead), r.logHandler.DownloadLog)
+			client.GET("/logs/last-run", scope(models.ScopeLogsRead), r.logHandler.GetLastRun)
+			client.GET("/logs/annotations", scope(models.ScopeLogsRead), r.logHandler.ListAnnotations)
+			client.POST("/logs/annotations", scope(models.ScopeLogsWrite), r.logHandler.AddAnnotation)
+			client.DELETE("/logs/annotations/:annotationId", scope(models.ScopeLogsWrite), r.logHandler.DeleteAnnotation)
+
+			// Event endpoints
+			client.GET("/events", scope(models.ScopeEventsRead), r.eventHandler.List)
+			client.GET("/events/stream", scope(models.ScopeEventsRead), r.eventHandler.StreamEvents)
+			client.GET("/events/unacknowledged", scope(models.ScopeEventsRead), r.eventHandler.AckReport)
+			client.POST("/events", scope(models.ScopeEventsWrite), r.eventHandler.Inject)
+			client.GET("/events/:eventId", scope(models.ScopeEventsRead), r.eventHandler.Get)
+			client.GET("/events/:eventId/download", scope(models.ScopeEventsRead), r.eventHandler.Download)
+			client.POST("/events/:eventId/script", scope(models.ScopeEventsWrite), r.eventHandler.RegenerateScript)
+			client.DELETE("/events/:eventId", scope(models.ScopeEventsWrite), r.eventHandler.Delete)
+			client.POST("/events/replay", scope(models.ScopeEventsWrite), r.eventHandler.Replay)
+			client.POST("/events/replay-failed", scope(models.ScopeEventsWrite), r.eventHandler.ReplayFailed)
+			client.POST("/events/deduplicate", scope(models.ScopeEventsWrite), r.eventHandler.Deduplicate)
+
+			// Delivery circuit breaker endpoints
+			client.GET("/circuit", scope(models.ScopeClientsRead), r.eventHandler.GetCircuit)
+			client.POST("/circuit/reset", scope(models.ScopeClientsWrite), r.eventHandler.ResetCircuit)
+		}
+
+		// Logs of several of the user's clients
+		user.GET("/logs", scope(models.ScopeLogsRead), r.logHandler.GetMergedLogs)
+		user.GET("/logs/stream", scope(models.ScopeLogsRead), r.logHandler.StreamMergedLogs)
+
+		// Event lookup across the user's clients
+		user.GET("/events/by-delivery/:deliveryId", scope(models.ScopeEventsRead), r.eventHandler.FindByDelivery)
+
+		// Job endpoints
+		user.GET("/jobs", scope(models.ScopeJobsRead), r.jobHandler.List)
+		user.GET("/jobs/:jobId", scope(models.ScopeJobsRead), r.jobHandler.Get)
+
+		// Notification endpoints
+		user.GET("/notifications", scope(models.ScopeNotificationsRead), r.notificationHandler.List)
+		user.POST("/notifications/read-all", scope(models.ScopeNotificationsRead), r.notificationHandler.MarkAllRead)
+		user.POST("/notifications/:notificationId/read", scope(models.ScopeNotificationsRead), r.notificationHandler.MarkRead)
+
+		// Aggregate statistics endpoints
+		user.GET("/stats/overview", scope(models.ScopeClientsRead), r.statsHandler.Overview)
+
+		// Metrics endpoint (Prometheus text format)
+		api.GET("/metrics", require(middleware.RouteGroupMetrics), scope(models.ScopeMetricsRead), r.statsHandler.Metrics)
 
 		// Quota endpoints
-		api.GET("/quota", r.quotaHandler.GetQuota)
-	}
-}
+		user.GET("/quota", scope(models.ScopeQuotaRead), r.quotaHandler.GetQuota)
+		user.GET("/quota/history", scope(models.ScopeQuotaRead), r.quotaHandler.GetHistory)
+
+		// Settings endpoints
+		user.GET("/settings/masking", scope(models.ScopeSettingsRead), r.settingsHandler.GetMasking)
+		user.PUT("/settings/masking", scope(models.ScopeSettingsWrite), r.settingsHandler.UpdateMasking)
+		user.GET("/settings/quota", scope(models.ScopeSettingsRead), r.quotaHandler.GetSettings)
+		user.PUT("/settings/quota", scope(models.ScopeSettingsWrite), r.quotaHandler.UpdateSettings)
+
+		// Admin endpoints (administrators only, never service accounts by default)
+		admin := api.Group("/admin", require(middleware.RouteGroupAdmin))
+		{
+			admin.GET("/service-accounts", r.serviceAccountHandler.List)
+			admin.POST("/service-accounts", r.serviceAccountHandler.Create)
+			admin.POST("/service-accounts/:accountId/rotate", r.serviceAccountHandler.Rotate)
+			admin.DELETE("/service-accounts/:accountId", r.serviceAccountHandler.Delete)
+
+			// Client data no client tracks, e.g. left behind by partial deletes
+			admin.GET("/orphans", r.orphanHandler.List)
+			admin.DELETE("/orphans/:userId/:clientId", r.orphanHandler.Clean)
+			admin.POST("/orphans/:userId/:clientId/adopt", r.orphanHandler.Adopt)
+
+			// gosmee processes not managed by the backend, e.g. left running by a crashed backend
+			admin.GET("/processes/unmanaged", r.clientHandler.ListUnmanagedProcesses)
+
+			// Rebuild of the indices and rollups derived from the raw event files
+			admin.POST("/reindex", r.reindexHandler.Reindex)
 
-// healthCheck returns a simple health status.
-func (r *Router) healthCheck(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"status":  "healthy",
-		"service": "gosmee-webui",
-	})
+			// Audit log of requests made while impersonating users
+			admin.GET("/impersonations", r.impersonationHandler.List)
+
+			// Offboarding of users: clients, data, sessions and service accounts
+			admin.DELETE("/users/:userId", r.offboardingHandler.Offboard)
+
+			// Free space of the data volumes and notifications about the server
+			admin.GET("/storage", r.healthHandler.Storage)
+			admin.GET("/notifications", r.notificationHandler.ListAdmin)
+			admin.POST("/notifications/:notificationId/read", r.notificationHandler.MarkAdminRead)
+		}
+	}
 }
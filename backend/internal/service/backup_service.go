@@ -0,0 +1,492 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+)
+
+// backupManifestName is the first entry of every export archive.
+const backupManifestName = "gosmee-export.json"
+
+// backupFormatVersion is the archive layout version written by Export.
+const backupFormatVersion = 1
+
+// serviceAccountsFile is the server-wide file exported alongside full backups.
+const serviceAccountsFile = "service_accounts.json"
+
+// backupColdPrefix is the archive directory holding the files of the cold data directory,
+// in the same layout as the data directory.
+const backupColdPrefix = "cold"
+
+// BackupManifest describes the content of an export archive.
+type BackupManifest struct {
+	Version   int       `json:"version"`          // Archive layout version
+	CreatedAt time.Time `json:"createdAt"`        // Export time
+	UserID    string    `json:"userId,omitempty"` // Exported user (empty for a full export)
+	Users     []string  `json:"users"`            // Users contained in the archive
+	Clients   int       `json:"clients"`          // Number of clients contained in the archive
+}
+
+// ImportOptions controls how an archive is restored.
+type ImportOptions struct {
+	UserID    string // Only import this user (optional, default: all users in the archive)
+	Overwrite bool   // Replace existing clients and files instead of failing
+}
+
+// ImportResult summarizes a completed import.
+type ImportResult struct {
+	Users   []string `json:"users"`   // Imported users
+	Clients int      `json:"clients"` // Number of imported clients
+	Files   int      `json:"files"`   // Number of restored files
+}
+
+// BackupService exports and imports the data directory as tar.gz archives.
+// It operates on the files directly, so imports must run while the server is stopped.
+// Events in S3 object storage are not part of the data directory and not backed up.
+type BackupService struct {
+	baseDir string
+	coldDir string // Cold storage data directory (empty = disabled)
+	log     logger.Logger
+}
+
+// NewBackupService creates a new backup service for the data directory baseDir.
+func NewBackupService(baseDir string, log logger.Logger) *BackupService {
+	return &BackupService{
+		baseDir: baseDir,
+		log:     log,
+	}
+}
+
+// SetColdDir makes exports include the events moved to the cold data directory, and imports
+// restore them there. Without a cold data directory, imported cold events are restored to
+// the data directory.
+func (s *BackupService) SetColdDir(coldDir string) {
+	s.coldDir = coldDir
+}
+
+// Export writes a tar.gz archive of one user's data, or of all users plus the service
+// accounts when userID is empty.
+func (s *BackupService) Export(w io.Writer, userID string) (*BackupManifest, error) {
+	users, err := s.listUsers()
+	if err != nil {
+		return nil, err
+	}
+	if userID != "" {
+		if !isSafePathElement(userID) || !containsString(users, userID) {
+			return nil, fmt.Errorf("user not found: %s", userID)
+		}
+		users = []string{userID}
+	}
+
+	manifest := &BackupManifest{
+		Version:   backupFormatVersion,
+		CreatedAt: time.Now().UTC(),
+		UserID:    userID,
+		Users:     users,
+	}
+	for _, user := range users {
+		clientIDs, err := s.listClientDirs(filepath.Join(s.baseDir, "users", user, "clients"))
+		if err != nil {
+			return nil, err
+		}
+		manifest.Clients += len(clientIDs)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    backupManifestName,
+		Mode:    0644,
+		Size:    int64(len(manifestData)),
+		ModTime: manifest.CreatedAt,
+	}); err != nil {
+		return nil, err
+	}
+	if _, err := tw.Write(manifestData); err != nil {
+		return nil, err
+	}
+
+	for _, user := range users {
+		if err := addTree(tw, s.baseDir, "", filepath.Join("users", user)); err != nil {
+			return nil, err
+		}
+		if s.coldDir == "" {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(s.coldDir, "users", user)); err == nil {
+			if err := addTree(tw, s.coldDir, backupColdPrefix, filepath.Join("users", user)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if userID == "" {
+		if _, err := os.Stat(filepath.Join(s.baseDir, serviceAccountsFile)); err == nil {
+			if err := addTree(tw, s.baseDir, "", serviceAccountsFile); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish archive: %w", err)
+	}
+
+	s.log.Info("Exported %d users with %d clients", len(users), manifest.Clients)
+	return manifest, nil
+}
+
+// addTree adds a file or directory (relative to dataDir) to the archive below prefix.
+// Symbolic links, other special files and PID files are skipped.
+func addTree(tw *tar.Writer, dataDir, prefix, relPath string) error {
+	root := filepath.Join(dataDir, relPath)
+	return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && (!d.Type().IsRegular() || d.Name() == clientPIDFile) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dataDir, p)
+		if err != nil {
+			return err
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = path.Join(prefix, filepath.ToSlash(rel))
+		if d.IsDir() {
+			header.Name += "/"
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		file, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		if _, err := io.Copy(tw, file); err != nil {
+			return fmt.Errorf("failed to archive %s: %w", rel, err)
+		}
+		return nil
+	})
+}
+
+// Import restores an archive created by Export into the data directory.
+// The archive is extracted to a staging directory first; existing clients (matched by ID,
+// across all users) are only replaced with opts.Overwrite, otherwise nothing is imported.
+func (s *BackupService) Import(r io.Reader, opts ImportOptions) (*ImportResult, error) {
+	if err := os.MkdirAll(s.baseDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+	staging, err := os.MkdirTemp(s.baseDir, ".import-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	manifest, files, err := extractBackup(r, staging, opts.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if opts.UserID != "" && !containsString(manifest.Users, opts.UserID) {
+		return nil, fmt.Errorf("user %s is not contained in the archive", opts.UserID)
+	}
+
+	// Collect the clients to import and check for conflicts before touching the data directory
+	existing, err := s.clientOwners()
+	if err != nil {
+		return nil, err
+	}
+	type clientMove struct{ userID, clientID string }
+	var moves []clientMove
+	users, err := s.listUsersIn(staging)
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		clientIDs, err := s.listClientDirs(filepath.Join(staging, "users", user, "clients"))
+		if err != nil {
+			return nil, err
+		}
+		for _, clientID := range clientIDs {
+			if owner, exists := existing[clientID]; exists && !opts.Overwrite {
+				return nil, fmt.Errorf("client %s already exists (user %s); use overwrite to replace it", clientID, owner)
+			}
+			moves = append(moves, clientMove{userID: user, clientID: clientID})
+		}
+	}
+
+	result := &ImportResult{Users: users, Files: files}
+	for _, move := range moves {
+		if owner, exists := existing[move.clientID]; exists {
+			for _, dataDir := range s.dataDirs() {
+				if err := os.RemoveAll(filepath.Join(dataDir, "users", owner, "clients", move.clientID)); err != nil {
+					return result, fmt.Errorf("failed to remove existing client %s: %w", move.clientID, err)
+				}
+			}
+		}
+		rel := filepath.Join("users", move.userID, "clients", move.clientID)
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(s.baseDir, rel)), 0755); err != nil {
+			return result, err
+		}
+		if err := os.Rename(filepath.Join(staging, rel), filepath.Join(s.baseDir, rel)); err != nil {
+			return result, fmt.Errorf("failed to restore client %s: %w", move.clientID, err)
+		}
+		result.Clients++
+	}
+
+	// Restore the remaining files (outside client directories, and the cold tier)
+	err = filepath.WalkDir(staging, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(staging, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(s.baseDir, rel)
+		if coldRel, ok := strings.CutPrefix(filepath.ToSlash(rel), backupColdPrefix+"/"); ok {
+			target = filepath.Join(s.baseDir, filepath.FromSlash(coldRel))
+			if s.coldDir != "" {
+				target = filepath.Join(s.coldDir, filepath.FromSlash(coldRel))
+			}
+		}
+		if _, err := os.Stat(target); err == nil && !opts.Overwrite {
+			s.log.Info("Skipping existing file %s", rel)
+			return nil
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		return os.Rename(p, target)
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to restore files: %w", err)
+	}
+
+	s.log.Info("Imported %d clients of %d users", result.Clients, len(result.Users))
+	return result, nil
+}
+
+// extractBackup validates and extracts an archive into dir, keeping only userID's data when set.
+// It returns the archive manifest and the number of extracted files.
+func extractBackup(r io.Reader, dir, userID string) (*BackupManifest, int, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid archive: %w", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+
+	var manifest *BackupManifest
+	files := 0
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid archive: %w", err)
+		}
+
+		if manifest == nil {
+			if header.Name != backupManifestName {
+				return nil, 0, fmt.Errorf("invalid archive: missing %s", backupManifestName)
+			}
+			manifest = &BackupManifest{}
+			if err := json.NewDecoder(tr).Decode(manifest); err != nil {
+				return nil, 0, fmt.Errorf("invalid archive manifest: %w", err)
+			}
+			if manifest.Version != backupFormatVersion {
+				return nil, 0, fmt.Errorf("unsupported archive version %d", manifest.Version)
+			}
+			continue
+		}
+
+		name := path.Clean(header.Name)
+		if !isAllowedBackupPath(name) {
+			return nil, 0, fmt.Errorf("invalid archive entry: %s", header.Name)
+		}
+		dataName, _ := strings.CutPrefix(name, backupColdPrefix+"/")
+		if userID != "" && !strings.HasPrefix(dataName, "users/"+userID+"/") && dataName != "users/"+userID {
+			continue
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return nil, 0, err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return nil, 0, err
+			}
+			file, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.FileMode(header.Mode).Perm())
+			if err != nil {
+				return nil, 0, err
+			}
+			_, copyErr := io.Copy(file, tr)
+			closeErr := file.Close()
+			if copyErr != nil {
+				return nil, 0, fmt.Errorf("failed to extract %s: %w", name, copyErr)
+			}
+			if closeErr != nil {
+				return nil, 0, closeErr
+			}
+			os.Chtimes(target, header.ModTime, header.ModTime)
+			files++
+		default:
+			return nil, 0, fmt.Errorf("invalid archive entry type for %s", header.Name)
+		}
+	}
+
+	if manifest == nil {
+		return nil, 0, fmt.Errorf("invalid archive: missing %s", backupManifestName)
+	}
+	return manifest, files, nil
+}
+
+// isAllowedBackupPath accepts the paths written by Export: the service accounts file
+// and anything below users/<id>/, in the data directory or the cold tier.
+func isAllowedBackupPath(name string) bool {
+	if name == serviceAccountsFile || name == "users" || name == backupColdPrefix {
+		return true
+	}
+	name, _ = strings.CutPrefix(name, backupColdPrefix+"/")
+	if name == "users" {
+		return true
+	}
+	parts := strings.Split(name, "/")
+	if parts[0] != "users" {
+		return false
+	}
+	for _, part := range parts[1:] {
+		if !isSafePathElement(part) {
+			return false
+		}
+	}
+	return true
+}
+
+// isSafePathElement reports whether s is a single, non-special path element.
+func isSafePathElement(s string) bool {
+	return s != "" && s != "." && s != ".." && !strings.ContainsAny(s, `/\`)
+}
+
+// dataDirs returns the data directory and, if configured, the cold data directory.
+func (s *BackupService) dataDirs() []string {
+	if s.coldDir == "" {
+		return []string{s.baseDir}
+	}
+	return []string{s.baseDir, s.coldDir}
+}
+
+// listUsers returns the users of the data directory.
+func (s *BackupService) listUsers() ([]string, error) {
+	return s.listUsersIn(s.baseDir)
+}
+
+// listUsersIn returns the user directories below dir/users, sorted.
+func (s *BackupService) listUsersIn(dir string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(dir, "users"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, fmt.Errorf("failed to read users directory: %w", err)
+	}
+
+	users := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			users = append(users, entry.Name())
+		}
+	}
+	sort.Strings(users)
+	return users, nil
+}
+
+// listClientDirs returns the client directories in clientsDir.
+func (s *BackupService) listClientDirs(clientsDir string) ([]string, error) {
+	entries, err := os.ReadDir(clientsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read clients directory: %w", err)
+	}
+
+	var clientIDs []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			clientIDs = append(clientIDs, entry.Name())
+		}
+	}
+	return clientIDs, nil
+}
+
+// clientOwners maps the IDs of all existing clients to their user.
+func (s *BackupService) clientOwners() (map[string]string, error) {
+	users, err := s.listUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	owners := make(map[string]string)
+	for _, user := range users {
+		clientIDs, err := s.listClientDirs(filepath.Join(s.baseDir, "users", user, "clients"))
+		if err != nil {
+			return nil, err
+		}
+		for _, clientID := range clientIDs {
+			owners[clientID] = user
+		}
+	}
+	return owners, nil
+}
+
+// containsString reports whether values contains value.
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
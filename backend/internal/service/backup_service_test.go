@@ -0,0 +1,183 @@
+package service_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("BackupService", func() {
+	var (
+		sourceDir string
+		targetDir string
+		log       logger.Logger
+	)
+
+	createClient := func(baseDir, userID, clientID string) {
+		repo, err := repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(repo.Create(&models.Client{
+			ID:        clientID,
+			UserID:    userID,
+			Name:      clientID,
+			SmeeURL:   "https://smee.io/" + clientID,
+			TargetURL: "http://localhost:3000",
+		})).To(Succeed())
+		logPath := filepath.Join(baseDir, "users", userID, "clients", clientID, "logs", "2025-10-01.log")
+		Expect(os.WriteFile(logPath, []byte("log line\n"), 0644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		sourceDir = GinkgoT().TempDir()
+		targetDir = GinkgoT().TempDir()
+		log = logger.New()
+		createClient(sourceDir, "alice", "client-a1")
+		createClient(sourceDir, "alice", "client-a2")
+		createClient(sourceDir, "bob", "client-b1")
+	})
+
+	It("round-trips a single user's data", func() {
+		var archive bytes.Buffer
+		manifest, err := service.NewBackupService(sourceDir, log).Export(&archive, "alice")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(manifest.Users).To(Equal([]string{"alice"}))
+		Expect(manifest.Clients).To(Equal(2))
+
+		result, err := service.NewBackupService(targetDir, log).Import(&archive, service.ImportOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Clients).To(Equal(2))
+
+		repo, err := repository.NewFileClientRepository(targetDir)
+		Expect(err).NotTo(HaveOccurred())
+		clients, err := repo.GetByUserID("alice")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(clients).To(HaveLen(2))
+		Expect(filepath.Join(targetDir, "users", "alice", "clients", "client-a1", "logs", "2025-10-01.log")).To(BeARegularFile())
+		Expect(filepath.Join(targetDir, "users", "bob")).NotTo(BeADirectory())
+	})
+
+	It("refuses to replace existing clients unless overwriting", func() {
+		var archive bytes.Buffer
+		_, err := service.NewBackupService(sourceDir, log).Export(&archive, "")
+		Expect(err).NotTo(HaveOccurred())
+		data := archive.Bytes()
+
+		createClient(targetDir, "carol", "client-b1")
+		backupService := service.NewBackupService(targetDir, log)
+
+		_, err = backupService.Import(bytes.NewReader(data), service.ImportOptions{})
+		Expect(err).To(MatchError(ContainSubstring("client-b1 already exists")))
+		Expect(filepath.Join(targetDir, "users", "alice")).NotTo(BeADirectory())
+
+		result, err := backupService.Import(bytes.NewReader(data), service.ImportOptions{Overwrite: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Clients).To(Equal(3))
+		Expect(filepath.Join(targetDir, "users", "bob", "clients", "client-b1")).To(BeADirectory())
+		Expect(filepath.Join(targetDir, "users", "carol", "clients", "client-b1")).NotTo(BeADirectory())
+	})
+
+	It("imports only the selected user", func() {
+		var archive bytes.Buffer
+		_, err := service.NewBackupService(sourceDir, log).Export(&archive, "")
+		Expect(err).NotTo(HaveOccurred())
+
+		result, err := service.NewBackupService(targetDir, log).Import(&archive, service.ImportOptions{UserID: "bob"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Users).To(Equal([]string{"bob"}))
+		Expect(result.Clients).To(Equal(1))
+		Expect(filepath.Join(targetDir, "users", "alice")).NotTo(BeADirectory())
+	})
+
+	Context("with a cold data directory", func() {
+		var sourceCold string
+
+		coldEvent := func(dataDir, userID, clientID string) string {
+			return filepath.Join(dataDir, "users", userID, "clients", clientID, "events", "2025-01-01", "evt-old.json")
+		}
+
+		BeforeEach(func() {
+			sourceCold = GinkgoT().TempDir()
+			path := coldEvent(sourceCold, "alice", "client-a1")
+			Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+			Expect(os.WriteFile(path, []byte(`{"id":"evt-old"}`), 0644)).To(Succeed())
+		})
+
+		export := func(userID string) *bytes.Buffer {
+			var archive bytes.Buffer
+			backupService := service.NewBackupService(sourceDir, log)
+			backupService.SetColdDir(sourceCold)
+			_, err := backupService.Export(&archive, userID)
+			Expect(err).NotTo(HaveOccurred())
+			return &archive
+		}
+
+		It("backs up the cold events and restores them to the cold data directory", func() {
+			archive := export("alice")
+
+			targetCold := GinkgoT().TempDir()
+			backupService := service.NewBackupService(targetDir, log)
+			backupService.SetColdDir(targetCold)
+			_, err := backupService.Import(archive, service.ImportOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(coldEvent(targetCold, "alice", "client-a1")).To(BeARegularFile())
+			Expect(coldEvent(targetDir, "alice", "client-a1")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(targetCold, "users", "alice", "clients", "client-a1", "config.json")).NotTo(BeAnExistingFile())
+		})
+
+		It("restores the cold events to the data directory without a cold data directory", func() {
+			_, err := service.NewBackupService(targetDir, log).Import(export(""), service.ImportOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(coldEvent(targetDir, "alice", "client-a1")).To(BeARegularFile())
+			events, err := repository.NewFileEventRepository(targetDir).GetByClientID("client-a1", &models.EventListRequest{Page: 1, PageSize: 10})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events.Total).To(Equal(1))
+		})
+
+		It("replaces the cold events of overwritten clients", func() {
+			archive := export("")
+			targetCold := GinkgoT().TempDir()
+			createClient(targetDir, "carol", "client-a1")
+			stale := filepath.Join(targetCold, "users", "carol", "clients", "client-a1", "events", "2024-12-01", "evt-stale.json")
+			Expect(os.MkdirAll(filepath.Dir(stale), 0755)).To(Succeed())
+			Expect(os.WriteFile(stale, []byte(`{"id":"evt-stale"}`), 0644)).To(Succeed())
+
+			backupService := service.NewBackupService(targetDir, log)
+			backupService.SetColdDir(targetCold)
+			_, err := backupService.Import(archive, service.ImportOptions{Overwrite: true})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stale).NotTo(BeAnExistingFile())
+			Expect(coldEvent(targetCold, "alice", "client-a1")).To(BeARegularFile())
+		})
+
+		It("leaves out the cold events of other users", func() {
+			path := coldEvent(sourceCold, "bob", "client-b1")
+			Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+			Expect(os.WriteFile(path, []byte(`{"id":"evt-old"}`), 0644)).To(Succeed())
+
+			targetCold := GinkgoT().TempDir()
+			backupService := service.NewBackupService(targetDir, log)
+			backupService.SetColdDir(targetCold)
+			_, err := backupService.Import(export(""), service.ImportOptions{UserID: "alice"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(coldEvent(targetCold, "alice", "client-a1")).To(BeARegularFile())
+			Expect(filepath.Join(targetCold, "users", "bob")).NotTo(BeADirectory())
+		})
+	})
+
+	It("rejects unknown users on export", func() {
+		_, err := service.NewBackupService(sourceDir, log).Export(&bytes.Buffer{}, "../alice")
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,198 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+)
+
+// circuit holds the breaker state of a single client.
+type circuit struct {
+	state               models.CircuitState
+	consecutiveFailures int
+	openedAt            time.Time
+	probeInFlight       bool
+	lastError           string
+}
+
+// CircuitBreakerService pauses deliveries to targets that keep failing.
+// After threshold consecutive failures the circuit opens and deliveries are rejected;
+// once the cooldown elapses a single probe delivery is let through (half-open),
+// which closes the circuit on success or re-opens it on failure.
+type CircuitBreakerService struct {
+	circuits            map[string]*circuit // clientID -> circuit
+	threshold           int                 // Consecutive failures to open the circuit (0 = disabled)
+	cooldown            time.Duration       // Time to wait before probing an open circuit
+	notificationService *NotificationService
+	mu                  sync.Mutex
+	log                 logger.Logger
+}
+
+// NewCircuitBreakerService creates a new circuit breaker service.
+func NewCircuitBreakerService(threshold int, cooldown time.Duration, notificationService *NotificationService, log logger.Logger) *CircuitBreakerService {
+	return &CircuitBreakerService{
+		circuits:            make(map[string]*circuit),
+		threshold:           threshold,
+		cooldown:            cooldown,
+		notificationService: notificationService,
+		log:                 log,
+	}
+}
+
+// getCircuit returns the circuit of a client, creating a closed one if needed.
+// Caller must hold the lock.
+func (s *CircuitBreakerService) getCircuit(clientID string) *circuit {
+	c, exists := s.circuits[clientID]
+	if !exists {
+		c = &circuit{state: models.CircuitStateClosed}
+		s.circuits[clientID] = c
+	}
+	return c
+}
+
+// Allow reports whether a delivery to the client's target may be attempted now.
+// When an open circuit's cooldown has elapsed, the caller is granted the probe delivery.
+func (s *CircuitBreakerService) Allow(clientID string) bool {
+	if s.threshold <= 0 {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.getCircuit(clientID)
+	switch c.state {
+	case models.CircuitStateOpen:
+		if time.Since(c.openedAt) < s.cooldown {
+			return false
+		}
+		c.state = models.CircuitStateHalfOpen
+		c.probeInFlight = true
+		s.log.Info("Circuit for client %s is half-open, sending probe delivery", clientID)
+		return true
+	case models.CircuitStateHalfOpen:
+		if c.probeInFlight {
+			return false
+		}
+		c.probeInFlight = true
+		return true
+	default:
+		return true
+	}
+}
+
+// Blocked reports whether deliveries to the client's target are currently rejected,
+// without claiming the probe delivery.
+func (s *CircuitBreakerService) Blocked(clientID string) bool {
+	if s.threshold <= 0 {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.getCircuit(clientID)
+	switch c.state {
+	case models.CircuitStateOpen:
+		return time.Since(c.openedAt) < s.cooldown
+	case models.CircuitStateHalfOpen:
+		return c.probeInFlight
+	default:
+		return false
+	}
+}
+
+// RecordSuccess records a successful delivery and closes the circuit.
+func (s *CircuitBreakerService) RecordSuccess(client *models.Client) {
+	if s.threshold <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	c := s.getCircuit(client.ID)
+	recovered := c.state != models.CircuitStateClosed
+	c.state = models.CircuitStateClosed
+	c.consecutiveFailures = 0
+	c.probeInFlight = false
+	c.lastError = ""
+	s.mu.Unlock()
+
+	if recovered {
+		s.log.Info("Circuit for client %s closed", client.ID)
+		s.notificationService.Notify(client.UserID, client.ID, "circuit_closed", models.NotificationLevelInfo,
+			fmt.Sprintf("Target of client %q is reachable again, deliveries resumed", client.Name))
+	}
+}
+
+// RecordFailure records a failed delivery and opens the circuit when the threshold is reached
+// or the half-open probe failed.
+func (s *CircuitBreakerService) RecordFailure(client *models.Client, errMsg string) {
+	if s.threshold <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	c := s.getCircuit(client.ID)
+	c.consecutiveFailures++
+	c.lastError = errMsg
+
+	opened := false
+	switch c.state {
+	case models.CircuitStateHalfOpen:
+		c.state = models.CircuitStateOpen
+		c.openedAt = time.Now()
+		c.probeInFlight = false
+	case models.CircuitStateClosed:
+		if c.consecutiveFailures >= s.threshold {
+			c.state = models.CircuitStateOpen
+			c.openedAt = time.Now()
+			opened = true
+		}
+	}
+	failures := c.consecutiveFailures
+	s.mu.Unlock()
+
+	if opened {
+		s.log.Info("Circuit for client %s opened after %d consecutive failures", client.ID, failures)
+		s.notificationService.Notify(client.UserID, client.ID, "circuit_open", models.NotificationLevelError,
+			fmt.Sprintf("Deliveries of client %q paused after %d consecutive failures: %s", client.Name, failures, errMsg))
+	}
+}
+
+// Status returns the circuit breaker status of a client.
+func (s *CircuitBreakerService) Status(clientID string) *models.CircuitStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.getCircuit(clientID)
+	status := &models.CircuitStatus{
+		ClientID:            clientID,
+		State:               c.state,
+		ConsecutiveFailures: c.consecutiveFailures,
+		Threshold:           s.threshold,
+		LastError:           c.lastError,
+	}
+	if c.state != models.CircuitStateClosed {
+		openedAt := c.openedAt
+		nextProbeAt := c.openedAt.Add(s.cooldown)
+		status.OpenedAt = &openedAt
+		status.NextProbeAt = &nextProbeAt
+	}
+
+	return status
+}
+
+// Reset closes the circuit of a client manually.
+func (s *CircuitBreakerService) Reset(clientID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.circuits, clientID)
+	s.log.Info("Circuit for client %s reset", clientID)
+}
@@ -0,0 +1,73 @@
+package service_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("CircuitBreakerService", func() {
+	const cooldown = 20 * time.Millisecond
+
+	var (
+		notifications *service.NotificationService
+		breaker       *service.CircuitBreakerService
+		client        *models.Client
+	)
+
+	BeforeEach(func() {
+		log := logger.New()
+		notifications = service.NewNotificationService(log)
+		breaker = service.NewCircuitBreakerService(3, cooldown, notifications, log)
+		client = &models.Client{ID: "client-cb", UserID: "user-cb", Name: "flapping"}
+	})
+
+	openCircuit := func() {
+		for i := 0; i < 3; i++ {
+			Expect(breaker.Allow(client.ID)).To(BeTrue())
+			breaker.RecordFailure(client, "HTTP 503")
+		}
+	}
+
+	It("opens after the failure threshold and notifies the owner", func() {
+		openCircuit()
+
+		Expect(breaker.Allow(client.ID)).To(BeFalse())
+		Expect(breaker.Blocked(client.ID)).To(BeTrue())
+		Expect(breaker.Status(client.ID).State).To(Equal(models.CircuitStateOpen))
+
+		list := notifications.List(client.UserID, true)
+		Expect(list.Notifications).To(HaveLen(1))
+		Expect(list.Notifications[0].Type).To(Equal("circuit_open"))
+	})
+
+	It("lets a single probe through after the cooldown and closes on success", func() {
+		openCircuit()
+		time.Sleep(cooldown + 5*time.Millisecond)
+
+		Expect(breaker.Allow(client.ID)).To(BeTrue())
+		Expect(breaker.Allow(client.ID)).To(BeFalse())
+		Expect(breaker.Status(client.ID).State).To(Equal(models.CircuitStateHalfOpen))
+
+		breaker.RecordSuccess(client)
+		Expect(breaker.Status(client.ID).State).To(Equal(models.CircuitStateClosed))
+		Expect(breaker.Allow(client.ID)).To(BeTrue())
+		Expect(notifications.List(client.UserID, false).Notifications[0].Type).To(Equal("circuit_closed"))
+	})
+
+	It("re-opens when the probe fails", func() {
+		openCircuit()
+		time.Sleep(cooldown + 5*time.Millisecond)
+
+		Expect(breaker.Allow(client.ID)).To(BeTrue())
+		breaker.RecordFailure(client, "HTTP 503")
+
+		Expect(breaker.Status(client.ID).State).To(Equal(models.CircuitStateOpen))
+		Expect(breaker.Allow(client.ID)).To(BeFalse())
+	})
+})
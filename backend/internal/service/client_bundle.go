@@ -0,0 +1,415 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+)
+
+// clientBundleVersion is the bundle layout version written by Export.
+const clientBundleVersion = 1
+
+// Entries of a client bundle besides the events/ and logs/ directories.
+const (
+	clientBundleManifestName = "bundle.json"
+	clientBundleConfigName   = "config.json"
+)
+
+// ClientBundleService moves single clients between users or instances: it exports a
+// client's configuration, events and logs as a zip bundle and imports a bundle as a new
+// client with a fresh ID. Replay scripts are not part of bundles: importing a bundle
+// generates the scripts of its events, so a bundle can't plant scripts.
+//
+// Bundle layout:
+//
+//	bundle.json                      manifest (models.ClientBundleManifest)
+//	config.json                      client settings (models.ClientRequest, without target secrets)
+//	events/<eventId>.json            events
+//	logs/YYYY-MM-DD.ndjson           log records of a day
+//	logs/YYYY-MM-DD.annotations.json log annotations of a day
+type ClientBundleService struct {
+	clientService *ClientService
+	clientRepo    repository.ClientRepository
+	quotaRepo     repository.QuotaRepository
+	eventRepo     repository.EventRepository
+	eventService  *EventService
+	logService    *LogService
+	log           logger.Logger
+
+	statsService *StatsService // Rolls up the stats of imported events (optional)
+}
+
+// NewClientBundleService creates a new client bundle service.
+func NewClientBundleService(
+	clientService *ClientService,
+	clientRepo repository.ClientRepository,
+	quotaRepo repository.QuotaRepository,
+	eventRepo repository.EventRepository,
+	eventService *EventService,
+	logService *LogService,
+	log logger.Logger,
+) *ClientBundleService {
+	return &ClientBundleService{
+		clientService: clientService,
+		clientRepo:    clientRepo,
+		quotaRepo:     quotaRepo,
+		eventRepo:     eventRepo,
+		eventService:  eventService,
+		logService:    logService,
+		log:           log,
+	}
+}
+
+// SetStatsService rolls up the daily stats of imported events, so the history of an
+// imported client shows in its stats right away.
+func (s *ClientBundleService) SetStatsService(statsService *StatsService) {
+	s.statsService = statsService
+}
+
+// Export packages a client as a bundle. Target credentials are not exported; log records
+// are masked like in the log viewer if masking is configured.
+func (s *ClientBundleService) Export(clientID string) ([]byte, *models.ClientBundleManifest, error) {
+	client, err := s.clientRepo.Get(clientID)
+	if err != nil {
+		return nil, nil, apperrors.ErrClientNotFound
+	}
+
+	summaries, err := collectEventSummaries(s.eventRepo, clientID, &models.EventListRequest{SortBy: "timestamp", SortOrder: "asc"})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list events: %w", err)
+	}
+	dates, err := s.logService.LogDates(client.UserID, clientID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	add := func(name string, data []byte, mode fs.FileMode, modified time.Time) error {
+		header := &zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified}
+		header.SetMode(mode)
+		writer, err := archive.CreateHeader(header)
+		if err != nil {
+			return fmt.Errorf("failed to create bundle: %w", err)
+		}
+		if _, err := writer.Write(data); err != nil {
+			return fmt.Errorf("failed to create bundle: %w", err)
+		}
+		return nil
+	}
+	addJSON := func(name string, value interface{}, modified time.Time) error {
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s: %w", name, err)
+		}
+		return add(name, data, 0644, modified)
+	}
+
+	now := time.Now().UTC()
+	manifest := &models.ClientBundleManifest{
+		Version:    clientBundleVersion,
+		ExportedAt: now,
+		ClientID:   clientID,
+		Name:       client.Name,
+		Events:     len(summaries),
+		LogDays:    len(dates),
+	}
+	if err := addJSON(clientBundleManifestName, manifest, now); err != nil {
+		return nil, nil, err
+	}
+
+	config := clientRequestOf(client)
+	config.Version = 0
+	if err := addJSON(clientBundleConfigName, config, client.UpdatedAt); err != nil {
+		return nil, nil, err
+	}
+
+	for _, summary := range summaries {
+		event, err := s.eventRepo.Get(clientID, summary.ID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read event %s: %w", summary.ID, err)
+		}
+		if err := addJSON("events/"+event.ID+".json", event, event.Timestamp); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for _, date := range dates {
+		records, err := s.logService.DownloadLog(client.UserID, clientID, date, LogFormatNDJSON)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read log of %s: %w", date, err)
+		}
+		if err := add("logs/"+date+".ndjson", records, 0644, now); err != nil {
+			return nil, nil, err
+		}
+
+		annotations, err := s.logService.ListAnnotations(client.UserID, clientID, date)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read log annotations of %s: %w", date, err)
+		}
+		if len(annotations) > 0 {
+			if err := addJSON("logs/"+date+logAnnotationsSuffix, annotations, now); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, nil, fmt.Errorf("failed to create bundle: %w", err)
+	}
+
+	s.log.Info("Exported client %s: %d events, %d days of logs", clientID, manifest.Events, manifest.LogDays)
+	return buf.Bytes(), manifest, nil
+}
+
+// clientBundle is the validated content of an uploaded bundle.
+type clientBundle struct {
+	manifest    *models.ClientBundleManifest
+	config      *models.ClientRequest
+	events      []*zip.File
+	logs        map[string]*zip.File // Date -> log records
+	annotations map[string]*zip.File // Date -> log annotations
+	size        int64                // Uncompressed size of the events and logs
+}
+
+// Import recreates the client of a bundle under the user with a fresh ID. The client is
+// created stopped, counts against the user's client and storage quota and gets the bundle's
+// slug unless the user already has a client with it. If anything fails, the new client is
+// deleted again.
+func (s *ClientBundleService) Import(userID string, data []byte) (*models.ClientBundleImportResult, error) {
+	bundle, err := readClientBundle(data)
+	if err != nil {
+		return nil, err
+	}
+
+	quota, err := s.quotaRepo.GetQuota(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check quota: %w", err)
+	}
+	if quota.TotalBytes > 0 && quota.UsedBytes+bundle.size > quota.TotalBytes {
+		return nil, apperrors.NewQuotaExceeded(fmt.Sprintf("the bundle needs %d bytes, %d of %d bytes are used",
+			bundle.size, quota.UsedBytes, quota.TotalBytes))
+	}
+
+	result := &models.ClientBundleImportResult{SourceClientID: bundle.manifest.ClientID, Warnings: []string{}}
+
+	config := bundle.config
+	config.Version = 0
+	config.StartImmediately = false
+	if config.TargetAuth != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Target credentials (%s) are not part of bundles and were removed, set them again", config.TargetAuth.Type))
+		config.TargetAuth = nil
+	}
+	if config.Slug != "" {
+		if _, err := s.clientService.Resolve(userID, config.Slug); err == nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("The slug %q is already used, the client got a new one", config.Slug))
+			config.Slug = ""
+		}
+	}
+
+	client, err := s.clientService.Create(userID, config)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.importData(client, bundle, result); err != nil {
+		if deleteErr := s.clientService.Delete(client.ID); deleteErr != nil {
+			s.log.Error("Failed to delete client %s after its import failed: %v", client.ID, deleteErr)
+		}
+		return nil, err
+	}
+
+	if s.statsService != nil {
+		if _, err := s.statsService.RebuildClient(client); err != nil {
+			s.log.Error("Failed to roll up the stats of imported client %s: %v", client.ID, err)
+		}
+	}
+	s.quotaRepo.InvalidateCache(userID)
+
+	result.Client = client
+	s.log.Info("Imported client %s from bundle of client %s (user: %s, %d events, %d days of logs)",
+		client.ID, result.SourceClientID, userID, result.Events, result.LogDays)
+	return result, nil
+}
+
+// importData stores the events, logs and annotations of a bundle for a new client. Events are
+// redacted and limited in size like the events the client receives, and get a replay script
+// generated for the new client.
+func (s *ClientBundleService) importData(client *models.Client, bundle *clientBundle, result *models.ClientBundleImportResult) error {
+	format := models.ScriptFormatCurl
+	if client.HTTPie {
+		format = models.ScriptFormatHTTPie
+	}
+
+	for _, file := range bundle.events {
+		event := &models.Event{}
+		if err := readBundleJSON(file, event); err != nil {
+			return err
+		}
+		if !isSafePathElement(event.ID) {
+			return apperrors.NewInvalidInput(fmt.Sprintf("invalid bundle entry %s: invalid event ID %q", file.Name, event.ID))
+		}
+		event.ClientID = client.ID
+		event.Version = 0
+		if err := s.eventService.ImportEvent(client, event); err != nil {
+			var appErr *apperrors.AppError
+			if errors.As(err, &appErr) && appErr.Code == apperrors.CodeRequestTooLarge {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("Event %s was not imported: %s", event.ID, appErr.Message))
+				continue
+			}
+			return fmt.Errorf("failed to import event %s: %w", event.ID, err)
+		}
+
+		targetURL, err := resolveTargetURL(client, event)
+		if err != nil {
+			return apperrors.NewInvalidInput(fmt.Sprintf("invalid bundle entry %s: %v", file.Name, err))
+		}
+		script := buildReplayScript(format, event.ID, targetURL, event.Headers, event.Payload, event.PayloadEncoding, time.Now())
+		if err := s.eventRepo.SaveScript(client.ID, event.ID, []byte(script)); err != nil {
+			return fmt.Errorf("failed to save replay script of event %s: %w", event.ID, err)
+		}
+		result.Events++
+	}
+
+	for date, file := range bundle.logs {
+		data, err := readBundleFile(file)
+		if err != nil {
+			return err
+		}
+		var records []models.LogRecord
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+		for scanner.Scan() {
+			if line := scanner.Text(); line != "" {
+				records = append(records, decodeLogRecord(line))
+			}
+		}
+		if err := s.logService.ImportLog(client.UserID, client.ID, date, records); err != nil {
+			return fmt.Errorf("failed to import log of %s: %w", date, err)
+		}
+		result.LogDays++
+	}
+
+	for date, file := range bundle.annotations {
+		var annotations []*models.LogAnnotation
+		if err := readBundleJSON(file, &annotations); err != nil {
+			return err
+		}
+		if err := s.logService.ImportAnnotations(client.UserID, client.ID, date, annotations); err != nil {
+			return fmt.Errorf("failed to import log annotations of %s: %w", date, err)
+		}
+	}
+	return nil
+}
+
+// readClientBundle validates an uploaded bundle and indexes its entries. Entries that
+// Export does not write are rejected.
+func readClientBundle(data []byte) (*clientBundle, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, apperrors.NewInvalidInput(fmt.Sprintf("invalid bundle: %v", err))
+	}
+
+	bundle := &clientBundle{
+		logs:        make(map[string]*zip.File),
+		annotations: make(map[string]*zip.File),
+	}
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		name := file.Name
+		dir, base := path.Split(name)
+		switch {
+		case name == clientBundleManifestName:
+			bundle.manifest = &models.ClientBundleManifest{}
+			if err := readBundleJSON(file, bundle.manifest); err != nil {
+				return nil, err
+			}
+			continue
+		case name == clientBundleConfigName:
+			bundle.config = &models.ClientRequest{}
+			if err := readBundleJSON(file, bundle.config); err != nil {
+				return nil, err
+			}
+			continue
+		case dir == "events/" && strings.HasSuffix(base, ".json") && isSafePathElement(strings.TrimSuffix(base, ".json")):
+			bundle.events = append(bundle.events, file)
+		case dir == "events/" && strings.HasSuffix(base, ".sh") && isSafePathElement(strings.TrimSuffix(base, ".sh")):
+			continue // Replay script of an older bundle, generated again on import
+		case dir == "logs/" && isBundleLogDate(base, ".ndjson"):
+			bundle.logs[strings.TrimSuffix(base, ".ndjson")] = file
+		case dir == "logs/" && isBundleLogDate(base, logAnnotationsSuffix):
+			bundle.annotations[strings.TrimSuffix(base, logAnnotationsSuffix)] = file
+		default:
+			return nil, apperrors.NewInvalidInput(fmt.Sprintf("invalid bundle entry: %s", name))
+		}
+		bundle.size += int64(file.UncompressedSize64)
+	}
+
+	switch {
+	case bundle.manifest == nil:
+		return nil, apperrors.NewInvalidInput(fmt.Sprintf("invalid bundle: missing %s", clientBundleManifestName))
+	case bundle.manifest.Version != clientBundleVersion:
+		return nil, apperrors.NewInvalidInput(fmt.Sprintf("unsupported bundle version %d", bundle.manifest.Version))
+	case bundle.config == nil:
+		return nil, apperrors.NewInvalidInput(fmt.Sprintf("invalid bundle: missing %s", clientBundleConfigName))
+	case bundle.config.Name == "" || bundle.config.SmeeURL == "" || bundle.config.TargetURL == "":
+		return nil, apperrors.NewInvalidInput("invalid bundle: the client needs a name, a smee URL and a target URL")
+	}
+	return bundle, nil
+}
+
+// isBundleLogDate reports whether name is a day (YYYY-MM-DD) followed by suffix.
+func isBundleLogDate(name, suffix string) bool {
+	date, ok := strings.CutSuffix(name, suffix)
+	if !ok {
+		return false
+	}
+	_, err := time.Parse("2006-01-02", date)
+	return err == nil
+}
+
+// readBundleFile reads an entry of a bundle.
+func readBundleFile(file *zip.File) ([]byte, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, apperrors.NewInvalidInput(fmt.Sprintf("invalid bundle entry %s: %v", file.Name, err))
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, apperrors.NewInvalidInput(fmt.Sprintf("invalid bundle entry %s: %v", file.Name, err))
+	}
+	return data, nil
+}
+
+// readBundleJSON decodes a JSON entry of a bundle into value.
+func readBundleJSON(file *zip.File, value interface{}) error {
+	data, err := readBundleFile(file)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, value); err != nil {
+		return apperrors.NewInvalidInput(fmt.Sprintf("invalid bundle entry %s: %v", file.Name, err))
+	}
+	return nil
+}
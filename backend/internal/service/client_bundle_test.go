@@ -0,0 +1,202 @@
+package service_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("ClientBundleService", func() {
+	var (
+		clientService *service.ClientService
+		clientRepo    repository.ClientRepository
+		eventRepo     *repository.FileEventRepository
+		eventService  *service.EventService
+		logService    *service.LogService
+		bundleService *service.ClientBundleService
+		source        *models.Client
+	)
+
+	BeforeEach(func() {
+		baseDir := GinkgoT().TempDir()
+		log := logger.New()
+		var err error
+		clientRepo, err = repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		quotaRepo := repository.NewFileQuotaRepository(baseDir, 10*1024*1024, 10)
+		eventRepo = repository.NewFileEventRepository(baseDir)
+		logService = service.NewLogService(baseDir, repository.NewFileLogAnnotationRepository(baseDir), log)
+		jobService := service.NewJobService(time.Hour, log)
+		clientService = service.NewClientService(
+			clientRepo,
+			quotaRepo,
+			eventRepo,
+			service.NewProcessService(false, 0, time.Minute, log),
+			jobService,
+			baseDir,
+			log,
+		)
+		eventService = service.NewEventService(
+			eventRepo,
+			clientRepo,
+			jobService,
+			service.NewCircuitBreakerService(0, time.Minute, service.NewNotificationService(log), log),
+			log,
+		)
+		bundleService = service.NewClientBundleService(clientService, clientRepo, quotaRepo, eventRepo, eventService, logService, log)
+
+		source, err = clientService.Create("user-a", &models.ClientRequest{
+			Name:      "CI relay",
+			Slug:      "ci-relay",
+			SmeeURL:   "https://smee.example.com/channel",
+			TargetURL: "http://target.example.com/hook",
+			Runbook:   "Page the CI team.",
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("recreates an exported client under another user with a fresh ID", func() {
+		received := time.Date(2025, 10, 1, 12, 0, 0, 0, time.Local)
+		Expect(eventRepo.Save(source.ID, &models.Event{ID: "evt-1", Timestamp: received, EventType: "push", Status: models.EventStatusSuccess, Payload: `{"ref":"main"}`})).To(Succeed())
+		Expect(eventRepo.SaveScript(source.ID, "evt-1", []byte("curl http://target.example.com/hook\n"))).To(Succeed())
+		Expect(logService.ImportLog("user-a", source.ID, "2025-10-01", []models.LogRecord{
+			{Time: received, Source: models.LogSourceStdout, Level: models.LogLevelInfo, Message: "forwarded push"},
+		})).To(Succeed())
+		_, err := logService.AddAnnotation("user-a", source.ID, &models.LogAnnotationRequest{Time: received, Note: "incident started here"})
+		Expect(err).NotTo(HaveOccurred())
+
+		data, manifest, err := bundleService.Export(source.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(manifest.Events).To(Equal(1))
+		Expect(manifest.LogDays).To(Equal(1))
+
+		result, err := bundleService.Import("user-b", data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.SourceClientID).To(Equal(source.ID))
+		Expect(result.Events).To(Equal(1))
+		Expect(result.LogDays).To(Equal(1))
+		Expect(result.Warnings).To(BeEmpty())
+
+		imported := result.Client
+		Expect(imported.ID).NotTo(Equal(source.ID))
+		Expect(imported.UserID).To(Equal("user-b"))
+		Expect(imported.Slug).To(Equal("ci-relay"))
+		Expect(imported.Runbook).To(Equal("Page the CI team."))
+		Expect(imported.Status).To(Equal(models.ClientStatusStopped))
+
+		event, err := eventRepo.Get(imported.ID, "evt-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(event.ClientID).To(Equal(imported.ID))
+		Expect(event.Payload).To(Equal(`{"ref":"main"}`))
+		script, err := eventRepo.GetScript(imported.ID, "evt-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(script)).To(ContainSubstring("curl"))
+		Expect(string(script)).To(ContainSubstring("http://target.example.com/hook"))
+
+		records, _, err := logService.GetLogs("user-b", imported.ID, "2025-10-01", 1, 100, models.LogFilter{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(records).To(HaveLen(1))
+		Expect(records[0].Message).To(Equal("forwarded push"))
+		annotations, err := logService.ListAnnotations("user-b", imported.ID, "2025-10-01")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(annotations).To(HaveLen(1))
+		Expect(annotations[0].Note).To(Equal("incident started here"))
+
+		// Importing again for the same user needs a new slug
+		result, err = bundleService.Import("user-b", data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Client.Slug).NotTo(Equal("ci-relay"))
+		Expect(result.Warnings).To(ConsistOf(ContainSubstring("already used")))
+	})
+
+	It("rejects archives that are not client bundles", func() {
+		var buf bytes.Buffer
+		archive := zip.NewWriter(&buf)
+		writer, err := archive.Create("../config.json")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = writer.Write([]byte("{}"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(archive.Close()).To(Succeed())
+
+		_, err = bundleService.Import("user-b", buf.Bytes())
+		Expect(err).To(MatchError(ContainSubstring("invalid bundle entry")))
+
+		_, err = bundleService.Import("user-b", []byte("not a zip"))
+		Expect(err).To(MatchError(ContainSubstring("invalid bundle")))
+
+		clients, err := clientService.UserClientIDs("user-b")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(clients).To(BeEmpty())
+	})
+
+	// withEntry returns a copy of a bundle with an entry added
+	withEntry := func(data []byte, name, content string) []byte {
+		source, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		Expect(err).NotTo(HaveOccurred())
+		var buf bytes.Buffer
+		archive := zip.NewWriter(&buf)
+		for _, file := range source.File {
+			reader, err := file.Open()
+			Expect(err).NotTo(HaveOccurred())
+			writer, err := archive.Create(file.Name)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = io.Copy(writer, reader)
+			Expect(err).NotTo(HaveOccurred())
+			reader.Close()
+		}
+		writer, err := archive.Create(name)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = writer.Write([]byte(content))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(archive.Close()).To(Succeed())
+		return buf.Bytes()
+	}
+
+	It("generates the replay scripts of imported events instead of importing scripts", func() {
+		Expect(eventRepo.Save(source.ID, &models.Event{ID: "evt-1", Timestamp: time.Now(), Payload: `{"ref":"main"}`})).To(Succeed())
+		data, _, err := bundleService.Export(source.ID)
+		Expect(err).NotTo(HaveOccurred())
+		data = withEntry(data, "events/evt-1.sh", "#!/bin/sh\nrm -rf /tmp/planted\n")
+
+		result, err := bundleService.Import("user-b", data)
+		Expect(err).NotTo(HaveOccurred())
+		script, err := eventRepo.GetScript(result.Client.ID, "evt-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(script)).NotTo(ContainSubstring("planted"))
+		Expect(string(script)).To(ContainSubstring("http://target.example.com/hook"))
+	})
+
+	It("redacts and limits the payloads of imported events", func() {
+		updated, err := clientService.Patch(source.ID, &models.ClientPatchRequest{
+			RedactionRules: &[]models.RedactionRule{{Path: "$.token"}},
+		}, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated.RedactionRules).To(HaveLen(1))
+		Expect(eventRepo.Save(source.ID, &models.Event{ID: "evt-secret", Timestamp: time.Now(), Payload: `{"token":"s3cr3t"}`})).To(Succeed())
+		Expect(eventRepo.Save(source.ID, &models.Event{ID: "evt-huge", Timestamp: time.Now(), Payload: `{"data":"` + strings.Repeat("x", 100) + `"}`})).To(Succeed())
+		data, _, err := bundleService.Export(source.ID)
+		Expect(err).NotTo(HaveOccurred())
+
+		eventService.SetPayloadLimiter(service.NewPayloadLimiter(50, service.PayloadLimitReject, eventRepo, logger.New()))
+		result, err := bundleService.Import("user-b", data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Events).To(Equal(1))
+		Expect(result.Warnings).To(ConsistOf(ContainSubstring("Event evt-huge was not imported")))
+
+		event, err := eventRepo.Get(result.Client.ID, "evt-secret")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(event.Payload).NotTo(ContainSubstring("s3cr3t"))
+		_, err = eventRepo.Get(result.Client.ID, "evt-huge")
+		Expect(err).To(HaveOccurred())
+	})
+})
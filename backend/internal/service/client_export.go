@@ -0,0 +1,105 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+)
+
+// clientExportColumns is the header row of the client CSV export.
+var clientExportColumns = []string{
+	"id", "name", "description", "status", "paused", "smeeUrl", "targetUrl",
+	"todayEvents", "totalEvents", "failedEvents", "lastActivity", "createdAt",
+}
+
+// ExportCSV exports all clients of a user as CSV, sorted by name, with their current status,
+// event counts and last activity.
+func (s *ClientService) ExportCSV(userID string) ([]byte, error) {
+	clients, err := s.clientRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clients: %w", err)
+	}
+
+	sort.Slice(clients, func(i, j int) bool {
+		return strings.ToLower(clients[i].Name) < strings.ToLower(clients[j].Name)
+	})
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(clientExportColumns); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	for _, client := range clients {
+		total := s.countEvents(client.ID, &models.EventListRequest{})
+		todayCount := s.countEvents(client.ID, &models.EventListRequest{DateFrom: today})
+		failed := s.countEvents(client.ID, &models.EventListRequest{Status: string(models.EventStatusFailed)})
+
+		lastActivity := ""
+		if err := s.populateClientLastActivity(client); err != nil {
+			s.log.Error("Failed to fetch last activity for client %s: %v", client.ID, err)
+		} else if client.LastActivity != nil {
+			lastActivity = client.LastActivity.UTC().Format(time.RFC3339)
+		}
+
+		record := []string{
+			client.ID,
+			client.Name,
+			client.Description,
+			string(s.currentStatus(client.ID, client.Status)),
+			strconv.FormatBool(client.Paused),
+			client.SmeeURL,
+			client.TargetURL,
+			strconv.Itoa(todayCount),
+			strconv.Itoa(total),
+			strconv.Itoa(failed),
+			lastActivity,
+			client.CreatedAt.UTC().Format(time.RFC3339),
+		}
+		for i, field := range record {
+			record[i] = csvSafe(field)
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Exported %d clients of user %s", len(clients), userID)
+	return buf.Bytes(), nil
+}
+
+// countEvents counts the events of a client matching filter; errors count as no events.
+func (s *ClientService) countEvents(clientID string, filter *models.EventListRequest) int {
+	filter.Page = 1
+	filter.PageSize = 1
+	response, err := s.eventRepo.GetByClientID(clientID, filter)
+	if err != nil {
+		s.log.Error("Failed to count events of client %s: %v", clientID, err)
+		return 0
+	}
+	return response.Total
+}
+
+// csvSafe prevents spreadsheet applications from evaluating user-provided text as a formula.
+func csvSafe(field string) string {
+	if field != "" && strings.ContainsRune("=+-@\t\r", rune(field[0])) {
+		return "'" + field
+	}
+	return field
+}
@@ -0,0 +1,65 @@
+package service_test
+
+import (
+	"encoding/csv"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("ClientService CSV export", func() {
+	It("exports the user's clients with status and event counts", func() {
+		baseDir := GinkgoT().TempDir()
+		clientRepo, err := repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		eventRepo := repository.NewFileEventRepository(baseDir)
+		log := logger.New()
+		clientService := service.NewClientService(
+			clientRepo,
+			repository.NewFileQuotaRepository(baseDir, 10*1024*1024, 10),
+			eventRepo,
+			service.NewProcessService(false, 0, time.Minute, log),
+			service.NewJobService(time.Hour, log),
+			baseDir,
+			log,
+		)
+
+		for _, client := range []*models.Client{
+			{ID: "client-b", UserID: "user-export", Name: "beta", SmeeURL: "https://smee.io/b", TargetURL: "http://b.example.com", Status: models.ClientStatusError},
+			{ID: "client-a", UserID: "user-export", Name: "=alpha", SmeeURL: "https://smee.io/a", TargetURL: "http://a.example.com", Paused: true},
+			{ID: "client-other", UserID: "user-other", Name: "other"},
+		} {
+			Expect(clientRepo.Create(client)).To(Succeed())
+		}
+
+		old := time.Now().AddDate(0, 0, -3)
+		Expect(eventRepo.Save("client-b", &models.Event{ID: "evt-old", Timestamp: old, Status: models.EventStatusFailed})).To(Succeed())
+		Expect(eventRepo.Save("client-b", &models.Event{ID: "evt-new", Timestamp: time.Now(), Status: models.EventStatusSuccess})).To(Succeed())
+
+		data, err := clientService.ExportCSV("user-export")
+		Expect(err).NotTo(HaveOccurred())
+
+		records, err := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(records).To(HaveLen(3))
+		Expect(records[0][:4]).To(Equal([]string{"id", "name", "description", "status"}))
+
+		// Sorted by name; formula-like names are neutralized
+		Expect(records[1][0]).To(Equal("client-a"))
+		Expect(records[1][1]).To(Equal("'=alpha"))
+		Expect(records[1][3:5]).To(Equal([]string{"stopped", "true"}))
+		Expect(records[1][7:11]).To(Equal([]string{"0", "0", "0", ""}))
+
+		Expect(records[2][0]).To(Equal("client-b"))
+		Expect(records[2][3]).To(Equal("error"))
+		Expect(records[2][5:10]).To(Equal([]string{"https://smee.io/b", "http://b.example.com", "1", "2", "1"}))
+		Expect(records[2][10]).NotTo(BeEmpty())
+	})
+})
@@ -4,12 +4,16 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
 	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
 	"github.com/lazycatapps/gosmee/backend/internal/repository"
 )
@@ -20,8 +24,21 @@ type ClientService struct {
 	quotaRepo      repository.QuotaRepository
 	eventRepo      repository.EventRepository
 	processService *ProcessService
+	jobService     *JobService
+	statsService   *StatsService               // Serves event totals from daily rollups (optional)
+	secretRepo     repository.SecretRepository // Stores target credentials (optional)
+	ingestion      *IngestionLimiter           // Counts dropped events (optional)
+	sampler        *EventSampler               // Counts sampled out events (optional)
 	baseDir        string
 	log            logger.Logger
+
+	scheduleStates map[string]bool // clientID -> last desired running state from its schedule
+	scheduleMu     sync.Mutex
+
+	updateMu sync.Mutex // Serializes configuration updates, so version preconditions hold until the update is saved
+
+	transitioning map[string]bool // Clients being started or stopped, their stored starting/stopping status is current
+	transitionMu  sync.Mutex
 }
 
 // NewClientService creates a new client service.
@@ -30,21 +47,101 @@ func NewClientService(
 	quotaRepo repository.QuotaRepository,
 	eventRepo repository.EventRepository,
 	processService *ProcessService,
+	jobService *JobService,
 	baseDir string,
 	log logger.Logger,
 ) *ClientService {
-	return &ClientService{
+	s := &ClientService{
 		clientRepo:     clientRepo,
 		quotaRepo:      quotaRepo,
 		eventRepo:      eventRepo,
 		processService: processService,
+		jobService:     jobService,
 		baseDir:        baseDir,
 		log:            log,
+		scheduleStates: make(map[string]bool),
+		transitioning:  make(map[string]bool),
+	}
+
+	processService.SetExitHandler(s.handleProcessExit)
+
+	return s
+}
+
+// SetStatsService makes GetStats report event counts, success rate and average latency
+// from the daily statistics rollups.
+func (s *ClientService) SetStatsService(statsService *StatsService) {
+	s.statsService = statsService
+}
+
+// SetSecretRepository enables target credentials: passwords and tokens are kept in the
+// secret repository, apart from the client configuration.
+func (s *ClientService) SetSecretRepository(secretRepo repository.SecretRepository) {
+	s.secretRepo = secretRepo
+}
+
+// SetIngestionLimiter reports the events dropped over the ingestion limit in client stats.
+func (s *ClientService) SetIngestionLimiter(ingestion *IngestionLimiter) {
+	s.ingestion = ingestion
+}
+
+// SetEventSampler reports the events discarded by sampling policies in client stats.
+func (s *ClientService) SetEventSampler(sampler *EventSampler) {
+	s.sampler = sampler
+}
+
+// handleProcessExit records unexpected process exits on the client, so the reason
+// a client stopped is visible in API responses.
+func (s *ClientService) handleProcessExit(exit *ProcessExit) {
+	client, err := s.clientRepo.Get(exit.ClientID)
+	if err != nil {
+		s.log.Error("Failed to load client %s after process exit: %v", exit.ClientID, err)
+		return
+	}
+
+	now := time.Now()
+	client.LastError = exit.Error
+	client.LastErrorCategory = exit.Category
+	if exit.ExitCode >= 0 {
+		exitCode := exit.ExitCode
+		client.LastExitCode = &exitCode
+	}
+	if !exit.Restarting {
+		if err := client.Transition(models.ClientStatusError, exit.Error); err != nil {
+			s.log.Error("Failed to record process exit of client %s: %v", exit.ClientID, err)
+		}
+		client.StoppedAt = &now
+	}
+	client.UpdatedAt = now
+
+	if err := s.clientRepo.Update(client); err != nil {
+		s.log.Error("Failed to update client status: %v", err)
 	}
 }
 
 // Create creates a new client instance.
+// With StartImmediately set, the client is started as well; if it fails to start, it is
+// deleted again and the start error is returned.
 func (s *ClientService) Create(userID string, req *models.ClientRequest) (*models.Client, error) {
+	client, err := s.create(userID, req)
+	if err != nil || !req.StartImmediately {
+		return client, err
+	}
+
+	if err := s.Start(client.ID); err != nil {
+		if deleteErr := s.Delete(client.ID); deleteErr != nil {
+			s.log.Error("Failed to delete client %s after it failed to start: %v", client.ID, deleteErr)
+		} else {
+			s.log.Info("Deleted client %s: it failed to start", client.ID)
+		}
+		return nil, err
+	}
+
+	return s.Get(client.ID)
+}
+
+// create validates and saves a new client.
+func (s *ClientService) create(userID string, req *models.ClientRequest) (*models.Client, error) {
 	// Check quota first
 	quota, err := s.quotaRepo.GetQuota(userID)
 	if err != nil {
@@ -52,15 +149,81 @@ func (s *ClientService) Create(userID string, req *models.ClientRequest) (*model
 	}
 
 	if !quota.CanCreateClient() {
-		return nil, fmt.Errorf("client limit reached: %d/%d", quota.ClientsCount, quota.MaxClients)
+		return nil, apperrors.NewQuotaExceeded(fmt.Sprintf("client limit reached: %d/%d", quota.ClientsCount, quota.MaxClients))
+	}
+
+	if err := validateNewClient(req); err != nil {
+		return nil, err
+	}
+
+	client := newClientFromRequest(userID, req)
+	clientID := client.ID
+
+	secrets, err := s.applyTargetAuth(client, req.TargetAuth)
+	if err != nil {
+		return nil, err
+	}
+
+	// Assign the slug and save under the update lock, so two clients can't take the same slug
+	s.updateMu.Lock()
+	defer s.updateMu.Unlock()
+	if client.Slug, err = s.slugFor(client, req.Slug); err != nil {
+		return nil, err
+	}
+
+	// Save to repository
+	if err := s.clientRepo.Create(client); err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	if err := s.saveSecrets(client, secrets); err != nil {
+		s.clientRepo.Delete(clientID)
+		return nil, err
 	}
 
-	// Generate client ID
-	clientID := uuid.New().String()
+	s.log.Info("Created client: %s (user: %s, name: %s)", clientID, userID, req.Name)
+
+	// Invalidate quota cache
+	s.quotaRepo.(*repository.FileQuotaRepository).InvalidateCache(userID)
+
+	return client, nil
+}
 
-	// Create client model
+// validateNewClient checks the settings of a client to be created that can be checked
+// without the stored clients.
+func validateNewClient(req *models.ClientRequest) error {
+	if req.Schedule != nil {
+		if err := req.Schedule.Validate(); err != nil {
+			return apperrors.NewInvalidInput(fmt.Sprintf("invalid schedule: %v", err))
+		}
+	}
+	if err := ValidateRedactionRules(req.RedactionRules); err != nil {
+		return apperrors.NewInvalidInput(fmt.Sprintf("invalid redaction rule: %v", err))
+	}
+	if err := ValidateIgnoreEvents(req.Provider, req.IgnoreEvents, nil); err != nil {
+		return apperrors.NewInvalidInput(fmt.Sprintf("invalid ignored events: %v", err))
+	}
+	if err := ValidateTargetURL(req.TargetURL); err != nil {
+		return apperrors.NewInvalidInput(fmt.Sprintf("invalid target URL: %v", err))
+	}
+	if req.Ack != nil && strings.ContainsAny(req.Ack.Header, " :\r\n") {
+		return apperrors.NewInvalidInput(fmt.Sprintf("invalid delivery token header: %q", req.Ack.Header))
+	}
+	if req.Sampling != nil {
+		if err := req.Sampling.Validate(); err != nil {
+			return apperrors.NewInvalidInput(fmt.Sprintf("invalid sampling policy: %v", err))
+		}
+	}
+	if _, err := CompilePayloadSchemas(req.PayloadSchema); err != nil {
+		return apperrors.NewInvalidInput(fmt.Sprintf("invalid payload schema: %v", err))
+	}
+	return nil
+}
+
+// newClientFromRequest builds a new client with a fresh ID from a creation request. Target
+// credentials and the slug are applied separately.
+func newClientFromRequest(userID string, req *models.ClientRequest) *models.Client {
 	client := models.NewClient(
-		clientID,
+		uuid.New().String(),
 		userID,
 		req.Name,
 		req.Description,
@@ -69,27 +232,39 @@ func (s *ClientService) Create(userID string, req *models.ClientRequest) (*model
 	)
 
 	// Apply optional settings
+	client.Runbook = req.Runbook
 	if req.TargetTimeout > 0 {
 		client.TargetTimeout = req.TargetTimeout
 	}
 	client.HTTPie = req.HTTPie
+	client.Provider = req.Provider
 	client.IgnoreEvents = req.IgnoreEvents
 	client.NoReplay = req.NoReplay
 	if req.SSEBufferSize > 0 {
 		client.SSEBufferSize = req.SSEBufferSize
 	}
+	client.LogLevel = req.LogLevel
+	client.RetryPolicy = normalizeRetryPolicy(req.RetryPolicy)
+	client.Schedule = req.Schedule
+	client.RedactionRules = req.RedactionRules
+	client.Ack = normalizeAck(req.Ack)
+	client.Sampling = normalizeSampling(req.Sampling)
+	client.PayloadSchema = normalizePayloadSchema(req.PayloadSchema)
+
+	return client
+}
 
-	// Save to repository
-	if err := s.clientRepo.Create(client); err != nil {
-		return nil, fmt.Errorf("failed to create client: %w", err)
+// IgnoreEventPresets returns the presets and provider event catalogs for choosing the
+// events a client of the provider (any provider if empty) ignores.
+func (s *ClientService) IgnoreEventPresets(provider string) *models.IgnoreEventPresetsResponse {
+	catalogs := eventCatalogsOf(provider)
+	presets := make([]models.IgnoreEventPreset, 0, len(models.IgnoreEventPresets))
+	for _, preset := range models.IgnoreEventPresets {
+		if _, ok := catalogs[preset.Provider]; ok {
+			presets = append(presets, preset)
+		}
 	}
-
-	s.log.Info("Created client: %s (user: %s, name: %s)", clientID, userID, req.Name)
-
-	// Invalidate quota cache
-	s.quotaRepo.(*repository.FileQuotaRepository).InvalidateCache(userID)
-
-	return client, nil
+	return &models.IgnoreEventPresetsResponse{Presets: presets, Catalogs: catalogs}
 }
 
 // Get retrieves a client by ID.
@@ -100,14 +275,10 @@ func (s *ClientService) Get(clientID string) (*models.Client, error) {
 	}
 
 	// Update status from process service
-	if s.processService.IsRunning(clientID) {
-		client.Status = models.ClientStatusRunning
-		if processInfo, err := s.processService.GetProcessInfo(clientID); err == nil {
-			client.PID = processInfo.PID
-			client.StartedAt = &processInfo.StartedAt
-		}
-	} else {
-		client.Status = models.ClientStatusStopped
+	client.Status = s.currentStatus(client.ID, client.Status)
+	if processInfo, err := s.processService.GetProcessInfo(clientID); err == nil {
+		client.PID = processInfo.PID
+		client.StartedAt = &processInfo.StartedAt
 	}
 
 	if err := s.populateClientLastActivity(client); err != nil {
@@ -130,13 +301,7 @@ func (s *ClientService) List(userID string, req *models.ClientListRequest) (*mod
 			continue
 		}
 
-		if strings.EqualFold(summary.Status, string(models.ClientStatusError)) {
-			// Preserve error status to surface failed instances after restarts.
-		} else if s.processService.IsRunning(summary.ID) {
-			summary.Status = string(models.ClientStatusRunning)
-		} else {
-			summary.Status = string(models.ClientStatusStopped)
-		}
+		summary.Status = string(s.currentStatus(summary.ID, models.ClientStatus(summary.Status)))
 
 		ts, err := s.eventRepo.GetLatestEventTimestamp(summary.ID)
 		if err != nil {
@@ -169,39 +334,311 @@ func (s *ClientService) List(userID string, req *models.ClientListRequest) (*mod
 }
 
 // Update updates a client instance.
-func (s *ClientService) Update(clientID string, req *models.ClientRequest) (*models.Client, error) {
+// A running client is only updated when applyNow is set: the process is stopped, the new
+// configuration saved and the process started again; if it fails to start, the previous
+// configuration is restored and the process restarted with it.
+// If req.Version is set, the client is only updated if its configuration was not changed since
+// that version was read, so concurrent edits don't silently overwrite each other.
+func (s *ClientService) Update(clientID string, req *models.ClientRequest, applyNow bool) (*models.Client, error) {
+	s.updateMu.Lock()
+	defer s.updateMu.Unlock()
+
 	// Get existing client
 	client, err := s.clientRepo.Get(clientID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Check if running - must stop first
-	if s.processService.IsRunning(clientID) {
-		return nil, fmt.Errorf("cannot update running client - stop it first")
+	return s.update(client, req, applyNow)
+}
+
+// Patch partially updates a client instance: the fields missing from the request keep their
+// current value, then the client is updated like by Update.
+func (s *ClientService) Patch(clientID string, patch *models.ClientPatchRequest, applyNow bool) (*models.Client, error) {
+	s.updateMu.Lock()
+	defer s.updateMu.Unlock()
+
+	client, err := s.clientRepo.Get(clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	req := clientRequestOf(client)
+	patch.ApplyTo(req)
+
+	return s.update(client, req, applyNow)
+}
+
+// clientRequestOf returns the update request that leaves a client unchanged. Target secrets
+// are left empty, which keeps the stored ones.
+func clientRequestOf(client *models.Client) *models.ClientRequest {
+	req := &models.ClientRequest{
+		Name:           client.Name,
+		Slug:           client.Slug,
+		Description:    client.Description,
+		Runbook:        client.Runbook,
+		SmeeURL:        client.SmeeURL,
+		TargetURL:      client.TargetURL,
+		TargetTimeout:  client.TargetTimeout,
+		HTTPie:         client.HTTPie,
+		IgnoreEvents:   client.IgnoreEvents,
+		NoReplay:       client.NoReplay,
+		SSEBufferSize:  client.SSEBufferSize,
+		LogLevel:       client.LogLevel,
+		Provider:       client.Provider,
+		RetryPolicy:    client.RetryPolicy,
+		Schedule:       client.Schedule,
+		RedactionRules: client.RedactionRules,
+		Ack:            client.Ack,
+		Sampling:       client.Sampling,
+		PayloadSchema:  client.PayloadSchema,
+	}
+	if auth := client.TargetAuth; auth != nil {
+		req.TargetAuth = &models.TargetAuthRequest{
+			Type:     auth.Type,
+			Username: auth.Username,
+			TokenURL: auth.TokenURL,
+			ClientID: auth.ClientID,
+			Scopes:   auth.Scopes,
+			Audience: auth.Audience,
+		}
+	}
+	return req
+}
+
+// update applies an update request to a client read from the repository. The caller must
+// hold s.updateMu.
+func (s *ClientService) update(client *models.Client, req *models.ClientRequest, applyNow bool) (*models.Client, error) {
+	clientID := client.ID
+	if req.Version > 0 && req.Version != client.Version {
+		return nil, apperrors.ErrClientConflict.WithDetails(map[string]int{"version": client.Version})
 	}
 
+	// A start or stop in progress would run with the previous configuration
+	if s.inTransition(clientID) {
+		return nil, apperrors.NewClientRunning(fmt.Sprintf("cannot update client while it is being started or stopped: %s", clientID))
+	}
+
+	// Check if running - must stop first unless the update is applied immediately
+	running := s.processService.IsRunning(clientID)
+	if running && !applyNow {
+		return nil, apperrors.NewClientRunning("cannot update running client - stop it first or set applyNow")
+	}
+	if req.Schedule != nil {
+		if err := req.Schedule.Validate(); err != nil {
+			return nil, apperrors.NewInvalidInput(fmt.Sprintf("invalid schedule: %v", err))
+		}
+	}
+	if err := ValidateRedactionRules(req.RedactionRules); err != nil {
+		return nil, apperrors.NewInvalidInput(fmt.Sprintf("invalid redaction rule: %v", err))
+	}
+	if err := ValidateIgnoreEvents(req.Provider, req.IgnoreEvents, client.IgnoreEvents); err != nil {
+		return nil, apperrors.NewInvalidInput(fmt.Sprintf("invalid ignored events: %v", err))
+	}
+	if err := ValidateTargetURL(req.TargetURL); err != nil {
+		return nil, apperrors.NewInvalidInput(fmt.Sprintf("invalid target URL: %v", err))
+	}
+	if req.Ack != nil && strings.ContainsAny(req.Ack.Header, " :\r\n") {
+		return nil, apperrors.NewInvalidInput(fmt.Sprintf("invalid delivery token header: %q", req.Ack.Header))
+	}
+	if req.Sampling != nil {
+		if err := req.Sampling.Validate(); err != nil {
+			return nil, apperrors.NewInvalidInput(fmt.Sprintf("invalid sampling policy: %v", err))
+		}
+	}
+	if _, err := CompilePayloadSchemas(req.PayloadSchema); err != nil {
+		return nil, apperrors.NewInvalidInput(fmt.Sprintf("invalid payload schema: %v", err))
+	}
+	slug := client.Slug
+	if req.Slug != "" && req.Slug != client.Slug {
+		var err error
+		if slug, err = s.slugFor(client, req.Slug); err != nil {
+			return nil, err
+		}
+	}
+	previous := *client
+
 	// Update fields
 	client.Name = req.Name
+	client.Slug = slug
 	client.Description = req.Description
+	client.Runbook = req.Runbook
 	client.TargetURL = req.TargetURL
 	client.TargetTimeout = req.TargetTimeout
 	client.HTTPie = req.HTTPie
+	client.Provider = req.Provider
 	client.IgnoreEvents = req.IgnoreEvents
 	client.NoReplay = req.NoReplay
 	client.SSEBufferSize = req.SSEBufferSize
+	client.LogLevel = req.LogLevel
+	client.RetryPolicy = normalizeRetryPolicy(req.RetryPolicy)
+	client.Schedule = req.Schedule
+	client.RedactionRules = req.RedactionRules
+	client.Ack = normalizeAck(req.Ack)
+	client.Sampling = normalizeSampling(req.Sampling)
+	client.PayloadSchema = normalizePayloadSchema(req.PayloadSchema)
 	client.UpdatedAt = time.Now()
+	client.Version++
+
+	secrets, err := s.applyTargetAuth(client, req.TargetAuth)
+	if err != nil {
+		return nil, err
+	}
+
+	if running {
+		updated, err := s.reloadWithConfig(&previous, client)
+		if err != nil {
+			return nil, err
+		}
+		return updated, s.saveSecrets(updated, secrets)
+	}
 
 	// Save updates
 	if err := s.clientRepo.Update(client); err != nil {
 		return nil, fmt.Errorf("failed to update client: %w", err)
 	}
+	if err := s.saveSecrets(client, secrets); err != nil {
+		return nil, err
+	}
 
 	s.log.Info("Updated client: %s", clientID)
 
 	return client, nil
 }
 
+// applyTargetAuth sets the requested target authentication on a client and returns the
+// client's secrets with the requested password, token or client secret. Secrets the request
+// leaves empty are kept when the authentication type is unchanged. A nil request removes
+// the credentials.
+func (s *ClientService) applyTargetAuth(client *models.Client, req *models.TargetAuthRequest) (*models.ClientSecrets, error) {
+	if req != nil && s.secretRepo == nil {
+		return nil, apperrors.NewInvalidInput("target credentials are not supported on this server")
+	}
+	if s.secretRepo == nil {
+		return nil, nil
+	}
+
+	secrets, err := s.secretRepo.Get(client.UserID, client.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client secrets: %w", err)
+	}
+	previous := client.TargetAuth
+	sameType := previous != nil && req != nil && previous.Type == req.Type
+	if !sameType {
+		secrets.TargetPassword = ""
+		secrets.TargetToken = ""
+		secrets.TargetClientSecret = ""
+	}
+
+	if req == nil {
+		client.TargetAuth = nil
+		return secrets, nil
+	}
+
+	switch req.Type {
+	case models.TargetAuthBasic:
+		if req.Username == "" {
+			return nil, apperrors.NewInvalidInput("target username is required for basic authentication")
+		}
+		if req.Password != "" {
+			secrets.TargetPassword = req.Password
+		}
+	case models.TargetAuthBearer:
+		if req.Token != "" {
+			secrets.TargetToken = req.Token
+		}
+		if secrets.TargetToken == "" {
+			return nil, apperrors.NewInvalidInput("target token is required for bearer authentication")
+		}
+	case models.TargetAuthOAuth2:
+		if tokenURL, err := url.Parse(req.TokenURL); err != nil || (tokenURL.Scheme != "http" && tokenURL.Scheme != "https") || tokenURL.Host == "" {
+			return nil, apperrors.NewInvalidInput("target token URL must be an http or https URL for oauth2 authentication")
+		}
+		if req.ClientID == "" {
+			return nil, apperrors.NewInvalidInput("target client ID is required for oauth2 authentication")
+		}
+		if req.ClientSecret != "" {
+			secrets.TargetClientSecret = req.ClientSecret
+		}
+		if secrets.TargetClientSecret == "" {
+			return nil, apperrors.NewInvalidInput("target client secret is required for oauth2 authentication")
+		}
+	default:
+		return nil, apperrors.NewInvalidInput(fmt.Sprintf("invalid target authentication type: %s", req.Type))
+	}
+	client.TargetAuth = &models.TargetAuth{Type: req.Type}
+	switch req.Type {
+	case models.TargetAuthBasic:
+		client.TargetAuth.Username = req.Username
+	case models.TargetAuthOAuth2:
+		client.TargetAuth.TokenURL = req.TokenURL
+		client.TargetAuth.ClientID = req.ClientID
+		client.TargetAuth.Scopes = req.Scopes
+		client.TargetAuth.Audience = req.Audience
+	}
+
+	return secrets, nil
+}
+
+// saveSecrets stores the secrets returned by applyTargetAuth.
+func (s *ClientService) saveSecrets(client *models.Client, secrets *models.ClientSecrets) error {
+	if secrets == nil {
+		return nil
+	}
+	if err := s.secretRepo.Save(client.UserID, client.ID, secrets); err != nil {
+		return fmt.Errorf("failed to save client secrets: %w", err)
+	}
+	return nil
+}
+
+// reloadWithConfig performs a coordinated stop -> update -> start of a running client,
+// rolling back to the previous configuration if the new one cannot be started.
+func (s *ClientService) reloadWithConfig(previous, updated *models.Client) (*models.Client, error) {
+	clientID := updated.ID
+
+	if err := s.processService.Stop(clientID); err != nil {
+		return nil, fmt.Errorf("failed to stop client: %w", err)
+	}
+
+	startErr := s.clientRepo.Update(updated)
+	if startErr == nil {
+		startErr = s.processService.Start(updated, s.baseDir)
+		if startErr == nil {
+			startErr = s.processService.VerifyStartup(clientID)
+		}
+	}
+
+	if startErr == nil {
+		now := time.Now()
+		if err := updated.Transition(models.ClientStatusRunning, "configuration reloaded"); err != nil {
+			s.log.Error("Failed to record status of client %s: %v", clientID, err)
+		}
+		updated.StartedAt = &now
+		if err := s.clientRepo.Update(updated); err != nil {
+			s.log.Error("Failed to update client status: %v", err)
+		}
+
+		s.log.Info("Updated and reloaded client: %s", clientID)
+		return updated, nil
+	}
+
+	// Roll back to the previous configuration
+	s.log.Error("Failed to apply new configuration for client %s, rolling back: %v", clientID, startErr)
+
+	if s.processService.IsRunning(clientID) {
+		s.processService.Stop(clientID)
+	}
+	if err := s.clientRepo.Update(previous); err != nil {
+		s.log.Error("Failed to restore configuration of client %s: %v", clientID, err)
+	}
+	if err := s.processService.Start(previous, s.baseDir); err != nil {
+		s.log.Error("Failed to restart client %s with previous configuration: %v", clientID, err)
+		return nil, fmt.Errorf("failed to apply configuration (%v) and to restart previous configuration: %w", startErr, err)
+	}
+
+	return nil, fmt.Errorf("failed to apply configuration, previous configuration restored: %w", startErr)
+}
+
 // Delete deletes a client instance.
 func (s *ClientService) Delete(clientID string) error {
 	// Get client first to get userID
@@ -217,6 +654,11 @@ func (s *ClientService) Delete(clientID string) error {
 		}
 	}
 
+	// Events in cold or object storage live outside the client directory
+	if err := s.eventRepo.PurgeClientEvents(clientID); err != nil {
+		return fmt.Errorf("failed to delete stored client events: %w", err)
+	}
+
 	// Delete from repository
 	if err := s.clientRepo.Delete(clientID); err != nil {
 		return fmt.Errorf("failed to delete client: %w", err)
@@ -232,6 +674,11 @@ func (s *ClientService) Delete(clientID string) error {
 
 // Start starts a client instance.
 func (s *ClientService) Start(clientID string) error {
+	if !s.beginTransition(clientID) {
+		return apperrors.NewClientRunning(fmt.Sprintf("client is being started or stopped: %s", clientID))
+	}
+	defer s.endTransition(clientID)
+
 	// Get client
 	client, err := s.clientRepo.Get(clientID)
 	if err != nil {
@@ -240,21 +687,48 @@ func (s *ClientService) Start(clientID string) error {
 
 	// Check if already running
 	if s.processService.IsRunning(clientID) {
-		return fmt.Errorf("client already running: %s", clientID)
+		return apperrors.NewClientRunning(fmt.Sprintf("client already running: %s", clientID))
+	}
+
+	// Show the client as starting while it waits for a start slot and its startup check
+	previous := s.settledStatus(clientID, client.Status)
+	client.Status = previous
+	if err := s.transition(client, models.ClientStatusStarting, ""); err != nil {
+		return err
 	}
 
+	// A manual start gives the client a fresh restart budget
+	s.processService.ResetRestartBudget(clientID)
+
 	// Start process
 	if err := s.processService.Start(client, s.baseDir); err != nil {
+		var appErr *apperrors.AppError
+		if !errors.As(err, &appErr) || (appErr.Code != apperrors.CodeCapacityReached && appErr.Code != apperrors.CodeQuotaExceeded) {
+			s.recordStartError(client, err)
+		} else if transitionErr := s.transition(client, previous, err.Error()); transitionErr != nil {
+			// The client is fine when a running clients limit is reached, it just wasn't started
+			s.log.Error("Failed to record rejected start of client %s: %v", clientID, transitionErr)
+		}
 		return fmt.Errorf("failed to start client: %w", err)
 	}
 
+	// Only report success once the process actually came up
+	if err := s.verifyStartup(client); err != nil {
+		return err
+	}
+
 	// Update client status
 	now := time.Now()
-	client.Status = models.ClientStatusRunning
+	if err := client.Transition(models.ClientStatusRunning, ""); err != nil {
+		s.log.Error("Failed to record status of client %s: %v", clientID, err)
+	}
 	client.StartedAt = &now
 	client.UpdatedAt = now
+	client.LastError = ""
+	client.LastExitCode = nil
+	client.LastErrorCategory = ""
 
-	if err := s.clientRepo.Update(client); err != nil {
+	if err := s.saveStatus(client); err != nil {
 		s.log.Error("Failed to update client status: %v", err)
 	}
 
@@ -263,8 +737,19 @@ func (s *ClientService) Start(clientID string) error {
 	return nil
 }
 
-// Stop stops a client instance.
+// Stop stops a client instance, giving its process the server's stop timeout to exit.
 func (s *ClientService) Stop(clientID string) error {
+	return s.StopWith(clientID, &models.ClientStopRequest{})
+}
+
+// StopWith stops a client instance, killing its process right away with req.Force or after
+// req.Timeout seconds if set.
+func (s *ClientService) StopWith(clientID string, req *models.ClientStopRequest) error {
+	if !s.beginTransition(clientID) {
+		return apperrors.NewClientRunning(fmt.Sprintf("client is being started or stopped: %s", clientID))
+	}
+	defer s.endTransition(clientID)
+
 	// Get client
 	client, err := s.clientRepo.Get(clientID)
 	if err != nil {
@@ -273,21 +758,40 @@ func (s *ClientService) Stop(clientID string) error {
 
 	// Check if running
 	if !s.processService.IsRunning(clientID) {
-		return fmt.Errorf("client not running: %s", clientID)
+		return apperrors.NewClientNotRunning(fmt.Sprintf("client not running: %s", clientID))
+	}
+
+	client.Status = s.settledStatus(clientID, client.Status)
+	if err := s.transition(client, models.ClientStatusStopping, ""); err != nil {
+		return err
 	}
 
 	// Stop process
-	if err := s.processService.Stop(clientID); err != nil {
+	switch {
+	case req.Force:
+		err = s.processService.StopWithTimeout(clientID, 0)
+	case req.Timeout != nil:
+		err = s.processService.StopWithTimeout(clientID, time.Duration(*req.Timeout)*time.Second)
+	default:
+		err = s.processService.Stop(clientID)
+	}
+	if err != nil {
+		// The process is gone either way
+		if transitionErr := s.transition(client, models.ClientStatusStopped, err.Error()); transitionErr != nil {
+			s.log.Error("Failed to record status of client %s: %v", clientID, transitionErr)
+		}
 		return fmt.Errorf("failed to stop client: %w", err)
 	}
 
 	// Update client status
 	now := time.Now()
-	client.Status = models.ClientStatusStopped
+	if err := client.Transition(models.ClientStatusStopped, ""); err != nil {
+		s.log.Error("Failed to record status of client %s: %v", clientID, err)
+	}
 	client.StoppedAt = &now
 	client.UpdatedAt = now
 
-	if err := s.clientRepo.Update(client); err != nil {
+	if err := s.saveStatus(client); err != nil {
 		s.log.Error("Failed to update client status: %v", err)
 	}
 
@@ -298,25 +802,45 @@ func (s *ClientService) Stop(clientID string) error {
 
 // Restart restarts a client instance.
 func (s *ClientService) Restart(clientID string) error {
+	if !s.beginTransition(clientID) {
+		return apperrors.NewClientRunning(fmt.Sprintf("client is being started or stopped: %s", clientID))
+	}
+	defer s.endTransition(clientID)
+
 	// Get client
 	client, err := s.clientRepo.Get(clientID)
 	if err != nil {
 		return err
 	}
 
+	client.Status = s.settledStatus(clientID, client.Status)
+	if err := s.transition(client, models.ClientStatusStarting, "restart"); err != nil {
+		return err
+	}
+
 	// Restart process
 	if err := s.processService.Restart(client, s.baseDir); err != nil {
+		s.recordStartError(client, err)
 		return fmt.Errorf("failed to restart client: %w", err)
 	}
 
+	if err := s.verifyStartup(client); err != nil {
+		return err
+	}
+
 	// Update client status
 	now := time.Now()
-	client.Status = models.ClientStatusRunning
+	if err := client.Transition(models.ClientStatusRunning, ""); err != nil {
+		s.log.Error("Failed to record status of client %s: %v", clientID, err)
+	}
 	client.StartedAt = &now
 	client.RestartCount++
 	client.UpdatedAt = now
+	client.LastError = ""
+	client.LastExitCode = nil
+	client.LastErrorCategory = ""
 
-	if err := s.clientRepo.Update(client); err != nil {
+	if err := s.saveStatus(client); err != nil {
 		s.log.Error("Failed to update client status: %v", err)
 	}
 
@@ -325,6 +849,268 @@ func (s *ClientService) Restart(clientID string) error {
 	return nil
 }
 
+// verifyStartup runs the startup health check of a freshly started client. A process that is
+// still running but failed the check (e.g. never connected) is stopped and the client marked as errored;
+// a process that exited is recorded by handleProcessExit.
+func (s *ClientService) verifyStartup(client *models.Client) error {
+	err := s.processService.VerifyStartup(client.ID)
+	if err == nil {
+		return nil
+	}
+
+	if s.processService.IsRunning(client.ID) {
+		var logLines []string
+		if info, infoErr := s.processService.GetProcessInfo(client.ID); infoErr == nil {
+			logLines = info.GetLogLines()
+		}
+		if stopErr := s.processService.Stop(client.ID); stopErr != nil {
+			s.log.Error("Failed to stop unhealthy client %s: %v", client.ID, stopErr)
+		}
+
+		now := time.Now()
+		if transitionErr := client.Transition(models.ClientStatusError, err.Error()); transitionErr != nil {
+			s.log.Error("Failed to record status of client %s: %v", client.ID, transitionErr)
+		}
+		client.LastError = err.Error()
+		client.LastErrorCategory = classifyProcessError(client, err.Error(), logLines)
+		client.StoppedAt = &now
+		client.UpdatedAt = now
+		if updateErr := s.saveStatus(client); updateErr != nil {
+			s.log.Error("Failed to update client status: %v", updateErr)
+		}
+	}
+
+	s.log.Error("Client %s failed its startup check: %v", client.ID, err)
+	return fmt.Errorf("client failed to start: %w", err)
+}
+
+// recordStartError records on the client why its process could not be started at all
+// (e.g. the gosmee binary is missing).
+func (s *ClientService) recordStartError(client *models.Client, err error) {
+	now := time.Now()
+	if transitionErr := client.Transition(models.ClientStatusError, err.Error()); transitionErr != nil {
+		s.log.Error("Failed to record status of client %s: %v", client.ID, transitionErr)
+	}
+	client.LastError = err.Error()
+	client.LastErrorCategory = classifyProcessError(client, err.Error(), nil)
+	client.LastExitCode = nil
+	client.UpdatedAt = now
+	if updateErr := s.saveStatus(client); updateErr != nil {
+		s.log.Error("Failed to update client status: %v", updateErr)
+	}
+}
+
+// transition moves a client to a new status and saves it, so the status is visible while the
+// start or stop is in progress.
+func (s *ClientService) transition(client *models.Client, to models.ClientStatus, reason string) error {
+	if err := client.Transition(to, reason); err != nil {
+		return apperrors.NewInvalidTransition(err.Error())
+	}
+	client.UpdatedAt = time.Now()
+	if err := s.saveStatus(client); err != nil {
+		s.log.Error("Failed to update client status: %v", err)
+	}
+	return nil
+}
+
+// saveStatus saves the status of a client being started or stopped. Only the status fields are
+// written, on the client re-read under s.updateMu, so configuration changes saved meanwhile
+// (e.g. pausing the client) are kept.
+func (s *ClientService) saveStatus(client *models.Client) error {
+	s.updateMu.Lock()
+	defer s.updateMu.Unlock()
+
+	stored, err := s.clientRepo.Get(client.ID)
+	if err != nil {
+		return err
+	}
+	stored.Status = client.Status
+	stored.StatusChangedAt = client.StatusChangedAt
+	stored.Transitions = client.Transitions
+	stored.StartedAt = client.StartedAt
+	stored.StoppedAt = client.StoppedAt
+	stored.RestartCount = client.RestartCount
+	stored.LastError = client.LastError
+	stored.LastExitCode = client.LastExitCode
+	stored.LastErrorCategory = client.LastErrorCategory
+	stored.UpdatedAt = client.UpdatedAt
+	return s.clientRepo.Update(stored)
+}
+
+// beginTransition marks a client as being started or stopped. It returns false if another
+// start or stop of the client is in progress.
+func (s *ClientService) beginTransition(clientID string) bool {
+	s.transitionMu.Lock()
+	defer s.transitionMu.Unlock()
+
+	if s.transitioning[clientID] {
+		return false
+	}
+	s.transitioning[clientID] = true
+	return true
+}
+
+// endTransition clears the mark set by beginTransition.
+func (s *ClientService) endTransition(clientID string) {
+	s.transitionMu.Lock()
+	defer s.transitionMu.Unlock()
+
+	delete(s.transitioning, clientID)
+}
+
+// inTransition reports whether a client is being started or stopped.
+func (s *ClientService) inTransition(clientID string) bool {
+	s.transitionMu.Lock()
+	defer s.transitionMu.Unlock()
+
+	return s.transitioning[clientID]
+}
+
+// currentStatus returns the status of a client: the stored starting or stopping status while
+// the start or stop is in progress, otherwise its settled status.
+func (s *ClientService) currentStatus(clientID string, stored models.ClientStatus) models.ClientStatus {
+	if (stored == models.ClientStatusStarting || stored == models.ClientStatusStopping) && s.inTransition(clientID) {
+		return stored
+	}
+	return s.settledStatus(clientID, stored)
+}
+
+// settledStatus returns the status of a client from the process state, preserving the error
+// status of failed clients. A starting or stopping status left over from an interrupted start
+// or stop (e.g. a server restart) is resolved the same way.
+func (s *ClientService) settledStatus(clientID string, stored models.ClientStatus) models.ClientStatus {
+	switch {
+	case s.processService.IsRunning(clientID):
+		return models.ClientStatusRunning
+	case stored == models.ClientStatusError:
+		return models.ClientStatusError
+	default:
+		return models.ClientStatusStopped
+	}
+}
+
+// Pause holds event forwarding of a client: events keep being received and saved but are not
+// forwarded to the target until Resume is called. A running client keeps its event stream open.
+func (s *ClientService) Pause(clientID string) (*models.Client, error) {
+	return s.setPaused(clientID, true)
+}
+
+// Resume resumes event forwarding of a paused client.
+func (s *ClientService) Resume(clientID string) (*models.Client, error) {
+	return s.setPaused(clientID, false)
+}
+
+// setPaused persists the paused flag. Forwarding is done by the backend, which checks the flag
+// of every delivery, so a running process is left alone.
+func (s *ClientService) setPaused(clientID string, paused bool) (*models.Client, error) {
+	s.updateMu.Lock()
+	defer s.updateMu.Unlock()
+
+	client, err := s.clientRepo.Get(clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	if client.Paused == paused {
+		return client, nil
+	}
+
+	client.Paused = paused
+	client.UpdatedAt = time.Now()
+
+	if err := s.clientRepo.Update(client); err != nil {
+		return nil, fmt.Errorf("failed to update client: %w", err)
+	}
+
+	if paused {
+		s.log.Info("Paused forwarding of client: %s", clientID)
+	} else {
+		s.log.Info("Resumed forwarding of client: %s", clientID)
+	}
+
+	return client, nil
+}
+
+// ReconcileProcesses adopts or terminates the gosmee processes of clients left running by a
+// previous backend, e.g. after a crash, and logs the processes left unmanaged. It is run on startup.
+func (s *ClientService) ReconcileProcesses() (*models.ProcessReconcileReport, error) {
+	clients, err := s.clientRepo.ListAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clients: %w", err)
+	}
+
+	report, err := s.processService.Reconcile(clients, s.baseDir, true)
+	if err != nil {
+		return nil, err
+	}
+	for _, process := range report.Unmanaged {
+		s.log.Info("Unmanaged gosmee process %d of client %s/%s left running (%s)",
+			process.PID, process.UserID, process.ClientID, process.Reason)
+	}
+	return report, nil
+}
+
+// UnmanagedProcesses returns the gosmee processes saving events to the data directory that
+// are not managed by the backend.
+func (s *ClientService) UnmanagedProcesses() ([]*models.UnmanagedProcess, error) {
+	clients, err := s.clientRepo.ListAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clients: %w", err)
+	}
+
+	report, err := s.processService.Reconcile(clients, s.baseDir, false)
+	if err != nil {
+		return nil, err
+	}
+	return report.Unmanaged, nil
+}
+
+// ApplySchedules starts and stops clients according to their schedules.
+// It is executed periodically by the scheduler and only acts when a client's scheduled state
+// changes, so manual starts and stops are respected until the next window boundary.
+func (s *ClientService) ApplySchedules() {
+	clients, err := s.clientRepo.ListAll()
+	if err != nil {
+		s.log.Error("Failed to list clients for schedules: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for _, client := range clients {
+		if client.Schedule == nil || !client.Schedule.Enabled {
+			s.scheduleMu.Lock()
+			delete(s.scheduleStates, client.ID)
+			s.scheduleMu.Unlock()
+			continue
+		}
+
+		desired := client.Schedule.Active(now)
+
+		s.scheduleMu.Lock()
+		last, seen := s.scheduleStates[client.ID]
+		s.scheduleStates[client.ID] = desired
+		s.scheduleMu.Unlock()
+
+		if seen && last == desired {
+			continue
+		}
+
+		running := s.processService.IsRunning(client.ID)
+		switch {
+		case desired && !running:
+			s.log.Info("Starting client %s according to its schedule", client.ID)
+			if err := s.Start(client.ID); err != nil {
+				s.log.Error("Scheduled start of client %s failed: %v", client.ID, err)
+			}
+		case !desired && running:
+			s.log.Info("Stopping client %s according to its schedule", client.ID)
+			if err := s.Stop(client.ID); err != nil {
+				s.log.Error("Scheduled stop of client %s failed: %v", client.ID, err)
+			}
+		}
+	}
+}
+
 // GetStats retrieves statistics for a client.
 func (s *ClientService) GetStats(clientID string) (*models.ClientStats, error) {
 	client, err := s.clientRepo.Get(clientID)
@@ -347,11 +1133,66 @@ func (s *ClientService) GetStats(clientID string) (*models.ClientStats, error) {
 		stats.RunningTime = int64(time.Since(*client.StartedAt).Seconds())
 	}
 
-	// TODO: Calculate success rate, average latency from event data
+	if s.ingestion != nil {
+		stats.DroppedEvents = s.ingestion.Dropped(clientID)
+	}
+	if s.sampler != nil {
+		stats.SampledOutEvents = s.sampler.Discarded(clientID)
+	}
+
+	if s.statsService != nil {
+		if err := s.statsService.applyTotals(client, stats); err != nil {
+			s.log.Error("Failed to read stats rollups of client %s: %v", clientID, err)
+		}
+	}
 
 	return stats, nil
 }
 
+// normalizeRetryPolicy applies defaults to a requested retry policy; disabled policies are dropped.
+func normalizeRetryPolicy(policy *models.RetryPolicy) *models.RetryPolicy {
+	if policy == nil || !policy.Enabled {
+		return nil
+	}
+	normalized := *policy
+	normalized.Normalize()
+	return &normalized
+}
+
+// normalizeAck returns the delivery receipt configuration to store: nil unless enabled,
+// with the default header filled in.
+func normalizeAck(ack *models.AckConfig) *models.AckConfig {
+	if ack == nil || !ack.Enabled {
+		return nil
+	}
+	normalized := *ack
+	if normalized.Header == "" {
+		normalized.Header = models.DefaultAckHeader
+	}
+	return &normalized
+}
+
+// normalizeSampling returns the sampling policy to store: nil unless a mode is set.
+func normalizeSampling(policy *models.SamplingPolicy) *models.SamplingPolicy {
+	if policy == nil || policy.Mode == "" {
+		return nil
+	}
+	normalized := *policy
+	return &normalized
+}
+
+// normalizePayloadSchema returns the payload schemas to store: nil unless there is a schema.
+func normalizePayloadSchema(config *models.PayloadSchemaConfig) *models.PayloadSchemaConfig {
+	if config == nil || (len(config.Default) == 0 && len(config.EventTypes) == 0) {
+		return nil
+	}
+	normalized := *config
+	if len(normalized.EventTypes) == 0 {
+		normalized.EventTypes = nil
+	}
+	return &normalized
+}
+
 // populateClientLastActivity refreshes the last activity timestamp from stored events.
 func (s *ClientService) populateClientLastActivity(client *models.Client) error {
 	if client == nil || s.eventRepo == nil {
@@ -381,16 +1222,8 @@ func (s *ClientService) countClientsByStatus(userID, status string) (int, error)
 			continue
 		}
 
-		actualStatus := string(client.Status)
-		if !strings.EqualFold(actualStatus, string(models.ClientStatusError)) {
-			if s.processService.IsRunning(client.ID) {
-				actualStatus = string(models.ClientStatusRunning)
-			} else {
-				actualStatus = string(models.ClientStatusStopped)
-			}
-		}
-
-		if strings.ToLower(actualStatus) == targetStatus {
+		actualStatus := string(s.currentStatus(client.ID, client.Status))
+		if actualStatus == targetStatus {
 			count++
 		}
 	}
@@ -398,6 +1231,11 @@ func (s *ClientService) countClientsByStatus(userID, status string) (int, error)
 	return count, nil
 }
 
+// UserClientIDs returns the IDs of all clients of a user.
+func (s *ClientService) UserClientIDs(userID string) ([]string, error) {
+	return s.getBatchTargetClientIDs(userID, &models.ClientBatchRequest{All: true})
+}
+
 // getBatchTargetClientIDs resolves the list of client IDs for a batch operation.
 func (s *ClientService) getBatchTargetClientIDs(userID string, req *models.ClientBatchRequest) ([]string, error) {
 	if req == nil {
@@ -448,34 +1286,25 @@ func (s *ClientService) BatchStart(userID string, req *models.ClientBatchRequest
 		return response, nil
 	}
 
-	for _, clientID := range clientIDs {
-		result := &models.ClientBatchResult{
-			ClientID: clientID,
-		}
-
-		client, err := s.clientRepo.Get(clientID)
-		if err != nil {
-			result.Message = fmt.Sprintf("failed to load client: %v", err)
-			response.Failed++
-			response.Results = append(response.Results, result)
-			continue
-		}
-
-		if client.UserID != userID {
-			result.Message = "client does not belong to current user"
-			response.Failed++
-			response.Results = append(response.Results, result)
-			continue
-		}
+	// Clients are started concurrently, the process service queues the starts beyond
+	// --max-concurrent-starts
+	results := make([]*models.ClientBatchResult, len(clientIDs))
+	var wg sync.WaitGroup
+	for i, clientID := range clientIDs {
+		wg.Add(1)
+		go func(i int, clientID string) {
+			defer wg.Done()
+			results[i] = s.batchStartClient(userID, clientID)
+		}(i, clientID)
+	}
+	wg.Wait()
 
-		if err := s.Start(clientID); err != nil {
-			result.Message = err.Error()
-			response.Failed++
-		} else {
-			result.Success = true
+	for _, result := range results {
+		if result.Success {
 			response.Successful++
+		} else {
+			response.Failed++
 		}
-
 		response.Results = append(response.Results, result)
 	}
 
@@ -485,6 +1314,31 @@ func (s *ClientService) BatchStart(userID string, req *models.ClientBatchRequest
 	return response, nil
 }
 
+// batchStartClient starts a client of a batch start.
+func (s *ClientService) batchStartClient(userID, clientID string) *models.ClientBatchResult {
+	result := &models.ClientBatchResult{
+		ClientID: clientID,
+	}
+
+	client, err := s.clientRepo.Get(clientID)
+	if err != nil {
+		result.Message = fmt.Sprintf("failed to load client: %v", err)
+		return result
+	}
+
+	if client.UserID != userID {
+		result.Message = "client does not belong to current user"
+		return result
+	}
+
+	if err := s.Start(clientID); err != nil {
+		result.Message = err.Error()
+	} else {
+		result.Success = true
+	}
+	return result
+}
+
 // BatchStop stops multiple clients for a user.
 func (s *ClientService) BatchStop(userID string, req *models.ClientBatchRequest) (*models.ClientBatchResponse, error) {
 	clientIDs, err := s.getBatchTargetClientIDs(userID, req)
@@ -537,3 +1391,107 @@ func (s *ClientService) BatchStop(userID string, req *models.ClientBatchRequest)
 
 	return response, nil
 }
+
+// RollingRestart restarts the running clients of a user batch by batch as an asynchronous job.
+// Each batch must pass a startup health check before the next one is restarted; on failure the
+// rollout is aborted unless ContinueOnError is set.
+func (s *ClientService) RollingRestart(userID string, req *models.ClientRollingRestartRequest) (*models.Job, error) {
+	clientIDs, err := s.getBatchTargetClientIDs(userID, &models.ClientBatchRequest{ClientIDs: req.ClientIDs})
+	if err != nil {
+		return nil, err
+	}
+
+	// Only running clients owned by the user are restarted
+	targets := make([]string, 0, len(clientIDs))
+	for _, clientID := range clientIDs {
+		client, err := s.clientRepo.Get(clientID)
+		if err != nil || client.UserID != userID {
+			continue
+		}
+		if s.processService.IsRunning(clientID) {
+			targets = append(targets, clientID)
+		}
+	}
+
+	batchSize := req.BatchSize
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+	delay := 5 * time.Second
+	if req.DelaySeconds > 0 {
+		delay = time.Duration(req.DelaySeconds) * time.Second
+	}
+
+	job := s.jobService.Submit(userID, "rolling-restart", len(targets), func(progress JobProgressFunc) (interface{}, error) {
+		response := &models.ClientBatchResponse{
+			Total:   len(targets),
+			Results: make([]*models.ClientBatchResult, 0, len(targets)),
+		}
+
+		aborted := false
+		for start := 0; start < len(targets); start += batchSize {
+			end := start + batchSize
+			if end > len(targets) {
+				end = len(targets)
+			}
+			batch := targets[start:end]
+
+			if aborted {
+				for _, clientID := range batch {
+					response.Results = append(response.Results, &models.ClientBatchResult{
+						ClientID: clientID,
+						Message:  "skipped: rollout aborted after a failed health check",
+					})
+					response.Failed++
+				}
+				continue
+			}
+
+			if start > 0 {
+				time.Sleep(delay)
+			}
+
+			for _, result := range s.restartBatch(batch) {
+				response.Results = append(response.Results, result)
+				if result.Success {
+					response.Successful++
+				} else {
+					response.Failed++
+					aborted = !req.ContinueOnError
+				}
+			}
+			progress(end, len(targets))
+		}
+
+		s.log.Info("Rolling restart completed: user=%s, total=%d, successful=%d, failed=%d",
+			userID, response.Total, response.Successful, response.Failed)
+
+		return response, nil
+	})
+
+	return job, nil
+}
+
+// restartBatch restarts a batch of clients concurrently and verifies each one stays up.
+func (s *ClientService) restartBatch(clientIDs []string) []*models.ClientBatchResult {
+	results := make([]*models.ClientBatchResult, len(clientIDs))
+
+	var wg sync.WaitGroup
+	for i, clientID := range clientIDs {
+		wg.Add(1)
+		go func(i int, clientID string) {
+			defer wg.Done()
+
+			result := &models.ClientBatchResult{ClientID: clientID}
+			if err := s.Restart(clientID); err != nil {
+				result.Message = err.Error()
+			} else {
+				result.Success = true
+			}
+			results[i] = result
+		}(i, clientID)
+	}
+	wg.Wait()
+
+	return results
+}
@@ -86,9 +86,9 @@ var _ = Describe("ClientService last activity enrichment", func() {
 		eventRepo := repository.NewFileEventRepository(baseDir)
 		quotaRepo := repository.NewFileQuotaRepository(baseDir, 10*1024*1024, 1000)
 		log := logger.New()
-		processService := service.NewProcessService(false, 0, log)
+		processService := service.NewProcessService(false, 0, time.Minute, log)
 
-		clientService := service.NewClientService(clientRepo, quotaRepo, eventRepo, processService, baseDir, log)
+		clientService := service.NewClientService(clientRepo, quotaRepo, eventRepo, processService, service.NewJobService(time.Hour, log), baseDir, log)
 
 		return clientService, clientRepo, quotaRepo
 	}
@@ -0,0 +1,94 @@
+package service_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("Partial client updates", func() {
+	var clientService *service.ClientService
+
+	BeforeEach(func() {
+		baseDir := GinkgoT().TempDir()
+		log := logger.New()
+		clientRepo, err := repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+
+		clientService = service.NewClientService(
+			clientRepo,
+			repository.NewFileQuotaRepository(baseDir, 10*1024*1024, 10),
+			repository.NewFileEventRepository(baseDir),
+			service.NewProcessService(false, 0, time.Minute, log),
+			service.NewJobService(time.Hour, log),
+			baseDir,
+			log,
+		)
+		clientService.SetSecretRepository(repository.NewFileSecretRepository(baseDir))
+	})
+
+	It("changes only the fields present in the request", func() {
+		client, err := clientService.Create("user", &models.ClientRequest{
+			Name:          "relay",
+			Description:   "Feeds the CI",
+			SmeeURL:       "https://smee.io/abc",
+			TargetURL:     "http://127.0.0.1:1/hook",
+			TargetTimeout: 30,
+			IgnoreEvents:  []string{"ping"},
+			TargetAuth:    &models.TargetAuthRequest{Type: models.TargetAuthBearer, Token: "tok"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		name := "renamed"
+		patched, err := clientService.Patch(client.ID, &models.ClientPatchRequest{Name: &name}, false)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(patched.Name).To(Equal("renamed"))
+		Expect(patched.Description).To(Equal("Feeds the CI"))
+		Expect(patched.IgnoreEvents).To(Equal([]string{"ping"}))
+		Expect(patched.TargetTimeout).To(Equal(30))
+		Expect(patched.TargetAuth).To(Equal(&models.TargetAuth{Type: models.TargetAuthBearer}))
+		Expect(patched.Version).To(Equal(2))
+	})
+
+	It("clears list fields set to an empty list", func() {
+		client, err := clientService.Create("user", &models.ClientRequest{
+			Name:         "relay",
+			SmeeURL:      "https://smee.io/abc",
+			TargetURL:    "http://127.0.0.1:1/hook",
+			IgnoreEvents: []string{"ping"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		patched, err := clientService.Patch(client.ID, &models.ClientPatchRequest{IgnoreEvents: &[]string{}}, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(patched.IgnoreEvents).To(BeEmpty())
+		Expect(patched.Name).To(Equal("relay"))
+	})
+
+	It("keeps the runbook unless it is patched", func() {
+		client, err := clientService.Create("user", &models.ClientRequest{
+			Name:      "relay",
+			Runbook:   "## Escalation\n\nPage the CI team.",
+			SmeeURL:   "https://smee.io/abc",
+			TargetURL: "http://127.0.0.1:1/hook",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		name := "renamed"
+		patched, err := clientService.Patch(client.ID, &models.ClientPatchRequest{Name: &name}, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(patched.Runbook).To(Equal("## Escalation\n\nPage the CI team."))
+
+		empty := ""
+		patched, err = clientService.Patch(client.ID, &models.ClientPatchRequest{Runbook: &empty}, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(patched.Runbook).To(BeEmpty())
+	})
+})
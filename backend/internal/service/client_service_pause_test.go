@@ -0,0 +1,135 @@
+package service_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("Pausing clients", func() {
+	var (
+		clientService  *service.ClientService
+		eventService   *service.EventService
+		processService *service.ProcessService
+		eventRepo      *repository.FileEventRepository
+		client         *models.Client
+		deliveries     atomic.Int32
+	)
+
+	BeforeEach(func() {
+		binDir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(binDir, "gosmee"), []byte("#!/bin/sh\nexec sleep 30\n"), 0755)).To(Succeed())
+		GinkgoT().Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+		deliveries.Store(0)
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			deliveries.Add(1)
+		}))
+		DeferCleanup(target.Close)
+
+		baseDir := GinkgoT().TempDir()
+		log := logger.New()
+		clientRepo, err := repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		eventRepo = repository.NewFileEventRepository(baseDir)
+		jobService := service.NewJobService(time.Hour, log)
+		processService = service.NewProcessService(false, 0, time.Minute, log)
+		processService.SetStartupCheck(200*time.Millisecond, nil, 0)
+		clientService = service.NewClientService(
+			clientRepo,
+			repository.NewFileQuotaRepository(baseDir, 10*1024*1024, 10),
+			eventRepo,
+			processService,
+			jobService,
+			baseDir,
+			log,
+		)
+		eventService = service.NewEventService(
+			eventRepo,
+			clientRepo,
+			jobService,
+			service.NewCircuitBreakerService(0, time.Minute, service.NewNotificationService(log), log),
+			log,
+		)
+
+		client, err = clientService.Create("user-pause", &models.ClientRequest{
+			Name:             "paused",
+			SmeeURL:          "https://smee.example.com/channel",
+			TargetURL:        target.URL,
+			TargetTimeout:    5,
+			StartImmediately: true,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(processService.StopAll)
+	})
+
+	saveEvent := func(id string, status models.EventStatus) {
+		Expect(eventRepo.Save(client.ID, &models.Event{
+			ID: id, ClientID: client.ID, Timestamp: time.Now().UTC(), Status: status, Payload: `{}`,
+		})).To(Succeed())
+	}
+
+	It("keeps the running process while paused and resumed", func() {
+		info, err := processService.GetProcessInfo(client.ID)
+		Expect(err).NotTo(HaveOccurred())
+		pid := info.PID
+
+		paused, err := clientService.Pause(client.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(paused.Paused).To(BeTrue())
+		info, err = processService.GetProcessInfo(client.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.PID).To(Equal(pid))
+
+		resumed, err := clientService.Resume(client.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resumed.Paused).To(BeFalse())
+		info, err = processService.GetProcessInfo(client.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.PID).To(Equal(pid))
+		Expect(processService.IsRunning(client.ID)).To(BeTrue())
+	})
+
+	It("forwards nothing while paused", func() {
+		_, err := clientService.Pause(client.ID)
+		Expect(err).NotTo(HaveOccurred())
+		saveEvent("new", models.EventStatusNotReplayed)
+		saveEvent("failed", models.EventStatusFailed)
+
+		eventService.ForwardNewEvents(client.ID, []string{"new"})
+		stored, err := eventRepo.Get(client.ID, "new")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored.Status).To(Equal(models.EventStatusNotReplayed))
+
+		injected, err := eventService.Inject(client.ID, &models.EventInjectRequest{Payload: []byte(`{}`), Forward: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(injected.Forward.Paused).To(BeTrue())
+		Expect(injected.Event.Status).To(Equal(models.EventStatusNotReplayed))
+
+		_, err = eventService.Replay(client.ID, &models.EventReplayRequest{EventIDs: []string{"failed"}})
+		Expect(err).To(MatchError(apperrors.ErrClientPaused))
+		_, err = eventService.ReplayFailed("user-pause", client.ID, &models.EventReplayFailedRequest{})
+		Expect(err).To(MatchError(apperrors.ErrClientPaused))
+		Expect(deliveries.Load()).To(BeZero())
+
+		_, err = clientService.Resume(client.ID)
+		Expect(err).NotTo(HaveOccurred())
+		eventService.ForwardNewEvents(client.ID, []string{"new"})
+		stored, err = eventRepo.Get(client.ID, "new")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored.Status).To(Equal(models.EventStatusSuccess))
+		Expect(deliveries.Load()).To(Equal(int32(1)))
+	})
+})
@@ -0,0 +1,148 @@
+package service_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("Applying updates to running clients", func() {
+	var (
+		clientService  *service.ClientService
+		processService *service.ProcessService
+		clientRepo     repository.ClientRepository
+		client         *models.Client
+	)
+
+	BeforeEach(func() {
+		// The fake gosmee fails to start for targets containing "broken"
+		binDir := GinkgoT().TempDir()
+		script := "#!/bin/sh\ncase \"$*\" in *broken*) echo 'target rejected' >&2; exit 3;; esac\nexec sleep 30\n"
+		Expect(os.WriteFile(filepath.Join(binDir, "gosmee"), []byte(script), 0755)).To(Succeed())
+		GinkgoT().Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+		baseDir := GinkgoT().TempDir()
+		log := logger.New()
+		var err error
+		clientRepo, err = repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		processService = service.NewProcessService(false, 0, time.Minute, log)
+		processService.SetStartupCheck(200*time.Millisecond, nil, 0)
+		clientService = service.NewClientService(
+			clientRepo,
+			repository.NewFileQuotaRepository(baseDir, 10*1024*1024, 10),
+			repository.NewFileEventRepository(baseDir),
+			processService,
+			service.NewJobService(time.Hour, log),
+			baseDir,
+			log,
+		)
+
+		client, err = clientService.Create("user-reload", &models.ClientRequest{
+			Name:             "reload",
+			SmeeURL:          "https://smee.example.com/channel",
+			TargetURL:        "http://127.0.0.1:1/hook",
+			StartImmediately: true,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(processService.StopAll)
+	})
+
+	pid := func() int {
+		info, err := processService.GetProcessInfo(client.ID)
+		Expect(err).NotTo(HaveOccurred())
+		return info.PID
+	}
+
+	update := func(targetURL string) (*models.Client, error) {
+		return clientService.Update(client.ID, &models.ClientRequest{
+			Name:      client.Name,
+			SmeeURL:   client.SmeeURL,
+			TargetURL: targetURL,
+			Version:   client.Version,
+		}, true)
+	}
+
+	It("restarts the process with the new configuration", func() {
+		previousPID := pid()
+
+		updated, err := update("http://127.0.0.1:1/new-hook")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated.TargetURL).To(Equal("http://127.0.0.1:1/new-hook"))
+		Expect(updated.Status).To(Equal(models.ClientStatusRunning))
+		Expect(updated.Version).To(Equal(client.Version + 1))
+		Expect(processService.IsRunning(client.ID)).To(BeTrue())
+		Expect(pid()).NotTo(Equal(previousPID))
+
+		stored, err := clientRepo.Get(client.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored.TargetURL).To(Equal("http://127.0.0.1:1/new-hook"))
+		Expect(stored.Version).To(Equal(updated.Version))
+	})
+
+	It("restores and restarts the previous configuration when the new one fails to start", func() {
+		_, err := update("http://127.0.0.1:1/broken")
+		Expect(err).To(MatchError(ContainSubstring("previous configuration restored")))
+		Expect(err).To(MatchError(ContainSubstring("process exited during startup")))
+
+		stored, err := clientRepo.Get(client.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored.TargetURL).To(Equal("http://127.0.0.1:1/hook"))
+		Expect(stored.Version).To(Equal(client.Version))
+		Expect(processService.IsRunning(client.ID)).To(BeTrue())
+	})
+
+	Context("while the client is being started", func() {
+		var started chan error
+
+		BeforeEach(func() {
+			Expect(clientService.Stop(client.ID)).To(Succeed())
+			stopped, err := clientRepo.Get(client.ID)
+			Expect(err).NotTo(HaveOccurred())
+			client = stopped
+
+			// The start waits for its startup check, holding the client in the starting status
+			started = make(chan error, 1)
+			go func() { started <- clientService.Start(client.ID) }()
+			Eventually(func() models.ClientStatus {
+				current, err := clientService.Get(client.ID)
+				Expect(err).NotTo(HaveOccurred())
+				return current.Status
+			}).Should(Equal(models.ClientStatusStarting))
+		})
+
+		It("rejects configuration updates", func() {
+			_, err := update("http://127.0.0.1:1/new-hook")
+			var appErr *apperrors.AppError
+			Expect(errors.As(err, &appErr)).To(BeTrue())
+			Expect(appErr.Code).To(Equal(apperrors.CodeClientRunning))
+			Expect(<-started).To(Succeed())
+
+			stored, err := clientRepo.Get(client.ID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stored.TargetURL).To(Equal("http://127.0.0.1:1/hook"))
+			Expect(stored.Version).To(Equal(client.Version))
+		})
+
+		It("keeps changes saved meanwhile when recording the started status", func() {
+			_, err := clientService.Pause(client.ID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(<-started).To(Succeed())
+
+			stored, err := clientRepo.Get(client.ID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stored.Status).To(Equal(models.ClientStatusRunning))
+			Expect(stored.Paused).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,104 @@
+package service_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("Client slugs", func() {
+	var clientService *service.ClientService
+
+	BeforeEach(func() {
+		baseDir := GinkgoT().TempDir()
+		log := logger.New()
+		clientRepo, err := repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+
+		clientService = service.NewClientService(
+			clientRepo,
+			repository.NewFileQuotaRepository(baseDir, 10*1024*1024, 10),
+			repository.NewFileEventRepository(baseDir),
+			service.NewProcessService(false, 0, time.Minute, log),
+			service.NewJobService(time.Hour, log),
+			baseDir,
+			log,
+		)
+	})
+
+	create := func(userID, name, slug string) (*models.Client, error) {
+		return clientService.Create(userID, &models.ClientRequest{
+			Name:      name,
+			Slug:      slug,
+			SmeeURL:   "https://smee.io/abc",
+			TargetURL: "http://127.0.0.1:1/hook",
+		})
+	}
+
+	It("derives URL-friendly slugs from client names", func() {
+		Expect(service.Slugify("GitHub → CI (staging)")).To(Equal("github-ci-staging"))
+		Expect(service.Slugify("  --Relay__2--  ")).To(Equal("relay-2"))
+		Expect(service.Slugify("测试")).To(BeEmpty())
+	})
+
+	It("makes slugs unique per user and keeps them on rename", func() {
+		first, err := create("user", "CI Relay", "")
+		Expect(err).NotTo(HaveOccurred())
+		second, err := create("user", "ci relay", "")
+		Expect(err).NotTo(HaveOccurred())
+		other, err := create("other", "CI Relay", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(first.Slug).To(Equal("ci-relay"))
+		Expect(second.Slug).To(Equal("ci-relay-2"))
+		Expect(other.Slug).To(Equal("ci-relay"))
+
+		name := "Deploy relay"
+		renamed, err := clientService.Patch(first.ID, &models.ClientPatchRequest{Name: &name}, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(renamed.Slug).To(Equal("ci-relay"))
+	})
+
+	It("falls back to the client ID for names without usable characters", func() {
+		client, err := create("user", "测试", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(client.Slug).To(Equal("client-" + client.ID[:8]))
+	})
+
+	It("rejects invalid, reserved and taken slugs", func() {
+		_, err := create("user", "relay", "Bad Slug")
+		Expect(err).To(HaveOccurred())
+		_, err = create("user", "relay", "export")
+		Expect(err).To(HaveOccurred())
+
+		first, err := create("user", "relay", "")
+		Expect(err).NotTo(HaveOccurred())
+		second, err := create("user", "other", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = clientService.Patch(second.ID, &models.ClientPatchRequest{Slug: &first.Slug}, false)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("resolves clients by ID or by the user's slug", func() {
+		client, err := create("user", "relay", "github-ci")
+		Expect(err).NotTo(HaveOccurred())
+
+		bySlug, err := clientService.Resolve("user", "github-ci")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bySlug.ID).To(Equal(client.ID))
+
+		byID, err := clientService.Resolve("user", client.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(byID.ID).To(Equal(client.ID))
+
+		_, err = clientService.Resolve("other", "github-ci")
+		Expect(err).To(HaveOccurred())
+	})
+})
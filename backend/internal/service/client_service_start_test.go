@@ -0,0 +1,78 @@
+package service_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("Starting clients on creation", func() {
+	var (
+		clientService *service.ClientService
+		clientRepo    repository.ClientRepository
+	)
+
+	installFakeGosmee := func(script string) {
+		binDir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(binDir, "gosmee"), []byte(script), 0755)).To(Succeed())
+		GinkgoT().Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	}
+
+	BeforeEach(func() {
+		baseDir := GinkgoT().TempDir()
+		log := logger.New()
+		var err error
+		clientRepo, err = repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		processService := service.NewProcessService(false, 0, time.Minute, log)
+		processService.SetStartupCheck(200*time.Millisecond, nil, 0)
+		clientService = service.NewClientService(
+			clientRepo,
+			repository.NewFileQuotaRepository(baseDir, 10*1024*1024, 10),
+			repository.NewFileEventRepository(baseDir),
+			processService,
+			service.NewJobService(time.Hour, log),
+			baseDir,
+			log,
+		)
+	})
+
+	request := func() *models.ClientRequest {
+		return &models.ClientRequest{
+			Name:             "relay",
+			SmeeURL:          "https://smee.example.com/channel",
+			TargetURL:        "http://127.0.0.1:1/hook",
+			StartImmediately: true,
+		}
+	}
+
+	It("creates and starts the client in one call", func() {
+		installFakeGosmee("#!/bin/sh\nexec sleep 30\n")
+
+		client, err := clientService.Create("user-start", request())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(client.Status).To(Equal(models.ClientStatusRunning))
+		Expect(client.PID).NotTo(BeZero())
+		Expect(clientService.Stop(client.ID)).To(Succeed())
+	})
+
+	It("deletes the client again when it fails to start", func() {
+		installFakeGosmee("#!/bin/sh\nexit 3\n")
+
+		client, err := clientService.Create("user-start", request())
+		Expect(err).To(MatchError(ContainSubstring("process exited during startup")))
+		Expect(client).To(BeNil())
+
+		clients, err := clientRepo.GetByUserID("user-start")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(clients).To(BeEmpty())
+	})
+})
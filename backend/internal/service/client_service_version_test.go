@@ -0,0 +1,78 @@
+package service_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("Client configuration versions", func() {
+	var clientService *service.ClientService
+
+	BeforeEach(func() {
+		baseDir := GinkgoT().TempDir()
+		log := logger.New()
+		clientRepo, err := repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+
+		clientService = service.NewClientService(
+			clientRepo,
+			repository.NewFileQuotaRepository(baseDir, 10*1024*1024, 10),
+			repository.NewFileEventRepository(baseDir),
+			service.NewProcessService(false, 0, time.Minute, log),
+			service.NewJobService(time.Hour, log),
+			baseDir,
+			log,
+		)
+	})
+
+	request := func(name string, version int) *models.ClientRequest {
+		return &models.ClientRequest{
+			Name:      name,
+			SmeeURL:   "https://smee.io/abc",
+			TargetURL: "http://127.0.0.1:1/hook",
+			Version:   version,
+		}
+	}
+
+	It("increments the version on every update", func() {
+		client, err := clientService.Create("user", request("first", 0))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(client.Version).To(Equal(1))
+
+		updated, err := clientService.Update(client.ID, request("second", 1), false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated.Version).To(Equal(2))
+
+		// Without a version the update is unconditional
+		updated, err = clientService.Update(client.ID, request("third", 0), false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated.Version).To(Equal(3))
+	})
+
+	It("rejects updates based on an outdated version", func() {
+		client, err := clientService.Create("user", request("first", 0))
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = clientService.Update(client.ID, request("from tab 1", client.Version), false)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = clientService.Update(client.ID, request("from tab 2", client.Version), false)
+		var appErr *apperrors.AppError
+		Expect(errors.As(err, &appErr)).To(BeTrue())
+		Expect(appErr.Code).To(Equal(apperrors.CodeClientConflict))
+		Expect(appErr.Details).To(Equal(map[string]int{"version": 2}))
+
+		stored, err := clientService.Get(client.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored.Name).To(Equal("from tab 1"))
+	})
+})
@@ -0,0 +1,168 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+)
+
+// maxSlugLength is the longest slug derived from a client name, leaving room for the
+// suffix that makes it unique.
+const maxSlugLength = 48
+
+// slugPattern matches valid client slugs: lowercase letters and digits separated by single
+// hyphens.
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// reservedSlugs are path segments of routes under /clients that a slug must not shadow.
+var reservedSlugs = []string{"export", "batch", "ignore-event-presets", "validate"}
+
+// Slugify derives a URL-friendly slug from a client name: lowercase ASCII letters and digits,
+// with every other run of characters replaced by a hyphen. Names without any such character
+// (e.g. Chinese names) return an empty slug.
+func Slugify(name string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+			continue
+		}
+		hyphen = true
+	}
+
+	slug := b.String()
+	if len(slug) > maxSlugLength {
+		slug = strings.TrimRight(slug[:maxSlugLength], "-")
+	}
+	return slug
+}
+
+// ValidateSlug checks that a slug requested for a client is URL-friendly and cannot be
+// mistaken for a client ID or another route.
+func ValidateSlug(slug string) error {
+	if len(slug) > 63 {
+		return fmt.Errorf("slug must be at most 63 characters")
+	}
+	if !slugPattern.MatchString(slug) {
+		return fmt.Errorf("slug %q must contain only lowercase letters and digits separated by single hyphens", slug)
+	}
+	if slices.Contains(reservedSlugs, slug) {
+		return fmt.Errorf("slug %q is reserved", slug)
+	}
+	if _, err := uuid.Parse(slug); err == nil {
+		return fmt.Errorf("slug %q must not be a client ID", slug)
+	}
+	return nil
+}
+
+// uniqueSlug returns the base slug, or the base slug with the first free numeric suffix
+// ("-2", "-3", ...) if another of the user's clients already uses it.
+func uniqueSlug(base, clientID string, clients []*models.Client) string {
+	taken := make(map[string]bool, len(clients))
+	for _, client := range clients {
+		if client.ID != clientID && client.Slug != "" {
+			taken[client.Slug] = true
+		}
+	}
+
+	slug := base
+	for n := 2; taken[slug]; n++ {
+		slug = fmt.Sprintf("%s-%d", base, n)
+	}
+	return slug
+}
+
+// defaultSlug derives the slug of a client from its name, falling back to a slug built from
+// the client ID when the name has no usable characters or yields a reserved slug.
+func defaultSlug(client *models.Client) string {
+	slug := Slugify(client.Name)
+	if slug == "" || ValidateSlug(slug) != nil {
+		slug = "client-" + strings.SplitN(client.ID, "-", 2)[0]
+	}
+	return slug
+}
+
+// slugFor returns the slug of a client: the requested one, which must be valid and not used
+// by another of the user's clients, or one derived from the client name if none is requested.
+// The caller must hold s.updateMu until the client is saved.
+func (s *ClientService) slugFor(client *models.Client, requested string) (string, error) {
+	clients, err := s.clientRepo.GetByUserID(client.UserID)
+	if err != nil {
+		return "", fmt.Errorf("failed to list clients: %w", err)
+	}
+
+	if requested == "" {
+		return uniqueSlug(defaultSlug(client), client.ID, clients), nil
+	}
+
+	if err := ValidateSlug(requested); err != nil {
+		return "", apperrors.NewInvalidInput(fmt.Sprintf("invalid slug: %v", err))
+	}
+	if uniqueSlug(requested, client.ID, clients) != requested {
+		return "", apperrors.NewInvalidInput(fmt.Sprintf("invalid slug: %q is already used by another client", requested))
+	}
+	return requested, nil
+}
+
+// Resolve returns the user's client identified by its ID or its slug.
+func (s *ClientService) Resolve(userID, idOrSlug string) (*models.Client, error) {
+	if _, err := uuid.Parse(idOrSlug); err == nil {
+		return s.Get(idOrSlug)
+	}
+
+	clients, err := s.clientRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, client := range clients {
+		if client.Slug == idOrSlug {
+			return s.Get(client.ID)
+		}
+	}
+	return nil, apperrors.ErrClientNotFound
+}
+
+// AssignMissingSlugs gives a slug to every client stored before clients had slugs.
+func (s *ClientService) AssignMissingSlugs() error {
+	s.updateMu.Lock()
+	defer s.updateMu.Unlock()
+
+	clients, err := s.clientRepo.ListAll()
+	if err != nil {
+		return fmt.Errorf("failed to list clients: %w", err)
+	}
+
+	assigned := 0
+	for _, client := range clients {
+		if client.Slug != "" {
+			continue
+		}
+		slug, err := s.slugFor(client, "")
+		if err != nil {
+			return err
+		}
+		client.Slug = slug
+		if err := s.clientRepo.Update(client); err != nil {
+			return fmt.Errorf("failed to save slug of client %s: %w", client.ID, err)
+		}
+		assigned++
+	}
+	if assigned > 0 {
+		s.log.Info("Assigned slugs to %d existing clients", assigned)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+)
+
+// validationProbeTimeout limits each request probing the smee server or the target of a
+// validated client.
+const validationProbeTimeout = 5 * time.Second
+
+// validationHTTPClient probes smee servers and targets. Redirects are not followed, as any
+// response shows that the server is reachable.
+var validationHTTPClient = &http.Client{
+	Timeout:       validationProbeTimeout,
+	CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+}
+
+// Validate runs the checks of creating a client without creating it: the client quota, the
+// settings Create rejects, the URL syntax, whether the smee server and the target can be
+// reached, and whether the installed gosmee supports the flags the client is started with.
+// Failed checks are reported in the response, not as errors.
+func (s *ClientService) Validate(userID string, req *models.ClientRequest) (*models.ClientValidationResponse, error) {
+	response := &models.ClientValidationResponse{Valid: true, Checks: []*models.ClientValidationCheck{}}
+	add := func(check, level, message string) {
+		response.Checks = append(response.Checks, &models.ClientValidationCheck{Check: check, Level: level, Message: message})
+		if level == models.ValidationFail {
+			response.Valid = false
+		}
+	}
+
+	quota, err := s.quotaRepo.GetQuota(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check quota: %w", err)
+	}
+	if quota.CanCreateClient() {
+		add("quota", models.ValidationOK, fmt.Sprintf("%d of %d clients used", quota.ClientsCount, quota.MaxClients))
+	} else {
+		add("quota", models.ValidationFail, fmt.Sprintf("client limit reached: %d/%d", quota.ClientsCount, quota.MaxClients))
+	}
+
+	client := newClientFromRequest(userID, req)
+	if err := s.validateNewClientConfig(client, req); err != nil {
+		var appErr *apperrors.AppError
+		if !errors.As(err, &appErr) {
+			return nil, err
+		}
+		add("config", models.ValidationFail, appErr.Message)
+	} else {
+		add("config", models.ValidationOK, fmt.Sprintf("Settings are valid, the client's slug will be %q", client.Slug))
+	}
+
+	smeeURL, smeeErr := parseHTTPURL(req.SmeeURL)
+	targetURL, targetErr := parseHTTPURL(req.TargetURL)
+	if client.HasTargetTemplate() {
+		// Template errors are reported by the config check
+		targetURL, targetErr = nil, nil
+		if resolved, err := renderTargetURL(req.TargetURL, targetURLData{EventType: "push", EventID: "event", ClientID: client.ID, Source: "source"}); err == nil {
+			targetURL, _ = parseHTTPURL(resolved)
+		}
+	}
+	switch {
+	case smeeErr != nil:
+		add("urls", models.ValidationFail, fmt.Sprintf("invalid smee URL: %v", smeeErr))
+	case targetErr != nil:
+		add("urls", models.ValidationFail, fmt.Sprintf("invalid target URL: %v", targetErr))
+	default:
+		add("urls", models.ValidationOK, "The smee URL and the target URL are valid")
+	}
+
+	if smeeURL != nil {
+		level, message := probeSmee(smeeURL.String())
+		add("smee", level, message)
+	}
+	if targetURL != nil {
+		level, message := probeTarget(targetURL.String())
+		add("target", level, message)
+	}
+
+	if s.processService.Embedded() {
+		add("gosmee", models.ValidationOK, "The client runs in the backend process, gosmee is not needed")
+		return response, nil
+	}
+	unsupported, err := s.processService.UnsupportedFlags(client)
+	switch {
+	case err != nil:
+		add("gosmee", models.ValidationFail, fmt.Sprintf("The client cannot be started: %v", err))
+	case len(unsupported) > 0:
+		add("gosmee", models.ValidationFail, fmt.Sprintf("The installed gosmee does not support %s", strings.Join(unsupported, ", ")))
+	default:
+		add("gosmee", models.ValidationOK, "The installed gosmee supports all flags of the client")
+	}
+
+	return response, nil
+}
+
+// validateNewClientConfig runs the validations of Create that reject a client's settings,
+// including its target credentials and slug, on a client built from req.
+func (s *ClientService) validateNewClientConfig(client *models.Client, req *models.ClientRequest) error {
+	if err := validateNewClient(req); err != nil {
+		return err
+	}
+	if _, err := s.applyTargetAuth(client, req.TargetAuth); err != nil {
+		return err
+	}
+
+	slug, err := s.slugFor(client, req.Slug)
+	if err != nil {
+		return err
+	}
+	client.Slug = slug
+	return nil
+}
+
+// parseHTTPURL parses an absolute HTTP or HTTPS URL.
+func parseHTTPURL(rawURL string) (*url.URL, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("%q is not an http or https URL", rawURL)
+	}
+	return parsed, nil
+}
+
+// probeSmee connects to a smee channel the way gosmee does and reports whether it accepted
+// the event stream request.
+func probeSmee(smeeURL string) (string, string) {
+	ctx, cancel := context.WithTimeout(context.Background(), validationProbeTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, smeeURL, nil)
+	if err != nil {
+		return models.ValidationFail, fmt.Sprintf("invalid smee URL: %v", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	// The stream stays open, only the response headers are awaited
+	resp, err := validationHTTPClient.Do(req)
+	if err != nil {
+		return models.ValidationFail, fmt.Sprintf("The smee server is unreachable: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return models.ValidationWarn, fmt.Sprintf("The smee server answered HTTP %d, gosmee may fail to connect", resp.StatusCode)
+	}
+	return models.ValidationOK, fmt.Sprintf("The smee server answered HTTP %d", resp.StatusCode)
+}
+
+// probeTarget sends a HEAD request to a target. Any answer shows the target is reachable;
+// an unreachable target is a warning only, as events are stored and can be replayed later.
+func probeTarget(targetURL string) (string, string) {
+	resp, err := validationHTTPClient.Head(targetURL)
+	if err != nil {
+		return models.ValidationWarn, fmt.Sprintf("The target is unreachable, deliveries will fail until it is: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return models.ValidationWarn, fmt.Sprintf("The target answered HTTP %d", resp.StatusCode)
+	}
+	return models.ValidationOK, fmt.Sprintf("The target is reachable (HTTP %d)", resp.StatusCode)
+}
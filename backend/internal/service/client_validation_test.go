@@ -0,0 +1,101 @@
+package service_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("Client validation", func() {
+	var (
+		clientService *service.ClientService
+		clientRepo    repository.ClientRepository
+		smee          *httptest.Server
+		target        *httptest.Server
+	)
+
+	BeforeEach(func() {
+		// A gosmee without --debug support
+		binDir := GinkgoT().TempDir()
+		help := "#!/bin/sh\necho 'Flags: --saveDir --target-connection-timeout --sse-buffer-size --ignore-event --noReplay --httpie'\n"
+		Expect(os.WriteFile(filepath.Join(binDir, "gosmee"), []byte(help), 0755)).To(Succeed())
+		GinkgoT().Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+		smee = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.WriteHeader(http.StatusOK)
+		}))
+		DeferCleanup(smee.Close)
+		target = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}))
+		DeferCleanup(target.Close)
+
+		baseDir := GinkgoT().TempDir()
+		log := logger.New()
+		var err error
+		clientRepo, err = repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		clientService = service.NewClientService(
+			clientRepo,
+			repository.NewFileQuotaRepository(baseDir, 10*1024*1024, 1),
+			repository.NewFileEventRepository(baseDir),
+			service.NewProcessService(false, 0, time.Minute, log),
+			service.NewJobService(time.Hour, log),
+			baseDir,
+			log,
+		)
+	})
+
+	levels := func(response *models.ClientValidationResponse) map[string]string {
+		result := map[string]string{}
+		for _, check := range response.Checks {
+			result[check.Check] = check.Level
+		}
+		return result
+	}
+
+	It("passes a client that can be created and started", func() {
+		response, err := clientService.Validate("user", &models.ClientRequest{Name: "CI relay", SmeeURL: smee.URL, TargetURL: target.URL})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.Valid).To(BeTrue())
+		Expect(levels(response)).To(Equal(map[string]string{
+			"quota": models.ValidationOK, "config": models.ValidationOK, "urls": models.ValidationOK,
+			"smee": models.ValidationOK, "target": models.ValidationOK, "gosmee": models.ValidationOK,
+		}))
+
+		clients, err := clientRepo.GetByUserID("user")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(clients).To(BeEmpty())
+	})
+
+	It("reports every problem without creating the client", func() {
+		Expect(clientRepo.Create(models.NewClient("existing", "user", "existing", "", smee.URL, target.URL))).To(Succeed())
+		target.Close()
+
+		response, err := clientService.Validate("user", &models.ClientRequest{
+			Name:      "relay",
+			Slug:      "Not A Slug",
+			SmeeURL:   "smee.example.com/channel",
+			TargetURL: target.URL,
+			LogLevel:  models.ClientLogLevelDebug,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.Valid).To(BeFalse())
+		Expect(levels(response)).To(Equal(map[string]string{
+			"quota": models.ValidationFail, "config": models.ValidationFail, "urls": models.ValidationFail,
+			"target": models.ValidationWarn, "gosmee": models.ValidationFail,
+		}))
+		Expect(response.Checks[len(response.Checks)-1].Message).To(ContainSubstring("--debug"))
+	})
+})
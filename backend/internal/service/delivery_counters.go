@@ -0,0 +1,123 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"regexp"
+	"sync"
+)
+
+// Event type labels of events counted without a type of their own.
+const (
+	DeliveryEventTypeUnknown = "unknown" // Events without an event type
+	DeliveryEventTypeOther   = "other"   // Event types beyond the per-client limit, or not usable as label
+)
+
+// deliveryEventTypePattern matches event types that are counted under their own label.
+// Event types come from webhook headers, so anything else is counted as "other".
+var deliveryEventTypePattern = regexp.MustCompile(`^[A-Za-z0-9_.:-]{1,64}$`)
+
+// DeliveryCounts are the counters of an event type of a client.
+type DeliveryCounts struct {
+	Received  int64 // New events received
+	Forwarded int64 // Deliveries the target accepted
+	Failed    int64 // Deliveries that failed
+}
+
+// DeliveryCounters counts received events and deliveries per client and event type as they
+// happen, for metrics alerting on failure ratios. Each client gets at most maxEventTypes
+// event types of its own; further types are counted as "other", bounding the number of
+// exported series. Counts start at zero with every server start.
+type DeliveryCounters struct {
+	maxEventTypes int
+
+	mu      sync.Mutex
+	clients map[string]map[string]*DeliveryCounts // clientID -> event type -> counts
+}
+
+// NewDeliveryCounters creates delivery counters tracking up to maxEventTypes event types
+// per client.
+func NewDeliveryCounters(maxEventTypes int) *DeliveryCounters {
+	return &DeliveryCounters{
+		maxEventTypes: maxEventTypes,
+		clients:       make(map[string]map[string]*DeliveryCounts),
+	}
+}
+
+// Received counts a new event of a client.
+func (c *DeliveryCounters) Received(clientID, eventType string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.countsLocked(clientID, eventType).Received++
+}
+
+// Delivered counts a delivery of an event of a client to its target.
+func (c *DeliveryCounters) Delivered(clientID, eventType string, success bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	counts := c.countsLocked(clientID, eventType)
+	if success {
+		counts.Forwarded++
+	} else {
+		counts.Failed++
+	}
+}
+
+// Counts returns a copy of a client's counters by event type label.
+func (c *DeliveryCounters) Counts(clientID string) map[string]DeliveryCounts {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	counts := make(map[string]DeliveryCounts, len(c.clients[clientID]))
+	for eventType, count := range c.clients[clientID] {
+		counts[eventType] = *count
+	}
+	return counts
+}
+
+// countsLocked returns the counters of an event type of a client, creating them if the
+// client has room for another event type. The caller must hold c.mu.
+func (c *DeliveryCounters) countsLocked(clientID, eventType string) *DeliveryCounts {
+	types := c.clients[clientID]
+	if types == nil {
+		types = make(map[string]*DeliveryCounts)
+		c.clients[clientID] = types
+	}
+
+	label := deliveryEventTypeLabel(eventType)
+	if counts, ok := types[label]; ok {
+		return counts
+	}
+	// "other" and "unknown" don't take up one of the client's event types
+	tracked := len(types)
+	for _, fallback := range []string{DeliveryEventTypeOther, DeliveryEventTypeUnknown} {
+		if _, ok := types[fallback]; ok {
+			tracked--
+		}
+	}
+	if label == eventType && tracked >= c.maxEventTypes {
+		label = DeliveryEventTypeOther
+		if counts, ok := types[label]; ok {
+			return counts
+		}
+	}
+
+	counts := &DeliveryCounts{}
+	types[label] = counts
+	return counts
+}
+
+// deliveryEventTypeLabel returns the label an event type is counted under.
+func deliveryEventTypeLabel(eventType string) string {
+	switch {
+	case eventType == "":
+		return DeliveryEventTypeUnknown
+	case deliveryEventTypePattern.MatchString(eventType):
+		return eventType
+	default:
+		return DeliveryEventTypeOther
+	}
+}
@@ -0,0 +1,84 @@
+package service_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("DeliveryCounters", func() {
+	It("counts event types beyond the per-client limit as other", func() {
+		counters := service.NewDeliveryCounters(2)
+		for _, eventType := range []string{"push", "issues", "release", "push", "", "bad type\n"} {
+			counters.Received("client", eventType)
+		}
+		counters.Delivered("client", "push", true)
+		counters.Delivered("client", "release", false)
+
+		Expect(counters.Counts("client")).To(Equal(map[string]service.DeliveryCounts{
+			"push":                           {Received: 2, Forwarded: 1},
+			"issues":                         {Received: 1},
+			service.DeliveryEventTypeOther:   {Received: 2, Failed: 1},
+			service.DeliveryEventTypeUnknown: {Received: 1},
+		}))
+		Expect(counters.Counts("other-client")).To(BeEmpty())
+	})
+
+	It("counts deliveries by event type and exports them as metrics", func() {
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-GitHub-Event") == "push" {
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		DeferCleanup(target.Close)
+
+		baseDir := GinkgoT().TempDir()
+		log := logger.New()
+		clientRepo, err := repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		eventRepo := repository.NewFileEventRepository(baseDir)
+		eventService := service.NewEventService(
+			eventRepo,
+			clientRepo,
+			service.NewJobService(time.Hour, log),
+			service.NewCircuitBreakerService(0, time.Minute, service.NewNotificationService(log), log),
+			log,
+		)
+		statsService := service.NewStatsService(clientRepo, eventRepo, repository.NewFileStatsRepository(baseDir),
+			repository.NewFileQuotaRepository(baseDir, 10*1024*1024, 10), log)
+		counters := service.NewDeliveryCounters(20)
+		eventService.SetDeliveryCounters(counters)
+		statsService.SetDeliveryCounters(counters)
+
+		client := &models.Client{ID: "client-counters", UserID: "user-counters", Name: "counters", TargetURL: target.URL, TargetTimeout: 5}
+		Expect(clientRepo.Create(client)).To(Succeed())
+
+		for _, eventType := range []string{"push", "push", "issues"} {
+			_, err := eventService.Inject(client.ID, &models.EventInjectRequest{
+				EventType: eventType,
+				Headers:   map[string]string{"X-GitHub-Event": eventType},
+				Payload:   []byte(`{}`),
+				Forward:   true,
+			})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		var buf strings.Builder
+		Expect(statsService.WriteMetrics(&buf, client.UserID)).To(Succeed())
+		labels := `client_id="client-counters",client_name="counters"`
+		Expect(buf.String()).To(ContainSubstring(`gosmee_webhook_forwarded_total{` + labels + `,event_type="push"} 2`))
+		Expect(buf.String()).To(ContainSubstring(`gosmee_webhook_failed_total{` + labels + `,event_type="issues"} 1`))
+		Expect(buf.String()).To(ContainSubstring(`gosmee_webhook_failed_total{` + labels + `,event_type="push"} 0`))
+	})
+})
@@ -0,0 +1,148 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+)
+
+// deliveryRef locates an event with a delivery ID.
+type deliveryRef struct {
+	clientID string
+	eventID  string
+}
+
+// DeliveryIndex maps the delivery IDs webhook providers send to the events of all clients, so
+// a delivery can be located without reading every event. The stored events of a client are
+// indexed on its first lookup, new events as they are received or injected.
+type DeliveryIndex struct {
+	eventRepo repository.EventRepository
+	log       logger.Logger
+
+	mu      sync.Mutex
+	indexed map[string]bool          // IDs of clients whose stored events are indexed
+	refs    map[string][]deliveryRef // deliveryID -> events
+}
+
+// NewDeliveryIndex creates a new delivery index.
+func NewDeliveryIndex(eventRepo repository.EventRepository, log logger.Logger) *DeliveryIndex {
+	return &DeliveryIndex{
+		eventRepo: eventRepo,
+		log:       log,
+		indexed:   make(map[string]bool),
+		refs:      make(map[string][]deliveryRef),
+	}
+}
+
+// Add indexes a new event. Events of clients not indexed yet are picked up on their first lookup.
+func (x *DeliveryIndex) Add(clientID, eventID, deliveryID string) {
+	if deliveryID == "" {
+		return
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if x.indexed[clientID] {
+		x.add(deliveryRef{clientID: clientID, eventID: eventID}, deliveryID)
+	}
+}
+
+// Remove drops an event from the index, e.g. once it was found to be deleted.
+func (x *DeliveryIndex) Remove(clientID, eventID, deliveryID string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.refs[deliveryID] = slices.DeleteFunc(x.refs[deliveryID], func(ref deliveryRef) bool {
+		return ref.clientID == clientID && ref.eventID == eventID
+	})
+	if len(x.refs[deliveryID]) == 0 {
+		delete(x.refs, deliveryID)
+	}
+}
+
+// Rebuild drops the indexed events of a client and indexes its stored events again. It
+// returns how many of them have a delivery ID.
+func (x *DeliveryIndex) Rebuild(clientID string) (int, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	ofClient := func(ref deliveryRef) bool { return ref.clientID == clientID }
+	for deliveryID, refs := range x.refs {
+		if refs = slices.DeleteFunc(refs, ofClient); len(refs) == 0 {
+			delete(x.refs, deliveryID)
+		} else {
+			x.refs[deliveryID] = refs
+		}
+	}
+	delete(x.indexed, clientID)
+
+	if err := x.indexClient(clientID); err != nil {
+		return 0, err
+	}
+
+	indexed := 0
+	for _, refs := range x.refs {
+		for _, ref := range refs {
+			if ofClient(ref) {
+				indexed++
+			}
+		}
+	}
+	return indexed, nil
+}
+
+// find returns the events of the clients with the delivery ID, indexing the clients first
+// if needed. Deleted events may still be returned.
+func (x *DeliveryIndex) find(clientIDs []string, deliveryID string) ([]deliveryRef, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	for _, clientID := range clientIDs {
+		if err := x.indexClient(clientID); err != nil {
+			return nil, err
+		}
+	}
+
+	var refs []deliveryRef
+	for _, ref := range x.refs[deliveryID] {
+		if slices.Contains(clientIDs, ref.clientID) {
+			refs = append(refs, ref)
+		}
+	}
+	return refs, nil
+}
+
+// indexClient indexes the stored events of a client once. The caller must hold x.mu.
+func (x *DeliveryIndex) indexClient(clientID string) error {
+	if x.indexed[clientID] {
+		return nil
+	}
+
+	summaries, err := collectEventSummaries(x.eventRepo, clientID, &models.EventListRequest{SortBy: "timestamp", SortOrder: "asc"})
+	if err != nil {
+		return err
+	}
+	for _, summary := range summaries {
+		if summary.DeliveryID != "" {
+			x.add(deliveryRef{clientID: clientID, eventID: summary.ID}, summary.DeliveryID)
+		}
+	}
+	x.indexed[clientID] = true
+
+	x.log.Debug("Indexed delivery IDs of %d events of client %s", len(summaries), clientID)
+	return nil
+}
+
+// add adds an event to the index unless it is indexed already. The caller must hold x.mu.
+func (x *DeliveryIndex) add(ref deliveryRef, deliveryID string) {
+	if !slices.Contains(x.refs[deliveryID], ref) {
+		x.refs[deliveryID] = append(x.refs[deliveryID], ref)
+	}
+}
@@ -0,0 +1,99 @@
+package service_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("DeliveryIndex", func() {
+	var (
+		eventRepo    *repository.FileEventRepository
+		eventService *service.EventService
+		clients      []*models.Client
+	)
+
+	BeforeEach(func() {
+		baseDir := GinkgoT().TempDir()
+		log := logger.New()
+		clientRepo, err := repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		eventRepo = repository.NewFileEventRepository(baseDir)
+		eventService = service.NewEventService(
+			eventRepo,
+			clientRepo,
+			service.NewJobService(time.Hour, log),
+			service.NewCircuitBreakerService(0, time.Minute, service.NewNotificationService(log), log),
+			log,
+		)
+		eventService.SetDeliveryIndex(service.NewDeliveryIndex(eventRepo, log))
+
+		clients = []*models.Client{
+			{ID: "client-a", UserID: "user", Name: "A", TargetURL: "http://127.0.0.1:1"},
+			{ID: "client-b", UserID: "user", Name: "B", TargetURL: "http://127.0.0.1:1"},
+			{ID: "client-other", UserID: "other", Name: "Other", TargetURL: "http://127.0.0.1:1"},
+		}
+		for _, client := range clients {
+			Expect(clientRepo.Create(client)).To(Succeed())
+			Expect(eventRepo.Save(client.ID, &models.Event{
+				ID: "stored", ClientID: client.ID, Timestamp: time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC),
+				Payload: `{}`, Headers: map[string]string{"X-GitHub-Delivery": "guid-1"},
+			})).To(Succeed())
+		}
+	})
+
+	It("locates stored and injected events of the user's clients by delivery ID", func() {
+		response, err := eventService.FindByDelivery("user", "guid-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.Matches).To(HaveLen(2))
+		Expect(response.Matches[0].Event.DeliveryID).To(Equal("guid-1"))
+
+		injected, err := eventService.Inject("client-b", &models.EventInjectRequest{
+			Payload: []byte(`{}`), Headers: map[string]string{"X-Gitlab-Event-UUID": "guid-2"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(injected.Event.DeliveryID).To(Equal("guid-2"))
+
+		response, err = eventService.FindByDelivery("user", "guid-2")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.Matches).To(HaveLen(1))
+		Expect(response.Matches[0].ClientName).To(Equal("B"))
+		Expect(response.Matches[0].Event.ID).To(Equal(injected.Event.ID))
+	})
+
+	It("reads the delivery ID of stored events back", func() {
+		// No provider headers, the delivery ID is only known from the stored field
+		Expect(eventRepo.Save("client-a", &models.Event{
+			ID: "round-trip", ClientID: "client-a", Timestamp: time.Date(2025, 10, 2, 12, 0, 0, 0, time.UTC),
+			Payload: `{}`, DeliveryID: "guid-stored",
+		})).To(Succeed())
+
+		event, err := eventRepo.Get("client-a", "round-trip")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(event.DeliveryID).To(Equal("guid-stored"))
+
+		response, err := eventService.FindByDelivery("user", "guid-stored")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.Matches).To(HaveLen(1))
+		Expect(response.Matches[0].Event.ID).To(Equal("round-trip"))
+	})
+
+	It("skips deleted events", func() {
+		_, err := eventService.FindByDelivery("other", "guid-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(eventRepo.Delete("client-other", "stored")).To(Succeed())
+
+		_, err = eventService.FindByDelivery("other", "guid-1")
+		var appErr *apperrors.AppError
+		Expect(errors.As(err, &appErr)).To(BeTrue())
+		Expect(appErr.Code).To(Equal(apperrors.CodeEventNotFound))
+	})
+})
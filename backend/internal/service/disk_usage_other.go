@@ -0,0 +1,13 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+//go:build !unix
+
+package service
+
+import "errors"
+
+// diskUsage is not supported on this platform.
+func diskUsage(path string) (total, free int64, err error) {
+	return 0, 0, errors.New("disk usage is not supported on this platform")
+}
@@ -0,0 +1,18 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+//go:build unix
+
+package service
+
+import "syscall"
+
+// diskUsage returns the size of the volume holding path and the space available to
+// unprivileged processes on it.
+func diskUsage(path string) (total, free int64, err error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, 0, err
+	}
+	return int64(stat.Blocks) * int64(stat.Bsize), int64(stat.Bavail) * int64(stat.Bsize), nil
+}
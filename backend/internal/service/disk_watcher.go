@@ -0,0 +1,131 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+)
+
+// DiskWatcherService checks the free space of the volumes holding the data directories,
+// independently of user quotas, and notifies the administrators before a disk fills up.
+type DiskWatcherService struct {
+	dirs                []string
+	warningFreePercent  float64 // Free space in percent below which a volume is rated warning (0 = disabled)
+	criticalFreePercent float64 // Free space in percent below which a volume is rated critical (0 = disabled)
+	notificationService *NotificationService
+	log                 logger.Logger
+
+	mu      sync.RWMutex
+	status  *models.StorageStatus
+	alerted map[string]models.StorageLevel // Data directory -> level administrators were notified of
+}
+
+// NewDiskWatcherService creates a new disk watcher for the data directories.
+func NewDiskWatcherService(dirs []string, warningFreePercent, criticalFreePercent float64, notificationService *NotificationService, log logger.Logger) *DiskWatcherService {
+	return &DiskWatcherService{
+		dirs:                dirs,
+		warningFreePercent:  warningFreePercent,
+		criticalFreePercent: criticalFreePercent,
+		notificationService: notificationService,
+		log:                 log,
+		status:              &models.StorageStatus{Level: models.StorageLevelOK, Volumes: []*models.StorageVolume{}},
+		alerted:             make(map[string]models.StorageLevel),
+	}
+}
+
+// Check measures the free space of all data volumes. Administrators are notified when a
+// volume gets worse than the level they were last notified of, and again after it recovered.
+func (s *DiskWatcherService) Check() {
+	status := &models.StorageStatus{Level: models.StorageLevelOK, CheckedAt: time.Now(), Volumes: []*models.StorageVolume{}}
+	for _, dir := range s.dirs {
+		volume := s.checkVolume(dir)
+		status.Volumes = append(status.Volumes, volume)
+		if storageLevelRank(volume.Level) > storageLevelRank(status.Level) {
+			status.Level = volume.Level
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.status = status
+	for _, volume := range status.Volumes {
+		previous := s.alerted[volume.Path]
+		if volume.Level == models.StorageLevelOK {
+			if previous != "" {
+				s.log.Info("Free space of data directory %s recovered: %.1f%%", volume.Path, volume.FreePercent)
+			}
+			delete(s.alerted, volume.Path)
+			continue
+		}
+		s.alerted[volume.Path] = volume.Level
+		if storageLevelRank(volume.Level) <= storageLevelRank(previous) {
+			continue
+		}
+
+		level := models.NotificationLevelWarning
+		if volume.Level == models.StorageLevelCritical {
+			level = models.NotificationLevelError
+		}
+		message := fmt.Sprintf("Data directory %s is running out of space: %s of %s free (%.1f%%)",
+			volume.Path, formatBytes(volume.FreeBytes), formatBytes(volume.TotalBytes), volume.FreePercent)
+		if volume.Error != "" {
+			message = fmt.Sprintf("Free space of data directory %s is unknown: %s", volume.Path, volume.Error)
+		}
+		s.notificationService.NotifyAdmins("disk_space", level, message)
+	}
+}
+
+// Status returns the result of the latest check.
+func (s *DiskWatcherService) Status() *models.StorageStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.status
+}
+
+// checkVolume measures and rates the free space of a data directory's volume.
+func (s *DiskWatcherService) checkVolume(dir string) *models.StorageVolume {
+	volume := &models.StorageVolume{Path: dir}
+	total, free, err := diskUsage(dir)
+	if err != nil || total <= 0 {
+		if err == nil {
+			err = fmt.Errorf("volume reports no size")
+		}
+		volume.Level = models.StorageLevelCritical
+		volume.Error = err.Error()
+		return volume
+	}
+
+	volume.TotalBytes = total
+	volume.FreeBytes = free
+	volume.FreePercent = float64(free) / float64(total) * 100
+	switch {
+	case volume.FreePercent < s.criticalFreePercent:
+		volume.Level = models.StorageLevelCritical
+	case volume.FreePercent < s.warningFreePercent:
+		volume.Level = models.StorageLevelWarning
+	default:
+		volume.Level = models.StorageLevelOK
+	}
+	return volume
+}
+
+// storageLevelRank orders storage levels from best to worst; no level ranks lowest.
+func storageLevelRank(level models.StorageLevel) int {
+	switch level {
+	case models.StorageLevelOK:
+		return 0
+	case models.StorageLevelWarning:
+		return 1
+	case models.StorageLevelCritical:
+		return 2
+	}
+	return -1
+}
@@ -0,0 +1,63 @@
+package service_test
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("DiskWatcherService", func() {
+	var (
+		dataDir             string
+		notificationService *service.NotificationService
+	)
+
+	BeforeEach(func() {
+		dataDir = GinkgoT().TempDir()
+		notificationService = service.NewNotificationService(logger.New())
+	})
+
+	It("reports volumes with enough free space as ok", func() {
+		watcher := service.NewDiskWatcherService([]string{dataDir}, 0, 0, notificationService, logger.New())
+		watcher.Check()
+
+		status := watcher.Status()
+		Expect(status.Level).To(Equal(models.StorageLevelOK))
+		Expect(status.Volumes).To(HaveLen(1))
+		Expect(status.Volumes[0].TotalBytes).To(BeNumerically(">", 0))
+		Expect(status.Volumes[0].FreeBytes).To(BeNumerically("<=", status.Volumes[0].TotalBytes))
+		Expect(notificationService.ListAdmin(false).Notifications).To(BeEmpty())
+	})
+
+	It("notifies administrators once per level when free space runs low", func() {
+		// A volume is never completely free, so every volume is below 100% free space
+		watcher := service.NewDiskWatcherService([]string{dataDir}, 100, 100, notificationService, logger.New())
+		watcher.Check()
+		watcher.Check()
+
+		Expect(watcher.Status().Level).To(Equal(models.StorageLevelCritical))
+		notifications := notificationService.ListAdmin(false).Notifications
+		Expect(notifications).To(HaveLen(1))
+		Expect(notifications[0].Type).To(Equal("disk_space"))
+		Expect(notifications[0].Level).To(Equal(models.NotificationLevelError))
+		Expect(notifications[0].Message).To(ContainSubstring(dataDir))
+
+		// Administrator notifications are not shown to users
+		Expect(notificationService.List("default", false).Notifications).To(BeEmpty())
+	})
+
+	It("rates volumes whose free space is unknown as critical", func() {
+		watcher := service.NewDiskWatcherService([]string{filepath.Join(dataDir, "missing")}, 10, 5, notificationService, logger.New())
+		watcher.Check()
+
+		status := watcher.Status()
+		Expect(status.Level).To(Equal(models.StorageLevelCritical))
+		Expect(status.Volumes[0].Error).NotTo(BeEmpty())
+		Expect(notificationService.ListAdmin(true).Unread).To(Equal(1))
+	})
+})
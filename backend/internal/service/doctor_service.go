@@ -0,0 +1,348 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/types"
+)
+
+// DoctorLevel is the severity of a diagnostic finding.
+type DoctorLevel string
+
+const (
+	DoctorOK   DoctorLevel = "ok"   // Check passed
+	DoctorWarn DoctorLevel = "warn" // Works, but should be looked at
+	DoctorFail DoctorLevel = "fail" // Broken, the server will not work correctly
+)
+
+// DoctorFinding is the result of a single diagnostic check.
+type DoctorFinding struct {
+	Check   string      `json:"check"`          // Check name (e.g. "data-dir")
+	Level   DoctorLevel `json:"level"`          // Severity
+	Message string      `json:"message"`        // What was found
+	Hint    string      `json:"hint,omitempty"` // How to fix it (for warnings and failures)
+}
+
+// gosmeeRequiredFlags are the gosmee client flags used by buildGosmeeCommand.
+var gosmeeRequiredFlags = []string{"--saveDir", "--target-connection-timeout", "--sse-buffer-size", "--ignore-event", "--noReplay", "--httpie"}
+
+// DoctorService diagnoses the installation: data directory, gosmee binary and OIDC issuer.
+type DoctorService struct {
+	dataDir    string
+	oidc       *types.OIDCConfig
+	httpClient *http.Client
+	embedded   bool // Clients run in the server process, gosmee is not needed
+}
+
+// NewDoctorService creates a new doctor service.
+func NewDoctorService(dataDir string, oidc *types.OIDCConfig) *DoctorService {
+	return &DoctorService{
+		dataDir:    dataDir,
+		oidc:       oidc,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// SetClientRuntime sets how the server runs clients (one of the ClientRuntime* constants).
+// The gosmee binary is not checked for embedded clients.
+func (s *DoctorService) SetClientRuntime(runtime string) {
+	s.embedded = runtime == ClientRuntimeEmbedded
+}
+
+// Run runs all checks.
+func (s *DoctorService) Run() []*DoctorFinding {
+	var findings []*DoctorFinding
+	findings = append(findings, s.CheckDataDir()...)
+	findings = append(findings, s.CheckGosmeeBinary()...)
+	findings = append(findings, s.CheckOIDC()...)
+	return findings
+}
+
+// CheckDataDir validates the data directory layout and permissions.
+func (s *DoctorService) CheckDataDir() []*DoctorFinding {
+	const check = "data-dir"
+
+	info, err := os.Stat(s.dataDir)
+	if err != nil {
+		return []*DoctorFinding{failFinding(check, fmt.Sprintf("Data directory %s is not accessible: %v", s.dataDir, err),
+			"Create the directory or point --data-dir (GOSMEE_DATA_DIR) to the correct location")}
+	}
+	if !info.IsDir() {
+		return []*DoctorFinding{failFinding(check, fmt.Sprintf("%s is not a directory", s.dataDir),
+			"Point --data-dir (GOSMEE_DATA_DIR) to a directory")}
+	}
+
+	var findings []*DoctorFinding
+
+	probe, err := os.CreateTemp(s.dataDir, ".doctor-")
+	if err != nil {
+		findings = append(findings, failFinding(check, fmt.Sprintf("Data directory %s is not writable: %v", s.dataDir, err),
+			fmt.Sprintf("Make it writable by the server user (uid %d), e.g. chown -R %d %s", os.Getuid(), os.Getuid(), s.dataDir)))
+	} else {
+		probe.Close()
+		os.Remove(probe.Name())
+		findings = append(findings, okFinding(check, fmt.Sprintf("Data directory %s is writable", s.dataDir)))
+	}
+	if info.Mode().Perm()&0007 != 0 {
+		findings = append(findings, warnFinding(check, fmt.Sprintf("Data directory %s is accessible by other users (mode %s)", s.dataDir, info.Mode().Perm()),
+			fmt.Sprintf("Restrict access with chmod 700 %s", s.dataDir)))
+	}
+
+	leftovers, _ := filepath.Glob(filepath.Join(s.dataDir, ".import-*"))
+	for _, leftover := range leftovers {
+		findings = append(findings, warnFinding(check, fmt.Sprintf("Leftover import staging directory %s", leftover),
+			"Remove it once no import is running"))
+	}
+
+	findings = append(findings, s.checkServiceAccountsFile()...)
+	findings = append(findings, s.checkClients()...)
+	return findings
+}
+
+// checkServiceAccountsFile validates the service accounts file, which holds token hashes.
+func (s *DoctorService) checkServiceAccountsFile() []*DoctorFinding {
+	const check = "service-accounts"
+
+	path := filepath.Join(s.dataDir, serviceAccountsFile)
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return []*DoctorFinding{failFinding(check, fmt.Sprintf("Cannot access %s: %v", path, err), "Fix the file permissions")}
+	}
+
+	var findings []*DoctorFinding
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []*DoctorFinding{failFinding(check, fmt.Sprintf("Cannot read %s: %v", path, err), "Fix the file permissions")}
+	}
+	var accounts []json.RawMessage
+	if err := json.Unmarshal(data, &accounts); err != nil {
+		findings = append(findings, failFinding(check, fmt.Sprintf("%s is corrupt: %v", path, err),
+			"Restore it from a backup, or remove it and recreate the service accounts"))
+	} else {
+		findings = append(findings, okFinding(check, fmt.Sprintf("%d service accounts", len(accounts))))
+	}
+	if info.Mode().Perm()&0077 != 0 {
+		findings = append(findings, warnFinding(check, fmt.Sprintf("%s is readable by other users (mode %s)", path, info.Mode().Perm()),
+			fmt.Sprintf("Restrict access with chmod 600 %s", path)))
+	}
+	return findings
+}
+
+// checkClients validates every client directory below users/.
+func (s *DoctorService) checkClients() []*DoctorFinding {
+	const check = "clients"
+
+	usersDir := filepath.Join(s.dataDir, "users")
+	users, err := os.ReadDir(usersDir)
+	if os.IsNotExist(err) {
+		return []*DoctorFinding{okFinding(check, "No users yet")}
+	}
+	if err != nil {
+		return []*DoctorFinding{failFinding(check, fmt.Sprintf("Cannot read %s: %v", usersDir, err), "Fix the directory permissions")}
+	}
+
+	var findings []*DoctorFinding
+	total := 0
+	for _, user := range users {
+		if !user.IsDir() {
+			continue
+		}
+		clientsDir := filepath.Join(usersDir, user.Name(), "clients")
+		clients, err := os.ReadDir(clientsDir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			findings = append(findings, failFinding(check, fmt.Sprintf("Cannot read %s: %v", clientsDir, err), "Fix the directory permissions"))
+			continue
+		}
+
+		for _, entry := range clients {
+			if !entry.IsDir() {
+				continue
+			}
+			total++
+			findings = append(findings, checkClientDir(filepath.Join(clientsDir, entry.Name()), user.Name(), entry.Name())...)
+		}
+	}
+
+	if len(findings) == 0 {
+		findings = append(findings, okFinding(check, fmt.Sprintf("%d clients of %d users look healthy", total, len(users))))
+	}
+	return findings
+}
+
+// checkClientDir validates a single client directory.
+func checkClientDir(clientDir, userID, clientID string) []*DoctorFinding {
+	const check = "clients"
+
+	configPath := filepath.Join(clientDir, "config.json")
+	data, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return []*DoctorFinding{warnFinding(check, fmt.Sprintf("Client directory %s has no config.json and is ignored", clientDir),
+			"Remove the directory if the client is no longer needed")}
+	}
+	if err != nil {
+		return []*DoctorFinding{failFinding(check, fmt.Sprintf("Cannot read %s: %v", configPath, err), "Fix the file permissions")}
+	}
+
+	var client models.Client
+	if err := json.Unmarshal(data, &client); err != nil {
+		return []*DoctorFinding{failFinding(check, fmt.Sprintf("%s is corrupt: %v", configPath, err),
+			"Restore the client from a backup (gosmee-web import) or remove its directory")}
+	}
+
+	var findings []*DoctorFinding
+	if client.ID != clientID || client.UserID != userID {
+		findings = append(findings, failFinding(check,
+			fmt.Sprintf("%s belongs to client %s of user %s but is stored under %s/%s", configPath, client.ID, client.UserID, userID, clientID),
+			"Move the directory to users/<userId>/clients/<id> matching its config.json"))
+	}
+	if file, err := os.OpenFile(configPath, os.O_WRONLY, 0); err != nil {
+		findings = append(findings, failFinding(check, fmt.Sprintf("%s is not writable: %v", configPath, err),
+			fmt.Sprintf("Make the data directory writable by the server user (uid %d)", os.Getuid())))
+	} else {
+		file.Close()
+	}
+	for _, sub := range []string{"events", "logs"} {
+		if info, err := os.Stat(filepath.Join(clientDir, sub)); err != nil || !info.IsDir() {
+			findings = append(findings, warnFinding(check, fmt.Sprintf("Client %s has no %s directory", clientID, sub),
+				fmt.Sprintf("Create it with mkdir %s", filepath.Join(clientDir, sub))))
+		}
+	}
+	return findings
+}
+
+// CheckGosmeeBinary verifies that the gosmee binary is installed and supports the flags used.
+func (s *DoctorService) CheckGosmeeBinary() []*DoctorFinding {
+	const check = "gosmee"
+
+	if s.embedded {
+		return []*DoctorFinding{okFinding(check, "Clients run in the server process, gosmee is not needed")}
+	}
+	path, err := exec.LookPath(gosmeeBinary)
+	if err != nil {
+		return []*DoctorFinding{failFinding(check, "gosmee binary not found in PATH",
+			"Install gosmee from https://github.com/chmouel/gosmee/releases and make sure it is in the server's PATH")}
+	}
+
+	findings := []*DoctorFinding{}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	version, err := exec.CommandContext(ctx, path, "--version").CombinedOutput()
+	if err != nil {
+		findings = append(findings, failFinding(check, fmt.Sprintf("%s does not run: %v", path, err),
+			"Reinstall gosmee for this platform"))
+		return findings
+	}
+	findings = append(findings, okFinding(check, fmt.Sprintf("%s: %s", path, strings.TrimSpace(string(version)))))
+
+	help, _ := exec.CommandContext(ctx, path, "client", "--help").CombinedOutput()
+	var missing []string
+	for _, flag := range gosmeeRequiredFlags {
+		if !strings.Contains(string(help), flag) {
+			missing = append(missing, flag)
+		}
+	}
+	if len(missing) > 0 {
+		findings = append(findings, failFinding(check, fmt.Sprintf("gosmee client does not support %s", strings.Join(missing, ", ")),
+			"Upgrade gosmee to the version pinned in the Dockerfile (GOSMEE_VERSION)"))
+	}
+	for _, flags := range gosmeeLogLevelFlags {
+		for _, flag := range flags {
+			if !strings.Contains(string(help), flag) {
+				findings = append(findings, warnFinding(check, fmt.Sprintf("gosmee client does not support %s; clients with log level \"debug\" will fail to start", flag),
+					"Upgrade gosmee, or set the log level of these clients back to \"info\""))
+			}
+		}
+	}
+	return findings
+}
+
+// CheckOIDC verifies the OIDC configuration and probes the issuer's discovery document.
+func (s *DoctorService) CheckOIDC() []*DoctorFinding {
+	const check = "oidc"
+
+	cfg := s.oidc
+	if cfg.ClientID == "" && cfg.ClientSecret == "" && cfg.Issuer == "" {
+		return []*DoctorFinding{okFinding(check, "OIDC authentication is disabled; all users share a single implicit account")}
+	}
+	if !cfg.Enabled {
+		var missing []string
+		if cfg.ClientID == "" {
+			missing = append(missing, "--oidc-client-id")
+		}
+		if cfg.ClientSecret == "" {
+			missing = append(missing, "--oidc-client-secret (or --oidc-pkce)")
+		}
+		if cfg.Issuer == "" {
+			missing = append(missing, "--oidc-issuer")
+		}
+		return []*DoctorFinding{warnFinding(check, "OIDC is partially configured and therefore disabled",
+			fmt.Sprintf("Set %s as well, or unset the other OIDC options", strings.Join(missing, ", ")))}
+	}
+
+	var findings []*DoctorFinding
+	if cfg.RedirectURL == "" {
+		findings = append(findings, failFinding(check, "No OIDC redirect URL configured",
+			"Set --oidc-redirect-url to https://<your-domain>/api/v1/auth/callback"))
+	} else if !strings.HasSuffix(cfg.RedirectURL, "/api/v1/auth/callback") {
+		findings = append(findings, warnFinding(check, fmt.Sprintf("Redirect URL %s does not point to /api/v1/auth/callback", cfg.RedirectURL),
+			"Set --oidc-redirect-url to https://<your-domain>/api/v1/auth/callback"))
+	}
+
+	discoveryURL := strings.TrimSuffix(cfg.Issuer, "/") + "/.well-known/openid-configuration"
+	resp, err := s.httpClient.Get(discoveryURL)
+	if err != nil {
+		return append(findings, failFinding(check, fmt.Sprintf("OIDC issuer unreachable: %v", err),
+			"Check --oidc-issuer and the server's network access (DNS, proxy, TLS certificates)"))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return append(findings, failFinding(check, fmt.Sprintf("%s returned HTTP %d", discoveryURL, resp.StatusCode),
+			"Check that --oidc-issuer is the issuer URL (without /.well-known/...)"))
+	}
+
+	var discovery struct {
+		Issuer string `json:"issuer"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&discovery); err != nil {
+		return append(findings, failFinding(check, fmt.Sprintf("Invalid discovery document at %s: %v", discoveryURL, err),
+			"Check that --oidc-issuer points to an OpenID Connect provider"))
+	}
+	if discovery.Issuer != cfg.Issuer {
+		return append(findings, failFinding(check, fmt.Sprintf("Issuer mismatch: configured %s, provider reports %s", cfg.Issuer, discovery.Issuer),
+			fmt.Sprintf("Set --oidc-issuer to %s", discovery.Issuer)))
+	}
+
+	return append(findings, okFinding(check, fmt.Sprintf("OIDC issuer %s is reachable", cfg.Issuer)))
+}
+
+// ok, warn and fail build findings of the respective level.
+func okFinding(check, message string) *DoctorFinding {
+	return &DoctorFinding{Check: check, Level: DoctorOK, Message: message}
+}
+
+func warnFinding(check, message, hint string) *DoctorFinding {
+	return &DoctorFinding{Check: check, Level: DoctorWarn, Message: message, Hint: hint}
+}
+
+func failFinding(check, message, hint string) *DoctorFinding {
+	return &DoctorFinding{Check: check, Level: DoctorFail, Message: message, Hint: hint}
+}
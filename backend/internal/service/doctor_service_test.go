@@ -0,0 +1,123 @@
+package service_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+	"github.com/lazycatapps/gosmee/backend/internal/types"
+)
+
+var _ = Describe("DoctorService", func() {
+	levels := func(findings []*service.DoctorFinding) map[service.DoctorLevel]int {
+		counts := make(map[service.DoctorLevel]int)
+		for _, finding := range findings {
+			counts[finding.Level]++
+		}
+		return counts
+	}
+
+	writeConfig := func(dataDir, userID, clientID, content string) {
+		clientDir := filepath.Join(dataDir, "users", userID, "clients", clientID)
+		Expect(os.MkdirAll(filepath.Join(clientDir, "events"), 0755)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(clientDir, "logs"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(clientDir, "config.json"), []byte(content), 0644)).To(Succeed())
+	}
+
+	Describe("CheckDataDir", func() {
+		var dataDir string
+
+		BeforeEach(func() {
+			dataDir = GinkgoT().TempDir()
+			Expect(os.Chmod(dataDir, 0700)).To(Succeed())
+		})
+
+		It("passes for a healthy data directory", func() {
+			writeConfig(dataDir, "alice", "c1", `{"id":"c1","userId":"alice"}`)
+
+			findings := service.NewDoctorService(dataDir, &types.OIDCConfig{}).CheckDataDir()
+			Expect(levels(findings)).To(Equal(map[service.DoctorLevel]int{service.DoctorOK: 2}))
+		})
+
+		It("reports misplaced, corrupt and orphaned clients", func() {
+			writeConfig(dataDir, "alice", "c1", `{"id":"c1","userId":"bob"}`)
+			writeConfig(dataDir, "alice", "c2", `{not json`)
+			Expect(os.MkdirAll(filepath.Join(dataDir, "users", "alice", "clients", "c3"), 0755)).To(Succeed())
+
+			findings := service.NewDoctorService(dataDir, &types.OIDCConfig{}).CheckDataDir()
+			counts := levels(findings)
+			Expect(counts[service.DoctorFail]).To(Equal(2))
+			Expect(counts[service.DoctorWarn]).To(Equal(1))
+			for _, finding := range findings {
+				if finding.Level != service.DoctorOK {
+					Expect(finding.Hint).NotTo(BeEmpty())
+				}
+			}
+		})
+
+		It("fails for a missing data directory", func() {
+			findings := service.NewDoctorService(filepath.Join(dataDir, "missing"), &types.OIDCConfig{}).CheckDataDir()
+			Expect(findings).To(HaveLen(1))
+			Expect(findings[0].Level).To(Equal(service.DoctorFail))
+		})
+	})
+
+	Describe("CheckOIDC", func() {
+		var (
+			issuer     *httptest.Server
+			reportedAs string
+		)
+
+		BeforeEach(func() {
+			issuer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/.well-known/openid-configuration" {
+					http.NotFound(w, r)
+					return
+				}
+				json.NewEncoder(w).Encode(map[string]string{"issuer": reportedAs})
+			}))
+			reportedAs = issuer.URL
+		})
+
+		AfterEach(func() {
+			issuer.Close()
+		})
+
+		oidcConfig := func() *types.OIDCConfig {
+			return &types.OIDCConfig{
+				ClientID:     "client",
+				ClientSecret: "secret",
+				Issuer:       issuer.URL,
+				RedirectURL:  "https://gosmee.example.com/api/v1/auth/callback",
+				Enabled:      true,
+			}
+		}
+
+		It("passes for a reachable issuer", func() {
+			findings := service.NewDoctorService("", oidcConfig()).CheckOIDC()
+			Expect(levels(findings)).To(Equal(map[service.DoctorLevel]int{service.DoctorOK: 1}))
+		})
+
+		It("fails when the provider reports a different issuer", func() {
+			reportedAs = "https://other.example.com"
+
+			findings := service.NewDoctorService("", oidcConfig()).CheckOIDC()
+			Expect(findings).To(HaveLen(1))
+			Expect(findings[0].Level).To(Equal(service.DoctorFail))
+			Expect(findings[0].Hint).To(ContainSubstring("https://other.example.com"))
+		})
+
+		It("warns about partial configuration", func() {
+			findings := service.NewDoctorService("", &types.OIDCConfig{ClientID: "client"}).CheckOIDC()
+			Expect(findings).To(HaveLen(1))
+			Expect(findings[0].Level).To(Equal(service.DoctorWarn))
+			Expect(findings[0].Hint).To(ContainSubstring("--oidc-issuer"))
+		})
+	})
+})
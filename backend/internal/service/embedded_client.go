@@ -0,0 +1,285 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+)
+
+// Delays between the reconnects of an embedded client whose event stream dropped, doubled
+// after every failed attempt.
+const (
+	embeddedMinReconnectDelay = time.Second
+	embeddedMaxReconnectDelay = 30 * time.Second
+)
+
+// defaultSSEBufferSize is the largest event stream message of clients without an SSE buffer size.
+const defaultSSEBufferSize = 1024 * 1024
+
+// smeeMessageFields are the fields of a smee message that are not headers of the webhook request.
+var smeeMessageFields = map[string]bool{"body": true, "bodyB": true, "query": true, "timestamp": true}
+
+// smeeConnectionHeaders are headers of the webhook request that describe its connection to
+// the smee server rather than the webhook, and are not stored.
+var smeeConnectionHeaders = map[string]bool{"host": true, "connection": true, "content-length": true, "accept-encoding": true}
+
+// streamRejectedError is returned when the smee server refuses the event stream, which
+// reconnecting doesn't fix.
+type streamRejectedError struct {
+	status string
+}
+
+func (e *streamRejectedError) Error() string {
+	return "smee server answered " + e.status
+}
+
+// embeddedClient is a smee client running in the backend process instead of a gosmee process.
+// It reads the event stream of the client's channel and hands every webhook to the ingester,
+// which saves and processes it like the events gosmee writes, whatever the event storage.
+// The backend forwards the events (see EventService.ForwardNewEvents). Its log lines are
+// written to pipes read like the output of a gosmee process.
+type embeddedClient struct {
+	client     *models.Client
+	ingester   EventIngester
+	httpClient *http.Client
+	stdout     *io.PipeWriter
+	stderr     *io.PipeWriter
+	cancel     context.CancelFunc
+	done       chan struct{} // Closed when the client has stopped
+	err        error         // Why the client stopped on its own, nil if stopped on request
+}
+
+// newEmbeddedClient creates an embedded client handing the events of client to ingester and
+// returns it with the readers of its standard and error log output, which must be drained
+// once it is started.
+func newEmbeddedClient(client *models.Client, ingester EventIngester) (*embeddedClient, io.Reader, io.Reader) {
+	stdoutReader, stdout := io.Pipe()
+	stderrReader, stderr := io.Pipe()
+	c := &embeddedClient{
+		client:     client,
+		ingester:   ingester,
+		httpClient: &http.Client{}, // No timeout, the event stream stays open
+		stdout:     stdout,
+		stderr:     stderr,
+		done:       make(chan struct{}),
+	}
+	return c, stdoutReader, stderrReader
+}
+
+// start starts consuming the event stream in the background.
+func (c *embeddedClient) start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	go c.consumeAndClose(ctx)
+}
+
+// stop stops the started client. It returns right away, wait waits for the client to have stopped.
+func (c *embeddedClient) stop() {
+	c.cancel()
+}
+
+// wait waits for the client to stop and returns why it stopped on its own.
+func (c *embeddedClient) wait() error {
+	<-c.done
+	return c.err
+}
+
+// consumeAndClose consumes the event stream until the client is stopped or fails, then
+// closes the log output.
+func (c *embeddedClient) consumeAndClose(ctx context.Context) {
+	defer close(c.done)
+	defer c.stderr.Close()
+	defer c.stdout.Close()
+
+	c.err = c.consume(ctx)
+	if c.err != nil {
+		c.logf(c.stderr, "Error: %v", c.err)
+	}
+}
+
+// consume reads the event stream, reconnecting when an established stream drops. It fails if
+// the first connection can't be established or the smee server refuses the stream, like a
+// gosmee process exits.
+func (c *embeddedClient) consume(ctx context.Context) error {
+	if _, err := parseHTTPURL(c.client.SmeeURL); err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+
+	established := false
+	delay := embeddedMinReconnectDelay
+	for {
+		err := c.stream(ctx, func() {
+			established = true
+			delay = embeddedMinReconnectDelay
+		})
+		if ctx.Err() != nil {
+			return nil
+		}
+		var rejected *streamRejectedError
+		if !established || errors.As(err, &rejected) {
+			return err
+		}
+
+		c.logf(c.stderr, "Event stream of %s lost: %v, reconnecting in %s", c.client.SmeeURL, err, delay)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+		delay = min(2*delay, embeddedMaxReconnectDelay)
+	}
+}
+
+// stream connects to the smee channel and handles its messages until the stream ends.
+// connected is called once the smee server accepted the stream.
+func (c *embeddedClient) stream(ctx context.Context, connected func()) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.client.SmeeURL, nil)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case resp.StatusCode >= 400 && resp.StatusCode < 500 &&
+		resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests:
+		return &streamRejectedError{status: resp.Status}
+	default:
+		return fmt.Errorf("smee server answered %s", resp.Status)
+	}
+	connected()
+	c.logf(c.stdout, "Connected to %s", c.client.SmeeURL)
+
+	bufferSize := c.client.SSEBufferSize
+	if bufferSize <= 0 {
+		bufferSize = defaultSSEBufferSize
+	}
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, min(bufferSize, 64*1024)), bufferSize)
+
+	// Server-sent events: "field: value" lines, a blank line ends a message
+	var name string
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			c.handle(name, strings.Join(data, "\n"))
+			name, data = "", nil
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			name = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return fmt.Errorf("event larger than the SSE buffer size of %d bytes", bufferSize)
+		}
+		return err
+	}
+	return fmt.Errorf("stream closed by the smee server")
+}
+
+// handle ingests the webhook of a smee message as a new event. Messages of the smee server
+// itself (ready, ping) and events of ignored types are skipped.
+func (c *embeddedClient) handle(name, data string) {
+	if name == "ready" || name == "ping" || data == "" {
+		return
+	}
+
+	event, err := parseSmeeMessage(c.client, []byte(data))
+	if err != nil {
+		c.logf(c.stderr, "Skipping malformed message: %v", err)
+		return
+	}
+	if event.EventType != "" && slices.Contains(c.client.IgnoreEvents, event.EventType) {
+		c.logf(c.stdout, "Skipping event of ignored type %s", event.EventType)
+		return
+	}
+	if err := c.ingester.Ingest(c.client.ID, event); err != nil {
+		c.logf(c.stderr, "Failed to save event %s: %v", event.ID, err)
+		return
+	}
+	c.logf(c.stdout, "Received event %s (type: %s)", event.ID, event.EventType)
+}
+
+// logf writes a log line to the client's log output.
+func (c *embeddedClient) logf(out *io.PipeWriter, format string, args ...interface{}) {
+	fmt.Fprintf(out, format+"\n", args...)
+}
+
+// parseSmeeMessage builds a new event from a smee message: a JSON object of the webhook's
+// headers (lowercase names) with its body, as JSON ("body") or base64-encoded ("bodyB"),
+// and the time it was received in milliseconds ("timestamp").
+func parseSmeeMessage(client *models.Client, data []byte) (*models.Event, error) {
+	var message map[string]json.RawMessage
+	if err := json.Unmarshal(data, &message); err != nil {
+		return nil, fmt.Errorf("message is not a JSON object: %w", err)
+	}
+
+	event := &models.Event{
+		ID:        uuid.New().String(),
+		ClientID:  client.ID,
+		Timestamp: time.Now().UTC(),
+		Status:    models.EventStatusNotReplayed,
+		Headers:   make(map[string]string),
+	}
+	for key, raw := range message {
+		if smeeMessageFields[key] || smeeConnectionHeaders[strings.ToLower(key)] {
+			continue
+		}
+		var value string
+		if err := json.Unmarshal(raw, &value); err == nil {
+			event.Headers[http.CanonicalHeaderKey(key)] = value
+		}
+	}
+
+	if raw, ok := message["bodyB"]; ok {
+		var encoded string
+		if err := json.Unmarshal(raw, &encoded); err != nil {
+			return nil, fmt.Errorf("bodyB is not a string: %w", err)
+		}
+		body, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("bodyB is not valid base64: %w", err)
+		}
+		event.SetPayloadBytes(body)
+	} else if raw, ok := message["body"]; ok && string(raw) != "null" {
+		event.Payload = string(raw)
+	}
+
+	var timestamp int64
+	if raw, ok := message["timestamp"]; ok && json.Unmarshal(raw, &timestamp) == nil && timestamp > 0 {
+		event.Timestamp = time.UnixMilli(timestamp).UTC()
+	}
+
+	event.EventType = detectEventType(client, event.Headers, event.Payload)
+	event.ResolveDeliveryID()
+	return event, nil
+}
@@ -0,0 +1,168 @@
+package service_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("Embedded clients", func() {
+	var (
+		baseDir        string
+		eventRepo      *repository.FileEventRepository
+		watcherService *service.WatcherService
+		processService *service.ProcessService
+		client         *models.Client
+		forwarded      chan []string
+	)
+
+	BeforeEach(func() {
+		baseDir = GinkgoT().TempDir()
+		log := logger.New()
+		// Events are stored outside the watched directory, like in object storage, so only
+		// events handed over by the embedded client are processed
+		storeDir := GinkgoT().TempDir()
+		Expect(os.MkdirAll(filepath.Join(storeDir, "users", "user-embedded", "clients", "client-embedded", "events"), 0755)).To(Succeed())
+		eventRepo = repository.NewFileEventRepository(storeDir)
+		quotaService := service.NewQuotaService(repository.NewFileQuotaRepository(baseDir, 1000000, 10), repository.NewFileQuotaHistoryRepository(baseDir), log)
+		var err error
+		watcherService, err = service.NewWatcherService(eventRepo, quotaService, log)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(watcherService.Close)
+		forwarded = make(chan []string, 10)
+		watcherService.SetForwarder(func(clientID string, eventIDs []string) { forwarded <- eventIDs })
+
+		processService = service.NewProcessService(false, 0, time.Minute, log)
+		processService.SetEventWatcher(watcherService)
+		processService.SetEmbeddedClients(watcherService)
+		client = &models.Client{
+			ID:           "client-embedded",
+			UserID:       "user-embedded",
+			TargetURL:    "http://127.0.0.1:1/hook",
+			IgnoreEvents: []string{"issues"},
+		}
+	})
+
+	// smee serves an event stream sending the messages, then keeping the stream open
+	smee := func(messages ...string) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Header.Get("Accept")).To(Equal("text/event-stream"))
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "event: ready\ndata: {}\n\n")
+			for _, message := range messages {
+				fmt.Fprintf(w, "data: %s\n\n", message)
+			}
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}))
+		DeferCleanup(server.Close)
+		return server
+	}
+
+	It("saves the webhooks of the event stream as events", func() {
+		client.SmeeURL = smee(
+			`{"x-github-event":"issues","body":{"action":"opened"},"timestamp":1759320000000}`,
+			`{"x-github-event":"push","x-github-delivery":"delivery-1","content-type":"application/json","host":"smee.example.com","body":{"ref":"main"},"query":{},"timestamp":1759320000000}`,
+		).URL
+		Expect(processService.Start(client, baseDir)).To(Succeed())
+		DeferCleanup(processService.StopAll)
+
+		var events []*models.EventSummary
+		Eventually(func() ([]*models.EventSummary, error) {
+			list, err := eventRepo.GetByClientID(client.ID, &models.EventListRequest{Page: 1, PageSize: 20})
+			if err != nil {
+				return nil, err
+			}
+			events = list.Events
+			return events, nil
+		}, 5*time.Second, 50*time.Millisecond).Should(HaveLen(1))
+
+		event, err := eventRepo.Get(client.ID, events[0].ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(event.EventType).To(Equal("push"))
+		Expect(event.DeliveryID).To(Equal("delivery-1"))
+		Expect(event.Status).To(Equal(models.EventStatusNotReplayed))
+		Expect(event.Timestamp).To(Equal(time.UnixMilli(1759320000000).UTC()))
+		Expect(event.Headers).To(Equal(map[string]string{
+			"X-Github-Event":    "push",
+			"X-Github-Delivery": "delivery-1",
+			"Content-Type":      "application/json",
+		}))
+		Expect(event.Payload).To(Equal(`{"ref":"main"}`))
+		Expect(forwarded).To(Receive(Equal([]string{event.ID})))
+
+		info, err := processService.GetProcessInfo(client.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.PID).To(BeZero())
+		Expect(info.GetLogLines()).To(ContainElement(ContainSubstring("Connected to " + client.SmeeURL)))
+
+		Expect(processService.Stop(client.ID)).To(Succeed())
+		Expect(processService.IsRunning(client.ID)).To(BeFalse())
+	})
+
+	It("processes events as soon as they are received", func() {
+		summaries, unsubscribe := watcherService.Subscribe(client.ID)
+		defer unsubscribe()
+		client.SmeeURL = smee(`{"x-github-event":"push","body":{"ref":"main"}}`).URL
+		Expect(processService.Start(client, baseDir)).To(Succeed())
+		DeferCleanup(processService.StopAll)
+
+		// Files gosmee writes are processed once they settled for 2 seconds
+		var summary *models.EventSummary
+		Eventually(summaries, time.Second).Should(Receive(&summary))
+		Expect(summary.EventType).To(Equal("push"))
+		Eventually(forwarded, time.Second).Should(Receive(Equal([]string{summary.ID})))
+		Consistently(forwarded, 3*time.Second).ShouldNot(Receive())
+	})
+
+	It("stores binary bodies base64-encoded", func() {
+		client.SmeeURL = smee(`{"content-type":"application/octet-stream","bodyB":"/w=="}`).URL
+		Expect(processService.Start(client, baseDir)).To(Succeed())
+		DeferCleanup(processService.StopAll)
+
+		var events []*models.EventSummary
+		Eventually(func() ([]*models.EventSummary, error) {
+			list, err := eventRepo.GetByClientID(client.ID, &models.EventListRequest{Page: 1, PageSize: 20})
+			if err != nil {
+				return nil, err
+			}
+			events = list.Events
+			return events, nil
+		}, 5*time.Second, 50*time.Millisecond).Should(HaveLen(1))
+
+		event, err := eventRepo.Get(client.ID, events[0].ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(event.PayloadEncoding).To(Equal(models.PayloadEncodingBase64))
+		Expect(event.PayloadBytes()).To(Equal([]byte{0xff}))
+	})
+
+	It("exits when the smee server refuses the event stream", func() {
+		server := httptest.NewServer(http.NotFoundHandler())
+		DeferCleanup(server.Close)
+		client.SmeeURL = server.URL
+		Expect(processService.Start(client, baseDir)).To(Succeed())
+
+		Eventually(func() error {
+			_, err := processService.GetLastRun(client.ID, models.LogFilter{})
+			return err
+		}, 5*time.Second, 50*time.Millisecond).Should(Succeed())
+
+		run, err := processService.GetLastRun(client.ID, models.LogFilter{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(run.Crashed).To(BeTrue())
+		Expect(run.ExitCode).To(Equal(1))
+		Expect(run.Error).To(Equal("smee server answered 404 Not Found"))
+		Expect(processService.IsRunning(client.ID)).To(BeFalse())
+	})
+})
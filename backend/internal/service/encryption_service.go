@@ -0,0 +1,60 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+)
+
+// EncryptionService encrypts the event files gosmee writes in plaintext, when encryption
+// at rest is enabled. Events stored by the backend itself are encrypted when written.
+type EncryptionService struct {
+	clientRepo repository.ClientRepository
+	eventRepo  repository.EventRepository
+	log        logger.Logger
+
+	mu    sync.Mutex
+	swept map[string]time.Time // clientID -> files modified up to this time are encrypted
+}
+
+// NewEncryptionService creates a new encryption service.
+func NewEncryptionService(clientRepo repository.ClientRepository, eventRepo repository.EventRepository, log logger.Logger) *EncryptionService {
+	return &EncryptionService{
+		clientRepo: clientRepo,
+		eventRepo:  eventRepo,
+		log:        log,
+		swept:      make(map[string]time.Time),
+	}
+}
+
+// EncryptNewEvents encrypts the plaintext event files written since the previous run.
+// The first run after startup covers all stored events, so existing data directories are
+// encrypted on the first start with a key. It is executed periodically by the scheduler.
+func (s *EncryptionService) EncryptNewEvents() {
+	clients, err := s.clientRepo.ListAll()
+	if err != nil {
+		s.log.Error("Failed to list clients for event encryption: %v", err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	until := time.Now().Add(-eventSettleTime)
+	for _, client := range clients {
+		encrypted, err := s.eventRepo.EncryptPlaintextFiles(client.ID, s.swept[client.ID], until)
+		if err != nil {
+			s.log.Error("Failed to encrypt events of client %s: %v", client.ID, err)
+			continue
+		}
+		if encrypted > 0 {
+			s.log.Info("Encrypted %d event files of client %s", encrypted, client.ID)
+		}
+		s.swept[client.ID] = until
+	}
+}
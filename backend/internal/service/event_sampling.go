@@ -0,0 +1,89 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"sync"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+)
+
+// EventSampler applies the sampling policies of clients (see models.SamplingPolicy) to the
+// events gosmee stores, deleting the events a policy doesn't keep. The discarded events of
+// each client are counted.
+type EventSampler struct {
+	eventRepo repository.EventRepository
+	log       logger.Logger
+
+	mu        sync.Mutex
+	seen      map[string]int64 // clientID -> new events considered by the one_in_n mode
+	discarded map[string]int64 // clientID -> events discarded since startup
+}
+
+// NewEventSampler creates a new event sampler.
+func NewEventSampler(eventRepo repository.EventRepository, log logger.Logger) *EventSampler {
+	return &EventSampler{
+		eventRepo: eventRepo,
+		log:       log,
+		seen:      make(map[string]int64),
+		discarded: make(map[string]int64),
+	}
+}
+
+// Keep reports whether a new event of a client is kept under the sampling policy. The
+// one_in_n mode keeps the first of every Rate events. The failures mode keeps events that
+// weren't delivered successfully; events the backend is still to deliver are decided by
+// DiscardDelivered.
+func (s *EventSampler) Keep(clientID string, policy *models.SamplingPolicy, event *models.Event) bool {
+	switch policy.Mode {
+	case models.SamplingModeOneInN:
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		seen := s.seen[clientID]
+		s.seen[clientID]++
+		return seen%int64(policy.Rate) == 0
+	case models.SamplingModeFailures:
+		return event.Status != models.EventStatusSuccess
+	}
+	return true
+}
+
+// Discard deletes an event the sampling policy doesn't keep and counts it.
+func (s *EventSampler) Discard(clientID, eventID string) {
+	if err := s.eventRepo.Delete(clientID, eventID); err != nil {
+		s.log.Error("Failed to discard sampled out event %s: %v", eventID, err)
+		return
+	}
+
+	s.mu.Lock()
+	s.discarded[clientID]++
+	s.mu.Unlock()
+
+	s.log.Debug("Discarded event %s of client %s: sampled out", eventID, clientID)
+}
+
+// DiscardDelivered discards the events the backend delivered successfully, for clients
+// keeping only failures.
+func (s *EventSampler) DiscardDelivered(clientID string, eventIDs []string) {
+	for _, eventID := range eventIDs {
+		event, err := s.eventRepo.Get(clientID, eventID)
+		if err != nil {
+			continue
+		}
+		if event.Status == models.EventStatusSuccess {
+			s.Discard(clientID, eventID)
+		}
+	}
+}
+
+// Discarded returns how many events of a client were discarded since startup.
+func (s *EventSampler) Discarded(clientID string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.discarded[clientID]
+}
@@ -0,0 +1,87 @@
+package service_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("EventSampler", func() {
+	var (
+		baseDir   string
+		eventsDir string
+		eventRepo *repository.FileEventRepository
+		sampler   *service.EventSampler
+	)
+
+	BeforeEach(func() {
+		baseDir = GinkgoT().TempDir()
+		eventRepo = repository.NewFileEventRepository(baseDir)
+		sampler = service.NewEventSampler(eventRepo, logger.New())
+		eventsDir = filepath.Join(baseDir, "users", "user", "clients", "client", "events")
+		Expect(os.MkdirAll(eventsDir, 0755)).To(Succeed())
+	})
+
+	It("keeps one in every N events per client", func() {
+		policy := &models.SamplingPolicy{Mode: models.SamplingModeOneInN, Rate: 3}
+		var kept []bool
+		for i := 0; i < 7; i++ {
+			kept = append(kept, sampler.Keep("client", policy, &models.Event{}))
+		}
+		Expect(kept).To(Equal([]bool{true, false, false, true, false, false, true}))
+		Expect(sampler.Keep("other", policy, &models.Event{})).To(BeTrue())
+	})
+
+	It("keeps only events that weren't delivered successfully", func() {
+		policy := &models.SamplingPolicy{Mode: models.SamplingModeFailures}
+		Expect(sampler.Keep("client", policy, &models.Event{Status: models.EventStatusSuccess})).To(BeFalse())
+		Expect(sampler.Keep("client", policy, &models.Event{Status: models.EventStatusFailed})).To(BeTrue())
+		Expect(sampler.Keep("client", policy, &models.Event{Status: models.EventStatusNotReplayed})).To(BeTrue())
+	})
+
+	It("discards delivered events and counts them", func() {
+		Expect(os.WriteFile(filepath.Join(eventsDir, "ok.json"), []byte(`{"status":"success"}`), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(eventsDir, "bad.json"), []byte(`{"status":"failed"}`), 0644)).To(Succeed())
+
+		sampler.DiscardDelivered("client", []string{"ok", "bad", "missing"})
+
+		Expect(filepath.Join(eventsDir, "ok.json")).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(eventsDir, "bad.json")).To(BeAnExistingFile())
+		Expect(sampler.Discarded("client")).To(Equal(int64(1)))
+	})
+
+	It("rejects invalid sampling policies of clients", func() {
+		log := logger.New()
+		clientRepo, err := repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		clientService := service.NewClientService(
+			clientRepo,
+			repository.NewFileQuotaRepository(baseDir, 10*1024*1024, 10),
+			eventRepo,
+			service.NewProcessService(false, 0, time.Minute, log),
+			service.NewJobService(time.Hour, log),
+			baseDir,
+			log,
+		)
+		req := func(policy *models.SamplingPolicy) *models.ClientRequest {
+			return &models.ClientRequest{Name: "chatty", SmeeURL: "https://smee.io/abc", TargetURL: "http://127.0.0.1:1/hook", Sampling: policy}
+		}
+
+		_, err = clientService.Create("user", req(&models.SamplingPolicy{Mode: models.SamplingModeOneInN}))
+		Expect(err).To(HaveOccurred())
+		_, err = clientService.Create("user", req(&models.SamplingPolicy{Mode: models.SamplingModeFailures, Rate: 10}))
+		Expect(err).To(HaveOccurred())
+
+		client, err := clientService.Create("user", req(&models.SamplingPolicy{Mode: models.SamplingModeOneInN, Rate: 10}))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(client.Sampling).To(Equal(&models.SamplingPolicy{Mode: models.SamplingModeOneInN, Rate: 10}))
+	})
+})
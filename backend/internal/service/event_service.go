@@ -4,50 +4,385 @@
 package service
 
 import (
+	"archive/zip"
 	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"mime"
 	"net/http"
+	"net/url"
+	"sort"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
+	"github.com/google/uuid"
 	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
 	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/masking"
 	"github.com/lazycatapps/gosmee/backend/internal/repository"
 )
 
 // EventService manages webhook events.
 type EventService struct {
-	eventRepo  repository.EventRepository
-	clientRepo repository.ClientRepository
-	log        logger.Logger
+	eventRepo      repository.EventRepository
+	clientRepo     repository.ClientRepository
+	jobService     *JobService
+	circuitBreaker *CircuitBreakerService
+	masker         MaskerFunc                  // Redacts secrets from event details (optional)
+	scriptTimeout  time.Duration               // Run time limit of replay scripts (0 = script replay disabled)
+	responseSize   int                         // Target response bytes stored on delivered events (0 = not stored)
+	watcher        *WatcherService             // Announces new events to streams (optional)
+	secretRepo     repository.SecretRepository // Provides target credentials (optional)
+	targetTokens   *TargetTokenService         // Caches OAuth2 access tokens of targets
+	payloadLimiter *PayloadLimiter             // Limits the payload size of injected events (optional)
+	ingestion      *IngestionLimiter           // Limits the rate of injected events (optional)
+	deliveries     *DeliveryIndex              // Locates events by delivery ID (optional)
+	counters       *DeliveryCounters           // Counts deliveries per event type (optional)
+	retention      models.EventRetention       // How long ApplyRetention keeps events (zero = forever)
+	schemas        sync.Map                    // clientID -> *cachedPayloadSchemas
+	retries        *retrySchedule              // Due times of scheduled delivery retries
+	log            logger.Logger
+}
+
+// cachedPayloadSchemas are the payload schemas of a client compiled at a client version.
+type cachedPayloadSchemas struct {
+	version int
+	schemas *PayloadSchemas
 }
 
 // NewEventService creates a new event service.
 func NewEventService(
 	eventRepo repository.EventRepository,
 	clientRepo repository.ClientRepository,
+	jobService *JobService,
+	circuitBreaker *CircuitBreakerService,
 	log logger.Logger,
 ) *EventService {
 	return &EventService{
-		eventRepo:  eventRepo,
-		clientRepo: clientRepo,
-		log:        log,
+		eventRepo:      eventRepo,
+		clientRepo:     clientRepo,
+		jobService:     jobService,
+		circuitBreaker: circuitBreaker,
+		targetTokens:   NewTargetTokenService(log),
+		retries:        newRetrySchedule(),
+		log:            log,
 	}
 }
 
-// List retrieves events for a client with filters and pagination.
+// List retrieves events for a client with filters and pagination. Secrets in payload
+// previews are masked if masking is configured.
 func (s *EventService) List(clientID string, req *models.EventListRequest) (*models.EventListResponse, error) {
-	return s.eventRepo.GetByClientID(clientID, req)
+	response, err := s.eventRepo.GetByClientID(clientID, req)
+	if err != nil {
+		return nil, err
+	}
+	if req.MarkDuplicates {
+		if err := s.markDuplicates(clientID, response.Events); err != nil {
+			return nil, err
+		}
+	}
+	if s.masker == nil {
+		return response, nil
+	}
+
+	client, err := s.clientRepo.Get(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client: %w", err)
+	}
+	masker := s.masker(client.UserID)
+	for _, summary := range response.Events {
+		summary.Preview = masker.MaskText(summary.Preview)
+	}
+
+	return response, nil
+}
+
+// SetMasker sets how secrets are redacted from event details returned by Get.
+// Stored events are not modified, so replays still send the original headers.
+func (s *EventService) SetMasker(masker MaskerFunc) {
+	s.masker = masker
+}
+
+// SetScriptReplay enables replaying events by executing their stored replay scripts,
+// with the given run time limit per script. A zero timeout disables script replay.
+func (s *EventService) SetScriptReplay(timeout time.Duration) {
+	s.scriptTimeout = timeout
+}
+
+// SetResponseCapture stores up to maxSize bytes of the target's response body on events
+// delivered by the backend.
+func (s *EventService) SetResponseCapture(maxSize int) {
+	s.responseSize = maxSize
+}
+
+// SetWatcher enables streaming the events of running clients as gosmee writes them.
+func (s *EventService) SetWatcher(watcher *WatcherService) {
+	s.watcher = watcher
+}
+
+// SetSecretRepository enables sending the target credentials of clients with deliveries.
+func (s *EventService) SetSecretRepository(secretRepo repository.SecretRepository) {
+	s.secretRepo = secretRepo
+}
+
+// SetPayloadLimiter limits the stored payload size of injected events.
+func (s *EventService) SetPayloadLimiter(payloadLimiter *PayloadLimiter) {
+	s.payloadLimiter = payloadLimiter
+}
+
+// SetIngestionLimiter rejects injected events over the per-minute event limits.
+func (s *EventService) SetIngestionLimiter(ingestion *IngestionLimiter) {
+	s.ingestion = ingestion
 }
 
-// Get retrieves a single event.
+// SetDeliveryIndex indexes injected events by delivery ID and enables FindByDelivery.
+func (s *EventService) SetDeliveryIndex(deliveries *DeliveryIndex) {
+	s.deliveries = deliveries
+}
+
+// SetRetention sets how many days ApplyRetention keeps events, and failed events if
+// failedDays is not zero (0 = forever).
+func (s *EventService) SetRetention(days, failedDays int) {
+	s.retention = models.EventRetention{Days: days, FailedDays: failedDays}
+}
+
+// SetDeliveryCounters counts the outcome of every delivery attempt by event type for metrics.
+func (s *EventService) SetDeliveryCounters(counters *DeliveryCounters) {
+	s.counters = counters
+}
+
+// Subscribe returns a channel receiving the summaries of a client's new events while the
+// client is running, and a function ending the subscription.
+func (s *EventService) Subscribe(clientID string) (<-chan *models.EventSummary, func(), error) {
+	if s.watcher == nil {
+		return nil, nil, fmt.Errorf("event streaming is not enabled")
+	}
+
+	events, cancel := s.watcher.Subscribe(clientID)
+	return events, cancel, nil
+}
+
+// Get retrieves a single event, with secrets masked if masking is configured. The content
+// type of the payload is filled in, and form-encoded payloads are parsed for display.
 func (s *EventService) Get(clientID, eventID string) (*models.Event, error) {
-	return s.eventRepo.Get(clientID, eventID)
+	event, err := s.eventRepo.Get(clientID, eventID)
+	if err != nil {
+		return nil, err
+	}
+	client, err := s.clientRepo.Get(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client: %w", err)
+	}
+
+	// Events stored by gosmee carry no event type
+	if event.EventType == "" && event.PayloadEncoding == "" {
+		event.EventType = detectEventType(client, event.Headers, event.Payload)
+	}
+	if s.masker != nil {
+		event = maskEvent(s.masker(client.UserID), event)
+	}
+
+	event.ContentType = event.PayloadContentType()
+	if mediaType, _, err := mime.ParseMediaType(event.ContentType); err == nil && mediaType == models.ContentTypeForm {
+		if form, err := url.ParseQuery(event.Payload); err == nil {
+			event.Form = form
+		}
+	}
+
+	return event, nil
+}
+
+// Bundle packages an event for sharing with support: a zip archive with the event JSON,
+// the replay script gosmee generated for it (if any) and a README with the event metadata.
+// Secrets are masked if masking is configured.
+func (s *EventService) Bundle(clientID, eventID string) ([]byte, error) {
+	event, err := s.eventRepo.Get(clientID, eventID)
+	if err != nil {
+		return nil, apperrors.ErrEventNotFound
+	}
+
+	client, err := s.clientRepo.Get(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client: %w", err)
+	}
+
+	script, err := s.eventRepo.GetScript(clientID, eventID)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read replay script: %w", err)
+	}
+
+	masked := false
+	if s.masker != nil {
+		masker := s.masker(client.UserID)
+		event = maskEvent(masker, event)
+		script = []byte(masker.MaskText(string(script)))
+		masked = true
+	}
+
+	eventJSON, err := json.MarshalIndent(event, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	type bundleFile struct {
+		name string
+		data []byte
+		mode fs.FileMode
+	}
+	files := []bundleFile{
+		{"README.md", []byte(eventBundleReadme(client, event, len(script) > 0, masked)), 0644},
+		{eventID + ".json", eventJSON, 0644},
+	}
+	if len(script) > 0 {
+		files = append(files, bundleFile{eventID + ".sh", script, 0755})
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, file := range files {
+		header := &zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: event.Timestamp,
+		}
+		header.SetMode(file.mode)
+		writer, err := archive.CreateHeader(header)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create bundle: %w", err)
+		}
+		if _, err := writer.Write(file.data); err != nil {
+			return nil, fmt.Errorf("failed to create bundle: %w", err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("failed to create bundle: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// RegenerateScript regenerates the replay script of an event for the client's current
+// target URL, applying header overrides, and replaces the stored script unless req.DryRun
+// is set. The returned script has secrets masked if masking is configured.
+func (s *EventService) RegenerateScript(clientID, eventID string, req *models.EventScriptRequest) (*models.EventScriptResponse, error) {
+	event, err := s.eventRepo.Get(clientID, eventID)
+	if err != nil {
+		return nil, apperrors.ErrEventNotFound
+	}
+
+	client, err := s.clientRepo.Get(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client: %w", err)
+	}
+
+	format := req.Format
+	if format == "" {
+		format = models.ScriptFormatCurl
+		if client.HTTPie {
+			format = models.ScriptFormatHTTPie
+		}
+	}
+
+	headers := make(map[string]string, len(event.Headers))
+	for name, value := range event.Headers {
+		headers[name] = value
+	}
+	for name, value := range req.Headers {
+		// Overrides replace headers regardless of the case they were received in
+		for existing := range headers {
+			if strings.EqualFold(existing, name) {
+				delete(headers, existing)
+			}
+		}
+		if value != "" {
+			headers[name] = value
+		}
+	}
+
+	targetURL, err := resolveTargetURL(client, event)
+	if err != nil {
+		return nil, apperrors.NewInvalidInput(err.Error())
+	}
+	script := buildReplayScript(format, eventID, targetURL, headers, event.Payload, event.PayloadEncoding, time.Now())
+
+	response := &models.EventScriptResponse{
+		EventID:   eventID,
+		Format:    format,
+		TargetURL: targetURL,
+		Script:    script,
+	}
+	if !req.DryRun {
+		if err := s.eventRepo.SaveScript(clientID, eventID, []byte(script)); err != nil {
+			return nil, err
+		}
+		response.Saved = true
+		s.log.Info("Regenerated %s replay script of event %s (client: %s)", format, eventID, clientID)
+	}
+
+	if s.masker != nil {
+		response.Script = s.masker(client.UserID).MaskText(script)
+	}
+
+	return response, nil
+}
+
+// eventBundleReadme describes an event and the files of its bundle.
+func eventBundleReadme(client *models.Client, event *models.Event, hasScript, masked bool) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Webhook event %s\n\n", event.ID)
+	fmt.Fprintf(&b, "| Field | Value |\n|---|---|\n")
+	rows := [][2]string{
+		{"Client", fmt.Sprintf("%s (%s)", client.Name, client.ID)},
+		{"Target URL", client.TargetURL},
+		{"Received", event.Timestamp.UTC().Format(time.RFC3339)},
+		{"Event type", event.EventType},
+		{"Source", event.Source},
+		{"Status", string(event.Status)},
+		{"Target status code", fmt.Sprintf("%d", event.StatusCode)},
+		{"Latency", fmt.Sprintf("%d ms", event.LatencyMs)},
+		{"Automatic retries", fmt.Sprintf("%d", event.RetryAttempts)},
+	}
+	if event.ErrorMessage != "" {
+		rows = append(rows, [2]string{"Error", event.ErrorMessage})
+	}
+	for _, row := range rows {
+		fmt.Fprintf(&b, "| %s | %s |\n", row[0], strings.ReplaceAll(row[1], "|", "\\|"))
+	}
+
+	fmt.Fprintf(&b, "\n## Files\n\n")
+	fmt.Fprintf(&b, "- `%s.json`: the event as returned by the API (headers, payload, target response)\n", event.ID)
+	if hasScript {
+		fmt.Fprintf(&b, "- `%s.sh`: the replay script generated by gosmee (run it to resend the event)\n", event.ID)
+	}
+	if masked {
+		fmt.Fprintf(&b, "\nSecrets in headers, payload and script are replaced with `%s`; replaying from this bundle requires restoring them.\n", masking.Redacted)
+	}
+
+	return b.String()
 }
 
 // Delete deletes an event.
-func (s *EventService) Delete(clientID, eventID string) error {
+// If req.Version is set, the event is only deleted if it was not changed since that version
+// was read, e.g. by a replay from another browser tab.
+func (s *EventService) Delete(clientID, eventID string, req *models.EventDeleteRequest) error {
+	if req.Version > 0 {
+		event, err := s.eventRepo.Get(clientID, eventID)
+		if err != nil {
+			return apperrors.ErrEventNotFound
+		}
+		if event.Version != req.Version {
+			return apperrors.ErrEventConflict.WithDetails(map[string]int{"version": event.Version})
+		}
+	}
+
 	if err := s.eventRepo.Delete(clientID, eventID); err != nil {
 		return fmt.Errorf("failed to delete event: %w", err)
 	}
@@ -58,20 +393,42 @@ func (s *EventService) Delete(clientID, eventID string) error {
 
 // Replay replays events to the target URL.
 func (s *EventService) Replay(clientID string, req *models.EventReplayRequest) (*models.EventReplayResponse, error) {
+	if req.Mode == models.ReplayModeScript && s.scriptTimeout <= 0 {
+		return nil, apperrors.NewInvalidInput("script replay is disabled on this server")
+	}
+
+	if (len(req.EventIDs) > 0) == (req.Filter != nil) {
+		return nil, apperrors.NewInvalidInput("specify either eventIds or filter")
+	}
+
 	// Get client to get target URL
 	client, err := s.clientRepo.Get(clientID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get client: %w", err)
 	}
+	if client.Paused {
+		return nil, apperrors.ErrClientPaused
+	}
+
+	eventIDs := req.EventIDs
+	if req.Filter != nil {
+		if eventIDs, err = s.collectEventIDs(clientID, req.Filter.ListRequest()); err != nil {
+			return nil, fmt.Errorf("failed to select events: %w", err)
+		}
+		if len(eventIDs) > models.MaxReplayFilterEvents {
+			return nil, apperrors.NewInvalidInput(fmt.Sprintf("filter matches %d events, at most %d can be replayed at once: narrow the filter",
+				len(eventIDs), models.MaxReplayFilterEvents))
+		}
+	}
 
 	response := &models.EventReplayResponse{
-		Total:   len(req.EventIDs),
-		Results: make([]*models.EventReplayResult, 0, len(req.EventIDs)),
+		Total:   len(eventIDs),
+		Results: make([]*models.EventReplayResult, 0, len(eventIDs)),
 	}
 
 	// Replay each event
-	for _, eventID := range req.EventIDs {
-		result := s.replayEvent(client, eventID)
+	for _, eventID := range eventIDs {
+		result := s.replayEvent(client, eventID, req.Mode)
 		response.Results = append(response.Results, result)
 
 		if result.Success {
@@ -87,35 +444,709 @@ func (s *EventService) Replay(clientID string, req *models.EventReplayRequest) (
 	return response, nil
 }
 
+// ReplayFailed replays all failed events of a client within a date range as an asynchronous job.
+// Each successful replay updates the stored event status so recovered events are not replayed twice.
+func (s *EventService) ReplayFailed(userID, clientID string, req *models.EventReplayFailedRequest) (*models.Job, error) {
+	client, err := s.clientRepo.Get(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client: %w", err)
+	}
+	if client.Paused {
+		return nil, apperrors.ErrClientPaused
+	}
+
+	eventIDs, err := s.collectEventIDs(clientID, &models.EventListRequest{
+		Status:    string(models.EventStatusFailed),
+		DateFrom:  req.DateFrom,
+		DateTo:    req.DateTo,
+		SortBy:    "timestamp",
+		SortOrder: "asc",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to select failed events: %w", err)
+	}
+
+	job := s.jobService.Submit(userID, "replay-failed", len(eventIDs), func(progress JobProgressFunc) (interface{}, error) {
+		response := &models.EventReplayResponse{
+			Total:   len(eventIDs),
+			Results: make([]*models.EventReplayResult, 0, len(eventIDs)),
+		}
+
+		for i, eventID := range eventIDs {
+			result := s.replayAndRecord(client, eventID)
+			response.Results = append(response.Results, result)
+			if result.Success {
+				response.Successful++
+			} else {
+				response.Failed++
+			}
+			progress(i+1, len(eventIDs))
+		}
+
+		s.log.Info("Replayed %d failed events for client %s (%d successful, %d failed)",
+			response.Total, clientID, response.Successful, response.Failed)
+
+		return response, nil
+	})
+
+	return job, nil
+}
+
+// FindByDelivery locates the events of a user's clients with the delivery ID a webhook
+// provider sent, oldest first.
+func (s *EventService) FindByDelivery(userID, deliveryID string) (*models.EventDeliveryLookupResponse, error) {
+	if s.deliveries == nil {
+		return nil, apperrors.ErrEventNotFound
+	}
+
+	clients, err := s.clientRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clients: %w", err)
+	}
+	clientIDs := make([]string, len(clients))
+	clientNames := make(map[string]string, len(clients))
+	for i, client := range clients {
+		clientIDs[i] = client.ID
+		clientNames[client.ID] = client.Name
+	}
+
+	refs, err := s.deliveries.find(clientIDs, deliveryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to index events: %w", err)
+	}
+
+	response := &models.EventDeliveryLookupResponse{DeliveryID: deliveryID, Matches: []*models.EventDeliveryMatch{}}
+	for _, ref := range refs {
+		event, err := s.eventRepo.Get(ref.clientID, ref.eventID)
+		if err != nil {
+			s.deliveries.Remove(ref.clientID, ref.eventID, deliveryID) // Deleted since it was indexed
+			continue
+		}
+		summary := event.ToSummary()
+		if s.masker != nil {
+			summary.Preview = s.masker(userID).MaskText(summary.Preview)
+		}
+		response.Matches = append(response.Matches, &models.EventDeliveryMatch{
+			ClientID:   ref.clientID,
+			ClientName: clientNames[ref.clientID],
+			Event:      summary,
+		})
+	}
+	if len(response.Matches) == 0 {
+		return nil, apperrors.ErrEventNotFound
+	}
+
+	sort.Slice(response.Matches, func(i, j int) bool {
+		return response.Matches[i].Event.Timestamp.Before(response.Matches[j].Event.Timestamp)
+	})
+	return response, nil
+}
+
+// markDuplicates sets duplicateOf on summaries of events received before with the same
+// fingerprint, comparing against all events of the client.
+func (s *EventService) markDuplicates(clientID string, summaries []*models.EventSummary) error {
+	all, err := collectEventSummaries(s.eventRepo, clientID, &models.EventListRequest{SortBy: "timestamp", SortOrder: "asc"})
+	if err != nil {
+		return fmt.Errorf("failed to read events: %w", err)
+	}
+
+	originals := make(map[string]string)
+	for _, summary := range all {
+		if _, exists := originals[summary.Fingerprint]; !exists {
+			originals[summary.Fingerprint] = summary.ID
+		}
+	}
+	for _, summary := range summaries {
+		if original := originals[summary.Fingerprint]; original != summary.ID {
+			summary.DuplicateOf = original
+		}
+	}
+	return nil
+}
+
+// Deduplicate deletes redelivered events within a date range, keeping the earliest event
+// of each fingerprint.
+func (s *EventService) Deduplicate(clientID string, req *models.EventDeduplicateRequest) (*models.EventDeduplicateResponse, error) {
+	if _, err := s.clientRepo.Get(clientID); err != nil {
+		return nil, fmt.Errorf("failed to get client: %w", err)
+	}
+
+	summaries, err := collectEventSummaries(s.eventRepo, clientID, &models.EventListRequest{
+		DateFrom:  req.DateFrom,
+		DateTo:    req.DateTo,
+		SortBy:    "timestamp",
+		SortOrder: "asc",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read events: %w", err)
+	}
+
+	response := &models.EventDeduplicateResponse{EventIDs: []string{}, DryRun: req.DryRun}
+	counts := make(map[string]int)
+	for _, summary := range summaries {
+		counts[summary.Fingerprint]++
+		switch counts[summary.Fingerprint] {
+		case 1:
+			continue
+		case 2:
+			response.Groups++
+		}
+		response.EventIDs = append(response.EventIDs, summary.ID)
+	}
+	if req.DryRun || len(response.EventIDs) == 0 {
+		return response, nil
+	}
+
+	if err := s.eventRepo.DeleteBatch(clientID, response.EventIDs); err != nil {
+		return nil, fmt.Errorf("failed to delete duplicate events: %w", err)
+	}
+	response.Deleted = len(response.EventIDs)
+
+	s.log.Info("Deleted %d duplicate events of %d events for client %s", response.Deleted, response.Groups, clientID)
+	return response, nil
+}
+
+// RetryFailedDeliveries retries failed deliveries for every client with an enabled retry policy.
+// It is executed periodically by the scheduler.
+func (s *EventService) RetryFailedDeliveries() {
+	clients, err := s.clientRepo.ListAll()
+	if err != nil {
+		s.log.Error("Failed to list clients for delivery retry: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for _, client := range clients {
+		if client.RetryPolicy == nil || !client.RetryPolicy.Enabled || client.NoReplay || client.Paused {
+			continue
+		}
+		s.retryClientDeliveries(client, now)
+	}
+}
+
+// retryClientDeliveries retries the due failed deliveries of a single client.
+func (s *EventService) retryClientDeliveries(client *models.Client, now time.Time) {
+	policy := *client.RetryPolicy
+	policy.Normalize()
+
+	eventIDs, err := s.retryCandidates(client.ID, &policy, now)
+	if err != nil {
+		s.log.Error("Failed to select events to retry for client %s: %v", client.ID, err)
+		return
+	}
+
+	for _, eventID := range eventIDs {
+		if s.circuitBreaker.Blocked(client.ID) {
+			return
+		}
+		s.retryEvent(client, &policy, eventID, now)
+	}
+}
+
+// retryCandidates returns the IDs of the events of a client that may be due for a retry,
+// without reading every stored event. Failed events are only retried while their retry
+// schedule lasts, so only the date directories of that period are listed; events waiting
+// for a later retry come from the retry schedule.
+func (s *EventService) retryCandidates(clientID string, policy *models.RetryPolicy, now time.Time) ([]string, error) {
+	if !s.retries.isLoaded(clientID) {
+		retrying, err := s.collectEventIDs(clientID, &models.EventListRequest{Status: string(models.EventStatusRetrying)})
+		if err != nil {
+			return nil, err
+		}
+		s.retries.load(clientID, retrying)
+	}
+
+	recent, err := collectEventSummaries(s.eventRepo, clientID, &models.EventListRequest{
+		DateFrom:  now.Add(-policy.Schedule()),
+		SortBy:    "timestamp",
+		SortOrder: "asc",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	eventIDs := s.retries.dueAt(clientID, now)
+	scheduled := make(map[string]bool, len(eventIDs))
+	for _, eventID := range eventIDs {
+		scheduled[eventID] = true
+	}
+	for _, summary := range recent {
+		if summary.Status == models.EventStatusFailed && !scheduled[summary.ID] {
+			eventIDs = append(eventIDs, summary.ID)
+		}
+	}
+	return eventIDs, nil
+}
+
+// retryEvent performs one scheduled retry of an event if it is due, and records the outcome.
+// The retry schedule is anchored at the event timestamp; events whose whole schedule
+// already elapsed (e.g. failures from before the policy was enabled) are left untouched.
+func (s *EventService) retryEvent(client *models.Client, policy *models.RetryPolicy, eventID string, now time.Time) {
+	event, err := s.eventRepo.Get(client.ID, eventID)
+	if err != nil {
+		s.retries.remove(client.ID, eventID)
+		if !errors.Is(err, fs.ErrNotExist) {
+			s.log.Error("Failed to load event %s for retry: %v", eventID, err)
+		}
+		return
+	}
+
+	if (event.Status != models.EventStatusFailed && event.Status != models.EventStatusRetrying) ||
+		event.RetryAttempts >= policy.MaxAttempts || event.PayloadTruncated {
+		s.retries.remove(client.ID, eventID)
+		return
+	}
+
+	dueAt := event.NextRetryAt
+	if dueAt == nil {
+		if now.Sub(event.Timestamp) > policy.Schedule() {
+			s.retries.remove(client.ID, eventID)
+			return
+		}
+		first := event.Timestamp.Add(policy.Backoff(1))
+		dueAt = &first
+	}
+
+	if now.Before(*dueAt) {
+		if event.Status != models.EventStatusRetrying {
+			event.Status = models.EventStatusRetrying
+			event.NextRetryAt = dueAt
+			if err := s.eventRepo.Save(client.ID, event); err != nil {
+				s.log.Error("Failed to schedule retry for event %s: %v", eventID, err)
+				return
+			}
+		}
+		s.retries.schedule(client.ID, eventID, *dueAt)
+		return
+	}
+
+	result := s.deliverEvent(client, event)
+	if result.CircuitOpen || result.Paused {
+		return
+	}
+	event, err = s.updateEvent(client.ID, event, func(event *models.Event) {
+		applyDeliveryResult(event, result)
+		event.RetryAttempts++
+		event.NextRetryAt = nil
+
+		switch {
+		case result.Success:
+		case event.RetryAttempts < policy.MaxAttempts:
+			next := now.Add(policy.Backoff(event.RetryAttempts + 1))
+			event.Status = models.EventStatusRetrying
+			event.NextRetryAt = &next
+		default:
+			event.ErrorMessage = fmt.Sprintf("%s (gave up after %d retries)", event.ErrorMessage, event.RetryAttempts)
+		}
+	})
+	if err != nil {
+		s.log.Error("Failed to record retry result for event %s: %v", eventID, err)
+		return
+	}
+	if event.NextRetryAt != nil {
+		s.retries.schedule(client.ID, eventID, *event.NextRetryAt)
+	} else {
+		s.retries.remove(client.ID, eventID)
+	}
+
+	switch {
+	case result.Success:
+		s.log.Info("Retry %d/%d of event %s succeeded", event.RetryAttempts, policy.MaxAttempts, eventID)
+	case event.RetryAttempts >= policy.MaxAttempts:
+		s.log.Info("Giving up on event %s after %d retries", eventID, event.RetryAttempts)
+	}
+}
+
+// ForwardNewEvents forwards the new events of a client, which gosmee and embedded clients
+// only save, and records the delivery outcomes. It is called by the watcher as events
+// arrive; paused and save-only clients are skipped, so pausing doesn't touch the event stream.
+func (s *EventService) ForwardNewEvents(clientID string, eventIDs []string) {
+	client, err := s.clientRepo.Get(clientID)
+	if err != nil {
+		s.log.Error("Failed to get client %s for forwarding: %v", clientID, err)
+		return
+	}
+	if client.NoReplay || client.Paused {
+		return
+	}
+
+	failed := 0
+	for _, eventID := range eventIDs {
+		if result := s.replayAndRecord(client, eventID); !result.Success {
+			failed++
+		}
+	}
+	s.log.Info("Forwarded %d new events for client %s (%d failed)", len(eventIDs), clientID, failed)
+}
+
+// replayAndRecord replays a stored event and writes the delivery outcome back to it.
+func (s *EventService) replayAndRecord(client *models.Client, eventID string) *models.EventReplayResult {
+	event, err := s.eventRepo.Get(client.ID, eventID)
+	if err != nil {
+		return &models.EventReplayResult{
+			EventID:      eventID,
+			ErrorMessage: fmt.Sprintf("failed to get event: %v", err),
+		}
+	}
+
+	if schemas := s.payloadSchemas(client); schemas != nil {
+		schemas.Check(event)
+	}
+	status, messages := event.SchemaStatus, event.SchemaErrors
+
+	result := s.deliverEvent(client, event)
+	result.SchemaStatus, result.SchemaErrors = status, messages
+	if result.CircuitOpen || result.Paused {
+		return result
+	}
+	if _, err := s.updateEvent(client.ID, event, func(event *models.Event) {
+		applyDeliveryResult(event, result)
+		event.SchemaStatus, event.SchemaErrors = status, messages
+	}); err != nil {
+		s.log.Error("Failed to record replay result for event %s: %v", eventID, err)
+	}
+
+	return result
+}
+
+// updateEventAttempts is the number of times updateEvent applies an update to a stored event.
+const updateEventAttempts = 3
+
+// updateEvent applies update to an event read from the repository and saves it. If the event
+// was changed concurrently (e.g. replayed from another browser tab), update is applied again
+// to the current version instead of overwriting it; if it was deleted, it is not recreated.
+// It returns the saved event.
+func (s *EventService) updateEvent(clientID string, event *models.Event, update func(*models.Event)) (*models.Event, error) {
+	for attempt := 1; ; attempt++ {
+		update(event)
+		err := s.eventRepo.Save(clientID, event)
+		if !errors.Is(err, repository.ErrEventConflict) || attempt == updateEventAttempts {
+			return event, err
+		}
+
+		current, getErr := s.eventRepo.Get(clientID, event.ID)
+		if getErr != nil {
+			return event, err
+		}
+		s.log.Debug("Event %s changed concurrently, updating version %d", event.ID, current.Version)
+		event = current
+	}
+}
+
+// collectEventIDs returns the IDs of all events matching the filter, walking every result page.
+func (s *EventService) collectEventIDs(clientID string, filter *models.EventListRequest) ([]string, error) {
+	summaries, err := collectEventSummaries(s.eventRepo, clientID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(summaries))
+	for i, summary := range summaries {
+		ids[i] = summary.ID
+	}
+	return ids, nil
+}
+
+// collectEventSummaries returns the summaries of all events matching the filter, walking
+// every result page.
+func collectEventSummaries(eventRepo repository.EventRepository, clientID string, filter *models.EventListRequest) ([]*models.EventSummary, error) {
+	const pageSize = 500
+
+	req := *filter
+	req.PageSize = pageSize
+
+	var summaries []*models.EventSummary
+	for page := 1; ; page++ {
+		req.Page = page
+		response, err := eventRepo.GetByClientID(clientID, &req)
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, response.Events...)
+		if len(response.Events) < pageSize || len(summaries) >= response.Total {
+			return summaries, nil
+		}
+	}
+}
+
+// AckReport reconciles delivery receipts: it reports the events delivered successfully
+// within a date range and lists those the target never acknowledged with a delivery token.
+func (s *EventService) AckReport(clientID string, req *models.EventAckReportRequest) (*models.EventAckReport, error) {
+	client, err := s.clientRepo.Get(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client: %w", err)
+	}
+	if client.Ack == nil {
+		return nil, apperrors.NewInvalidInput("delivery receipts are not enabled for this client")
+	}
+
+	delivered, err := collectEventSummaries(s.eventRepo, clientID, &models.EventListRequest{
+		Status:    string(models.EventStatusSuccess),
+		DateFrom:  req.DateFrom,
+		DateTo:    req.DateTo,
+		SortBy:    "timestamp",
+		SortOrder: "asc",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list delivered events: %w", err)
+	}
+
+	report := &models.EventAckReport{
+		Delivered:      len(delivered),
+		Unacknowledged: make([]*models.EventSummary, 0),
+	}
+	for _, summary := range delivered {
+		if summary.Acked {
+			report.Acknowledged++
+		} else {
+			report.Unacknowledged = append(report.Unacknowledged, summary)
+		}
+	}
+
+	return report, nil
+}
+
+// Inject stores a synthetic event for a client and optionally forwards it to the target URL.
+func (s *EventService) Inject(clientID string, req *models.EventInjectRequest) (*models.EventInjectResponse, error) {
+	client, err := s.clientRepo.Get(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client: %w", err)
+	}
+
+	event := &models.Event{
+		ID:        uuid.New().String(),
+		ClientID:  clientID,
+		Timestamp: time.Now().UTC(),
+		EventType: req.EventType,
+		Source:    req.Source,
+		Status:    models.EventStatusNotReplayed,
+		Headers:   req.Headers,
+		Payload:   req.PayloadString(),
+	}
+	if req.PayloadEncoding == models.PayloadEncodingBase64 {
+		data, err := base64.StdEncoding.DecodeString(event.Payload)
+		if err != nil {
+			return nil, apperrors.NewInvalidInput("payload is not valid base64")
+		}
+		event.SetPayloadBytes(data)
+	}
+
+	// The redacted payload is stored, the original one is forwarded
+	payload, err := s.prepareReceived(client, event)
+	if err != nil {
+		return nil, err
+	}
+	if s.ingestion != nil {
+		if err := s.ingestion.Limit(clientID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.eventRepo.Save(clientID, event); err != nil {
+		return nil, fmt.Errorf("failed to save event: %w", err)
+	}
+	if s.deliveries != nil {
+		s.deliveries.Add(clientID, event.ID, event.DeliveryID)
+	}
+
+	s.log.Info("Injected event %s for client %s (type: %s)", event.ID, clientID, event.EventType)
+
+	response := &models.EventInjectResponse{Event: event}
+	if !req.Forward {
+		return response, nil
+	}
+
+	forwarded := *event
+	forwarded.Payload = payload
+	forwarded.PayloadTruncated = false
+	result := s.deliverEvent(client, &forwarded)
+	response.Forward = result
+	if result.CircuitOpen || result.Paused {
+		return response, nil
+	}
+	saved, err := s.updateEvent(clientID, event, func(event *models.Event) { applyDeliveryResult(event, result) })
+	if err != nil {
+		s.log.Error("Failed to record forward result for event %s: %v", event.ID, err)
+	}
+	response.Event = saved
+
+	return response, nil
+}
+
+// ImportEvent saves an event imported from a client bundle. Like injected events, it is checked
+// against the client's payload schemas and its payload is redacted and limited in size before it
+// is stored; payloads the limit rejects fail with a request too large error.
+func (s *EventService) ImportEvent(client *models.Client, event *models.Event) error {
+	if _, err := s.prepareReceived(client, event); err != nil {
+		return err
+	}
+	if err := s.eventRepo.Save(client.ID, event); err != nil {
+		return err
+	}
+	if s.deliveries != nil {
+		s.deliveries.Add(client.ID, event.ID, event.DeliveryID)
+	}
+	return nil
+}
+
+// prepareReceived prepares an event the backend received itself, rather than gosmee, to be
+// stored: the payload is validated as received, then redacted and limited in size. It returns
+// the payload as received.
+func (s *EventService) prepareReceived(client *models.Client, event *models.Event) (string, error) {
+	if schemas := s.payloadSchemas(client); schemas != nil {
+		schemas.Check(event)
+	}
+
+	payload := event.Payload
+	if event.PayloadEncoding == "" {
+		event.Payload = RedactPayload(client, payload)
+	}
+	if event.EventType == "" {
+		event.EventType = detectEventType(client, event.Headers, payload)
+	}
+	event.ResolveDeliveryID()
+	if s.payloadLimiter != nil {
+		if err := s.payloadLimiter.Limit(event); err != nil {
+			return "", err
+		}
+	}
+	return payload, nil
+}
+
 // replayEvent replays a single event.
-func (s *EventService) replayEvent(client *models.Client, eventID string) *models.EventReplayResult {
+func (s *EventService) replayEvent(client *models.Client, eventID, mode string) *models.EventReplayResult {
+	// Get event
+	event, err := s.eventRepo.Get(client.ID, eventID)
+	if err != nil {
+		return &models.EventReplayResult{
+			EventID:      eventID,
+			Success:      false,
+			ErrorMessage: fmt.Sprintf("failed to get event: %v", err),
+		}
+	}
+
+	if schemas := s.payloadSchemas(client); schemas != nil && schemas.Check(event) {
+		status, messages := event.SchemaStatus, event.SchemaErrors
+		saved, err := s.updateEvent(client.ID, event, func(event *models.Event) {
+			event.SchemaStatus, event.SchemaErrors = status, messages
+		})
+		if err != nil {
+			s.log.Error("Failed to record schema validation of event %s: %v", eventID, err)
+		}
+		event = saved
+	}
+
+	if len(client.RedactionRules) > 0 {
+		event = withoutSignatures(client, event)
+	}
+	var result *models.EventReplayResult
+	if mode == models.ReplayModeScript {
+		result = s.deliverEventWith(client, event, s.runEventScript)
+	} else {
+		result = s.deliverEvent(client, event)
+	}
+	result.SchemaStatus = event.SchemaStatus
+	result.SchemaErrors = event.SchemaErrors
+	return result
+}
+
+// payloadSchemas returns the compiled payload schemas of a client, nil if it has none.
+func (s *EventService) payloadSchemas(client *models.Client) *PayloadSchemas {
+	if client.PayloadSchema == nil {
+		return nil
+	}
+	if cached, ok := s.schemas.Load(client.ID); ok && cached.(*cachedPayloadSchemas).version == client.Version {
+		return cached.(*cachedPayloadSchemas).schemas
+	}
+
+	schemas, err := CompilePayloadSchemas(client.PayloadSchema)
+	if err != nil {
+		s.log.Error("Failed to compile payload schemas of client %s: %v", client.ID, err)
+		return nil
+	}
+	s.schemas.Store(client.ID, &cachedPayloadSchemas{version: client.Version, schemas: schemas})
+	return schemas
+}
+
+// deliverEvent sends an event to the client's target URL through the client's circuit breaker.
+// Deliveries rejected by an open circuit are reported with CircuitOpen set and are not attempted,
+// like those of paused clients with Paused set.
+func (s *EventService) deliverEvent(client *models.Client, event *models.Event) *models.EventReplayResult {
+	return s.deliverEventWith(client, event, s.sendEvent)
+}
+
+// deliverEventWith delivers an event with send through the client's circuit breaker.
+func (s *EventService) deliverEventWith(client *models.Client, event *models.Event, send func(*models.Client, *models.Event) *models.EventReplayResult) *models.EventReplayResult {
+	if event.PayloadTruncated {
+		return &models.EventReplayResult{
+			EventID:      event.ID,
+			ErrorMessage: fmt.Sprintf("payload was truncated from %d bytes when stored and cannot be replayed", event.OriginalPayloadSize),
+		}
+	}
+	if client.Paused {
+		return &models.EventReplayResult{
+			EventID:      event.ID,
+			Paused:       true,
+			ErrorMessage: "forwarding of the client is paused",
+		}
+	}
+	if !s.circuitBreaker.Allow(client.ID) {
+		return &models.EventReplayResult{
+			EventID:      event.ID,
+			CircuitOpen:  true,
+			ErrorMessage: "circuit open: deliveries to the target are paused after repeated failures",
+		}
+	}
+
+	result := send(client, event)
+	result.Comparison = compareReplay(event, result, s.responseSize > 0)
+	if result.Success {
+		s.circuitBreaker.RecordSuccess(client)
+	} else {
+		s.circuitBreaker.RecordFailure(client, result.ErrorMessage)
+	}
+	if s.counters != nil {
+		s.counters.Delivered(client.ID, event.EventType, result.Success)
+	}
+
+	return result
+}
+
+// sendEvent sends an event to the client's target URL and reports the outcome.
+func (s *EventService) sendEvent(client *models.Client, event *models.Event) *models.EventReplayResult {
+	eventID := event.ID
 	result := &models.EventReplayResult{
 		EventID: eventID,
 	}
 
-	// Get event
-	event, err := s.eventRepo.Get(client.ID, eventID)
+	payload, err := event.PayloadBytes()
 	if err != nil {
 		result.Success = false
-		result.ErrorMessage = fmt.Sprintf("failed to get event: %v", err)
+		result.ErrorMessage = err.Error()
 		return result
 	}
 
 	// Log payload for debugging
-	s.log.Info("Replaying event %s: payload length=%d bytes", eventID, len(event.Payload))
-	if len(event.Payload) < 500 {
+	s.log.Info("Replaying event %s: payload length=%d bytes", eventID, len(payload))
+	if len(payload) < 500 && event.PayloadEncoding == "" {
 		s.log.Debug("Payload content: %s", event.Payload)
 	}
 
 	// Prepare HTTP request
-	req, err := http.NewRequest("POST", client.TargetURL, bytes.NewBufferString(event.Payload))
+	targetURL, err := resolveTargetURL(client, event)
+	if err != nil {
+		result.Success = false
+		result.ErrorMessage = err.Error()
+		return result
+	}
+	req, err := http.NewRequest("POST", targetURL, bytes.NewReader(payload))
 	if err != nil {
 		result.Success = false
 		result.ErrorMessage = fmt.Sprintf("failed to create request: %v", err)
 		return result
 	}
 
-	// Set default Content-Type if not present in original headers
+	// Set the detected Content-Type if not present in original headers
 	hasContentType := false
 	for key := range event.Headers {
 		if strings.EqualFold(key, "Content-Type") {
@@ -124,8 +1155,9 @@ func (s *EventService) replayEvent(client *models.Client, eventID string) *model
 		}
 	}
 	if !hasContentType {
-		req.Header.Set("Content-Type", "application/json")
-		s.log.Debug("Set default Content-Type: application/json")
+		contentType := event.PayloadContentType()
+		req.Header.Set("Content-Type", contentType)
+		s.log.Debug("Set detected Content-Type: %s", contentType)
 	}
 
 	// Copy headers from original event
@@ -141,12 +1173,19 @@ func (s *EventService) replayEvent(client *models.Client, eventID string) *model
 		s.log.Debug("  %s: %s", key, strings.Join(values, ", "))
 	}
 
+	// Target credentials replace an Authorization header of the original event
+	if err := s.setTargetAuth(client, req); err != nil {
+		result.Success = false
+		result.ErrorMessage = err.Error()
+		return result
+	}
+
 	// Send request
 	httpClient := &http.Client{
 		Timeout: time.Duration(client.TargetTimeout) * time.Second,
 	}
 
-	s.log.Info("Sending replay request to %s", client.TargetURL)
+	s.log.Info("Sending replay request to %s", targetURL)
 	startTime := time.Now()
 	resp, err := httpClient.Do(req)
 	latency := time.Since(startTime)
@@ -159,12 +1198,20 @@ func (s *EventService) replayEvent(client *models.Client, eventID string) *model
 	}
 	defer resp.Body.Close()
 
+	// A rejected access token may have been revoked before it expired
+	if resp.StatusCode == http.StatusUnauthorized && client.TargetAuth != nil && client.TargetAuth.Type == models.TargetAuthOAuth2 {
+		s.targetTokens.Invalidate(client.ID)
+	}
+
 	// Read response
 	body, _ := io.ReadAll(resp.Body)
 
 	result.Success = resp.StatusCode >= 200 && resp.StatusCode < 300
 	result.StatusCode = resp.StatusCode
 	result.LatencyMs = int(latency.Milliseconds())
+	if client.Ack != nil && result.Success {
+		result.AckToken = resp.Header.Get(client.Ack.Header)
+	}
 
 	s.log.Info("Replay response: status=%d, latency=%dms, body_length=%d bytes",
 		resp.StatusCode, result.LatencyMs, len(body))
@@ -175,16 +1222,190 @@ func (s *EventService) replayEvent(client *models.Client, eventID string) *model
 	if !result.Success {
 		result.ErrorMessage = fmt.Sprintf("HTTP %d: %s", resp.StatusCode, string(body))
 	}
+	result.Response, result.ResponseTruncated = captureResponse(body, s.responseSize)
+
+	return result
+}
+
+// captureResponse returns the beginning of a target response body to store on the event,
+// at most maxSize bytes without splitting a UTF-8 character. Binary bodies are described by
+// their size.
+func captureResponse(body []byte, maxSize int) (string, bool) {
+	if maxSize <= 0 || len(body) == 0 {
+		return "", false
+	}
+	if !utf8.Valid(body) {
+		return fmt.Sprintf("binary response (%d bytes)", len(body)), false
+	}
+	if len(body) <= maxSize {
+		return string(body), false
+	}
+
+	cut := maxSize
+	for cut > 0 && !utf8.RuneStart(body[cut]) {
+		cut--
+	}
+	return string(body[:cut]), true
+}
+
+// runEventScript replays an event by executing a cURL replay script against the client's
+// current target URL, which sends the stored headers and payload byte for byte. The script
+// is generated from the event: scripts stored next to events (written by gosmee, or imported)
+// are never executed.
+func (s *EventService) runEventScript(client *models.Client, event *models.Event) *models.EventReplayResult {
+	targetURL, err := s.scriptTargetURL(client, event)
+	if err != nil {
+		return &models.EventReplayResult{EventID: event.ID, ErrorMessage: err.Error()}
+	}
+	script := buildReplayScript(models.ScriptFormatCurl, event.ID, targetURL, event.Headers, event.Payload, event.PayloadEncoding, time.Now())
+
+	s.log.Info("Replaying event %s by script to %s", event.ID, client.TargetURL)
+	result := runReplayScript([]byte(script), event.ID, targetURL, s.scriptTimeout)
+	s.log.Info("Script replay result: success=%v, status=%d, latency=%dms", result.Success, result.StatusCode, result.LatencyMs)
 
 	return result
 }
 
-// CleanupOldEvents removes events older than retention period.
-func (s *EventService) CleanupOldEvents(clientID string, retentionDays int) error {
-	if err := s.eventRepo.CleanupOldEvents(clientID, retentionDays); err != nil {
+// setTargetAuth adds the target credentials of a client to a delivery request.
+func (s *EventService) setTargetAuth(client *models.Client, req *http.Request) error {
+	if client.TargetAuth == nil {
+		return nil
+	}
+	secrets, err := s.targetSecrets(client)
+	if err != nil {
+		return err
+	}
+
+	switch client.TargetAuth.Type {
+	case models.TargetAuthBasic:
+		req.SetBasicAuth(client.TargetAuth.Username, secrets.TargetPassword)
+	case models.TargetAuthBearer:
+		req.Header.Set("Authorization", "Bearer "+secrets.TargetToken)
+	case models.TargetAuthOAuth2:
+		token, err := s.targetTokens.Token(client, secrets.TargetClientSecret)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return nil
+}
+
+// scriptTargetURL returns the target URL of an event passed to replay scripts. Basic
+// credentials are embedded in the URL, which curl and HTTPie send as an Authorization
+// header; scripts cannot send bearer tokens.
+func (s *EventService) scriptTargetURL(client *models.Client, event *models.Event) (string, error) {
+	targetURL, err := resolveTargetURL(client, event)
+	if err != nil || client.TargetAuth == nil {
+		return targetURL, err
+	}
+	if client.TargetAuth.Type != models.TargetAuthBasic {
+		return "", fmt.Errorf("script replay cannot send %s credentials, replay over HTTP instead", client.TargetAuth.Type)
+	}
+	secrets, err := s.targetSecrets(client)
+	if err != nil {
+		return "", err
+	}
+
+	target, err := url.Parse(targetURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid target URL: %w", err)
+	}
+	target.User = url.UserPassword(client.TargetAuth.Username, secrets.TargetPassword)
+	return target.String(), nil
+}
+
+// targetSecrets returns the stored secrets of a client with target credentials.
+func (s *EventService) targetSecrets(client *models.Client) (*models.ClientSecrets, error) {
+	if s.secretRepo == nil {
+		return nil, fmt.Errorf("target credentials are not available on this server")
+	}
+	secrets, err := s.secretRepo.Get(client.UserID, client.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get target credentials: %w", err)
+	}
+	return secrets, nil
+}
+
+// applyDeliveryResult records the outcome of a delivery on the event.
+func applyDeliveryResult(event *models.Event, result *models.EventReplayResult) {
+	event.StatusCode = result.StatusCode
+	event.LatencyMs = result.LatencyMs
+	event.ErrorMessage = result.ErrorMessage
+	event.Response = result.Response
+	event.ResponseTruncated = result.ResponseTruncated
+	if result.Success {
+		event.Status = models.EventStatusSuccess
+	} else {
+		event.Status = models.EventStatusFailed
+	}
+	if result.AckToken != "" {
+		now := time.Now().UTC()
+		event.AckToken = result.AckToken
+		event.AckedAt = &now
+	}
+}
+
+// maskEvent returns a copy of event with secrets in headers, payload, response and error redacted.
+func maskEvent(masker *masking.Masker, event *models.Event) *models.Event {
+	masked := *event
+	masked.Headers = masker.MaskHeaders(event.Headers)
+	masked.Payload = masker.MaskText(event.Payload)
+	masked.Response = masker.MaskText(event.Response)
+	masked.ErrorMessage = masker.MaskText(event.ErrorMessage)
+	return &masked
+}
+
+// CircuitStatus returns the delivery circuit breaker status of a client.
+func (s *EventService) CircuitStatus(clientID string) (*models.CircuitStatus, error) {
+	if _, err := s.clientRepo.Get(clientID); err != nil {
+		return nil, fmt.Errorf("failed to get client: %w", err)
+	}
+
+	return s.circuitBreaker.Status(clientID), nil
+}
+
+// ResetCircuit closes the delivery circuit of a client so deliveries resume immediately.
+func (s *EventService) ResetCircuit(clientID string) (*models.CircuitStatus, error) {
+	if _, err := s.clientRepo.Get(clientID); err != nil {
+		return nil, fmt.Errorf("failed to get client: %w", err)
+	}
+
+	s.circuitBreaker.Reset(clientID)
+	return s.circuitBreaker.Status(clientID), nil
+}
+
+// CleanupOldEvents removes the events of a client past their retention period, keeping
+// failed events for retention.FailedDays if set.
+func (s *EventService) CleanupOldEvents(clientID string, retention models.EventRetention) error {
+	removed, err := s.eventRepo.CleanupOldEvents(clientID, retention)
+	if err != nil {
 		return fmt.Errorf("failed to cleanup old events: %w", err)
 	}
 
-	s.log.Info("Cleaned up old events for client: %s (retention: %d days)", clientID, retentionDays)
+	if removed > 0 {
+		s.log.Info("Cleaned up %d old events for client: %s (retention: %d days, failed events: %d days)",
+			removed, clientID, retention.DaysFor(models.EventStatusSuccess), retention.DaysFor(models.EventStatusFailed))
+	}
 	return nil
 }
+
+// ApplyRetention removes the events of all clients past the retention period set with
+// SetRetention. It is executed periodically by the scheduler.
+func (s *EventService) ApplyRetention() {
+	if !s.retention.Enabled() {
+		return
+	}
+
+	clients, err := s.clientRepo.ListAll()
+	if err != nil {
+		s.log.Error("Failed to list clients for event retention: %v", err)
+		return
+	}
+
+	for _, client := range clients {
+		if err := s.CleanupOldEvents(client.ID, s.retention); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			s.log.Error("Failed to apply event retention to client %s: %v", client.ID, err)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package service_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("EventService delivery receipts", func() {
+	It("records delivery tokens and reports unacknowledged deliveries", func() {
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/acked":
+				w.Header().Set("X-Receipt", "receipt-1")
+			case "/failed":
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}))
+		defer target.Close()
+
+		baseDir := GinkgoT().TempDir()
+		log := logger.New()
+		clientRepo, err := repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		eventRepo := repository.NewFileEventRepository(baseDir)
+		eventService := service.NewEventService(
+			eventRepo,
+			clientRepo,
+			service.NewJobService(time.Hour, log),
+			service.NewCircuitBreakerService(0, time.Minute, service.NewNotificationService(log), log),
+			log,
+		)
+
+		client := &models.Client{
+			ID:            "client-ack",
+			UserID:        "user-ack",
+			TargetURL:     target.URL + "/{{.EventType}}",
+			TargetTimeout: 5,
+			Ack:           &models.AckConfig{Enabled: true, Header: "X-Receipt"},
+		}
+		Expect(clientRepo.Create(client)).To(Succeed())
+
+		now := time.Now().UTC()
+		for i, eventType := range []string{"acked", "silent", "failed"} {
+			Expect(eventRepo.Save(client.ID, &models.Event{
+				ID:        eventType,
+				ClientID:  client.ID,
+				Timestamp: now.Add(time.Duration(i) * time.Second),
+				EventType: eventType,
+				Payload:   "{}",
+			})).To(Succeed())
+		}
+
+		eventService.ForwardNewEvents(client.ID, []string{"acked", "silent", "failed"})
+
+		acked, err := eventRepo.Get(client.ID, "acked")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(acked.AckToken).To(Equal("receipt-1"))
+		Expect(acked.AckedAt).NotTo(BeNil())
+
+		report, err := eventService.AckReport(client.ID, &models.EventAckReportRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Delivered).To(Equal(2))
+		Expect(report.Acknowledged).To(Equal(1))
+		Expect(report.Unacknowledged).To(HaveLen(1))
+		Expect(report.Unacknowledged[0].ID).To(Equal("silent"))
+	})
+})
@@ -0,0 +1,94 @@
+package service_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("EventService binary payloads", func() {
+	var (
+		baseDir      string
+		client       *models.Client
+		eventRepo    *repository.FileEventRepository
+		eventService *service.EventService
+		bodies       [][]byte
+		contentTypes []string
+	)
+
+	binary := []byte{0x1f, 0x8b, 0x08, 0x00, 0xff, 0xfe, 0x00, 0x0a, 0x20}
+
+	BeforeEach(func() {
+		bodies, contentTypes = nil, nil
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, body)
+			contentTypes = append(contentTypes, r.Header.Get("Content-Type"))
+		}))
+		DeferCleanup(target.Close)
+
+		baseDir = GinkgoT().TempDir()
+		log := logger.New()
+		clientRepo, err := repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		eventRepo = repository.NewFileEventRepository(baseDir)
+		eventService = service.NewEventService(
+			eventRepo,
+			clientRepo,
+			service.NewJobService(time.Hour, log),
+			service.NewCircuitBreakerService(0, time.Minute, service.NewNotificationService(log), log),
+			log,
+		)
+
+		client = &models.Client{ID: "client-binary", UserID: "user-binary", TargetURL: target.URL, TargetTimeout: 5}
+		Expect(clientRepo.Create(client)).To(Succeed())
+	})
+
+	It("keeps binary bodies stored by gosmee byte for byte", func() {
+		eventsDir := filepath.Join(baseDir, "users", client.UserID, "clients", client.ID, "events")
+		Expect(os.MkdirAll(eventsDir, 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(eventsDir, "2025-10-01T12.00.00.000.json"), binary, 0644)).To(Succeed())
+
+		event, err := eventService.Get(client.ID, "2025-10-01T12.00.00.000")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(event.PayloadEncoding).To(Equal(models.PayloadEncodingBase64))
+		Expect(event.ContentType).To(Equal(models.ContentTypeBinary))
+		Expect(event.ToSummary().Preview).To(Equal("binary payload (9 bytes)"))
+
+		// Recording the delivery result keeps the payload intact
+		for i := 0; i < 2; i++ {
+			response, err := eventService.Replay(client.ID, &models.EventReplayRequest{EventIDs: []string{event.ID}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.Results[0].Success).To(BeTrue())
+		}
+		Expect(bodies).To(Equal([][]byte{binary, binary}))
+		Expect(contentTypes).To(Equal([]string{models.ContentTypeBinary, models.ContentTypeBinary}))
+	})
+
+	It("injects base64-encoded payloads", func() {
+		response, err := eventService.Inject(client.ID, &models.EventInjectRequest{
+			Payload:         []byte(`"H4sIAP/+AAog"`),
+			PayloadEncoding: models.PayloadEncodingBase64,
+			Headers:         map[string]string{"Content-Type": "application/gzip"},
+			Forward:         true,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.Forward.Success).To(BeTrue())
+		Expect(bodies).To(Equal([][]byte{binary}))
+		Expect(contentTypes).To(Equal([]string{"application/gzip"}))
+
+		_, err = eventService.Inject(client.ID, &models.EventInjectRequest{Payload: []byte(`"not base64!"`), PayloadEncoding: models.PayloadEncodingBase64})
+		Expect(err).To(MatchError(ContainSubstring("base64")))
+	})
+})
@@ -0,0 +1,93 @@
+package service_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/masking"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("EventService event bundle", func() {
+	var (
+		eventService *service.EventService
+		eventsDir    string
+	)
+
+	readBundle := func(data []byte) map[string]string {
+		archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		Expect(err).NotTo(HaveOccurred())
+
+		files := make(map[string]string)
+		for _, file := range archive.File {
+			reader, err := file.Open()
+			Expect(err).NotTo(HaveOccurred())
+			content, err := io.ReadAll(reader)
+			Expect(err).NotTo(HaveOccurred())
+			reader.Close()
+			files[file.Name] = string(content)
+		}
+		return files
+	}
+
+	BeforeEach(func() {
+		baseDir := GinkgoT().TempDir()
+		clientRepo, err := repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		eventRepo := repository.NewFileEventRepository(baseDir)
+		log := logger.New()
+		eventService = service.NewEventService(
+			eventRepo,
+			clientRepo,
+			service.NewJobService(time.Hour, log),
+			service.NewCircuitBreakerService(0, time.Minute, service.NewNotificationService(log), log),
+			log,
+		)
+		eventService.SetMasker(func(userID string) *masking.Masker { return masking.Default })
+
+		client := &models.Client{ID: "client-bundle", UserID: "user-bundle", Name: "bundle", TargetURL: "http://target.example.com"}
+		Expect(clientRepo.Create(client)).To(Succeed())
+		eventsDir = filepath.Join(baseDir, "users", client.UserID, "clients", client.ID, "events")
+	})
+
+	It("packages the masked event, its script and a README", func() {
+		Expect(os.WriteFile(filepath.Join(eventsDir, "20251001T120000.json"), []byte(`{"action":"opened"}`), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(eventsDir, "20251001T120000.sh"), []byte(
+			"curl -H 'X-GitHub-Event: pull_request' -H 'X-Hub-Signature-256: sha256=abc' http://target.example.com\n"), 0755)).To(Succeed())
+
+		data, err := eventService.Bundle("client-bundle", "20251001T120000")
+		Expect(err).NotTo(HaveOccurred())
+
+		files := readBundle(data)
+		Expect(files).To(HaveKey("README.md"))
+		Expect(files["README.md"]).To(ContainSubstring("bundle (client-bundle)"))
+		Expect(files["20251001T120000.json"]).To(ContainSubstring(`"X-GitHub-Event": "pull_request"`))
+		Expect(files["20251001T120000.json"]).To(ContainSubstring(`"X-Hub-Signature-256": "[REDACTED]"`))
+		Expect(files["20251001T120000.sh"]).To(ContainSubstring("X-Hub-Signature-256: [REDACTED]"))
+		Expect(files["20251001T120000.sh"]).NotTo(ContainSubstring("sha256=abc"))
+	})
+
+	It("omits the script of events without one", func() {
+		Expect(os.WriteFile(filepath.Join(eventsDir, "evt-1.json"), []byte(`{"id":"evt-1","clientId":"client-bundle","payload":"{}"}`), 0644)).To(Succeed())
+
+		data, err := eventService.Bundle("client-bundle", "evt-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(readBundle(data)).To(HaveLen(2))
+	})
+
+	It("reports unknown events", func() {
+		_, err := eventService.Bundle("client-bundle", "missing")
+		Expect(err).To(MatchError(apperrors.ErrEventNotFound))
+	})
+})
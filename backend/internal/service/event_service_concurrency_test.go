@@ -0,0 +1,103 @@
+package service_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("EventService concurrent updates", func() {
+	var (
+		client       *models.Client
+		eventRepo    *repository.FileEventRepository
+		eventService *service.EventService
+		onDelivery   func()
+	)
+
+	BeforeEach(func() {
+		onDelivery = func() {}
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			onDelivery()
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		DeferCleanup(target.Close)
+
+		baseDir := GinkgoT().TempDir()
+		log := logger.New()
+		clientRepo, err := repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		eventRepo = repository.NewFileEventRepository(baseDir)
+		eventService = service.NewEventService(
+			eventRepo,
+			clientRepo,
+			service.NewJobService(time.Hour, log),
+			service.NewCircuitBreakerService(0, time.Minute, service.NewNotificationService(log), log),
+			log,
+		)
+
+		client = &models.Client{ID: "client-versions", UserID: "user", TargetURL: target.URL, TargetTimeout: 5}
+		Expect(clientRepo.Create(client)).To(Succeed())
+		Expect(eventRepo.Save(client.ID, &models.Event{ID: "event", ClientID: client.ID, Payload: `{}`})).To(Succeed())
+	})
+
+	It("rejects saving an event that was changed or deleted since it was read", func() {
+		first, err := eventRepo.Get(client.ID, "event")
+		Expect(err).NotTo(HaveOccurred())
+		second, err := eventRepo.Get(client.ID, "event")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(first.Version).To(Equal(1))
+
+		Expect(eventRepo.Save(client.ID, first)).To(Succeed())
+		Expect(first.Version).To(Equal(2))
+		Expect(errors.Is(eventRepo.Save(client.ID, second), repository.ErrEventConflict)).To(BeTrue())
+
+		Expect(eventRepo.Delete(client.ID, "event")).To(Succeed())
+		Expect(errors.Is(eventRepo.Save(client.ID, first), repository.ErrEventConflict)).To(BeTrue())
+		_, err = eventRepo.Get(client.ID, "event")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("records delivery results on top of concurrent changes", func() {
+		onDelivery = func() {
+			list, err := eventService.List(client.ID, &models.EventListRequest{Page: 1, PageSize: 10, SortBy: "timestamp", SortOrder: "desc"})
+			Expect(err).NotTo(HaveOccurred())
+			for _, summary := range list.Events {
+				event, err := eventRepo.Get(client.ID, summary.ID)
+				Expect(err).NotTo(HaveOccurred())
+				event.Source = "other-tab"
+				Expect(eventRepo.Save(client.ID, event)).To(Succeed())
+			}
+		}
+
+		response, err := eventService.Inject(client.ID, &models.EventInjectRequest{Payload: []byte(`{}`), Forward: true})
+		Expect(err).NotTo(HaveOccurred())
+
+		stored, err := eventRepo.Get(client.ID, response.Event.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored.Source).To(Equal("other-tab"))
+		Expect(stored.StatusCode).To(Equal(http.StatusAccepted))
+		Expect(stored.Version).To(Equal(3))
+	})
+
+	It("only deletes events at the requested version", func() {
+		err := eventService.Delete(client.ID, "event", &models.EventDeleteRequest{Version: 2})
+		var appErr *apperrors.AppError
+		Expect(errors.As(err, &appErr)).To(BeTrue())
+		Expect(appErr.Code).To(Equal(apperrors.CodeEventConflict))
+		Expect(appErr.StatusCode).To(Equal(http.StatusConflict))
+
+		Expect(eventService.Delete(client.ID, "event", &models.EventDeleteRequest{Version: 1})).To(Succeed())
+		_, err = eventRepo.Get(client.ID, "event")
+		Expect(err).To(HaveOccurred())
+	})
+})
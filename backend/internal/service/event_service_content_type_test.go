@@ -0,0 +1,65 @@
+package service_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("EventService content types", func() {
+	It("parses form-encoded payloads and replays them with their content type", func() {
+		var contentTypes []string
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			contentTypes = append(contentTypes, r.Header.Get("Content-Type"))
+		}))
+		defer target.Close()
+
+		baseDir := GinkgoT().TempDir()
+		log := logger.New()
+		clientRepo, err := repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		eventRepo := repository.NewFileEventRepository(baseDir)
+		eventService := service.NewEventService(
+			eventRepo,
+			clientRepo,
+			service.NewJobService(time.Hour, log),
+			service.NewCircuitBreakerService(0, time.Minute, service.NewNotificationService(log), log),
+			log,
+		)
+
+		client := &models.Client{ID: "client-form", UserID: "user-form", TargetURL: target.URL, TargetTimeout: 5}
+		Expect(clientRepo.Create(client)).To(Succeed())
+		for _, event := range []*models.Event{
+			{ID: "evt-form", Payload: "payload=%7B%22ref%22%3A%22main%22%7D&sig=abc"},
+			{ID: "evt-xml", Payload: "<push><ref>main</ref></push>"},
+			{ID: "evt-json", Payload: `{"ref":"main"}`},
+			{ID: "evt-header", Payload: "a=b", Headers: map[string]string{"content-type": "text/plain"}},
+		} {
+			event.ClientID = client.ID
+			event.Timestamp = time.Now().UTC()
+			Expect(eventRepo.Save(client.ID, event)).To(Succeed())
+		}
+
+		event, err := eventService.Get(client.ID, "evt-form")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(event.ContentType).To(Equal(models.ContentTypeForm))
+		Expect(event.Form).To(Equal(map[string][]string{"payload": {`{"ref":"main"}`}, "sig": {"abc"}}))
+
+		event, err = eventService.Get(client.ID, "evt-header")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(event.ContentType).To(Equal("text/plain"))
+		Expect(event.Form).To(BeNil())
+
+		_, err = eventService.Replay(client.ID, &models.EventReplayRequest{EventIDs: []string{"evt-form", "evt-xml", "evt-json", "evt-header"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(contentTypes).To(Equal([]string{models.ContentTypeForm, models.ContentTypeXML, models.ContentTypeJSON, "text/plain"}))
+	})
+})
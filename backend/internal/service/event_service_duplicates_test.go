@@ -0,0 +1,87 @@
+package service_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("EventService duplicates", func() {
+	var (
+		client       *models.Client
+		eventRepo    *repository.FileEventRepository
+		eventService *service.EventService
+	)
+
+	BeforeEach(func() {
+		baseDir := GinkgoT().TempDir()
+		log := logger.New()
+		clientRepo, err := repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		eventRepo = repository.NewFileEventRepository(baseDir)
+		eventService = service.NewEventService(
+			eventRepo,
+			clientRepo,
+			service.NewJobService(time.Hour, log),
+			service.NewCircuitBreakerService(0, time.Minute, service.NewNotificationService(log), log),
+			log,
+		)
+
+		client = &models.Client{ID: "client-dup", UserID: "user-dup", TargetURL: "http://127.0.0.1:1"}
+		Expect(clientRepo.Create(client)).To(Succeed())
+
+		start := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+		for i, event := range []*models.Event{
+			{ID: "original", Payload: `{"ref":"main"}`, Headers: map[string]string{"X-GitHub-Delivery": "guid-1"}},
+			{ID: "redelivery", Payload: `{"ref":"main","retried":true}`, Headers: map[string]string{"x-github-delivery": "guid-1"}},
+			{ID: "other", Payload: `{"ref":"main"}`, Headers: map[string]string{"X-GitHub-Delivery": "guid-2"}},
+			{ID: "plain", EventType: "push", Payload: `{"ref":"dev"}`},
+			{ID: "plain-again", EventType: "push", Payload: `{"ref":"dev"}`},
+			{ID: "plain-tag", EventType: "tag", Payload: `{"ref":"dev"}`},
+		} {
+			event.ClientID = client.ID
+			event.Timestamp = start.Add(time.Duration(i) * time.Minute)
+			Expect(eventRepo.Save(client.ID, event)).To(Succeed())
+		}
+	})
+
+	It("flags redeliveries by delivery ID or content in the list", func() {
+		response, err := eventService.List(client.ID, &models.EventListRequest{
+			Page: 1, PageSize: 20, SortBy: "timestamp", SortOrder: "asc", MarkDuplicates: true,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		duplicateOf := make(map[string]string)
+		for _, summary := range response.Events {
+			Expect(summary.Fingerprint).NotTo(BeEmpty())
+			duplicateOf[summary.ID] = summary.DuplicateOf
+		}
+		Expect(duplicateOf).To(Equal(map[string]string{
+			"original": "", "redelivery": "original", "other": "",
+			"plain": "", "plain-again": "plain", "plain-tag": "",
+		}))
+	})
+
+	It("deletes redeliveries and keeps the earliest event", func() {
+		response, err := eventService.Deduplicate(client.ID, &models.EventDeduplicateRequest{DryRun: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.Groups).To(Equal(2))
+		Expect(response.EventIDs).To(Equal([]string{"redelivery", "plain-again"}))
+		Expect(response.Deleted).To(BeZero())
+
+		response, err = eventService.Deduplicate(client.ID, &models.EventDeduplicateRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.Deleted).To(Equal(2))
+
+		_, err = eventRepo.Get(client.ID, "redelivery")
+		Expect(err).To(HaveOccurred())
+		_, err = eventRepo.Get(client.ID, "original")
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
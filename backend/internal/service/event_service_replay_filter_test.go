@@ -0,0 +1,107 @@
+package service_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("EventService replay by filter", func() {
+	var (
+		client       *models.Client
+		eventService *service.EventService
+		mu           sync.Mutex
+		received     []string
+		base         time.Time
+	)
+
+	BeforeEach(func() {
+		received = nil
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			received = append(received, r.Header.Get("X-Event-Id"))
+			mu.Unlock()
+		}))
+		DeferCleanup(target.Close)
+
+		baseDir := GinkgoT().TempDir()
+		log := logger.New()
+		clientRepo, err := repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		eventRepo := repository.NewFileEventRepository(baseDir)
+		eventService = service.NewEventService(
+			eventRepo,
+			clientRepo,
+			service.NewJobService(time.Hour, log),
+			service.NewCircuitBreakerService(0, time.Minute, service.NewNotificationService(log), log),
+			log,
+		)
+
+		client = &models.Client{ID: "client-filter", UserID: "user", TargetURL: target.URL, TargetTimeout: 5}
+		Expect(clientRepo.Create(client)).To(Succeed())
+
+		base = time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+		for i, spec := range []struct {
+			id, eventType string
+			status        models.EventStatus
+		}{
+			{"push-failed-1", "push", models.EventStatusFailed},
+			{"push-ok", "push", models.EventStatusSuccess},
+			{"issue-failed", "issues", models.EventStatusFailed},
+			{"push-failed-2", "push", models.EventStatusFailed},
+		} {
+			Expect(eventRepo.Save(client.ID, &models.Event{
+				ID:        spec.id,
+				ClientID:  client.ID,
+				Timestamp: base.Add(time.Duration(i) * time.Hour),
+				EventType: spec.eventType,
+				Status:    spec.status,
+				Headers:   map[string]string{"X-Event-Id": spec.id},
+				Payload:   `{}`,
+			})).To(Succeed())
+		}
+	})
+
+	It("replays the events matching the filter, oldest first", func() {
+		response, err := eventService.Replay(client.ID, &models.EventReplayRequest{
+			Filter: &models.EventReplayFilter{Status: "failed", EventType: "push"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.Total).To(Equal(2))
+		Expect(response.Successful).To(Equal(2))
+		Expect(received).To(Equal([]string{"push-failed-1", "push-failed-2"}))
+	})
+
+	It("limits the filter to a date range", func() {
+		response, err := eventService.Replay(client.ID, &models.EventReplayRequest{
+			Filter: &models.EventReplayFilter{Status: "failed", DateFrom: base.Add(30 * time.Minute), DateTo: base.Add(150 * time.Minute)},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.Total).To(Equal(1))
+		Expect(received).To(Equal([]string{"issue-failed"}))
+	})
+
+	It("requires either event IDs or a filter", func() {
+		for _, req := range []*models.EventReplayRequest{
+			{},
+			{EventIDs: []string{"push-ok"}, Filter: &models.EventReplayFilter{Status: "failed"}},
+		} {
+			_, err := eventService.Replay(client.ID, req)
+			var appErr *apperrors.AppError
+			Expect(errors.As(err, &appErr)).To(BeTrue())
+			Expect(appErr.Code).To(Equal(apperrors.CodeInvalidInput))
+		}
+		Expect(received).To(BeEmpty())
+	})
+})
@@ -0,0 +1,79 @@
+package service_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("EventService response capture", func() {
+	var (
+		client       *models.Client
+		eventRepo    *repository.FileEventRepository
+		eventService *service.EventService
+		reply        string
+	)
+
+	BeforeEach(func() {
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(reply))
+		}))
+		DeferCleanup(target.Close)
+
+		baseDir := GinkgoT().TempDir()
+		log := logger.New()
+		clientRepo, err := repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		eventRepo = repository.NewFileEventRepository(baseDir)
+		eventService = service.NewEventService(
+			eventRepo,
+			clientRepo,
+			service.NewJobService(time.Hour, log),
+			service.NewCircuitBreakerService(0, time.Minute, service.NewNotificationService(log), log),
+			log,
+		)
+		eventService.SetResponseCapture(10)
+
+		client = &models.Client{ID: "client-response", UserID: "user", TargetURL: target.URL, TargetTimeout: 5}
+		Expect(clientRepo.Create(client)).To(Succeed())
+	})
+
+	forward := func() *models.Event {
+		response, err := eventService.Inject(client.ID, &models.EventInjectRequest{Payload: []byte(`{}`), Forward: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.Forward.Success).To(BeTrue())
+
+		stored, err := eventRepo.Get(client.ID, response.Event.ID)
+		Expect(err).NotTo(HaveOccurred())
+		return stored
+	}
+
+	It("stores the target's response on forwarded events", func() {
+		reply = `{"ok":1}`
+		stored := forward()
+		Expect(stored.Response).To(Equal(`{"ok":1}`))
+		Expect(stored.ResponseTruncated).To(BeFalse())
+	})
+
+	It("cuts long responses without splitting characters", func() {
+		reply = "accepted:äöü"
+		stored := forward()
+		Expect(stored.Response).To(Equal("accepted:"))
+		Expect(stored.ResponseTruncated).To(BeTrue())
+	})
+
+	It("describes binary responses", func() {
+		reply = "\xff\xfe\x00"
+		stored := forward()
+		Expect(stored.Response).To(Equal("binary response (3 bytes)"))
+	})
+})
@@ -0,0 +1,213 @@
+package service_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("EventService automatic delivery retry", func() {
+	type testCase struct {
+		targetStatus     int
+		maxAttempts      int
+		priorAttempts    int
+		expectedStatus   models.EventStatus
+		expectedAttempts int
+		expectNextRetry  bool
+		expectGaveUp     bool
+	}
+
+	DescribeTable("retries due failed events and records the outcome",
+		func(tc testCase) {
+			target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.targetStatus)
+			}))
+			defer target.Close()
+
+			baseDir := GinkgoT().TempDir()
+			clientRepo, err := repository.NewFileClientRepository(baseDir)
+			Expect(err).NotTo(HaveOccurred())
+			eventRepo := repository.NewFileEventRepository(baseDir)
+			log := logger.New()
+			eventService := service.NewEventService(
+				eventRepo,
+				clientRepo,
+				service.NewJobService(time.Hour, log),
+				service.NewCircuitBreakerService(0, time.Minute, service.NewNotificationService(log), log),
+				log,
+			)
+
+			client := &models.Client{
+				ID:            "client-retry",
+				UserID:        "user-retry",
+				Name:          "retry",
+				TargetURL:     target.URL,
+				TargetTimeout: 5,
+				RetryPolicy: &models.RetryPolicy{
+					Enabled:               true,
+					MaxAttempts:           tc.maxAttempts,
+					InitialBackoffSeconds: 1,
+					MaxBackoffSeconds:     1,
+				},
+			}
+			Expect(clientRepo.Create(client)).To(Succeed())
+
+			event := &models.Event{
+				ID:            "event-retry",
+				ClientID:      client.ID,
+				Timestamp:     time.Now().Add(-1500 * time.Millisecond).UTC(),
+				EventType:     "push",
+				Status:        models.EventStatusFailed,
+				StatusCode:    http.StatusBadGateway,
+				Payload:       "{}",
+				RetryAttempts: tc.priorAttempts,
+			}
+			Expect(eventRepo.Save(client.ID, event)).To(Succeed())
+
+			eventService.RetryFailedDeliveries()
+
+			stored, err := eventRepo.Get(client.ID, event.ID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stored.Status).To(Equal(tc.expectedStatus))
+			Expect(stored.RetryAttempts).To(Equal(tc.expectedAttempts))
+			Expect(stored.StatusCode).To(Equal(tc.targetStatus))
+			if tc.expectNextRetry {
+				Expect(stored.NextRetryAt).NotTo(BeNil())
+			} else {
+				Expect(stored.NextRetryAt).To(BeNil())
+			}
+			if tc.expectGaveUp {
+				Expect(stored.ErrorMessage).To(ContainSubstring("gave up after"))
+			}
+		},
+		Entry("marks the event successful when the retry succeeds", testCase{
+			targetStatus:     http.StatusOK,
+			maxAttempts:      3,
+			expectedStatus:   models.EventStatusSuccess,
+			expectedAttempts: 1,
+		}),
+		Entry("schedules another retry while attempts remain", testCase{
+			targetStatus:     http.StatusInternalServerError,
+			maxAttempts:      3,
+			expectedStatus:   models.EventStatusRetrying,
+			expectedAttempts: 1,
+			expectNextRetry:  true,
+		}),
+		Entry("gives up once the last attempt fails", testCase{
+			targetStatus:     http.StatusInternalServerError,
+			maxAttempts:      2,
+			priorAttempts:    1,
+			expectedStatus:   models.EventStatusFailed,
+			expectedAttempts: 2,
+			expectGaveUp:     true,
+		}),
+	)
+})
+
+// readCountingEventRepository counts the events read one by one.
+type readCountingEventRepository struct {
+	repository.EventRepository
+
+	mu    sync.Mutex
+	reads map[string]int
+}
+
+func (r *readCountingEventRepository) Get(clientID, eventID string) (*models.Event, error) {
+	r.mu.Lock()
+	r.reads[eventID]++
+	r.mu.Unlock()
+	return r.EventRepository.Get(clientID, eventID)
+}
+
+var _ = Describe("EventService delivery retry selection", func() {
+	var (
+		eventRepo    *readCountingEventRepository
+		eventService *service.EventService
+		client       *models.Client
+		delivered    chan string
+	)
+
+	BeforeEach(func() {
+		delivered = make(chan string, 10)
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			delivered <- r.Header.Get("X-Event-Id")
+		}))
+		DeferCleanup(target.Close)
+
+		baseDir := GinkgoT().TempDir()
+		clientRepo, err := repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		eventRepo = &readCountingEventRepository{
+			EventRepository: repository.NewFileEventRepository(baseDir),
+			reads:           make(map[string]int),
+		}
+		log := logger.New()
+		eventService = service.NewEventService(
+			eventRepo,
+			clientRepo,
+			service.NewJobService(time.Hour, log),
+			service.NewCircuitBreakerService(0, time.Minute, service.NewNotificationService(log), log),
+			log,
+		)
+
+		client = &models.Client{
+			ID:            "client-retry-selection",
+			UserID:        "user-retry",
+			TargetURL:     target.URL,
+			TargetTimeout: 5,
+			RetryPolicy:   &models.RetryPolicy{Enabled: true, MaxAttempts: 3, InitialBackoffSeconds: 1, MaxBackoffSeconds: 1},
+		}
+		Expect(clientRepo.Create(client)).To(Succeed())
+	})
+
+	save := func(event *models.Event) {
+		event.ClientID = client.ID
+		event.Headers = map[string]string{"X-Event-Id": event.ID}
+		event.Payload = "{}"
+		Expect(eventRepo.Save(client.ID, event)).To(Succeed())
+	}
+
+	It("reads only the failed events within the retry schedule", func() {
+		save(&models.Event{ID: "old-failure", Timestamp: time.Now().AddDate(0, 0, -3).UTC(), Status: models.EventStatusFailed})
+		save(&models.Event{ID: "old-success", Timestamp: time.Now().AddDate(0, 0, -3).UTC(), Status: models.EventStatusSuccess})
+		save(&models.Event{ID: "recent-success", Timestamp: time.Now().Add(-1500 * time.Millisecond).UTC(), Status: models.EventStatusSuccess})
+		save(&models.Event{ID: "recent-failure", Timestamp: time.Now().Add(-1500 * time.Millisecond).UTC(), Status: models.EventStatusFailed})
+
+		eventService.RetryFailedDeliveries()
+		eventService.RetryFailedDeliveries()
+
+		Expect(delivered).To(Receive(Equal("recent-failure")))
+		Expect(delivered).NotTo(Receive())
+		Expect(eventRepo.reads).NotTo(HaveKey("old-failure"))
+		Expect(eventRepo.reads).NotTo(HaveKey("old-success"))
+		Expect(eventRepo.reads).NotTo(HaveKey("recent-success"))
+	})
+
+	It("retries scheduled events once they are due, however old they are", func() {
+		due := time.Now().Add(-time.Second)
+		save(&models.Event{ID: "late-retry", Timestamp: time.Now().AddDate(0, 0, -3).UTC(), Status: models.EventStatusRetrying, RetryAttempts: 1, NextRetryAt: &due})
+		later := time.Now().Add(time.Hour)
+		save(&models.Event{ID: "later-retry", Timestamp: time.Now().AddDate(0, 0, -3).UTC(), Status: models.EventStatusRetrying, RetryAttempts: 1, NextRetryAt: &later})
+
+		eventService.RetryFailedDeliveries()
+		Expect(delivered).To(Receive(Equal("late-retry")))
+		Expect(delivered).NotTo(Receive())
+
+		stored, err := eventRepo.Get(client.ID, "late-retry")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored.Status).To(Equal(models.EventStatusSuccess))
+
+		// The event not due yet is read once to learn its due time, then waits for it
+		eventService.RetryFailedDeliveries()
+		Expect(eventRepo.reads["later-retry"]).To(Equal(1))
+	})
+})
@@ -0,0 +1,135 @@
+package service_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("EventService script replay", func() {
+	var (
+		eventService *service.EventService
+		eventRepo    *repository.FileEventRepository
+		status       int
+		delay        time.Duration
+		received     chan *http.Request
+		bodies       chan string
+	)
+
+	saveEvent := func(eventID string) {
+		Expect(eventRepo.Save("client-replay", &models.Event{
+			ID:        eventID,
+			ClientID:  "client-replay",
+			Timestamp: time.Now().UTC(),
+			Headers:   map[string]string{"X-Github-Event": "issues", "Content-Type": "application/json"},
+			Payload:   `{"action":"opened"}`,
+		})).To(Succeed())
+	}
+
+	replay := func(eventID string) *models.EventReplayResult {
+		response, err := eventService.Replay("client-replay", &models.EventReplayRequest{
+			EventIDs: []string{eventID},
+			Mode:     models.ReplayModeScript,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.Results).To(HaveLen(1))
+		return response.Results[0]
+	}
+
+	BeforeEach(func() {
+		status, delay = http.StatusAccepted, 0
+		received = make(chan *http.Request, 1)
+		bodies = make(chan string, 1)
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			received <- r
+			bodies <- string(body)
+			time.Sleep(delay)
+			w.WriteHeader(status)
+		}))
+		DeferCleanup(target.Close)
+
+		baseDir := GinkgoT().TempDir()
+		clientRepo, err := repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		log := logger.New()
+		eventRepo = repository.NewFileEventRepository(baseDir)
+		eventService = service.NewEventService(
+			eventRepo,
+			clientRepo,
+			service.NewJobService(time.Hour, log),
+			service.NewCircuitBreakerService(0, time.Minute, service.NewNotificationService(log), log),
+			log,
+		)
+		eventService.SetScriptReplay(time.Second)
+
+		client := &models.Client{ID: "client-replay", UserID: "user-replay", Name: "replay", TargetURL: target.URL + "/hook"}
+		Expect(clientRepo.Create(client)).To(Succeed())
+	})
+
+	It("sends the stored headers and payload with a script generated from the event", func() {
+		saveEvent("evt-ok")
+
+		result := replay("evt-ok")
+		Expect(result.ErrorMessage).To(BeEmpty())
+		Expect(result.Success).To(BeTrue())
+		Expect(result.StatusCode).To(Equal(http.StatusAccepted))
+
+		var request *http.Request
+		Expect(received).To(Receive(&request))
+		Expect(request.URL.Path).To(Equal("/hook"))
+		Expect(request.Header.Get("X-Github-Event")).To(Equal("issues"))
+		Expect(bodies).To(Receive(Equal(`{"action":"opened"}`)))
+	})
+
+	It("never executes the script stored next to the event", func() {
+		saveEvent("evt-planted")
+		marker := filepath.Join(GinkgoT().TempDir(), "executed")
+		script := "#!/usr/bin/env bash\ntouch " + marker + "\nprintf 'HTTP/1.1 200 OK\\r\\n'\n"
+		Expect(eventRepo.SaveScript("client-replay", "evt-planted", []byte(script))).To(Succeed())
+
+		result := replay("evt-planted")
+		Expect(result.Success).To(BeTrue())
+		Expect(received).To(Receive())
+		Expect(marker).NotTo(BeAnExistingFile())
+	})
+
+	It("reports HTTP errors of the target", func() {
+		status = http.StatusInternalServerError
+		saveEvent("evt-http")
+
+		result := replay("evt-http")
+		Expect(result.Success).To(BeFalse())
+		Expect(result.StatusCode).To(Equal(http.StatusInternalServerError))
+	})
+
+	It("kills scripts that exceed the timeout", func() {
+		delay = 3 * time.Second
+		saveEvent("evt-slow")
+
+		started := time.Now()
+		result := replay("evt-slow")
+		Expect(result.Success).To(BeFalse())
+		Expect(result.ErrorMessage).To(ContainSubstring("timed out"))
+		Expect(time.Since(started)).To(BeNumerically("<", 3*time.Second))
+	})
+
+	It("is rejected when script replay is disabled", func() {
+		eventService.SetScriptReplay(0)
+		_, err := eventService.Replay("client-replay", &models.EventReplayRequest{
+			EventIDs: []string{"evt-ok"},
+			Mode:     models.ReplayModeScript,
+		})
+		Expect(err).To(HaveOccurred())
+	})
+})
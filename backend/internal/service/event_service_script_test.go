@@ -0,0 +1,87 @@
+package service_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/masking"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("EventService replay script regeneration", func() {
+	var (
+		eventService *service.EventService
+		eventsDir    string
+	)
+
+	BeforeEach(func() {
+		baseDir := GinkgoT().TempDir()
+		clientRepo, err := repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		log := logger.New()
+		eventService = service.NewEventService(
+			repository.NewFileEventRepository(baseDir),
+			clientRepo,
+			service.NewJobService(time.Hour, log),
+			service.NewCircuitBreakerService(0, time.Minute, service.NewNotificationService(log), log),
+			log,
+		)
+		eventService.SetMasker(func(userID string) *masking.Masker { return masking.Default })
+
+		client := &models.Client{ID: "client-script", UserID: "user-script", Name: "script", TargetURL: "http://new-target.example.com/hook"}
+		Expect(clientRepo.Create(client)).To(Succeed())
+		eventsDir = filepath.Join(baseDir, "users", client.UserID, "clients", client.ID, "events")
+
+		Expect(os.WriteFile(filepath.Join(eventsDir, "evt-1.json"), []byte(`{"action":"it's opened"}`), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(eventsDir, "evt-1.sh"), []byte(
+			"curl -H 'X-GitHub-Event: push' -H 'X-Hub-Signature-256: sha256=abc' -H 'X-Debug: 0' http://old-target.example.com\n"), 0755)).To(Succeed())
+	})
+
+	It("replaces the stored script with one for the current target URL", func() {
+		response, err := eventService.RegenerateScript("client-script", "evt-1", &models.EventScriptRequest{
+			Headers: map[string]string{"x-debug": "1"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.Saved).To(BeTrue())
+		Expect(response.Format).To(Equal(models.ScriptFormatCurl))
+		Expect(response.Script).To(ContainSubstring("targetURL='http://new-target.example.com/hook'"))
+		Expect(response.Script).To(ContainSubstring(`payload='{"action":"it'\''s opened"}'`))
+		Expect(response.Script).To(ContainSubstring("-H 'x-debug: 1'"))
+		Expect(response.Script).NotTo(ContainSubstring("X-Debug: 0"))
+		Expect(response.Script).NotTo(ContainSubstring("sha256=abc"))
+
+		stored, err := os.ReadFile(filepath.Join(eventsDir, "evt-1.sh"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(stored)).To(ContainSubstring("X-Hub-Signature-256: sha256=abc"))
+		Expect(string(stored)).NotTo(ContainSubstring("old-target"))
+	})
+
+	It("leaves the stored script untouched on a dry run", func() {
+		response, err := eventService.RegenerateScript("client-script", "evt-1", &models.EventScriptRequest{
+			Format:  models.ScriptFormatHTTPie,
+			Headers: map[string]string{"X-Hub-Signature-256": ""},
+			DryRun:  true,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.Saved).To(BeFalse())
+		Expect(response.Script).To(ContainSubstring(`http POST "${targetURL}"`))
+		Expect(response.Script).NotTo(ContainSubstring("X-Hub-Signature-256"))
+
+		stored, err := os.ReadFile(filepath.Join(eventsDir, "evt-1.sh"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(stored)).To(ContainSubstring("old-target"))
+	})
+
+	It("reports unknown events", func() {
+		_, err := eventService.RegenerateScript("client-script", "missing", &models.EventScriptRequest{})
+		Expect(err).To(MatchError(apperrors.ErrEventNotFound))
+	})
+})
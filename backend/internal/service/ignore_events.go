@@ -0,0 +1,106 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+)
+
+// maxIgnoreEventTypoDistance is the largest edit distance at which an unknown event type is
+// taken as a typo of a known one.
+const maxIgnoreEventTypoDistance = 2
+
+// ValidateIgnoreEvents checks that every ignored event type is sent by the client's provider
+// (any known provider for custom clients), suggesting the intended event type for typos.
+// Duplicates are rejected as well. Event types the client already ignores are kept as they
+// are, so clients created before validation (or ignoring events of other providers) can
+// still be updated.
+func ValidateIgnoreEvents(provider string, events, existing []string) error {
+	catalogs := eventCatalogsOf(provider)
+	seen := make(map[string]bool, len(events))
+	for _, event := range events {
+		if seen[event] {
+			return fmt.Errorf("event type %q is listed twice", event)
+		}
+		seen[event] = true
+
+		if isKnownEventType(catalogs, event) || slices.Contains(existing, event) {
+			continue
+		}
+		if len(catalogs) == 0 {
+			return fmt.Errorf("%s webhooks have no event type header, so their events cannot be ignored", provider)
+		}
+		if suggestion := suggestEventType(catalogs, event); suggestion != "" {
+			return fmt.Errorf("unknown event type %q, did you mean %q?", event, suggestion)
+		}
+		return fmt.Errorf("unknown event type %q", event)
+	}
+	return nil
+}
+
+// eventCatalogsOf returns the event catalogs of the event types a client of the provider
+// can ignore: the provider's own catalog, or all catalogs for custom clients.
+func eventCatalogsOf(provider string) map[string][]string {
+	if provider == "" || provider == models.ProviderCustom {
+		return models.EventCatalogs
+	}
+	catalogs := make(map[string][]string)
+	if catalog, ok := models.EventCatalogs[provider]; ok {
+		catalogs[provider] = catalog
+	}
+	return catalogs
+}
+
+// isKnownEventType reports whether any of the event catalogs contains the event type.
+func isKnownEventType(catalogs map[string][]string, event string) bool {
+	for _, catalog := range catalogs {
+		for _, known := range catalog {
+			if known == event {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// suggestEventType returns the event type of the catalogs closest to an unknown one, ignoring case
+// and surrounding spaces, or "" if none is close enough.
+func suggestEventType(catalogs map[string][]string, event string) string {
+	normalized := strings.ToLower(strings.TrimSpace(event))
+	best, bestDistance := "", maxIgnoreEventTypoDistance+1
+	for _, catalog := range catalogs {
+		for _, known := range catalog {
+			distance := editDistance(normalized, strings.ToLower(known))
+			if distance < bestDistance || (distance == bestDistance && known < best) {
+				best, bestDistance = known, distance
+			}
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between two strings.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
@@ -0,0 +1,46 @@
+package service_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("Ignored event validation", func() {
+	It("accepts event types of known providers", func() {
+		Expect(service.ValidateIgnoreEvents("", nil, nil)).To(Succeed())
+		Expect(service.ValidateIgnoreEvents("", []string{"ping", "Pipeline Hook", "repo:commit_status_created"}, nil)).To(Succeed())
+	})
+
+	It("suggests the intended event type for typos", func() {
+		Expect(service.ValidateIgnoreEvents("", []string{"check_suit"}, nil)).To(MatchError(ContainSubstring(`did you mean "check_suite"?`)))
+		Expect(service.ValidateIgnoreEvents("", []string{"pipeline hook"}, nil)).To(MatchError(ContainSubstring(`did you mean "Pipeline Hook"?`)))
+	})
+
+	It("rejects unknown and duplicate event types", func() {
+		err := service.ValidateIgnoreEvents("", []string{"deployment_review_requested_by_bot"}, nil)
+		Expect(err).To(MatchError(`unknown event type "deployment_review_requested_by_bot"`))
+		Expect(service.ValidateIgnoreEvents("", []string{"ping", "ping"}, nil)).To(MatchError(ContainSubstring("listed twice")))
+	})
+
+	It("keeps unknown event types the client already ignores", func() {
+		Expect(service.ValidateIgnoreEvents("", []string{"tag_push", "ping"}, []string{"tag_push"})).To(Succeed())
+		Expect(service.ValidateIgnoreEvents("", []string{"tag_push", "tag_pushed"}, []string{"tag_push"})).NotTo(Succeed())
+	})
+
+	It("only accepts event types of the client's provider", func() {
+		Expect(service.ValidateIgnoreEvents(models.ProviderGitHub, []string{"ping"}, nil)).To(Succeed())
+		Expect(service.ValidateIgnoreEvents(models.ProviderGitHub, []string{"Pipeline Hook"}, nil)).NotTo(Succeed())
+		Expect(service.ValidateIgnoreEvents(models.ProviderStripe, []string{"ping"}, nil)).To(MatchError(ContainSubstring("cannot be ignored")))
+		Expect(service.ValidateIgnoreEvents(models.ProviderCustom, []string{"Pipeline Hook", "ping"}, nil)).To(Succeed())
+	})
+
+	It("only offers presets of known event types", func() {
+		for _, preset := range models.IgnoreEventPresets {
+			Expect(service.ValidateIgnoreEvents("", preset.Events, nil)).To(Succeed(), preset.ID)
+			Expect(models.EventCatalogs).To(HaveKey(preset.Provider), preset.ID)
+		}
+	})
+})
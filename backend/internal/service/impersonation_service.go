@@ -0,0 +1,53 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+)
+
+// ImpersonationService keeps the audit log of requests administrators made on behalf of
+// other users.
+type ImpersonationService struct {
+	repo repository.ImpersonationRepository
+	log  logger.Logger
+}
+
+// NewImpersonationService creates a new impersonation audit service.
+func NewImpersonationService(repo repository.ImpersonationRepository, log logger.Logger) *ImpersonationService {
+	return &ImpersonationService{
+		repo: repo,
+		log:  log,
+	}
+}
+
+// RecordImpersonation adds an impersonated request to the audit log. The request was
+// answered already, so failures are logged only.
+func (s *ImpersonationService) RecordImpersonation(record *models.ImpersonationRecord) {
+	s.log.Info("Impersonation: admin %s acted as user %s: %s %s -> %d (reason: %s)",
+		record.AdminID, record.UserID, record.Method, record.Path, record.Status, record.Reason)
+
+	if err := s.repo.Append(record); err != nil {
+		s.log.Error("Failed to record impersonated request %s: %v", record.RequestID, err)
+	}
+}
+
+// List returns the newest entries of the audit log matching the request, newest first.
+func (s *ImpersonationService) List(req *models.ImpersonationListRequest) ([]*models.ImpersonationRecord, error) {
+	records, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*models.ImpersonationRecord{}
+	for i := len(records) - 1; i >= 0 && len(result) < req.Limit; i-- {
+		record := records[i]
+		if (req.UserID == "" || record.UserID == req.UserID) && (req.AdminID == "" || record.AdminID == req.AdminID) {
+			result = append(result, record)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,61 @@
+package service_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("ImpersonationService", func() {
+	var (
+		baseDir              string
+		impersonationService *service.ImpersonationService
+	)
+
+	BeforeEach(func() {
+		baseDir = GinkgoT().TempDir()
+		impersonationService = service.NewImpersonationService(repository.NewFileImpersonationRepository(baseDir), logger.New())
+	})
+
+	It("lists audited requests newest first, filtered by user and administrator", func() {
+		start := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+		for i, pair := range [][2]string{{"admin-a", "bob"}, {"admin-b", "bob"}, {"admin-a", "carol"}, {"admin-a", "bob"}} {
+			impersonationService.RecordImpersonation(&models.ImpersonationRecord{
+				Time:    start.Add(time.Duration(i) * time.Minute),
+				AdminID: pair[0],
+				UserID:  pair[1],
+				Reason:  "TICKET-42",
+				Method:  "GET",
+				Path:    "/api/v1/clients",
+				Status:  200,
+			})
+		}
+
+		info, err := os.Stat(filepath.Join(baseDir, "impersonation_audit.jsonl"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+
+		records, err := impersonationService.List(&models.ImpersonationListRequest{Limit: 100})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(records).To(HaveLen(4))
+		Expect(records[0].Time).To(BeTemporally("==", start.Add(3*time.Minute)))
+
+		records, err = impersonationService.List(&models.ImpersonationListRequest{UserID: "bob", AdminID: "admin-a", Limit: 100})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(records).To(HaveLen(2))
+		Expect(records[0].Time).To(BeTemporally(">", records[1].Time))
+
+		records, err = impersonationService.List(&models.ImpersonationListRequest{UserID: "bob", Limit: 1})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(records).To(HaveLen(1))
+		Expect(records[0].AdminID).To(Equal("admin-a"))
+	})
+})
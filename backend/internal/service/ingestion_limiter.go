@@ -0,0 +1,164 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+)
+
+// Ingestion overflow policies.
+const (
+	IngestionOverflowDrop  = "drop"  // Delete events over the limit
+	IngestionOverflowQueue = "queue" // Hold events over the limit back until the next minute
+)
+
+// ingestionWindow is the period the event rate limits apply to.
+const ingestionWindow = time.Minute
+
+// IngestionLimiter limits how many new events are stored per minute, per client and in
+// total, protecting the disk and the API from webhook storms of misconfigured sources.
+// Events gosmee stored over the limit are dropped, or queued and processed in a later
+// minute; the dropped events of each client are counted.
+type IngestionLimiter struct {
+	clientLimit int
+	globalLimit int
+	policy      string
+	queueSize   int
+	eventRepo   repository.EventRepository
+	log         logger.Logger
+
+	notificationService *NotificationService // Notifies users about dropped events (optional)
+
+	mu       sync.Mutex
+	window   time.Time      // Start of the current minute
+	global   int            // Events stored in the current minute
+	clients  map[string]int // clientID -> events stored in the current minute
+	notified map[string]bool
+	dropped  map[string]int64 // clientID -> events dropped since startup
+	now      func() time.Time
+}
+
+// NewIngestionLimiter creates a new ingestion limiter. A zero limit disables it; events
+// beyond queueSize queued events of a client are dropped with the queue policy as well.
+func NewIngestionLimiter(clientLimit, globalLimit int, policy string, queueSize int, eventRepo repository.EventRepository, log logger.Logger) *IngestionLimiter {
+	return &IngestionLimiter{
+		clientLimit: clientLimit,
+		globalLimit: globalLimit,
+		policy:      policy,
+		queueSize:   queueSize,
+		eventRepo:   eventRepo,
+		log:         log,
+		clients:     make(map[string]int),
+		notified:    make(map[string]bool),
+		dropped:     make(map[string]int64),
+		now:         time.Now,
+	}
+}
+
+// SetNotificationService notifies users once a minute when events of a client are dropped.
+func (l *IngestionLimiter) SetNotificationService(notificationService *NotificationService) {
+	l.notificationService = notificationService
+}
+
+// Allow reports whether another event of the client may be stored in the current minute,
+// and if so counts it.
+func (l *IngestionLimiter) Allow(clientID string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.advance()
+	if (l.clientLimit > 0 && l.clients[clientID] >= l.clientLimit) ||
+		(l.globalLimit > 0 && l.global >= l.globalLimit) {
+		return false
+	}
+	l.clients[clientID]++
+	l.global++
+	return true
+}
+
+// Limit counts an event about to be stored through the API, rejecting it with a rate
+// limited error when the limit is reached.
+func (l *IngestionLimiter) Limit(clientID string) error {
+	if l.Allow(clientID) {
+		return nil
+	}
+	retryAfter := int(l.RetryAfter().Seconds()) + 1
+	return apperrors.New(apperrors.CodeRateLimited, "Too many events stored in the last minute, try again later",
+		http.StatusTooManyRequests).WithDetails(map[string]int{"retryAfter": retryAfter})
+}
+
+// Queues reports whether events over the limit are queued rather than dropped, with room for
+// a client having queued events already.
+func (l *IngestionLimiter) Queues(queued int) bool {
+	return l.policy == IngestionOverflowQueue && queued < l.queueSize
+}
+
+// RetryAfter returns how long until the current minute ends and events can be stored again.
+func (l *IngestionLimiter) RetryAfter() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.advance()
+	return l.window.Add(ingestionWindow).Sub(l.now())
+}
+
+// Drop deletes an event gosmee stored over the limit, counts it and notifies the user.
+func (l *IngestionLimiter) Drop(userID, clientID, eventID string) {
+	if err := l.eventRepo.Delete(clientID, eventID); err != nil {
+		l.log.Error("Failed to drop event %s over the ingestion limit: %v", eventID, err)
+		return
+	}
+
+	l.mu.Lock()
+	l.dropped[clientID]++
+	notify := !l.notified[clientID]
+	l.notified[clientID] = true
+	l.mu.Unlock()
+
+	l.log.Info("Dropped event %s of client %s: ingestion limit reached", eventID, clientID)
+	if notify && l.notificationService != nil {
+		l.notificationService.Notify(userID, clientID, "events_dropped", models.NotificationLevelWarning,
+			fmt.Sprintf("Events are being dropped: more than %s were received in a minute", l.describeLimits()))
+	}
+}
+
+// Dropped returns how many events of a client were dropped since startup.
+func (l *IngestionLimiter) Dropped(clientID string) int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.dropped[clientID]
+}
+
+// advance starts a new window once the current minute is over. The caller must hold l.mu.
+func (l *IngestionLimiter) advance() {
+	window := l.now().Truncate(ingestionWindow)
+	if window.Equal(l.window) {
+		return
+	}
+	l.window = window
+	l.global = 0
+	l.clients = make(map[string]int)
+	l.notified = make(map[string]bool)
+}
+
+// describeLimits describes the configured limits for notifications.
+func (l *IngestionLimiter) describeLimits() string {
+	switch {
+	case l.clientLimit > 0 && l.globalLimit > 0:
+		return fmt.Sprintf("%d events for this client or %d events in total", l.clientLimit, l.globalLimit)
+	case l.clientLimit > 0:
+		return fmt.Sprintf("%d events for this client", l.clientLimit)
+	default:
+		return fmt.Sprintf("%d events in total", l.globalLimit)
+	}
+}
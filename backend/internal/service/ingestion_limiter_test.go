@@ -0,0 +1,98 @@
+package service_test
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("IngestionLimiter", func() {
+	var (
+		eventsDir           string
+		client              *models.Client
+		eventRepo           *repository.FileEventRepository
+		eventService        *service.EventService
+		notificationService *service.NotificationService
+		log                 logger.Logger
+	)
+
+	BeforeEach(func() {
+		baseDir := GinkgoT().TempDir()
+		log = logger.New()
+		clientRepo, err := repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		eventRepo = repository.NewFileEventRepository(baseDir)
+		notificationService = service.NewNotificationService(log)
+		eventService = service.NewEventService(
+			eventRepo,
+			clientRepo,
+			service.NewJobService(time.Hour, log),
+			service.NewCircuitBreakerService(0, time.Minute, notificationService, log),
+			log,
+		)
+
+		client = &models.Client{ID: "client-rate", UserID: "user-rate", TargetURL: "http://127.0.0.1:1", TargetTimeout: 1}
+		Expect(clientRepo.Create(client)).To(Succeed())
+		eventsDir = filepath.Join(baseDir, "users", client.UserID, "clients", client.ID, "events")
+		Expect(os.MkdirAll(eventsDir, 0755)).To(Succeed())
+	})
+
+	It("limits events per client and in total", func() {
+		limiter := service.NewIngestionLimiter(2, 3, service.IngestionOverflowDrop, 0, eventRepo, log)
+
+		Expect(limiter.Allow("a")).To(BeTrue())
+		Expect(limiter.Allow("a")).To(BeTrue())
+		Expect(limiter.Allow("a")).To(BeFalse())
+		Expect(limiter.Allow("b")).To(BeTrue())
+		Expect(limiter.Allow("c")).To(BeFalse())
+		Expect(limiter.RetryAfter()).To(BeNumerically("<=", time.Minute))
+	})
+
+	It("rejects injected events over the limit", func() {
+		eventService.SetIngestionLimiter(service.NewIngestionLimiter(1, 0, service.IngestionOverflowDrop, 0, eventRepo, log))
+
+		_, err := eventService.Inject(client.ID, &models.EventInjectRequest{Payload: []byte(`{}`)})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = eventService.Inject(client.ID, &models.EventInjectRequest{Payload: []byte(`{}`)})
+		var appErr *apperrors.AppError
+		Expect(errors.As(err, &appErr)).To(BeTrue())
+		Expect(appErr.StatusCode).To(Equal(http.StatusTooManyRequests))
+		Expect(appErr.Code).To(Equal(apperrors.CodeRateLimited))
+	})
+
+	It("drops events stored by gosmee, counts them and notifies the user once", func() {
+		limiter := service.NewIngestionLimiter(1, 0, service.IngestionOverflowDrop, 0, eventRepo, log)
+		limiter.SetNotificationService(notificationService)
+		for _, eventID := range []string{"first", "second"} {
+			Expect(os.WriteFile(filepath.Join(eventsDir, eventID+".json"), []byte(`{}`), 0644)).To(Succeed())
+			limiter.Drop(client.UserID, client.ID, eventID)
+		}
+
+		Expect(filepath.Join(eventsDir, "first.json")).NotTo(BeAnExistingFile())
+		Expect(limiter.Dropped(client.ID)).To(Equal(int64(2)))
+		list := notificationService.List(client.UserID, false)
+		Expect(list.Notifications).To(HaveLen(1))
+		Expect(list.Notifications[0].Type).To(Equal("events_dropped"))
+	})
+
+	It("queues events over the limit up to the queue size", func() {
+		limiter := service.NewIngestionLimiter(1, 0, service.IngestionOverflowQueue, 2, eventRepo, log)
+
+		Expect(limiter.Queues(0)).To(BeTrue())
+		Expect(limiter.Queues(1)).To(BeTrue())
+		Expect(limiter.Queues(2)).To(BeFalse())
+		Expect(service.NewIngestionLimiter(1, 0, service.IngestionOverflowDrop, 2, eventRepo, log).Queues(0)).To(BeFalse())
+	})
+})
@@ -0,0 +1,152 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+)
+
+// JobProgressFunc reports the progress of a running job.
+type JobProgressFunc func(processed, total int)
+
+// JobFunc is the unit of work executed by a job. The returned value is exposed as the job result.
+type JobFunc func(progress JobProgressFunc) (interface{}, error)
+
+// JobService runs long operations asynchronously and tracks their progress in memory.
+type JobService struct {
+	jobs map[string]*models.Job
+	mu   sync.RWMutex
+	ttl  time.Duration // How long finished jobs are kept
+	log  logger.Logger
+}
+
+// NewJobService creates a new job service. Finished jobs are kept for ttl.
+func NewJobService(ttl time.Duration, log logger.Logger) *JobService {
+	s := &JobService{
+		jobs: make(map[string]*models.Job),
+		ttl:  ttl,
+		log:  log,
+	}
+
+	// Start cleanup goroutine
+	go s.cleanup()
+
+	return s
+}
+
+// Submit queues a job for asynchronous execution and returns a snapshot of it.
+func (s *JobService) Submit(userID, jobType string, total int, fn JobFunc) *models.Job {
+	job := &models.Job{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Type:      jobType,
+		Status:    models.JobStatusPending,
+		Total:     total,
+		CreatedAt: time.Now(),
+	}
+
+	s.mu.Lock()
+	s.jobs[job.ID] = job
+	snapshot := *job
+	s.mu.Unlock()
+
+	go s.run(job, fn)
+
+	s.log.Info("Submitted job %s (type: %s, user: %s, items: %d)", job.ID, jobType, userID, total)
+
+	return &snapshot
+}
+
+// Get returns a snapshot of a job of a user by ID. Jobs of other users are not found.
+func (s *JobService) Get(userID, jobID string) (*models.Job, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	job, exists := s.jobs[jobID]
+	if !exists || job.UserID != userID {
+		return nil, apperrors.ErrJobNotFound
+	}
+
+	snapshot := *job
+	return &snapshot, nil
+}
+
+// List returns snapshots of all jobs owned by a user, newest first.
+func (s *JobService) List(userID string) []*models.Job {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	jobs := make([]*models.Job, 0)
+	for _, job := range s.jobs {
+		if job.UserID != userID {
+			continue
+		}
+		snapshot := *job
+		jobs = append(jobs, &snapshot)
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.After(jobs[j].CreatedAt)
+	})
+
+	return jobs
+}
+
+// run executes a job and records its outcome.
+func (s *JobService) run(job *models.Job, fn JobFunc) {
+	s.mu.Lock()
+	now := time.Now()
+	job.Status = models.JobStatusRunning
+	job.StartedAt = &now
+	s.mu.Unlock()
+
+	progress := func(processed, total int) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		job.Processed = processed
+		job.Total = total
+	}
+
+	result, err := fn(progress)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	finished := time.Now()
+	job.FinishedAt = &finished
+	job.Result = result
+	if err != nil {
+		job.Status = models.JobStatusFailed
+		job.Error = err.Error()
+		s.log.Error("Job %s (%s) failed: %v", job.ID, job.Type, err)
+		return
+	}
+
+	job.Status = models.JobStatusCompleted
+	s.log.Info("Job %s (%s) completed in %s", job.ID, job.Type, finished.Sub(now).Round(time.Millisecond))
+}
+
+// cleanup removes finished jobs older than the TTL periodically.
+func (s *JobService) cleanup() {
+	ticker := time.NewTicker(10 * time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.mu.Lock()
+		now := time.Now()
+		for id, job := range s.jobs {
+			if job.IsFinished() && job.FinishedAt != nil && now.Sub(*job.FinishedAt) > s.ttl {
+				delete(s.jobs, id)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
@@ -0,0 +1,159 @@
+package service_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("JobService", func() {
+	var jobService *service.JobService
+
+	BeforeEach(func() {
+		jobService = service.NewJobService(time.Hour, logger.New())
+	})
+
+	status := func(userID, jobID string) func() models.JobStatus {
+		return func() models.JobStatus {
+			job, err := jobService.Get(userID, jobID)
+			Expect(err).NotTo(HaveOccurred())
+			return job.Status
+		}
+	}
+
+	It("runs a job from queued to completed, reporting its progress", func() {
+		started, release := make(chan struct{}), make(chan struct{})
+		job := jobService.Submit("user", "test", 2, func(progress service.JobProgressFunc) (interface{}, error) {
+			progress(1, 2)
+			close(started)
+			<-release
+			progress(2, 2)
+			return "done", nil
+		})
+		Expect(job.Status).To(Equal(models.JobStatusPending))
+		Expect(job.Total).To(Equal(2))
+
+		<-started
+		running, err := jobService.Get("user", job.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(running.Status).To(Equal(models.JobStatusRunning))
+		Expect(running.StartedAt).NotTo(BeNil())
+		Expect(running.Processed).To(Equal(1))
+
+		close(release)
+		Eventually(status("user", job.ID)).Should(Equal(models.JobStatusCompleted))
+		completed, err := jobService.Get("user", job.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(completed.Processed).To(Equal(2))
+		Expect(completed.Result).To(Equal("done"))
+		Expect(completed.FinishedAt).NotTo(BeNil())
+	})
+
+	It("records the error of a failed job", func() {
+		job := jobService.Submit("user", "test", 1, func(service.JobProgressFunc) (interface{}, error) {
+			return nil, errors.New("target gone")
+		})
+
+		Eventually(status("user", job.ID)).Should(Equal(models.JobStatusFailed))
+		failed, err := jobService.Get("user", job.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(failed.Error).To(Equal("target gone"))
+	})
+
+	It("returns jobs to their owner only", func() {
+		job := jobService.Submit("user", "test", 0, func(service.JobProgressFunc) (interface{}, error) {
+			return nil, nil
+		})
+
+		_, err := jobService.Get("other", job.ID)
+		Expect(err).To(MatchError(apperrors.ErrJobNotFound))
+		_, err = jobService.Get("user", "missing")
+		Expect(err).To(MatchError(apperrors.ErrJobNotFound))
+
+		Expect(jobService.List("user")).To(HaveLen(1))
+		Expect(jobService.List("other")).To(BeEmpty())
+	})
+})
+
+var _ = Describe("EventService replay of failed events", func() {
+	It("replays the failed events in a job and records their new status", func() {
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Event-Id") == "still-failing" {
+				w.WriteHeader(http.StatusBadGateway)
+			}
+		}))
+		DeferCleanup(target.Close)
+
+		baseDir := GinkgoT().TempDir()
+		log := logger.New()
+		clientRepo, err := repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		eventRepo := repository.NewFileEventRepository(baseDir)
+		jobService := service.NewJobService(time.Hour, log)
+		eventService := service.NewEventService(
+			eventRepo,
+			clientRepo,
+			jobService,
+			service.NewCircuitBreakerService(0, time.Minute, service.NewNotificationService(log), log),
+			log,
+		)
+
+		client := &models.Client{ID: "client-jobs", UserID: "user", TargetURL: target.URL, TargetTimeout: 5}
+		Expect(clientRepo.Create(client)).To(Succeed())
+		base := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+		for i, spec := range []struct {
+			id     string
+			status models.EventStatus
+		}{
+			{"recovered", models.EventStatusFailed},
+			{"delivered", models.EventStatusSuccess},
+			{"still-failing", models.EventStatusFailed},
+		} {
+			Expect(eventRepo.Save(client.ID, &models.Event{
+				ID:        spec.id,
+				ClientID:  client.ID,
+				Timestamp: base.Add(time.Duration(i) * time.Hour),
+				Status:    spec.status,
+				Headers:   map[string]string{"X-Event-Id": spec.id},
+				Payload:   `{}`,
+			})).To(Succeed())
+		}
+
+		job, err := eventService.ReplayFailed("user", client.ID, &models.EventReplayFailedRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(job.Type).To(Equal("replay-failed"))
+		Expect(job.Total).To(Equal(2))
+
+		Eventually(func() bool {
+			job, err = jobService.Get("user", job.ID)
+			Expect(err).NotTo(HaveOccurred())
+			return job.IsFinished()
+		}).Should(BeTrue())
+		Expect(job.Status).To(Equal(models.JobStatusCompleted))
+		Expect(job.Processed).To(Equal(2))
+		response, ok := job.Result.(*models.EventReplayResponse)
+		Expect(ok).To(BeTrue())
+		Expect(response.Successful).To(Equal(1))
+		Expect(response.Failed).To(Equal(1))
+
+		for id, status := range map[string]models.EventStatus{
+			"recovered":     models.EventStatusSuccess,
+			"delivered":     models.EventStatusSuccess,
+			"still-failing": models.EventStatusFailed,
+		} {
+			event, err := eventRepo.Get(client.ID, id)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(event.Status).To(Equal(status), id)
+		}
+	})
+})
@@ -0,0 +1,150 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+)
+
+// MaxLogAnnotationsPerDay limits the annotations of a client's log per day.
+const MaxLogAnnotationsPerDay = 200
+
+// logAnnotationsSuffix is the file name suffix of a day's annotations (YYYY-MM-DD.annotations.json).
+const logAnnotationsSuffix = repository.LogAnnotationsSuffix
+
+// AddAnnotation attaches a note to a log timestamp of a client. The note is stored with the
+// log of the timestamp's day.
+func (s *LogService) AddAnnotation(userID, clientID string, req *models.LogAnnotationRequest) (*models.LogAnnotation, error) {
+	note := strings.TrimSpace(req.Note)
+	if note == "" {
+		return nil, apperrors.NewInvalidInput("note cannot be empty")
+	}
+
+	date := req.Time.Local().Format("2006-01-02")
+	annotation := &models.LogAnnotation{
+		ID:        uuid.New().String(),
+		Time:      req.Time,
+		Note:      note,
+		CreatedBy: userID,
+		CreatedAt: time.Now(),
+	}
+
+	s.annotationsMu.Lock()
+	defer s.annotationsMu.Unlock()
+
+	annotations, err := s.readAnnotations(userID, clientID, date)
+	if err != nil {
+		return nil, err
+	}
+	if len(annotations) >= MaxLogAnnotationsPerDay {
+		return nil, apperrors.NewInvalidInput(fmt.Sprintf("at most %d annotations can be added per day", MaxLogAnnotationsPerDay))
+	}
+
+	annotations = append(annotations, annotation)
+	slices.SortStableFunc(annotations, func(a, b *models.LogAnnotation) int {
+		return a.Time.Compare(b.Time)
+	})
+	if err := s.writeAnnotations(userID, clientID, date, annotations); err != nil {
+		return nil, err
+	}
+	return annotation, nil
+}
+
+// ListAnnotations returns the annotations of a client's log of a day, ordered by time.
+func (s *LogService) ListAnnotations(userID, clientID, date string) ([]*models.LogAnnotation, error) {
+	s.annotationsMu.Lock()
+	defer s.annotationsMu.Unlock()
+
+	return s.readAnnotations(userID, clientID, date)
+}
+
+// ListMergedAnnotations returns the annotations of a day of several clients, ordered by
+// time. Annotations carry their client's ID.
+func (s *LogService) ListMergedAnnotations(userID string, clientIDs []string, date string) ([]*models.LogAnnotation, error) {
+	s.annotationsMu.Lock()
+	defer s.annotationsMu.Unlock()
+
+	merged := []*models.LogAnnotation{}
+	for _, clientID := range clientIDs {
+		annotations, err := s.readAnnotations(userID, clientID, date)
+		if err != nil {
+			return nil, fmt.Errorf("client %s: %w", clientID, err)
+		}
+		for _, annotation := range annotations {
+			annotation.ClientID = clientID
+			merged = append(merged, annotation)
+		}
+	}
+
+	slices.SortStableFunc(merged, func(a, b *models.LogAnnotation) int {
+		return a.Time.Compare(b.Time)
+	})
+	return merged, nil
+}
+
+// DeleteAnnotation removes an annotation from a client's log of a day.
+func (s *LogService) DeleteAnnotation(userID, clientID, date, annotationID string) error {
+	s.annotationsMu.Lock()
+	defer s.annotationsMu.Unlock()
+
+	annotations, err := s.readAnnotations(userID, clientID, date)
+	if err != nil {
+		return err
+	}
+
+	index := slices.IndexFunc(annotations, func(a *models.LogAnnotation) bool { return a.ID == annotationID })
+	if index < 0 {
+		return apperrors.ErrAnnotationNotFound
+	}
+	return s.writeAnnotations(userID, clientID, date, slices.Delete(annotations, index, index+1))
+}
+
+// ImportAnnotations stores the annotations of a client's log of a day, replacing the
+// existing ones.
+func (s *LogService) ImportAnnotations(userID, clientID, date string, annotations []*models.LogAnnotation) error {
+	s.annotationsMu.Lock()
+	defer s.annotationsMu.Unlock()
+
+	for _, annotation := range annotations {
+		annotation.ClientID = ""
+	}
+	slices.SortStableFunc(annotations, func(a, b *models.LogAnnotation) int {
+		return a.Time.Compare(b.Time)
+	})
+	return s.writeAnnotations(userID, clientID, date, annotations)
+}
+
+// checkLogDate validates the day (YYYY-MM-DD) of a client's log.
+func checkLogDate(date string) error {
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return apperrors.NewInvalidInput(fmt.Sprintf("invalid date format: %s", date))
+	}
+	return nil
+}
+
+// readAnnotations reads the annotations of a day. Must be called with annotationsMu held.
+func (s *LogService) readAnnotations(userID, clientID, date string) ([]*models.LogAnnotation, error) {
+	if err := checkLogDate(date); err != nil {
+		return nil, err
+	}
+	return s.annotations.Get(userID, clientID, date)
+}
+
+// writeAnnotations stores the annotations of a day, removing them when none are left.
+// Must be called with annotationsMu held.
+func (s *LogService) writeAnnotations(userID, clientID, date string, annotations []*models.LogAnnotation) error {
+	if err := checkLogDate(date); err != nil {
+		return err
+	}
+	return s.annotations.Save(userID, clientID, date, annotations)
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+)
+
+// logLevelPattern matches a level tag ("INF", "[ERROR]", "level=warn") among the first words
+// of a process log line.
+var logLevelPattern = regexp.MustCompile(`(?i)^(?:\S+\s+){0,2}?\[?(?:level=)?(DBG|DEBUG|INF|INFO|WRN|WARN|WARNING|ERR|ERROR|FTL|FATAL|PANIC)\]?(?:\s|:|$)`)
+
+// logLevelAliases maps level tags printed by processes to log levels.
+var logLevelAliases = map[string]string{
+	"dbg": models.LogLevelDebug, "debug": models.LogLevelDebug,
+	"inf": models.LogLevelInfo, "info": models.LogLevelInfo,
+	"wrn": models.LogLevelWarn, "warn": models.LogLevelWarn, "warning": models.LogLevelWarn,
+	"err": models.LogLevelError, "error": models.LogLevelError,
+	"ftl": models.LogLevelError, "fatal": models.LogLevelError, "panic": models.LogLevelError,
+}
+
+// Patterns of the delivery information gosmee prints when it forwards an event.
+var (
+	logStatusPattern     = regexp.MustCompile(`(?i)\bstatus(?:[ _-]?code)?\s*[=:]?\s*(\d{3})\b`)
+	logEventTypePattern  = regexp.MustCompile(`(?i)\b(?:event[ _-]?type|type)\s*[=:]\s*"?([A-Za-z0-9_.:-]+)`)
+	logDeliveryIDPattern = regexp.MustCompile(`(?i)\b(?:delivery[ _-]?id|event[ _-]?id|id)\s*[=:]\s*"?([A-Za-z0-9_.-]+)`)
+)
+
+// legacyLogTimeLayout is the timestamp layout of plain text log lines written before logs
+// were stored as records.
+const legacyLogTimeLayout = "2006-01-02 15:04:05"
+
+// newLogRecord builds the record of a line read from a process output stream at t.
+// Lines without a level tag are info on stdout and errors on stderr.
+func newLogRecord(t time.Time, source, line string) models.LogRecord {
+	record := models.LogRecord{Time: t, Source: source, Message: line, Level: models.LogLevelInfo}
+	if source == models.LogSourceStderr {
+		record.Level = models.LogLevelError
+	}
+	if match := logLevelPattern.FindStringSubmatch(line); match != nil {
+		record.Level = logLevelAliases[strings.ToLower(match[1])]
+	}
+	record.Delivery = parseLogDelivery(line)
+	return record
+}
+
+// parseLogDelivery returns the delivery a log line reports on, or nil if it reports on none.
+// A line is taken as a delivery report when it names an event type or a target status.
+func parseLogDelivery(line string) *models.LogDelivery {
+	var delivery models.LogDelivery
+	if match := logStatusPattern.FindStringSubmatch(line); match != nil {
+		delivery.StatusCode, _ = strconv.Atoi(match[1])
+	}
+	if match := logEventTypePattern.FindStringSubmatch(line); match != nil {
+		delivery.EventType = match[1]
+	}
+	if delivery.StatusCode == 0 && delivery.EventType == "" {
+		return nil
+	}
+	if match := logDeliveryIDPattern.FindStringSubmatch(line); match != nil {
+		delivery.DeliveryID = match[1]
+	}
+	return &delivery
+}
+
+// encodeLogRecord encodes a record as a line of a log file (NDJSON). The sequence number is
+// only meaningful within the running process and is not stored.
+func encodeLogRecord(record models.LogRecord) (string, error) {
+	record.Seq = 0
+	data, err := json.Marshal(record)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// decodeLogRecord decodes a line of a log file: a JSON record, or a plain text line
+// ("[2006-01-02 15:04:05] [stdout] message") written before logs were stored as records.
+func decodeLogRecord(line string) models.LogRecord {
+	if strings.HasPrefix(line, "{") {
+		var record models.LogRecord
+		if err := json.Unmarshal([]byte(line), &record); err == nil {
+			return record
+		}
+	}
+
+	if rest, ok := strings.CutPrefix(line, "["); ok {
+		if stamp, rest, ok := strings.Cut(rest, "] ["); ok {
+			if source, message, ok := strings.Cut(rest, "] "); ok && models.IsLogSource(source) {
+				if t, err := time.ParseInLocation(legacyLogTimeLayout, stamp, time.Local); err == nil {
+					return newLogRecord(t, source, message)
+				}
+			}
+		}
+	}
+	return models.LogRecord{Source: models.LogSourceStdout, Level: models.LogLevelInfo, Message: line}
+}
@@ -8,39 +8,74 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/encryption"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
 	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/masking"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
 )
 
+// MaxMergedLogClients limits how many clients' logs one request may merge.
+const MaxMergedLogClients = 20
+
 // LogService manages log files and streaming.
 type LogService struct {
 	baseDir string
 	log     logger.Logger
+	masker  MaskerFunc         // Redacts secrets from log lines (optional)
+	cipher  *encryption.Cipher // Decrypts encrypted log lines (optional)
+
+	annotations   repository.LogAnnotationRepository // Stores log annotations
+	annotationsMu sync.Mutex                         // Serializes changes of annotations
 }
 
 // NewLogService creates a new log service.
-func NewLogService(baseDir string, log logger.Logger) *LogService {
+func NewLogService(baseDir string, annotations repository.LogAnnotationRepository, log logger.Logger) *LogService {
 	return &LogService{
-		baseDir: baseDir,
-		log:     log,
+		baseDir:     baseDir,
+		annotations: annotations,
+		log:         log,
 	}
 }
 
+// SetMasker sets how secrets are redacted from log lines returned to users.
+// Lines written before masking was configured are redacted on read.
+func (s *LogService) SetMasker(masker MaskerFunc) {
+	s.masker = masker
+}
+
+// SetCipher enables transparent decryption of log lines encrypted at rest.
+func (s *LogService) SetCipher(cipher *encryption.Cipher) {
+	s.cipher = cipher
+}
+
+// maskerFor returns the masker for a user, or nil if masking is not configured.
+func (s *LogService) maskerFor(userID string) *masking.Masker {
+	if s.masker == nil {
+		return nil
+	}
+	return s.masker(userID)
+}
+
 // GetLogFile returns the path to a log file for a specific date.
 func (s *LogService) getLogFile(userID, clientID, date string) (string, error) {
 	// Validate date format
 	if _, err := time.Parse("2006-01-02", date); err != nil {
-		return "", fmt.Errorf("invalid date format: %s", date)
+		return "", apperrors.NewInvalidInput(fmt.Sprintf("invalid date format: %s", date))
 	}
 
 	logPath := filepath.Join(s.baseDir, "users", userID, "clients", clientID, "logs", fmt.Sprintf("%s.log", date))
 	return logPath, nil
 }
 
-// GetLogs retrieves log lines from a log file with pagination and search.
-func (s *LogService) GetLogs(userID, clientID, date string, page, pageSize int, search string) ([]string, int, error) {
+// GetLogs retrieves the log records of a day matching the filter, with pagination.
+func (s *LogService) GetLogs(userID, clientID, date string, page, pageSize int, filter models.LogFilter) ([]models.LogRecord, int, error) {
 	logPath, err := s.getLogFile(userID, clientID, date)
 	if err != nil {
 		return nil, 0, err
@@ -48,70 +83,209 @@ func (s *LogService) GetLogs(userID, clientID, date string, page, pageSize int,
 
 	// Check if file exists
 	if _, err := os.Stat(logPath); os.IsNotExist(err) {
-		return []string{}, 0, nil
+		return []models.LogRecord{}, 0, nil
+	}
+
+	records, err := s.readLogRecords(userID, logPath)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var matched []models.LogRecord
+	for _, record := range records {
+		if filter.Matches(record) {
+			matched = append(matched, record)
+		}
+	}
+
+	return pageLogRecords(matched, page, pageSize), len(matched), nil
+}
+
+// GetMergedLogs retrieves the log records of a day of several clients of a user matching
+// the filter, merged chronologically, with pagination. Records carry their client's ID.
+func (s *LogService) GetMergedLogs(userID string, clientIDs []string, date string, page, pageSize int, filter models.LogFilter) ([]models.LogRecord, int, error) {
+	var merged []models.LogRecord
+	for _, clientID := range clientIDs {
+		logPath, err := s.getLogFile(userID, clientID, date)
+		if err != nil {
+			return nil, 0, err
+		}
+		if _, err := os.Stat(logPath); os.IsNotExist(err) {
+			continue
+		}
+
+		records, err := s.readLogRecords(userID, logPath)
+		if err != nil {
+			return nil, 0, fmt.Errorf("client %s: %w", clientID, err)
+		}
+		for _, record := range records {
+			if filter.Matches(record) {
+				record.ClientID = clientID
+				merged = append(merged, record)
+			}
+		}
 	}
 
-	// Read log file
+	// Records of a client are in order already, records of the same time keep the client order
+	slices.SortStableFunc(merged, func(a, b models.LogRecord) int {
+		return a.Time.Compare(b.Time)
+	})
+
+	return pageLogRecords(merged, page, pageSize), len(merged), nil
+}
+
+// pageLogRecords returns a page of records.
+func pageLogRecords(records []models.LogRecord, page, pageSize int) []models.LogRecord {
+	start := (page - 1) * pageSize
+	if start >= len(records) {
+		return []models.LogRecord{}
+	}
+	return records[start:min(start+pageSize, len(records))]
+}
+
+// GetTodayLogs retrieves today's logs.
+func (s *LogService) GetTodayLogs(userID, clientID string, page, pageSize int, filter models.LogFilter) ([]models.LogRecord, int, error) {
+	today := time.Now().Format("2006-01-02")
+	return s.GetLogs(userID, clientID, today, page, pageSize, filter)
+}
+
+// readLogRecords reads, decrypts and masks all records of a log file.
+func (s *LogService) readLogRecords(userID, logPath string) ([]models.LogRecord, error) {
 	file, err := os.Open(logPath)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to open log file: %w", err)
+		return nil, fmt.Errorf("failed to open log file: %w", err)
 	}
 	defer file.Close()
 
-	var allLines []string
+	var records []models.LogRecord
 	scanner := bufio.NewScanner(file)
+	masker := s.maskerFor(userID)
 
-	// Read all lines
 	for scanner.Scan() {
-		line := scanner.Text()
-
-		// Apply search filter
-		if search != "" && !strings.Contains(strings.ToLower(line), strings.ToLower(search)) {
+		line, err := s.cipher.OpenLine(scanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt log file: %w", err)
+		}
+		if line == "" {
 			continue
 		}
 
-		allLines = append(allLines, line)
+		record := decodeLogRecord(line)
+		if masker != nil {
+			record.Message = masker.MaskText(record.Message)
+		}
+		records = append(records, record)
 	}
 
 	if err := scanner.Err(); err != nil {
-		return nil, 0, fmt.Errorf("failed to read log file: %w", err)
+		return nil, fmt.Errorf("failed to read log file: %w", err)
 	}
+	return records, nil
+}
 
-	total := len(allLines)
+// LogStream is a subscription to the real-time logs of a running client.
+type LogStream struct {
+	Backlog []models.LogRecord    // Buffered records to send before live records (when resuming)
+	Lines   chan models.LogRecord // Live records, closed when the process stops
+	Filter  models.LogFilter      // Records the subscriber wants
+	info    *models.ProcessInfo
+}
 
-	// Apply pagination
-	start := (page - 1) * pageSize
-	end := start + pageSize
-	if start >= total {
-		return []string{}, total, nil
+// Close unsubscribes from the process logs. Must be called when the SSE client disconnects.
+func (st *LogStream) Close() {
+	st.info.RemoveLogListener(st.Lines)
+}
+
+// Accepts reports whether a record matches the filter of the stream.
+func (st *LogStream) Accepts(record models.LogRecord) bool {
+	return st.Filter.Matches(record)
+}
+
+// StreamLogs subscribes to real-time logs matching the filter. A positive lastEventID resumes
+// the stream by replaying the buffered records received after it.
+func (s *LogService) StreamLogs(clientID string, lastEventID int64, filter models.LogFilter, processService *ProcessService) (*LogStream, error) {
+	// Get process info
+	processInfo, err := processService.GetProcessInfo(clientID)
+	if err != nil {
+		return nil, apperrors.NewClientNotRunning(fmt.Sprintf("client not running: %s", clientID))
 	}
-	if end > total {
-		end = total
+
+	stream := &LogStream{Filter: filter, info: processInfo}
+	if lastEventID > 0 {
+		stream.Lines, stream.Backlog = processInfo.AddLogListenerSince(lastEventID)
+	} else {
+		stream.Lines = processInfo.AddLogListener()
 	}
 
-	paged := allLines[start:end]
+	return stream, nil
+}
 
-	return paged, total, nil
+// MergedLogStream is a subscription to the real-time logs of several running clients.
+type MergedLogStream struct {
+	Clients    []string              // IDs of the streamed clients
+	NotRunning []string              // IDs of the requested clients that are not running
+	Lines      chan models.LogRecord // Live records labeled with their client ID, closed once all processes stopped
+	streams    []*LogStream
+	done       chan struct{}
 }
 
-// GetTodayLogs retrieves today's logs.
-func (s *LogService) GetTodayLogs(userID, clientID string, page, pageSize int, search string) ([]string, int, error) {
-	today := time.Now().Format("2006-01-02")
-	return s.GetLogs(userID, clientID, today, page, pageSize, search)
+// Close unsubscribes from the logs of all processes. Must be called when the SSE client
+// disconnects.
+func (st *MergedLogStream) Close() {
+	close(st.done)
+	for _, stream := range st.streams {
+		stream.Close()
+	}
 }
 
-// StreamLogs returns a channel for streaming logs in real-time.
-func (s *LogService) StreamLogs(clientID string, processService *ProcessService) (chan string, error) {
-	// Get process info
-	processInfo, err := processService.GetProcessInfo(clientID)
-	if err != nil {
-		return nil, fmt.Errorf("client not running: %s", clientID)
+// StreamMergedLogs subscribes to the real-time logs matching the filter of the running
+// clients among clientIDs, multiplexed in the order they arrive. Clients started later are
+// not picked up. It fails with CLIENT_NOT_RUNNING if none of the clients is running.
+func (s *LogService) StreamMergedLogs(clientIDs []string, filter models.LogFilter, processService *ProcessService) (*MergedLogStream, error) {
+	merged := &MergedLogStream{
+		Clients:    []string{},
+		NotRunning: []string{},
+		Lines:      make(chan models.LogRecord, 100),
+		done:       make(chan struct{}),
+	}
+	for _, clientID := range clientIDs {
+		stream, err := s.StreamLogs(clientID, 0, filter, processService)
+		if err != nil {
+			merged.NotRunning = append(merged.NotRunning, clientID)
+			continue
+		}
+		merged.Clients = append(merged.Clients, clientID)
+		merged.streams = append(merged.streams, stream)
+	}
+	if len(merged.streams) == 0 {
+		return nil, apperrors.NewClientNotRunning("none of the clients is running")
 	}
 
-	// Add log listener
-	logChan := processInfo.AddLogListener()
+	var wg sync.WaitGroup
+	for i, stream := range merged.streams {
+		clientID := merged.Clients[i]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for record := range stream.Lines {
+				if !stream.Accepts(record) {
+					continue
+				}
+				record.ClientID = clientID
+				select {
+				case merged.Lines <- record:
+				case <-merged.done:
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(merged.Lines)
+	}()
 
-	return logChan, nil
+	return merged, nil
 }
 
 // CleanupOldLogs removes log files older than retention period.
@@ -140,13 +314,16 @@ func (s *LogService) CleanupOldLogs(userID, clientID string, retentionDays int)
 			continue
 		}
 
-		// Parse date from filename (YYYY-MM-DD.log)
+		// Parse date from filename (YYYY-MM-DD.log, or YYYY-MM-DD.annotations.json for the
+		// day's annotations)
 		filename := file.Name()
-		if !strings.HasSuffix(filename, ".log") {
-			continue
+		dateStr, ok := strings.CutSuffix(filename, ".log")
+		if !ok {
+			if dateStr, ok = strings.CutSuffix(filename, logAnnotationsSuffix); !ok {
+				continue
+			}
 		}
 
-		dateStr := strings.TrimSuffix(filename, ".log")
 		fileDate, err := time.Parse("2006-01-02", dateStr)
 		if err != nil {
 			continue
@@ -166,18 +343,90 @@ func (s *LogService) CleanupOldLogs(userID, clientID string, retentionDays int)
 	return nil
 }
 
-// DownloadLog returns the full log file content for download.
-func (s *LogService) DownloadLog(userID, clientID, date string) ([]byte, error) {
+// LogDates returns the days a client has a log file of, oldest first.
+func (s *LogService) LogDates(userID, clientID string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(s.baseDir, "users", userID, "clients", clientID, "logs"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, fmt.Errorf("failed to read logs directory: %w", err)
+	}
+
+	dates := []string{}
+	for _, entry := range entries {
+		date, ok := strings.CutSuffix(entry.Name(), ".log")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err == nil {
+			dates = append(dates, date)
+		}
+	}
+	slices.Sort(dates)
+	return dates, nil
+}
+
+// ImportLog writes the records of a day to a client's log file, replacing the file and
+// encrypting the lines like the process logs.
+func (s *LogService) ImportLog(userID, clientID, date string, records []models.LogRecord) error {
+	logPath, err := s.getLogFile(userID, clientID, date)
+	if err != nil {
+		return err
+	}
+
+	var buf strings.Builder
+	for _, record := range records {
+		record.ClientID = ""
+		line, err := encodeLogRecord(record)
+		if err != nil {
+			return fmt.Errorf("failed to encode log record: %w", err)
+		}
+		if line, err = s.cipher.SealLine(line); err != nil {
+			return fmt.Errorf("failed to encrypt log record: %w", err)
+		}
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		return fmt.Errorf("failed to create logs directory: %w", err)
+	}
+	if err := os.WriteFile(logPath, []byte(buf.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write log file: %w", err)
+	}
+	return nil
+}
+
+// Log download formats.
+const (
+	LogFormatText   = "text"   // One "[2006-01-02 15:04:05] [stdout] message" line per record
+	LogFormatNDJSON = "ndjson" // One JSON record per line
+)
+
+// DownloadLog returns all records of a day's log file for download, as plain text lines or
+// as NDJSON.
+func (s *LogService) DownloadLog(userID, clientID, date, format string) ([]byte, error) {
 	logPath, err := s.getLogFile(userID, clientID, date)
 	if err != nil {
 		return nil, err
 	}
 
-	// Read entire file
-	data, err := os.ReadFile(logPath)
+	records, err := s.readLogRecords(userID, logPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read log file: %w", err)
+		return nil, err
 	}
 
-	return data, nil
+	var buf strings.Builder
+	for _, record := range records {
+		line := record.String()
+		if format == LogFormatNDJSON {
+			if line, err = encodeLogRecord(record); err != nil {
+				return nil, fmt.Errorf("failed to encode log record: %w", err)
+			}
+		}
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	return []byte(buf.String()), nil
 }
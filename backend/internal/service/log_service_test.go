@@ -0,0 +1,173 @@
+package service_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("Structured process logs", func() {
+	var (
+		baseDir    string
+		logService *service.LogService
+	)
+
+	BeforeEach(func() {
+		baseDir = GinkgoT().TempDir()
+		logsDir := filepath.Join(baseDir, "users", "user", "clients", "client", "logs")
+		Expect(os.MkdirAll(logsDir, 0755)).To(Succeed())
+		// A plain text line written before logs were stored as records, followed by records
+		Expect(os.WriteFile(filepath.Join(logsDir, "2025-10-01.log"), []byte(
+			"[2025-10-01 10:00:00] [stdout] INF connected\n"+
+				`{"time":"2025-10-01T10:00:01Z","source":"stderr","level":"error","message":"connection refused"}`+"\n"+
+				`{"time":"2025-10-01T10:00:02Z","source":"stdout","level":"info","message":"forwarded push","delivery":{"eventType":"push","statusCode":200}}`+"\n",
+		), 0644)).To(Succeed())
+
+		logService = service.NewLogService(baseDir, repository.NewFileLogAnnotationRepository(baseDir), logger.New())
+	})
+
+	It("reads records and plain text lines as records", func() {
+		records, total, err := logService.GetLogs("user", "client", "2025-10-01", 1, 100, models.LogFilter{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(total).To(Equal(3))
+		Expect(records[0].Source).To(Equal(models.LogSourceStdout))
+		Expect(records[0].Level).To(Equal(models.LogLevelInfo))
+		Expect(records[0].Message).To(Equal("INF connected"))
+		Expect(records[2].Delivery).To(Equal(&models.LogDelivery{EventType: "push", StatusCode: 200}))
+	})
+
+	It("filters records by source, level and delivery", func() {
+		records, total, err := logService.GetLogs("user", "client", "2025-10-01", 1, 100, models.LogFilter{Source: models.LogSourceStderr})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(total).To(Equal(1))
+		Expect(records[0].Message).To(Equal("connection refused"))
+
+		_, total, err = logService.GetLogs("user", "client", "2025-10-01", 1, 100, models.LogFilter{Level: models.LogLevelError})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(total).To(Equal(1))
+
+		records, _, err = logService.GetLogs("user", "client", "2025-10-01", 1, 100, models.LogFilter{Delivery: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(records).To(HaveLen(1))
+		Expect(records[0].Message).To(Equal("forwarded push"))
+	})
+
+	It("downloads records as text lines or NDJSON", func() {
+		text, err := logService.DownloadLog("user", "client", "2025-10-01", service.LogFormatText)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(strings.Split(strings.TrimSpace(string(text)), "\n")[0]).To(Equal("[2025-10-01 10:00:00] [stdout] INF connected"))
+
+		ndjson, err := logService.DownloadLog("user", "client", "2025-10-01", service.LogFormatNDJSON)
+		Expect(err).NotTo(HaveOccurred())
+		lines := strings.Split(strings.TrimSpace(string(ndjson)), "\n")
+		Expect(lines).To(HaveLen(3))
+		Expect(lines[1]).To(ContainSubstring(`"message":"connection refused"`))
+	})
+
+	It("merges the logs of several clients chronologically", func() {
+		logsDir := filepath.Join(baseDir, "users", "user", "clients", "other", "logs")
+		Expect(os.MkdirAll(logsDir, 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(logsDir, "2025-10-01.log"), []byte(
+			`{"time":"2025-10-01T10:00:00.500Z","source":"stderr","level":"warn","message":"target timeout"}`+"\n"+
+				`{"time":"2025-10-01T10:00:03Z","source":"stdout","level":"info","message":"forwarded issues"}`+"\n",
+		), 0644)).To(Succeed())
+
+		records, total, err := logService.GetMergedLogs("user", []string{"client", "other", "missing"}, "2025-10-01", 1, 100, models.LogFilter{Source: models.LogSourceStderr})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(total).To(Equal(2))
+		Expect(records[0].ClientID).To(Equal("other"))
+		Expect(records[0].Message).To(Equal("target timeout"))
+		Expect(records[1].ClientID).To(Equal("client"))
+		Expect(records[1].Message).To(Equal("connection refused"))
+
+		records, total, err = logService.GetMergedLogs("user", []string{"client", "other"}, "2025-10-01", 3, 2, models.LogFilter{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(total).To(Equal(5))
+		Expect(records).To(HaveLen(1))
+		Expect(records[0].String()).To(Equal("[other] [2025-10-01 10:00:03] [stdout] forwarded issues"))
+	})
+
+	It("keeps annotations with the log of their day", func() {
+		incident := time.Date(2025, 10, 1, 12, 0, 0, 0, time.Local)
+		_, err := logService.AddAnnotation("user", "client", &models.LogAnnotationRequest{Time: incident, Note: " incident started here "})
+		Expect(err).NotTo(HaveOccurred())
+		first, err := logService.AddAnnotation("user", "client", &models.LogAnnotationRequest{Time: incident.Add(-time.Hour), Note: "deploy"})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = logService.AddAnnotation("user", "client", &models.LogAnnotationRequest{Time: incident, Note: "  "})
+		Expect(err).To(HaveOccurred())
+
+		annotations, err := logService.ListAnnotations("user", "client", "2025-10-01")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(annotations).To(HaveLen(2))
+		Expect(annotations[0].Note).To(Equal("deploy"))
+		Expect(annotations[1].Note).To(Equal("incident started here"))
+		Expect(annotations[1].CreatedBy).To(Equal("user"))
+
+		merged, err := logService.ListMergedAnnotations("user", []string{"client", "other"}, "2025-10-01")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(merged).To(HaveLen(2))
+		Expect(merged[0].ClientID).To(Equal("client"))
+
+		Expect(logService.DeleteAnnotation("user", "client", "2025-10-01", first.ID)).To(Succeed())
+		Expect(logService.DeleteAnnotation("user", "client", "2025-10-01", first.ID)).To(MatchError(ContainSubstring("not found")))
+		annotations, err = logService.ListAnnotations("user", "client", "2025-10-01")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(annotations).To(HaveLen(1))
+
+		// Annotations expire with their log file
+		Expect(logService.CleanupOldLogs("user", "client", 1)).To(Succeed())
+		annotations, err = logService.ListAnnotations("user", "client", "2025-10-01")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(annotations).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Merged live logs", func() {
+	It("multiplexes the logs of several running clients with client labels", func() {
+		binDir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(binDir, "gosmee"), []byte("#!/bin/sh\nsleep 0.5\necho connected\nexec sleep 30\n"), 0755)).To(Succeed())
+		GinkgoT().Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+		log := logger.New()
+		processService := service.NewProcessService(false, 0, time.Minute, log)
+		baseDir := GinkgoT().TempDir()
+		for _, id := range []string{"client-a", "client-b"} {
+			client := models.NewClient(id, "user-logs", id, "", "https://smee.example.com/channel", "http://127.0.0.1:1/hook")
+			Expect(processService.Start(client, baseDir)).To(Succeed())
+			DeferCleanup(func() { processService.Stop(id) }) // Stopped by the test already unless it failed
+		}
+
+		logService := service.NewLogService(baseDir, repository.NewFileLogAnnotationRepository(baseDir), log)
+		stream, err := logService.StreamMergedLogs([]string{"client-a", "client-stopped", "client-b"}, models.LogFilter{}, processService)
+		Expect(err).NotTo(HaveOccurred())
+		defer stream.Close()
+		Expect(stream.Clients).To(Equal([]string{"client-a", "client-b"}))
+		Expect(stream.NotRunning).To(Equal([]string{"client-stopped"}))
+
+		var labels []string
+		for range 2 {
+			var record models.LogRecord
+			Eventually(stream.Lines, 5*time.Second).Should(Receive(&record))
+			Expect(record.Message).To(Equal("connected"))
+			labels = append(labels, record.ClientID)
+		}
+		Expect(labels).To(ConsistOf("client-a", "client-b"))
+
+		// The stream ends once all processes stopped
+		Expect(processService.Stop("client-a")).To(Succeed())
+		Expect(processService.Stop("client-b")).To(Succeed())
+		Eventually(stream.Lines, 5*time.Second).Should(BeClosed())
+
+		_, err = logService.StreamMergedLogs([]string{"client-stopped"}, models.LogFilter{}, processService)
+		Expect(err).To(MatchError(ContainSubstring("none of the clients is running")))
+	})
+})
@@ -0,0 +1,75 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/encryption"
+)
+
+// dailyLogWriter appends process log lines to one file per day (logs/YYYY-MM-DD.log),
+// the layout read by LogService. With a cipher, each line is encrypted.
+type dailyLogWriter struct {
+	dir    string
+	cipher *encryption.Cipher
+	mu     sync.Mutex
+	date   string
+	file   *os.File
+}
+
+// newDailyLogWriter creates a writer for the given logs directory.
+func newDailyLogWriter(dir string, cipher *encryption.Cipher) *dailyLogWriter {
+	return &dailyLogWriter{dir: dir, cipher: cipher}
+}
+
+// WriteLine appends a line to the log file of the given day, rotating files at midnight.
+func (w *dailyLogWriter) WriteLine(t time.Time, line string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	date := t.Format("2006-01-02")
+	if w.file == nil || w.date != date {
+		if w.file != nil {
+			w.file.Close()
+			w.file = nil
+		}
+
+		if err := os.MkdirAll(w.dir, 0755); err != nil {
+			return fmt.Errorf("failed to create logs directory: %w", err)
+		}
+		file, err := os.OpenFile(filepath.Join(w.dir, date+".log"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		w.file = file
+		w.date = date
+	}
+
+	line, err := w.cipher.SealLine(line)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt log line: %w", err)
+	}
+	if _, err := w.file.WriteString(line + "\n"); err != nil {
+		return fmt.Errorf("failed to write log file: %w", err)
+	}
+	return nil
+}
+
+// Close closes the current log file.
+func (w *dailyLogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
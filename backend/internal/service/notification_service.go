@@ -0,0 +1,124 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+)
+
+// maxNotificationsPerUser bounds the in-memory notification history of a user.
+const maxNotificationsPerUser = 200
+
+// adminRecipient is the recipient of notifications about the server itself, addressed to
+// all administrators. User IDs are never empty.
+const adminRecipient = ""
+
+// NotificationService keeps per-user notifications in memory.
+type NotificationService struct {
+	notifications map[string][]*models.Notification // userID -> notifications, oldest first
+	mu            sync.RWMutex
+	log           logger.Logger
+}
+
+// NewNotificationService creates a new notification service.
+func NewNotificationService(log logger.Logger) *NotificationService {
+	return &NotificationService{
+		notifications: make(map[string][]*models.Notification),
+		log:           log,
+	}
+}
+
+// Notify records a notification for a user.
+func (s *NotificationService) Notify(userID, clientID, notificationType string, level models.NotificationLevel, message string) *models.Notification {
+	notification := &models.Notification{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		ClientID:  clientID,
+		Type:      notificationType,
+		Level:     level,
+		Message:   message,
+		CreatedAt: time.Now(),
+	}
+
+	s.mu.Lock()
+	list := append(s.notifications[userID], notification)
+	if len(list) > maxNotificationsPerUser {
+		list = list[len(list)-maxNotificationsPerUser:]
+	}
+	s.notifications[userID] = list
+	s.mu.Unlock()
+
+	s.log.Info("Notification for user %s (%s): %s", userID, notificationType, message)
+
+	return notification
+}
+
+// NotifyAdmins records a notification about the server for the administrators.
+func (s *NotificationService) NotifyAdmins(notificationType string, level models.NotificationLevel, message string) *models.Notification {
+	return s.Notify(adminRecipient, "", notificationType, level, message)
+}
+
+// ListAdmin returns the notifications of the administrators, newest first.
+func (s *NotificationService) ListAdmin(unreadOnly bool) *models.NotificationListResponse {
+	return s.List(adminRecipient, unreadOnly)
+}
+
+// MarkAdminRead marks a single notification of the administrators as read.
+func (s *NotificationService) MarkAdminRead(notificationID string) error {
+	return s.MarkRead(adminRecipient, notificationID)
+}
+
+// List returns the notifications of a user, newest first.
+func (s *NotificationService) List(userID string, unreadOnly bool) *models.NotificationListResponse {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := s.notifications[userID]
+	response := &models.NotificationListResponse{
+		Notifications: make([]*models.Notification, 0, len(list)),
+	}
+
+	for i := len(list) - 1; i >= 0; i-- {
+		if !list[i].Read {
+			response.Unread++
+		} else if unreadOnly {
+			continue
+		}
+		snapshot := *list[i]
+		response.Notifications = append(response.Notifications, &snapshot)
+	}
+
+	return response
+}
+
+// MarkRead marks a single notification of a user as read.
+func (s *NotificationService) MarkRead(userID, notificationID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, notification := range s.notifications[userID] {
+		if notification.ID == notificationID {
+			notification.Read = true
+			return nil
+		}
+	}
+
+	return fmt.Errorf("notification not found: %s", notificationID)
+}
+
+// MarkAllRead marks all notifications of a user as read.
+func (s *NotificationService) MarkAllRead(userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, notification := range s.notifications[userID] {
+		notification.Read = true
+	}
+}
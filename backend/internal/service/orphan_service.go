@@ -0,0 +1,276 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+)
+
+// OrphanService detects client data no client tracks (see models.Orphan), e.g. events and
+// logs left behind by a partial delete, and cleans or re-adopts it so it no longer counts
+// against quota untracked.
+type OrphanService struct {
+	dataDir    string
+	coldDir    string // Cold storage data directory (empty = disabled)
+	clientRepo repository.ClientRepository
+	quotaRepo  repository.QuotaRepository
+	log        logger.Logger
+
+	mu sync.Mutex // Serializes cleaning and adopting orphans
+}
+
+// NewOrphanService creates a new orphan service for the data directory.
+func NewOrphanService(dataDir string, clientRepo repository.ClientRepository, quotaRepo repository.QuotaRepository, log logger.Logger) *OrphanService {
+	return &OrphanService{
+		dataDir:    dataDir,
+		clientRepo: clientRepo,
+		quotaRepo:  quotaRepo,
+		log:        log,
+	}
+}
+
+// SetColdDir makes scans include the client directories in the cold data directory.
+func (s *OrphanService) SetColdDir(coldDir string) {
+	s.coldDir = coldDir
+}
+
+// Scan lists the orphaned client directories of all users.
+func (s *OrphanService) Scan() (*models.OrphanListResponse, error) {
+	keys, err := s.clientDirs()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.OrphanListResponse{Orphans: []*models.Orphan{}}
+	for _, key := range keys {
+		orphan, err := s.inspect(key[0], key[1])
+		if err != nil {
+			return nil, err
+		}
+		if orphan != nil {
+			response.Orphans = append(response.Orphans, orphan)
+			response.TotalSize += orphan.Size
+		}
+	}
+	return response, nil
+}
+
+// Clean deletes an orphaned client directory from both data directories.
+func (s *OrphanService) Clean(userID, clientID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	orphan, err := s.find(userID, clientID)
+	if err != nil {
+		return err
+	}
+
+	for _, root := range s.roots() {
+		if err := os.RemoveAll(filepath.Join(root, orphan.Path)); err != nil {
+			return fmt.Errorf("failed to delete orphaned client directory: %w", err)
+		}
+	}
+	s.quotaRepo.InvalidateCache(userID)
+
+	s.log.Info("Deleted orphaned client directory %s (%s, %d bytes)", orphan.Path, orphan.Kind, orphan.Size)
+	return nil
+}
+
+// Adopt turns an orphaned client directory back into a client of the user owning the
+// directory, keeping its events and logs. Adopted clients are stopped.
+func (s *OrphanService) Adopt(userID, clientID string, req *models.OrphanAdoptRequest) (*models.Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	orphan, err := s.find(userID, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	var client *models.Client
+	switch orphan.Kind {
+	case models.OrphanMissingConfig:
+		if req.TargetURL != "" {
+			if err := ValidateTargetURL(req.TargetURL); err != nil {
+				return nil, apperrors.NewInvalidInput(fmt.Sprintf("invalid target URL: %v", err))
+			}
+		}
+		name := req.Name
+		if name == "" {
+			name = "Recovered " + clientID[:min(8, len(clientID))]
+		}
+		client = models.NewClient(clientID, userID, name, "Recovered from orphaned data", req.SmeeURL, req.TargetURL)
+		if err := s.clientRepo.Create(client); err != nil {
+			return nil, fmt.Errorf("failed to create client: %w", err)
+		}
+	default:
+		if client, err = readClientConfig(filepath.Join(s.dataDir, orphan.Path)); err != nil {
+			return nil, err
+		}
+		client.ID = clientID
+		client.UserID = userID
+		client.Status = models.ClientStatusStopped
+		client.PID = 0
+		client.UpdatedAt = time.Now()
+		if err := s.clientRepo.Update(client); err != nil {
+			return nil, fmt.Errorf("failed to update client: %w", err)
+		}
+	}
+	s.quotaRepo.InvalidateCache(userID)
+
+	s.log.Info("Adopted orphaned client directory %s (%s) as client %s", orphan.Path, orphan.Kind, client.Name)
+	return client, nil
+}
+
+// find returns the orphan stored in a client directory.
+func (s *OrphanService) find(userID, clientID string) (*models.Orphan, error) {
+	if !isPathSegment(userID) || !isPathSegment(clientID) {
+		return nil, apperrors.ErrOrphanNotFound
+	}
+
+	orphan, err := s.inspect(userID, clientID)
+	if err != nil {
+		return nil, err
+	}
+	if orphan == nil {
+		return nil, apperrors.ErrOrphanNotFound
+	}
+	return orphan, nil
+}
+
+// inspect checks a client directory and returns the orphan stored in it, or nil if a client
+// tracks it. Directories with a corrupt config.json are not orphans: they are reported by
+// the doctor and may be restored from a backup.
+func (s *OrphanService) inspect(userID, clientID string) (*models.Orphan, error) {
+	relDir := filepath.Join("users", userID, "clients", clientID)
+	orphan := &models.Orphan{UserID: userID, ClientID: clientID, Path: relDir}
+
+	client, err := readClientConfig(filepath.Join(s.dataDir, relDir))
+	switch {
+	case os.IsNotExist(err):
+		orphan.Kind = models.OrphanMissingConfig
+	case err != nil:
+		return nil, nil
+	case client.ID == clientID && client.UserID == userID:
+		return nil, nil
+	default:
+		orphan.Kind = models.OrphanUserMismatch
+		orphan.ConfigUserID = client.UserID
+		orphan.ConfigClientID = client.ID
+	}
+
+	for _, root := range s.roots() {
+		dir := filepath.Join(root, relDir)
+		if _, err := os.Stat(dir); err != nil {
+			continue
+		}
+		if root == s.coldDir {
+			orphan.Cold = true
+		}
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			orphan.Size += info.Size()
+			if strings.HasSuffix(path, ".json") && strings.Contains(path, string(filepath.Separator)+"events"+string(filepath.Separator)) {
+				orphan.Events++
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to inspect %s: %w", dir, err)
+		}
+	}
+	return orphan, nil
+}
+
+// clientDirs returns the user and client IDs of all client directories in both data
+// directories, sorted.
+func (s *OrphanService) clientDirs() ([][2]string, error) {
+	seen := make(map[[2]string]bool)
+	for _, root := range s.roots() {
+		usersDir := filepath.Join(root, "users")
+		users, err := os.ReadDir(usersDir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read users directory: %w", err)
+		}
+
+		for _, user := range users {
+			if !user.IsDir() {
+				continue
+			}
+			clients, err := os.ReadDir(filepath.Join(usersDir, user.Name(), "clients"))
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to read clients directory: %w", err)
+			}
+			for _, client := range clients {
+				if client.IsDir() {
+					seen[[2]string{user.Name(), client.Name()}] = true
+				}
+			}
+		}
+	}
+
+	keys := make([][2]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+	return keys, nil
+}
+
+// roots returns the data directories holding client directories.
+func (s *OrphanService) roots() []string {
+	if s.coldDir == "" {
+		return []string{s.dataDir}
+	}
+	return []string{s.dataDir, s.coldDir}
+}
+
+// readClientConfig reads the config.json of a client directory.
+func readClientConfig(clientDir string) (*models.Client, error) {
+	data, err := os.ReadFile(filepath.Join(clientDir, "config.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var client models.Client
+	if err := json.Unmarshal(data, &client); err != nil {
+		return nil, fmt.Errorf("failed to parse client config: %w", err)
+	}
+	return &client, nil
+}
+
+// isPathSegment reports whether an ID from a request can be used as a single directory name.
+func isPathSegment(id string) bool {
+	return id != "" && id != "." && id != ".." && !strings.ContainsAny(id, `/\`)
+}
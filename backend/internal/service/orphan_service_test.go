@@ -0,0 +1,96 @@
+package service_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("OrphanService", func() {
+	var (
+		baseDir       string
+		coldDir       string
+		clientRepo    *repository.FileClientRepository
+		orphanService *service.OrphanService
+	)
+
+	writeFile := func(root, rel, content string) {
+		path := filepath.Join(root, rel)
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		Expect(os.WriteFile(path, []byte(content), 0644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		baseDir = GinkgoT().TempDir()
+		coldDir = GinkgoT().TempDir()
+		var err error
+		clientRepo, err = repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		quotaRepo := repository.NewFileQuotaRepository(baseDir, 1<<30, 10)
+		orphanService = service.NewOrphanService(baseDir, clientRepo, quotaRepo, logger.New())
+		orphanService.SetColdDir(coldDir)
+
+		Expect(clientRepo.Create(&models.Client{ID: "tracked", UserID: "alice"})).To(Succeed())
+		writeFile(baseDir, "users/alice/clients/deleted/events/2025-10-01/a.json", `{}`)
+		writeFile(baseDir, "users/alice/clients/deleted/logs/gosmee.log", "log")
+		writeFile(coldDir, "users/bob/clients/cold/events/2025-01-01/b.json", `{}`)
+		writeFile(baseDir, "users/bob/clients/moved/config.json", `{"id":"moved","userId":"carol"}`)
+	})
+
+	It("reports client directories no client tracks", func() {
+		response, err := orphanService.Scan()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.Orphans).To(HaveLen(3))
+
+		deleted, cold, moved := response.Orphans[0], response.Orphans[1], response.Orphans[2]
+		Expect(deleted.ClientID).To(Equal("deleted"))
+		Expect(deleted.Kind).To(Equal(models.OrphanMissingConfig))
+		Expect(deleted.Events).To(Equal(1))
+		Expect(deleted.Size).To(Equal(int64(5)))
+		Expect(cold.ClientID).To(Equal("cold"))
+		Expect(cold.Cold).To(BeTrue())
+		Expect(moved.Kind).To(Equal(models.OrphanUserMismatch))
+		Expect(moved.ConfigUserID).To(Equal("carol"))
+		Expect(response.TotalSize).To(Equal(deleted.Size + cold.Size + moved.Size))
+	})
+
+	It("cleans orphans from both data directories", func() {
+		Expect(orphanService.Clean("bob", "cold")).To(Succeed())
+		Expect(filepath.Join(coldDir, "users/bob/clients/cold")).NotTo(BeADirectory())
+
+		err := orphanService.Clean("alice", "tracked")
+		var appErr *apperrors.AppError
+		Expect(errors.As(err, &appErr)).To(BeTrue())
+		Expect(appErr.Code).To(Equal(apperrors.CodeOrphanNotFound))
+		Expect(orphanService.Clean("alice", "..")).NotTo(Succeed())
+		Expect(filepath.Join(baseDir, "users/alice/clients/tracked")).To(BeADirectory())
+	})
+
+	It("re-adopts orphans as stopped clients of the directory owner", func() {
+		client, err := orphanService.Adopt("alice", "deleted", &models.OrphanAdoptRequest{TargetURL: "http://localhost:3000"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(client.Name).To(Equal("Recovered deleted"))
+		Expect(client.Status).To(Equal(models.ClientStatusStopped))
+
+		client, err = orphanService.Adopt("bob", "moved", &models.OrphanAdoptRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(client.UserID).To(Equal("bob"))
+
+		clients, err := clientRepo.GetByUserID("alice")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(clients).To(HaveLen(2))
+		response, err := orphanService.Scan()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.Orphans).To(HaveLen(1))
+		Expect(filepath.Join(baseDir, "users/alice/clients/deleted/events/2025-10-01/a.json")).To(BeARegularFile())
+	})
+})
@@ -0,0 +1,123 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+)
+
+// Payload limit policies.
+const (
+	PayloadLimitTruncate = "truncate" // Store the beginning of oversized payloads
+	PayloadLimitReject   = "reject"   // Do not store events with oversized payloads
+)
+
+// PayloadLimiter enforces the maximum stored payload size of events, so a single huge
+// webhook can't use up a user's storage quota. Oversized payloads are either truncated,
+// keeping the original size on the event, or the event is not stored at all.
+type PayloadLimiter struct {
+	maxSize   int
+	policy    string
+	eventRepo repository.EventRepository
+	log       logger.Logger
+
+	notificationService *NotificationService // Notifies users about rejected events (optional)
+}
+
+// NewPayloadLimiter creates a new payload limiter. A zero maxSize disables the limit.
+func NewPayloadLimiter(maxSize int, policy string, eventRepo repository.EventRepository, log logger.Logger) *PayloadLimiter {
+	return &PayloadLimiter{
+		maxSize:   maxSize,
+		policy:    policy,
+		eventRepo: eventRepo,
+		log:       log,
+	}
+}
+
+// SetNotificationService notifies users when events received by gosmee are rejected.
+func (l *PayloadLimiter) SetNotificationService(notificationService *NotificationService) {
+	l.notificationService = notificationService
+}
+
+// Exceeds reports whether a payload is larger than the maximum stored payload size.
+func (l *PayloadLimiter) Exceeds(payload string) bool {
+	return l.maxSize > 0 && len(payload) > l.maxSize
+}
+
+// Rejects reports whether an event with the payload must not be stored.
+func (l *PayloadLimiter) Rejects(payload string) bool {
+	return l.policy == PayloadLimitReject && l.Exceeds(payload)
+}
+
+// Limit applies the limit to an event about to be stored. Oversized payloads are truncated,
+// or rejected with a request too large error.
+func (l *PayloadLimiter) Limit(event *models.Event) error {
+	if !l.Exceeds(event.Payload) {
+		return nil
+	}
+	if l.policy == PayloadLimitReject {
+		return apperrors.New(apperrors.CodeRequestTooLarge,
+			fmt.Sprintf("Event payload of %d bytes exceeds the maximum stored payload size", len(event.Payload)),
+			http.StatusRequestEntityTooLarge).WithDetails(map[string]int{"limit": l.maxSize})
+	}
+
+	l.truncate(event)
+	return nil
+}
+
+// Enforce applies the limit to an event gosmee stored: an oversized payload is truncated in
+// place, or the event is deleted and the user notified. It returns whether the event was kept.
+func (l *PayloadLimiter) Enforce(userID, clientID, eventID string) bool {
+	event, err := l.eventRepo.Get(clientID, eventID)
+	if err != nil || !l.Exceeds(event.Payload) {
+		return err == nil
+	}
+	size := len(event.Payload)
+
+	if l.policy == PayloadLimitReject {
+		if err := l.eventRepo.Delete(clientID, eventID); err != nil {
+			l.log.Error("Failed to delete event %s with oversized payload: %v", eventID, err)
+			return true
+		}
+		l.log.Info("Rejected event %s of client %s: payload of %d bytes exceeds %d bytes", eventID, clientID, size, l.maxSize)
+		if l.notificationService != nil {
+			l.notificationService.Notify(userID, clientID, "payload_rejected", models.NotificationLevelWarning,
+				fmt.Sprintf("Event %s was not stored: its payload of %d bytes exceeds the maximum of %d bytes", eventID, size, l.maxSize))
+		}
+		return false
+	}
+
+	l.truncate(event)
+	if err := l.eventRepo.Save(clientID, event); err != nil {
+		l.log.Error("Failed to store truncated payload of event %s: %v", eventID, err)
+		return true
+	}
+	l.log.Info("Truncated payload of event %s of client %s from %d to %d bytes", eventID, clientID, size, l.maxSize)
+	return true
+}
+
+// truncate cuts a payload to the maximum size, without splitting a UTF-8 character or a
+// base64 quantum, and records the original size on the event.
+func (l *PayloadLimiter) truncate(event *models.Event) {
+	event.OriginalPayloadSize = len(event.Payload)
+	cut := l.maxSize
+	if event.PayloadEncoding == models.PayloadEncodingBase64 {
+		if data, err := event.PayloadBytes(); err == nil {
+			event.OriginalPayloadSize = len(data)
+		}
+		cut -= cut % 4
+	}
+	for cut > 0 && !utf8.RuneStart(event.Payload[cut]) {
+		cut--
+	}
+	event.PayloadTruncated = true
+	event.Payload = event.Payload[:cut]
+}
@@ -0,0 +1,113 @@
+package service_test
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("PayloadLimiter", func() {
+	var (
+		eventsDir           string
+		client              *models.Client
+		eventRepo           *repository.FileEventRepository
+		eventService        *service.EventService
+		notificationService *service.NotificationService
+		newLimiter          func(policy string) *service.PayloadLimiter
+	)
+
+	BeforeEach(func() {
+		baseDir := GinkgoT().TempDir()
+		log := logger.New()
+		clientRepo, err := repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		eventRepo = repository.NewFileEventRepository(baseDir)
+		notificationService = service.NewNotificationService(log)
+		eventService = service.NewEventService(
+			eventRepo,
+			clientRepo,
+			service.NewJobService(time.Hour, log),
+			service.NewCircuitBreakerService(0, time.Minute, notificationService, log),
+			log,
+		)
+		newLimiter = func(policy string) *service.PayloadLimiter {
+			limiter := service.NewPayloadLimiter(10, policy, eventRepo, log)
+			limiter.SetNotificationService(notificationService)
+			eventService.SetPayloadLimiter(limiter)
+			return limiter
+		}
+
+		client = &models.Client{ID: "client-limit", UserID: "user-limit", TargetURL: "http://127.0.0.1:1", TargetTimeout: 1}
+		Expect(clientRepo.Create(client)).To(Succeed())
+		eventsDir = filepath.Join(baseDir, "users", client.UserID, "clients", client.ID, "events")
+		Expect(os.MkdirAll(eventsDir, 0755)).To(Succeed())
+	})
+
+	It("truncates injected payloads without splitting characters and refuses to replay them", func() {
+		newLimiter(service.PayloadLimitTruncate)
+
+		response, err := eventService.Inject(client.ID, &models.EventInjectRequest{Payload: []byte(`"123456789äöü"`)})
+		Expect(err).NotTo(HaveOccurred())
+
+		stored, err := eventRepo.Get(client.ID, response.Event.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored.Payload).To(Equal("123456789"))
+		Expect(stored.PayloadTruncated).To(BeTrue())
+		Expect(stored.OriginalPayloadSize).To(Equal(15))
+
+		replay, err := eventService.Replay(client.ID, &models.EventReplayRequest{EventIDs: []string{stored.ID}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(replay.Results[0].Success).To(BeFalse())
+		Expect(replay.Results[0].ErrorMessage).To(ContainSubstring("truncated from 15 bytes"))
+	})
+
+	It("rejects injected events with oversized payloads", func() {
+		newLimiter(service.PayloadLimitReject)
+
+		_, err := eventService.Inject(client.ID, &models.EventInjectRequest{Payload: []byte(`{"ref":"refs/heads/main"}`)})
+		var appErr *apperrors.AppError
+		Expect(errors.As(err, &appErr)).To(BeTrue())
+		Expect(appErr.StatusCode).To(Equal(http.StatusRequestEntityTooLarge))
+
+		_, err = eventService.Inject(client.ID, &models.EventInjectRequest{Payload: []byte(`{}`)})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("truncates oversized events stored by gosmee", func() {
+		limiter := newLimiter(service.PayloadLimitTruncate)
+		Expect(os.WriteFile(filepath.Join(eventsDir, "big.json"), []byte(`{"ref":"refs/heads/main"}`), 0644)).To(Succeed())
+
+		Expect(limiter.Enforce(client.UserID, client.ID, "big")).To(BeTrue())
+
+		stored, err := eventRepo.Get(client.ID, "big")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored.Payload).To(HaveLen(10))
+		Expect(stored.OriginalPayloadSize).To(Equal(25))
+	})
+
+	It("deletes oversized events stored by gosmee and notifies the user", func() {
+		limiter := newLimiter(service.PayloadLimitReject)
+		Expect(os.WriteFile(filepath.Join(eventsDir, "big.json"), []byte(strings.Repeat("x", 11)), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(eventsDir, "small.json"), []byte(`{}`), 0644)).To(Succeed())
+
+		Expect(limiter.Enforce(client.UserID, client.ID, "big")).To(BeFalse())
+		Expect(limiter.Enforce(client.UserID, client.ID, "small")).To(BeTrue())
+
+		Expect(filepath.Join(eventsDir, "big.json")).NotTo(BeAnExistingFile())
+		list := notificationService.List(client.UserID, false)
+		Expect(list.Notifications).To(HaveLen(1))
+		Expect(list.Notifications[0].Type).To(Equal("payload_rejected"))
+	})
+})
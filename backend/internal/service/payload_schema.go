@@ -0,0 +1,88 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/jsonschema"
+)
+
+// PayloadSchemas are the compiled payload schemas of a client (see models.PayloadSchemaConfig).
+type PayloadSchemas struct {
+	defaultSchema *jsonschema.Schema
+	eventTypes    map[string]*jsonschema.Schema
+}
+
+// CompilePayloadSchemas compiles the payload schemas of a client. It returns nil if the
+// configuration has no schemas.
+func CompilePayloadSchemas(config *models.PayloadSchemaConfig) (*PayloadSchemas, error) {
+	if config == nil || (len(config.Default) == 0 && len(config.EventTypes) == 0) {
+		return nil, nil
+	}
+	if len(config.EventTypes) > models.MaxPayloadSchemaEventTypes {
+		return nil, fmt.Errorf("at most %d event types can have a schema", models.MaxPayloadSchemaEventTypes)
+	}
+	if config.Size() > models.MaxPayloadSchemaSize {
+		return nil, fmt.Errorf("schemas must not exceed %d bytes in total", models.MaxPayloadSchemaSize)
+	}
+
+	schemas := &PayloadSchemas{eventTypes: make(map[string]*jsonschema.Schema, len(config.EventTypes))}
+	if len(config.Default) > 0 {
+		schema, err := jsonschema.Compile(config.Default)
+		if err != nil {
+			return nil, fmt.Errorf("default schema: %w", err)
+		}
+		schemas.defaultSchema = schema
+	}
+	for eventType, data := range config.EventTypes {
+		if eventType == "" {
+			return nil, fmt.Errorf("event type schemas need an event type")
+		}
+		schema, err := jsonschema.Compile(data)
+		if err != nil {
+			return nil, fmt.Errorf("schema of event type %q: %w", eventType, err)
+		}
+		schemas.eventTypes[eventType] = schema
+	}
+
+	return schemas, nil
+}
+
+// Check validates the payload of an event against the schema of its event type, or the
+// default schema, and marks the event with the outcome. It reports whether the event has a
+// schema and the outcome changed, i.e. whether the event needs to be saved. Truncated
+// payloads are not validated.
+func (p *PayloadSchemas) Check(event *models.Event) bool {
+	if event.PayloadTruncated {
+		return false
+	}
+	schema, ok := p.eventTypes[event.EventType]
+	if !ok {
+		schema = p.defaultSchema
+	}
+	if schema == nil {
+		return false
+	}
+
+	status, messages := models.SchemaStatusValid, []string(nil)
+	payload, err := event.PayloadBytes()
+	if err != nil || event.PayloadEncoding == models.PayloadEncodingBase64 {
+		status, messages = models.SchemaStatusInvalid, []string{"$: payload is not valid JSON"}
+	} else if errs := schema.Validate(payload); len(errs) > 0 {
+		status = models.SchemaStatusInvalid
+		for _, err := range errs {
+			messages = append(messages, err.Error())
+		}
+	}
+
+	if event.SchemaStatus == status && slices.Equal(event.SchemaErrors, messages) {
+		return false
+	}
+	event.SchemaStatus = status
+	event.SchemaErrors = messages
+	return true
+}
@@ -0,0 +1,111 @@
+package service_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("Payload schema validation", func() {
+	var (
+		target       *httptest.Server
+		clientRepo   repository.ClientRepository
+		eventRepo    *repository.FileEventRepository
+		eventService *service.EventService
+		client       *models.Client
+	)
+
+	BeforeEach(func() {
+		target = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		DeferCleanup(target.Close)
+
+		baseDir := GinkgoT().TempDir()
+		log := logger.New()
+		var err error
+		clientRepo, err = repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		eventRepo = repository.NewFileEventRepository(baseDir)
+		eventService = service.NewEventService(
+			eventRepo,
+			clientRepo,
+			service.NewJobService(time.Hour, log),
+			service.NewCircuitBreakerService(0, time.Minute, service.NewNotificationService(log), log),
+			log,
+		)
+
+		client = &models.Client{
+			ID:            "client-schema",
+			UserID:        "user-schema",
+			TargetURL:     target.URL,
+			TargetTimeout: 5,
+			Version:       1,
+			PayloadSchema: &models.PayloadSchemaConfig{
+				Default: json.RawMessage(`{"type":"object","required":["action"]}`),
+				EventTypes: map[string]json.RawMessage{
+					"ping": json.RawMessage(`{"type":"object","required":["zen"]}`),
+				},
+			},
+		}
+		Expect(clientRepo.Create(client)).To(Succeed())
+	})
+
+	It("marks injected events with the outcome of the event type or default schema", func() {
+		response, err := eventService.Inject(client.ID, &models.EventInjectRequest{EventType: "ping", Payload: []byte(`{"zen":"Keep it logically awesome."}`)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.Event.SchemaStatus).To(Equal(models.SchemaStatusValid))
+
+		response, err = eventService.Inject(client.ID, &models.EventInjectRequest{EventType: "push", Payload: []byte(`{"ref":"main"}`)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.Event.SchemaStatus).To(Equal(models.SchemaStatusInvalid))
+		Expect(response.Event.SchemaErrors).To(Equal([]string{`$: missing required property "action"`}))
+
+		list, err := eventService.List(client.ID, &models.EventListRequest{Page: 1, PageSize: 20, SchemaStatus: string(models.SchemaStatusInvalid)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.Events).To(HaveLen(1))
+		Expect(list.Events[0].ID).To(Equal(response.Event.ID))
+	})
+
+	It("validates replayed events against the current schema", func() {
+		Expect(eventRepo.Save(client.ID, &models.Event{
+			ID:        "event-1",
+			ClientID:  client.ID,
+			Timestamp: time.Now().UTC(),
+			EventType: "push",
+			Payload:   `{"ref":"main"}`,
+		})).To(Succeed())
+
+		response, err := eventService.Replay(client.ID, &models.EventReplayRequest{EventIDs: []string{"event-1"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.Results[0].Success).To(BeTrue())
+		Expect(response.Results[0].SchemaStatus).To(Equal(models.SchemaStatusInvalid))
+
+		client.PayloadSchema.Default = json.RawMessage(`{"type":"object"}`)
+		client.Version++
+		Expect(clientRepo.Update(client)).To(Succeed())
+
+		response, err = eventService.Replay(client.ID, &models.EventReplayRequest{EventIDs: []string{"event-1"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.Results[0].SchemaStatus).To(Equal(models.SchemaStatusValid))
+
+		event, err := eventRepo.Get(client.ID, "event-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(event.SchemaStatus).To(Equal(models.SchemaStatusValid))
+		Expect(event.SchemaErrors).To(BeEmpty())
+	})
+
+	It("rejects schemas that don't compile", func() {
+		_, err := service.CompilePayloadSchemas(&models.PayloadSchemaConfig{
+			EventTypes: map[string]json.RawMessage{"push": json.RawMessage(`{"type":"text"}`)},
+		})
+		Expect(err).To(MatchError(ContainSubstring(`schema of event type "push"`)))
+	})
+})
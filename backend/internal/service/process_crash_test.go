@@ -0,0 +1,161 @@
+package service_test
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("Process crash handling", func() {
+	// installFakeGosmee puts a fake gosmee binary running the given shell script first on PATH.
+	installFakeGosmee := func(script string) {
+		binDir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(binDir, "gosmee"), []byte(script), 0755)).To(Succeed())
+		GinkgoT().Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	}
+
+	It("stops auto-restarting a crash-looping client", func() {
+		installFakeGosmee("#!/bin/sh\nexit 3\n")
+
+		processService := service.NewProcessService(true, 1, time.Minute, logger.New())
+
+		var (
+			mu    sync.Mutex
+			exits []service.ProcessExit
+		)
+		processService.SetExitHandler(func(exit *service.ProcessExit) {
+			mu.Lock()
+			defer mu.Unlock()
+			exits = append(exits, *exit)
+		})
+
+		client := &models.Client{
+			ID:        "client-crash",
+			UserID:    "user-crash",
+			SmeeURL:   "https://smee.example.com/channel",
+			TargetURL: "http://127.0.0.1:1/hook",
+		}
+		Expect(processService.Start(client, GinkgoT().TempDir())).To(Succeed())
+
+		recorded := func() []service.ProcessExit {
+			mu.Lock()
+			defer mu.Unlock()
+			return append([]service.ProcessExit(nil), exits...)
+		}
+
+		Eventually(recorded, 10*time.Second, 50*time.Millisecond).Should(HaveLen(2))
+
+		result := recorded()
+		Expect(result[0].Restarting).To(BeTrue())
+		Expect(result[0].ExitCode).To(Equal(3))
+		Expect(result[1].CrashLooping).To(BeTrue())
+		Expect(result[1].Error).To(ContainSubstring("crash-looping"))
+		Expect(processService.IsRunning(client.ID)).To(BeFalse())
+	})
+
+	// buildClientService wires a client service around a process service with a short startup check.
+	buildClientService := func(readyPattern *regexp.Regexp) (*service.ClientService, repository.ClientRepository) {
+		baseDir := GinkgoT().TempDir()
+		log := logger.New()
+		clientRepo, err := repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		processService := service.NewProcessService(false, 0, time.Minute, log)
+		processService.SetStartupCheck(200*time.Millisecond, readyPattern, 500*time.Millisecond)
+		clientService := service.NewClientService(
+			clientRepo,
+			repository.NewFileQuotaRepository(baseDir, 10*1024*1024, 10),
+			repository.NewFileEventRepository(baseDir),
+			processService,
+			service.NewJobService(time.Hour, log),
+			baseDir,
+			log,
+		)
+		return clientService, clientRepo
+	}
+
+	It("surfaces the crash reason and exit code on the client until it is started again", func() {
+		// Crashes on the first run only
+		installFakeGosmee("#!/bin/sh\n[ -f \"$0.ran\" ] && exec sleep 30\ntouch \"$0.ran\"\nexit 3\n")
+
+		clientService, clientRepo := buildClientService(nil)
+
+		client := models.NewClient("client-exit", "user-exit", "exit", "", "https://smee.example.com/channel", "http://127.0.0.1:1/hook")
+		Expect(clientRepo.Create(client)).To(Succeed())
+		Expect(clientService.Start(client.ID)).To(MatchError(ContainSubstring("process exited during startup")))
+
+		Eventually(func() models.ClientStatus {
+			stored, err := clientService.Get(client.ID)
+			Expect(err).NotTo(HaveOccurred())
+			return stored.Status
+		}, 5*time.Second, 50*time.Millisecond).Should(Equal(models.ClientStatusError))
+
+		stored, err := clientService.Get(client.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored.LastError).To(ContainSubstring("exit status 3"))
+		Expect(stored.LastExitCode).NotTo(BeNil())
+		Expect(*stored.LastExitCode).To(Equal(3))
+
+		Expect(clientService.Start(client.ID)).To(Succeed())
+		restarted, err := clientRepo.Get(client.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(restarted.LastExitCode).To(BeNil())
+		Expect(restarted.LastError).To(BeEmpty())
+		Expect(clientService.Stop(client.ID)).To(Succeed())
+	})
+
+	It("fails the start when the connection is not reported in time", func() {
+		installFakeGosmee("#!/bin/sh\necho 'connecting...'\nexec sleep 30\n")
+
+		clientService, clientRepo := buildClientService(regexp.MustCompile(`Forwarding`))
+
+		client := models.NewClient("client-ready", "user-ready", "ready", "", "https://smee.example.com/channel", "http://127.0.0.1:1/hook")
+		Expect(clientRepo.Create(client)).To(Succeed())
+		Expect(clientService.Start(client.ID)).To(MatchError(ContainSubstring("connection not established")))
+
+		stored, err := clientService.Get(client.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored.Status).To(Equal(models.ClientStatusError))
+		Expect(stored.LastError).To(ContainSubstring("connection not established"))
+	})
+
+	It("classifies why the process failed", func() {
+		installFakeGosmee("#!/bin/sh\necho 'dial tcp: lookup smee.example.com on 127.0.0.11:53: no such host' >&2\nexit 1\n")
+
+		clientService, clientRepo := buildClientService(nil)
+
+		client := models.NewClient("client-dns", "user-exit", "dns", "", "https://smee.example.com/channel", "http://127.0.0.1:1/hook")
+		Expect(clientRepo.Create(client)).To(Succeed())
+		Expect(clientService.Start(client.ID)).NotTo(Succeed())
+
+		Eventually(func() string {
+			stored, err := clientService.Get(client.ID)
+			Expect(err).NotTo(HaveOccurred())
+			return stored.LastErrorCategory
+		}, 5*time.Second, 50*time.Millisecond).Should(Equal(models.ClientErrorDNS))
+	})
+
+	It("reports a missing gosmee binary", func() {
+		GinkgoT().Setenv("PATH", GinkgoT().TempDir())
+
+		clientService, clientRepo := buildClientService(nil)
+
+		client := models.NewClient("client-missing", "user-exit", "missing", "", "https://smee.example.com/channel", "http://127.0.0.1:1/hook")
+		Expect(clientRepo.Create(client)).To(Succeed())
+		Expect(clientService.Start(client.ID)).To(MatchError(ContainSubstring("executable file not found")))
+
+		stored, err := clientService.Get(client.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored.Status).To(Equal(models.ClientStatusError))
+		Expect(stored.LastErrorCategory).To(Equal(models.ClientErrorBinaryMissing))
+	})
+})
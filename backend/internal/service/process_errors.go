@@ -0,0 +1,66 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+)
+
+// processErrorLogLines is how many of the last log lines of a failed process are searched
+// for the cause of the failure.
+const processErrorLogLines = 20
+
+// processErrorPatterns maps error categories to the (lowercase) messages of the Go runtime
+// and gosmee that identify them, checked in order.
+var processErrorPatterns = []struct {
+	category string
+	patterns []string
+}{
+	{models.ClientErrorBinaryMissing, []string{"executable file not found", "exec: \"gosmee\""}},
+	{models.ClientErrorDNS, []string{"no such host", "server misbehaving", "temporary failure in name resolution"}},
+	{models.ClientErrorInvalidSmeeURL, []string{"unsupported protocol scheme", "missing protocol scheme", "invalid url", "invalid uri", "404 not found"}},
+	{models.ClientErrorTargetRefused, []string{"connection refused"}},
+}
+
+// classifyProcessError sorts the failure of a client's gosmee process into a user-readable
+// category, from the error message and the process's last log lines. A refused connection
+// naming the smee server's host means the smee server is unreachable, any other the target.
+func classifyProcessError(client *models.Client, message string, logLines []string) string {
+	if len(logLines) > processErrorLogLines {
+		logLines = logLines[len(logLines)-processErrorLogLines:]
+	}
+	text := strings.ToLower(message + "\n" + strings.Join(logLines, "\n"))
+
+	for _, entry := range processErrorPatterns {
+		for _, pattern := range entry.patterns {
+			if !strings.Contains(text, pattern) {
+				continue
+			}
+			if entry.category == models.ClientErrorTargetRefused && refusedBySmeeServer(client, text) {
+				return models.ClientErrorSmeeUnreachable
+			}
+			return entry.category
+		}
+	}
+	return models.ClientErrorUnknown
+}
+
+// refusedBySmeeServer reports whether the refused connection in text was made to the smee
+// server rather than the target.
+func refusedBySmeeServer(client *models.Client, text string) bool {
+	smee, err := url.Parse(client.SmeeURL)
+	if err != nil || smee.Hostname() == "" {
+		return false
+	}
+	host := strings.ToLower(smee.Hostname())
+	for _, line := range strings.Split(text, "\n") {
+		if strings.Contains(line, "connection refused") && strings.Contains(line, host) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"time"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+)
+
+// defaultLastRunRetention is how long the logs of a stopped process are kept by default.
+const defaultLastRunRetention = 15 * time.Minute
+
+// SetLastRunRetention sets how many log lines of a stopped process are kept in memory and
+// for how long. A zero retention disables keeping them.
+func (s *ProcessService) SetLastRunRetention(lines int, retention time.Duration) {
+	s.lastRunMu.Lock()
+	defer s.lastRunMu.Unlock()
+
+	s.lastRunLines = lines
+	s.lastRunRetention = retention
+	if retention <= 0 {
+		s.lastRuns = make(map[string]*models.ProcessLastRun)
+	}
+}
+
+// retainLastRun keeps the last log lines of an exited process, so users can see why it
+// stopped after its buffer is gone. exit is nil for processes stopped on request.
+func (s *ProcessService) retainLastRun(ctx *processContext, exit *ProcessExit) {
+	s.lastRunMu.Lock()
+	defer s.lastRunMu.Unlock()
+
+	if s.lastRunRetention <= 0 {
+		return
+	}
+
+	lines := ctx.processInfo.GetLogRecords()
+	if len(lines) > s.lastRunLines {
+		lines = lines[len(lines)-s.lastRunLines:]
+	}
+
+	now := time.Now()
+	run := &models.ProcessLastRun{
+		ClientID:  ctx.client.ID,
+		PID:       ctx.processInfo.PID,
+		StartedAt: ctx.processInfo.StartedAt,
+		StoppedAt: now,
+		ExitCode:  ctx.exitCode(),
+		Logs:      lines,
+		ExpiresAt: now.Add(s.lastRunRetention),
+	}
+	if exit != nil {
+		run.Crashed = true
+		run.Error = exit.Error
+	}
+
+	s.pruneLastRunsLocked(now)
+	s.lastRuns[ctx.client.ID] = run
+}
+
+// GetLastRun returns the last log records of the client's most recently stopped process, while
+// they are retained, keeping only the records matching the filter.
+func (s *ProcessService) GetLastRun(clientID string, filter models.LogFilter) (*models.ProcessLastRun, error) {
+	s.lastRunMu.Lock()
+	defer s.lastRunMu.Unlock()
+
+	s.pruneLastRunsLocked(time.Now())
+	run, ok := s.lastRuns[clientID]
+	if !ok {
+		return nil, apperrors.ErrLastRunNotFound
+	}
+	if filter == (models.LogFilter{}) {
+		return run, nil
+	}
+
+	filtered := *run
+	filtered.Logs = []models.LogRecord{}
+	for _, record := range run.Logs {
+		if filter.Matches(record) {
+			filtered.Logs = append(filtered.Logs, record)
+		}
+	}
+	return &filtered, nil
+}
+
+// pruneLastRunsLocked drops expired last runs. Caller must hold lastRunMu.
+func (s *ProcessService) pruneLastRunsLocked(now time.Time) {
+	for clientID, run := range s.lastRuns {
+		if !now.Before(run.ExpiresAt) {
+			delete(s.lastRuns, clientID)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package service_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("Last-run process logs", func() {
+	var (
+		processService *service.ProcessService
+		client         *models.Client
+	)
+
+	BeforeEach(func() {
+		binDir := GinkgoT().TempDir()
+		script := "#!/bin/sh\nfor i in 1 2 3 4 5; do echo \"line $i\"; done\nsleep 0.2\necho \"connection refused\" >&2\nexit 2\n"
+		Expect(os.WriteFile(filepath.Join(binDir, "gosmee"), []byte(script), 0755)).To(Succeed())
+		GinkgoT().Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+		processService = service.NewProcessService(false, 0, time.Minute, logger.New())
+		client = &models.Client{
+			ID:        "client-last-run",
+			UserID:    "user-last-run",
+			SmeeURL:   "https://smee.example.com/channel",
+			TargetURL: "http://127.0.0.1:1/hook",
+		}
+	})
+
+	It("keeps the last log lines of a crashed process", func() {
+		processService.SetLastRunRetention(3, time.Minute)
+		Expect(processService.Start(client, GinkgoT().TempDir())).To(Succeed())
+
+		Eventually(func() error {
+			_, err := processService.GetLastRun(client.ID, models.LogFilter{})
+			return err
+		}, 5*time.Second, 50*time.Millisecond).Should(Succeed())
+
+		run, err := processService.GetLastRun(client.ID, models.LogFilter{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(run.Crashed).To(BeTrue())
+		Expect(run.ExitCode).To(Equal(2))
+		Expect(run.Logs).To(HaveLen(3))
+		Expect(run.Logs[len(run.Logs)-1].Message).To(ContainSubstring("connection refused"))
+
+		stderr, err := processService.GetLastRun(client.ID, models.LogFilter{Source: models.LogSourceStderr})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stderr.Logs).To(HaveLen(1))
+		Expect(stderr.Logs[0].Message).To(ContainSubstring("connection refused"))
+		Expect(processService.IsRunning(client.ID)).To(BeFalse())
+	})
+
+	It("drops the lines after the retention period", func() {
+		processService.SetLastRunRetention(10, 300*time.Millisecond)
+		Expect(processService.Start(client, GinkgoT().TempDir())).To(Succeed())
+
+		Eventually(func() error {
+			_, err := processService.GetLastRun(client.ID, models.LogFilter{})
+			return err
+		}, 5*time.Second, 20*time.Millisecond).Should(Succeed())
+		Eventually(func() error {
+			_, err := processService.GetLastRun(client.ID, models.LogFilter{})
+			return err
+		}, 2*time.Second, 50*time.Millisecond).Should(HaveOccurred())
+	})
+
+	It("keeps nothing when retention is disabled", func() {
+		processService.SetLastRunRetention(10, 0)
+		Expect(processService.Start(client, GinkgoT().TempDir())).To(Succeed())
+
+		Eventually(func() bool { return processService.IsRunning(client.ID) }, 5*time.Second, 50*time.Millisecond).Should(BeFalse())
+		_, err := processService.GetLastRun(client.ID, models.LogFilter{})
+		Expect(err).To(HaveOccurred())
+	})
+})
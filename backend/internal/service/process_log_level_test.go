@@ -0,0 +1,54 @@
+package service_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("Process log level", func() {
+	// startClient starts a fake gosmee printing its arguments and returns them.
+	startClient := func(client *models.Client) string {
+		binDir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(binDir, "gosmee"), []byte("#!/bin/sh\necho \"args: $*\"\nexec sleep 30\n"), 0755)).To(Succeed())
+		GinkgoT().Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+		processService := service.NewProcessService(false, 0, time.Minute, logger.New())
+		Expect(processService.Start(client, GinkgoT().TempDir())).To(Succeed())
+		DeferCleanup(processService.Stop, client.ID)
+
+		var args string
+		Eventually(func() string {
+			info, err := processService.GetProcessInfo(client.ID)
+			Expect(err).NotTo(HaveOccurred())
+			for _, line := range info.GetLogLines() {
+				if _, after, ok := strings.Cut(line, "args: "); ok {
+					args = after
+				}
+			}
+			return args
+		}, 5*time.Second, 50*time.Millisecond).ShouldNot(BeEmpty())
+		return args
+	}
+
+	It("passes the debug flag to gosmee for clients with log level debug", func() {
+		client := models.NewClient("client-debug", "user-log", "debug", "", "https://smee.example.com/channel", "http://127.0.0.1:1/hook")
+		client.LogLevel = models.ClientLogLevelDebug
+
+		Expect(startClient(client)).To(ContainSubstring("--debug https://smee.example.com/channel"))
+	})
+
+	It("passes no verbosity flags by default", func() {
+		client := models.NewClient("client-info", "user-log", "info", "", "https://smee.example.com/channel", "http://127.0.0.1:1/hook")
+
+		Expect(startClient(client)).NotTo(ContainSubstring("--debug"))
+	})
+})
@@ -0,0 +1,51 @@
+package service_test
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("Running processes limit per user", func() {
+	It("rejects starts once a user runs the maximum number of clients", func() {
+		binDir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(binDir, "gosmee"), []byte("#!/bin/sh\nsleep 30\n"), 0755)).To(Succeed())
+		GinkgoT().Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+		processService := service.NewProcessService(false, 0, time.Minute, logger.New())
+		processService.SetMaxRunningPerUser(1)
+		DeferCleanup(processService.StopAll)
+
+		baseDir := GinkgoT().TempDir()
+		first := &models.Client{ID: "client-per-user-1", UserID: "user-a", SmeeURL: "https://smee.example.com/a", TargetURL: "http://127.0.0.1:1/hook"}
+		second := &models.Client{ID: "client-per-user-2", UserID: "user-a", SmeeURL: "https://smee.example.com/b", TargetURL: "http://127.0.0.1:1/hook"}
+		other := &models.Client{ID: "client-per-user-3", UserID: "user-b", SmeeURL: "https://smee.example.com/c", TargetURL: "http://127.0.0.1:1/hook"}
+
+		Expect(processService.Start(first, baseDir)).To(Succeed())
+		err := processService.Start(second, baseDir)
+		var appErr *apperrors.AppError
+		Expect(errors.As(err, &appErr)).To(BeTrue())
+		Expect(appErr.Code).To(Equal(apperrors.CodeQuotaExceeded))
+		Expect(appErr.StatusCode).To(Equal(http.StatusForbidden))
+
+		// Other users are not affected
+		Expect(processService.Start(other, baseDir)).To(Succeed())
+
+		running, limit := processService.RunningLimit("user-a")
+		Expect(running).To(Equal(1))
+		Expect(limit).To(Equal(1))
+
+		Expect(processService.Stop(first.ID)).To(Succeed())
+		Expect(processService.Start(second, baseDir)).To(Succeed())
+	})
+})
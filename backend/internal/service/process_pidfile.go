@@ -0,0 +1,57 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+)
+
+// clientPIDFile is the file in a client directory holding the PID of its running gosmee
+// process. It is runtime state of this host and not backed up.
+const clientPIDFile = "gosmee.pid"
+
+// clientPIDPath returns the PID file of a client.
+func clientPIDPath(baseDir string, client *models.Client) string {
+	return filepath.Join(baseDir, "users", client.UserID, "clients", client.ID, clientPIDFile)
+}
+
+// writePIDFile records the PID of a client's process in its PID file.
+func (s *ProcessService) writePIDFile(ctx *processContext) {
+	path := clientPIDPath(ctx.baseDir, ctx.client)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		s.log.Error("Failed to write PID file of client %s: %v", ctx.client.ID, err)
+		return
+	}
+	if err := os.WriteFile(path, []byte(strconv.Itoa(ctx.processInfo.PID)+"\n"), 0644); err != nil {
+		s.log.Error("Failed to write PID file of client %s: %v", ctx.client.ID, err)
+	}
+}
+
+// removePIDFile removes the PID file of an exited process, unless it already holds the PID
+// of a process started since.
+func (s *ProcessService) removePIDFile(ctx *processContext) {
+	pid, err := readPIDFile(ctx.baseDir, ctx.client)
+	if err != nil || pid != ctx.processInfo.PID {
+		return
+	}
+	if err := os.Remove(clientPIDPath(ctx.baseDir, ctx.client)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		s.log.Error("Failed to remove PID file of client %s: %v", ctx.client.ID, err)
+	}
+}
+
+// readPIDFile reads the PID file of a client.
+func readPIDFile(baseDir string, client *models.Client) (int, error) {
+	data, err := os.ReadFile(clientPIDPath(baseDir, client))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
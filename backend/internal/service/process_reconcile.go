@@ -0,0 +1,311 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+)
+
+// procDir is the process table scanned for gosmee processes.
+const procDir = "/proc"
+
+// adoptedPollInterval is how often an adopted process is checked for having exited, as it
+// can't be waited for.
+const adoptedPollInterval = 500 * time.Millisecond
+
+// gosmeeProcess is a running gosmee client process found in the process table.
+type gosmeeProcess struct {
+	pid     int
+	saveDir string
+}
+
+// What happens to gosmee processes of clients left running by a previous backend.
+const (
+	StaleProcessAdopt     = "adopt"     // Manage them like started processes
+	StaleProcessTerminate = "terminate" // Stop them, the clients are started again as usual
+)
+
+// staleProcessStopTimeout is how long terminated stale processes get to exit before they
+// are killed.
+const staleProcessStopTimeout = 5 * time.Second
+
+// SetStaleProcessPolicy sets what Reconcile does with the processes of clients left running
+// by a previous backend: adopt (default) or terminate them. With embedded clients, they are
+// always terminated.
+func (s *ProcessService) SetStaleProcessPolicy(policy string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.staleProcessPolicy = policy
+}
+
+// Reconcile matches the gosmee processes running on this host against the clients, by the
+// events directory they save to and the clients' PID files, so a restarted backend doesn't
+// run two processes consuming the same channel. With resolve, processes left running by a
+// previous backend are handled according to the stale process policy:
+//   - adopt: the process of each client not running yet (the one in its PID file, if any) is
+//     managed like a started process, except that its output is not captured; the other
+//     processes saving to baseDir are reported as unmanaged
+//   - terminate: all processes saving to baseDir that are not managed are stopped
+//
+// Stale PID files are removed as well. Without resolve, unmanaged processes are only reported.
+func (s *ProcessService) Reconcile(clients []*models.Client, baseDir string, resolve bool) (*models.ProcessReconcileReport, error) {
+	processes, err := listGosmeeProcesses()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	report, stale := s.reconcileLocked(processes, clients, baseDir, resolve)
+	s.mu.Unlock()
+
+	if len(stale) > 0 {
+		terminateProcesses(stale)
+		for _, process := range report.Terminated {
+			s.log.Info("Terminated stale gosmee process %d of client %s", process.PID, process.ClientID)
+		}
+	}
+	return report, nil
+}
+
+// reconcileLocked adopts processes and returns the report and the processes to terminate.
+// The caller must hold s.mu.
+func (s *ProcessService) reconcileLocked(processes []gosmeeProcess, clients []*models.Client, baseDir string, resolve bool) (*models.ProcessReconcileReport, []*os.Process) {
+	// Embedded clients replace the gosmee processes of a previous backend, they are not adopted
+	policy := s.staleProcessPolicy
+	if s.ingester != nil {
+		policy = StaleProcessTerminate
+	}
+	managed := make(map[int]bool, len(s.processes))
+	for _, ctx := range s.processes {
+		managed[ctx.processInfo.PID] = true
+	}
+	saveDirs := make(map[int]string, len(processes))
+	for _, proc := range processes {
+		saveDirs[proc.pid] = proc.saveDir
+	}
+
+	// The process in a client's PID file is the one the previous backend managed
+	byID := make(map[string]*models.Client, len(clients))
+	recorded := make(map[int]bool)
+	for _, client := range clients {
+		byID[client.ID] = client
+		pid, err := readPIDFile(baseDir, client)
+		switch {
+		case err != nil:
+		case saveDirs[pid] == clientEventsDir(baseDir, client):
+			recorded[pid] = true
+		case resolve && !managed[pid]:
+			// The process exited or the PID was reused by another process
+			s.log.Info("Removing stale PID file of client %s (PID %d)", client.ID, pid)
+			if err := os.Remove(clientPIDPath(baseDir, client)); err != nil {
+				s.log.Error("Failed to remove stale PID file of client %s: %v", client.ID, err)
+			}
+		}
+	}
+	sort.SliceStable(processes, func(i, j int) bool { return recorded[processes[i].pid] && !recorded[processes[j].pid] })
+
+	report := &models.ProcessReconcileReport{
+		Adopted:    []string{},
+		Terminated: []*models.UnmanagedProcess{},
+		Unmanaged:  []*models.UnmanagedProcess{},
+	}
+	var stale []*os.Process
+	for _, proc := range processes {
+		if managed[proc.pid] {
+			continue
+		}
+		userID, clientID, ok := clientOfEventsDir(baseDir, proc.saveDir)
+		if !ok {
+			// Saves elsewhere, e.g. for another backend
+			continue
+		}
+
+		unmanaged := &models.UnmanagedProcess{
+			PID:      proc.pid,
+			UserID:   userID,
+			ClientID: clientID,
+			SaveDir:  filepath.Join("users", userID, "clients", clientID, "events"),
+			Reason:   models.UnmanagedStale,
+		}
+		client := byID[clientID]
+		switch {
+		case client == nil || client.UserID != userID:
+			unmanaged.Reason = models.UnmanagedUnknownClient
+		case s.processes[clientID] != nil:
+			unmanaged.Reason = models.UnmanagedDuplicate
+		case !resolve:
+			unmanaged.Reason = models.UnmanagedNotAdopted
+		case policy == StaleProcessAdopt:
+			s.adoptLocked(client, baseDir, proc.pid)
+			report.Adopted = append(report.Adopted, clientID)
+			continue
+		}
+
+		if resolve && policy == StaleProcessTerminate {
+			// Never fails on Unix, the process is only looked up when signalled
+			process, _ := os.FindProcess(proc.pid)
+			stale = append(stale, process)
+			if recorded[proc.pid] {
+				_ = os.Remove(clientPIDPath(baseDir, client))
+			}
+			report.Terminated = append(report.Terminated, unmanaged)
+			continue
+		}
+		report.Unmanaged = append(report.Unmanaged, unmanaged)
+	}
+	return report, stale
+}
+
+// terminateProcesses sends SIGTERM to processes and kills those still running after
+// staleProcessStopTimeout.
+func terminateProcesses(processes []*os.Process) {
+	for _, process := range processes {
+		_ = process.Signal(syscall.SIGTERM)
+	}
+
+	deadline := time.Now().Add(staleProcessStopTimeout)
+	for _, process := range processes {
+		for processAlive(process.Pid) && time.Now().Before(deadline) {
+			time.Sleep(adoptedPollInterval)
+		}
+		if processAlive(process.Pid) {
+			_ = process.Kill()
+		}
+	}
+}
+
+// adoptLocked manages an already running gosmee process of a client. The caller must hold s.mu.
+func (s *ProcessService) adoptLocked(client *models.Client, baseDir string, pid int) {
+	// Never fails on Unix, the process is only looked up when signalled
+	process, _ := os.FindProcess(pid)
+
+	ctx := &processContext{
+		client:      client,
+		baseDir:     baseDir,
+		cmd:         &exec.Cmd{Path: gosmeeBinary, Process: process},
+		processInfo: models.NewProcessInfo(client.ID, pid, s.logBufferLines),
+		stopChan:    make(chan struct{}),
+		exitChan:    make(chan struct{}),
+		masker:      s.masker,
+		adopted:     true,
+	}
+
+	s.processes[client.ID] = ctx
+	s.writePIDFile(ctx)
+	if s.watcher != nil {
+		s.watcher.Watch(client, clientEventsDir(baseDir, client))
+	}
+	go s.monitorProcess(ctx)
+
+	s.log.Info("Adopted running gosmee client process: %s (PID: %d)", client.ID, pid)
+}
+
+// wait waits for the process to exit. Adopted processes are not children of the backend,
+// so they are polled instead.
+func (ctx *processContext) wait() error {
+	if ctx.embedded != nil {
+		return ctx.embedded.wait()
+	}
+	if !ctx.adopted {
+		return ctx.cmd.Wait()
+	}
+	for processAlive(ctx.processInfo.PID) {
+		time.Sleep(adoptedPollInterval)
+	}
+	return nil
+}
+
+// exitCode returns the exit code of the exited process, -1 if unknown. Embedded clients
+// exit with 1 if they failed.
+func (ctx *processContext) exitCode() int {
+	if ctx.embedded == nil {
+		return ctx.cmd.ProcessState.ExitCode()
+	}
+	if ctx.embedded.err != nil {
+		return 1
+	}
+	return 0
+}
+
+// processAlive reports whether a process exists and has not exited (zombies have).
+func processAlive(pid int) bool {
+	stat, err := os.ReadFile(filepath.Join(procDir, strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return false
+	}
+	// The state follows the command name, which is in parentheses and may contain spaces
+	fields := strings.Fields(string(stat[bytes.LastIndexByte(stat, ')')+1:]))
+	return len(fields) > 0 && fields[0] != "Z" && fields[0] != "X"
+}
+
+// listGosmeeProcesses returns the gosmee client processes in the process table, by PID.
+func listGosmeeProcesses() ([]gosmeeProcess, error) {
+	entries, err := os.ReadDir(procDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read process table: %w", err)
+	}
+
+	var processes []gosmeeProcess
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || pid == os.Getpid() {
+			continue
+		}
+		cmdline, err := os.ReadFile(filepath.Join(procDir, entry.Name(), "cmdline"))
+		if err != nil {
+			// Exited in the meantime or not readable
+			continue
+		}
+		if saveDir, ok := gosmeeSaveDir(strings.Split(strings.TrimRight(string(cmdline), "\x00"), "\x00")); ok {
+			processes = append(processes, gosmeeProcess{pid: pid, saveDir: saveDir})
+		}
+	}
+	sort.Slice(processes, func(i, j int) bool { return processes[i].pid < processes[j].pid })
+	return processes, nil
+}
+
+// gosmeeSaveDir returns the events directory of a gosmee client command line. The gosmee
+// binary may be run through an interpreter, so it is looked for in all arguments.
+func gosmeeSaveDir(args []string) (string, bool) {
+	for i := 0; i+1 < len(args); i++ {
+		if filepath.Base(args[i]) != gosmeeBinary || args[i+1] != "client" {
+			continue
+		}
+		for j := i + 2; j < len(args); j++ {
+			if args[j] == "--saveDir" && j+1 < len(args) {
+				return filepath.Clean(args[j+1]), true
+			}
+			if value, ok := strings.CutPrefix(args[j], "--saveDir="); ok {
+				return filepath.Clean(value), true
+			}
+		}
+		return "", false
+	}
+	return "", false
+}
+
+// clientOfEventsDir returns the user and client of an events directory under baseDir.
+func clientOfEventsDir(baseDir, eventsDir string) (userID, clientID string, ok bool) {
+	rel, err := filepath.Rel(filepath.Clean(baseDir), eventsDir)
+	if err != nil {
+		return "", "", false
+	}
+	parts := strings.Split(rel, string(filepath.Separator))
+	if len(parts) != 5 || parts[0] != "users" || parts[2] != "clients" || parts[4] != "events" {
+		return "", "", false
+	}
+	return parts[1], parts[3], true
+}
@@ -0,0 +1,162 @@
+package service_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("Process reconciliation", func() {
+	var (
+		gosmeePath string
+		baseDir    string
+		client     *models.Client
+	)
+
+	BeforeEach(func() {
+		gosmeePath = filepath.Join(GinkgoT().TempDir(), "gosmee")
+		Expect(os.WriteFile(gosmeePath, []byte("#!/bin/sh\nsleep 30\n"), 0755)).To(Succeed())
+		baseDir = GinkgoT().TempDir()
+		client = &models.Client{ID: "client-reconcile", UserID: "user-reconcile", SmeeURL: "https://smee.example.com/channel", TargetURL: "http://127.0.0.1:1/hook"}
+		Expect(os.MkdirAll(filepath.Join(baseDir, "users", client.UserID, "clients", client.ID), 0755)).To(Succeed())
+	})
+
+	// startExternal starts a gosmee process saving to the events directory of a client, the
+	// way a previous backend would have.
+	startExternal := func(userID, clientID string) (*exec.Cmd, chan struct{}) {
+		saveDir := filepath.Join(baseDir, "users", userID, "clients", clientID, "events")
+		cmd := exec.Command(gosmeePath, "client", "--saveDir", saveDir, client.SmeeURL, client.TargetURL)
+		Expect(cmd.Start()).To(Succeed())
+		exited := make(chan struct{})
+		go func() {
+			_ = cmd.Wait()
+			close(exited)
+		}()
+		DeferCleanup(func() {
+			_ = cmd.Process.Kill()
+			<-exited
+		})
+		return cmd, exited
+	}
+
+	It("adopts the process of a client and reports the others as unmanaged", func() {
+		adopted, adoptedExited := startExternal(client.UserID, client.ID)
+		duplicate, duplicateExited := startExternal(client.UserID, client.ID)
+		unknown, _ := startExternal(client.UserID, "deleted-client")
+		// Give the processes time to run the script
+		time.Sleep(100 * time.Millisecond)
+
+		processService := service.NewProcessService(false, 0, time.Minute, logger.New())
+		report, err := processService.Reconcile([]*models.Client{client}, baseDir, true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Adopted).To(Equal([]string{client.ID}))
+		Expect(report.Unmanaged).To(ConsistOf(
+			&models.UnmanagedProcess{PID: duplicate.Process.Pid, UserID: client.UserID, ClientID: client.ID,
+				SaveDir: "users/user-reconcile/clients/client-reconcile/events", Reason: models.UnmanagedDuplicate},
+			&models.UnmanagedProcess{PID: unknown.Process.Pid, UserID: client.UserID, ClientID: "deleted-client",
+				SaveDir: "users/user-reconcile/clients/deleted-client/events", Reason: models.UnmanagedUnknownClient},
+		))
+
+		Expect(processService.IsRunning(client.ID)).To(BeTrue())
+		info, err := processService.GetProcessInfo(client.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.PID).To(Equal(adopted.Process.Pid))
+		Expect(processService.Start(client, baseDir)).To(MatchError(ContainSubstring("already running")))
+
+		// Managed processes are not reported again
+		report, err = processService.Reconcile([]*models.Client{client}, baseDir, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Unmanaged).To(HaveLen(2))
+
+		Expect(processService.Stop(client.ID)).To(Succeed())
+		Eventually(adoptedExited, 5*time.Second).Should(BeClosed())
+		Consistently(duplicateExited, 100*time.Millisecond).ShouldNot(BeClosed())
+	})
+
+	It("notices when an adopted process exits", func() {
+		adopted, _ := startExternal(client.UserID, client.ID)
+		time.Sleep(100 * time.Millisecond)
+
+		processService := service.NewProcessService(false, 0, time.Minute, logger.New())
+		_, err := processService.Reconcile([]*models.Client{client}, baseDir, true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(processService.IsRunning(client.ID)).To(BeTrue())
+
+		Expect(adopted.Process.Signal(syscall.SIGKILL)).To(Succeed())
+		Eventually(func() bool { return processService.IsRunning(client.ID) }, 5*time.Second).Should(BeFalse())
+	})
+
+	pidFile := func() string {
+		return filepath.Join(baseDir, "users", client.UserID, "clients", client.ID, "gosmee.pid")
+	}
+
+	It("writes a PID file while a client runs", func() {
+		binDir := filepath.Dir(gosmeePath)
+		GinkgoT().Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+		processService := service.NewProcessService(false, 0, time.Minute, logger.New())
+		Expect(processService.Start(client, baseDir)).To(Succeed())
+		info, err := processService.GetProcessInfo(client.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.ReadFile(pidFile())).To(Equal([]byte(strconv.Itoa(info.PID) + "\n")))
+
+		Expect(processService.Stop(client.ID)).To(Succeed())
+		Eventually(pidFile).ShouldNot(BeAnExistingFile())
+	})
+
+	It("adopts the process in the client's PID file", func() {
+		_, _ = startExternal(client.UserID, client.ID)
+		recorded, _ := startExternal(client.UserID, client.ID)
+		Expect(os.WriteFile(pidFile(), []byte(strconv.Itoa(recorded.Process.Pid)+"\n"), 0644)).To(Succeed())
+		time.Sleep(100 * time.Millisecond)
+
+		processService := service.NewProcessService(false, 0, time.Minute, logger.New())
+		report, err := processService.Reconcile([]*models.Client{client}, baseDir, true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Adopted).To(Equal([]string{client.ID}))
+		info, err := processService.GetProcessInfo(client.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.PID).To(Equal(recorded.Process.Pid))
+		Expect(report.Unmanaged).To(HaveLen(1))
+		Expect(report.Unmanaged[0].Reason).To(Equal(models.UnmanagedDuplicate))
+		Expect(processService.Stop(client.ID)).To(Succeed())
+	})
+
+	It("terminates stale processes and removes stale PID files", func() {
+		stale, staleExited := startExternal(client.UserID, client.ID)
+		_, unknownExited := startExternal(client.UserID, "deleted-client")
+		Expect(os.WriteFile(pidFile(), []byte(strconv.Itoa(stale.Process.Pid)+"\n"), 0644)).To(Succeed())
+		// The PID of a process that exited long ago
+		other := &models.Client{ID: "client-exited", UserID: client.UserID}
+		otherPIDFile := filepath.Join(baseDir, "users", other.UserID, "clients", other.ID, "gosmee.pid")
+		Expect(os.MkdirAll(filepath.Dir(otherPIDFile), 0755)).To(Succeed())
+		Expect(os.WriteFile(otherPIDFile, []byte("999999999\n"), 0644)).To(Succeed())
+		time.Sleep(100 * time.Millisecond)
+
+		processService := service.NewProcessService(false, 0, time.Minute, logger.New())
+		processService.SetStaleProcessPolicy(service.StaleProcessTerminate)
+		report, err := processService.Reconcile([]*models.Client{client, other}, baseDir, true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Adopted).To(BeEmpty())
+		Expect(report.Unmanaged).To(BeEmpty())
+		Expect(report.Terminated).To(HaveLen(2))
+		Expect(report.Terminated[0].Reason).To(Equal(models.UnmanagedStale))
+		Expect(report.Terminated[1].Reason).To(Equal(models.UnmanagedUnknownClient))
+
+		Eventually(staleExited, 5*time.Second).Should(BeClosed())
+		Eventually(unknownExited, 5*time.Second).Should(BeClosed())
+		Expect(processService.IsRunning(client.ID)).To(BeFalse())
+		Expect(pidFile()).NotTo(BeAnExistingFile())
+		Expect(otherPIDFile).NotTo(BeAnExistingFile())
+	})
+})
@@ -5,135 +5,476 @@ package service
 
 import (
 	"bufio"
+	"context"
 	"fmt"
+	"io"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
 
 	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/encryption"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
 	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
 )
 
+// gosmeeBinary is the gosmee executable, looked up in PATH.
+const gosmeeBinary = "gosmee"
+
+// How clients run: as gosmee processes or in the backend process (see SetEmbeddedClients).
+const (
+	ClientRuntimeExec     = "exec"     // gosmee client processes
+	ClientRuntimeEmbedded = "embedded" // SSE clients in the backend process, gosmee is not needed
+)
+
+// defaultStopTimeout is how long a stopped process gets to exit after SIGTERM by default.
+const defaultStopTimeout = 5 * time.Second
+
+// gosmeeLogLevelFlags maps client log levels to the gosmee client flags enabling them.
+// The default level passes no flags.
+var gosmeeLogLevelFlags = map[string][]string{
+	models.ClientLogLevelDebug: {"--debug"},
+}
+
+// ProcessExit describes an unexpected exit of a client process.
+type ProcessExit struct {
+	ClientID     string // Client instance ID
+	ExitCode     int    // Process exit code (-1 if unknown)
+	Error        string // Exit error message
+	Category     string // Error category (one of the models.ClientError* constants)
+	Restarting   bool   // Whether the process is being restarted automatically
+	CrashLooping bool   // Whether auto-restart gave up because the restart budget is exhausted
+}
+
+// ProcessExitHandler is notified when a client process exits unexpectedly.
+type ProcessExitHandler func(exit *ProcessExit)
+
+// EventWatcher is notified when client processes start and stop, to watch the events
+// directory gosmee writes to.
+type EventWatcher interface {
+	Watch(client *models.Client, eventsDir string)
+	Unwatch(clientID string)
+}
+
+// EventIngester saves and processes the events of clients run in the backend process, like
+// the EventWatcher does for the events gosmee writes.
+type EventIngester interface {
+	Ingest(clientID string, event *models.Event) error
+}
+
 // ProcessService manages gosmee client processes.
 type ProcessService struct {
 	processes       map[string]*processContext // clientID -> process context
 	mu              sync.RWMutex               // Mutex for thread-safe operations
 	log             logger.Logger
 	autoRestart     bool
-	maxRestartCount int
+	maxRestartCount int           // Maximum automatic restarts within restartWindow
+	restartWindow   time.Duration // Sliding window of the restart budget
+
+	restartHistory map[string][]time.Time // clientID -> automatic restart times within the window
+	historyMu      sync.Mutex
+	exitHandler    ProcessExitHandler
+	watcher        EventWatcher // Watches the events directories of running clients (optional)
+
+	logBufferLines int                // Log lines kept in memory per process
+	masker         MaskerFunc         // Redacts secrets from log lines (optional)
+	cipher         *encryption.Cipher // Encrypts persisted log lines (optional)
+
+	startupGrace time.Duration  // How long a started process must stay alive to be considered healthy
+	readyPattern *regexp.Regexp // Log line signalling an established SSE connection (optional)
+	readyTimeout time.Duration  // How long to wait for readyPattern
+
+	startSlots chan struct{} // Bounds the processes starting up at the same time (nil = unlimited)
+	maxRunning int           // Maximum processes running at the same time, across all users (0 = unlimited)
+	maxPerUser int           // Maximum processes of a single user running at the same time (0 = unlimited)
+
+	lastRuns         map[string]*models.ProcessLastRun // clientID -> logs of the most recently stopped process
+	lastRunLines     int                               // Log lines kept of a stopped process
+	lastRunRetention time.Duration                     // How long the logs of a stopped process are kept (0 = not kept)
+	lastRunMu        sync.Mutex
+
+	stopTimeout        time.Duration // How long a stopped process gets to exit after SIGTERM before it is killed
+	staleProcessPolicy string        // What Reconcile does with processes left running by a previous backend
+
+	gosmeeHelp   string // Cached "gosmee client --help" output, listing the supported flags
+	gosmeeHelpMu sync.Mutex
+
+	ingester EventIngester // Takes the events of clients run in the backend process (nil = gosmee processes)
 }
 
 // processContext holds information about a running process.
 type processContext struct {
 	client       *models.Client
-	cmd          *exec.Cmd
+	baseDir      string
+	cmd          *exec.Cmd       // gosmee process, nil for embedded clients
+	embedded     *embeddedClient // Client run in the backend process (see SetEmbeddedClients)
 	processInfo  *models.ProcessInfo
 	stopChan     chan struct{}
+	exitChan     chan struct{} // Closed when the process has exited
+	logWriter    *dailyLogWriter
+	masker       MaskerFunc
+	collectors   sync.WaitGroup // Running log collectors
 	restartCount int
+	adopted      bool // Started by a previous backend, its output is not captured
 }
 
 // NewProcessService creates a new process service.
-func NewProcessService(autoRestart bool, maxRestartCount int, log logger.Logger) *ProcessService {
+// With autoRestart enabled, a crashed process is restarted at most maxRestartCount times
+// within restartWindow; beyond that budget the client is considered crash-looping.
+func NewProcessService(autoRestart bool, maxRestartCount int, restartWindow time.Duration, log logger.Logger) *ProcessService {
 	return &ProcessService{
 		processes:       make(map[string]*processContext),
 		log:             log,
 		autoRestart:     autoRestart,
 		maxRestartCount: maxRestartCount,
+		restartWindow:   restartWindow,
+		restartHistory:  make(map[string][]time.Time),
+		logBufferLines:  models.DefaultLogBufferLines,
+		startupGrace:    3 * time.Second,
+
+		lastRuns:         make(map[string]*models.ProcessLastRun),
+		lastRunLines:     models.DefaultLastRunLogLines,
+		lastRunRetention: defaultLastRunRetention,
+
+		stopTimeout:        defaultStopTimeout,
+		staleProcessPolicy: StaleProcessAdopt,
+	}
+}
+
+// SetLogBufferLines sets how many recent log lines are kept in memory per process.
+// The full history is written to the daily log files.
+func (s *ProcessService) SetLogBufferLines(lines int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.logBufferLines = lines
+}
+
+// SetMasker sets how secrets are redacted from log lines before they are persisted or streamed.
+func (s *ProcessService) SetMasker(masker MaskerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.masker = masker
+}
+
+// SetCipher enables encryption of the log lines written to the daily log files.
+func (s *ProcessService) SetCipher(cipher *encryption.Cipher) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cipher = cipher
+}
+
+// SetStartupCheck configures the health verification performed by VerifyStartup.
+// If readyPattern is not nil, a log line matching it must appear within readyTimeout.
+func (s *ProcessService) SetStartupCheck(grace time.Duration, readyPattern *regexp.Regexp, readyTimeout time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.startupGrace = grace
+	s.readyPattern = readyPattern
+	s.readyTimeout = readyTimeout
+}
+
+// SetMaxConcurrentStarts bounds the number of processes starting up at the same time, so
+// starting hundreds of clients doesn't fork them and open their SSE connections all at once
+// (0 = unlimited). Further starts wait in a queue until a starting process is up or exited.
+func (s *ProcessService) SetMaxConcurrentStarts(limit int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.startSlots = nil
+	if limit > 0 {
+		s.startSlots = make(chan struct{}, limit)
+	}
+}
+
+// SetMaxRunning sets the maximum number of processes running at the same time across all
+// users, so a single user can't exhaust the host (0 = unlimited). Starts beyond it fail.
+func (s *ProcessService) SetMaxRunning(limit int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.maxRunning = limit
+}
+
+// SetMaxRunningPerUser sets the maximum number of processes of a single user running at the
+// same time (0 = unlimited). Starts beyond it fail with a quota error.
+func (s *ProcessService) SetMaxRunningPerUser(limit int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.maxPerUser = limit
+}
+
+// RunningLimit returns the number of running processes of a user and the maximum number of
+// processes the user may run at the same time (0 = unlimited).
+func (s *ProcessService) RunningLimit(userID string) (running, max int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.runningOf(userID), s.maxPerUser
+}
+
+// runningOf counts the running processes of a user. The caller must hold s.mu.
+func (s *ProcessService) runningOf(userID string) int {
+	running := 0
+	for _, ctx := range s.processes {
+		if ctx.client.UserID == userID {
+			running++
+		}
 	}
+	return running
 }
 
-// Start starts a gosmee client process.
+// SetExitHandler registers the handler notified about unexpected process exits.
+func (s *ProcessService) SetExitHandler(handler ProcessExitHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.exitHandler = handler
+}
+
+// SetEventWatcher registers the watcher of the events directories of running clients.
+func (s *ProcessService) SetEventWatcher(watcher EventWatcher) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.watcher = watcher
+}
+
+// SetEmbeddedClients runs clients in the backend process instead of gosmee processes, handing
+// every event they receive to ingester. Embedded clients don't need the gosmee binary; like
+// gosmee processes, they don't forward events themselves (see EventService.ForwardNewEvents).
+func (s *ProcessService) SetEmbeddedClients(ingester EventIngester) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ingester = ingester
+}
+
+// Embedded reports whether clients run in the backend process instead of gosmee processes.
+func (s *ProcessService) Embedded() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.ingester != nil
+}
+
+// Start starts a gosmee client process, or an embedded client, waiting for a start slot
+// first if the number of processes starting at the same time is bounded.
 func (s *ProcessService) Start(client *models.Client, baseDir string) error {
+	// Wait before locking, queued starts must not block the other operations
+	slots := s.acquireStartSlot(client.ID)
+	started := false
+	defer func() {
+		if slots != nil && !started {
+			<-slots
+		}
+	}()
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	// Check if already running
 	if ctx, exists := s.processes[client.ID]; exists {
-		if ctx.cmd.Process != nil {
-			return fmt.Errorf("client already running: %s", client.ID)
+		if ctx.embedded != nil || ctx.cmd.Process != nil {
+			return apperrors.NewClientRunning(fmt.Sprintf("client already running: %s", client.ID))
 		}
 	}
+	if s.maxRunning > 0 && len(s.processes) >= s.maxRunning {
+		return apperrors.NewCapacityReached(fmt.Sprintf("capacity reached: the server already runs %d clients", s.maxRunning))
+	}
+	if running := s.runningOf(client.UserID); s.maxPerUser > 0 && running >= s.maxPerUser {
+		return apperrors.NewQuotaExceeded(fmt.Sprintf("running client limit reached: %d/%d", running, s.maxPerUser))
+	}
 
-	// Build gosmee command
-	cmd, err := s.buildGosmeeCommand(client, baseDir)
-	if err != nil {
-		return fmt.Errorf("failed to build gosmee command: %w", err)
+	var (
+		ctx            *processContext
+		stdout, stderr io.Reader
+	)
+	if s.ingester != nil {
+		embedded, stdoutReader, stderrReader := newEmbeddedClient(client, s.ingester)
+		ctx, stdout, stderr = s.newProcessContext(client, baseDir, 0), stdoutReader, stderrReader
+		ctx.embedded = embedded
+	} else {
+		// Build gosmee command
+		cmd, err := s.buildGosmeeCommand(client, baseDir)
+		if err != nil {
+			return fmt.Errorf("failed to build gosmee command: %w", err)
+		}
+
+		// Create pipes for stdout/stderr
+		if stdout, err = cmd.StdoutPipe(); err != nil {
+			return fmt.Errorf("failed to create stdout pipe: %w", err)
+		}
+		if stderr, err = cmd.StderrPipe(); err != nil {
+			return fmt.Errorf("failed to create stderr pipe: %w", err)
+		}
+
+		// Start the process
+		if err := cmd.Start(); err != nil {
+			return fmt.Errorf("failed to start gosmee process: %w", err)
+		}
+		ctx = s.newProcessContext(client, baseDir, cmd.Process.Pid)
+		ctx.cmd = cmd
 	}
 
-	// Create pipes for stdout/stderr
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		return fmt.Errorf("failed to create stdout pipe: %w", err)
+	s.processes[client.ID] = ctx
+	if ctx.embedded == nil {
+		s.writePIDFile(ctx)
+	}
+	if s.watcher != nil {
+		s.watcher.Watch(client, clientEventsDir(baseDir, client))
 	}
 
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		return fmt.Errorf("failed to create stderr pipe: %w", err)
+	// Start log collectors, closing the log file once both pipes are drained
+	ctx.collectors.Add(2)
+	go s.collectLogs(ctx, stdout, models.LogSourceStdout)
+	go s.collectLogs(ctx, stderr, models.LogSourceStderr)
+	go func() {
+		ctx.collectors.Wait()
+		ctx.logWriter.Close()
+	}()
+
+	// Embedded clients start receiving events once the watcher knows the client, their events
+	// are processed with its watch state
+	if ctx.embedded != nil {
+		ctx.embedded.start()
+	}
+
+	// Start process monitor
+	go s.monitorProcess(ctx)
+	if slots != nil {
+		started = true
+		go s.releaseStartSlot(ctx, slots, s.startupGrace, s.readyPattern, s.readyTimeout)
 	}
 
-	// Start the process
-	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("failed to start gosmee process: %w", err)
+	if ctx.embedded != nil {
+		s.log.Info("Started embedded gosmee client: %s", client.ID)
+	} else {
+		s.log.Info("Started gosmee client process: %s (PID: %d)", client.ID, ctx.processInfo.PID)
 	}
 
-	// Create process info
-	processInfo := models.NewProcessInfo(client.ID, cmd.Process.Pid)
+	return nil
+}
 
-	// Create process context
-	ctx := &processContext{
+// newProcessContext creates the context of a started client. Embedded clients have PID 0.
+func (s *ProcessService) newProcessContext(client *models.Client, baseDir string, pid int) *processContext {
+	return &processContext{
 		client:      client,
-		cmd:         cmd,
-		processInfo: processInfo,
+		baseDir:     baseDir,
+		processInfo: models.NewProcessInfo(client.ID, pid, s.logBufferLines),
 		stopChan:    make(chan struct{}),
+		exitChan:    make(chan struct{}),
+		logWriter:   newDailyLogWriter(filepath.Join(baseDir, "users", client.UserID, "clients", client.ID, "logs"), s.cipher),
+		masker:      s.masker,
 	}
+}
 
-	s.processes[client.ID] = ctx
-
-	// Start log collectors
-	go s.collectLogs(ctx, stdout, "stdout")
-	go s.collectLogs(ctx, stderr, "stderr")
+// acquireStartSlot waits for a start slot and returns the slots to release it to, or nil if
+// starts are not bounded.
+func (s *ProcessService) acquireStartSlot(clientID string) chan struct{} {
+	s.mu.RLock()
+	slots := s.startSlots
+	s.mu.RUnlock()
 
-	// Start process monitor
-	go s.monitorProcess(ctx)
+	if slots == nil {
+		return nil
+	}
+	select {
+	case slots <- struct{}{}:
+	default:
+		s.log.Info("Queued start of client %s: %d clients are starting", clientID, cap(slots))
+		slots <- struct{}{}
+	}
+	return slots
+}
 
-	s.log.Info("Started gosmee client process: %s (PID: %d)", client.ID, cmd.Process.Pid)
+// releaseStartSlot releases the start slot of a process once it is up, i.e. it logged the
+// ready line (if a ready pattern is configured) or stayed alive for the startup grace period,
+// or once it exited.
+func (s *ProcessService) releaseStartSlot(ctx *processContext, slots chan struct{}, grace time.Duration, readyPattern *regexp.Regexp, readyTimeout time.Duration) {
+	defer func() { <-slots }()
 
-	return nil
+	if readyPattern != nil {
+		_ = s.waitReady(ctx, readyPattern, readyTimeout)
+		return
+	}
+	select {
+	case <-ctx.exitChan:
+	case <-time.After(grace):
+	}
 }
 
-// Stop stops a gosmee client process.
-func (s *ProcessService) Stop(clientID string) error {
+// SetStopTimeout sets how long Stop waits for a process to exit after SIGTERM before it is
+// killed. A zero timeout kills processes right away.
+func (s *ProcessService) SetStopTimeout(timeout time.Duration) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	s.stopTimeout = timeout
+}
+
+// Stop stops a gosmee client process, killing it if it doesn't exit within the stop timeout.
+func (s *ProcessService) Stop(clientID string) error {
+	s.mu.RLock()
+	timeout := s.stopTimeout
+	s.mu.RUnlock()
+
+	return s.StopWithTimeout(clientID, timeout)
+}
+
+// StopWithTimeout stops a gosmee client process, killing it if it doesn't exit within timeout
+// after SIGTERM. A zero timeout kills the process right away.
+func (s *ProcessService) StopWithTimeout(clientID string, timeout time.Duration) error {
+	// Take the process out under the lock, waiting for it to exit must not block the other clients
+	s.mu.Lock()
 	ctx, exists := s.processes[clientID]
 	if !exists {
-		return fmt.Errorf("client not running: %s", clientID)
+		s.mu.Unlock()
+		return apperrors.NewClientNotRunning(fmt.Sprintf("client not running: %s", clientID))
 	}
 
 	// Signal stop
 	close(ctx.stopChan)
+	delete(s.processes, clientID)
+	if s.watcher != nil {
+		s.watcher.Unwatch(clientID)
+	}
+	s.mu.Unlock()
 
-	// Try graceful shutdown (SIGTERM)
-	if ctx.cmd.Process != nil {
+	switch {
+	case ctx.embedded != nil:
+		ctx.embedded.stop()
+	case ctx.cmd.Process == nil:
+	case timeout <= 0:
+		s.log.Info("Force killing process %d", ctx.cmd.Process.Pid)
+		ctx.cmd.Process.Kill()
+	default:
+		// Try graceful shutdown (SIGTERM)
 		if err := ctx.cmd.Process.Signal(syscall.SIGTERM); err != nil {
 			s.log.Error("Failed to send SIGTERM to process %d: %v", ctx.cmd.Process.Pid, err)
 		}
 
-		// Wait for graceful shutdown (5 seconds timeout)
+		// Wait for graceful shutdown
 		done := make(chan error, 1)
 		go func() {
-			done <- ctx.cmd.Wait()
+			done <- ctx.wait()
 		}()
 
 		select {
 		case <-done:
 			s.log.Info("Process %d terminated gracefully", ctx.cmd.Process.Pid)
-		case <-time.After(5 * time.Second):
+		case <-time.After(timeout):
 			// Force kill if not stopped
-			s.log.Info("Process %d did not stop gracefully, force killing", ctx.cmd.Process.Pid)
+			s.log.Info("Process %d did not stop within %s, force killing", ctx.cmd.Process.Pid, timeout)
 			ctx.cmd.Process.Kill()
 		}
 	}
@@ -141,9 +482,6 @@ func (s *ProcessService) Stop(clientID string) error {
 	// Close log listeners
 	ctx.processInfo.CloseAllLogListeners()
 
-	// Remove from map
-	delete(s.processes, clientID)
-
 	s.log.Info("Stopped gosmee client process: %s", clientID)
 
 	return nil
@@ -168,12 +506,81 @@ func (s *ProcessService) GetProcessInfo(clientID string) (*models.ProcessInfo, e
 
 	ctx, exists := s.processes[clientID]
 	if !exists {
-		return nil, fmt.Errorf("client not running: %s", clientID)
+		return nil, apperrors.NewClientNotRunning(fmt.Sprintf("client not running: %s", clientID))
 	}
 
 	return ctx.processInfo, nil
 }
 
+// VerifyStartup checks that a freshly started process came up: it must not exit within the
+// startup grace period and, if a ready pattern is configured, must report an established connection.
+func (s *ProcessService) VerifyStartup(clientID string) error {
+	s.mu.RLock()
+	ctx, exists := s.processes[clientID]
+	grace, readyPattern, readyTimeout := s.startupGrace, s.readyPattern, s.readyTimeout
+	s.mu.RUnlock()
+
+	if !exists {
+		return apperrors.NewClientNotRunning(fmt.Sprintf("client not running: %s", clientID))
+	}
+
+	if readyPattern != nil {
+		if err := s.waitReady(ctx, readyPattern, readyTimeout); err != nil {
+			return err
+		}
+	}
+
+	select {
+	case <-ctx.exitChan:
+		return s.startupExitError(ctx)
+	case <-time.After(grace):
+		return nil
+	}
+}
+
+// waitReady waits for a log line matching pattern, failing if the process exits or the timeout elapses.
+func (s *ProcessService) waitReady(ctx *processContext, pattern *regexp.Regexp, timeout time.Duration) error {
+	listener := ctx.processInfo.AddLogListener()
+	defer ctx.processInfo.RemoveLogListener(listener)
+
+	for _, line := range ctx.processInfo.GetLogLines() {
+		if pattern.MatchString(line) {
+			return nil
+		}
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case line, ok := <-listener:
+			if !ok {
+				return s.startupExitError(ctx)
+			}
+			if pattern.MatchString(line.Message) {
+				return nil
+			}
+		case <-ctx.exitChan:
+			return s.startupExitError(ctx)
+		case <-timer.C:
+			return fmt.Errorf("connection not established within %s", timeout)
+		}
+	}
+}
+
+// startupExitError describes a process that exited while being verified.
+func (s *ProcessService) startupExitError(ctx *processContext) error {
+	s.mu.RLock()
+	lastError := ctx.processInfo.LastError
+	s.mu.RUnlock()
+
+	if lastError != "" {
+		return fmt.Errorf("process exited during startup: %s", lastError)
+	}
+	return fmt.Errorf("process exited during startup")
+}
+
 // IsRunning checks if a client process is running.
 func (s *ProcessService) IsRunning(clientID string) bool {
 	s.mu.RLock()
@@ -184,7 +591,7 @@ func (s *ProcessService) IsRunning(clientID string) bool {
 		return false
 	}
 
-	return ctx.cmd.Process != nil
+	return ctx.embedded != nil || ctx.cmd.Process != nil
 }
 
 // StopAll stops all running processes.
@@ -211,8 +618,7 @@ func (s *ProcessService) buildGosmeeCommand(client *models.Client, baseDir strin
 	}
 
 	// Add save directory
-	eventsDir := filepath.Join(baseDir, "users", client.UserID, "clients", client.ID, "events")
-	args = append(args, "--saveDir", eventsDir)
+	args = append(args, "--saveDir", clientEventsDir(baseDir, client))
 
 	// Add HTTPie flag if enabled
 	if client.HTTPie {
@@ -224,38 +630,100 @@ func (s *ProcessService) buildGosmeeCommand(client *models.Client, baseDir strin
 		args = append(args, "--ignore-event", event)
 	}
 
-	// Add noReplay flag if enabled
-	if client.NoReplay {
-		args = append(args, "--noReplay")
-	}
+	// gosmee only saves events, the backend forwards them (see EventService.ForwardNewEvents)
+	args = append(args, "--noReplay")
 
 	// Add SSE buffer size
 	if client.SSEBufferSize > 0 {
 		args = append(args, "--sse-buffer-size", fmt.Sprintf("%d", client.SSEBufferSize))
 	}
 
+	// Add verbosity flags of the client's log level
+	args = append(args, gosmeeLogLevelFlags[client.LogLevel]...)
+
 	// Add Smee URL and Target URL (positional arguments)
 	args = append(args, client.SmeeURL, client.TargetURL)
 
-	cmd := exec.Command("gosmee", args...)
+	cmd := exec.Command(gosmeeBinary, args...)
 
 	return cmd, nil
 }
 
-// collectLogs collects logs from stdout/stderr and broadcasts to listeners.
+// UnsupportedFlags returns the flags the client's gosmee process would be started with that
+// the installed gosmee client does not support. It fails if gosmee is not installed.
+func (s *ProcessService) UnsupportedFlags(client *models.Client) ([]string, error) {
+	help, err := s.gosmeeClientHelp()
+	if err != nil {
+		return nil, err
+	}
+
+	cmd, err := s.buildGosmeeCommand(client, "")
+	if err != nil {
+		return nil, err
+	}
+	var unsupported []string
+	for _, arg := range cmd.Args[1:] {
+		if strings.HasPrefix(arg, "--") && !strings.Contains(help, arg) && !slices.Contains(unsupported, arg) {
+			unsupported = append(unsupported, arg)
+		}
+	}
+	return unsupported, nil
+}
+
+// gosmeeClientHelp returns the help of the gosmee client command, running gosmee once.
+func (s *ProcessService) gosmeeClientHelp() (string, error) {
+	s.gosmeeHelpMu.Lock()
+	defer s.gosmeeHelpMu.Unlock()
+
+	if s.gosmeeHelp != "" {
+		return s.gosmeeHelp, nil
+	}
+	path, err := exec.LookPath(gosmeeBinary)
+	if err != nil {
+		return "", fmt.Errorf("gosmee binary not found in PATH")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	help, err := exec.CommandContext(ctx, path, "client", "--help").CombinedOutput()
+	if len(help) == 0 {
+		return "", fmt.Errorf("%s does not run: %v", path, err)
+	}
+	s.gosmeeHelp = string(help)
+	return s.gosmeeHelp, nil
+}
+
+// clientEventsDir returns the directory gosmee saves the events of a client to.
+func clientEventsDir(baseDir string, client *models.Client) string {
+	return filepath.Join(baseDir, "users", client.UserID, "clients", client.ID, "events")
+}
+
+// collectLogs collects logs from stdout/stderr as structured records, appends them to the
+// daily log file (one JSON record per line) and broadcasts them to listeners.
 func (s *ProcessService) collectLogs(ctx *processContext, pipe interface{}, source string) {
+	defer ctx.collectors.Done()
+
 	scanner := bufio.NewScanner(pipe.(interface{ Read([]byte) (int, error) }))
 
 	for scanner.Scan() {
 		line := scanner.Text()
-		timestamp := time.Now().Format("2006-01-02 15:04:05")
-		logLine := fmt.Sprintf("[%s] [%s] %s", timestamp, source, line)
+		if ctx.masker != nil {
+			line = ctx.masker(ctx.client.UserID).MaskText(line)
+		}
+		now := time.Now()
+		record := newLogRecord(now, source, line)
+
+		// Persist full history to disk
+		if encoded, err := encodeLogRecord(record); err != nil {
+			s.log.Error("Failed to encode log of client %s: %v", ctx.client.ID, err)
+		} else if err := ctx.logWriter.WriteLine(now, encoded); err != nil {
+			s.log.Error("Failed to persist log of client %s: %v", ctx.client.ID, err)
+		}
 
 		// Add to process info
-		ctx.processInfo.AddLog(logLine)
+		ctx.processInfo.AddLog(record)
 
 		// Also log to application logger
-		s.log.Debug("[Client %s] %s", ctx.client.ID, logLine)
+		s.log.Debug("[Client %s] %s", ctx.client.ID, record)
 	}
 
 	if err := scanner.Err(); err != nil {
@@ -265,35 +733,144 @@ func (s *ProcessService) collectLogs(ctx *processContext, pipe interface{}, sour
 
 // monitorProcess monitors the process and handles restarts.
 func (s *ProcessService) monitorProcess(ctx *processContext) {
-	// Wait for process to finish
-	err := ctx.cmd.Wait()
+	// Wait for process to finish, after draining its output (Wait closes the pipes), so the
+	// last log lines are available to classify a crash
+	ctx.collectors.Wait()
+	err := ctx.wait()
+	s.removePIDFile(ctx)
 
 	// Check if it was a normal stop
 	select {
 	case <-ctx.stopChan:
 		// Normal stop, don't restart
+		s.retainLastRun(ctx, nil)
+		close(ctx.exitChan)
 		s.log.Info("Client %s stopped normally", ctx.client.ID)
 		return
 	default:
 	}
 
 	// Process crashed
+	exit := &ProcessExit{
+		ClientID: ctx.client.ID,
+		ExitCode: ctx.exitCode(),
+		Error:    "process exited unexpectedly",
+	}
 	if err != nil {
-		s.log.Error("Client %s process crashed: %v", ctx.client.ID, err)
-		ctx.processInfo.LastError = err.Error()
-		ctx.processInfo.Status = models.ClientStatusError
+		exit.Error = err.Error()
+	}
+	exit.Category = classifyProcessError(ctx.client, exit.Error, ctx.processInfo.GetLogLines())
+	s.log.Error("Client %s process crashed (%s): %s", ctx.client.ID, exit.Category, exit.Error)
+	s.mu.Lock()
+	ctx.processInfo.LastError = exit.Error
+	ctx.processInfo.Status = models.ClientStatusError
+	s.mu.Unlock()
+	s.retainLastRun(ctx, exit)
+	close(ctx.exitChan)
+
+	if s.autoRestart {
+		if restarts, ok := s.reserveRestart(ctx.client.ID); ok {
+			exit.Restarting = true
+			s.notifyExit(exit)
+			s.autoRestartProcess(ctx, restarts)
+			return
+		}
+
+		exit.CrashLooping = true
+		exit.Error = fmt.Sprintf("crash-looping: exceeded %d restarts within %s (last error: %s)",
+			s.maxRestartCount, s.restartWindow, exit.Error)
+		s.mu.Lock()
+		ctx.processInfo.LastError = exit.Error
+		s.mu.Unlock()
+		s.log.Error("Client %s is crash-looping, auto-restart disabled until it is started manually", ctx.client.ID)
 	}
 
-	// Auto restart if enabled
-	if s.autoRestart && ctx.restartCount < s.maxRestartCount {
-		ctx.restartCount++
-		s.log.Info("Auto-restarting client %s (attempt %d/%d)", ctx.client.ID, ctx.restartCount, s.maxRestartCount)
+	s.removeProcess(ctx)
+	s.notifyExit(exit)
+}
 
-		// Wait a moment before restart
-		time.Sleep(2 * time.Second)
+// removeProcess drops the context of an exited process so the client can be started again.
+func (s *ProcessService) removeProcess(ctx *processContext) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if current, exists := s.processes[ctx.client.ID]; exists && current == ctx {
+		ctx.processInfo.CloseAllLogListeners()
+		delete(s.processes, ctx.client.ID)
+		if s.watcher != nil {
+			s.watcher.Unwatch(ctx.client.ID)
+		}
+	}
+}
+
+// reserveRestart records an automatic restart if the client's restart budget allows it.
+// It returns the number of restarts within the current window.
+func (s *ProcessService) reserveRestart(clientID string) (int, bool) {
+	s.historyMu.Lock()
+	defer s.historyMu.Unlock()
+
+	cutoff := time.Now().Add(-s.restartWindow)
+	history := s.restartHistory[clientID][:0]
+	for _, restartedAt := range s.restartHistory[clientID] {
+		if restartedAt.After(cutoff) {
+			history = append(history, restartedAt)
+		}
+	}
+
+	if len(history) >= s.maxRestartCount {
+		s.restartHistory[clientID] = history
+		return len(history), false
+	}
+
+	history = append(history, time.Now())
+	s.restartHistory[clientID] = history
+	return len(history), true
+}
+
+// ResetRestartBudget clears the automatic restart history of a client (e.g. on manual start).
+func (s *ProcessService) ResetRestartBudget(clientID string) {
+	s.historyMu.Lock()
+	defer s.historyMu.Unlock()
+
+	delete(s.restartHistory, clientID)
+}
+
+// autoRestartProcess restarts a crashed process after a short delay.
+func (s *ProcessService) autoRestartProcess(ctx *processContext, restarts int) {
+	clientID := ctx.client.ID
+	s.log.Info("Auto-restarting client %s (%d/%d restarts within %s)", clientID, restarts, s.maxRestartCount, s.restartWindow)
+
+	// Wait a moment before restart
+	time.Sleep(2 * time.Second)
+
+	s.mu.RLock()
+	current, exists := s.processes[clientID]
+	s.mu.RUnlock()
+	if !exists || current != ctx {
+		// Stopped or restarted by someone else in the meantime
+		return
+	}
+	s.removeProcess(ctx)
+
+	if err := s.Start(ctx.client, ctx.baseDir); err != nil {
+		s.log.Error("Auto-restart of client %s failed: %v", clientID, err)
+		s.notifyExit(&ProcessExit{ClientID: clientID, ExitCode: -1, Error: err.Error(),
+			Category: classifyProcessError(ctx.client, err.Error(), nil)})
+		return
+	}
+
+	if info, err := s.GetProcessInfo(clientID); err == nil {
+		info.RestartCount = restarts
+	}
+}
+
+// notifyExit forwards an unexpected exit to the registered handler.
+func (s *ProcessService) notifyExit(exit *ProcessExit) {
+	s.mu.RLock()
+	handler := s.exitHandler
+	s.mu.RUnlock()
 
-		// Restart (this requires client object and baseDir, which we need to pass through)
-		// For now, we'll just log - actual restart should be triggered from ClientService
-		s.log.Info("Auto-restart not implemented yet - please restart manually")
+	if handler != nil {
+		handler(exit)
 	}
 }
@@ -0,0 +1,47 @@
+package service_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("Process start queue", func() {
+	It("starts one process at a time once the limit is reached", func() {
+		binDir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(binDir, "gosmee"), []byte("#!/bin/sh\nsleep 30\n"), 0755)).To(Succeed())
+		GinkgoT().Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+		processService := service.NewProcessService(false, 0, time.Minute, logger.New())
+		processService.SetStartupCheck(500*time.Millisecond, nil, 0)
+		processService.SetMaxConcurrentStarts(1)
+		DeferCleanup(processService.StopAll)
+
+		baseDir := GinkgoT().TempDir()
+		client := func(i int) *models.Client {
+			return &models.Client{
+				ID:        fmt.Sprintf("client-queue-%d", i),
+				UserID:    "user-queue",
+				SmeeURL:   "https://smee.example.com/channel",
+				TargetURL: "http://127.0.0.1:1/hook",
+			}
+		}
+
+		Expect(processService.Start(client(1), baseDir)).To(Succeed())
+		done := make(chan error, 1)
+		go func() { done <- processService.Start(client(2), baseDir) }()
+
+		Consistently(func() bool { return processService.IsRunning("client-queue-2") }, 300*time.Millisecond, 50*time.Millisecond).Should(BeFalse())
+		Eventually(done, 2*time.Second).Should(Receive(BeNil()))
+		Expect(processService.IsRunning("client-queue-1")).To(BeTrue())
+		Expect(processService.IsRunning("client-queue-2")).To(BeTrue())
+	})
+})
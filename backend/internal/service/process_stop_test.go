@@ -0,0 +1,77 @@
+package service_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("Stopping processes", func() {
+	var (
+		processService *service.ProcessService
+		client         *models.Client
+	)
+
+	BeforeEach(func() {
+		// A gosmee ignoring SIGTERM, e.g. while flushing deliveries
+		binDir := GinkgoT().TempDir()
+		script := "#!/bin/sh\ntrap '' TERM\nwhile true; do sleep 0.1; done\n"
+		Expect(os.WriteFile(filepath.Join(binDir, "gosmee"), []byte(script), 0755)).To(Succeed())
+		GinkgoT().Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+		processService = service.NewProcessService(false, 0, time.Minute, logger.New())
+		client = &models.Client{ID: "client-stop", UserID: "user-stop", SmeeURL: "https://smee.example.com/channel", TargetURL: "http://127.0.0.1:1/hook"}
+		Expect(processService.Start(client, GinkgoT().TempDir())).To(Succeed())
+		// Give the script time to install its trap
+		time.Sleep(100 * time.Millisecond)
+	})
+
+	It("kills a process after the configured stop timeout", func() {
+		processService.SetStopTimeout(300 * time.Millisecond)
+
+		started := time.Now()
+		Expect(processService.Stop(client.ID)).To(Succeed())
+		Expect(time.Since(started)).To(BeNumerically(">=", 300*time.Millisecond))
+		Expect(processService.IsRunning(client.ID)).To(BeFalse())
+	})
+
+	It("kills a process right away when forced", func() {
+		processService.SetStopTimeout(time.Minute)
+
+		started := time.Now()
+		Expect(processService.StopWithTimeout(client.ID, 0)).To(Succeed())
+		Expect(time.Since(started)).To(BeNumerically("<", time.Second))
+		Eventually(func() (*models.ProcessLastRun, error) {
+			return processService.GetLastRun(client.ID, models.LogFilter{})
+		}, 5*time.Second).Should(HaveField("ExitCode", -1))
+	})
+
+	It("keeps serving the other clients while a process is slow to stop", func() {
+		other := &models.Client{ID: "client-other", UserID: "user-stop", SmeeURL: "https://smee.example.com/other", TargetURL: "http://127.0.0.1:1/hook"}
+		Expect(processService.Start(other, GinkgoT().TempDir())).To(Succeed())
+
+		stopped := make(chan error, 1)
+		go func() {
+			stopped <- processService.StopWithTimeout(client.ID, 3*time.Second)
+		}()
+		time.Sleep(100 * time.Millisecond)
+
+		started := time.Now()
+		Expect(processService.IsRunning(other.ID)).To(BeTrue())
+		Expect(processService.StopWithTimeout(other.ID, 0)).To(Succeed())
+		third := &models.Client{ID: "client-third", UserID: "user-stop", SmeeURL: "https://smee.example.com/third", TargetURL: "http://127.0.0.1:1/hook"}
+		Expect(processService.Start(third, GinkgoT().TempDir())).To(Succeed())
+		Expect(time.Since(started)).To(BeNumerically("<", time.Second))
+		Expect(processService.StopWithTimeout(third.ID, 0)).To(Succeed())
+
+		Consistently(stopped, 500*time.Millisecond).ShouldNot(Receive())
+		Eventually(stopped, 5*time.Second).Should(Receive(BeNil()))
+	})
+})
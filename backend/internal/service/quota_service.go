@@ -5,34 +5,176 @@ package service
 
 import (
 	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
 	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
 	"github.com/lazycatapps/gosmee/backend/internal/repository"
 )
 
+// quotaHistoryRetention is how long quota snapshots are kept.
+const quotaHistoryRetention = 90 * 24 * time.Hour
+
+// quotaAlertTopClients is how many of the biggest clients a quota alert lists.
+const quotaAlertTopClients = 3
+
 // QuotaService manages user quotas.
 type QuotaService struct {
-	quotaRepo repository.QuotaRepository
-	log       logger.Logger
+	quotaRepo   repository.QuotaRepository
+	historyRepo repository.QuotaHistoryRepository
+	log         logger.Logger
+
+	// Storage thresholds in percent, users can override them in their settings (see SetThresholds)
+	settingsRepo     repository.SettingsRepository
+	warningThreshold float64
+	fullThreshold    float64
+
+	// Threshold alerts (optional, see SetAlerts)
+	notificationService *NotificationService
+	clientRepo          repository.ClientRepository
+	alertThresholds     []int
+	alertsMu            sync.Mutex
+	alerted             map[string]int // userID -> highest threshold already notified
+
+	processService *ProcessService // Reports running clients and their limit (optional)
 }
 
 // NewQuotaService creates a new quota service.
-func NewQuotaService(quotaRepo repository.QuotaRepository, log logger.Logger) *QuotaService {
+func NewQuotaService(quotaRepo repository.QuotaRepository, historyRepo repository.QuotaHistoryRepository, log logger.Logger) *QuotaService {
 	return &QuotaService{
-		quotaRepo: quotaRepo,
-		log:       log,
+		quotaRepo:        quotaRepo,
+		historyRepo:      historyRepo,
+		log:              log,
+		warningThreshold: models.DefaultStorageWarningThreshold,
+		fullThreshold:    models.DefaultStorageFullThreshold,
+		alerted:          make(map[string]int),
 	}
 }
 
-// GetQuota retrieves quota information for a user.
+// SetThresholds sets the server-wide storage warning and full thresholds (percent of the
+// quota) and enables per-user overrides stored in the user settings.
+func (s *QuotaService) SetThresholds(settingsRepo repository.SettingsRepository, warning, full float64) {
+	s.settingsRepo = settingsRepo
+	s.warningThreshold = warning
+	s.fullThreshold = full
+}
+
+// SetAlerts enables notifications when a user's storage usage reaches one of the
+// thresholds (percentages in ascending order), checked by CheckThresholds.
+func (s *QuotaService) SetAlerts(notificationService *NotificationService, clientRepo repository.ClientRepository, thresholds []int) {
+	s.notificationService = notificationService
+	s.clientRepo = clientRepo
+	s.alertThresholds = thresholds
+}
+
+// SetProcessService makes quotas report the running clients of users and their limit.
+func (s *QuotaService) SetProcessService(processService *ProcessService) {
+	s.processService = processService
+}
+
+// GetQuota retrieves quota information for a user, with the user's storage thresholds.
 func (s *QuotaService) GetQuota(userID string) (*models.Quota, error) {
-	quota, err := s.quotaRepo.GetQuota(userID)
+	cached, err := s.quotaRepo.GetQuota(userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get quota: %w", err)
 	}
 
-	return quota, nil
+	// Copy, the repository caches the quota for all callers
+	quota := *cached
+	quota.WarningThreshold, quota.FullThreshold = s.thresholdsFor(userID)
+	if s.processService != nil {
+		quota.RunningClients, quota.MaxRunningClients = s.processService.RunningLimit(userID)
+	}
+
+	return &quota, nil
+}
+
+// Refresh drops the cached quota of a user, so the next check uses the current usage.
+func (s *QuotaService) Refresh(userID string) {
+	s.quotaRepo.InvalidateCache(userID)
+}
+
+// GetSettings retrieves the storage threshold overrides of a user (0 = server default).
+func (s *QuotaService) GetSettings(userID string) (*models.QuotaSettings, error) {
+	if s.settingsRepo == nil {
+		return &models.QuotaSettings{}, nil
+	}
+
+	settings, err := s.settingsRepo.Get(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &settings.Quota, nil
+}
+
+// UpdateSettings replaces the storage threshold overrides of a user. The full threshold cannot
+// exceed the server's, and the warning threshold cannot exceed the full threshold.
+func (s *QuotaService) UpdateSettings(userID string, req *models.QuotaSettingsRequest) (*models.QuotaSettings, error) {
+	if s.settingsRepo == nil {
+		return nil, fmt.Errorf("quota settings are not available")
+	}
+
+	full := s.fullThreshold
+	if req.FullThreshold > 0 {
+		if req.FullThreshold > s.fullThreshold {
+			return nil, apperrors.NewInvalidInput(fmt.Sprintf("fullThreshold cannot exceed the server limit of %g%%", s.fullThreshold))
+		}
+		full = req.FullThreshold
+	}
+	if req.WarningThreshold > full {
+		return nil, apperrors.NewInvalidInput(fmt.Sprintf("warningThreshold cannot exceed the full threshold of %g%%", full))
+	}
+
+	settings, err := s.settingsRepo.Get(userID)
+	if err != nil {
+		return nil, err
+	}
+	settings.Quota = models.QuotaSettings{
+		WarningThreshold: req.WarningThreshold,
+		FullThreshold:    req.FullThreshold,
+	}
+	settings.UpdatedAt = time.Now()
+
+	if err := s.settingsRepo.Save(settings); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Updated storage thresholds of user %s: warning %g%%, full %g%% (0 = default)", userID, req.WarningThreshold, req.FullThreshold)
+	return &settings.Quota, nil
+}
+
+// thresholdsFor returns the effective storage warning and full thresholds of a user.
+// If the user's settings cannot be loaded, the server thresholds apply.
+func (s *QuotaService) thresholdsFor(userID string) (warning, full float64) {
+	warning, full = s.warningThreshold, s.fullThreshold
+	if s.settingsRepo == nil {
+		return warning, full
+	}
+
+	settings, err := s.settingsRepo.Get(userID)
+	if err != nil {
+		s.log.Error("Failed to load quota settings of user %s: %v", userID, err)
+		return warning, full
+	}
+
+	if settings.Quota.FullThreshold > 0 && settings.Quota.FullThreshold <= full {
+		full = settings.Quota.FullThreshold
+	}
+	if settings.Quota.WarningThreshold > 0 {
+		warning = settings.Quota.WarningThreshold
+	}
+	// The server thresholds may have been lowered since the user saved theirs
+	if warning > full {
+		warning = full
+	}
+
+	return warning, full
 }
 
 // CheckCanCreateClient checks if a user can create a new client.
@@ -43,7 +185,7 @@ func (s *QuotaService) CheckCanCreateClient(userID string) error {
 	}
 
 	if !quota.CanCreateClient() {
-		return fmt.Errorf("client limit reached: %d/%d", quota.ClientsCount, quota.MaxClients)
+		return apperrors.NewQuotaExceeded(fmt.Sprintf("client limit reached: %d/%d", quota.ClientsCount, quota.MaxClients))
 	}
 
 	return nil
@@ -57,13 +199,28 @@ func (s *QuotaService) CheckStorageQuota(userID string) error {
 	}
 
 	if quota.IsStorageFull() {
-		return fmt.Errorf("storage quota exceeded: %.2f%% used", quota.Percentage)
+		return apperrors.NewQuotaExceeded(fmt.Sprintf("storage quota exceeded: %.2f%% used", quota.Percentage))
 	}
 
 	return nil
 }
 
-// GetStorageWarning returns a warning message if storage is above 80%.
+// GetClientsWarning returns a warning message if the user reached the soft client limit.
+func (s *QuotaService) GetClientsWarning(userID string) (string, error) {
+	quota, err := s.GetQuota(userID)
+	if err != nil {
+		return "", err
+	}
+
+	if quota.IsClientsSoftLimitReached() {
+		return fmt.Sprintf("Warning: %d clients reach the soft limit of %d (hard limit: %d) - consider deleting unused clients",
+			quota.ClientsCount, quota.SoftMaxClients, quota.MaxClients), nil
+	}
+
+	return "", nil
+}
+
+// GetStorageWarning returns a warning message if storage reached the user's warning threshold.
 func (s *QuotaService) GetStorageWarning(userID string) (string, error) {
 	quota, err := s.GetQuota(userID)
 	if err != nil {
@@ -76,3 +233,221 @@ func (s *QuotaService) GetStorageWarning(userID string) (string, error) {
 
 	return "", nil
 }
+
+// RecordSnapshots samples the current quota usage of every user into the quota history
+// and drops snapshots older than the retention. It is executed periodically by the scheduler.
+func (s *QuotaService) RecordSnapshots() {
+	userIDs, err := s.quotaRepo.ListUsers()
+	if err != nil {
+		s.log.Error("Failed to list users for quota snapshots: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for _, userID := range userIDs {
+		// Sample fresh usage instead of the cached quota, so the history has the actual trend
+		usedBytes, err := s.quotaRepo.CalculateUsage(userID)
+		if err != nil {
+			s.log.Error("Failed to calculate storage usage of user %s: %v", userID, err)
+			continue
+		}
+		clientsCount, err := s.quotaRepo.CountClients(userID)
+		if err != nil {
+			s.log.Error("Failed to count clients of user %s: %v", userID, err)
+			continue
+		}
+		quota, err := s.GetQuota(userID)
+		if err != nil {
+			s.log.Error("Failed to get quota of user %s: %v", userID, err)
+			continue
+		}
+
+		snapshot := &models.QuotaSnapshot{
+			Time:         now,
+			UsedBytes:    usedBytes,
+			TotalBytes:   quota.TotalBytes,
+			ClientsCount: clientsCount,
+		}
+		if err := s.historyRepo.Append(userID, snapshot); err != nil {
+			s.log.Error("Failed to record quota snapshot of user %s: %v", userID, err)
+			continue
+		}
+		if err := s.historyRepo.Prune(userID, now.Add(-quotaHistoryRetention)); err != nil {
+			s.log.Error("Failed to prune quota history of user %s: %v", userID, err)
+		}
+	}
+}
+
+// GetHistory returns the quota snapshots of a user within the last req.Days days, with the
+// storage growth trend and, if usage is growing, when the quota will be reached.
+func (s *QuotaService) GetHistory(userID string, req *models.QuotaHistoryRequest) (*models.QuotaHistoryResponse, error) {
+	since := time.Now().Add(-time.Duration(req.Days) * 24 * time.Hour)
+	snapshots, err := s.historyRepo.List(userID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quota history: %w", err)
+	}
+
+	response := &models.QuotaHistoryResponse{
+		Snapshots:         snapshots,
+		GrowthBytesPerDay: math.Round(growthPerDay(snapshots)*100) / 100,
+	}
+
+	if len(snapshots) == 0 || response.GrowthBytesPerDay <= 0 {
+		return response, nil
+	}
+	latest := snapshots[len(snapshots)-1]
+	if latest.TotalBytes <= 0 {
+		return response, nil
+	}
+
+	days := math.Max(float64(latest.TotalBytes-latest.UsedBytes)/response.GrowthBytesPerDay, 0)
+	days = math.Round(days*10) / 10
+	fullAt := latest.Time.Add(time.Duration(days * float64(24*time.Hour)))
+	response.DaysUntilFull = &days
+	response.EstimatedFullAt = &fullAt
+
+	return response, nil
+}
+
+// growthPerDay fits a line through the used storage of the snapshots (least squares)
+// and returns its slope in bytes per day; 0 if there are fewer than two points in time.
+func growthPerDay(snapshots []*models.QuotaSnapshot) float64 {
+	if len(snapshots) < 2 {
+		return 0
+	}
+
+	origin := snapshots[0].Time
+	var meanX, meanY float64
+	for _, snapshot := range snapshots {
+		meanX += snapshot.Time.Sub(origin).Hours() / 24
+		meanY += float64(snapshot.UsedBytes)
+	}
+	meanX /= float64(len(snapshots))
+	meanY /= float64(len(snapshots))
+
+	var covariance, variance float64
+	for _, snapshot := range snapshots {
+		dx := snapshot.Time.Sub(origin).Hours()/24 - meanX
+		covariance += dx * (float64(snapshot.UsedBytes) - meanY)
+		variance += dx * dx
+	}
+	if variance == 0 {
+		return 0
+	}
+
+	return covariance / variance
+}
+
+// CheckThresholds notifies users whose storage usage reached a higher alert threshold since
+// the previous check. Each threshold is notified once; it is re-armed when usage drops below
+// it again. It is executed periodically by the scheduler.
+func (s *QuotaService) CheckThresholds() {
+	if s.notificationService == nil || len(s.alertThresholds) == 0 {
+		return
+	}
+
+	userIDs, err := s.quotaRepo.ListUsers()
+	if err != nil {
+		s.log.Error("Failed to list users for quota alerts: %v", err)
+		return
+	}
+
+	s.alertsMu.Lock()
+	defer s.alertsMu.Unlock()
+
+	for _, userID := range userIDs {
+		quota, err := s.GetQuota(userID)
+		if err != nil {
+			s.log.Error("Failed to get quota of user %s: %v", userID, err)
+			continue
+		}
+		if quota.TotalBytes <= 0 {
+			continue
+		}
+		// The cached quota may be up to an hour old, alerts use the current usage
+		usedBytes, err := s.quotaRepo.CalculateUsage(userID)
+		if err != nil {
+			s.log.Error("Failed to calculate storage usage of user %s: %v", userID, err)
+			continue
+		}
+
+		percentage := float64(usedBytes) / float64(quota.TotalBytes) * 100
+		reached := 0
+		for _, threshold := range s.alertThresholds {
+			if percentage >= float64(threshold) {
+				reached = threshold
+			}
+		}
+
+		previous := s.alerted[userID]
+		if reached == previous {
+			continue
+		}
+		if reached == 0 {
+			delete(s.alerted, userID)
+		} else {
+			s.alerted[userID] = reached
+		}
+		if reached < previous {
+			continue
+		}
+
+		level := models.NotificationLevelWarning
+		if reached >= 100 {
+			level = models.NotificationLevelError
+		}
+		message := fmt.Sprintf("Storage usage reached %d%% of the quota (%s of %s, %.1f%%)",
+			reached, formatBytes(usedBytes), formatBytes(quota.TotalBytes), percentage)
+		if biggest := s.biggestClients(userID); biggest != "" {
+			message += ". Biggest clients: " + biggest
+		}
+		s.notificationService.Notify(userID, "", "quota_threshold", level, message)
+	}
+}
+
+// biggestClients describes the clients of a user using the most storage, e.g.
+// `"GitHub" (1.2 GB), "Stripe" (300.0 MB)`.
+func (s *QuotaService) biggestClients(userID string) string {
+	clients, err := s.clientRepo.GetByUserID(userID)
+	if err != nil {
+		s.log.Error("Failed to list clients of user %s for quota alert: %v", userID, err)
+		return ""
+	}
+
+	type clientUsage struct {
+		name  string
+		bytes int64
+	}
+	usages := make([]clientUsage, 0, len(clients))
+	for _, client := range clients {
+		bytes, err := s.quotaRepo.CalculateClientUsage(userID, client.ID)
+		if err != nil {
+			s.log.Error("Failed to calculate storage usage of client %s: %v", client.ID, err)
+			continue
+		}
+		usages = append(usages, clientUsage{name: client.Name, bytes: bytes})
+	}
+	sort.Slice(usages, func(i, j int) bool { return usages[i].bytes > usages[j].bytes })
+
+	parts := []string{}
+	for i := 0; i < len(usages) && i < quotaAlertTopClients; i++ {
+		parts = append(parts, fmt.Sprintf("%q (%s)", usages[i].name, formatBytes(usages[i].bytes)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatBytes formats a byte count with a binary unit, e.g. "1.5 GB".
+func formatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	value := float64(bytes) / unit
+	for _, suffix := range []string{"KB", "MB", "GB"} {
+		if value < unit {
+			return fmt.Sprintf("%.1f %s", value, suffix)
+		}
+		value /= unit
+	}
+	return fmt.Sprintf("%.1f TB", value)
+}
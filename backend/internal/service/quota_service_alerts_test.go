@@ -0,0 +1,82 @@
+package service_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("QuotaService threshold alerts", func() {
+	var (
+		baseDir             string
+		quotaService        *service.QuotaService
+		notificationService *service.NotificationService
+	)
+
+	BeforeEach(func() {
+		baseDir = GinkgoT().TempDir()
+		log := logger.New()
+		clientRepo, err := repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		quotaRepo := repository.NewFileQuotaRepository(baseDir, 100000, 10)
+		notificationService = service.NewNotificationService(log)
+		quotaService = service.NewQuotaService(quotaRepo, repository.NewFileQuotaHistoryRepository(baseDir), log)
+		quotaService.SetAlerts(notificationService, clientRepo, []int{80, 95, 100})
+
+		Expect(clientRepo.Create(&models.Client{ID: "client-big", UserID: "user-alerts", Name: "Big"})).To(Succeed())
+		Expect(clientRepo.Create(&models.Client{ID: "client-small", UserID: "user-alerts", Name: "Small"})).To(Succeed())
+	})
+
+	// setUsage fills the events directories so the user's total usage is roughly the given bytes
+	setUsage := func(big, small int) {
+		for clientID, size := range map[string]int{"client-big": big, "client-small": small} {
+			dir := filepath.Join(baseDir, "users", "user-alerts", "clients", clientID, "events")
+			Expect(os.MkdirAll(dir, 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "data.json"), make([]byte, size), 0644)).To(Succeed())
+		}
+	}
+
+	It("notifies once per crossed threshold with the biggest clients", func() {
+		setUsage(50000, 10000)
+		quotaService.CheckThresholds()
+		Expect(notificationService.List("user-alerts", false).Notifications).To(BeEmpty())
+
+		setUsage(70000, 12000)
+		quotaService.CheckThresholds()
+		quotaService.CheckThresholds()
+		list := notificationService.List("user-alerts", false)
+		Expect(list.Notifications).To(HaveLen(1))
+		Expect(list.Notifications[0].Type).To(Equal("quota_threshold"))
+		Expect(list.Notifications[0].Level).To(Equal(models.NotificationLevelWarning))
+		Expect(list.Notifications[0].Message).To(ContainSubstring("reached 80%"))
+		Expect(list.Notifications[0].Message).To(MatchRegexp(`Biggest clients: "Big" \(68\.\d KB\), "Small"`))
+
+		// Jumping past several thresholds notifies the highest one
+		setUsage(90000, 12000)
+		quotaService.CheckThresholds()
+		list = notificationService.List("user-alerts", false)
+		Expect(list.Notifications).To(HaveLen(2))
+		Expect(list.Notifications[0].Level).To(Equal(models.NotificationLevelError))
+		Expect(list.Notifications[0].Message).To(ContainSubstring("reached 100%"))
+	})
+
+	It("re-arms a threshold after usage drops below it", func() {
+		setUsage(70000, 12000)
+		quotaService.CheckThresholds()
+
+		setUsage(10000, 1000)
+		quotaService.CheckThresholds()
+		Expect(notificationService.List("user-alerts", false).Notifications).To(HaveLen(1))
+
+		setUsage(70000, 12000)
+		quotaService.CheckThresholds()
+		Expect(notificationService.List("user-alerts", false).Notifications).To(HaveLen(2))
+	})
+})
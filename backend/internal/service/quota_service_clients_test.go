@@ -0,0 +1,66 @@
+package service_test
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("QuotaService client limits", func() {
+	It("warns from the soft limit and blocks at the hard limit", func() {
+		baseDir := GinkgoT().TempDir()
+		log := logger.New()
+		clientRepo, err := repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		quotaRepo := repository.NewFileQuotaRepository(baseDir, 10*1024*1024, 3)
+		quotaRepo.SetSoftClientLimit(2)
+		quotaService := service.NewQuotaService(quotaRepo, repository.NewFileQuotaHistoryRepository(baseDir), log)
+		clientService := service.NewClientService(
+			clientRepo,
+			quotaRepo,
+			repository.NewFileEventRepository(baseDir),
+			service.NewProcessService(false, 0, time.Minute, log),
+			service.NewJobService(time.Hour, log),
+			baseDir,
+			log,
+		)
+
+		create := func(i int) error {
+			_, err := clientService.Create("user-limits", &models.ClientRequest{
+				Name:      fmt.Sprintf("client-%d", i),
+				SmeeURL:   "https://smee.io/abc",
+				TargetURL: "http://localhost:3000",
+			})
+			return err
+		}
+
+		Expect(create(1)).To(Succeed())
+		warning, err := quotaService.GetClientsWarning("user-limits")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warning).To(BeEmpty())
+
+		Expect(create(2)).To(Succeed())
+		quota, err := quotaService.GetQuota("user-limits")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(quota.SoftMaxClients).To(Equal(2))
+		Expect(quota.MaxClients).To(Equal(3))
+		warning, err = quotaService.GetClientsWarning("user-limits")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warning).To(ContainSubstring("soft limit of 2"))
+
+		// Above the soft limit creating is still allowed, up to the hard limit
+		Expect(create(3)).To(Succeed())
+		var appErr *apperrors.AppError
+		Expect(errors.As(create(4), &appErr)).To(BeTrue())
+		Expect(appErr.Code).To(Equal(apperrors.CodeQuotaExceeded))
+	})
+})
@@ -0,0 +1,80 @@
+package service_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("QuotaService history", func() {
+	var (
+		baseDir      string
+		historyRepo  *repository.FileQuotaHistoryRepository
+		quotaService *service.QuotaService
+	)
+
+	BeforeEach(func() {
+		baseDir = GinkgoT().TempDir()
+		historyRepo = repository.NewFileQuotaHistoryRepository(baseDir)
+		quotaService = service.NewQuotaService(repository.NewFileQuotaRepository(baseDir, 10000, 10), historyRepo, logger.New())
+	})
+
+	It("records snapshots of every user and prunes expired ones", func() {
+		clientDir := filepath.Join(baseDir, "users", "user-quota", "clients", "client-1")
+		Expect(os.MkdirAll(clientDir, 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(clientDir, "config.json"), make([]byte, 1500), 0644)).To(Succeed())
+		Expect(historyRepo.Append("user-quota", &models.QuotaSnapshot{Time: time.Now().AddDate(0, 0, -100), UsedBytes: 1})).To(Succeed())
+
+		quotaService.RecordSnapshots()
+
+		snapshots, err := historyRepo.List("user-quota", time.Time{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(snapshots).To(HaveLen(1))
+		Expect(snapshots[0].UsedBytes).To(BeNumerically(">=", 1500))
+		Expect(snapshots[0].TotalBytes).To(Equal(int64(10000)))
+		Expect(snapshots[0].ClientsCount).To(Equal(1))
+	})
+
+	It("returns the growth trend and predicts when the quota is reached", func() {
+		now := time.Now()
+		for i, used := range []int64{1000, 2000, 3000, 4000} {
+			ts := now.AddDate(0, 0, i-3)
+			Expect(historyRepo.Append("user-quota", &models.QuotaSnapshot{Time: ts, UsedBytes: used, TotalBytes: 10000})).To(Succeed())
+		}
+
+		history, err := quotaService.GetHistory("user-quota", &models.QuotaHistoryRequest{Days: 30})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(history.Snapshots).To(HaveLen(4))
+		Expect(history.GrowthBytesPerDay).To(BeNumerically("~", 1000, 0.01))
+		Expect(history.DaysUntilFull).NotTo(BeNil())
+		Expect(*history.DaysUntilFull).To(BeNumerically("~", 6, 0.01))
+		Expect(history.EstimatedFullAt.Sub(now)).To(BeNumerically("~", 6*24*time.Hour, time.Minute))
+
+		// Only the requested window is used
+		history, err = quotaService.GetHistory("user-quota", &models.QuotaHistoryRequest{Days: 1})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(history.Snapshots).To(HaveLen(1))
+		Expect(history.GrowthBytesPerDay).To(BeZero())
+		Expect(history.DaysUntilFull).To(BeNil())
+	})
+
+	It("does not predict a full quota when usage shrinks", func() {
+		now := time.Now()
+		Expect(historyRepo.Append("user-quota", &models.QuotaSnapshot{Time: now.AddDate(0, 0, -1), UsedBytes: 5000, TotalBytes: 10000})).To(Succeed())
+		Expect(historyRepo.Append("user-quota", &models.QuotaSnapshot{Time: now, UsedBytes: 3000, TotalBytes: 10000})).To(Succeed())
+
+		history, err := quotaService.GetHistory("user-quota", &models.QuotaHistoryRequest{Days: 30})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(history.GrowthBytesPerDay).To(BeNumerically("~", -2000, 0.01))
+		Expect(history.DaysUntilFull).To(BeNil())
+		Expect(history.EstimatedFullAt).To(BeNil())
+	})
+})
@@ -0,0 +1,83 @@
+package service_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("QuotaService storage thresholds", func() {
+	var quotaService *service.QuotaService
+
+	BeforeEach(func() {
+		baseDir := GinkgoT().TempDir()
+		quotaService = service.NewQuotaService(
+			repository.NewFileQuotaRepository(baseDir, 10000, 10),
+			repository.NewFileQuotaHistoryRepository(baseDir),
+			logger.New(),
+		)
+		quotaService.SetThresholds(repository.NewFileSettingsRepository(baseDir), 70, 90)
+
+		// 75% used
+		dir := filepath.Join(baseDir, "users", "user-thresholds", "clients", "client-1")
+		Expect(os.MkdirAll(dir, 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "events.json"), make([]byte, 7500), 0644)).To(Succeed())
+	})
+
+	It("applies the server thresholds and exposes them in the quota", func() {
+		quota, err := quotaService.GetQuota("user-thresholds")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(quota.WarningThreshold).To(Equal(70.0))
+		Expect(quota.FullThreshold).To(Equal(90.0))
+
+		warning, err := quotaService.GetStorageWarning("user-thresholds")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warning).To(ContainSubstring("75.00%"))
+		Expect(quotaService.CheckStorageQuota("user-thresholds")).To(Succeed())
+	})
+
+	It("applies per-user overrides", func() {
+		settings, err := quotaService.UpdateSettings("user-thresholds", &models.QuotaSettingsRequest{WarningThreshold: 60, FullThreshold: 75})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(settings.FullThreshold).To(Equal(75.0))
+
+		quota, err := quotaService.GetQuota("user-thresholds")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(quota.WarningThreshold).To(Equal(60.0))
+		Expect(quota.FullThreshold).To(Equal(75.0))
+
+		var appErr *apperrors.AppError
+		Expect(errors.As(quotaService.CheckStorageQuota("user-thresholds"), &appErr)).To(BeTrue())
+		Expect(appErr.Code).To(Equal(apperrors.CodeQuotaExceeded))
+
+		// Resetting to the server defaults
+		_, err = quotaService.UpdateSettings("user-thresholds", &models.QuotaSettingsRequest{WarningThreshold: 80})
+		Expect(err).NotTo(HaveOccurred())
+		quota, err = quotaService.GetQuota("user-thresholds")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(quota.WarningThreshold).To(Equal(80.0))
+		Expect(quota.FullThreshold).To(Equal(90.0))
+		Expect(quotaService.GetStorageWarning("user-thresholds")).To(BeEmpty())
+	})
+
+	It("rejects overrides beyond the server limit", func() {
+		for _, req := range []*models.QuotaSettingsRequest{
+			{FullThreshold: 95},
+			{WarningThreshold: 85, FullThreshold: 80},
+		} {
+			_, err := quotaService.UpdateSettings("user-thresholds", req)
+			var appErr *apperrors.AppError
+			Expect(errors.As(err, &appErr)).To(BeTrue())
+			Expect(appErr.Code).To(Equal(apperrors.CodeInvalidInput))
+		}
+	})
+})
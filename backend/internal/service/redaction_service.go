@@ -0,0 +1,168 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/masking"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+)
+
+// eventSettleTime is how long an event file must be unmodified before it is rewritten
+// (redacted or encrypted), so files gosmee is still writing are picked up by the next run.
+const eventSettleTime = 2 * time.Second
+
+// RedactionService applies client redaction rules to the events gosmee writes to disk.
+type RedactionService struct {
+	clientRepo repository.ClientRepository
+	eventRepo  repository.EventRepository
+	log        logger.Logger
+
+	watcher *WatcherService // Holds back events not forwarded yet (optional)
+
+	mu    sync.Mutex
+	swept map[string]redactionWatermark // clientID -> progress of the last run
+}
+
+// redactionWatermark records up to which modification time a client's events were redacted,
+// and with which rules.
+type redactionWatermark struct {
+	until time.Time
+	rules string
+}
+
+// NewRedactionService creates a new redaction service.
+func NewRedactionService(clientRepo repository.ClientRepository, eventRepo repository.EventRepository, log logger.Logger) *RedactionService {
+	return &RedactionService{
+		clientRepo: clientRepo,
+		eventRepo:  eventRepo,
+		log:        log,
+		swept:      make(map[string]redactionWatermark),
+	}
+}
+
+// SetWatcher holds back the redaction of new events until the watcher processed them, so
+// events the backend forwards are forwarded with their original payload.
+func (s *RedactionService) SetWatcher(watcher *WatcherService) {
+	s.watcher = watcher
+}
+
+// RedactNewEvents applies redaction rules to events written since the previous run.
+// The first run after startup, and the first run after a client's rules changed,
+// covers all stored events of the client. Events the watcher is still processing are left for
+// a later run. It is executed periodically by the scheduler.
+func (s *RedactionService) RedactNewEvents() {
+	clients, err := s.clientRepo.ListAll()
+	if err != nil {
+		s.log.Error("Failed to list clients for payload redaction: %v", err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	until := time.Now().Add(-eventSettleTime)
+	for _, client := range clients {
+		if len(client.RedactionRules) == 0 {
+			delete(s.swept, client.ID)
+			continue
+		}
+
+		redactions, err := compileRedactionRules(client.RedactionRules)
+		if err != nil {
+			s.log.Error("Invalid redaction rules of client %s: %v", client.ID, err)
+			continue
+		}
+
+		fingerprint := redactionFingerprint(client.RedactionRules)
+		watermark := s.swept[client.ID]
+		if watermark.rules != fingerprint {
+			watermark = redactionWatermark{rules: fingerprint}
+		}
+
+		held := false
+		rewritten, err := s.eventRepo.RewritePayloads(client.ID, watermark.until, until, func(eventID, payload string) (string, bool) {
+			if s.watcher != nil && s.watcher.Processing(client.ID, eventID) {
+				held = true
+				return payload, false
+			}
+			return redactPayload(redactions, payload)
+		}, client.WebhookProvider().SignatureHeaders)
+		if err != nil {
+			s.log.Error("Failed to redact events of client %s: %v", client.ID, err)
+			continue
+		}
+		if rewritten > 0 {
+			s.log.Info("Redacted payloads of %d events of client %s", rewritten, client.ID)
+		}
+
+		if held {
+			// Swept again by the next run, once the held events were forwarded
+			s.swept[client.ID] = watermark
+			continue
+		}
+		watermark.until = until
+		s.swept[client.ID] = watermark
+	}
+}
+
+// RedactPayload applies a client's redaction rules to a payload before it is stored.
+// Payloads that are not JSON, and clients with invalid rules, are left unchanged.
+func RedactPayload(client *models.Client, payload string) string {
+	if len(client.RedactionRules) == 0 {
+		return payload
+	}
+
+	redactions, err := compileRedactionRules(client.RedactionRules)
+	if err != nil {
+		return payload
+	}
+
+	redacted, _ := redactPayload(redactions, payload)
+	return redacted
+}
+
+// ValidateRedactionRules checks that all rules have valid JSONPath expressions.
+func ValidateRedactionRules(rules []models.RedactionRule) error {
+	_, err := compileRedactionRules(rules)
+	return err
+}
+
+// compileRedactionRules compiles redaction rules, defaulting the replacement to masking.Redacted.
+func compileRedactionRules(rules []models.RedactionRule) ([]masking.Redaction, error) {
+	redactions := make([]masking.Redaction, 0, len(rules))
+	for _, rule := range rules {
+		path, err := masking.ParseJSONPath(strings.TrimSpace(rule.Path))
+		if err != nil {
+			return nil, err
+		}
+		replacement := rule.Replace
+		if replacement == "" {
+			replacement = masking.Redacted
+		}
+		redactions = append(redactions, masking.Redaction{Path: path, Replacement: replacement})
+	}
+	return redactions, nil
+}
+
+// redactPayload applies redactions to a payload and reports whether it changed.
+func redactPayload(redactions []masking.Redaction, payload string) (string, bool) {
+	redacted, count := masking.RedactJSON(payload, redactions)
+	return redacted, count > 0 && redacted != payload
+}
+
+// redactionFingerprint identifies a set of rules, to detect rule changes.
+func redactionFingerprint(rules []models.RedactionRule) string {
+	var b strings.Builder
+	for _, rule := range rules {
+		fmt.Fprintf(&b, "%q=%q;", rule.Path, rule.Replace)
+	}
+	return b.String()
+}
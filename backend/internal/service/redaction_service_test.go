@@ -0,0 +1,171 @@
+package service_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("RedactionService", func() {
+	var (
+		baseDir          string
+		clientRepo       *repository.FileClientRepository
+		eventRepo        *repository.FileEventRepository
+		redactionService *service.RedactionService
+		client           *models.Client
+		eventsDir        string
+	)
+
+	// writeEvent writes an event file as gosmee does and backdates it past the settle time.
+	writeEvent := func(name, content string) string {
+		path := filepath.Join(eventsDir, name)
+		Expect(os.WriteFile(path, []byte(content), 0644)).To(Succeed())
+		past := time.Now().Add(-time.Minute)
+		Expect(os.Chtimes(path, past, past)).To(Succeed())
+		return path
+	}
+
+	BeforeEach(func() {
+		baseDir = GinkgoT().TempDir()
+		var err error
+		clientRepo, err = repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		eventRepo = repository.NewFileEventRepository(baseDir)
+		redactionService = service.NewRedactionService(clientRepo, eventRepo, logger.New())
+
+		client = &models.Client{
+			ID:     "client-redact",
+			UserID: "user-redact",
+			Name:   "redact",
+			RedactionRules: []models.RedactionRule{
+				{Path: "$.pusher.email"},
+				{Path: "$..token", Replace: "***"},
+			},
+		}
+		Expect(clientRepo.Create(client)).To(Succeed())
+		eventsDir = filepath.Join(baseDir, "users", client.UserID, "clients", client.ID, "events")
+		Expect(os.MkdirAll(eventsDir, 0755)).To(Succeed())
+	})
+
+	It("redacts raw payloads written by gosmee", func() {
+		path := writeEvent("20251001T120000.json", `{"pusher":{"email":"a@example.com","name":"alice"},"hook":{"config":{"token":"s3cr3t"}}}`)
+
+		redactionService.RedactNewEvents()
+
+		data, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal(`{"hook":{"config":{"token":"***"}},"pusher":{"email":"[REDACTED]","name":"alice"}}`))
+	})
+
+	It("redacts the payload of stored events and keeps their metadata", func() {
+		writeEvent("evt-1.json", `{"id":"evt-1","clientId":"client-redact","status":"failed","statusCode":502,"payload":"{\"token\":\"s3cr3t\"}"}`)
+
+		redactionService.RedactNewEvents()
+
+		event, err := eventRepo.Get(client.ID, "evt-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(event.Payload).To(Equal(`{"token":"***"}`))
+		Expect(event.Status).To(Equal(models.EventStatusFailed))
+		Expect(event.StatusCode).To(Equal(502))
+	})
+
+	It("leaves non-JSON payloads and files still being written alone", func() {
+		form := writeEvent("form.json", "payload=token%3Ds3cr3t")
+		fresh := filepath.Join(eventsDir, "fresh.json")
+		Expect(os.WriteFile(fresh, []byte(`{"token":"s3cr3t"}`), 0644)).To(Succeed())
+
+		redactionService.RedactNewEvents()
+
+		Expect(os.ReadFile(form)).To(Equal([]byte("payload=token%3Ds3cr3t")))
+		Expect(os.ReadFile(fresh)).To(Equal([]byte(`{"token":"s3cr3t"}`)))
+	})
+
+	It("redacts injected events before they are stored", func() {
+		log := logger.New()
+		eventService := service.NewEventService(
+			eventRepo,
+			clientRepo,
+			service.NewJobService(time.Hour, log),
+			service.NewCircuitBreakerService(0, time.Minute, service.NewNotificationService(log), log),
+			log,
+		)
+
+		response, err := eventService.Inject(client.ID, &models.EventInjectRequest{
+			Payload: []byte(`{"pusher":{"email":"a@example.com"}}`),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		stored, err := eventRepo.Get(client.ID, response.Event.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored.Payload).To(Equal(`{"pusher":{"email":"[REDACTED]"}}`))
+	})
+
+	It("forwards new events of a running client before redacting them", func() {
+		bodies := make(chan string, 1)
+		release := make(chan struct{})
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			bodies <- string(body)
+			<-release
+		}))
+		DeferCleanup(target.Close)
+		DeferCleanup(func() {
+			select {
+			case <-release:
+			default:
+				close(release)
+			}
+		})
+		client.TargetURL = target.URL
+		client.TargetTimeout = 5
+		Expect(clientRepo.Update(client)).To(Succeed())
+
+		log := logger.New()
+		eventService := service.NewEventService(
+			eventRepo,
+			clientRepo,
+			service.NewJobService(time.Hour, log),
+			service.NewCircuitBreakerService(0, time.Minute, service.NewNotificationService(log), log),
+			log,
+		)
+		quotaService := service.NewQuotaService(repository.NewFileQuotaRepository(baseDir, 1000000, 10), repository.NewFileQuotaHistoryRepository(baseDir), log)
+		watcherService, err := service.NewWatcherService(eventRepo, quotaService, log)
+		Expect(err).NotTo(HaveOccurred())
+		defer watcherService.Close()
+		watcherService.SetForwarder(eventService.ForwardNewEvents)
+		watcherService.Watch(client, eventsDir)
+		redactionService.SetWatcher(watcherService)
+
+		original := `{"hook":{"config":{"token":"s3cr3t"}}}`
+		path := writeEvent("20251001T120000.json", original)
+		Eventually(func() bool { return watcherService.Processing(client.ID, "20251001T120000") }).Should(BeTrue())
+
+		// Neither while the event settles nor while it is forwarded
+		redactionService.RedactNewEvents()
+		var body string
+		Eventually(bodies, 5*time.Second).Should(Receive(&body))
+		redactionService.RedactNewEvents()
+		Expect(os.ReadFile(path)).To(Equal([]byte(original)))
+		close(release)
+		Expect(body).To(Equal(original))
+
+		Eventually(func() (*models.Event, error) {
+			redactionService.RedactNewEvents()
+			return eventRepo.Get(client.ID, "20251001T120000")
+		}, 10*time.Second, 100*time.Millisecond).Should(And(
+			HaveField("Status", models.EventStatusSuccess),
+			HaveField("Payload", `{"hook":{"config":{"token":"***"}}}`),
+		))
+	})
+})
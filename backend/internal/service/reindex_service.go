@@ -0,0 +1,141 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+)
+
+// ReindexService rebuilds everything derived from the raw event files: the daily stats
+// rollups, the cached storage usage, the delivery ID index and, with S3 event storage, the
+// index of event objects. It recovers from corrupted indices and picks up events edited
+// by hand in the data directory.
+type ReindexService struct {
+	clientRepo   repository.ClientRepository
+	quotaRepo    repository.QuotaRepository
+	statsService *StatsService
+	jobService   *JobService // Runs rebuilds started through the API (nil = synchronous only)
+	log          logger.Logger
+
+	deliveryIndex *DeliveryIndex                // Delivery ID index (optional)
+	s3EventRepo   *repository.S3EventRepository // S3 event storage (optional)
+
+	running atomic.Bool // Whether a rebuild is running
+}
+
+// NewReindexService creates a new reindex service.
+func NewReindexService(
+	clientRepo repository.ClientRepository,
+	quotaRepo repository.QuotaRepository,
+	statsService *StatsService,
+	jobService *JobService,
+	log logger.Logger,
+) *ReindexService {
+	return &ReindexService{
+		clientRepo:   clientRepo,
+		quotaRepo:    quotaRepo,
+		statsService: statsService,
+		jobService:   jobService,
+		log:          log,
+	}
+}
+
+// SetDeliveryIndex rebuilds the delivery ID index along with the rollups.
+func (s *ReindexService) SetDeliveryIndex(index *DeliveryIndex) {
+	s.deliveryIndex = index
+}
+
+// SetS3EventRepository rebuilds the index of event objects in the bucket first, so the
+// other indices are built from the objects actually stored.
+func (s *ReindexService) SetS3EventRepository(repo *repository.S3EventRepository) {
+	s.s3EventRepo = repo
+}
+
+// Start rebuilds the indices of all clients as an asynchronous job of the user. Only one
+// rebuild runs at a time.
+func (s *ReindexService) Start(userID string) (*models.Job, error) {
+	if !s.running.CompareAndSwap(false, true) {
+		return nil, apperrors.ErrReindexRunning
+	}
+
+	clients, err := s.clientRepo.ListAll()
+	if err != nil {
+		s.running.Store(false)
+		return nil, fmt.Errorf("failed to list clients: %w", err)
+	}
+
+	return s.jobService.Submit(userID, "reindex", len(clients), func(progress JobProgressFunc) (interface{}, error) {
+		defer s.running.Store(false)
+		return s.reindex(clients, progress), nil
+	}), nil
+}
+
+// Run rebuilds the indices of all clients right away, reporting the progress after each
+// client.
+func (s *ReindexService) Run(progress JobProgressFunc) (*models.ReindexReport, error) {
+	if !s.running.CompareAndSwap(false, true) {
+		return nil, apperrors.ErrReindexRunning
+	}
+	defer s.running.Store(false)
+
+	clients, err := s.clientRepo.ListAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clients: %w", err)
+	}
+	return s.reindex(clients, progress), nil
+}
+
+// reindex rebuilds the indices of the clients. A client that fails is reported and skipped.
+func (s *ReindexService) reindex(clients []*models.Client, progress JobProgressFunc) *models.ReindexReport {
+	report := &models.ReindexReport{Failed: []*models.ReindexFailure{}}
+
+	for i, client := range clients {
+		if err := s.reindexClient(client, report); err != nil {
+			s.log.Error("Failed to reindex client %s: %v", client.ID, err)
+			report.Failed = append(report.Failed, &models.ReindexFailure{ClientID: client.ID, Error: err.Error()})
+		} else {
+			report.Clients++
+		}
+		progress(i+1, len(clients))
+	}
+
+	s.log.Info("Reindexed %d clients: %d daily rollups, %d delivery IDs, %d failed",
+		report.Clients, report.StatsDays, report.DeliveryIDs, len(report.Failed))
+	return report
+}
+
+// reindexClient rebuilds the indices of a client and adds the counts to the report.
+func (s *ReindexService) reindexClient(client *models.Client, report *models.ReindexReport) error {
+	if s.s3EventRepo != nil {
+		indexed, err := s.s3EventRepo.RebuildIndex(client.ID)
+		if err != nil {
+			return fmt.Errorf("failed to index event objects: %w", err)
+		}
+		report.S3Events += indexed
+	}
+
+	// The user's storage usage is calculated again on its next use
+	s.quotaRepo.InvalidateCache(client.UserID)
+
+	if s.deliveryIndex != nil {
+		indexed, err := s.deliveryIndex.Rebuild(client.ID)
+		if err != nil {
+			return fmt.Errorf("failed to index delivery IDs: %w", err)
+		}
+		report.DeliveryIDs += indexed
+	}
+
+	written, err := s.statsService.RebuildClient(client)
+	if err != nil {
+		return fmt.Errorf("failed to roll up stats: %w", err)
+	}
+	report.StatsDays += written
+	return nil
+}
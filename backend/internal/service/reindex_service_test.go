@@ -0,0 +1,95 @@
+package service_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("ReindexService", func() {
+	var (
+		eventRepo      *repository.FileEventRepository
+		statsService   *service.StatsService
+		jobService     *service.JobService
+		reindexService *service.ReindexService
+	)
+
+	BeforeEach(func() {
+		baseDir := GinkgoT().TempDir()
+		log := logger.New()
+		clientRepo, err := repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		eventRepo = repository.NewFileEventRepository(baseDir)
+		quotaRepo := repository.NewFileQuotaRepository(baseDir, 10*1024*1024, 10)
+		statsService = service.NewStatsService(clientRepo, eventRepo, repository.NewFileStatsRepository(baseDir), quotaRepo, log)
+		jobService = service.NewJobService(time.Hour, log)
+		reindexService = service.NewReindexService(clientRepo, quotaRepo, statsService, jobService, log)
+		reindexService.SetDeliveryIndex(service.NewDeliveryIndex(eventRepo, log))
+
+		Expect(clientRepo.Create(&models.Client{ID: "client-reindex", UserID: "user-reindex", Name: "reindex"})).To(Succeed())
+	})
+
+	saveEvent := func(id string, ts time.Time, status models.EventStatus) {
+		Expect(eventRepo.Save("client-reindex", &models.Event{ID: id, Timestamp: ts, Status: status, DeliveryID: "delivery-" + id})).To(Succeed())
+	}
+
+	pastDay := func() *models.DailyStats {
+		response, err := statsService.Daily("client-reindex", &models.DailyStatsRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.Days).NotTo(BeEmpty())
+		return response.Days[0]
+	}
+
+	It("rewrites the rollups of past days from the event files", func() {
+		past := time.Now().AddDate(0, 0, -5)
+		saveEvent("evt-1", past, models.EventStatusSuccess)
+		statsService.RollupEvents()
+
+		// An event restored by hand is ignored by the periodic rollup
+		saveEvent("evt-2", past, models.EventStatusFailed)
+		statsService.RollupEvents()
+		Expect(pastDay().Events).To(Equal(1))
+
+		var progress []int
+		report, err := reindexService.Run(func(processed, total int) {
+			progress = append(progress, processed, total)
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(progress).To(Equal([]int{1, 1}))
+		Expect(report.Clients).To(Equal(1))
+		Expect(report.StatsDays).To(Equal(2)) // The past day and today
+		Expect(report.DeliveryIDs).To(Equal(2))
+		Expect(report.Failed).To(BeEmpty())
+
+		day := pastDay()
+		Expect(day.Events).To(Equal(2))
+		Expect(day.Failed).To(Equal(1))
+	})
+
+	It("rebuilds as a job", func() {
+		saveEvent("evt-1", time.Now(), models.EventStatusSuccess)
+
+		job, err := reindexService.Start("admin")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(job.Type).To(Equal("reindex"))
+
+		Eventually(func() bool {
+			job, err = jobService.Get("admin", job.ID)
+			Expect(err).NotTo(HaveOccurred())
+			return job.IsFinished()
+		}).Should(BeTrue())
+		Expect(job.Status).To(Equal(models.JobStatusCompleted))
+		Expect(job.Processed).To(Equal(1))
+		Expect(job.Result).To(HaveField("DeliveryIDs", 1))
+
+		// The next rebuild can start once the job finished
+		_, err = reindexService.Run(func(int, int) {})
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
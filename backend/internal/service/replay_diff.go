@@ -0,0 +1,164 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+)
+
+// Response body diff limits: bodies with more lines are reported as changed without a diff,
+// longer diffs are cut.
+const (
+	maxDiffLines   = 2000
+	maxDiffSize    = 8 * 1024
+	diffContext    = 2 // Unchanged lines shown around changes
+	diffCutMessage = "... (diff truncated)"
+)
+
+// compareReplay compares the result of replaying an event with the delivery recorded on the
+// event. It returns nil if the event has no recorded delivery. Response bodies are compared
+// if the target's responses are captured and the original body was stored.
+func compareReplay(event *models.Event, result *models.EventReplayResult, captureEnabled bool) *models.ReplayComparison {
+	if event.StatusCode == 0 {
+		return nil
+	}
+
+	comparison := &models.ReplayComparison{
+		OriginalStatusCode: event.StatusCode,
+		StatusCode:         result.StatusCode,
+		StatusChanged:      event.StatusCode != result.StatusCode,
+		OriginalLatencyMs:  event.LatencyMs,
+		LatencyMs:          result.LatencyMs,
+		LatencyDeltaMs:     result.LatencyMs - event.LatencyMs,
+	}
+	if !captureEnabled || result.StatusCode == 0 || (event.Response == "" && result.Response != "") {
+		return comparison
+	}
+
+	comparison.BodyCompared = true
+	comparison.BodyTruncated = event.ResponseTruncated || result.ResponseTruncated
+	if event.Response == result.Response {
+		return comparison
+	}
+	original, replayed := event.Response, result.Response
+	if !comparison.BodyTruncated {
+		original, replayed = indentJSON(original), indentJSON(replayed)
+	}
+	comparison.BodyChanged = original != replayed
+	if comparison.BodyChanged {
+		comparison.BodyDiff = diffLines(original, replayed)
+	}
+	return comparison
+}
+
+// indentJSON returns a JSON body indented with sorted object keys, so differences are reported
+// per field. Other bodies are returned unchanged.
+func indentJSON(body string) string {
+	var value interface{}
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return body
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		return body
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// diffLines returns a unified diff of two texts, without file headers.
+func diffLines(a, b string) string {
+	x, y := strings.Split(a, "\n"), strings.Split(b, "\n")
+	if len(x) > maxDiffLines || len(y) > maxDiffLines {
+		return fmt.Sprintf("bodies have too many lines to compare (%d and %d)", len(x), len(y))
+	}
+
+	// Longest common subsequence table: lcs[i][j] is the LCS length of x[i:] and y[j:]
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	// Edit script: ' ' keeps, '-' removes a line of a, '+' adds a line of b
+	type edit struct {
+		op   byte
+		line string
+		i, j int // Line numbers in a and b before the edit
+	}
+	var edits []edit
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			edits = append(edits, edit{' ', x[i], i, j})
+			i++
+			j++
+		case i < len(x) && (j == len(y) || lcs[i+1][j] >= lcs[i][j+1]):
+			edits = append(edits, edit{'-', x[i], i, j})
+			i++
+		default:
+			edits = append(edits, edit{'+', y[j], i, j})
+			j++
+		}
+	}
+
+	// Group changes with their context into hunks
+	var out strings.Builder
+	for start := 0; start < len(edits); {
+		if edits[start].op == ' ' {
+			start++
+			continue
+		}
+		from := max(start-diffContext, 0)
+		end := start
+		for k := start; k < len(edits); k++ {
+			if edits[k].op != ' ' {
+				end = k
+			} else if k-end > 2*diffContext {
+				break
+			}
+		}
+		to := min(end+diffContext+1, len(edits))
+
+		removed, added := 0, 0
+		for _, e := range edits[from:to] {
+			if e.op != '+' {
+				removed++
+			}
+			if e.op != '-' {
+				added++
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", edits[from].i+1, removed, edits[from].j+1, added)
+		for _, e := range edits[from:to] {
+			out.WriteByte(e.op)
+			out.WriteString(e.line)
+			out.WriteByte('\n')
+		}
+		if out.Len() > maxDiffSize {
+			cut := out.String()[:maxDiffSize]
+			return cut[:strings.LastIndexByte(cut, '\n')+1] + diffCutMessage
+		}
+		start = to
+	}
+	return strings.TrimSuffix(out.String(), "\n")
+}
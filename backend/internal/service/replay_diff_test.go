@@ -0,0 +1,100 @@
+package service_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("Replay comparison", func() {
+	var (
+		client       *models.Client
+		eventRepo    *repository.FileEventRepository
+		eventService *service.EventService
+		status       int
+		reply        string
+	)
+
+	BeforeEach(func() {
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(reply))
+		}))
+		DeferCleanup(target.Close)
+
+		baseDir := GinkgoT().TempDir()
+		log := logger.New()
+		clientRepo, err := repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		eventRepo = repository.NewFileEventRepository(baseDir)
+		eventService = service.NewEventService(
+			eventRepo,
+			clientRepo,
+			service.NewJobService(time.Hour, log),
+			service.NewCircuitBreakerService(0, time.Minute, service.NewNotificationService(log), log),
+			log,
+		)
+		eventService.SetResponseCapture(64 * 1024)
+
+		client = &models.Client{ID: "client-diff", UserID: "user", TargetURL: target.URL, TargetTimeout: 5}
+		Expect(clientRepo.Create(client)).To(Succeed())
+	})
+
+	replay := func(event *models.Event) *models.EventReplayResult {
+		event.ID = "event-diff"
+		event.ClientID = client.ID
+		event.Timestamp = time.Now().UTC()
+		event.Payload = `{}`
+		Expect(eventRepo.Save(client.ID, event)).To(Succeed())
+
+		response, err := eventService.Replay(client.ID, &models.EventReplayRequest{EventIDs: []string{event.ID}})
+		Expect(err).NotTo(HaveOccurred())
+		return response.Results[0]
+	}
+
+	It("reports changed status codes and a diff of JSON bodies", func() {
+		status, reply = http.StatusBadRequest, `{"error":"unknown action","ok":false}`
+		result := replay(&models.Event{Status: models.EventStatusSuccess, StatusCode: 200, LatencyMs: 40, Response: `{"ok":true,"id":7}`})
+
+		comparison := result.Comparison
+		Expect(comparison).NotTo(BeNil())
+		Expect(comparison.OriginalStatusCode).To(Equal(200))
+		Expect(comparison.StatusCode).To(Equal(400))
+		Expect(comparison.StatusChanged).To(BeTrue())
+		Expect(comparison.LatencyDeltaMs).To(Equal(comparison.LatencyMs - 40))
+		Expect(comparison.BodyCompared).To(BeTrue())
+		Expect(comparison.BodyChanged).To(BeTrue())
+		Expect(comparison.BodyDiff).To(Equal("@@ -1,4 +1,4 @@\n {\n-  \"id\": 7,\n-  \"ok\": true\n+  \"error\": \"unknown action\",\n+  \"ok\": false\n }"))
+	})
+
+	It("ignores formatting differences of JSON bodies", func() {
+		status, reply = http.StatusOK, `{"id": 7, "ok": true}`
+		result := replay(&models.Event{Status: models.EventStatusSuccess, StatusCode: 200, Response: `{"ok":true,"id":7}`})
+
+		Expect(result.Comparison.StatusChanged).To(BeFalse())
+		Expect(result.Comparison.BodyChanged).To(BeFalse())
+		Expect(result.Comparison.BodyDiff).To(BeEmpty())
+	})
+
+	It("compares only the status when the original body wasn't stored", func() {
+		status, reply = http.StatusOK, `accepted`
+		result := replay(&models.Event{Status: models.EventStatusSuccess, StatusCode: 200})
+
+		Expect(result.Comparison.BodyCompared).To(BeFalse())
+	})
+
+	It("doesn't compare events that were never delivered", func() {
+		status = http.StatusOK
+		result := replay(&models.Event{Status: models.EventStatusNotReplayed})
+
+		Expect(result.Comparison).To(BeNil())
+	})
+})
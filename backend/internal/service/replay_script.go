@@ -0,0 +1,83 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+)
+
+// scriptSkippedHeaders are set by the HTTP client and must not be replayed verbatim.
+var scriptSkippedHeaders = map[string]bool{
+	"content-length":    true,
+	"host":              true,
+	"connection":        true,
+	"transfer-encoding": true,
+	"accept-encoding":   true,
+}
+
+// buildReplayScript generates a replay script in the layout of gosmee's scripts: the target
+// URL can be overridden with the first argument (-l for http://localhost:8080), and the
+// payload is sent byte for byte. Binary payloads (encoding "base64") are decoded by the script.
+func buildReplayScript(format, eventID, targetURL string, headers map[string]string, payload, encoding string, generatedAt time.Time) string {
+	names := make([]string, 0, len(headers))
+	hasContentType := false
+	for name := range headers {
+		if scriptSkippedHeaders[strings.ToLower(name)] {
+			continue
+		}
+		if strings.EqualFold(name, "Content-Type") {
+			hasContentType = true
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("#!/usr/bin/env bash\n")
+	fmt.Fprintf(&b, "# Replay script for event %s, regenerated on %s.\n", eventID, generatedAt.UTC().Format(time.RFC3339))
+	b.WriteString("#\n")
+	b.WriteString("# Usage: ./" + eventID + ".sh [target-url]  (-l sends to http://localhost:8080)\n")
+	b.WriteString("set -euo pipefail\n\n")
+	fmt.Fprintf(&b, "targetURL=%s\n", shellQuote(targetURL))
+	b.WriteString("if [[ ${1:-} == -l ]]; then\n\ttargetURL=\"http://localhost:8080\"\nelif [[ -n ${1:-} ]]; then\n\ttargetURL=${1}\nfi\n\n")
+	fmt.Fprintf(&b, "payload=%s\n\n", shellQuote(payload))
+
+	contentType := models.DetectContentType(payload)
+	b.WriteString(`printf '%s' "${payload}" | `)
+	if encoding == models.PayloadEncodingBase64 {
+		contentType = models.ContentTypeBinary
+		b.WriteString("base64 -d | ")
+	}
+	if format == models.ScriptFormatHTTPie {
+		b.WriteString(`http POST "${targetURL}"`)
+		if !hasContentType {
+			b.WriteString(" " + shellQuote("Content-Type:"+contentType))
+		}
+		for _, name := range names {
+			b.WriteString(" " + shellQuote(name+":"+headers[name]))
+		}
+	} else {
+		b.WriteString("curl -sSi -X POST")
+		if !hasContentType {
+			b.WriteString(" -H " + shellQuote("Content-Type: "+contentType))
+		}
+		for _, name := range names {
+			b.WriteString(" -H " + shellQuote(name+": "+headers[name]))
+		}
+		b.WriteString(` --data-binary @- "${targetURL}"`)
+	}
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// shellQuote quotes s as a single-quoted shell word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
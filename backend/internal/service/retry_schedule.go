@@ -0,0 +1,93 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// retrySchedule holds when the events waiting for another automatic retry are due, so the
+// retry loop reads only the events that are due. The retrying events stored for a client are
+// scheduled on its first retry pass, later ones as their retries are scheduled.
+type retrySchedule struct {
+	mu     sync.Mutex
+	loaded map[string]bool                 // IDs of clients whose stored retrying events are scheduled
+	due    map[string]map[string]time.Time // clientID -> eventID -> time of the next retry
+}
+
+// newRetrySchedule creates an empty retry schedule.
+func newRetrySchedule() *retrySchedule {
+	return &retrySchedule{
+		loaded: make(map[string]bool),
+		due:    make(map[string]map[string]time.Time),
+	}
+}
+
+// isLoaded reports whether the stored retrying events of a client are scheduled.
+func (r *retrySchedule) isLoaded(clientID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.loaded[clientID]
+}
+
+// load schedules stored retrying events of a client whose due time is not known yet, so they
+// are checked on the next retry pass.
+func (r *retrySchedule) load(clientID string, eventIDs []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.loaded[clientID] = true
+	for _, eventID := range eventIDs {
+		if _, exists := r.due[clientID][eventID]; !exists {
+			r.set(clientID, eventID, time.Time{})
+		}
+	}
+}
+
+// schedule sets when the next retry of an event is due.
+func (r *retrySchedule) schedule(clientID, eventID string, dueAt time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.set(clientID, eventID, dueAt)
+}
+
+func (r *retrySchedule) set(clientID, eventID string, dueAt time.Time) {
+	if r.due[clientID] == nil {
+		r.due[clientID] = make(map[string]time.Time)
+	}
+	r.due[clientID][eventID] = dueAt
+}
+
+// remove drops an event that is not retried anymore.
+func (r *retrySchedule) remove(clientID, eventID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.due[clientID], eventID)
+	if len(r.due[clientID]) == 0 {
+		delete(r.due, clientID)
+	}
+}
+
+// dueAt returns the IDs of the events of a client whose retry is due at now, earliest first.
+func (r *retrySchedule) dueAt(clientID string, now time.Time) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var eventIDs []string
+	for eventID, dueAt := range r.due[clientID] {
+		if !now.Before(dueAt) {
+			eventIDs = append(eventIDs, eventID)
+		}
+	}
+	due := r.due[clientID]
+	sort.Slice(eventIDs, func(i, j int) bool {
+		return due[eventIDs[i]].Before(due[eventIDs[j]])
+	})
+	return eventIDs
+}
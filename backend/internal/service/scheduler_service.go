@@ -0,0 +1,116 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+)
+
+// scheduledTask is a periodic background task.
+type scheduledTask struct {
+	name     string
+	interval time.Duration
+	fn       func()
+	running  atomic.Bool // Prevents overlapping runs of slow tasks
+}
+
+// SchedulerService runs registered tasks periodically in the background.
+type SchedulerService struct {
+	tasks    []*scheduledTask
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	started  bool
+	log      logger.Logger
+}
+
+// NewSchedulerService creates a new scheduler service.
+func NewSchedulerService(log logger.Logger) *SchedulerService {
+	return &SchedulerService{
+		stopChan: make(chan struct{}),
+		log:      log,
+	}
+}
+
+// Register adds a task that runs every interval once the scheduler is started.
+// Tasks must be registered before Start is called.
+func (s *SchedulerService) Register(name string, interval time.Duration, fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tasks = append(s.tasks, &scheduledTask{
+		name:     name,
+		interval: interval,
+		fn:       fn,
+	})
+}
+
+// Start launches all registered tasks.
+func (s *SchedulerService) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return
+	}
+	s.started = true
+
+	for _, task := range s.tasks {
+		s.wg.Add(1)
+		go s.loop(task)
+		s.log.Info("Scheduled task %s (every %s)", task.name, task.interval)
+	}
+}
+
+// Stop stops all tasks and waits for running executions to finish.
+func (s *SchedulerService) Stop() {
+	s.mu.Lock()
+	if !s.started {
+		s.mu.Unlock()
+		return
+	}
+	s.started = false
+	close(s.stopChan)
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+// loop runs a task on its interval until the scheduler stops.
+func (s *SchedulerService) loop(task *scheduledTask) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(task.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case <-ticker.C:
+			s.runTask(task)
+		}
+	}
+}
+
+// runTask executes a task once, skipping the run if the previous one is still in progress.
+func (s *SchedulerService) runTask(task *scheduledTask) {
+	if !task.running.CompareAndSwap(false, true) {
+		s.log.Debug("Skipping task %s: previous run still in progress", task.name)
+		return
+	}
+	defer task.running.Store(false)
+
+	defer func() {
+		if r := recover(); r != nil {
+			s.log.Error("Scheduled task %s panicked: %v", task.name, r)
+		}
+	}()
+
+	task.fn()
+}
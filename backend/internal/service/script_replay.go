@@ -0,0 +1,105 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+)
+
+// scriptOutputLimit caps how much script output is kept, per stream.
+const scriptOutputLimit = 64 << 10
+
+// scriptPath is the only environment passed to replay scripts besides HOME, TMPDIR and LANG,
+// so scripts cannot see server configuration or credentials from the environment.
+const scriptPath = "/usr/local/bin:/usr/bin:/bin"
+
+// scriptStatusLine matches the HTTP status line curl -i prints before the response headers.
+var scriptStatusLine = regexp.MustCompile(`(?m)^HTTP/[0-9.]+ ([0-9]{3})`)
+
+// limitedBuffer keeps the first limit bytes written to it and discards the rest.
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.Len(); room > 0 {
+		if len(p) > room {
+			b.Buffer.Write(p[:room])
+		} else {
+			b.Buffer.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+// runReplayScript executes a replay script generated by buildReplayScript with bash, passing
+// the client's current target URL as the first argument. The script runs in an empty temporary working directory
+// with a minimal environment and is killed after timeout.
+func runReplayScript(script []byte, eventID, targetURL string, timeout time.Duration) *models.EventReplayResult {
+	result := &models.EventReplayResult{EventID: eventID}
+
+	workDir, err := os.MkdirTemp("", "gosmee-replay-")
+	if err != nil {
+		result.ErrorMessage = fmt.Sprintf("failed to create script sandbox: %v", err)
+		return result
+	}
+	defer os.RemoveAll(workDir)
+
+	scriptFile := filepath.Join(workDir, "replay.sh")
+	if err := os.WriteFile(scriptFile, script, 0700); err != nil {
+		result.ErrorMessage = fmt.Sprintf("failed to write script: %v", err)
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	stdout := &limitedBuffer{limit: scriptOutputLimit}
+	stderr := &limitedBuffer{limit: scriptOutputLimit}
+	cmd := exec.CommandContext(ctx, "bash", scriptFile, targetURL)
+	cmd.Dir = workDir
+	cmd.Env = []string{"PATH=" + scriptPath, "HOME=" + workDir, "TMPDIR=" + workDir, "LANG=C.UTF-8"}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	// Don't wait for children (e.g. curl) holding the output pipes after bash was killed
+	cmd.WaitDelay = time.Second
+
+	startTime := time.Now()
+	err = cmd.Run()
+	result.LatencyMs = int(time.Since(startTime).Milliseconds())
+
+	// curl -i prints one status line per response (redirects, 100 Continue); the last one counts
+	if matches := scriptStatusLine.FindAllStringSubmatch(stdout.String(), -1); len(matches) > 0 {
+		result.StatusCode, _ = strconv.Atoi(matches[len(matches)-1][1])
+	}
+
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		result.ErrorMessage = fmt.Sprintf("script timed out after %s", timeout)
+	case err != nil:
+		result.ErrorMessage = fmt.Sprintf("script failed: %v", err)
+		if output := strings.TrimSpace(stderr.String()); output != "" {
+			result.ErrorMessage += ": " + output
+		}
+	case result.StatusCode >= 300:
+		result.ErrorMessage = fmt.Sprintf("HTTP %d", result.StatusCode)
+	default:
+		result.Success = true
+	}
+
+	return result
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+)
+
+// serviceAccountTokenPrefix marks service account tokens so they are recognizable in configs and logs.
+const serviceAccountTokenPrefix = "gsa_"
+
+// lastUsedResolution limits how often LastUsedAt is persisted for busy tokens.
+const lastUsedResolution = time.Minute
+
+// ServiceAccountService manages service accounts and authenticates their tokens.
+type ServiceAccountService struct {
+	repo repository.ServiceAccountRepository
+	log  logger.Logger
+}
+
+// NewServiceAccountService creates a new service account service.
+func NewServiceAccountService(repo repository.ServiceAccountRepository, log logger.Logger) *ServiceAccountService {
+	return &ServiceAccountService{
+		repo: repo,
+		log:  log,
+	}
+}
+
+// Create creates a service account and returns it with its token. The token is not stored
+// and cannot be retrieved again.
+func (s *ServiceAccountService) Create(createdBy string, req *models.ServiceAccountRequest) (*models.ServiceAccountTokenResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, apperrors.NewInvalidInput(err.Error())
+	}
+
+	token, err := generateServiceAccountToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	userID := req.UserID
+	if userID == "" {
+		userID = createdBy
+	}
+
+	now := time.Now()
+	account := &models.ServiceAccount{
+		ID:          uuid.New().String(),
+		Name:        req.Name,
+		Description: req.Description,
+		UserID:      userID,
+		Scopes:      req.Scopes,
+		TokenPrefix: tokenDisplayPrefix(token),
+		TokenHash:   hashServiceAccountToken(token),
+		CreatedBy:   createdBy,
+		CreatedAt:   now,
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := now.AddDate(0, 0, req.ExpiresInDays)
+		account.ExpiresAt = &expiresAt
+	}
+
+	if err := s.repo.Create(account); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Created service account %s (%s) for user %s with scopes %v", account.ID, account.Name, userID, account.Scopes)
+
+	return &models.ServiceAccountTokenResponse{ServiceAccount: account, Token: token}, nil
+}
+
+// List returns all service accounts.
+func (s *ServiceAccountService) List() (*models.ServiceAccountListResponse, error) {
+	accounts, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	return &models.ServiceAccountListResponse{ServiceAccounts: accounts}, nil
+}
+
+// Rotate issues a new token for a service account, invalidating the previous one.
+func (s *ServiceAccountService) Rotate(id string) (*models.ServiceAccountTokenResponse, error) {
+	account, err := s.repo.Get(id)
+	if err != nil {
+		return nil, apperrors.ErrServiceAccountNotFound
+	}
+
+	token, err := generateServiceAccountToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	account.TokenPrefix = tokenDisplayPrefix(token)
+	account.TokenHash = hashServiceAccountToken(token)
+	account.LastUsedAt = nil
+	if err := s.repo.Update(account); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Rotated token of service account %s (%s)", account.ID, account.Name)
+
+	return &models.ServiceAccountTokenResponse{ServiceAccount: account, Token: token}, nil
+}
+
+// Delete deletes a service account, revoking its token.
+func (s *ServiceAccountService) Delete(id string) error {
+	if err := s.repo.Delete(id); err != nil {
+		return apperrors.ErrServiceAccountNotFound
+	}
+
+	s.log.Info("Deleted service account %s", id)
+	return nil
+}
+
+// Authenticate returns the service account owning token, failing for unknown or expired tokens.
+func (s *ServiceAccountService) Authenticate(token string) (*models.ServiceAccount, error) {
+	if !strings.HasPrefix(token, serviceAccountTokenPrefix) {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	account, err := s.repo.GetByTokenHash(hashServiceAccountToken(token))
+	if err != nil {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	now := time.Now()
+	if account.IsExpired(now) {
+		return nil, fmt.Errorf("token expired")
+	}
+
+	if account.LastUsedAt == nil || now.Sub(*account.LastUsedAt) >= lastUsedResolution {
+		account.LastUsedAt = &now
+		if err := s.repo.Update(account); err != nil {
+			s.log.Error("Failed to record use of service account %s: %v", account.ID, err)
+		}
+	}
+
+	return account, nil
+}
+
+// ValidateToken authenticates a token for the auth middleware.
+// Returns interface{} to satisfy middleware.TokenValidator interface.
+func (s *ServiceAccountService) ValidateToken(token string) (interface{}, bool) {
+	account, err := s.Authenticate(token)
+	if err != nil {
+		return nil, false
+	}
+	return account, true
+}
+
+// generateServiceAccountToken generates a cryptographically secure random token.
+func generateServiceAccountToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return serviceAccountTokenPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashServiceAccountToken returns the hex SHA-256 of a token, as stored on disk.
+func hashServiceAccountToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// tokenDisplayPrefix returns the part of a token shown in listings.
+func tokenDisplayPrefix(token string) string {
+	return token[:len(serviceAccountTokenPrefix)+6]
+}
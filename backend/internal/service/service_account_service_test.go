@@ -0,0 +1,92 @@
+package service_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("ServiceAccountService", func() {
+	var (
+		baseDir string
+		svc     *service.ServiceAccountService
+	)
+
+	newService := func() *service.ServiceAccountService {
+		repo, err := repository.NewFileServiceAccountRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		return service.NewServiceAccountService(repo, logger.New())
+	}
+
+	BeforeEach(func() {
+		baseDir = GinkgoT().TempDir()
+		svc = newService()
+	})
+
+	It("issues tokens that authenticate as the account owner with its scopes", func() {
+		created, err := svc.Create("admin", &models.ServiceAccountRequest{
+			Name:   "monitoring",
+			UserID: "alice",
+			Scopes: []string{models.ScopeEventsRead},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created.Token).To(HavePrefix("gsa_"))
+		Expect(created.Token).To(HavePrefix(created.ServiceAccount.TokenPrefix))
+
+		account, err := svc.Authenticate(created.Token)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(account.UserID).To(Equal("alice"))
+		Expect(account.HasScope(models.ScopeEventsRead)).To(BeTrue())
+		Expect(account.HasScope(models.ScopeClientsStart)).To(BeFalse())
+		Expect(account.LastUsedAt).NotTo(BeNil())
+	})
+
+	It("rejects unknown scopes", func() {
+		_, err := svc.Create("admin", &models.ServiceAccountRequest{Name: "bad", Scopes: []string{"clients:destroy"}})
+		var appErr *apperrors.AppError
+		Expect(errors.As(err, &appErr)).To(BeTrue())
+		Expect(appErr.Code).To(Equal(apperrors.CodeInvalidInput))
+	})
+
+	It("keeps tokens valid across restarts", func() {
+		created, err := svc.Create("admin", &models.ServiceAccountRequest{Name: "deploy", Scopes: []string{models.ScopeClientsStart}})
+		Expect(err).NotTo(HaveOccurred())
+
+		account, err := newService().Authenticate(created.Token)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(account.ID).To(Equal(created.ServiceAccount.ID))
+		Expect(account.UserID).To(Equal("admin"))
+	})
+
+	It("invalidates the previous token on rotation", func() {
+		created, err := svc.Create("admin", &models.ServiceAccountRequest{Name: "deploy", Scopes: []string{models.ScopeClientsStart}})
+		Expect(err).NotTo(HaveOccurred())
+
+		rotated, err := svc.Rotate(created.ServiceAccount.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rotated.Token).NotTo(Equal(created.Token))
+
+		_, err = svc.Authenticate(created.Token)
+		Expect(err).To(HaveOccurred())
+		_, err = svc.Authenticate(rotated.Token)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("revokes tokens of deleted accounts", func() {
+		created, err := svc.Create("admin", &models.ServiceAccountRequest{Name: "deploy", Scopes: []string{models.ScopeAll}})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(svc.Delete(created.ServiceAccount.ID)).To(Succeed())
+		_, err = svc.Authenticate(created.Token)
+		Expect(err).To(HaveOccurred())
+
+		Expect(svc.Delete(created.ServiceAccount.ID)).To(MatchError(apperrors.ErrServiceAccountNotFound))
+	})
+})
@@ -6,17 +6,27 @@ package service
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
+	"sort"
 	"sync"
 	"time"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
 )
 
 // SessionInfo stores information about a user session.
 type SessionInfo struct {
-	UserID   string
-	Groups   []string
-	Email    string
-	ExpireAt time.Time
+	ID        string // Public session ID, derived from the session cookie without revealing it
+	UserID    string
+	Groups    []string
+	Email     string
+	IP        string // Client IP the session was created from
+	UserAgent string // User agent the session was created with
+	CreatedAt time.Time
+	ExpireAt  time.Time
 }
 
 // GetUserID returns the user ID.
@@ -55,7 +65,8 @@ func NewSessionService(ttl time.Duration) *SessionService {
 }
 
 // CreateSession creates a new session and returns the session ID.
-func (s *SessionService) CreateSession(userID, email string, groups []string) (string, error) {
+// The client IP and user agent identify the session in the session list.
+func (s *SessionService) CreateSession(userID, email string, groups []string, ip, userAgent string) (string, error) {
 	sessionID, err := generateSessionID()
 	if err != nil {
 		return "", err
@@ -64,11 +75,16 @@ func (s *SessionService) CreateSession(userID, email string, groups []string) (s
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	now := time.Now()
 	s.sessions[sessionID] = &SessionInfo{
-		UserID:   userID,
-		Groups:   groups,
-		Email:    email,
-		ExpireAt: time.Now().Add(s.ttl),
+		ID:        publicSessionID(sessionID),
+		UserID:    userID,
+		Groups:    groups,
+		Email:     email,
+		IP:        ip,
+		UserAgent: userAgent,
+		CreatedAt: now,
+		ExpireAt:  now.Add(s.ttl),
 	}
 
 	return sessionID, nil
@@ -110,6 +126,64 @@ func (s *SessionService) DeleteSession(sessionID string) {
 	delete(s.sessions, sessionID)
 }
 
+// ListSessions returns the active sessions of a user, newest first. The session with the
+// given session ID is marked as the current one.
+func (s *SessionService) ListSessions(userID, currentSessionID string) *models.SessionListResponse {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	sessions := []*models.Session{}
+	for sessionID, session := range s.sessions {
+		if session.UserID != userID || now.After(session.ExpireAt) {
+			continue
+		}
+		sessions = append(sessions, &models.Session{
+			ID:        session.ID,
+			IP:        session.IP,
+			UserAgent: session.UserAgent,
+			CreatedAt: session.CreatedAt,
+			ExpiresAt: session.ExpireAt,
+			Current:   sessionID == currentSessionID,
+		})
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.After(sessions[j].CreatedAt)
+	})
+
+	return &models.SessionListResponse{Sessions: sessions}
+}
+
+// RevokeSession deletes a session of a user by its public ID.
+func (s *SessionService) RevokeSession(userID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for sessionID, session := range s.sessions {
+		if session.ID == id && session.UserID == userID {
+			delete(s.sessions, sessionID)
+			return nil
+		}
+	}
+	return apperrors.ErrSessionNotFound
+}
+
+// RevokeUserSessions deletes all sessions of a user except the one with the given session ID
+// (none if empty), logging the user out everywhere. It returns the number of deleted sessions.
+func (s *SessionService) RevokeUserSessions(userID, exceptSessionID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	revoked := 0
+	for sessionID, session := range s.sessions {
+		if session.UserID == userID && sessionID != exceptSessionID {
+			delete(s.sessions, sessionID)
+			revoked++
+		}
+	}
+	return revoked
+}
+
 // RefreshSession extends the session expiration time.
 func (s *SessionService) RefreshSession(sessionID string) bool {
 	s.mu.Lock()
@@ -141,6 +215,13 @@ func (s *SessionService) cleanup() {
 	}
 }
 
+// publicSessionID derives the ID a session is listed and revoked by, so the session cookie
+// itself is never exposed.
+func publicSessionID(sessionID string) string {
+	sum := sha256.Sum256([]byte(sessionID))
+	return hex.EncodeToString(sum[:8])
+}
+
 // generateSessionID generates a cryptographically secure random session ID.
 func generateSessionID() (string, error) {
 	b := make([]byte, 32)
@@ -0,0 +1,66 @@
+package service_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("SessionService", func() {
+	var (
+		sessionService *service.SessionService
+		laptop, phone  string
+	)
+
+	BeforeEach(func() {
+		sessionService = service.NewSessionService(time.Hour)
+
+		var err error
+		laptop, err = sessionService.CreateSession("alice", "alice@example.com", nil, "10.0.0.1", "Firefox")
+		Expect(err).NotTo(HaveOccurred())
+		phone, err = sessionService.CreateSession("alice", "alice@example.com", nil, "10.0.0.2", "Safari")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = sessionService.CreateSession("bob", "bob@example.com", nil, "10.0.0.3", "Chrome")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("lists the user's sessions without exposing the session cookies", func() {
+		list := sessionService.ListSessions("alice", laptop)
+		Expect(list.Sessions).To(HaveLen(2))
+		for _, session := range list.Sessions {
+			Expect(session.ID).NotTo(BeElementOf(laptop, phone))
+			Expect(session.Current).To(Equal(session.UserAgent == "Firefox"))
+		}
+	})
+
+	It("revokes a single session of the user only", func() {
+		list := sessionService.ListSessions("alice", laptop)
+		var phoneID string
+		for _, session := range list.Sessions {
+			if !session.Current {
+				phoneID = session.ID
+			}
+		}
+
+		Expect(sessionService.RevokeSession("bob", phoneID)).To(MatchError(apperrors.ErrSessionNotFound))
+		Expect(sessionService.RevokeSession("alice", phoneID)).To(Succeed())
+
+		_, exists := sessionService.GetSession(phone)
+		Expect(exists).To(BeFalse())
+		_, exists = sessionService.GetSession(laptop)
+		Expect(exists).To(BeTrue())
+	})
+
+	It("logs the user out everywhere, optionally keeping the current session", func() {
+		Expect(sessionService.RevokeUserSessions("alice", laptop)).To(Equal(1))
+		Expect(sessionService.ListSessions("alice", laptop).Sessions).To(HaveLen(1))
+
+		Expect(sessionService.RevokeUserSessions("alice", "")).To(Equal(1))
+		Expect(sessionService.ListSessions("alice", "").Sessions).To(BeEmpty())
+		Expect(sessionService.ListSessions("bob", "").Sessions).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,119 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/masking"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+)
+
+// MaskerFunc returns the secret masker configured for a user.
+type MaskerFunc func(userID string) *masking.Masker
+
+// SettingsService manages per-user settings.
+type SettingsService struct {
+	repo    repository.SettingsRepository
+	log     logger.Logger
+	maskers sync.Map // Compiled maskers (key: userID, value: *masking.Masker)
+}
+
+// NewSettingsService creates a new settings service.
+func NewSettingsService(repo repository.SettingsRepository, log logger.Logger) *SettingsService {
+	return &SettingsService{
+		repo: repo,
+		log:  log,
+	}
+}
+
+// GetMasking retrieves the masking settings of a user.
+func (s *SettingsService) GetMasking(userID string) (*models.MaskingSettings, error) {
+	settings, err := s.repo.Get(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return normalizeMaskingSettings(&settings.Masking), nil
+}
+
+// UpdateMasking replaces the masking settings of a user. Patterns must be valid regular expressions.
+func (s *SettingsService) UpdateMasking(userID string, req *models.MaskingSettingsRequest) (*models.MaskingSettings, error) {
+	updated := normalizeMaskingSettings(&models.MaskingSettings{
+		Headers:  req.Headers,
+		Patterns: req.Patterns,
+	})
+
+	masker, err := newMasker(updated)
+	if err != nil {
+		return nil, apperrors.NewInvalidInput(err.Error())
+	}
+
+	settings, err := s.repo.Get(userID)
+	if err != nil {
+		return nil, err
+	}
+	settings.Masking = *updated
+	settings.UpdatedAt = time.Now()
+
+	if err := s.repo.Save(settings); err != nil {
+		return nil, err
+	}
+	s.maskers.Store(userID, masker)
+
+	s.log.Info("Updated masking settings of user %s: %d headers, %d patterns", userID, len(updated.Headers), len(updated.Patterns))
+	return updated, nil
+}
+
+// MaskerFor returns the masker for a user's logs, events and scripts. If the user's settings
+// cannot be loaded, the built-in rules still apply.
+func (s *SettingsService) MaskerFor(userID string) *masking.Masker {
+	if cached, ok := s.maskers.Load(userID); ok {
+		return cached.(*masking.Masker)
+	}
+
+	settings, err := s.repo.Get(userID)
+	if err != nil {
+		s.log.Error("Failed to load masking settings of user %s: %v", userID, err)
+		return masking.Default
+	}
+
+	masker, err := newMasker(&settings.Masking)
+	if err != nil {
+		s.log.Error("Invalid masking settings of user %s: %v", userID, err)
+		masker = masking.Default
+	}
+
+	s.maskers.Store(userID, masker)
+	return masker
+}
+
+// newMasker compiles masking settings.
+func newMasker(settings *models.MaskingSettings) (*masking.Masker, error) {
+	return masking.New(settings.Headers, settings.Patterns)
+}
+
+// normalizeMaskingSettings trims header names and drops empty entries.
+func normalizeMaskingSettings(settings *models.MaskingSettings) *models.MaskingSettings {
+	normalized := &models.MaskingSettings{
+		Headers:  []string{},
+		Patterns: []string{},
+	}
+	for _, header := range settings.Headers {
+		if header = strings.TrimSpace(header); header != "" {
+			normalized.Headers = append(normalized.Headers, header)
+		}
+	}
+	for _, pattern := range settings.Patterns {
+		if pattern != "" {
+			normalized.Patterns = append(normalized.Patterns, pattern)
+		}
+	}
+	return normalized
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+)
+
+// metricsLabelEscaper escapes label values in the Prometheus text exposition format.
+var metricsLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// WriteMetrics writes the statistics of a user's clients in the Prometheus text exposition
+// format. Values are totals of all daily rollups, so they lag up to one rollup interval behind.
+func (s *StatsService) WriteMetrics(w io.Writer, userID string) error {
+	clients, err := s.clientRepo.GetByUserID(userID)
+	if err != nil {
+		return fmt.Errorf("failed to list clients: %w", err)
+	}
+	sort.Slice(clients, func(i, j int) bool { return clients[i].ID < clients[j].ID })
+
+	type clientTotals struct {
+		labels     string
+		events     int
+		dropped    int64
+		successful int
+		failed     int
+		histogram  []int
+		sumMs      int64
+		eventTypes []string
+		byType     map[string]DeliveryCounts
+	}
+
+	totals := make([]*clientTotals, 0, len(clients))
+	for _, client := range clients {
+		days, err := s.statsRepo.List(client.UserID, client.ID, "", "")
+		if err != nil {
+			return err
+		}
+
+		t := &clientTotals{
+			labels:    fmt.Sprintf(`client_id="%s",client_name="%s"`, metricsLabelEscaper.Replace(client.ID), metricsLabelEscaper.Replace(client.Name)),
+			histogram: make([]int, len(models.LatencyBucketsMs)+1),
+		}
+		for _, day := range days {
+			t.events += day.Events
+			t.successful += day.Successful
+			t.failed += day.Failed
+			if len(day.LatencyHistogram) == len(t.histogram) {
+				for i, count := range day.LatencyHistogram {
+					t.histogram[i] += count
+				}
+				t.sumMs += day.LatencySumMs
+			}
+		}
+		if s.ingestion != nil {
+			t.dropped = s.ingestion.Dropped(client.ID)
+		}
+		if s.counters != nil {
+			t.byType = s.counters.Counts(client.ID)
+			for eventType := range t.byType {
+				t.eventTypes = append(t.eventTypes, eventType)
+			}
+			sort.Strings(t.eventTypes)
+		}
+		totals = append(totals, t)
+	}
+
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, "# HELP gosmee_events_total Webhook events received.")
+	fmt.Fprintln(bw, "# TYPE gosmee_events_total counter")
+	for _, t := range totals {
+		fmt.Fprintf(bw, "gosmee_events_total{%s} %d\n", t.labels, t.events)
+	}
+
+	fmt.Fprintln(bw, "# HELP gosmee_events_dropped_total Webhook events dropped over the ingestion limit since startup.")
+	fmt.Fprintln(bw, "# TYPE gosmee_events_dropped_total counter")
+	for _, t := range totals {
+		fmt.Fprintf(bw, "gosmee_events_dropped_total{%s} %d\n", t.labels, t.dropped)
+	}
+
+	fmt.Fprintln(bw, "# HELP gosmee_deliveries_total Event deliveries to the target URL by result.")
+	fmt.Fprintln(bw, "# TYPE gosmee_deliveries_total counter")
+	for _, t := range totals {
+		fmt.Fprintf(bw, "gosmee_deliveries_total{%s,result=\"success\"} %d\n", t.labels, t.successful)
+		fmt.Fprintf(bw, "gosmee_deliveries_total{%s,result=\"failed\"} %d\n", t.labels, t.failed)
+	}
+
+	if s.counters != nil {
+		for _, family := range []struct {
+			name  string
+			help  string
+			count func(DeliveryCounts) int64
+		}{
+			{"gosmee_webhook_received_total", "Webhook events received by event type since startup.", func(c DeliveryCounts) int64 { return c.Received }},
+			{"gosmee_webhook_forwarded_total", "Deliveries accepted by the target URL by event type since startup.", func(c DeliveryCounts) int64 { return c.Forwarded }},
+			{"gosmee_webhook_failed_total", "Failed deliveries to the target URL by event type since startup.", func(c DeliveryCounts) int64 { return c.Failed }},
+		} {
+			fmt.Fprintf(bw, "# HELP %s %s\n", family.name, family.help)
+			fmt.Fprintf(bw, "# TYPE %s counter\n", family.name)
+			for _, t := range totals {
+				for _, eventType := range t.eventTypes {
+					fmt.Fprintf(bw, "%s{%s,event_type=\"%s\"} %d\n", family.name, t.labels,
+						metricsLabelEscaper.Replace(eventType), family.count(t.byType[eventType]))
+				}
+			}
+		}
+	}
+
+	fmt.Fprintln(bw, "# HELP gosmee_delivery_latency_seconds Latency of event deliveries to the target URL.")
+	fmt.Fprintln(bw, "# TYPE gosmee_delivery_latency_seconds histogram")
+	for _, t := range totals {
+		cumulative := 0
+		for i, count := range t.histogram {
+			cumulative += count
+			le := "+Inf"
+			if i < len(models.LatencyBucketsMs) {
+				le = strconv.FormatFloat(float64(models.LatencyBucketsMs[i])/1000, 'g', -1, 64)
+			}
+			fmt.Fprintf(bw, "gosmee_delivery_latency_seconds_bucket{%s,le=\"%s\"} %d\n", t.labels, le, cumulative)
+		}
+		fmt.Fprintf(bw, "gosmee_delivery_latency_seconds_sum{%s} %s\n", t.labels, strconv.FormatFloat(float64(t.sumMs)/1000, 'g', -1, 64))
+		fmt.Fprintf(bw, "gosmee_delivery_latency_seconds_count{%s} %d\n", t.labels, cumulative)
+	}
+
+	return bw.Flush()
+}
@@ -0,0 +1,590 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+)
+
+// defaultStatsDays is how many days of daily statistics are returned by default.
+const defaultStatsDays = 30
+
+// maxStatsBuckets limits the number of buckets a statistics query may return.
+const maxStatsBuckets = 1000
+
+// overviewTopClients is how many clients the overview lists as top failing clients.
+const overviewTopClients = 5
+
+// StatsService aggregates events into persisted daily rollups and serves statistics from them,
+// so historic statistics don't depend on raw events being retained.
+type StatsService struct {
+	clientRepo repository.ClientRepository
+	eventRepo  repository.EventRepository
+	statsRepo  repository.StatsRepository
+	quotaRepo  repository.QuotaRepository
+	log        logger.Logger
+
+	ingestion *IngestionLimiter // Counts dropped events (optional)
+	counters  *DeliveryCounters // Counts events and deliveries per event type (optional)
+}
+
+// NewStatsService creates a new statistics service.
+func NewStatsService(
+	clientRepo repository.ClientRepository,
+	eventRepo repository.EventRepository,
+	statsRepo repository.StatsRepository,
+	quotaRepo repository.QuotaRepository,
+	log logger.Logger,
+) *StatsService {
+	return &StatsService{
+		clientRepo: clientRepo,
+		eventRepo:  eventRepo,
+		statsRepo:  statsRepo,
+		quotaRepo:  quotaRepo,
+		log:        log,
+	}
+}
+
+// SetIngestionLimiter exports the events dropped over the ingestion limit as metrics.
+func (s *StatsService) SetIngestionLimiter(ingestion *IngestionLimiter) {
+	s.ingestion = ingestion
+}
+
+// SetDeliveryCounters exports the events and deliveries counted per event type as metrics.
+func (s *StatsService) SetDeliveryCounters(counters *DeliveryCounters) {
+	s.counters = counters
+}
+
+// RollupEvents writes the daily rollups of all clients. Today and yesterday are rewritten
+// on every run to pick up new events and late delivery results; earlier days are only
+// written if they have no rollup yet, so rollups of days whose events were cleaned up are kept.
+// Today's rollup is written even without events, to record the client's storage usage.
+// It is executed periodically by the scheduler.
+func (s *StatsService) RollupEvents() {
+	clients, err := s.clientRepo.ListAll()
+	if err != nil {
+		s.log.Error("Failed to list clients for stats rollup: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for _, client := range clients {
+		written, err := s.rollupClient(client, now, false)
+		if err != nil {
+			s.log.Error("Failed to roll up stats of client %s: %v", client.ID, err)
+			continue
+		}
+		if written > 0 {
+			s.log.Debug("Rolled up %d days of stats for client %s", written, client.ID)
+		}
+	}
+}
+
+// RollupClient rolls up the events of a single client right away, e.g. when it received
+// new events.
+func (s *StatsService) RollupClient(clientID string) error {
+	client, err := s.clientRepo.Get(clientID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.rollupClient(client, time.Now(), false)
+	return err
+}
+
+// RebuildClient rewrites the rollups of every day a client has events of, e.g. after its
+// event files were edited by hand. Rollups of days without events are kept, like those of
+// days whose events were cleaned up. It returns how many rollups were written.
+func (s *StatsService) RebuildClient(client *models.Client) (int, error) {
+	return s.rollupClient(client, time.Now(), true)
+}
+
+// rollupClient writes the due rollups of a single client, or with rebuild the rollups of
+// all days with events, and returns how many were written.
+func (s *StatsService) rollupClient(client *models.Client, now time.Time, rebuild bool) (int, error) {
+	// Events are read from disk on every call, so fetch them all at once
+	response, err := s.eventRepo.GetByClientID(client.ID, &models.EventListRequest{
+		Page:      1,
+		PageSize:  math.MaxInt32,
+		SortBy:    "timestamp",
+		SortOrder: "asc",
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	today := now.Format(models.StatsDateFormat)
+	yesterday := now.AddDate(0, 0, -1).Format(models.StatsDateFormat)
+
+	byDay := map[string][]*models.EventSummary{today: nil}
+	for _, event := range response.Events {
+		date := event.Timestamp.In(now.Location()).Format(models.StatsDateFormat)
+		byDay[date] = append(byDay[date], event)
+	}
+
+	written := 0
+	for date, events := range byDay {
+		previous, err := s.statsRepo.Get(client.UserID, client.ID, date)
+		if err != nil {
+			return written, err
+		}
+		if date < yesterday && previous != nil && !rebuild {
+			continue
+		}
+
+		stats := rollupDay(events)
+		stats.ClientID = client.ID
+		stats.Date = date
+		stats.UpdatedAt = now
+
+		// Storage is sampled by today's rollups; later rewrites of a day keep the last sample
+		switch {
+		case date == today:
+			if stats.StorageBytes, err = s.quotaRepo.CalculateClientUsage(client.UserID, client.ID); err != nil {
+				s.log.Error("Failed to calculate storage of client %s: %v", client.ID, err)
+			}
+		case previous != nil:
+			stats.StorageBytes = previous.StorageBytes
+		}
+
+		if err := s.statsRepo.Save(client.UserID, stats); err != nil {
+			return written, err
+		}
+		written++
+	}
+
+	return written, nil
+}
+
+// rollupDay aggregates the events of one day. Latency statistics cover delivered events only.
+func rollupDay(events []*models.EventSummary) *models.DailyStats {
+	stats := &models.DailyStats{Events: len(events)}
+
+	var latencies []int
+	for _, event := range events {
+		switch event.Status {
+		case models.EventStatusSuccess:
+			stats.Successful++
+		case models.EventStatusFailed:
+			stats.Failed++
+		}
+		if event.LatencyMs > 0 {
+			latencies = append(latencies, event.LatencyMs)
+		}
+	}
+
+	if len(latencies) > 0 {
+		stats.LatencyHistogram = make([]int, len(models.LatencyBucketsMs)+1)
+		for _, latency := range latencies {
+			stats.LatencyHistogram[latencyBucket(latency)]++
+			stats.LatencySumMs += int64(latency)
+		}
+
+		sort.Ints(latencies)
+		stats.LatencyP50Ms = percentile(latencies, 50)
+		stats.LatencyP95Ms = percentile(latencies, 95)
+		stats.AverageLatencyMs = int(stats.LatencySumMs / int64(len(latencies)))
+	}
+
+	return stats
+}
+
+// latencyBucket returns the index of the histogram bucket of a latency.
+func latencyBucket(latencyMs int) int {
+	return sort.SearchInts(models.LatencyBucketsMs, latencyMs)
+}
+
+// percentile returns the nearest-rank percentile p of sorted values.
+func percentile(sorted []int, p int) int {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// Daily retrieves the daily rollups of a client within a date range.
+func (s *StatsService) Daily(clientID string, req *models.DailyStatsRequest) (*models.DailyStatsResponse, error) {
+	client, err := s.clientRepo.Get(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client: %w", err)
+	}
+
+	to := req.DateTo
+	if to.IsZero() {
+		to = time.Now()
+	}
+	from := req.DateFrom
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -(defaultStatsDays - 1))
+	}
+
+	days, err := s.statsRepo.List(client.UserID, clientID, from.Format(models.StatsDateFormat), to.Format(models.StatsDateFormat))
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.DailyStatsResponse{
+		ClientID: clientID,
+		Days:     days,
+	}, nil
+}
+
+// Query aggregates the daily rollups of a client into day, week or month buckets.
+func (s *StatsService) Query(clientID string, req *models.StatsQueryRequest) (*models.StatsQueryResponse, error) {
+	client, err := s.clientRepo.Get(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client: %w", err)
+	}
+
+	granularity := req.Granularity
+	if granularity == "" {
+		granularity = models.StatsGranularityDay
+	}
+
+	now := time.Now()
+	to := startOfDay(now)
+	if !req.To.IsZero() {
+		to = startOfDay(req.To)
+	}
+	from := to.AddDate(0, 0, -(defaultStatsDays - 1))
+	if !req.From.IsZero() {
+		from = startOfDay(req.From)
+	}
+	if from.After(to) {
+		return nil, apperrors.NewInvalidInput("from must not be after to")
+	}
+
+	// Buckets cover whole weeks and months, clipped to the requested range
+	var buckets []*models.StatsBucket
+	for start := from; !start.After(to); {
+		next := nextBucket(start, granularity)
+		end := next.AddDate(0, 0, -1)
+		if end.After(to) {
+			end = to
+		}
+		buckets = append(buckets, &models.StatsBucket{
+			Start: start.Format(models.StatsDateFormat),
+			End:   end.Format(models.StatsDateFormat),
+		})
+		if len(buckets) > maxStatsBuckets {
+			return nil, apperrors.NewInvalidInput(fmt.Sprintf("range exceeds %d buckets, use a coarser granularity", maxStatsBuckets))
+		}
+		start = next
+	}
+
+	days, err := s.statsRepo.List(client.UserID, clientID, from.Format(models.StatsDateFormat), to.Format(models.StatsDateFormat))
+	if err != nil {
+		return nil, err
+	}
+
+	bucketDays := make([][]*models.DailyStats, len(buckets))
+	i := 0
+	for _, day := range days {
+		for i < len(buckets)-1 && day.Date > buckets[i].End {
+			i++
+		}
+		bucketDays[i] = append(bucketDays[i], day)
+	}
+	for i, bucket := range buckets {
+		aggregateStats(bucket, bucketDays[i])
+	}
+
+	total := &models.StatsBucket{Start: buckets[0].Start, End: buckets[len(buckets)-1].End}
+	aggregateStats(total, days)
+
+	return &models.StatsQueryResponse{
+		ClientID:    clientID,
+		Granularity: granularity,
+		From:        total.Start,
+		To:          total.End,
+		Buckets:     buckets,
+		Total:       total,
+	}, nil
+}
+
+// Heatmap counts the events of a client over the last weeks by weekday and hour of the day,
+// showing when its webhook source is active. It reads the stored events, so only events
+// within the retention period are counted.
+func (s *StatsService) Heatmap(clientID string, req *models.StatsHeatmapRequest) (*models.StatsHeatmapResponse, error) {
+	if _, err := s.clientRepo.Get(clientID); err != nil {
+		return nil, fmt.Errorf("failed to get client: %w", err)
+	}
+
+	location := time.Local
+	if req.Timezone != "" {
+		var err error
+		if location, err = time.LoadLocation(req.Timezone); err != nil {
+			return nil, apperrors.NewInvalidInput(fmt.Sprintf("invalid timezone: %s", req.Timezone))
+		}
+	}
+
+	to := time.Now().In(location)
+	from := to.AddDate(0, 0, -7*req.Weeks)
+	response, err := s.eventRepo.GetByClientID(clientID, &models.EventListRequest{
+		Page:     1,
+		PageSize: math.MaxInt32,
+		DateFrom: from,
+		DateTo:   to,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	heatmap := &models.StatsHeatmapResponse{
+		ClientID: clientID,
+		Weeks:    req.Weeks,
+		Timezone: location.String(),
+		From:     from,
+		To:       to,
+		Total:    len(response.Events),
+	}
+	for _, event := range response.Events {
+		local := event.Timestamp.In(location)
+		weekday := (int(local.Weekday()) + 6) % 7 // Monday first
+		heatmap.Counts[weekday][local.Hour()]++
+	}
+
+	return heatmap, nil
+}
+
+// Overview aggregates the rollups of all of a user's clients over the last 30 days:
+// events and deliveries per day, the storage trend and the clients failing most.
+func (s *StatsService) Overview(userID string) (*models.StatsOverviewResponse, error) {
+	clients, err := s.clientRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clients: %w", err)
+	}
+
+	to := startOfDay(time.Now())
+	from := to.AddDate(0, 0, -(defaultStatsDays - 1))
+	fromDate, toDate := from.Format(models.StatsDateFormat), to.Format(models.StatsDateFormat)
+
+	days := make([]*models.OverviewDay, 0, defaultStatsDays)
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		days = append(days, &models.OverviewDay{Date: day.Format(models.StatsDateFormat)})
+	}
+
+	var inRange []*models.DailyStats
+	failing := []*models.ClientFailures{}
+	for _, client := range clients {
+		// Earlier rollups carry the client's storage into the range
+		rollups, err := s.statsRepo.List(client.UserID, client.ID, "", toDate)
+		if err != nil {
+			return nil, err
+		}
+
+		failures := &models.ClientFailures{ClientID: client.ID, Name: client.Name}
+		var storage int64
+		i := 0
+		for _, day := range days {
+			for ; i < len(rollups) && rollups[i].Date <= day.Date; i++ {
+				rollup := rollups[i]
+				if rollup.StorageBytes > 0 {
+					storage = rollup.StorageBytes
+				}
+				if rollup.Date < fromDate {
+					continue
+				}
+				day.Events += rollup.Events
+				day.Successful += rollup.Successful
+				day.Failed += rollup.Failed
+				failures.Failed += rollup.Failed
+				failures.Deliveries += rollup.Successful + rollup.Failed
+				inRange = append(inRange, rollup)
+			}
+			day.StorageBytes += storage
+		}
+
+		if failures.Failed > 0 {
+			failures.FailureRate = math.Round(float64(failures.Failed)*10000/float64(failures.Deliveries)) / 100
+			failing = append(failing, failures)
+		}
+	}
+
+	sort.Slice(failing, func(i, j int) bool {
+		if failing[i].Failed != failing[j].Failed {
+			return failing[i].Failed > failing[j].Failed
+		}
+		return failing[i].FailureRate > failing[j].FailureRate
+	})
+	if len(failing) > overviewTopClients {
+		failing = failing[:overviewTopClients]
+	}
+
+	total := &models.StatsBucket{Start: fromDate, End: toDate}
+	aggregateStats(total, inRange)
+
+	return &models.StatsOverviewResponse{
+		From:              fromDate,
+		To:                toDate,
+		Clients:           len(clients),
+		Total:             total,
+		Days:              days,
+		TopFailingClients: failing,
+	}, nil
+}
+
+// startOfDay returns midnight (server local time) of the day of t.
+func startOfDay(t time.Time) time.Time {
+	t = t.In(time.Local)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// nextBucket returns the first day of the bucket following the one containing day.
+func nextBucket(day time.Time, granularity string) time.Time {
+	switch granularity {
+	case models.StatsGranularityWeek:
+		// Weeks start on Monday
+		return day.AddDate(0, 0, 7-(int(day.Weekday())+6)%7)
+	case models.StatsGranularityMonth:
+		return time.Date(day.Year(), day.Month()+1, 1, 0, 0, 0, 0, day.Location())
+	default:
+		return day.AddDate(0, 0, 1)
+	}
+}
+
+// aggregateStats sums daily rollups into bucket. Latency percentiles of several days are
+// computed from the merged latency histograms; rollups written before histograms were
+// recorded fall back to an estimate from the daily percentiles, weighted by deliveries.
+func aggregateStats(bucket *models.StatsBucket, days []*models.DailyStats) {
+	histogram := make([]int, len(models.LatencyBucketsMs)+1)
+	var p50s, p95s []weightedLatency
+	latencySum, latencyWeight := 0, 0
+	histogramComplete := true
+	for _, day := range days {
+		bucket.Events += day.Events
+		bucket.Successful += day.Successful
+		bucket.Failed += day.Failed
+		if day.AverageLatencyMs > 0 {
+			weight := max(day.Successful+day.Failed, 1)
+			if len(day.LatencyHistogram) == len(histogram) {
+				weight = 0
+				for i, count := range day.LatencyHistogram {
+					histogram[i] += count
+					weight += count
+				}
+			} else {
+				histogramComplete = false
+			}
+			latencySum += day.AverageLatencyMs * weight
+			latencyWeight += weight
+			p50s = append(p50s, weightedLatency{day.LatencyP50Ms, weight})
+			p95s = append(p95s, weightedLatency{day.LatencyP95Ms, weight})
+		}
+	}
+
+	bucket.LatencyHistogram = make([]models.LatencyBucket, len(histogram))
+	for i, count := range histogram {
+		bucket.LatencyHistogram[i].Count = count
+		if i < len(models.LatencyBucketsMs) {
+			bucket.LatencyHistogram[i].UpperMs = models.LatencyBucketsMs[i]
+		}
+	}
+
+	if delivered := bucket.Successful + bucket.Failed; delivered > 0 {
+		bucket.SuccessRate = math.Round(float64(bucket.Successful)*10000/float64(delivered)) / 100
+	}
+	if latencyWeight == 0 {
+		return
+	}
+
+	bucket.AverageLatencyMs = latencySum / latencyWeight
+	switch {
+	case len(p50s) == 1:
+		// The exact percentiles of a single day are known
+		bucket.LatencyP50Ms = p50s[0].latencyMs
+		bucket.LatencyP95Ms = p95s[0].latencyMs
+	case histogramComplete:
+		bucket.LatencyP50Ms = histogramPercentile(histogram, 50)
+		bucket.LatencyP95Ms = histogramPercentile(histogram, 95)
+	default:
+		bucket.LatencyP50Ms = weightedPercentile(p50s, 50)
+		bucket.LatencyP95Ms = weightedPercentile(p95s, 95)
+	}
+}
+
+// histogramPercentile estimates percentile p of a latency histogram by linear interpolation
+// within the bucket containing it. Percentiles in the unbounded bucket report its lower bound.
+func histogramPercentile(histogram []int, p int) int {
+	total := 0
+	for _, count := range histogram {
+		total += count
+	}
+	rank := float64(p) * float64(total) / 100
+
+	seen := 0
+	for i, count := range histogram {
+		if count == 0 || float64(seen+count) < rank {
+			seen += count
+			continue
+		}
+		lower := 0
+		if i > 0 {
+			lower = models.LatencyBucketsMs[i-1]
+		}
+		if i == len(models.LatencyBucketsMs) {
+			return lower
+		}
+		upper := models.LatencyBucketsMs[i]
+		return lower + int(math.Round(float64(upper-lower)*(rank-float64(seen))/float64(count)))
+	}
+	return 0
+}
+
+// weightedLatency is a latency with the number of deliveries it represents.
+type weightedLatency struct {
+	latencyMs int
+	weight    int
+}
+
+// weightedPercentile returns the value at percentile p of the weighted values.
+func weightedPercentile(values []weightedLatency, p int) int {
+	sort.Slice(values, func(i, j int) bool { return values[i].latencyMs < values[j].latencyMs })
+
+	total := 0
+	for _, v := range values {
+		total += v.weight
+	}
+	rank := (p*total + 99) / 100
+	for _, v := range values {
+		rank -= v.weight
+		if rank <= 0 {
+			return v.latencyMs
+		}
+	}
+	return values[len(values)-1].latencyMs
+}
+
+// applyTotals fills the event counts, success rate and average latency of client statistics
+// from all rollups of the client.
+func (s *StatsService) applyTotals(client *models.Client, stats *models.ClientStats) error {
+	days, err := s.statsRepo.List(client.UserID, client.ID, "", "")
+	if err != nil {
+		return err
+	}
+
+	total := &models.StatsBucket{}
+	aggregateStats(total, days)
+	stats.TotalEvents = total.Events
+	stats.SuccessRate = total.SuccessRate
+	stats.AverageLatency = total.AverageLatencyMs
+
+	today := time.Now().Format(models.StatsDateFormat)
+	for _, day := range days {
+		if day.Date == today {
+			stats.TodayEvents = day.Events
+		}
+	}
+
+	return nil
+}
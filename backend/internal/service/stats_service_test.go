@@ -0,0 +1,346 @@
+package service_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("StatsService", func() {
+	var (
+		baseDir       string
+		eventRepo     *repository.FileEventRepository
+		statsService  *service.StatsService
+		clientService *service.ClientService
+	)
+
+	BeforeEach(func() {
+		baseDir = GinkgoT().TempDir()
+		clientRepo, err := repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		eventRepo = repository.NewFileEventRepository(baseDir)
+		log := logger.New()
+		quotaRepo := repository.NewFileQuotaRepository(baseDir, 10*1024*1024, 10)
+		statsService = service.NewStatsService(clientRepo, eventRepo, repository.NewFileStatsRepository(baseDir), quotaRepo, log)
+		clientService = service.NewClientService(
+			clientRepo,
+			quotaRepo,
+			eventRepo,
+			service.NewProcessService(false, 0, time.Minute, log),
+			service.NewJobService(time.Hour, log),
+			baseDir,
+			log,
+		)
+		clientService.SetStatsService(statsService)
+
+		Expect(clientRepo.Create(&models.Client{ID: "client-stats", UserID: "user-stats", Name: "stats"})).To(Succeed())
+	})
+
+	saveEvent := func(id string, ts time.Time, status models.EventStatus, latencyMs int) {
+		Expect(eventRepo.Save("client-stats", &models.Event{ID: id, Timestamp: ts, Status: status, LatencyMs: latencyMs})).To(Succeed())
+	}
+
+	It("rolls up daily counts and latency percentiles that outlive the events", func() {
+		now := time.Now()
+		past := now.AddDate(0, 0, -5)
+		for i := 1; i <= 20; i++ {
+			saveEvent(fmt.Sprintf("evt-past-%d", i), past, models.EventStatusSuccess, i*10)
+		}
+		saveEvent("evt-past-failed", past, models.EventStatusFailed, 0)
+		saveEvent("evt-today", now, models.EventStatusSuccess, 50)
+
+		statsService.RollupEvents()
+
+		response, err := statsService.Daily("client-stats", &models.DailyStatsRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.Days).To(HaveLen(2))
+
+		day := response.Days[0]
+		Expect(day.Date).To(Equal(past.Format(models.StatsDateFormat)))
+		Expect(day.Events).To(Equal(21))
+		Expect(day.Successful).To(Equal(20))
+		Expect(day.Failed).To(Equal(1))
+		Expect(day.LatencyP50Ms).To(Equal(100))
+		Expect(day.LatencyP95Ms).To(Equal(190))
+		Expect(day.AverageLatencyMs).To(Equal(105))
+
+		// Rollups of past days survive event cleanup
+		eventsDir := filepath.Join(baseDir, "users", "user-stats", "clients", "client-stats", "events")
+		Expect(os.RemoveAll(eventsDir)).To(Succeed())
+		Expect(os.MkdirAll(eventsDir, 0755)).To(Succeed())
+		saveEvent("evt-today", now, models.EventStatusSuccess, 50)
+		statsService.RollupEvents()
+
+		stats, err := clientService.GetStats("client-stats")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stats.TotalEvents).To(Equal(22))
+		Expect(stats.TodayEvents).To(Equal(1))
+		Expect(stats.SuccessRate).To(BeNumerically("~", 95.45, 0.01))
+		Expect(stats.AverageLatency).To(Equal(102))
+	})
+
+	It("limits daily stats to the requested range", func() {
+		saveEvent("evt-old", time.Now().AddDate(0, 0, -40), models.EventStatusSuccess, 10)
+		saveEvent("evt-new", time.Now(), models.EventStatusSuccess, 10)
+		statsService.RollupEvents()
+
+		response, err := statsService.Daily("client-stats", &models.DailyStatsRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.Days).To(HaveLen(1))
+
+		response, err = statsService.Daily("client-stats", &models.DailyStatsRequest{DateFrom: time.Now().AddDate(0, 0, -60)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.Days).To(HaveLen(2))
+	})
+
+	It("counts events of the last weeks by weekday and hour", func() {
+		tokyo, err := time.LoadLocation("Asia/Tokyo")
+		Expect(err).NotTo(HaveOccurred())
+		// A Wednesday 10:30 in Tokyo, at least a week ago
+		day := time.Now().In(tokyo).AddDate(0, 0, -7)
+		for day.Weekday() != time.Wednesday {
+			day = day.AddDate(0, 0, -1)
+		}
+		wednesday := time.Date(day.Year(), day.Month(), day.Day(), 10, 30, 0, 0, tokyo)
+
+		saveEvent("evt-1", wednesday.UTC(), models.EventStatusSuccess, 10)
+		saveEvent("evt-2", wednesday.Add(15*time.Minute).UTC(), models.EventStatusFailed, 0)
+		saveEvent("evt-old", wednesday.AddDate(0, 0, -35).UTC(), models.EventStatusSuccess, 10)
+
+		heatmap, err := statsService.Heatmap("client-stats", &models.StatsHeatmapRequest{Weeks: 4, Timezone: "Asia/Tokyo"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(heatmap.Total).To(Equal(2))
+		Expect(heatmap.Counts[2][10]).To(Equal(2))
+		Expect(heatmap.Timezone).To(Equal("Asia/Tokyo"))
+
+		heatmap, err = statsService.Heatmap("client-stats", &models.StatsHeatmapRequest{Weeks: 8, Timezone: "Asia/Tokyo"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(heatmap.Total).To(Equal(3))
+
+		_, err = statsService.Heatmap("client-stats", &models.StatsHeatmapRequest{Weeks: 4, Timezone: "Mars/Olympus"})
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("StatsService queries", func() {
+	var (
+		statsRepo    *repository.FileStatsRepository
+		statsService *service.StatsService
+	)
+
+	BeforeEach(func() {
+		baseDir := GinkgoT().TempDir()
+		clientRepo, err := repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		statsRepo = repository.NewFileStatsRepository(baseDir)
+		statsService = service.NewStatsService(clientRepo, repository.NewFileEventRepository(baseDir), statsRepo, repository.NewFileQuotaRepository(baseDir, 10*1024*1024, 10), logger.New())
+		Expect(clientRepo.Create(&models.Client{ID: "client-query", UserID: "user-query", Name: "query"})).To(Succeed())
+
+		for _, day := range []*models.DailyStats{
+			{Date: "2025-09-29", Events: 10, Successful: 8, Failed: 2, LatencyP50Ms: 100, LatencyP95Ms: 300, AverageLatencyMs: 120}, // Monday
+			{Date: "2025-10-01", Events: 30, Successful: 30, LatencyP50Ms: 50, LatencyP95Ms: 90, AverageLatencyMs: 60},
+			{Date: "2025-10-06", Events: 5, Successful: 0, Failed: 0}, // Monday, saved only
+		} {
+			day.ClientID = "client-query"
+			Expect(statsRepo.Save("user-query", day)).To(Succeed())
+		}
+	})
+
+	It("aggregates rollups into weekly buckets clipped to the range", func() {
+		response, err := statsService.Query("client-query", &models.StatsQueryRequest{
+			From:        time.Date(2025, 9, 30, 0, 0, 0, 0, time.Local),
+			To:          time.Date(2025, 10, 7, 0, 0, 0, 0, time.Local),
+			Granularity: models.StatsGranularityWeek,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.Buckets).To(HaveLen(2))
+
+		first := response.Buckets[0]
+		Expect([]string{first.Start, first.End}).To(Equal([]string{"2025-09-30", "2025-10-05"}))
+		Expect(first.Events).To(Equal(30))
+		Expect(first.SuccessRate).To(Equal(100.0))
+
+		second := response.Buckets[1]
+		Expect([]string{second.Start, second.End}).To(Equal([]string{"2025-10-06", "2025-10-07"}))
+		Expect(second.Events).To(Equal(5))
+		Expect(second.LatencyP95Ms).To(BeZero())
+	})
+
+	It("includes empty days and estimates percentiles across days", func() {
+		response, err := statsService.Query("client-query", &models.StatsQueryRequest{
+			From: time.Date(2025, 9, 29, 0, 0, 0, 0, time.Local),
+			To:   time.Date(2025, 10, 6, 0, 0, 0, 0, time.Local),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.Granularity).To(Equal(models.StatsGranularityDay))
+		Expect(response.Buckets).To(HaveLen(8))
+		Expect(response.Buckets[1].Events).To(BeZero())
+
+		total := response.Total
+		Expect(total.Events).To(Equal(45))
+		Expect(total.SuccessRate).To(Equal(95.0))
+		Expect(total.AverageLatencyMs).To(Equal(75))
+		Expect(total.LatencyP50Ms).To(Equal(50))
+		Expect(total.LatencyP95Ms).To(Equal(300))
+	})
+
+	It("rejects inverted and oversized ranges", func() {
+		_, err := statsService.Query("client-query", &models.StatsQueryRequest{
+			From: time.Date(2025, 10, 2, 0, 0, 0, 0, time.Local),
+			To:   time.Date(2025, 10, 1, 0, 0, 0, 0, time.Local),
+		})
+		Expect(err).To(HaveOccurred())
+
+		_, err = statsService.Query("client-query", &models.StatsQueryRequest{
+			From: time.Date(2020, 1, 1, 0, 0, 0, 0, time.Local),
+			To:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local),
+		})
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("StatsService latency histograms", func() {
+	var (
+		eventRepo    *repository.FileEventRepository
+		statsRepo    *repository.FileStatsRepository
+		statsService *service.StatsService
+	)
+
+	BeforeEach(func() {
+		baseDir := GinkgoT().TempDir()
+		clientRepo, err := repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		eventRepo = repository.NewFileEventRepository(baseDir)
+		statsRepo = repository.NewFileStatsRepository(baseDir)
+		statsService = service.NewStatsService(clientRepo, eventRepo, statsRepo, repository.NewFileQuotaRepository(baseDir, 10*1024*1024, 10), logger.New())
+		Expect(clientRepo.Create(&models.Client{ID: "client-hist", UserID: "user-hist", Name: `hist "prod"`})).To(Succeed())
+	})
+
+	It("records latencies into histogram buckets and exposes them to Prometheus", func() {
+		now := time.Now()
+		for i, latency := range []int{5, 10, 40, 80, 3000, 20000} {
+			Expect(eventRepo.Save("client-hist", &models.Event{
+				ID: fmt.Sprintf("evt-%d", i), Timestamp: now, Status: models.EventStatusSuccess, LatencyMs: latency,
+			})).To(Succeed())
+		}
+		statsService.RollupEvents()
+
+		days, err := statsRepo.List("user-hist", "client-hist", "", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(days).To(HaveLen(1))
+		Expect(days[0].LatencyHistogram).To(Equal([]int{2, 0, 1, 1, 0, 0, 0, 0, 1, 0, 1}))
+		Expect(days[0].LatencySumMs).To(Equal(int64(23135)))
+
+		var buf strings.Builder
+		Expect(statsService.WriteMetrics(&buf, "user-hist")).To(Succeed())
+		labels := `client_id="client-hist",client_name="hist \"prod\""`
+		Expect(buf.String()).To(ContainSubstring(`gosmee_events_total{` + labels + `} 6`))
+		Expect(buf.String()).To(ContainSubstring(`gosmee_deliveries_total{` + labels + `,result="success"} 6`))
+		Expect(buf.String()).To(ContainSubstring(`gosmee_delivery_latency_seconds_bucket{` + labels + `,le="0.05"} 3`))
+		Expect(buf.String()).To(ContainSubstring(`gosmee_delivery_latency_seconds_bucket{` + labels + `,le="+Inf"} 6`))
+		Expect(buf.String()).To(ContainSubstring(`gosmee_delivery_latency_seconds_sum{` + labels + `} 23.135`))
+	})
+
+	It("computes percentiles of several days from the merged histograms", func() {
+		for _, day := range []*models.DailyStats{
+			{Date: "2025-10-01", Successful: 10, LatencyHistogram: []int{0, 0, 0, 10, 0, 0, 0, 0, 0, 0, 0}, LatencySumMs: 800, AverageLatencyMs: 80, LatencyP50Ms: 80, LatencyP95Ms: 95},
+			{Date: "2025-10-02", Successful: 10, LatencyHistogram: []int{0, 0, 0, 0, 0, 0, 10, 0, 0, 0, 0}, LatencySumMs: 8000, AverageLatencyMs: 800, LatencyP50Ms: 800, LatencyP95Ms: 990},
+		} {
+			day.ClientID = "client-hist"
+			Expect(statsRepo.Save("user-hist", day)).To(Succeed())
+		}
+
+		response, err := statsService.Query("client-hist", &models.StatsQueryRequest{
+			From: time.Date(2025, 10, 1, 0, 0, 0, 0, time.Local),
+			To:   time.Date(2025, 10, 2, 0, 0, 0, 0, time.Local),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		total := response.Total
+		Expect(total.LatencyP50Ms).To(Equal(100))
+		Expect(total.LatencyP95Ms).To(Equal(950))
+		Expect(total.LatencyHistogram).To(HaveLen(len(models.LatencyBucketsMs) + 1))
+		Expect(total.LatencyHistogram[3]).To(Equal(models.LatencyBucket{UpperMs: 100, Count: 10}))
+		Expect(total.LatencyHistogram[10].UpperMs).To(BeZero())
+	})
+})
+
+var _ = Describe("StatsService overview", func() {
+	var (
+		statsRepo    *repository.FileStatsRepository
+		statsService *service.StatsService
+	)
+
+	date := func(daysAgo int) string {
+		return time.Now().AddDate(0, 0, -daysAgo).Format(models.StatsDateFormat)
+	}
+
+	BeforeEach(func() {
+		baseDir := GinkgoT().TempDir()
+		clientRepo, err := repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		statsRepo = repository.NewFileStatsRepository(baseDir)
+		statsService = service.NewStatsService(clientRepo, repository.NewFileEventRepository(baseDir), statsRepo, repository.NewFileQuotaRepository(baseDir, 10*1024*1024, 10), logger.New())
+
+		for _, client := range []*models.Client{
+			{ID: "client-ok", UserID: "user-overview", Name: "ok"},
+			{ID: "client-flaky", UserID: "user-overview", Name: "flaky"},
+			{ID: "client-foreign", UserID: "user-foreign", Name: "foreign"},
+		} {
+			Expect(clientRepo.Create(client)).To(Succeed())
+		}
+	})
+
+	It("aggregates all clients of the user from rollups", func() {
+		for _, day := range []*models.DailyStats{
+			{ClientID: "client-ok", Date: date(45), Events: 100, Successful: 100, StorageBytes: 1000},
+			{ClientID: "client-ok", Date: date(2), Events: 10, Successful: 10, StorageBytes: 1500},
+			{ClientID: "client-flaky", Date: date(2), Events: 10, Successful: 6, Failed: 4, StorageBytes: 500},
+			{ClientID: "client-flaky", Date: date(1), Events: 5, Failed: 5},
+		} {
+			Expect(statsRepo.Save("user-overview", day)).To(Succeed())
+		}
+		Expect(statsRepo.Save("user-foreign", &models.DailyStats{ClientID: "client-foreign", Date: date(1), Events: 99, Failed: 99})).To(Succeed())
+
+		overview, err := statsService.Overview("user-overview")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(overview.Clients).To(Equal(2))
+		Expect(overview.Days).To(HaveLen(30))
+		Expect(overview.To).To(Equal(date(0)))
+
+		// Storage of days without rollups is carried over from earlier rollups
+		Expect(overview.Days[0].StorageBytes).To(Equal(int64(1000)))
+		Expect(overview.Days[27].Events).To(Equal(20))
+		Expect(overview.Days[27].StorageBytes).To(Equal(int64(2000)))
+		Expect(overview.Days[28].Failed).To(Equal(5))
+		Expect(overview.Days[28].StorageBytes).To(Equal(int64(2000)))
+
+		Expect(overview.Total.Events).To(Equal(25))
+		Expect(overview.Total.Failed).To(Equal(9))
+
+		Expect(overview.TopFailingClients).To(HaveLen(1))
+		Expect(*overview.TopFailingClients[0]).To(Equal(models.ClientFailures{
+			ClientID: "client-flaky", Name: "flaky", Failed: 9, Deliveries: 15, FailureRate: 60,
+		}))
+	})
+
+	It("samples client storage in today's rollup", func() {
+		statsService.RollupEvents()
+
+		today, err := statsRepo.Get("user-overview", "client-ok", date(0))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(today).NotTo(BeNil())
+		Expect(today.Events).To(BeZero())
+		Expect(today.StorageBytes).To(BeNumerically(">", 0))
+	})
+})
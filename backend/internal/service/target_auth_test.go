@@ -0,0 +1,181 @@
+package service_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/encryption"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("Target credentials", func() {
+	var (
+		baseDir       string
+		eventRepo     *repository.FileEventRepository
+		clientService *service.ClientService
+		eventService  *service.EventService
+		target        *httptest.Server
+		mu            sync.Mutex
+		authHeaders   []string
+		targetStatus  int
+	)
+
+	BeforeEach(func() {
+		authHeaders = nil
+		targetStatus = http.StatusOK
+		target = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			authHeaders = append(authHeaders, r.Header.Get("Authorization"))
+			mu.Unlock()
+			w.WriteHeader(targetStatus)
+		}))
+		DeferCleanup(target.Close)
+
+		baseDir = GinkgoT().TempDir()
+		log := logger.New()
+		clientRepo, err := repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		eventRepo = repository.NewFileEventRepository(baseDir)
+		cipher, err := encryption.NewCipher(make([]byte, encryption.KeySize))
+		Expect(err).NotTo(HaveOccurred())
+		secretRepo := repository.NewFileSecretRepository(baseDir)
+		secretRepo.SetCipher(cipher)
+
+		clientService = service.NewClientService(
+			clientRepo,
+			repository.NewFileQuotaRepository(baseDir, 10*1024*1024, 10),
+			eventRepo,
+			service.NewProcessService(false, 0, time.Minute, log),
+			service.NewJobService(time.Hour, log),
+			baseDir,
+			log,
+		)
+		clientService.SetSecretRepository(secretRepo)
+		eventService = service.NewEventService(
+			eventRepo,
+			clientRepo,
+			service.NewJobService(time.Hour, log),
+			service.NewCircuitBreakerService(0, time.Minute, service.NewNotificationService(log), log),
+			log,
+		)
+		eventService.SetSecretRepository(secretRepo)
+	})
+
+	request := func(auth *models.TargetAuthRequest) *models.ClientRequest {
+		return &models.ClientRequest{
+			Name:          "auth",
+			SmeeURL:       "https://smee.io/abc",
+			TargetURL:     target.URL,
+			TargetTimeout: 5,
+			TargetAuth:    auth,
+		}
+	}
+
+	replay := func(client *models.Client) *models.EventReplayResult {
+		Expect(eventRepo.Save(client.ID, &models.Event{ID: "evt-1", ClientID: client.ID, Timestamp: time.Now().UTC(), Payload: "{}"})).To(Succeed())
+		response, err := eventService.Replay(client.ID, &models.EventReplayRequest{EventIDs: []string{"evt-1"}})
+		Expect(err).NotTo(HaveOccurred())
+		return response.Results[0]
+	}
+
+	It("stores secrets encrypted apart from the client config and sends them to the target", func() {
+		client, err := clientService.Create("user-auth", request(&models.TargetAuthRequest{Type: "basic", Username: "hook", Password: "s3cret"}))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(client.TargetAuth).To(Equal(&models.TargetAuth{Type: "basic", Username: "hook"}))
+
+		clientDir := filepath.Join(baseDir, "users", "user-auth", "clients", client.ID)
+		config, err := os.ReadFile(filepath.Join(clientDir, "config.json"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(config)).NotTo(ContainSubstring("s3cret"))
+		secrets, err := os.ReadFile(filepath.Join(clientDir, "secrets.json"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(encryption.IsSealed(secrets)).To(BeTrue())
+
+		Expect(replay(client).Success).To(BeTrue())
+		Expect(authHeaders).To(Equal([]string{"Basic aG9vazpzM2NyZXQ="}))
+	})
+
+	It("keeps stored secrets on update and removes them with the credentials", func() {
+		client, err := clientService.Create("user-auth", request(&models.TargetAuthRequest{Type: "bearer", Token: "tok-1"}))
+		Expect(err).NotTo(HaveOccurred())
+
+		// Same type without a token keeps the stored token
+		client, err = clientService.Update(client.ID, request(&models.TargetAuthRequest{Type: "bearer"}), false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(replay(client).Success).To(BeTrue())
+		Expect(authHeaders).To(Equal([]string{"Bearer tok-1"}))
+
+		// Switching type requires new secrets
+		_, err = clientService.Update(client.ID, request(&models.TargetAuthRequest{Type: "basic"}), false)
+		Expect(err).To(HaveOccurred())
+
+		client, err = clientService.Update(client.ID, request(nil), false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(client.TargetAuth).To(BeNil())
+		Expect(filepath.Join(baseDir, "users", "user-auth", "clients", client.ID, "secrets.json")).NotTo(BeAnExistingFile())
+	})
+
+	It("forwards new events of clients with credentials", func() {
+		client, err := clientService.Create("user-auth", request(&models.TargetAuthRequest{Type: "bearer", Token: "tok-2"}))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(eventRepo.Save(client.ID, &models.Event{ID: "evt-new", ClientID: client.ID, Timestamp: time.Now().UTC(), Payload: "{}"})).To(Succeed())
+
+		eventService.ForwardNewEvents(client.ID, []string{"evt-new"})
+
+		Expect(authHeaders).To(Equal([]string{"Bearer tok-2"}))
+		stored, err := eventRepo.Get(client.ID, "evt-new")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored.Status).To(Equal(models.EventStatusSuccess))
+	})
+
+	It("acquires, caches and renews OAuth2 access tokens", func() {
+		var tokenRequests []string
+		tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.ParseForm()).To(Succeed())
+			clientID, clientSecret, _ := r.BasicAuth()
+			tokenRequests = append(tokenRequests, fmt.Sprintf("%s:%s %s %s", clientID, clientSecret, r.Form.Get("grant_type"), r.Form.Get("scope")))
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"access_token":"access-%d","token_type":"Bearer","expires_in":3600}`, len(tokenRequests))
+		}))
+		DeferCleanup(tokenServer.Close)
+
+		client, err := clientService.Create("user-auth", request(&models.TargetAuthRequest{
+			Type:         "oauth2",
+			TokenURL:     tokenServer.URL,
+			ClientID:     "gosmee",
+			ClientSecret: "client-secret",
+			Scopes:       []string{"hooks:write"},
+		}))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(client.TargetAuth.ClientID).To(Equal("gosmee"))
+
+		Expect(replay(client).Success).To(BeTrue())
+		Expect(replay(client).Success).To(BeTrue())
+		Expect(tokenRequests).To(Equal([]string{"gosmee:client-secret client_credentials hooks:write"}))
+		Expect(authHeaders).To(Equal([]string{"Bearer access-1", "Bearer access-1"}))
+
+		// A rejected token is renewed for the next delivery
+		targetStatus = http.StatusUnauthorized
+		Expect(replay(client).Success).To(BeFalse())
+		targetStatus = http.StatusOK
+		Expect(replay(client).Success).To(BeTrue())
+		Expect(tokenRequests).To(HaveLen(2))
+		Expect(authHeaders[len(authHeaders)-1]).To(Equal("Bearer access-2"))
+	})
+
+	It("rejects incomplete OAuth2 configuration", func() {
+		_, err := clientService.Create("user-auth", request(&models.TargetAuthRequest{Type: "oauth2", TokenURL: "ftp://tokens", ClientID: "gosmee", ClientSecret: "s"}))
+		Expect(err).To(MatchError(ContainSubstring("token URL")))
+	})
+})
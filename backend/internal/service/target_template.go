@@ -0,0 +1,76 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"text/template"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+)
+
+// targetURLData is the data available to target URL templates. Values are path-escaped, so
+// they can be used as path segments or query values.
+type targetURLData struct {
+	EventType string // Event type (e.g. "push"), empty if unknown
+	EventID   string // Event ID
+	ClientID  string // Client ID
+	Source    string // Event source (e.g. "github.com/myorg/myrepo")
+}
+
+// ValidateTargetURL checks that a templated target URL parses and resolves to an http or
+// https URL. Target URLs without template variables are accepted as they are.
+func ValidateTargetURL(targetURL string) error {
+	if !models.IsTargetTemplate(targetURL) {
+		return nil
+	}
+
+	resolved, err := renderTargetURL(targetURL, targetURLData{EventType: "push", EventID: "event", ClientID: "client", Source: "source"})
+	if err != nil {
+		return err
+	}
+	parsed, err := url.Parse(resolved)
+	if err != nil {
+		return fmt.Errorf("template resolves to an invalid URL: %w", err)
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("template must resolve to an http or https URL")
+	}
+	return nil
+}
+
+// resolveTargetURL returns the URL an event is delivered to, resolving the template
+// variables of the client's target URL for the event.
+func resolveTargetURL(client *models.Client, event *models.Event) (string, error) {
+	if !client.HasTargetTemplate() {
+		return client.TargetURL, nil
+	}
+
+	resolved, err := renderTargetURL(client.TargetURL, targetURLData{
+		EventType: url.PathEscape(event.EventType),
+		EventID:   url.PathEscape(event.ID),
+		ClientID:  url.PathEscape(client.ID),
+		Source:    url.PathEscape(event.Source),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve target URL: %w", err)
+	}
+	return resolved, nil
+}
+
+// renderTargetURL executes a target URL template.
+func renderTargetURL(targetURL string, data targetURLData) (string, error) {
+	tmpl, err := template.New("targetUrl").Option("missingkey=error").Parse(targetURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid target URL template: %w", err)
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("invalid target URL template: %w", err)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,79 @@
+package service_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("Templated target URLs", func() {
+	It("delivers each event type to its own route", func() {
+		var paths []string
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			paths = append(paths, r.URL.EscapedPath()+"?"+r.URL.RawQuery)
+		}))
+		defer target.Close()
+
+		baseDir := GinkgoT().TempDir()
+		log := logger.New()
+		clientRepo, err := repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		eventRepo := repository.NewFileEventRepository(baseDir)
+		eventService := service.NewEventService(
+			eventRepo,
+			clientRepo,
+			service.NewJobService(time.Hour, log),
+			service.NewCircuitBreakerService(0, time.Minute, service.NewNotificationService(log), log),
+			log,
+		)
+
+		client := &models.Client{
+			ID:            "client-template",
+			UserID:        "user-template",
+			TargetURL:     target.URL + `/hooks/{{or .EventType "other"}}?source={{.Source}}`,
+			TargetTimeout: 5,
+		}
+		Expect(clientRepo.Create(client)).To(Succeed())
+
+		for _, event := range []*models.Event{
+			{ID: "evt-push", EventType: "push", Source: "github.com/org/repo"},
+			{ID: "evt-unknown"},
+		} {
+			event.ClientID = client.ID
+			event.Timestamp = time.Now().UTC()
+			event.Payload = "{}"
+			Expect(eventRepo.Save(client.ID, event)).To(Succeed())
+		}
+
+		eventService.ForwardNewEvents(client.ID, []string{"evt-push", "evt-unknown"})
+
+		Expect(paths).To(Equal([]string{
+			"/hooks/push?source=github.com%2Forg%2Frepo",
+			"/hooks/other?source=",
+		}))
+	})
+
+	DescribeTable("validates target URL templates",
+		func(targetURL string, valid bool) {
+			err := service.ValidateTargetURL(targetURL)
+			if valid {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(HaveOccurred())
+			}
+		},
+		Entry("plain URL", "http://localhost:3000/hooks", true),
+		Entry("event type path", "https://api.internal/hooks/{{.EventType}}", true),
+		Entry("unknown variable", "https://api.internal/hooks/{{.Repository}}", false),
+		Entry("unclosed action", "https://api.internal/hooks/{{.EventType", false),
+		Entry("templated scheme", "{{.EventType}}://api.internal", false),
+	)
+})
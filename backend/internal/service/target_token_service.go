@@ -0,0 +1,90 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// tokenRequestTimeout limits requests to OAuth2 token endpoints.
+const tokenRequestTimeout = 30 * time.Second
+
+// TargetTokenService acquires access tokens for targets protected by OAuth2 with the client
+// credentials flow. Tokens are cached per client and renewed shortly before they expire.
+type TargetTokenService struct {
+	log logger.Logger
+
+	mu      sync.Mutex
+	sources map[string]*targetTokenSource // clientID -> token source
+}
+
+// targetTokenSource is the cached token source of a client.
+type targetTokenSource struct {
+	config string // Configuration the source was created for
+	source oauth2.TokenSource
+}
+
+// NewTargetTokenService creates a new target token service.
+func NewTargetTokenService(log logger.Logger) *TargetTokenService {
+	return &TargetTokenService{
+		log:     log,
+		sources: make(map[string]*targetTokenSource),
+	}
+}
+
+// Token returns a valid access token for the target of a client with oauth2 authentication,
+// requesting a new one from the token endpoint when the cached token expired or the client
+// configuration changed.
+func (s *TargetTokenService) Token(client *models.Client, clientSecret string) (string, error) {
+	auth := client.TargetAuth
+	config := strings.Join([]string{auth.TokenURL, auth.ClientID, clientSecret, strings.Join(auth.Scopes, " "), auth.Audience}, "\n")
+
+	s.mu.Lock()
+	cached, exists := s.sources[client.ID]
+	if !exists || cached.config != config {
+		credentials := &clientcredentials.Config{
+			ClientID:     auth.ClientID,
+			ClientSecret: clientSecret,
+			TokenURL:     auth.TokenURL,
+			Scopes:       auth.Scopes,
+		}
+		if auth.Audience != "" {
+			credentials.EndpointParams = url.Values{"audience": {auth.Audience}}
+		}
+		ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{Timeout: tokenRequestTimeout})
+		cached = &targetTokenSource{config: config, source: credentials.TokenSource(ctx)}
+		s.sources[client.ID] = cached
+	}
+	s.mu.Unlock()
+
+	token, err := cached.source.Token()
+	if err != nil {
+		return "", fmt.Errorf("failed to acquire target access token: %w", err)
+	}
+
+	return token.AccessToken, nil
+}
+
+// Invalidate drops the cached token of a client, e.g. after the target rejected it, so the
+// next delivery requests a new one.
+func (s *TargetTokenService) Invalidate(clientID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.sources[clientID]; exists {
+		delete(s.sources, clientID)
+		s.log.Info("Dropped cached target access token of client %s", clientID)
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+)
+
+// TieringService moves old events to cold storage, a secondary data directory on a slower,
+// bigger disk. The event repository keeps them readable.
+type TieringService struct {
+	clientRepo repository.ClientRepository
+	eventRepo  repository.EventRepository
+	coldAfter  int // Days after which events are moved
+	log        logger.Logger
+}
+
+// NewTieringService creates a new tiering service moving events older than coldAfterDays.
+func NewTieringService(clientRepo repository.ClientRepository, eventRepo repository.EventRepository, coldAfterDays int, log logger.Logger) *TieringService {
+	return &TieringService{
+		clientRepo: clientRepo,
+		eventRepo:  eventRepo,
+		coldAfter:  coldAfterDays,
+		log:        log,
+	}
+}
+
+// MoveColdEvents moves the events of all clients that are older than the configured
+// number of days to cold storage. It is executed periodically by the scheduler.
+func (s *TieringService) MoveColdEvents() {
+	clients, err := s.clientRepo.ListAll()
+	if err != nil {
+		s.log.Error("Failed to list clients for storage tiering: %v", err)
+		return
+	}
+
+	for _, client := range clients {
+		moved, err := s.eventRepo.MoveToCold(client.ID, s.coldAfter)
+		if err != nil {
+			s.log.Error("Failed to move events of client %s to cold storage: %v", client.ID, err)
+		}
+		if moved > 0 {
+			s.log.Info("Moved %d events of client %s to cold storage", moved, client.ID)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"time"
+
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+)
+
+// UploadService moves the events gosmee writes to the local data directory to object
+// storage, when events are stored in S3.
+type UploadService struct {
+	clientRepo repository.ClientRepository
+	eventRepo  *repository.S3EventRepository
+	log        logger.Logger
+}
+
+// NewUploadService creates a new upload service.
+func NewUploadService(clientRepo repository.ClientRepository, eventRepo *repository.S3EventRepository, log logger.Logger) *UploadService {
+	return &UploadService{
+		clientRepo: clientRepo,
+		eventRepo:  eventRepo,
+		log:        log,
+	}
+}
+
+// UploadNewEvents uploads the local events of all clients that gosmee finished writing.
+// It is executed periodically by the scheduler.
+func (s *UploadService) UploadNewEvents() {
+	clients, err := s.clientRepo.ListAll()
+	if err != nil {
+		s.log.Error("Failed to list clients for event upload: %v", err)
+		return
+	}
+
+	until := time.Now().Add(-eventSettleTime)
+	for _, client := range clients {
+		uploaded, err := s.eventRepo.UploadLocalEvents(client.ID, until)
+		if err != nil {
+			s.log.Error("Failed to upload events of client %s: %v", client.ID, err)
+		}
+		if uploaded > 0 {
+			s.log.Debug("Uploaded %d events of client %s to object storage", uploaded, client.ID)
+		}
+	}
+}
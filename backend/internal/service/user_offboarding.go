@@ -0,0 +1,200 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+)
+
+// offboardingArchiveDir is the directory, relative to each data directory, archived user
+// data is moved to. It lies outside users/ so archived data counts against no quota.
+const offboardingArchiveDir = "archive"
+
+// UserOffboardingService removes a user from the instance: it stops and removes the
+// user's clients, deletes or archives the user's data, revokes the user's sessions and
+// service accounts, and records the offboarding in an audit log.
+type UserOffboardingService struct {
+	dataDir               string
+	coldDir               string // Cold storage data directory (empty = disabled)
+	clientService         *ClientService
+	clientRepo            repository.ClientRepository
+	quotaRepo             repository.QuotaRepository
+	sessionService        *SessionService
+	serviceAccountService *ServiceAccountService
+	auditRepo             repository.OffboardingRepository
+	log                   logger.Logger
+
+	mu sync.Mutex // Serializes offboardings
+}
+
+// NewUserOffboardingService creates a new user offboarding service for the data directory.
+func NewUserOffboardingService(
+	dataDir string,
+	clientService *ClientService,
+	clientRepo repository.ClientRepository,
+	quotaRepo repository.QuotaRepository,
+	sessionService *SessionService,
+	serviceAccountService *ServiceAccountService,
+	auditRepo repository.OffboardingRepository,
+	log logger.Logger,
+) *UserOffboardingService {
+	return &UserOffboardingService{
+		dataDir:               dataDir,
+		clientService:         clientService,
+		clientRepo:            clientRepo,
+		quotaRepo:             quotaRepo,
+		sessionService:        sessionService,
+		serviceAccountService: serviceAccountService,
+		auditRepo:             auditRepo,
+		log:                   log,
+	}
+}
+
+// SetColdDir makes offboardings include the user's data in the cold data directory.
+func (s *UserOffboardingService) SetColdDir(coldDir string) {
+	s.coldDir = coldDir
+}
+
+// Offboard removes a user on behalf of an administrator. The user's data is deleted, or
+// moved to archive/users/<userID>-<time> in each data directory with req.Archive. Events
+// in S3 object storage are deleted with the clients, but kept when archiving.
+func (s *UserOffboardingService) Offboard(adminID, userID string, req *models.UserOffboardRequest) (*models.UserOffboardResult, error) {
+	if !isPathSegment(userID) {
+		return nil, apperrors.ErrUserNotFound
+	}
+	if userID == adminID {
+		return nil, apperrors.NewInvalidInput("administrators cannot offboard themselves")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hasData := false
+	for _, root := range s.roots() {
+		if _, err := os.Stat(filepath.Join(root, "users", userID)); err == nil {
+			hasData = true
+		}
+	}
+	accounts, err := s.serviceAccountService.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list service accounts: %w", err)
+	}
+	userAccounts := []string{}
+	for _, account := range accounts.ServiceAccounts {
+		if account.UserID == userID {
+			userAccounts = append(userAccounts, account.ID)
+		}
+	}
+
+	result := &models.UserOffboardResult{UserID: userID, Archived: req.Archive}
+
+	// Sign the user out first so nothing is changed while the data is removed
+	result.SessionsRevoked = s.sessionService.RevokeUserSessions(userID, "")
+	for _, id := range userAccounts {
+		if err := s.serviceAccountService.Delete(id); err != nil {
+			return nil, fmt.Errorf("failed to delete service account %s: %w", id, err)
+		}
+		result.ServiceAccountsDeleted++
+	}
+	if !hasData && result.SessionsRevoked == 0 && result.ServiceAccountsDeleted == 0 {
+		return nil, apperrors.ErrUserNotFound
+	}
+
+	if hasData {
+		if err := s.removeData(userID, req.Archive, result); err != nil {
+			return nil, err
+		}
+	}
+	s.quotaRepo.InvalidateCache(userID)
+
+	action := "deleted"
+	if req.Archive {
+		action = "archived"
+	}
+	s.log.Info("Offboarded user %s (admin %s): %d clients, %d bytes %s, %d sessions and %d service accounts revoked",
+		userID, adminID, result.Clients, result.Size, action, result.SessionsRevoked, result.ServiceAccountsDeleted)
+
+	record := &models.UserOffboardingRecord{Time: time.Now(), AdminID: adminID, Reason: req.Reason, UserOffboardResult: *result}
+	if err := s.auditRepo.Append(record); err != nil {
+		s.log.Error("Failed to record offboarding of user %s: %v", userID, err)
+	}
+	return result, nil
+}
+
+// removeData stops and removes the user's clients, then deletes or archives the user's
+// data directories.
+func (s *UserOffboardingService) removeData(userID string, archive bool, result *models.UserOffboardResult) error {
+	size, err := s.quotaRepo.CalculateUsage(userID)
+	if err != nil {
+		return fmt.Errorf("failed to calculate usage: %w", err)
+	}
+	result.Size = size
+
+	clients, err := s.clientRepo.GetByUserID(userID)
+	if err != nil {
+		return fmt.Errorf("failed to list clients: %w", err)
+	}
+	result.Clients = len(clients)
+
+	for _, client := range clients {
+		err := s.clientService.Stop(client.ID)
+		var appErr *apperrors.AppError
+		switch {
+		case err == nil:
+			result.ClientsStopped++
+		case errors.As(err, &appErr) && appErr.Code == apperrors.CodeClientNotRunning:
+		default:
+			return fmt.Errorf("failed to stop client %s: %w", client.ID, err)
+		}
+
+		if !archive {
+			if err := s.clientService.Delete(client.ID); err != nil {
+				return fmt.Errorf("failed to delete client %s: %w", client.ID, err)
+			}
+		}
+	}
+
+	archiveName := fmt.Sprintf("%s-%s", userID, time.Now().Format("20060102T150405"))
+	for _, root := range s.roots() {
+		userDir := filepath.Join(root, "users", userID)
+		if _, err := os.Stat(userDir); err != nil {
+			continue
+		}
+
+		if !archive {
+			if err := os.RemoveAll(userDir); err != nil {
+				return fmt.Errorf("failed to delete user directory: %w", err)
+			}
+			continue
+		}
+
+		archiveDir := filepath.Join(root, offboardingArchiveDir, "users", archiveName)
+		if err := os.MkdirAll(filepath.Dir(archiveDir), 0755); err != nil {
+			return fmt.Errorf("failed to create archive directory: %w", err)
+		}
+		if err := os.Rename(userDir, archiveDir); err != nil {
+			return fmt.Errorf("failed to archive user directory: %w", err)
+		}
+		result.ArchivePath = filepath.Join(offboardingArchiveDir, "users", archiveName)
+	}
+	return nil
+}
+
+// roots returns the data directories holding user directories.
+func (s *UserOffboardingService) roots() []string {
+	if s.coldDir == "" {
+		return []string{s.dataDir}
+	}
+	return []string{s.dataDir, s.coldDir}
+}
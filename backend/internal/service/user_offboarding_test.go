@@ -0,0 +1,110 @@
+package service_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("UserOffboardingService", func() {
+	var (
+		baseDir               string
+		clientService         *service.ClientService
+		sessionService        *service.SessionService
+		serviceAccountService *service.ServiceAccountService
+		offboardingService    *service.UserOffboardingService
+	)
+
+	BeforeEach(func() {
+		baseDir = GinkgoT().TempDir()
+		log := logger.New()
+		clientRepo, err := repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		quotaRepo := repository.NewFileQuotaRepository(baseDir, 10*1024*1024, 10)
+		clientService = service.NewClientService(
+			clientRepo,
+			quotaRepo,
+			repository.NewFileEventRepository(baseDir),
+			service.NewProcessService(false, 0, time.Minute, log),
+			service.NewJobService(time.Hour, log),
+			baseDir,
+			log,
+		)
+		sessionService = service.NewSessionService(time.Hour)
+		accountRepo, err := repository.NewFileServiceAccountRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		serviceAccountService = service.NewServiceAccountService(accountRepo, log)
+		offboardingService = service.NewUserOffboardingService(baseDir, clientService, clientRepo, quotaRepo,
+			sessionService, serviceAccountService, repository.NewFileOffboardingRepository(baseDir), log)
+
+		for _, userID := range []string{"alice", "bob"} {
+			_, err := clientService.Create(userID, &models.ClientRequest{
+				Name:      "relay",
+				SmeeURL:   "https://smee.example.com/channel",
+				TargetURL: "http://target.example.com/hook",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = sessionService.CreateSession(userID, userID+"@example.com", nil, "127.0.0.1", "test")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = serviceAccountService.Create("admin", &models.ServiceAccountRequest{Name: "ci", UserID: userID, Scopes: []string{models.ScopeEventsRead}})
+			Expect(err).NotTo(HaveOccurred())
+		}
+	})
+
+	It("deletes the user's clients and data and revokes sessions and service accounts", func() {
+		result, err := offboardingService.Offboard("admin", "alice", &models.UserOffboardRequest{Reason: "left the company"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Clients).To(Equal(1))
+		Expect(result.Archived).To(BeFalse())
+		Expect(result.SessionsRevoked).To(Equal(1))
+		Expect(result.ServiceAccountsDeleted).To(Equal(1))
+
+		Expect(filepath.Join(baseDir, "users", "alice")).NotTo(BeADirectory())
+		Expect(sessionService.ListSessions("alice", "").Sessions).To(BeEmpty())
+		accounts, err := serviceAccountService.List()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(accounts.ServiceAccounts).To(HaveLen(1))
+		Expect(accounts.ServiceAccounts[0].UserID).To(Equal("bob"))
+
+		// Other users are untouched
+		clients, err := clientService.UserClientIDs("bob")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(clients).To(HaveLen(1))
+
+		audit, err := os.ReadFile(filepath.Join(baseDir, "offboarding_audit.jsonl"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(audit)).To(ContainSubstring(`"userId":"alice"`))
+		Expect(string(audit)).To(ContainSubstring(`"reason":"left the company"`))
+
+		_, err = offboardingService.Offboard("admin", "alice", &models.UserOffboardRequest{})
+		Expect(err).To(MatchError(apperrors.ErrUserNotFound))
+	})
+
+	It("archives the user's data outside the users directory", func() {
+		result, err := offboardingService.Offboard("admin", "alice", &models.UserOffboardRequest{Archive: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Archived).To(BeTrue())
+		Expect(result.ArchivePath).To(HavePrefix(filepath.Join("archive", "users", "alice-")))
+
+		Expect(filepath.Join(baseDir, "users", "alice")).NotTo(BeADirectory())
+		Expect(filepath.Join(baseDir, result.ArchivePath, "clients")).To(BeADirectory())
+		clients, err := clientService.UserClientIDs("alice")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(clients).To(BeEmpty())
+	})
+
+	It("refuses to offboard the calling administrator", func() {
+		_, err := offboardingService.Offboard("alice", "alice", &models.UserOffboardRequest{})
+		Expect(err).To(MatchError(ContainSubstring("cannot offboard themselves")))
+		Expect(filepath.Join(baseDir, "users", "alice")).To(BeADirectory())
+	})
+})
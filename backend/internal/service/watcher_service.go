@@ -0,0 +1,614 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	apperrors "github.com/lazycatapps/gosmee/backend/internal/pkg/errors"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+)
+
+// eventStreamBuffer is how many new events a slow stream subscriber may fall behind
+// before events are dropped for it.
+const eventStreamBuffer = 64
+
+// WatcherService watches the events directories of running clients and reacts to the event
+// files gosmee writes as soon as they appear: new events are announced to stream subscribers,
+// forwarded if the backend forwards for the client, oversized payloads and event rates are
+// limited, the user's storage quota is refreshed and enforced, and today's statistics are
+// rolled up. Clients running in the backend process hand their events over directly (see
+// Ingest).
+type WatcherService struct {
+	eventRepo    repository.EventRepository
+	quotaService *QuotaService
+	log          logger.Logger
+
+	statsService        *StatsService                            // Rolls up statistics of new events (optional)
+	notificationService *NotificationService                     // Notifies users about stopped clients (optional)
+	stopClient          func(clientID string) error              // Stops clients exceeding the storage quota (optional)
+	forward             func(clientID string, eventIDs []string) // Forwards new events (optional)
+	payloadLimiter      *PayloadLimiter                          // Limits stored payload sizes (optional)
+	ingestionLimiter    *IngestionLimiter                        // Limits the rate of new events (optional)
+	deliveries          *DeliveryIndex                           // Indexes new events by delivery ID (optional)
+	counters            *DeliveryCounters                        // Counts new events and gosmee's deliveries (optional)
+	masker              MaskerFunc                               // Masks secrets in payload previews (optional)
+	sampler             *EventSampler                            // Discards events of sampled clients (optional)
+
+	watcher   *fsnotify.Watcher
+	mu        sync.Mutex
+	clients   map[string]*watchedClient                     // clientID -> watch state
+	dirs      map[string]string                             // watched directory -> clientID
+	listeners map[string]map[chan *models.EventSummary]bool // clientID -> stream subscribers
+}
+
+// watchedClient is the watch state of a running client.
+type watchedClient struct {
+	userID     string
+	eventsDir  string
+	known      map[string]bool // IDs of the events stored in the directory
+	pending    map[string]bool // IDs of events changed since the last processing
+	processing map[string]bool // IDs of events being processed, not forwarded yet
+	timer      *time.Timer     // Processes pending events once they settled
+	sampling   *models.SamplingPolicy
+	schemas    *PayloadSchemas   // Validate the payloads of new events (nil if the client has none)
+	ingested   []*receivedEvents // Ingested events waiting to be delivered, oldest first
+	delivering bool              // Ingested events are being delivered
+}
+
+// receivedEvents are new events of a client announced to stream subscribers, to be delivered.
+type receivedEvents struct {
+	userID    string
+	sampling  *models.SamplingPolicy
+	summaries []*models.EventSummary
+	oversized []string // IDs of events over the payload size limit, enforced once forwarded
+}
+
+// NewWatcherService creates a new watcher service.
+func NewWatcherService(eventRepo repository.EventRepository, quotaService *QuotaService, log logger.Logger) (*WatcherService, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file watcher: %w", err)
+	}
+
+	s := &WatcherService{
+		eventRepo:    eventRepo,
+		quotaService: quotaService,
+		log:          log,
+		watcher:      watcher,
+		clients:      make(map[string]*watchedClient),
+		dirs:         make(map[string]string),
+		listeners:    make(map[string]map[chan *models.EventSummary]bool),
+	}
+	go s.run()
+
+	return s, nil
+}
+
+// SetStatsService rolls up today's statistics of a client when it received new events.
+func (s *WatcherService) SetStatsService(statsService *StatsService) {
+	s.statsService = statsService
+}
+
+// SetQuotaEnforcement stops clients whose user exceeded the storage quota with new events,
+// and notifies the user.
+func (s *WatcherService) SetQuotaEnforcement(notificationService *NotificationService, stopClient func(clientID string) error) {
+	s.notificationService = notificationService
+	s.stopClient = stopClient
+}
+
+// SetForwarder forwards the new events of clients whose events are not forwarded by gosmee.
+// forward receives the event IDs in arrival order and decides whether the client needs it.
+func (s *WatcherService) SetForwarder(forward func(clientID string, eventIDs []string)) {
+	s.forward = forward
+}
+
+// SetPayloadLimiter limits the payload size of new events. Oversized events are rejected
+// before they are announced, or truncated after they were forwarded.
+func (s *WatcherService) SetPayloadLimiter(payloadLimiter *PayloadLimiter) {
+	s.payloadLimiter = payloadLimiter
+}
+
+// SetIngestionLimiter drops or holds back new events over the per-minute event limits.
+func (s *WatcherService) SetIngestionLimiter(ingestionLimiter *IngestionLimiter) {
+	s.ingestionLimiter = ingestionLimiter
+}
+
+// SetDeliveryIndex indexes new events by the delivery ID of the webhook provider.
+func (s *WatcherService) SetDeliveryIndex(deliveries *DeliveryIndex) {
+	s.deliveries = deliveries
+}
+
+// SetDeliveryCounters counts new events, and the deliveries gosmee made before storing
+// them, by event type for metrics.
+func (s *WatcherService) SetDeliveryCounters(counters *DeliveryCounters) {
+	s.counters = counters
+}
+
+// SetMasker sets how secrets are masked in the payload previews of announced events.
+func (s *WatcherService) SetMasker(masker MaskerFunc) {
+	s.masker = masker
+}
+
+// SetSampler discards the new events the sampling policy of their client doesn't keep.
+func (s *WatcherService) SetSampler(sampler *EventSampler) {
+	s.sampler = sampler
+}
+
+// Close stops watching all directories.
+func (s *WatcherService) Close() error {
+	return s.watcher.Close()
+}
+
+// Watch starts watching the events directory of a client. Events already stored in the
+// directory are not reported as new. Watching a client twice has no effect.
+func (s *WatcherService) Watch(client *models.Client, eventsDir string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.clients[client.ID]; exists {
+		return
+	}
+	if err := os.MkdirAll(eventsDir, 0755); err != nil {
+		s.log.Error("Failed to create events directory of client %s: %v", client.ID, err)
+		return
+	}
+
+	watched := &watchedClient{
+		userID:     client.UserID,
+		eventsDir:  eventsDir,
+		known:      make(map[string]bool),
+		pending:    make(map[string]bool),
+		processing: make(map[string]bool),
+		sampling:   client.Sampling,
+	}
+	s.clients[client.ID] = watched
+	if schemas, err := CompilePayloadSchemas(client.PayloadSchema); err != nil {
+		s.log.Error("Failed to compile payload schemas of client %s: %v", client.ID, err)
+	} else {
+		watched.schemas = schemas
+	}
+
+	s.addDir(client.ID, eventsDir)
+	entries, err := os.ReadDir(eventsDir)
+	if err != nil {
+		s.log.Error("Failed to read events directory of client %s: %v", client.ID, err)
+		return
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			if eventID, ok := strings.CutSuffix(entry.Name(), ".json"); ok {
+				watched.known[eventID] = true
+			}
+			continue
+		}
+		dir := filepath.Join(eventsDir, entry.Name())
+		s.addDir(client.ID, dir)
+		files, _ := os.ReadDir(dir)
+		for _, file := range files {
+			if eventID, ok := strings.CutSuffix(file.Name(), ".json"); ok {
+				watched.known[eventID] = true
+			}
+		}
+	}
+}
+
+// Unwatch stops watching the events directory of a client.
+func (s *WatcherService) Unwatch(clientID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	watched, exists := s.clients[clientID]
+	if !exists {
+		return
+	}
+	if watched.timer != nil {
+		watched.timer.Stop()
+	}
+	for dir, owner := range s.dirs {
+		if owner == clientID {
+			s.watcher.Remove(dir)
+			delete(s.dirs, dir)
+		}
+	}
+	delete(s.clients, clientID)
+}
+
+// Subscribe returns a channel receiving the summaries of a client's new events, and a
+// function ending the subscription.
+func (s *WatcherService) Subscribe(clientID string) (<-chan *models.EventSummary, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch := make(chan *models.EventSummary, eventStreamBuffer)
+	if s.listeners[clientID] == nil {
+		s.listeners[clientID] = make(map[chan *models.EventSummary]bool)
+	}
+	s.listeners[clientID][ch] = true
+
+	return ch, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		delete(s.listeners[clientID], ch)
+		if len(s.listeners[clientID]) == 0 {
+			delete(s.listeners, clientID)
+		}
+	}
+}
+
+// addDir watches a directory of a client. The caller must hold s.mu.
+func (s *WatcherService) addDir(clientID, dir string) {
+	if err := s.watcher.Add(dir); err != nil {
+		s.log.Error("Failed to watch %s: %v", dir, err)
+		return
+	}
+	s.dirs[dir] = clientID
+}
+
+// run dispatches file system notifications until the watcher is closed.
+func (s *WatcherService) run() {
+	for {
+		select {
+		case event, ok := <-s.watcher.Events:
+			if !ok {
+				return
+			}
+			s.handle(event)
+		case err, ok := <-s.watcher.Errors:
+			if !ok {
+				return
+			}
+			s.log.Error("Event directory watcher error: %v", err)
+		}
+	}
+}
+
+// handle records a change in a watched directory. Event files and replay scripts schedule
+// the processing of their event; new date directories (YYYY-MM-DD) are watched as well.
+func (s *WatcherService) handle(event fsnotify.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clientID, ok := s.dirs[filepath.Dir(event.Name)]
+	if !ok {
+		return
+	}
+	watched := s.clients[clientID]
+	name := filepath.Base(event.Name)
+
+	if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+		// Deleted, uploaded or moved to cold storage
+		if eventID, ok := strings.CutSuffix(name, ".json"); ok {
+			delete(watched.known, eventID)
+		}
+		return
+	}
+	if !event.Has(fsnotify.Create) && !event.Has(fsnotify.Write) {
+		return
+	}
+
+	if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+		// Files may have been created before the directory was watched
+		s.addDir(clientID, event.Name)
+		files, _ := os.ReadDir(event.Name)
+		for _, file := range files {
+			if eventID, ok := strings.CutSuffix(file.Name(), ".json"); ok {
+				s.schedule(clientID, watched, eventID)
+			}
+		}
+		return
+	}
+
+	for _, suffix := range []string{".json", ".sh"} {
+		if eventID, ok := strings.CutSuffix(name, suffix); ok {
+			s.schedule(clientID, watched, eventID)
+		}
+	}
+}
+
+// schedule queues an event for processing once gosmee finished writing it.
+// The caller must hold s.mu.
+func (s *WatcherService) schedule(clientID string, watched *watchedClient, eventID string) {
+	if watched.known[eventID] {
+		return // Rewritten (e.g. redacted or encrypted), not new
+	}
+	watched.pending[eventID] = true
+	if watched.timer == nil {
+		watched.timer = time.AfterFunc(eventSettleTime, func() { s.process(clientID) })
+	}
+}
+
+// Processing reports whether a new event of a client was not processed yet: it is settling,
+// held back by the ingestion limit or being forwarded.
+func (s *WatcherService) Processing(clientID, eventID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	watched, exists := s.clients[clientID]
+	return exists && (watched.pending[eventID] || watched.processing[eventID])
+}
+
+// processed records that the processing of events of a client finished.
+func (s *WatcherService) processed(clientID string, eventIDs []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if watched, exists := s.clients[clientID]; exists {
+		for _, eventID := range eventIDs {
+			delete(watched.processing, eventID)
+		}
+	}
+}
+
+// requeue processes events held back by the ingestion limit again once the delay passed.
+func (s *WatcherService) requeue(clientID string, eventIDs []string, delay time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	watched, exists := s.clients[clientID]
+	if !exists {
+		return
+	}
+	for _, eventID := range eventIDs {
+		watched.pending[eventID] = true
+	}
+	if watched.timer == nil {
+		watched.timer = time.AfterFunc(delay, func() { s.process(clientID) })
+	}
+}
+
+// Ingest saves an event a client running in the backend process received and processes it
+// right away like the events gosmee writes, without waiting for a file notification: with
+// object storage, saved events never appear in the watched directory. The event is announced
+// before Ingest returns; it is forwarded in the background, so a slow target doesn't hold up
+// the client's event stream.
+func (s *WatcherService) Ingest(clientID string, event *models.Event) error {
+	s.mu.Lock()
+	watched, exists := s.clients[clientID]
+	if exists {
+		watched.known[event.ID] = true // Not scheduled again by the notification of its file
+		watched.processing[event.ID] = true
+	}
+	s.mu.Unlock()
+	if !exists {
+		return fmt.Errorf("client %s is not watched", clientID)
+	}
+
+	if err := s.eventRepo.Save(clientID, event); err != nil {
+		s.processed(clientID, []string{event.ID})
+		return err
+	}
+	received := s.receive(clientID, []*models.Event{event})
+	if received == nil {
+		s.processed(clientID, []string{event.ID})
+		return nil
+	}
+
+	s.mu.Lock()
+	watched.ingested = append(watched.ingested, received)
+	if !watched.delivering {
+		watched.delivering = true
+		go s.deliverIngested(clientID, watched)
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// deliverIngested delivers the ingested events of a client in order, until none are left.
+func (s *WatcherService) deliverIngested(clientID string, watched *watchedClient) {
+	for {
+		s.mu.Lock()
+		if len(watched.ingested) == 0 {
+			watched.delivering = false
+			s.mu.Unlock()
+			return
+		}
+		received := watched.ingested[0]
+		watched.ingested = watched.ingested[1:]
+		s.mu.Unlock()
+
+		s.deliver(clientID, received)
+		eventIDs := make([]string, len(received.summaries))
+		for i, summary := range received.summaries {
+			eventIDs[i] = summary.ID
+		}
+		s.processed(clientID, eventIDs)
+	}
+}
+
+// process handles the pending new events of a client.
+func (s *WatcherService) process(clientID string) {
+	s.mu.Lock()
+	watched, exists := s.clients[clientID]
+	if !exists {
+		s.mu.Unlock()
+		return
+	}
+	pending := make([]string, 0, len(watched.pending))
+	for eventID := range watched.pending {
+		pending = append(pending, eventID)
+		watched.processing[eventID] = true
+	}
+	watched.pending = make(map[string]bool)
+	watched.timer = nil
+	s.mu.Unlock()
+	defer s.processed(clientID, pending)
+
+	var events []*models.Event
+	for _, eventID := range pending {
+		event, err := s.eventRepo.Get(clientID, eventID)
+		if err != nil {
+			continue // Removed before it settled
+		}
+		events = append(events, event)
+	}
+	if received := s.receive(clientID, events); received != nil {
+		s.deliver(clientID, received)
+	}
+}
+
+// receive checks new stored events of a watched client against the ingestion limit, the
+// sampling policy, the payload size limit and the payload schemas, and announces those kept
+// to stream subscribers. It returns nil if no event is kept.
+func (s *WatcherService) receive(clientID string, events []*models.Event) *receivedEvents {
+	s.mu.Lock()
+	watched, exists := s.clients[clientID]
+	if !exists {
+		s.mu.Unlock()
+		return nil
+	}
+	userID := watched.userID
+	sampling := watched.sampling
+	schemas := watched.schemas
+	if s.sampler == nil {
+		sampling = nil
+	}
+	s.mu.Unlock()
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Timestamp.Before(events[j].Timestamp)
+	})
+
+	var summaries []*models.EventSummary
+	var oversized, queued []string
+	for _, event := range events {
+		eventID := event.ID
+		if s.ingestionLimiter != nil && !s.ingestionLimiter.Allow(clientID) {
+			if s.ingestionLimiter.Queues(len(queued)) {
+				queued = append(queued, eventID)
+			} else {
+				s.ingestionLimiter.Drop(userID, clientID, eventID)
+			}
+			continue
+		}
+		if sampling != nil && !s.sampler.Keep(clientID, sampling, event) {
+			s.sampler.Discard(clientID, eventID)
+			continue
+		}
+		if s.payloadLimiter != nil && s.payloadLimiter.Exceeds(event.Payload) {
+			if s.payloadLimiter.Rejects(event.Payload) && !s.payloadLimiter.Enforce(userID, clientID, eventID) {
+				continue
+			}
+			oversized = append(oversized, eventID)
+		}
+		if schemas != nil && schemas.Check(event) {
+			if err := s.eventRepo.Save(clientID, event); err != nil {
+				s.log.Error("Failed to record schema validation of event %s: %v", eventID, err)
+			}
+		}
+		summaries = append(summaries, event.ToSummary())
+	}
+	if len(queued) > 0 {
+		s.requeue(clientID, queued, s.ingestionLimiter.RetryAfter())
+	}
+	if len(summaries) == 0 {
+		return nil
+	}
+	if s.masker != nil {
+		masker := s.masker(userID)
+		for _, summary := range summaries {
+			summary.Preview = masker.MaskText(summary.Preview)
+		}
+	}
+
+	s.mu.Lock()
+	for _, summary := range summaries {
+		watched.known[summary.ID] = true
+	}
+	for ch := range s.listeners[clientID] {
+		for _, summary := range summaries {
+			select {
+			case ch <- summary:
+			default: // Subscriber is too slow, drop the event
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	s.log.Debug("Client %s received %d new events", clientID, len(summaries))
+
+	return &receivedEvents{userID: userID, sampling: sampling, summaries: summaries, oversized: oversized}
+}
+
+// deliver indexes and counts received events, forwards them, enforces the payload size limit
+// and the storage quota, and rolls up today's statistics.
+func (s *WatcherService) deliver(clientID string, received *receivedEvents) {
+	userID, sampling, summaries := received.userID, received.sampling, received.summaries
+
+	if s.deliveries != nil {
+		for _, summary := range summaries {
+			s.deliveries.Add(clientID, summary.ID, summary.DeliveryID)
+		}
+	}
+
+	if s.counters != nil {
+		for _, summary := range summaries {
+			s.counters.Received(clientID, summary.EventType)
+			switch summary.Status {
+			case models.EventStatusSuccess, models.EventStatusFailed:
+				// Forwarded by gosmee
+				s.counters.Delivered(clientID, summary.EventType, summary.Status == models.EventStatusSuccess)
+			}
+		}
+	}
+
+	if s.forward != nil {
+		eventIDs := make([]string, len(summaries))
+		for i, summary := range summaries {
+			eventIDs[i] = summary.ID
+		}
+		s.forward(clientID, eventIDs)
+		if sampling != nil && sampling.Mode == models.SamplingModeFailures {
+			s.sampler.DiscardDelivered(clientID, eventIDs)
+		}
+	}
+	for _, eventID := range received.oversized {
+		s.payloadLimiter.Enforce(userID, clientID, eventID)
+	}
+	if s.statsService != nil {
+		if err := s.statsService.RollupClient(clientID); err != nil {
+			s.log.Error("Failed to roll up stats of client %s: %v", clientID, err)
+		}
+	}
+	s.enforceQuota(clientID, userID)
+}
+
+// enforceQuota refreshes the storage usage of a user and stops the client if it exceeded
+// the quota.
+func (s *WatcherService) enforceQuota(clientID, userID string) {
+	s.quotaService.Refresh(userID)
+	if s.stopClient == nil {
+		return
+	}
+
+	err := s.quotaService.CheckStorageQuota(userID)
+	var appErr *apperrors.AppError
+	if err == nil || !errors.As(err, &appErr) || appErr.Code != apperrors.CodeQuotaExceeded {
+		if err != nil {
+			s.log.Error("Failed to check storage quota of user %s: %v", userID, err)
+		}
+		return
+	}
+
+	if err := s.stopClient(clientID); err != nil {
+		s.log.Error("Failed to stop client %s exceeding the storage quota: %v", clientID, err)
+		return
+	}
+	s.log.Info("Stopped client %s: %s", clientID, appErr.Message)
+	if s.notificationService != nil {
+		s.notificationService.Notify(userID, clientID, "quota_exceeded", models.NotificationLevelError,
+			fmt.Sprintf("Client stopped: %s. Delete old events or logs before starting it again", appErr.Message))
+	}
+}
@@ -0,0 +1,117 @@
+package service_test
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("WatcherService", func() {
+	var (
+		eventsDir           string
+		client              *models.Client
+		watcherService      *service.WatcherService
+		notificationService *service.NotificationService
+
+		stoppedMu sync.Mutex
+		stopped   []string
+	)
+
+	BeforeEach(func() {
+		baseDir := GinkgoT().TempDir()
+		log := logger.New()
+		client = &models.Client{ID: "client-watch", UserID: "user-watch", Name: "Watched"}
+		eventsDir = filepath.Join(baseDir, "users", client.UserID, "clients", client.ID, "events")
+		Expect(os.MkdirAll(eventsDir, 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(eventsDir, "old.json"), []byte(`{"ref":"old"}`), 0644)).To(Succeed())
+
+		quotaService := service.NewQuotaService(repository.NewFileQuotaRepository(baseDir, 1000, 10), repository.NewFileQuotaHistoryRepository(baseDir), log)
+		notificationService = service.NewNotificationService(log)
+
+		var err error
+		watcherService, err = service.NewWatcherService(repository.NewFileEventRepository(baseDir), quotaService, log)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(watcherService.Close)
+
+		stopped = nil
+		watcherService.SetQuotaEnforcement(notificationService, func(clientID string) error {
+			stoppedMu.Lock()
+			defer stoppedMu.Unlock()
+			stopped = append(stopped, clientID)
+			return nil
+		})
+		watcherService.Watch(client, eventsDir)
+	})
+
+	stoppedClients := func() []string {
+		stoppedMu.Lock()
+		defer stoppedMu.Unlock()
+		return append([]string{}, stopped...)
+	}
+
+	It("announces new events and ignores rewritten ones", func() {
+		events, cancel := watcherService.Subscribe(client.ID)
+		defer cancel()
+
+		// Existing events are rewritten, e.g. when they are encrypted
+		Expect(os.WriteFile(filepath.Join(eventsDir, "old.json"), []byte(`{"ref":"rewritten"}`), 0644)).To(Succeed())
+
+		dateDir := filepath.Join(eventsDir, "2025-10-01")
+		Expect(os.MkdirAll(dateDir, 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dateDir, "2025-10-01T12.00.00.000.json"), []byte(`{"ref":"main"}`), 0644)).To(Succeed())
+
+		var summary *models.EventSummary
+		Eventually(events, 10*time.Second).Should(Receive(&summary))
+		Expect(summary.ID).To(Equal("2025-10-01T12.00.00.000"))
+		Consistently(events, 3*time.Second).ShouldNot(Receive())
+		Expect(stoppedClients()).To(BeEmpty())
+	})
+
+	It("stops the client when new events exceed the storage quota", func() {
+		Expect(os.WriteFile(filepath.Join(eventsDir, "big.json"), make([]byte, 2000), 0644)).To(Succeed())
+
+		Eventually(stoppedClients, 10*time.Second).Should(Equal([]string{client.ID}))
+		list := notificationService.List(client.UserID, false)
+		Expect(list.Notifications).To(HaveLen(1))
+		Expect(list.Notifications[0].Type).To(Equal("quota_exceeded"))
+	})
+
+	It("forwards ingested events in the background, in order", func() {
+		forwarded := make(chan []string, 10)
+		release := make(chan struct{})
+		watcherService.SetForwarder(func(clientID string, eventIDs []string) {
+			forwarded <- eventIDs
+			<-release
+		})
+		events, cancel := watcherService.Subscribe(client.ID)
+		defer cancel()
+
+		ingest := func(eventID string) {
+			event := &models.Event{ID: eventID, ClientID: client.ID, Timestamp: time.Now().UTC(), Payload: `{"ref":"main"}`}
+			done := make(chan error, 1)
+			go func() { done <- watcherService.Ingest(client.ID, event) }()
+			Eventually(done, time.Second).Should(Receive(BeNil()))
+			Eventually(events, time.Second).Should(Receive(HaveField("ID", eventID)))
+		}
+
+		// The target of the first event doesn't answer
+		ingest("evt-1")
+		Eventually(forwarded, time.Second).Should(Receive(Equal([]string{"evt-1"})))
+		ingest("evt-2")
+		Consistently(forwarded, 200*time.Millisecond).ShouldNot(Receive())
+		Expect(watcherService.Processing(client.ID, "evt-2")).To(BeTrue())
+
+		close(release)
+		Eventually(forwarded, time.Second).Should(Receive(Equal([]string{"evt-2"})))
+		Eventually(func() bool { return watcherService.Processing(client.ID, "evt-2") }).Should(BeFalse())
+	})
+})
@@ -0,0 +1,73 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package service
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+)
+
+// genericEventHeaders are the event type headers of well-known providers, checked in order
+// for custom clients.
+var genericEventHeaders = []string{"X-GitHub-Event", "X-Gitlab-Event", "X-Event-Key", "X-Gitea-Event"}
+
+// detectEventType infers the type of an event from where the client's provider puts it: an
+// event type header, or a field of the JSON payload (e.g. Stripe's "type"). Events of custom
+// clients are recognized by the headers of well-known providers.
+func detectEventType(client *models.Client, headers map[string]string, payload string) string {
+	provider := client.WebhookProvider()
+	switch {
+	case provider.EventHeader != "":
+		return headerValue(headers, provider.EventHeader)
+	case provider.EventField != "":
+		var fields map[string]interface{}
+		if err := json.Unmarshal([]byte(payload), &fields); err != nil {
+			return ""
+		}
+		eventType, _ := fields[provider.EventField].(string)
+		return eventType
+	}
+
+	for _, name := range genericEventHeaders {
+		if value := headerValue(headers, name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// withoutSignatures returns a copy of an event without the signature headers of the client's
+// provider. Stored payloads of clients with redaction rules no longer match the signature
+// the provider sent, and a replayed request is better unsigned than looking tampered with.
+func withoutSignatures(client *models.Client, event *models.Event) *models.Event {
+	signatureHeaders := client.WebhookProvider().SignatureHeaders
+	if len(signatureHeaders) == 0 {
+		return event
+	}
+
+	stripped := *event
+	stripped.Headers = make(map[string]string, len(event.Headers))
+	for key, value := range event.Headers {
+		signature := false
+		for _, name := range signatureHeaders {
+			signature = signature || strings.EqualFold(key, name)
+		}
+		if !signature {
+			stripped.Headers[key] = value
+		}
+	}
+	return &stripped
+}
+
+// headerValue returns the value of a header, matching its name case-insensitively.
+func headerValue(headers map[string]string, name string) string {
+	for key, value := range headers {
+		if strings.EqualFold(key, name) && value != "" {
+			return value
+		}
+	}
+	return ""
+}
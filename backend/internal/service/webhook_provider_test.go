@@ -0,0 +1,92 @@
+package service_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazycatapps/gosmee/backend/internal/models"
+	"github.com/lazycatapps/gosmee/backend/internal/pkg/logger"
+	"github.com/lazycatapps/gosmee/backend/internal/repository"
+	"github.com/lazycatapps/gosmee/backend/internal/service"
+)
+
+var _ = Describe("Webhook providers", func() {
+	var (
+		clientRepo   *repository.FileClientRepository
+		eventRepo    *repository.FileEventRepository
+		eventService *service.EventService
+	)
+
+	BeforeEach(func() {
+		baseDir := GinkgoT().TempDir()
+		log := logger.New()
+		var err error
+		clientRepo, err = repository.NewFileClientRepository(baseDir)
+		Expect(err).NotTo(HaveOccurred())
+		eventRepo = repository.NewFileEventRepository(baseDir)
+		eventService = service.NewEventService(
+			eventRepo,
+			clientRepo,
+			service.NewJobService(time.Hour, log),
+			service.NewCircuitBreakerService(0, time.Minute, service.NewNotificationService(log), log),
+			log,
+		)
+	})
+
+	It("reads the event type where the provider puts it", func() {
+		stripe := &models.Client{ID: "client-stripe", UserID: "user", Provider: models.ProviderStripe, TargetURL: "http://127.0.0.1:1"}
+		gitlab := &models.Client{ID: "client-gitlab", UserID: "user", Provider: models.ProviderGitLab, TargetURL: "http://127.0.0.1:1"}
+		custom := &models.Client{ID: "client-custom", UserID: "user", TargetURL: "http://127.0.0.1:1"}
+		for _, client := range []*models.Client{stripe, gitlab, custom} {
+			Expect(clientRepo.Create(client)).To(Succeed())
+		}
+		headers := map[string]string{"X-GitHub-Event": "push", "X-Gitlab-Event": "Push Hook"}
+
+		response, err := eventService.Inject(stripe.ID, &models.EventInjectRequest{Payload: []byte(`{"type":"invoice.paid"}`), Headers: headers})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.Event.EventType).To(Equal("invoice.paid"))
+
+		response, err = eventService.Inject(gitlab.ID, &models.EventInjectRequest{Payload: []byte(`{}`), Headers: headers})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.Event.EventType).To(Equal("Push Hook"))
+
+		response, err = eventService.Inject(custom.ID, &models.EventInjectRequest{Payload: []byte(`{}`), Headers: headers})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.Event.EventType).To(Equal("push"))
+
+		Expect(eventRepo.Save(stripe.ID, &models.Event{ID: "gosmee", ClientID: stripe.ID, Payload: `{"type":"charge.refunded"}`})).To(Succeed())
+		event, err := eventService.Get(stripe.ID, "gosmee")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(event.EventType).To(Equal("charge.refunded"))
+	})
+
+	It("drops signatures of redacted payloads on replay", func() {
+		var received []http.Header
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = append(received, r.Header.Clone())
+		}))
+		defer target.Close()
+
+		headers := map[string]string{"X-Hub-Signature-256": "sha256=abc", "X-GitHub-Delivery": "1"}
+		plain := &models.Client{ID: "client-plain", UserID: "user", Provider: models.ProviderGitHub, TargetURL: target.URL, TargetTimeout: 5}
+		redacted := &models.Client{
+			ID: "client-redacted", UserID: "user", Provider: models.ProviderGitHub, TargetURL: target.URL, TargetTimeout: 5,
+			RedactionRules: []models.RedactionRule{{Path: "$.token"}},
+		}
+		for _, client := range []*models.Client{plain, redacted} {
+			Expect(clientRepo.Create(client)).To(Succeed())
+			Expect(eventRepo.Save(client.ID, &models.Event{ID: "evt", ClientID: client.ID, Payload: `{}`, Headers: headers})).To(Succeed())
+			_, err := eventService.Replay(client.ID, &models.EventReplayRequest{EventIDs: []string{"evt"}})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Expect(received).To(HaveLen(2))
+		Expect(received[0].Get("X-Hub-Signature-256")).To(Equal("sha256=abc"))
+		Expect(received[1].Get("X-Hub-Signature-256")).To(BeEmpty())
+		Expect(received[1].Get("X-GitHub-Delivery")).To(Equal("1"))
+	})
+})
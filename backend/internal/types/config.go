@@ -17,16 +17,65 @@ type Config struct {
 type ServerConfig struct {
 	Host string // Server listening address (e.g., "0.0.0.0", "127.0.0.1")
 	Port int    // Server listening port (e.g., 8080)
+
+	Compression        bool // Compress responses with brotli/gzip (default: true)
+	CompressionMinSize int  // Minimum response size in bytes to compress (default: 1024)
+
+	MaxBodySize      int64 // Maximum request body size in bytes (default: 1MB, 0 = unlimited)
+	MaxEventBodySize int64 // Maximum body size in bytes for manual event injection (default: 25MB, 0 = unlimited)
+	MaxBundleSize    int64 // Maximum body size in bytes for client bundle uploads (default: 100MB, 0 = unlimited)
+
+	ServeFrontend bool // Serve the embedded frontend build (default: false)
 }
 
 // GosmeeConfig defines gosmee client management configuration.
 type GosmeeConfig struct {
     MaxClientsPerUser  int   // Maximum number of clients per user (default: 1000)
+	SoftClientsPerUser int   // Clients per user above which users are warned (default: 0 = no soft limit)
 	MaxStoragePerUser  int64 // Maximum storage per user in bytes (default: 10GB = 10737418240)
 	EventRetentionDays int   // Days to retain events (default: 30, 0 = forever)
 	LogRetentionDays   int   // Days to retain logs (default: 30, 0 = forever)
 	AutoRestart        bool  // Auto restart crashed clients (default: false)
-	MaxRestartAttempts int   // Maximum restart attempts (default: 3)
+	MaxRestartAttempts int   // Maximum restart attempts within the restart window (default: 3)
+	RestartWindow      int   // Sliding window of the restart budget in seconds (default: 600)
+
+	FailedRetentionDays int // Days to retain failed events, e.g. longer than the others (default: 0 = same as EventRetentionDays)
+
+	LogBufferLines      int // Log lines kept in memory per running client (default: 5000)
+	LastRunLogLines     int // Log lines kept in memory of a stopped client process (default: 200)
+	LastRunRetentionSec int // Seconds the log lines of a stopped client process are kept (default: 900, 0 = not kept)
+
+	StartupGraceSeconds int    // Seconds a started process must stay alive to count as started (default: 3)
+	StartupReadyPattern string // Regexp matching the log line of an established SSE connection (optional)
+	StartupReadyTimeout int    // Seconds to wait for StartupReadyPattern (default: 15)
+	MaxConcurrentStarts int    // Client processes starting up at the same time, further starts are queued (default: 10, 0 = unlimited)
+	MaxRunningClients   int    // Client processes running at the same time across all users (default: 0 = unlimited)
+	MaxRunningPerUser   int    // Client processes of a single user running at the same time (default: 0 = unlimited)
+	StopTimeoutSeconds  int    // Seconds a stopped process gets to exit after SIGTERM before it is killed (default: 5, 0 = kill right away)
+	StaleProcessPolicy  string // What happens to client processes left running by a previous server, "adopt" or "terminate" (default: "adopt")
+	ClientRuntime       string // How clients run, "exec" (gosmee processes) or "embedded" (in the server process) (default: "exec")
+
+	CircuitBreakerThreshold int // Consecutive delivery failures that open a client's circuit (default: 10, 0 = disabled)
+	CircuitBreakerCooldown  int // Seconds before an open circuit is probed again (default: 60)
+
+	ScriptReplayTimeout int // Seconds a replay script may run in script replay mode (default: 0 = disabled)
+
+	ResponseCaptureSize int // Target response bytes stored on events delivered by the backend (default: 65536, 0 = not stored)
+
+	MaxPayloadSize     int    // Maximum stored event payload size in bytes (default: 0 = unlimited)
+	PayloadLimitPolicy string // What happens to larger payloads, "truncate" or "reject" (default: "truncate")
+
+	MaxEventsPerMinutePerClient int    // New events stored per client and minute (default: 0 = unlimited)
+	MaxEventsPerMinute          int    // New events stored across all clients per minute (default: 0 = unlimited)
+	IngestionOverflow           string // What happens to events over the limits, "drop" or "queue" (default: "drop")
+	IngestionQueueSize          int    // Events per client held back with the "queue" policy before dropping (default: 1000)
+
+	MetricsMaxEventTypes int // Event types per client exported with their own metrics label, others count as "other" (default: 20)
+
+	QuotaAlertThresholds []int // Storage usage percentages that notify the user (default: 80, 95, 100; empty = disabled)
+
+	StorageWarningThreshold float64 // Storage usage percentage that triggers a warning (default: 80)
+	StorageFullThreshold    float64 // Storage usage percentage at which storage counts as full (default: 100)
 }
 
 // CORSConfig defines Cross-Origin Resource Sharing policy.
@@ -37,6 +86,28 @@ type CORSConfig struct {
 // StorageConfig defines storage configuration.
 type StorageConfig struct {
 	DataDir string // Base data directory for all user data (default: "/data")
+
+	ColdDataDir   string // Secondary data directory old events are moved to (empty = disabled)
+	ColdAfterDays int    // Days after which events are moved to ColdDataDir (default: 7)
+
+	DiskWarningFreePercent  float64 // Free space of a data volume in percent below which admins are warned (default: 10, 0 = disabled)
+	DiskCriticalFreePercent float64 // Free space of a data volume in percent rated critical (default: 5, 0 = disabled)
+
+	EncryptionKey     string // AES-256 key for encryption at rest, base64 or hex (empty = disabled)
+	EncryptionKeyFile string // File containing the encryption key, e.g. a mounted KMS secret
+
+	EventStorage string   // Event storage backend, "file" or "s3" (default: "file")
+	S3           S3Config // Object storage for events (EventStorage "s3")
+}
+
+// S3Config defines S3-compatible object storage configuration.
+type S3Config struct {
+	Endpoint  string // Endpoint URL (e.g., "https://s3.amazonaws.com", "http://minio:9000")
+	Region    string // Region used for request signing (default: "us-east-1")
+	Bucket    string // Bucket name
+	Prefix    string // Key prefix of all objects (optional, e.g. "gosmee/")
+	AccessKey string // Access key ID
+	SecretKey string // Secret access key
 }
 
 // OIDCConfig defines OIDC authentication configuration.
@@ -46,4 +117,21 @@ type OIDCConfig struct {
 	Issuer       string // OIDC issuer URL
 	RedirectURL  string // OIDC redirect URL after authentication
 	Enabled      bool   // Whether OIDC authentication is enabled
+
+	Scopes []string // Requested scopes (e.g. "openid", "profile", "email", "groups")
+	PKCE   bool     // Use PKCE in the authorization code flow (allows public clients without a secret)
+
+	Providers []OIDCProviderConfig // Additional identity providers (from --oidc-providers-file)
+}
+
+// OIDCProviderConfig defines an additional OIDC identity provider. The user IDs of its users
+// are prefixed with the provider ID ("<id>:<subject>"), as subjects are only unique per issuer.
+type OIDCProviderConfig struct {
+	ID           string   `json:"id"`           // Provider ID (lowercase letters, digits and dashes)
+	Name         string   `json:"name"`         // Display name on the login page
+	Issuer       string   `json:"issuer"`       // OIDC issuer URL
+	ClientID     string   `json:"clientId"`     // OIDC client ID
+	ClientSecret string   `json:"clientSecret"` // OIDC client secret (optional with PKCE)
+	Scopes       []string `json:"scopes"`       // Requested scopes (default: openid, profile, email, groups)
+	PKCE         bool     `json:"pkce"`         // Use PKCE in the authorization code flow
 }
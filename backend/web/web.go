@@ -0,0 +1,31 @@
+// Copyright (c) 2025 Lazycat Apps
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+// Package web embeds the built frontend, so the server can be deployed as a single binary.
+//
+// The dist directory is populated by `make build-single-bin`; a regular build embeds an
+// empty directory and Available reports false.
+package web
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed all:dist
+var dist embed.FS
+
+// FS returns the embedded frontend build, rooted at its index.html.
+func FS() fs.FS {
+	sub, err := fs.Sub(dist, "dist")
+	if err != nil {
+		panic(err) // The embedded directory always exists
+	}
+	return sub
+}
+
+// Available reports whether a frontend build was embedded.
+func Available() bool {
+	_, err := fs.Stat(FS(), "index.html")
+	return err == nil
+}
@@ -62,19 +62,35 @@ const API_PREFIX = (BACKEND_API_URL || '').replace(/\/$/, '');
 const MAX_LOG_LINES = 500;
 
 const CLIENT_STATUS_MAP = {
+  starting: { color: 'processing', label: '启动中…' },
   running: { color: 'success', label: '运行中' },
+  stopping: { color: 'warning', label: '停止中…' },
   stopped: { color: 'default', label: '已停止' },
   error: { color: 'error', label: '错误' },
 };
 
-const EVENT_IGNORE_OPTIONS = [
-  { label: 'push', value: 'push' },
-  { label: 'pull_request', value: 'pull_request' },
-  { label: 'issue_comment', value: 'issue_comment' },
-  { label: 'release', value: 'release' },
-  { label: 'workflow_run', value: 'workflow_run' },
-  { label: 'tag_push', value: 'tag_push' },
-];
+const CLIENT_ERROR_CATEGORY_MAP = {
+  binary_missing: '未安装 gosmee 可执行文件',
+  invalid_smee_url: 'Smee URL 无效或频道不存在',
+  smee_unreachable: '无法连接 Smee 服务器',
+  dns_error: '域名解析失败',
+  target_refused: '目标服务拒绝连接',
+  unknown: '未知错误',
+};
+
+const EVENT_PROVIDER_LABELS = {
+  github: 'GitHub',
+  gitlab: 'GitLab',
+  bitbucket: 'Bitbucket',
+  gitea: 'Gitea',
+  stripe: 'Stripe',
+  custom: '自定义',
+};
+
+const WEBHOOK_PROVIDER_OPTIONS = ['custom', 'github', 'gitlab', 'bitbucket', 'stripe'].map((value) => ({
+  label: EVENT_PROVIDER_LABELS[value],
+  value,
+}));
 
 const buildApiUrl = (path) => `${API_PREFIX}${path}`;
 
@@ -191,9 +207,41 @@ const copyToClipboard = async (text, messageApi) => {
   }
 };
 
+const VALIDATION_LEVEL_COLORS = { ok: 'green', warn: 'orange', fail: 'red' };
+
 function ClientFormModal({ open, onCancel, onSubmit, initialValues, loading }) {
   const [form] = Form.useForm();
   const isEditing = Boolean(initialValues?.id);
+  const [ignorePresets, setIgnorePresets] = useState({ presets: [], catalogs: {} });
+  const provider = Form.useWatch('provider', form) || 'custom';
+
+  useEffect(() => {
+    if (!open) {
+      return;
+    }
+    apiFetch(`/api/v1/clients/ignore-event-presets?provider=${provider}`)
+      .then((response) => (response.ok ? response.json() : null))
+      .then((data) => {
+        if (data) {
+          setIgnorePresets({ presets: data.presets || [], catalogs: data.catalogs || {} });
+        }
+      })
+      .catch(() => {});
+  }, [open, provider]);
+
+  const ignoreEventOptions = useMemo(
+    () =>
+      Object.entries(ignorePresets.catalogs).map(([provider, events]) => ({
+        label: EVENT_PROVIDER_LABELS[provider] || provider,
+        options: events.map((event) => ({ label: event, value: event })),
+      })),
+    [ignorePresets],
+  );
+
+  const applyIgnorePreset = (preset) => {
+    const current = form.getFieldValue('ignoreEvents') || [];
+    form.setFieldsValue({ ignoreEvents: Array.from(new Set([...current, ...preset.events])) });
+  };
 
   useEffect(() => {
     if (!open) {
@@ -202,37 +250,81 @@ function ClientFormModal({ open, onCancel, onSubmit, initialValues, loading }) {
     form.resetFields();
     form.setFieldsValue({
       name: initialValues?.name || '',
+      slug: initialValues?.slug || '',
       description: initialValues?.description || '',
+      runbook: initialValues?.runbook || '',
       smeeUrl: initialValues?.smeeUrl || '',
       targetUrl: initialValues?.targetUrl || '',
+      provider: initialValues?.provider || 'custom',
       targetTimeout: initialValues?.targetTimeout || 60,
       httpie: initialValues?.httpie ?? false,
       ignoreEvents: initialValues?.ignoreEvents || [],
       noReplay: initialValues?.noReplay ?? false,
       sseBufferSize: initialValues?.sseBufferSize || 1048576,
+      startImmediately: false,
     });
   }, [open, initialValues, form]);
 
+  const [validation, setValidation] = useState(null);
+  const [validating, setValidating] = useState(false);
+
+  useEffect(() => {
+    setValidation(null);
+  }, [open]);
+
+  const buildPayload = (values) => {
+    const payload = {
+      name: values.name.trim(),
+      slug: values.slug?.trim() || '',
+      description: values.description?.trim() || '',
+      runbook: values.runbook || '',
+      smeeUrl: values.smeeUrl.trim(),
+      targetUrl: values.targetUrl.trim(),
+      provider: values.provider,
+      targetTimeout: values.targetTimeout || 60,
+      httpie: values.httpie,
+      ignoreEvents: values.ignoreEvents || [],
+      noReplay: values.noReplay,
+      sseBufferSize: values.sseBufferSize || 1048576,
+    };
+    if (!isEditing) {
+      payload.startImmediately = values.startImmediately;
+    }
+    return payload;
+  };
+
   const handleSubmit = () => {
     form
       .validateFields()
-      .then((values) => {
-        const payload = {
-          name: values.name.trim(),
-          description: values.description?.trim() || '',
-          smeeUrl: values.smeeUrl.trim(),
-          targetUrl: values.targetUrl.trim(),
-          targetTimeout: values.targetTimeout || 60,
-          httpie: values.httpie,
-          ignoreEvents: values.ignoreEvents || [],
-          noReplay: values.noReplay,
-          sseBufferSize: values.sseBufferSize || 1048576,
-        };
-        onSubmit(payload);
-      })
+      .then((values) => onSubmit(buildPayload(values)))
       .catch(() => {});
   };
 
+  const handleValidate = async () => {
+    let values;
+    try {
+      values = await form.validateFields();
+    } catch (error) {
+      return;
+    }
+    setValidating(true);
+    try {
+      const response = await apiFetch('/api/v1/clients/validate', {
+        method: 'POST',
+        body: JSON.stringify(buildPayload(values)),
+      });
+      const data = await response.json().catch(() => ({}));
+      if (!response.ok) {
+        throw new Error(data.message || '检查失败');
+      }
+      setValidation(data);
+    } catch (error) {
+      setValidation({ valid: false, checks: [{ check: 'request', level: 'fail', message: error.message }] });
+    } finally {
+      setValidating(false);
+    }
+  };
+
   return (
     <Modal
       title={isEditing ? '编辑实例' : '创建实例'}
@@ -244,7 +336,42 @@ function ClientFormModal({ open, onCancel, onSubmit, initialValues, loading }) {
       width={680}
       okText={isEditing ? '保存修改' : '创建实例'}
       cancelText="取消"
+      footer={(_, { OkBtn, CancelBtn }) => (
+        <>
+          {!isEditing && (
+            <Button onClick={handleValidate} loading={validating}>
+              检查配置
+            </Button>
+          )}
+          <CancelBtn />
+          <OkBtn />
+        </>
+      )}
     >
+      {validation && (
+        <Alert
+          style={{ marginBottom: 16 }}
+          type={
+            !validation.valid
+              ? 'error'
+              : validation.checks.some((check) => check.level === 'warn')
+                ? 'warning'
+                : 'success'
+          }
+          showIcon
+          message={validation.valid ? '配置检查通过' : '配置检查未通过'}
+          description={
+            <ul className="validation-checks">
+              {validation.checks.map((check) => (
+                <li key={check.check}>
+                  <Tag color={VALIDATION_LEVEL_COLORS[check.level]}>{check.check}</Tag>
+                  {check.message}
+                </li>
+              ))}
+            </ul>
+          }
+        />
+      )}
       <Form layout="vertical" form={form}>
         <Form.Item
           label="实例名称"
@@ -257,6 +384,21 @@ function ClientFormModal({ open, onCancel, onSubmit, initialValues, loading }) {
           <Input placeholder="例如：Agola Webhook 中继" allowClear />
         </Form.Item>
 
+        <Form.Item
+          label="Slug"
+          name="slug"
+          extra="可在 API 路径中代替实例 ID，留空时由名称生成，改名后保持不变"
+          rules={[
+            { max: 63, message: 'Slug 不能超过 63 个字符' },
+            {
+              pattern: /^[a-z0-9]+(-[a-z0-9]+)*$/,
+              message: '只能包含小写字母和数字，以单个 - 分隔',
+            },
+          ]}
+        >
+          <Input placeholder="例如：agola-webhook" allowClear />
+        </Form.Item>
+
         <Form.Item
           label="描述"
           name="description"
@@ -270,6 +412,15 @@ function ClientFormModal({ open, onCancel, onSubmit, initialValues, loading }) {
           />
         </Form.Item>
 
+        <Form.Item
+          label="运行手册"
+          name="runbook"
+          extra="支持 Markdown，可记录中继用途、升级联系人和重放流程"
+          rules={[{ max: 20000, message: '运行手册不能超过 20000 个字符' }]}
+        >
+          <Input.TextArea rows={6} showCount maxLength={20000} placeholder="可选" />
+        </Form.Item>
+
         <Form.Item
           label="Smee URL"
           name="smeeUrl"
@@ -316,6 +467,14 @@ function ClientFormModal({ open, onCancel, onSubmit, initialValues, loading }) {
           <Input placeholder="https://internal.example.com/webhooks" allowClear />
         </Form.Item>
 
+        <Form.Item
+          label="Webhook 来源"
+          name="provider"
+          tooltip="决定事件类型的解析方式、签名请求头及可选的忽略事件"
+        >
+          <Select options={WEBHOOK_PROVIDER_OPTIONS} />
+        </Form.Item>
+
         <Collapse
           items={[
             {
@@ -335,19 +494,46 @@ function ClientFormModal({ open, onCancel, onSubmit, initialValues, loading }) {
                     <Switch checkedChildren="HTTPie" unCheckedChildren="cURL" />
                   </Form.Item>
 
-                  <Form.Item label="忽略事件类型" name="ignoreEvents">
-                    <Select
-                      mode="multiple"
-                      allowClear
-                      placeholder="选择需要忽略的事件类型"
-                      options={EVENT_IGNORE_OPTIONS}
-                    />
+                  <Form.Item label="忽略事件类型">
+                    <Space direction="vertical" style={{ width: '100%' }}>
+                      <Form.Item name="ignoreEvents" noStyle>
+                        <Select
+                          mode="multiple"
+                          allowClear
+                          placeholder="选择需要忽略的事件类型"
+                          options={ignoreEventOptions}
+                        />
+                      </Form.Item>
+                      {ignorePresets.presets.length > 0 && (
+                        <Space wrap size={[8, 8]}>
+                          <Typography.Text type="secondary">常用预设：</Typography.Text>
+                          {ignorePresets.presets.map((preset) => (
+                            <Tooltip key={preset.id} title={`${preset.description}：${preset.events.join(', ')}`}>
+                              <Button size="small" onClick={() => applyIgnorePreset(preset)}>
+                                {preset.name}
+                              </Button>
+                            </Tooltip>
+                          ))}
+                        </Space>
+                      )}
+                    </Space>
                   </Form.Item>
 
                   <Form.Item label="仅保存不转发" name="noReplay" valuePropName="checked">
                     <Switch checkedChildren="仅保存" unCheckedChildren="正常转发" />
                   </Form.Item>
 
+                  {!isEditing && (
+                    <Form.Item
+                      label="创建后立即启动"
+                      name="startImmediately"
+                      valuePropName="checked"
+                      extra="启动失败时不会保留该实例"
+                    >
+                      <Switch checkedChildren="启动" unCheckedChildren="不启动" />
+                    </Form.Item>
+                  )}
+
                   <Form.Item
                     label="SSE 缓冲区大小（字节）"
                     name="sseBufferSize"
@@ -429,13 +615,18 @@ function EventDetailModal({ open, loading, event, onClose, onReplay, onDelete, m
         key: 'response',
         label: '响应内容',
         children: (
-          <Card size="small" className="code-block">
-            <pre>
-              {responseJson
-                ? JSON.stringify(responseJson, null, 2)
-                : event?.response || '无响应数据'}
-            </pre>
-          </Card>
+          <Space direction="vertical" style={{ width: '100%' }}>
+            {event?.responseTruncated && (
+              <Alert type="info" showIcon message="响应体过长，仅保存了开头部分" />
+            )}
+            <Card size="small" className="code-block">
+              <pre>
+                {responseJson
+                  ? JSON.stringify(responseJson, null, 2)
+                  : event?.response || '无响应数据'}
+              </pre>
+            </Card>
+          </Space>
         ),
       });
     }
@@ -465,7 +656,7 @@ function EventDetailModal({ open, loading, event, onClose, onReplay, onDelete, m
           key="delete"
           danger
           icon={<DeleteOutlined />}
-          onClick={() => event && onDelete(event.id)}
+          onClick={() => event && onDelete(event.id, event.version)}
           disabled={!event}
         >
           删除
@@ -555,7 +746,7 @@ function AppContent() {
       const response = await apiFetch('/api/v1/auth/userinfo');
       const data = await response.json().catch(() => ({}));
       if (!response.ok) {
-        throw new Error(data.error || '无法获取用户信息');
+        throw new Error(data.message || '无法获取用户信息');
       }
       setAuthInfo({ loading: false, data, error: null });
     } catch (error) {
@@ -578,7 +769,7 @@ function AppContent() {
           setQuotaInfo({ loading: false, quota: null, warning: '' });
           return;
         }
-        throw new Error(data.error || '无法获取配额信息');
+        throw new Error(data.message || '无法获取配额信息');
       }
       setQuotaInfo({
         loading: false,
@@ -620,7 +811,7 @@ function AppContent() {
           setClientsTotal(0);
           return;
         }
-        throw new Error(data.error || '加载实例列表失败');
+        throw new Error(data.message || '加载实例列表失败');
       }
 
       const clientList = data.clients || [];
@@ -645,7 +836,7 @@ function AppContent() {
     const response = await apiFetch(`/api/v1/clients/${clientId}`);
     const data = await response.json().catch(() => ({}));
     if (!response.ok) {
-      throw new Error(data.error || '获取实例详情失败');
+      throw new Error(data.message || '获取实例详情失败');
     }
     return data;
   }, []);
@@ -654,7 +845,7 @@ function AppContent() {
     const response = await apiFetch(`/api/v1/clients/${clientId}/stats`);
     const data = await response.json().catch(() => ({}));
     if (!response.ok) {
-      throw new Error(data.error || '获取实例统计信息失败');
+      throw new Error(data.message || '获取实例统计信息失败');
     }
     return data;
   }, []);
@@ -690,6 +881,7 @@ function AppContent() {
           pageSize: String(pageSize),
           sortBy: eventSortState.field,
           sortOrder: eventSortState.order,
+          markDuplicates: 'true',
         });
 
         const response = await apiFetch(
@@ -697,7 +889,7 @@ function AppContent() {
         );
         const data = await response.json().catch(() => ({}));
         if (!response.ok) {
-          throw new Error(data.error || '加载事件失败');
+          throw new Error(data.message || '加载事件失败');
         }
 
         setEvents(data.events || []);
@@ -773,8 +965,12 @@ function AppContent() {
       );
       logEventSourceRef.current = source;
       source.addEventListener('log', (event) => {
+        const record = safeJsonParse(event.data);
+        if (!record) {
+          return;
+        }
         setLogs((prev) => {
-          const next = [...prev, event.data];
+          const next = [...prev, record];
           if (next.length > MAX_LOG_LINES) {
             next.splice(0, next.length - MAX_LOG_LINES);
           }
@@ -828,16 +1024,20 @@ function AppContent() {
     try {
       const method = editingClient ? 'PUT' : 'POST';
       const url = editingClient ? `/api/v1/clients/${editingClient.id}` : '/api/v1/clients';
+      const body = editingClient ? { ...payload, version: editingClient.version } : payload;
       const response = await apiFetch(url, {
         method,
-        body: JSON.stringify(payload),
+        body: JSON.stringify(body),
       });
       const data = await response.json().catch(() => ({}));
+      if (data.code === 'CLIENT_CONFLICT') {
+        throw new Error('实例已被其他人修改，请关闭后重新打开编辑');
+      }
       if (!response.ok) {
-        throw new Error(data.error || '保存实例失败');
+        throw new Error(data.message || '保存实例失败');
       }
 
-      message.success(editingClient ? '实例已更新' : '实例创建成功');
+      message.success(editingClient ? '实例已更新' : body.startImmediately ? '实例已创建并启动' : '实例创建成功');
       setCreateModalVisible(false);
       setEditingClient(null);
       if (!editingClient) {
@@ -871,7 +1071,7 @@ function AppContent() {
             });
             const data = await response.json().catch(() => ({}));
             if (!response.ok) {
-              throw new Error(data.error || '删除实例失败');
+              throw new Error(data.message || '删除实例失败');
             }
             message.success('实例已删除');
             if (selectedClientId === client.id) {
@@ -912,7 +1112,7 @@ function AppContent() {
         });
         const data = await response.json().catch(() => ({}));
         if (!response.ok) {
-          throw new Error(data.error || '操作失败');
+          throw new Error(data.message || '操作失败');
         }
         message.success(`${actionLabelMap[action]}指令已发送`);
         await loadClients();
@@ -946,7 +1146,7 @@ function AppContent() {
         });
         const data = await response.json().catch(() => ({}));
         if (!response.ok) {
-          throw new Error(data.error || `${action === 'start' ? '启动' : '停止'}实例失败`);
+          throw new Error(data.message || `${action === 'start' ? '启动' : '停止'}实例失败`);
         }
 
         const total = data.total || 0;
@@ -1032,7 +1232,7 @@ function AppContent() {
         });
         const data = await response.json().catch(() => ({}));
         if (!response.ok) {
-          throw new Error(data.error || '重放事件失败');
+          throw new Error(data.message || '重放事件失败');
         }
         message.success(`重放完成：成功 ${data.successful} 条，失败 ${data.failed} 条`);
         loadEvents(selectedClientId);
@@ -1044,7 +1244,7 @@ function AppContent() {
   );
 
   const handleDeleteEvent = useCallback(
-    (eventId) => {
+    (eventId, version) => {
       if (!selectedClientId) {
         return;
       }
@@ -1056,13 +1256,18 @@ function AppContent() {
         cancelText: '取消',
         async onOk() {
           try {
+            const query = version ? `?version=${version}` : '';
             const response = await apiFetch(
-              `/api/v1/clients/${selectedClientId}/events/${eventId}`,
+              `/api/v1/clients/${selectedClientId}/events/${eventId}${query}`,
               { method: 'DELETE' },
             );
             const data = await response.json().catch(() => ({}));
+            if (response.status === 409) {
+              loadEvents(selectedClientId);
+              throw new Error('事件已被修改，请确认后重试');
+            }
             if (!response.ok) {
-              throw new Error(data.error || '删除事件失败');
+              throw new Error(data.message || '删除事件失败');
             }
             message.success('事件已删除');
             loadEvents(selectedClientId);
@@ -1075,6 +1280,37 @@ function AppContent() {
     [loadEvents, message, modal, selectedClientId],
   );
 
+  const handleDeduplicateEvents = useCallback(() => {
+    if (!selectedClientId) {
+      return;
+    }
+    modal.confirm({
+      title: '删除重复投递的事件？',
+      content: '投递 ID 或事件类型和请求体相同的事件只保留最早的一条。',
+      icon: <WarningOutlined />,
+      okText: '删除',
+      okType: 'danger',
+      cancelText: '取消',
+      async onOk() {
+        try {
+          const response = await apiFetch(
+            `/api/v1/clients/${selectedClientId}/events/deduplicate`,
+            { method: 'POST' },
+          );
+          const data = await response.json().catch(() => ({}));
+          if (!response.ok) {
+            throw new Error(data.message || '删除重复事件失败');
+          }
+          message.success(`已删除 ${data.deleted || 0} 条重复事件`);
+          setSelectedEventKeys([]);
+          loadEvents(selectedClientId);
+        } catch (error) {
+          message.error(`删除重复事件失败：${error.message}`);
+        }
+      },
+    });
+  }, [loadEvents, message, modal, selectedClientId]);
+
   const handleOpenEventDetail = useCallback(
     async (eventId) => {
       if (!selectedClientId) {
@@ -1088,7 +1324,7 @@ function AppContent() {
         );
         const data = await response.json().catch(() => ({}));
         if (!response.ok) {
-          throw new Error(data.error || '获取事件详情失败');
+          throw new Error(data.message || '获取事件详情失败');
         }
         setEventDetailData(data);
       } catch (error) {
@@ -1209,10 +1445,11 @@ function AppContent() {
             >
               详情
             </Button>
-            {record.status === 'running' ? (
+            {record.status === 'running' || record.status === 'stopping' ? (
               <Button
                 size="small"
                 icon={<StopOutlined />}
+                loading={record.status === 'stopping'}
                 onClick={() => performClientAction(record.id, 'stop')}
               >
                 停止
@@ -1222,6 +1459,7 @@ function AppContent() {
                 size="small"
                 type="primary"
                 icon={<CaretRightOutlined />}
+                loading={record.status === 'starting'}
                 onClick={() => performClientAction(record.id, 'start')}
               >
                 启动
@@ -1271,10 +1509,17 @@ function AppContent() {
         dataIndex: 'id',
         key: 'id',
         ellipsis: true,
-        render: (value) => (
-          <Typography.Text copyable={{ text: value }} ellipsis style={{ maxWidth: '100%' }}>
-            {value}
-          </Typography.Text>
+        render: (value, record) => (
+          <Space size="small">
+            <Typography.Text copyable={{ text: value }} ellipsis style={{ maxWidth: '100%' }}>
+              {value}
+            </Typography.Text>
+            {record.duplicateOf && (
+              <Tooltip title={`重复投递，最早的事件：${record.duplicateOf}`}>
+                <Tag color="orange">重复</Tag>
+              </Tooltip>
+            )}
+          </Space>
         ),
       },
       {
@@ -1302,7 +1547,7 @@ function AppContent() {
               size="small"
               danger
               icon={<DeleteOutlined />}
-              onClick={() => handleDeleteEvent(record.id)}
+              onClick={() => handleDeleteEvent(record.id, record.version)}
             >
               删除
             </Button>
@@ -1452,7 +1697,9 @@ function AppContent() {
                 value={statusFilter}
                 options={[
                   { label: '全部状态', value: 'all' },
+                  { label: '启动中', value: 'starting' },
                   { label: '运行中', value: 'running' },
+                  { label: '停止中', value: 'stopping' },
                   { label: '已停止', value: 'stopped' },
                   { label: '错误', value: 'error' },
                 ]}
@@ -1616,9 +1863,10 @@ function AppContent() {
               >
                 刷新
               </Button>
-              {clientDetail.status === 'running' ? (
+              {clientDetail.status === 'running' || clientDetail.status === 'stopping' ? (
                 <Button
                   icon={<StopOutlined />}
+                  loading={clientDetail.status === 'stopping'}
                   onClick={() => performClientAction(clientDetail.id, 'stop')}
                 >
                   停止
@@ -1627,6 +1875,7 @@ function AppContent() {
                 <Button
                   type="primary"
                   icon={<CaretRightOutlined />}
+                  loading={clientDetail.status === 'starting'}
                   onClick={() => performClientAction(clientDetail.id, 'start')}
                 >
                   启动
@@ -1658,11 +1907,30 @@ function AppContent() {
                           <Descriptions.Item label="名称">
                             {clientDetail.name}
                           </Descriptions.Item>
+                          <Descriptions.Item label="Slug">
+                            {clientDetail.slug || '-'}
+                          </Descriptions.Item>
                           <Descriptions.Item label="描述">
                             {clientDetail.description || '-'}
                           </Descriptions.Item>
+                          <Descriptions.Item label="运行手册">
+                            {clientDetail.runbook ? (
+                              <Typography.Paragraph style={{ whiteSpace: 'pre-wrap', marginBottom: 0 }}>
+                                {clientDetail.runbook}
+                              </Typography.Paragraph>
+                            ) : (
+                              '-'
+                            )}
+                          </Descriptions.Item>
                           <Descriptions.Item label="状态">
-                            {renderClientStatusTag(clientDetail.status)}
+                            <Space size={8}>
+                              {renderClientStatusTag(clientDetail.status)}
+                              {clientDetail.statusChangedAt && (
+                                <Typography.Text type="secondary">
+                                  自 {formatDateTime(clientDetail.statusChangedAt)}
+                                </Typography.Text>
+                              )}
+                            </Space>
                           </Descriptions.Item>
                           <Descriptions.Item label="Smee URL">
                             <Space size={8}>
@@ -1700,6 +1968,9 @@ function AppContent() {
                           <Descriptions.Item label="脚本格式">
                             {clientDetail.httpie ? 'HTTPie' : 'cURL'}
                           </Descriptions.Item>
+                          <Descriptions.Item label="Webhook 来源">
+                            {EVENT_PROVIDER_LABELS[clientDetail.provider || 'custom']}
+                          </Descriptions.Item>
                           <Descriptions.Item label="忽略事件">
                             {clientDetail.ignoreEvents?.length
                               ? clientDetail.ignoreEvents.join(', ')
@@ -1722,6 +1993,15 @@ function AppContent() {
                               <span>PID：{clientDetail.pid || '-'}</span>
                               <span>重启次数：{clientDetail.restartCount || 0}</span>
                               <span>最后错误：{clientDetail.lastError || '-'}</span>
+                              {clientDetail.lastErrorCategory && (
+                                <span>
+                                  错误类型：
+                                  {CLIENT_ERROR_CATEGORY_MAP[clientDetail.lastErrorCategory] ||
+                                    clientDetail.lastErrorCategory}
+                                  {clientDetail.lastExitCode != null &&
+                                    `（退出码 ${clientDetail.lastExitCode}）`}
+                                </span>
+                              )}
                             </Space>
                           </Descriptions.Item>
                         </Descriptions>
@@ -1748,6 +2028,9 @@ function AppContent() {
                         >
                           清空选择
                         </Button>
+                        <Button danger icon={<DeleteOutlined />} onClick={handleDeduplicateEvents}>
+                          删除重复事件
+                        </Button>
                       </Space>
                       <Table
                         rowKey="id"
@@ -1814,9 +2097,12 @@ function AppContent() {
                           {logs.length === 0 && (
                             <Typography.Text type="secondary">暂无日志输出</Typography.Text>
                           )}
-                          {logs.map((line, index) => (
-                            <div key={`${line}-${index}`} className="log-line">
-                              {line}
+                          {logs.map((record) => (
+                            <div
+                              key={record.seq}
+                              className={`log-line log-line-${record.level}`}
+                            >
+                              [{formatDateTime(record.time)}] [{record.source}] {record.message}
                             </div>
                           ))}
                         </div>
@@ -1839,7 +2125,7 @@ function AppContent() {
           setEventDetailData(null);
         }}
         onReplay={(eventId) => handleReplayEvents([eventId])}
-        onDelete={(eventId) => handleDeleteEvent(eventId)}
+        onDelete={(eventId, version) => handleDeleteEvent(eventId, version)}
         messageApi={message}
       />
     </Layout>